@@ -2,58 +2,207 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
-	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
+	"github.com/ripta/hotpod/internal/audit"
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/buildinfo"
 	"github.com/ripta/hotpod/internal/config"
+	"github.com/ripta/hotpod/internal/controller"
+	"github.com/ripta/hotpod/internal/custommetrics"
+	"github.com/ripta/hotpod/internal/events"
 	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/fleet"
 	"github.com/ripta/hotpod/internal/handlers"
+	"github.com/ripta/hotpod/internal/health"
+	"github.com/ripta/hotpod/internal/initjob"
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/internal/leader"
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/internal/logging"
 	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/nodepressure"
+	"github.com/ripta/hotpod/internal/probe"
+	"github.com/ripta/hotpod/internal/profiling"
 	"github.com/ripta/hotpod/internal/queue"
+	"github.com/ripta/hotpod/internal/replay"
+	"github.com/ripta/hotpod/internal/schedule"
 	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/internal/shed"
 	"github.com/ripta/hotpod/internal/sidecar"
+	"github.com/ripta/hotpod/internal/slo"
+	"github.com/ripta/hotpod/internal/state"
+	"github.com/ripta/hotpod/internal/topology"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
-// version is set via ldflags at build time.
-var version = "dev"
+// version, commit, and buildDate are set via ldflags at build time.
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
 
 func main() {
+	fault.RunZombieChild()
+
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		code := runCommand(ctx, os.Args[2:], os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		slog.Error("failed to load configuration", "error", err)
 		os.Exit(1)
 	}
 
-	initLogger(cfg.LogLevel)
+	closeLog, err := logging.Setup(logging.Config{
+		Level:    cfg.LogLevel,
+		Format:   cfg.LogFormat,
+		Output:   cfg.LogOutput,
+		MaxSize:  cfg.LogMaxSize,
+		MaxFiles: cfg.LogMaxFiles,
+	})
+	if err != nil {
+		slog.Error("failed to configure logging", "error", err)
+		os.Exit(1)
+	}
+	defer closeLog()
+	if cfg.Variant != "" {
+		slog.SetDefault(slog.Default().With("variant", cfg.Variant))
+	}
+
+	if cfg.Mode == "init" {
+		code := runInit(cfg)
+		closeLog()
+		os.Exit(code)
+	}
+
+	events.Default = events.New(cfg.EventLogSize)
+	if cfg.KubeEvents {
+		startKubeEvents()
+	}
+
+	authn, err := newAuthenticator(cfg)
+	if err != nil {
+		slog.Error("failed to load admin tokens", "error", err)
+		os.Exit(1)
+	}
 
 	injector := fault.NewInjector()
 	srv := server.New(cfg, injector)
 
-	healthHandlers := handlers.NewHealthHandlers(srv.Lifecycle())
+	guard, err := newGuard(cfg)
+	if err != nil {
+		slog.Error("invalid chaos guardrails", "error", err)
+		os.Exit(1)
+	}
+
+	var tlsFaults *fault.TLSFaults
+	if cfg.TLS {
+		if tlsFaults, err = newTLSFaults(cfg); err != nil {
+			slog.Error("failed to load TLS certificate", "error", err)
+			os.Exit(1)
+		}
+		srv.SetTLSConfig(tlsFaults.ServerConfig())
+	}
+
+	dependencies, err := newDependencies(cfg)
+	if err != nil {
+		slog.Error("invalid dependencies", "error", err)
+		os.Exit(1)
+	}
+	healthDelays := health.NewDelays()
+	if err := healthDelays.Set("", health.Delay{Fixed: cfg.HealthDelay, Jitter: cfg.HealthDelayJitter}); err != nil {
+		slog.Error("invalid health delay", "error", err)
+		os.Exit(1)
+	}
+	healthHandlers := handlers.NewHealthHandlers(srv.Lifecycle(), dependencies, healthDelays)
 	healthHandlers.Register(srv.Mux())
+	dependencyHandlers := handlers.NewDependencyHandlers(authn, dependencies)
+	dependencyHandlers.Register(srv.Mux())
+	healthDelayHandlers := handlers.NewHealthDelayHandlers(authn, healthDelays)
+	healthDelayHandlers.Register(srv.Mux())
+
+	preStopHandlers := handlers.NewPreStopHandlers(srv.Lifecycle(), cfg.PreStopDelay)
+	preStopHandlers.Register(srv.Mux())
 
-	metricsHandlers := handlers.NewMetricsHandlers()
+	scrapeCost := metrics.NewScrapeCost()
+	if err := scrapeCost.Set(cfg.MetricsDelay, cfg.MetricsDelayJitter, cfg.MetricsPadding); err != nil {
+		slog.Error("invalid metrics scrape cost", "error", err)
+		os.Exit(1)
+	}
+	metricsHandlers := handlers.NewMetricsHandlers(scrapeCost)
 	metricsHandlers.Register(srv.Mux())
+	scrapeCostHandlers := handlers.NewScrapeCostHandlers(authn, scrapeCost)
+	scrapeCostHandlers.Register(srv.Mux())
 
-	infoHandlers := handlers.NewInfoHandlers(version, srv.Lifecycle(), cfg)
+	build := buildinfo.Read(commit, buildDate)
+	infoHandlers := handlers.NewInfoHandlers(version, build, srv.Lifecycle(), cfg)
 	infoHandlers.Register(srv.Mux())
 
+	kubeClient := newKubeClient()
+	resolveTopology(cfg, kubeClient)
+
+	echoHandlers := handlers.NewEchoHandlers()
+	echoHandlers.Register(srv.Mux())
+
+	sessionHandlers := handlers.NewSessionHandlers(kube.PodName())
+	sessionHandlers.Register(srv.Mux())
+
+	mirrorHandlers := handlers.NewMirrorHandlers()
+	mirrorHandlers.Register(srv.Mux())
+
+	burstHandlers := handlers.NewBurstHandlers()
+	burstHandlers.Register(srv.Mux())
+
+	framingHandlers := handlers.NewFramingHandlers()
+	framingHandlers.Register(srv.Mux())
+
+	cacheableHandlers := handlers.NewCacheableHandlers()
+	cacheableHandlers.Register(srv.Mux())
+
+	protectedHandlers := handlers.NewProtectedHandlers(cfg.ProtectedBasicAuth, cfg.ProtectedHeader)
+	protectedHandlers.Register(srv.Mux())
+
+	eventsHandlers := handlers.NewEventsHandlers(authn, events.Default)
+	eventsHandlers.Register(srv.Mux())
+
+	uiHandlers := handlers.NewUIHandlers()
+	uiHandlers.Register(srv.Mux())
+
 	var runner *sidecar.Runner
 	var queueHandlers *handlers.QueueHandlers
 	var workQueue *queue.Queue
 	var workerPool *queue.WorkerPool
 
-	if cfg.Mode == "sidecar" {
+	if cfg.RunsSidecar() {
 		metrics.SidecarMode.Set(1)
-		runner = sidecar.New(cfg.SidecarCPUBaseline, cfg.SidecarCPUJitter, cfg.SidecarMemoryBaseline)
-	} else {
-		metrics.SidecarMode.Set(0)
+		runner = sidecar.New(sidecar.Config{
+			CPUBaseline:    cfg.SidecarCPUBaseline,
+			CPUJitter:      cfg.SidecarCPUJitter,
+			MemoryBaseline: cfg.SidecarMemoryBaseline,
+			MemoryJitter:   cfg.SidecarMemoryJitter,
+			MemoryPeak:     cfg.SidecarMemoryPeak,
+			MemoryRamp:     cfg.SidecarMemoryRamp,
+		})
+	}
 
+	if cfg.RunsApp() {
 		tracker := load.NewTracker(cfg.MaxConcurrentOps)
+		tracker.SetQueueTimeout(cfg.AdmissionQueueTimeout)
 		latencyHandlers := handlers.NewLatencyHandlers(tracker)
 		latencyHandlers.Register(srv.Mux())
 
@@ -68,25 +217,138 @@ func main() {
 
 		workHandlers := handlers.NewWorkHandlers(tracker, cfg)
 		workHandlers.Register(srv.Mux())
+		if err := loadWorkProfiles(cfg, workHandlers.Profiles()); err != nil {
+			slog.Error("invalid work profiles", "error", err)
+			os.Exit(1)
+		}
+		workProfileHandlers := handlers.NewWorkProfileHandlers(authn, workHandlers.Profiles())
+		workProfileHandlers.Register(srv.Mux())
+
+		dnsHandlers := handlers.NewDNSHandlers(tracker)
+		dnsHandlers.Register(srv.Mux())
 
-		faultHandlers := handlers.NewFaultHandlers(!cfg.DisableChaos)
+		faultHandlers := handlers.NewFaultHandlers(!cfg.DisableChaos, authn, guard)
 		faultHandlers.Register(srv.Mux())
 
 		workQueue = queue.New(cfg.QueueMaxDepth)
+		workQueue.SetMaxPayload(cfg.MaxMemorySize)
+		if cfg.ReadyQueueHigh > 0 {
+			backlog := health.NewBacklog(cfg.ReadyQueueHigh, cfg.ReadyQueueLow)
+			backlog.Watch(workQueue.Depth)
+			healthHandlers.SetBacklog(backlog)
+		}
 		queueHandlers = handlers.NewQueueHandlers(!cfg.DisableQueue, workQueue, cfg.QueueDefaultWorkers)
 		queueHandlers.Register(srv.Mux())
 		workerPool = queueHandlers.WorkerPool()
 	}
 
-	adminHandlers := handlers.NewAdminHandlers(cfg.AdminToken, srv.Lifecycle(), injector, cfg, workQueue, workerPool)
+	// Audit sits outside ReadOnly so rejected admin mutations are recorded too.
+	auditLog := audit.New(audit.DefaultCapacity)
+	srv.Use(server.AdminAudit(auditLog, authn))
+
+	disabledEndpoints, _ := cfg.DisabledEndpointGroups()
+	endpointGates := server.NewEndpointGates(config.EndpointGroups, disabledEndpoints)
+	srv.Use(server.EndpointGate(endpointGates))
+	if cfg.ReadOnly {
+		srv.Use(server.ReadOnly)
+		slog.Info("read-only mode enabled")
+	}
+	endpointHandlers := handlers.NewEndpointHandlers(authn, endpointGates)
+	endpointHandlers.Register(srv.Mux())
+
+	nodePressure := newNodePressure(cfg)
+	if nodePressure != nil {
+		srv.Use(server.NodePressure(nodePressure))
+	}
+	nodePressureHandlers := handlers.NewNodePressureHandlers(authn, nodePressure)
+	nodePressureHandlers.Register(srv.Mux())
+
+	shedder := shed.New(cfg.ShedMaxInFlight, cfg.ShedMaxCPU.Seconds())
+	if shedder.Enabled() {
+		srv.Use(server.LoadShedding(shedder))
+		go shedder.Run(context.Background(), time.Second)
+	}
+
+	var store *state.Store
+	if cfg.StateFile != "" {
+		store = state.NewStore(cfg.StateFile, state.Sources{Injector: injector, Lifecycle: srv.Lifecycle(), Queue: workQueue, Dependencies: dependencies, HealthDelays: healthDelays, ScrapeCost: scrapeCost})
+		if err := store.Restore(); err != nil {
+			// A bad state file must not crash-loop the pod; start clean instead.
+			slog.Warn("failed to restore runtime state", "path", cfg.StateFile, "error", err)
+		}
+		srv.Use(server.PersistState(store.SaveOrLog))
+	}
+
+	adminHandlers := handlers.NewAdminHandlers(authn, srv.Lifecycle(), injector, cfg, workQueue, workerPool, auditLog)
 	adminHandlers.Register(srv.Mux())
 
+	selftestHandlers := handlers.NewSelftestHandlers(authn, srv.Mux(), endpointGates, cfg.RunsApp())
+	selftestHandlers.Register(srv.Mux())
+
+	discoverer := newDiscoverer(cfg)
+	fleetHandlers := handlers.NewFleetHandlers(authn, discoverer, fleet.NewBroadcaster(30*time.Second, cfg.FleetScheme()))
+	fleetHandlers.Register(srv.Mux())
+
+	customMetrics := custommetrics.NewLocal(workQueue)
+	customMetricsHandlers := handlers.NewCustomMetricsHandlers(authn, customMetrics, kube.PodName())
+	customMetricsHandlers.Register(srv.Mux())
+
+	elector := newElector(cfg)
+	leaderHandlers := handlers.NewLeaderHandlers(elector, !cfg.DisableChaos, authn, guard)
+	leaderHandlers.Register(srv.Mux())
+
+	evictHandlers := handlers.NewEvictHandlers(!cfg.DisableChaos, authn, guard, kubeClient, kube.PodName(), kube.PodNamespace())
+	evictHandlers.Register(srv.Mux())
+
+	localURL := cfg.LocalURL()
+	player := replay.NewPlayer(localURL)
+	replayHandlers := handlers.NewReplayHandlers(authn, player)
+	replayHandlers.Register(srv.Mux())
+
+	sidecarHandlers := handlers.NewSidecarHandlers(authn, runner)
+	sidecarHandlers.Register(srv.Mux())
+
+	profileHandlers := handlers.NewProfileHandlers(authn, cfg.GroupRequestTimeout("admin"))
+	profileHandlers.Register(srv.Mux())
+
+	dumpHandlers := handlers.NewDumpHandlers(authn, cfg.DumpDir, cfg.DumpMaxSize, cfg.DumpMaxFiles)
+	dumpHandlers.Register(srv.Mux())
+
+	tlsFaultHandlers := handlers.NewTLSFaultHandlers(authn, tlsFaults)
+	tlsFaultHandlers.Register(srv.Mux())
+
+	chaosSchedule := fault.NewChaosSchedule()
+	srv.Use(server.ChaosSchedule(chaosSchedule))
+	chaosScheduleHandlers := handlers.NewChaosScheduleHandlers(!cfg.DisableChaos, authn, guard, chaosSchedule)
+	chaosScheduleHandlers.Register(srv.Mux())
+
+	storm := fault.NewStorm()
+	srv.Use(server.Storm(storm))
+	stormHandlers := handlers.NewStormHandlers(!cfg.DisableChaos, authn, guard, storm)
+	stormHandlers.Register(srv.Mux())
+
+	brownout := fault.NewBrownout()
+	srv.Use(server.Brownout(brownout))
+	brownoutHandlers := handlers.NewBrownoutHandlers(!cfg.DisableChaos, authn, guard, brownout)
+	brownoutHandlers.Register(srv.Mux())
+
+	sloTracker := newSLOTracker(cfg)
+	srv.SetSLO(sloTracker)
+	sloHandlers := handlers.NewSLOHandlers(authn, sloTracker)
+	sloHandlers.Register(srv.Mux())
+
+	prober := newProber(cfg, localURL)
+	probeHandlers := handlers.NewProbeHandlers(authn, prober)
+	probeHandlers.Register(srv.Mux())
+
 	if cfg.EnablePprof {
-		go startPprof()
+		go startPprof(cfg, authn)
 	}
 
 	slog.Info("hotpod starting",
 		"version", version,
+		"commit", build.Commit,
+		"go_version", build.GoVersion,
 		"mode", cfg.Mode,
 		"port", cfg.Port,
 		"log_level", cfg.LogLevel,
@@ -98,43 +360,474 @@ func main() {
 		go runner.Start(context.Background())
 	}
 
+	scheduler, err := newScheduler(cfg, workQueue)
+	if err != nil {
+		slog.Error("invalid load schedule", "error", err)
+		os.Exit(1)
+	}
+	bgCtx, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+	if scheduler.Enabled() {
+		if nodePressure != nil {
+			scheduler.SetPause(nodePressure.BackingOff)
+		}
+		go scheduler.Run(bgCtx)
+	}
+	if nodePressure != nil {
+		go nodePressure.Run(bgCtx)
+	}
+	go chaosSchedule.Run(bgCtx)
+	if sloTracker != nil {
+		go sloTracker.Run(bgCtx)
+	}
+	if prober != nil {
+		go prober.Run(bgCtx)
+	}
+	if cfg.Controller {
+		startController(bgCtx, cfg)
+	}
+	if elector != nil {
+		go elector.Run(bgCtx)
+	}
+	if cfg.RunsSidecar() && cfg.SidecarProxyPort > 0 {
+		go startSidecarProxy(bgCtx, cfg)
+	}
+	if cfg.CustomMetricsPort > 0 {
+		go startCustomMetrics(bgCtx, cfg, customMetrics, discoverer)
+	}
+
+	var pushDone chan struct{}
+	pushCtx, cancelPush := context.WithCancel(context.Background())
+	defer cancelPush()
+	if cfg.MetricsPushMode != "" {
+		pusher := metrics.NewPusher(cfg.MetricsPushMode, cfg.MetricsPushURL, cfg.MetricsPushJob, cfg.MetricsPushInterval)
+		pushDone = make(chan struct{})
+		go func() {
+			defer close(pushDone)
+			pusher.Start(pushCtx)
+		}()
+	}
+
+	var profilingDone chan struct{}
+	switch cfg.ProfilingMode {
+	case "pyroscope":
+		agent := newProfilingAgent(cfg)
+		profilingDone = make(chan struct{})
+		go func() {
+			defer close(profilingDone)
+			agent.Run(pushCtx)
+		}()
+	case "parca":
+		slog.Info("continuous profiling via Parca scraping", "bind", cfg.PprofBind, "port", cfg.PprofPort, "path", "/debug/pprof/")
+	}
+
 	startTime := time.Now()
 	if err := srv.Run(context.Background()); err != nil {
 		slog.Error("server error", "error", err)
 		os.Exit(1)
 	}
 
+	player.Stop()
+	cancelBackground()
 	if runner != nil {
 		runner.Stop()
 	}
 	if queueHandlers != nil {
 		queueHandlers.WorkerPool().Stop()
 	}
+	cancelPush()
+	if pushDone != nil {
+		<-pushDone
+	}
+	if profilingDone != nil {
+		<-profilingDone
+	}
+	if store != nil {
+		store.SaveOrLog()
+	}
 	slog.Info("hotpod shutdown complete", "uptime", time.Since(startTime))
+
+	if code, ok := srv.Lifecycle().ExitCode(); ok {
+		slog.Info("exiting with configured exit code", "exit_code", code)
+		closeLog()
+		os.Exit(code)
+	}
 }
 
-func startPprof() {
-	slog.Info("pprof server starting", "port", 6060, "bind", "localhost")
-	if err := http.ListenAndServe("localhost:6060", nil); err != nil {
-		slog.Error("pprof server error", "error", err)
+// runInit performs the init mode work and returns the process exit code: the
+// configured code on success, or 1 if the work failed or was interrupted.
+func runInit(cfg *config.Config) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err := initjob.Run(ctx, initjob.Config{
+		CPU:    cfg.InitCPU,
+		Cores:  cfg.InitCores,
+		IOSize: cfg.InitIOSize,
+		IODir:  cfg.IOPath(),
+		Sleep:  cfg.InitSleep,
+	})
+	if err != nil {
+		slog.Error("init job failed", "error", err)
+		return 1
+	}
+	slog.Info("init job exiting", "exit_code", cfg.InitExitCode)
+	return cfg.InitExitCode
+}
+
+// newAuthenticator builds the admin authenticator from the legacy token plus
+// any role-scoped tokens given inline or in a mounted file, and optionally an
+// OIDC issuer for bearer JWTs.
+func newAuthenticator(cfg *config.Config) (*auth.Authenticator, error) {
+	tokens, err := auth.ParseTokens(cfg.AdminTokens)
+	if err != nil {
+		return nil, err
 	}
+	if cfg.AdminTokensFile != "" {
+		fileTokens, err := auth.LoadTokensFile(cfg.AdminTokensFile)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, fileTokens...)
+	}
+	authn := auth.New(cfg.AdminToken, tokens)
+	if cfg.OIDCIssuer != "" {
+		authn.UseJWT(auth.NewJWTVerifier(auth.JWTConfig{
+			Issuer:      cfg.OIDCIssuer,
+			Audience:    cfg.OIDCAudience,
+			JWKSURL:     cfg.OIDCJWKSURL,
+			RoleClaim:   cfg.OIDCRoleClaim,
+			DefaultRole: auth.Role(cfg.OIDCDefaultRole),
+		}))
+		slog.Info("OIDC authentication enabled", "issuer", cfg.OIDCIssuer, "audience", cfg.OIDCAudience)
+	}
+	return authn, nil
 }
 
-func initLogger(level string) {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
+// newGuard parses the chaos allowlist and cooldowns into blast radius
+// guardrails.
+func newGuard(cfg *config.Config) (*fault.Guard, error) {
+	allow, err := fault.ParseChaosAllow(cfg.ChaosAllow)
+	if err != nil {
+		return nil, err
+	}
+	cooldowns, err := fault.ParseChaosCooldowns(cfg.ChaosCooldowns)
+	if err != nil {
+		return nil, err
+	}
+	if allow != nil {
+		slog.Info("chaos actions restricted", "allow", cfg.ChaosAllow)
+	}
+	return fault.NewGuard(fault.GuardConfig{Allow: allow, Cooldowns: cooldowns, MaxDestructive: cfg.ChaosMaxDestructive}), nil
+}
+
+// newScheduler parses the configured background load patterns. CPU levels use
+// Kubernetes CPU notation and memory levels use size notation.
+func newScheduler(cfg *config.Config, q *queue.Queue) (*schedule.Scheduler, error) {
+	parsers := []struct {
+		spec  string
+		name  string
+		parse schedule.LevelParser
+	}{
+		{cfg.ScheduleCPU, "cpu", func(s string) (float64, error) {
+			d, err := config.ParseCPU(s)
+			return d.Seconds(), err
+		}},
+		{cfg.ScheduleMemory, "memory", func(s string) (float64, error) {
+			n, err := config.ParseSize(s)
+			return float64(n), err
+		}},
+		{cfg.ScheduleQueue, "queue", func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		}},
+	}
+
+	patterns := make([]schedule.Pattern, len(parsers))
+	for i, p := range parsers {
+		if p.spec == "" {
+			continue
+		}
+		pattern, err := schedule.ParsePattern(p.spec, p.parse)
+		if err != nil {
+			return nil, fmt.Errorf("%s schedule: %w", p.name, err)
+		}
+		patterns[i] = pattern
+	}
+
+	return schedule.New(patterns[0], patterns[1], patterns[2], q), nil
+}
+
+// newTLSFaults loads the configured serving certificate, or generates a
+// self-signed one, for the main listener.
+func newTLSFaults(cfg *config.Config) (*fault.TLSFaults, error) {
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return fault.NewTLSFaults(cert), nil
+	}
+	hosts := []string{"localhost"}
+	if name := kube.PodName(); name != "" {
+		hosts = append(hosts, name)
+	}
+	now := time.Now()
+	cert, err := fault.SelfSignedCertificate(hosts, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	return fault.NewTLSFaults(cert), nil
+}
+
+// newDependencies creates the configured dependency checks, all passing.
+func newDependencies(cfg *config.Config) (*health.Dependencies, error) {
+	names, err := health.ParseDependencyNames(cfg.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	deps := health.NewDependencies()
+	for _, name := range names {
+		if err := deps.Set(api.DependencyCheck{Name: name}); err != nil {
+			return nil, err
+		}
+	}
+	return deps, nil
+}
+
+// loadWorkProfiles defines the configured /work profiles, first from
+// HOTPOD_WORK_PROFILES and then from HOTPOD_WORK_PROFILES_FILE.
+func loadWorkProfiles(cfg *config.Config, profiles *handlers.WorkProfiles) error {
+	if cfg.WorkProfiles != "" {
+		if err := defineWorkProfiles(profiles, []byte(cfg.WorkProfiles)); err != nil {
+			return err
+		}
+	}
+	if cfg.WorkProfilesFile != "" {
+		if err := defineWorkProfilesFile(profiles, cfg.WorkProfilesFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defineWorkProfilesFile defines the profiles in a JSON file.
+func defineWorkProfilesFile(profiles *handlers.WorkProfiles, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading work profiles: %w", err)
+	}
+	if err := defineWorkProfiles(profiles, data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// defineWorkProfiles defines each profile in a JSON array.
+func defineWorkProfiles(profiles *handlers.WorkProfiles, data []byte) error {
+	specs, err := handlers.ParseWorkProfiles(data)
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if err := profiles.Define(spec); err != nil {
+			return fmt.Errorf("work profile %q: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// startController runs the HotpodProfile controller in the pod's namespace.
+// Failure to build an in-cluster client is fatal since the controller was
+// explicitly requested.
+func startController(ctx context.Context, cfg *config.Config) {
+	client, err := kube.NewInCluster()
+	if err != nil {
+		slog.Error("failed to start hotpod profile controller", "error", err)
+		os.Exit(1)
+	}
+
+	ctrl := controller.New(client, kube.PodNamespace(), cfg.ControllerToken, cfg.ControllerResync)
+	go ctrl.Run(ctx)
+}
+
+// newElector returns a lease-based leader elector, or nil if leader election
+// is disabled. Failure to build an in-cluster client is fatal since leader
+// election was explicitly requested.
+func newElector(cfg *config.Config) *leader.Elector {
+	if !cfg.LeaderElection {
+		return nil
+	}
+	client, err := kube.NewInCluster()
+	if err != nil {
+		slog.Error("failed to start leader election", "error", err)
+		os.Exit(1)
+	}
+	return leader.New(client, kube.PodNamespace(), leader.Config{
+		LeaseName:     cfg.LeaderLeaseName,
+		Identity:      kube.PodName(),
+		LeaseDuration: cfg.LeaderLeaseDuration,
+		RenewDeadline: cfg.LeaderRenewDeadline,
+		RetryPeriod:   cfg.LeaderRetryPeriod,
+	})
+}
+
+// newNodePressure returns a watcher of the pod's node, or nil if node
+// pressure back-off is disabled. Failure to build an in-cluster client is
+// fatal since the watcher was explicitly requested.
+func newNodePressure(cfg *config.Config) *nodepressure.Watcher {
+	if !cfg.NodePressure {
+		return nil
+	}
+	client, err := kube.NewInCluster()
+	if err != nil {
+		slog.Error("failed to start node pressure watcher", "error", err)
+		os.Exit(1)
+	}
+	node := kube.NodeName()
+	if node == "" {
+		slog.Error("failed to start node pressure watcher", "error", "NODE_NAME is not set")
+		os.Exit(1)
+	}
+	return nodepressure.New(client, node, cfg.NodePressureInterval)
+}
+
+// newSLOTracker returns a tracker for the configured SLO objectives, or nil
+// when none is set.
+func newSLOTracker(cfg *config.Config) *slo.Tracker {
+	windows, err := slo.ParseWindows(cfg.SLOWindows)
+	if err != nil {
+		slog.Error("invalid SLO windows", "error", err)
+		os.Exit(1)
+	}
+	sloCfg := slo.Config{
+		Availability:     cfg.SLOAvailability,
+		Latency:          cfg.SLOLatency,
+		LatencyThreshold: cfg.SLOLatencyThreshold,
+		Period:           cfg.SLOPeriod,
+		Windows:          windows,
+	}
+	if !sloCfg.Enabled() {
+		return nil
+	}
+	if err := sloCfg.Validate(); err != nil {
+		slog.Error("invalid SLO configuration", "error", err)
+		os.Exit(1)
+	}
+	return slo.New(sloCfg)
+}
+
+// newProber returns a prober for the configured targets, with paths probed
+// on the local server at localURL, or nil when there are no targets.
+func newProber(cfg *config.Config, localURL string) *probe.Prober {
+	targets, err := probe.ParseTargets(cfg.ProbeTargets, localURL)
+	if err != nil {
+		slog.Error("invalid probe targets", "error", err)
+		os.Exit(1)
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	return probe.New(targets, cfg.ProbeInterval, cfg.ProbeTimeout)
+}
+
+// newKubeClient returns an in-cluster Kubernetes API client, or nil when
+// hotpod is not running in a cluster, for features that are optional.
+func newKubeClient() *kube.Client {
+	client, err := kube.NewInCluster()
+	if err != nil {
+		slog.Debug("kubernetes API unavailable", "error", err)
+		return nil
+	}
+	return client
+}
+
+// resolveTopology determines the pod, node, and zone labels added to
+// responses. A failed node lookup leaves the zone and region empty rather
+// than failing startup.
+func resolveTopology(cfg *config.Config, client *kube.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	t, err := topology.Resolve(ctx, client, cfg.Zone, cfg.Region)
+	if err != nil {
+		slog.Warn("failed to resolve zone from node labels", "error", err)
+	}
+	slog.Debug("topology resolved", "pod", t.Pod, "node", t.Node, "zone", t.Zone, "region", t.Region)
+}
+
+// newDiscoverer returns the configured peer discoverer, or nil if fleet
+// coordination is disabled or the Kubernetes API is unavailable.
+func newDiscoverer(cfg *config.Config) fleet.Discoverer {
+	switch {
+	case cfg.FleetDNS != "":
+		return &fleet.DNSDiscoverer{Host: cfg.FleetDNS, Port: cfg.FleetPort}
+	case cfg.FleetSelector != "":
+		client, err := kube.NewInCluster()
+		if err != nil {
+			slog.Warn("fleet discovery disabled", "error", err)
+			return nil
+		}
+		return &fleet.KubeDiscoverer{Client: client, Namespace: kube.PodNamespace(), Selector: cfg.FleetSelector, Port: cfg.FleetPort}
 	default:
-		logLevel = slog.LevelInfo
+		return nil
+	}
+}
+
+// startKubeEvents mirrors recorded events to the Kubernetes API. Failure to
+// build an in-cluster client is logged but not fatal.
+func startKubeEvents() {
+	client, err := kube.NewInCluster()
+	if err != nil {
+		slog.Warn("kubernetes events disabled", "error", err)
+		return
 	}
 
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})
-	slog.SetDefault(slog.New(handler))
+	sink := events.NewKubeSink(client, kube.PodName(), kube.PodNamespace(), slog.LevelInfo)
+	events.Default.AddSink(sink)
+	go sink.Run(context.Background())
+	slog.Info("kubernetes events enabled", "pod", kube.PodName(), "namespace", kube.PodNamespace())
+}
+
+// newProfilingAgent builds a Pyroscope agent. Labels and types were checked by
+// Config.Validate.
+func newProfilingAgent(cfg *config.Config) *profiling.Agent {
+	labels, _ := cfg.ProfilingLabelSet()
+	types, _ := cfg.ProfilingTypeList()
+	return profiling.NewAgent(profiling.Config{
+		URL:      cfg.ProfilingURL,
+		AppName:  cfg.ProfilingAppName,
+		Labels:   labels,
+		Interval: cfg.ProfilingInterval,
+		Types:    types,
+	})
+}
+
+// startSidecarProxy forwards requests to the application container, burning
+// the configured overhead per request. The target was checked by
+// Config.Validate.
+func startSidecarProxy(ctx context.Context, cfg *config.Config) {
+	target, _ := cfg.SidecarProxyURL()
+	addr := net.JoinHostPort("", strconv.Itoa(cfg.SidecarProxyPort))
+	slog.Info("sidecar proxy starting", "port", cfg.SidecarProxyPort, "target", target.String(), "overhead", cfg.SidecarRequestOverhead)
+	if err := sidecar.NewProxy(target, cfg.SidecarRequestOverhead).Run(ctx, addr); err != nil {
+		slog.Error("sidecar proxy error", "error", err)
+	}
+}
+
+// startCustomMetrics serves the custom metrics API for the HPA, aggregating
+// values across the peers found by discoverer.
+func startCustomMetrics(ctx context.Context, cfg *config.Config, local *custommetrics.Local, discoverer fleet.Discoverer) {
+	addr := net.JoinHostPort("", strconv.Itoa(cfg.CustomMetricsPort))
+	slog.Info("custom metrics API starting", "port", cfg.CustomMetricsPort, "fleet", discoverer != nil, "self_signed", cfg.CustomMetricsCertFile == "")
+	srv := custommetrics.NewServer(local, kube.PodName(), kube.PodNamespace(), discoverer, cfg.FleetScheme())
+	if err := srv.Run(ctx, addr, cfg.CustomMetricsCertFile, cfg.CustomMetricsKeyFile); err != nil {
+		slog.Error("custom metrics API error", "error", err)
+	}
+}
+
+func startPprof(cfg *config.Config, authn *auth.Authenticator) {
+	addr := net.JoinHostPort(cfg.PprofBind, strconv.Itoa(cfg.PprofPort))
+	slog.Info("pprof server starting", "port", cfg.PprofPort, "bind", cfg.PprofBind, "auth", cfg.PprofAuth)
+	if err := http.ListenAndServe(addr, handlers.NewPprofHandler(authn, cfg.PprofAuth)); err != nil {
+		slog.Error("pprof server error", "error", err)
+	}
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ripta/hotpod/internal/handlers"
+)
+
+const runUsage = `Usage: hotpod run [flags]
+
+Runs a /work profile repeatedly for a fixed duration without starting the
+HTTP server, then prints a JSON summary. Intended for CronJobs and quick node
+soak tests.
+
+Flags:
+`
+
+// runSummary is printed when hotpod run finishes.
+type runSummary struct {
+	Profile       string `json:"profile"`
+	Duration      string `json:"duration"`
+	Elapsed       string `json:"elapsed"`
+	Concurrency   int    `json:"concurrency"`
+	Iterations    int64  `json:"iterations"`
+	CPUIterations int64  `json:"cpu_iterations"`
+	Interrupted   bool   `json:"interrupted,omitempty"`
+}
+
+// runCommand implements hotpod run and returns the process exit code. ctx is
+// cancelled on SIGINT or SIGTERM, which ends the run early with exit code 1.
+func runCommand(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("hotpod run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, runUsage)
+		fs.PrintDefaults()
+	}
+
+	profiles := handlers.NewWorkProfiles(os.TempDir(), 0, "")
+	profile := fs.String("profile", "web", "work profile: "+strings.Join(profiles.Names(), ", ")+", or one from -profiles")
+	profilesFile := fs.String("profiles", "", "JSON file of additional work profiles")
+	duration := fs.Duration("duration", time.Minute, "how long to run")
+	concurrency := fs.Int("concurrency", 1, "number of profile iterations to run at once")
+	variance := fs.Float64("variance", 0, "random variance applied to each iteration, 0 to 1")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *profilesFile != "" {
+		if err := defineWorkProfilesFile(profiles, *profilesFile); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+
+	switch {
+	case fs.NArg() > 0:
+		fmt.Fprintf(stderr, "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return 2
+	case !slices.Contains(profiles.Names(), *profile):
+		fmt.Fprintf(stderr, "profile must be one of: %s\n", strings.Join(profiles.Names(), ", "))
+		return 2
+	case *duration <= 0:
+		fmt.Fprintln(stderr, "duration must be positive")
+		return 2
+	case *concurrency < 1:
+		fmt.Fprintln(stderr, "concurrency must be at least 1")
+		return 2
+	case *variance < 0 || *variance > 1:
+		fmt.Fprintln(stderr, "variance must be between 0 and 1")
+		return 2
+	}
+
+	slog.Info("hotpod run starting", "profile", *profile, "duration", *duration, "concurrency", *concurrency, "variance", *variance)
+
+	runCtx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	var iterations, cpuIterations atomic.Int64
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range *concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for runCtx.Err() == nil {
+				resp, err := profiles.Run(runCtx, *profile, *variance, 0, 0)
+				if err != nil {
+					slog.Error("work iteration failed", "error", err)
+					return
+				}
+				cpuIterations.Add(resp.CPUIterations)
+				if !resp.Cancelled {
+					iterations.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	summary := runSummary{
+		Profile:       *profile,
+		Duration:      duration.String(),
+		Elapsed:       time.Since(start).Round(time.Millisecond).String(),
+		Concurrency:   *concurrency,
+		Iterations:    iterations.Load(),
+		CPUIterations: cpuIterations.Load(),
+		Interrupted:   ctx.Err() != nil,
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(summary); err != nil {
+		slog.Warn("failed to encode run summary", "error", err)
+	}
+
+	slog.Info("hotpod run complete", "iterations", summary.Iterations, "interrupted", summary.Interrupted)
+	if summary.Interrupted {
+		return 1
+	}
+	return 0
+}
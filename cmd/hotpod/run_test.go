@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	var stdout bytes.Buffer
+	code := runCommand(context.Background(), []string{"--profile", "api", "--duration", "300ms", "--concurrency", "2"}, &stdout, io.Discard)
+	if code != 0 {
+		t.Fatalf("runCommand() = %d, want 0", code)
+	}
+
+	var summary runSummary
+	if err := json.Unmarshal(stdout.Bytes(), &summary); err != nil {
+		t.Fatalf("failed to parse summary %q: %v", stdout.String(), err)
+	}
+	if summary.Profile != "api" || summary.Concurrency != 2 || summary.Duration != "300ms" {
+		t.Errorf("summary = %+v", summary)
+	}
+	if summary.Iterations == 0 || summary.CPUIterations == 0 {
+		t.Errorf("summary = %+v, want completed iterations", summary)
+	}
+	if summary.Interrupted {
+		t.Error("summary.Interrupted = true, want false")
+	}
+}
+
+func TestRunCommandInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var stdout bytes.Buffer
+	if code := runCommand(ctx, []string{"-duration", "1m"}, &stdout, io.Discard); code != 1 {
+		t.Errorf("runCommand() = %d, want 1", code)
+	}
+}
+
+func TestRunCommandUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{"-profile", "nope"},
+		{"-duration", "0s"},
+		{"-concurrency", "0"},
+		{"-variance", "2"},
+		{"extra"},
+		{"-unknown"},
+		{"-profiles", "/nonexistent/profiles.json"},
+	} {
+		if code := runCommand(context.Background(), args, io.Discard, io.Discard); code != 2 {
+			t.Errorf("runCommand(%q) = %d, want 2", args, code)
+		}
+	}
+}
+
+func TestRunCommandProfilesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"checkout","cpu":"1ms","latency":"1ms"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	code := runCommand(context.Background(), []string{"-profiles", path, "-profile", "checkout", "-duration", "50ms"}, &stdout, io.Discard)
+	if code != 0 {
+		t.Fatalf("runCommand() = %d, want 0", code)
+	}
+	var summary runSummary
+	if err := json.Unmarshal(stdout.Bytes(), &summary); err != nil {
+		t.Fatalf("failed to parse summary %q: %v", stdout.String(), err)
+	}
+	if summary.Profile != "checkout" || summary.Iterations == 0 {
+		t.Errorf("summary = %+v", summary)
+	}
+}
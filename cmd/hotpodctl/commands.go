@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/client"
+)
+
+// command runs a subcommand against c, writing its output to out.
+type command func(ctx context.Context, c *client.Client, args []string, out io.Writer) error
+
+var commands = map[string]command{
+	"status":     status,
+	"ready":      ready,
+	"error-rate": errorRate,
+	"reset":      reset,
+	"queue":      queueCmd,
+	"scenario":   scenario,
+}
+
+// newFlagSet returns a flag set for a subcommand whose errors are returned
+// rather than printed.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+func status(ctx context.Context, c *client.Client, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return usageError("status takes no arguments")
+	}
+
+	info, err := c.Info(ctx)
+	if err != nil {
+		return err
+	}
+
+	ready := "yes"
+	if _, err := c.Readyz(ctx); err != nil {
+		ready = "no (" + err.Error() + ")"
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "version:\t%s\n", info.Version)
+	if info.Build.Commit != "" {
+		fmt.Fprintf(tw, "commit:\t%s\n", info.Build.Commit)
+	}
+	fmt.Fprintf(tw, "state:\t%s\n", info.Lifecycle.State)
+	fmt.Fprintf(tw, "ready:\t%s\n", ready)
+	fmt.Fprintf(tw, "uptime:\t%s\n", info.Uptime)
+	fmt.Fprintf(tw, "in-flight:\t%d\n", info.Lifecycle.InFlightRequests)
+	if info.ClockSkew != "" {
+		fmt.Fprintf(tw, "clock skew:\t%s\n", info.ClockSkew)
+	}
+
+	if q, err := c.QueueStatus(ctx); err != nil {
+		fmt.Fprintf(tw, "queue:\tunavailable (%v)\n", err)
+	} else {
+		paused := ""
+		if q.Paused {
+			paused = " (paused)"
+		}
+		fmt.Fprintf(tw, "queue depth:\t%d%s\n", q.QueueDepth, paused)
+		fmt.Fprintf(tw, "queue workers:\t%d active, %d processed\n", q.ActiveWorkers, q.ItemsProcessedTotal)
+	}
+	return tw.Flush()
+}
+
+func ready(ctx context.Context, c *client.Client, args []string, out io.Writer) error {
+	var state *bool
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && (args[0] == "true" || args[0] == "false"):
+		v := args[0] == "true"
+		state = &v
+	default:
+		return usageError("ready takes an optional true or false")
+	}
+	return render(c.SetReady(ctx, state))(out)
+}
+
+func errorRate(ctx context.Context, c *client.Client, args []string, out io.Writer) error {
+	fs := newFlagSet("error-rate")
+	endpoint := fs.String("endpoint", "", "endpoint to fault (default all)")
+	codes := fs.String("codes", "", "comma-separated status codes (default 500)")
+	duration := fs.Duration("duration", 0, "how long the fault lasts (default forever)")
+	delay := fs.Duration("delay", 0, "delay before the error is returned")
+	if err := fs.Parse(args); err != nil {
+		return usageError("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return usageError("error-rate takes exactly one RATE between 0 and 1")
+	}
+
+	opts := client.ErrorRateOptions{Endpoint: *endpoint, Duration: *duration, Delay: *delay}
+	var err error
+	if opts.Rate, err = strconv.ParseFloat(fs.Arg(0), 64); err != nil {
+		return usageError("RATE must be a number")
+	}
+	if *codes != "" {
+		for _, s := range strings.Split(*codes, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return usageError("codes must be comma-separated integers")
+			}
+			opts.Codes = append(opts.Codes, code)
+		}
+	}
+	return render(c.SetErrorRate(ctx, opts))(out)
+}
+
+func reset(ctx context.Context, c *client.Client, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return usageError("reset takes no arguments")
+	}
+	return render(c.Reset(ctx))(out)
+}
+
+func queueCmd(ctx context.Context, c *client.Client, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return usageError("queue requires a subcommand: fill, pause, resume, clear, status, items, or workers")
+	}
+
+	switch sub, rest := args[0], args[1:]; sub {
+	case "fill":
+		fs := newFlagSet("queue fill")
+		processingTime := fs.Duration("processing-time", 0, "processing time per item")
+		priority := fs.String("priority", "", "item priority: high, normal, or low")
+		if err := fs.Parse(rest); err != nil {
+			return usageError("%v", err)
+		}
+		if fs.NArg() != 1 {
+			return usageError("queue fill takes exactly one COUNT")
+		}
+		count, err := strconv.Atoi(fs.Arg(0))
+		if err != nil || count < 1 {
+			return usageError("COUNT must be a positive integer")
+		}
+		return render(c.Enqueue(ctx, client.EnqueueOptions{Count: count, ProcessingTime: *processingTime, Priority: *priority}))(out)
+	case "pause":
+		return render(c.PauseQueue(ctx))(out)
+	case "resume":
+		return render(c.ResumeQueue(ctx))(out)
+	case "clear":
+		return render(c.ClearQueue(ctx))(out)
+	case "status":
+		return render(c.QueueStatus(ctx))(out)
+	case "workers":
+		if len(rest) != 1 {
+			return usageError("queue workers takes exactly one COUNT")
+		}
+		count, err := strconv.Atoi(rest[0])
+		if err != nil || count < 0 {
+			return usageError("COUNT must be a non-negative integer")
+		}
+		return render(c.ScaleWorkers(ctx, count))(out)
+	case "items":
+		fs := newFlagSet("queue items")
+		priority := fs.String("priority", "", "only list high, normal, or low items")
+		offset := fs.Int("offset", 0, "number of items to skip")
+		limit := fs.Int("limit", 0, "maximum number of items to list (default 100)")
+		if err := fs.Parse(rest); err != nil {
+			return usageError("%v", err)
+		}
+		switch fs.NArg() {
+		case 0:
+			return render(c.QueueItems(ctx, client.QueueItemsOptions{Priority: *priority, Offset: *offset, Limit: *limit}))(out)
+		case 1:
+			return render(c.QueueItem(ctx, fs.Arg(0)))(out)
+		default:
+			return usageError("queue items takes at most one ID")
+		}
+	default:
+		return usageError("unknown queue subcommand %q", sub)
+	}
+}
+
+func scenario(ctx context.Context, c *client.Client, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return usageError("scenario requires a subcommand: start, stop, status, or mark")
+	}
+
+	switch sub, rest := args[0], args[1:]; sub {
+	case "start":
+		fs := newFlagSet("scenario start")
+		speed := fs.Float64("speed", 0, "replay speed multiplier")
+		loop := fs.Bool("loop", false, "restart the log when it ends")
+		format := fs.String("format", "", "log format: json or csv (default from the file extension)")
+		if err := fs.Parse(rest); err != nil {
+			return usageError("%v", err)
+		}
+		if fs.NArg() != 1 {
+			return usageError("scenario start takes exactly one FILE")
+		}
+
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if *format == "" && strings.EqualFold(filepath.Ext(fs.Arg(0)), ".csv") {
+			*format = "csv"
+		}
+		return render(c.StartReplay(ctx, f, client.ReplayOptions{Format: *format, Speed: *speed, Loop: *loop}))(out)
+	case "stop":
+		return render(c.StopReplay(ctx))(out)
+	case "status":
+		return render(c.Replay(ctx))(out)
+	case "mark":
+		if len(rest) == 0 {
+			return usageError("scenario mark takes a MESSAGE")
+		}
+		return render(c.CreateEvent(ctx, api.CreateEventRequest{Message: strings.Join(rest, " ")}))(out)
+	default:
+		return usageError("unknown scenario subcommand %q", sub)
+	}
+}
+
+// render returns a function that writes resp to out as indented JSON, so a
+// client method's results can be passed straight through.
+func render[T any](resp *T, err error) func(out io.Writer) error {
+	return func(out io.Writer) error {
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(resp, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	}
+}
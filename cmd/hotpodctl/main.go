@@ -0,0 +1,124 @@
+// Command hotpodctl drives hotpod experiments through its HTTP API, either
+// directly or through kubectl port-forward.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/client"
+)
+
+// version is set via ldflags at build time.
+var version = "dev"
+
+const usage = `Usage: hotpodctl [flags] <command> [args]
+
+Commands:
+  status                          Show lifecycle, readiness, and queue state
+  ready [true|false]              Force readiness; with no argument, toggle the override
+  error-rate [flags] RATE         Inject errors (-endpoint, -codes, -duration, -delay)
+  reset                           Clear all injected faults and overrides
+  queue fill [flags] COUNT        Enqueue items (-processing-time, -priority)
+  queue pause|resume|clear|status
+  queue workers COUNT             Resize the running worker pool
+  queue items [flags] [ID]        List queued items, or show one (-priority, -offset, -limit)
+  scenario start [flags] FILE     Replay a request log (-speed, -loop, -format)
+  scenario stop|status
+  scenario mark MESSAGE           Add a marker to the event timeline
+  version                         Print the hotpodctl version
+
+Flags:
+`
+
+// errUsage reports invalid command-line arguments.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes hotpodctl and returns the process exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("hotpodctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	addr := fs.String("addr", envOr("HOTPOD_ADDR", "http://localhost:8080"), "hotpod base URL (env HOTPOD_ADDR)")
+	token := fs.String("token", os.Getenv("HOTPOD_ADMIN_TOKEN"), "admin token (env HOTPOD_ADMIN_TOKEN)")
+	timeout := fs.Duration("timeout", 30*time.Second, "per-request timeout")
+	pf := &portForward{}
+	fs.StringVar(&pf.target, "target", "", "port-forward to this kubectl resource (e.g. svc/hotpod) instead of using -addr")
+	fs.StringVar(&pf.namespace, "namespace", "", "namespace of -target")
+	fs.StringVar(&pf.context, "context", "", "kubeconfig context for -target")
+	fs.IntVar(&pf.remotePort, "remote-port", 8080, "hotpod port in the pod for -target")
+	fs.StringVar(&pf.kubectl, "kubectl", "kubectl", "kubectl binary for -target")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cmdName, cmdArgs := fs.Arg(0), fs.Args()[1:]
+	if cmdName == "version" {
+		fmt.Fprintln(stdout, version)
+		return 0
+	}
+
+	cmd, ok := commands[cmdName]
+	if !ok {
+		fmt.Fprintf(stderr, "hotpodctl: unknown command %q\n\n", cmdName)
+		fs.Usage()
+		return 2
+	}
+
+	base := *addr
+	if pf.target != "" {
+		forwardCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var err error
+		if base, err = pf.start(forwardCtx); err != nil {
+			fmt.Fprintf(stderr, "hotpodctl: %v\n", err)
+			return 1
+		}
+	}
+
+	c := client.New(client.Config{BaseURL: base, Token: *token, HTTPClient: &http.Client{Timeout: *timeout}})
+
+	if err := cmd(ctx, c, cmdArgs, stdout); err != nil {
+		fmt.Fprintf(stderr, "hotpodctl %s: %v\n", cmdName, err)
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
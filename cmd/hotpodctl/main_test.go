@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type recordedRequest struct {
+	method, path, query, auth, body string
+}
+
+func newRecordingServer(t *testing.T) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var reqs []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		reqs = append(reqs, recordedRequest{r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get("Authorization"), string(body)})
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"version":"v1.2.3","uptime":"1m0s","lifecycle":{"state":"ready","in_flight_requests":2}}`))
+		case "/readyz":
+			w.Write([]byte(`{"status":"ok"}`))
+		case "/queue/status":
+			w.Write([]byte(`{"queue_depth":7,"active_workers":1,"items_processed_total":40,"paused":true}`))
+		case "/admin/error-rate":
+			if r.URL.Query().Get("rate") == "2" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"rate must be between 0 and 1","code":"INVALID_PARAMETER"}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reqs
+}
+
+func TestRunCommands(t *testing.T) {
+	replayLog := filepath.Join(t.TempDir(), "log.csv")
+	if err := os.WriteFile(replayLog, []byte("0,GET,/cpu\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     []recordedRequest
+	}{
+		{
+			name: "ready toggle",
+			args: []string{"ready"},
+			want: []recordedRequest{{method: "POST", path: "/admin/ready"}},
+		},
+		{
+			name: "ready false",
+			args: []string{"ready", "false"},
+			want: []recordedRequest{{method: "POST", path: "/admin/ready", query: "state=false"}},
+		},
+		{
+			name:     "ready invalid",
+			args:     []string{"ready", "maybe"},
+			wantCode: 2,
+		},
+		{
+			name: "error rate with flags",
+			args: []string{"error-rate", "-endpoint", "/cpu", "-codes", "503", "-duration", "5m", "0.5"},
+			want: []recordedRequest{{method: "POST", path: "/admin/error-rate", query: "codes=503&duration=5m0s&endpoint=%2Fcpu&rate=0.5"}},
+		},
+		{
+			name:     "error rate rejected by server",
+			args:     []string{"error-rate", "2"},
+			wantCode: 1,
+			want:     []recordedRequest{{method: "POST", path: "/admin/error-rate", query: "rate=2"}},
+		},
+		{
+			name: "queue fill",
+			args: []string{"queue", "fill", "-priority", "high", "50"},
+			want: []recordedRequest{{method: "POST", path: "/queue/enqueue", query: "count=50&priority=high"}},
+		},
+		{
+			name:     "queue fill non-integer",
+			args:     []string{"queue", "fill", "lots"},
+			wantCode: 2,
+		},
+		{
+			name: "queue workers",
+			args: []string{"queue", "workers", "0"},
+			want: []recordedRequest{{method: "POST", path: "/queue/workers", query: "count=0"}},
+		},
+		{
+			name: "queue items",
+			args: []string{"queue", "items", "-priority", "low", "-limit", "10"},
+			want: []recordedRequest{{method: "GET", path: "/queue/items", query: "limit=10&priority=low"}},
+		},
+		{
+			name: "queue item",
+			args: []string{"queue", "items", "123-0"},
+			want: []recordedRequest{{method: "GET", path: "/queue/items/123-0"}},
+		},
+		{
+			name: "queue pause",
+			args: []string{"queue", "pause"},
+			want: []recordedRequest{{method: "POST", path: "/admin/queue/pause"}},
+		},
+		{
+			name: "scenario start",
+			args: []string{"scenario", "start", "-speed", "2", replayLog},
+			want: []recordedRequest{{method: "POST", path: "/admin/replay", query: "format=csv&speed=2", body: "0,GET,/cpu\n"}},
+		},
+		{
+			name: "scenario stop",
+			args: []string{"scenario", "stop"},
+			want: []recordedRequest{{method: "DELETE", path: "/admin/replay"}},
+		},
+		{
+			name: "scenario mark",
+			args: []string{"scenario", "mark", "phase", "two"},
+			want: []recordedRequest{{method: "POST", path: "/events", body: `{"message":"phase two"}`}},
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"explode"},
+			wantCode: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, reqs := newRecordingServer(t)
+
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-addr", srv.URL}, tt.args...)
+			if code := run(context.Background(), args, &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+
+			if len(*reqs) != len(tt.want) {
+				t.Fatalf("got %d requests %+v, want %d", len(*reqs), *reqs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := (*reqs)[i]; got != want {
+					t.Errorf("request %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunStatus(t *testing.T) {
+	srv, reqs := newRecordingServer(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-addr", srv.URL, "-token", "secret", "status"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d (stderr: %s)", code, stderr.String())
+	}
+
+	for _, want := range []string{"v1.2.3", "ready", "in-flight:", "7 (paused)"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("status output missing %q:\n%s", want, stdout.String())
+		}
+	}
+	for _, r := range *reqs {
+		if r.auth != "Bearer secret" {
+			t.Errorf("%s %s sent Authorization %q", r.method, r.path, r.auth)
+		}
+	}
+}
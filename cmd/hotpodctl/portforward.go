@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// portForwardTimeout bounds how long kubectl may take to open the listener.
+const portForwardTimeout = 30 * time.Second
+
+// forwardingLine matches kubectl's readiness message, e.g.
+// "Forwarding from 127.0.0.1:54321 -> 8080".
+var forwardingLine = regexp.MustCompile(`^Forwarding from 127\.0\.0\.1:(\d+) -> `)
+
+// portForward is where kubectl port-forward should connect.
+type portForward struct {
+	// kubectl is the kubectl binary to run
+	kubectl string
+	// target is a kubectl resource, e.g. svc/hotpod or pod/hotpod-abc
+	target string
+	// namespace and context select the cluster; empty uses kubeconfig defaults
+	namespace string
+	context   string
+	// remotePort is the hotpod port in the pod
+	remotePort int
+}
+
+func (pf *portForward) args() []string {
+	args := []string{"port-forward", pf.target, ":" + strconv.Itoa(pf.remotePort)}
+	if pf.namespace != "" {
+		args = append(args, "--namespace", pf.namespace)
+	}
+	if pf.context != "" {
+		args = append(args, "--context", pf.context)
+	}
+	return args
+}
+
+// start runs kubectl port-forward on an ephemeral local port and returns the
+// local base URL once the listener is ready. kubectl uses the caller's
+// kubeconfig, so any credential plugin it supports works here. The forward
+// stops when ctx is done.
+func (pf *portForward) start(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, pf.kubectl, pf.args()...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	var stderr syncBuffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("starting kubectl: %w", err)
+	}
+
+	ready := make(chan string, 1)
+	exited := make(chan error, 1)
+	go func() {
+		// Keep draining after the listener is ready; kubectl logs every
+		// forwarded connection and would block on a full pipe.
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if m := forwardingLine.FindStringSubmatch(scanner.Text()); m != nil {
+				select {
+				case ready <- m[1]:
+				default:
+				}
+			}
+		}
+		exited <- cmd.Wait()
+	}()
+
+	timer := time.NewTimer(portForwardTimeout)
+	defer timer.Stop()
+
+	select {
+	case port := <-ready:
+		return "http://127.0.0.1:" + port, nil
+	case err := <-exited:
+		msg := bytes.TrimSpace(stderr.Bytes())
+		if len(msg) > 0 {
+			return "", fmt.Errorf("kubectl port-forward exited: %s", msg)
+		}
+		if err == nil {
+			err = errors.New("exited before forwarding")
+		}
+		return "", fmt.Errorf("kubectl port-forward: %w", err)
+	case <-timer.C:
+		return "", fmt.Errorf("kubectl port-forward did not become ready within %s", portForwardTimeout)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes and reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes())
+}
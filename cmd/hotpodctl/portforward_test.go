@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeKubectl writes a script standing in for kubectl that records its
+// arguments and runs body.
+func fakeKubectl(t *testing.T, body string) (path, argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	path = filepath.Join(dir, "kubectl")
+	argsFile = filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\n" + body
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path, argsFile
+}
+
+func TestPortForwardStart(t *testing.T) {
+	kubectl, argsFile := fakeKubectl(t, "echo 'Forwarding from 127.0.0.1:41234 -> 8080'\necho 'Forwarding from [::1]:41234 -> 8080'\nexec sleep 30\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pf := &portForward{kubectl: kubectl, target: "svc/hotpod", namespace: "chaos", context: "staging", remotePort: 8080}
+	base, err := pf.start(ctx)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if base != "http://127.0.0.1:41234" {
+		t.Errorf("base = %q, want http://127.0.0.1:41234", base)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(args)), "port-forward svc/hotpod :8080 --namespace chaos --context staging"; got != want {
+		t.Errorf("kubectl args = %q, want %q", got, want)
+	}
+}
+
+func TestPortForwardFailure(t *testing.T) {
+	kubectl, _ := fakeKubectl(t, "echo 'error: services \"hotpod\" not found' >&2\nexit 1\n")
+
+	pf := &portForward{kubectl: kubectl, target: "svc/hotpod", remotePort: 8080}
+	_, err := pf.start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("start error = %v, want kubectl's message", err)
+	}
+}
@@ -0,0 +1,76 @@
+// Package audit keeps a bounded trail of mutating admin API calls so shared
+// test clusters can see who changed what and when.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Entry describes a single admin API mutation.
+type Entry = api.AuditEntry
+
+// DefaultCapacity is the number of entries retained by default.
+const DefaultCapacity = 500
+
+// Log is a thread-safe, bounded list of audit entries.
+type Log struct {
+	mu       sync.RWMutex
+	entries  []Entry
+	capacity int
+	seq      uint64
+}
+
+// New creates an audit log retaining at most capacity entries.
+func New(capacity int) *Log {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Log{capacity: capacity}
+}
+
+// Record appends an entry, assigning its ID, and returns it.
+func (l *Log) Record(e Entry) Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	e.ID = l.seq
+	if len(l.entries) >= l.capacity {
+		copy(l.entries, l.entries[1:])
+		l.entries = l.entries[:len(l.entries)-1]
+	}
+	l.entries = append(l.entries, e)
+	return e
+}
+
+// List returns entries with an ID greater than afterID, oldest first, capped
+// to the most recent limit entries when limit is positive.
+func (l *Log) List(afterID uint64, limit int) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make([]Entry, 0, len(l.entries))
+	for _, e := range l.entries {
+		if e.ID > afterID {
+			result = append(result, e)
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result
+}
+
+// Fingerprint returns a short, non-reversible identifier for a credential.
+// Empty credentials return an empty fingerprint.
+func Fingerprint(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:6])
+}
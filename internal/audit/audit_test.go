@@ -0,0 +1,56 @@
+package audit
+
+import "testing"
+
+func TestLogRecordAndList(t *testing.T) {
+	l := New(10)
+	l.Record(Entry{Method: "POST", Path: "/admin/ready"})
+	l.Record(Entry{Method: "POST", Path: "/admin/gc"})
+
+	got := l.List(0, 0)
+	if len(got) != 2 {
+		t.Fatalf("List() returned %d entries, want 2", len(got))
+	}
+	if got[0].ID != 1 || got[1].Path != "/admin/gc" {
+		t.Errorf("unexpected entries %+v", got)
+	}
+
+	if got := l.List(1, 0); len(got) != 1 {
+		t.Errorf("List(afterID=1) returned %d entries, want 1", len(got))
+	}
+	if got := l.List(0, 1); len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("List(limit=1) = %+v, want most recent entry", got)
+	}
+}
+
+func TestLogCapacity(t *testing.T) {
+	l := New(2)
+	for range 5 {
+		l.Record(Entry{Method: "POST"})
+	}
+
+	got := l.List(0, 0)
+	if len(got) != 2 {
+		t.Fatalf("retained %d entries, want 2", len(got))
+	}
+	if got[0].ID != 4 || got[1].ID != 5 {
+		t.Errorf("retained IDs %d,%d, want 4,5", got[0].ID, got[1].ID)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	if Fingerprint("") != "" {
+		t.Error("empty token should have empty fingerprint")
+	}
+
+	a, b := Fingerprint("secret-a"), Fingerprint("secret-b")
+	if len(a) != 12 {
+		t.Errorf("fingerprint length = %d, want 12", len(a))
+	}
+	if a == b {
+		t.Error("different tokens should have different fingerprints")
+	}
+	if a != Fingerprint("secret-a") {
+		t.Error("fingerprint should be deterministic")
+	}
+}
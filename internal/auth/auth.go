@@ -0,0 +1,235 @@
+// Package auth authenticates admin and chaos API callers using static tokens
+// with role scopes, or bearer JWTs issued by an OIDC provider.
+package auth
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ripta/hotpod/internal/audit"
+)
+
+// Role is a permission scope. Roles are hierarchical: chaos implies mutate,
+// which implies read.
+type Role string
+
+const (
+	// RoleRead allows read-only admin endpoints such as GET /admin/config.
+	RoleRead Role = "read"
+	// RoleMutate allows admin endpoints that change runtime state.
+	RoleMutate Role = "mutate"
+	// RoleChaos allows destructive /fault/* endpoints.
+	RoleChaos Role = "chaos"
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleRead:
+		return 1
+	case RoleMutate:
+		return 2
+	case RoleChaos:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Allows reports whether a principal holding r may perform an action that
+// requires the given role.
+func (r Role) Allows(required Role) bool {
+	return r.rank() >= required.rank() && r.rank() > 0
+}
+
+// ParseRole validates a role name.
+func ParseRole(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	if r.rank() == 0 {
+		return "", fmt.Errorf("invalid role %q, must be one of: read, mutate, chaos", s)
+	}
+	return r, nil
+}
+
+// Errors returned by Authorize.
+var (
+	ErrUnauthenticated = errors.New("invalid or missing admin token")
+	ErrForbidden       = errors.New("token does not have the required role")
+)
+
+// Token is a named credential with a role.
+type Token struct {
+	Name  string
+	Role  Role
+	Value string
+}
+
+// Principal identifies an authenticated caller.
+type Principal struct {
+	Name        string `json:"name"`
+	Role        Role   `json:"role"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// anonymous is the principal used when authentication is disabled.
+var anonymous = &Principal{Name: "anonymous", Role: RoleChaos}
+
+// Authenticator validates credentials against a set of tokens.
+type Authenticator struct {
+	tokens []Token
+	jwt    *JWTVerifier
+	// scoped is true when role-scoped tokens were configured, in which case
+	// chaos endpoints also require authentication.
+	scoped bool
+}
+
+// New creates an authenticator. The legacy token, if non-empty, is granted the
+// chaos role. When no tokens are configured, all requests are allowed.
+func New(legacyToken string, scoped []Token) *Authenticator {
+	a := &Authenticator{scoped: len(scoped) > 0}
+	if legacyToken != "" {
+		a.tokens = append(a.tokens, Token{Name: "admin", Role: RoleChaos, Value: legacyToken})
+	}
+	a.tokens = append(a.tokens, scoped...)
+	return a
+}
+
+// UseJWT additionally accepts bearer JWTs validated by v. Roles then come from
+// token claims, so chaos endpoints also require authentication.
+func (a *Authenticator) UseJWT(v *JWTVerifier) {
+	a.jwt = v
+	a.scoped = true
+}
+
+// Enabled reports whether any tokens or a JWT verifier are configured.
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (len(a.tokens) > 0 || a.jwt != nil)
+}
+
+// Scoped reports whether role-scoped tokens are configured.
+func (a *Authenticator) Scoped() bool {
+	return a != nil && a.scoped
+}
+
+// Credential extracts the presented credential from X-Admin-Token or an
+// Authorization: Bearer header.
+func Credential(r *http.Request) string {
+	if v := r.Header.Get("X-Admin-Token"); v != "" {
+		return v
+	}
+	if v, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+// Authenticate resolves the caller. It returns ErrUnauthenticated if tokens
+// are configured and the presented credential does not match any of them.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if !a.Enabled() {
+		return anonymous, nil
+	}
+
+	cred := Credential(r)
+	if cred == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	// Compare against every token so timing does not reveal which matched.
+	var match *Token
+	for i := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(cred), []byte(a.tokens[i].Value)) == 1 && match == nil {
+			match = &a.tokens[i]
+		}
+	}
+	if match == nil {
+		if a.jwt != nil && looksLikeJWT(cred) {
+			p, err := a.jwt.Verify(r.Context(), cred)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+			}
+			p.Fingerprint = audit.Fingerprint(cred)
+			return p, nil
+		}
+		return nil, ErrUnauthenticated
+	}
+
+	return &Principal{Name: match.Name, Role: match.Role, Fingerprint: audit.Fingerprint(cred)}, nil
+}
+
+// Authorize authenticates the caller and checks that it holds the required role.
+func (a *Authenticator) Authorize(r *http.Request, required Role) (*Principal, error) {
+	p, err := a.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Role.Allows(required) {
+		return p, ErrForbidden
+	}
+	return p, nil
+}
+
+// ParseTokens parses a comma-separated list of name:role:token entries.
+func ParseTokens(spec string) ([]Token, error) {
+	var tokens []Token
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tok, err := parseToken(entry)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+// LoadTokensFile reads name:role:token entries, one per line. Blank lines and
+// lines starting with # are ignored.
+func LoadTokensFile(path string) ([]Token, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening admin tokens file: %w", err)
+	}
+	defer f.Close()
+
+	var tokens []Token
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		tok, err := parseToken(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		tokens = append(tokens, tok)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading admin tokens file: %w", err)
+	}
+	return tokens, nil
+}
+
+func parseToken(entry string) (Token, error) {
+	parts := strings.SplitN(entry, ":", 3)
+	if len(parts) != 3 {
+		return Token{}, errors.New("admin token entries must be name:role:token")
+	}
+	name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[2])
+	if name == "" || value == "" {
+		return Token{}, errors.New("admin token name and value must not be empty")
+	}
+	role, err := ParseRole(parts[1])
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{Name: name, Role: role, Value: value}, nil
+}
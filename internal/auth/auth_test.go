@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type roleAllowsTest struct {
+	have     Role
+	required Role
+	want     bool
+}
+
+var roleAllowsTests = []roleAllowsTest{
+	{RoleRead, RoleRead, true},
+	{RoleRead, RoleMutate, false},
+	{RoleMutate, RoleRead, true},
+	{RoleMutate, RoleChaos, false},
+	{RoleChaos, RoleMutate, true},
+	{Role("bogus"), RoleRead, false},
+}
+
+func TestRoleAllows(t *testing.T) {
+	for _, tt := range roleAllowsTests {
+		if got := tt.have.Allows(tt.required); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %v, want %v", tt.have, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticatorDisabled(t *testing.T) {
+	a := New("", nil)
+	if a.Enabled() {
+		t.Error("Enabled() should be false with no tokens")
+	}
+	p, err := a.Authorize(httptest.NewRequest("GET", "/", nil), RoleChaos)
+	if err != nil || p.Name != "anonymous" {
+		t.Errorf("Authorize() = %v, %v; want anonymous principal", p, err)
+	}
+}
+
+func TestAuthenticatorScoped(t *testing.T) {
+	tokens, err := ParseTokens("dash:read:r-token, ops:mutate:m-token,chaos:chaos:c-token")
+	if err != nil {
+		t.Fatalf("ParseTokens: %v", err)
+	}
+	a := New("legacy", tokens)
+	if !a.Scoped() {
+		t.Error("Scoped() should be true")
+	}
+
+	tests := []struct {
+		header   string
+		value    string
+		required Role
+		wantErr  error
+		wantName string
+	}{
+		{"X-Admin-Token", "r-token", RoleRead, nil, "dash"},
+		{"X-Admin-Token", "r-token", RoleMutate, ErrForbidden, "dash"},
+		{"Authorization", "Bearer m-token", RoleMutate, nil, "ops"},
+		{"X-Admin-Token", "m-token", RoleChaos, ErrForbidden, "ops"},
+		{"X-Admin-Token", "c-token", RoleChaos, nil, "chaos"},
+		{"X-Admin-Token", "legacy", RoleChaos, nil, "admin"},
+		{"X-Admin-Token", "wrong", RoleRead, ErrUnauthenticated, ""},
+		{"", "", RoleRead, ErrUnauthenticated, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		p, err := a.Authorize(req, tt.required)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s=%q required=%s: err = %v, want %v", tt.header, tt.value, tt.required, err, tt.wantErr)
+			continue
+		}
+		if tt.wantName != "" && (p == nil || p.Name != tt.wantName) {
+			t.Errorf("%s=%q: principal = %+v, want %s", tt.header, tt.value, p, tt.wantName)
+		}
+	}
+}
+
+var invalidTokenSpecs = []string{
+	"noroles",
+	"name:admin:token",
+	":read:token",
+	"name:read:",
+}
+
+func TestParseTokensInvalid(t *testing.T) {
+	for _, spec := range invalidTokenSpecs {
+		if _, err := ParseTokens(spec); err == nil {
+			t.Errorf("ParseTokens(%q) should error", spec)
+		}
+	}
+}
+
+func TestLoadTokensFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	content := "# dashboards\ngrafana:read:abc\n\nops:mutate:def:with:colons\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := LoadTokensFile(path)
+	if err != nil {
+		t.Fatalf("LoadTokensFile: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("got %d tokens, want 2", len(tokens))
+	}
+	if tokens[1].Value != "def:with:colons" {
+		t.Errorf("token value = %q, want colons preserved", tokens[1].Value)
+	}
+}
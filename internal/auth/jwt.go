@@ -0,0 +1,456 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/wallclock"
+)
+
+// DefaultRoleClaim is the JWT claim consulted for the caller's role.
+const DefaultRoleClaim = "hotpod_role"
+
+// jwksRefreshInterval bounds how stale cached signing keys may become, and
+// jwksMinRefresh rate-limits refetches triggered by unknown key IDs.
+const (
+	jwksRefreshInterval = time.Hour
+	jwksMinRefresh      = time.Minute
+)
+
+// clockSkew is the leeway applied to exp and nbf checks.
+const clockSkew = 30 * time.Second
+
+// JWTConfig configures bearer token validation against an OIDC issuer.
+type JWTConfig struct {
+	// Issuer is the expected iss claim. Unless JWKSURL is set, signing keys
+	// are discovered from Issuer + "/.well-known/openid-configuration".
+	Issuer string
+	// Audience, if non-empty, must appear in the aud claim.
+	Audience string
+	// JWKSURL overrides discovery of the issuer's key set.
+	JWKSURL string
+	// RoleClaim names the claim holding the role (default: hotpod_role). The
+	// claim may be a string or a list of strings; the highest role wins.
+	RoleClaim string
+	// DefaultRole is granted to valid tokens without a role claim. Empty means
+	// such tokens are authenticated but authorized for nothing.
+	DefaultRole Role
+	// HTTPClient is used to fetch discovery and key documents.
+	HTTPClient *http.Client
+}
+
+// JWTVerifier validates signed JWTs using keys published by an OIDC issuer.
+type JWTVerifier struct {
+	cfg JWTConfig
+	// now is the skewed clock exp and nbf are also checked against; key
+	// cache bookkeeping uses the real clock
+	now func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	jwksURL string
+	// fetched is when keys were last fetched, and attempted when a fetch
+	// was last started, successful or not
+	fetched   time.Time
+	attempted time.Time
+	// inflight is the fetch in progress, shared by every request waiting
+	// on it
+	inflight *jwksFetch
+}
+
+// jwksFetch is a key set fetch that concurrent requests wait on together.
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWTVerifier creates a verifier. Keys are fetched lazily on first use.
+func NewJWTVerifier(cfg JWTConfig) *JWTVerifier {
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = DefaultRoleClaim
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	return &JWTVerifier{cfg: cfg, now: wallclock.Now, jwksURL: cfg.JWKSURL}
+}
+
+// looksLikeJWT reports whether a credential has the three-part JWS compact form.
+func looksLikeJWT(cred string) bool {
+	return strings.Count(cred, ".") == 2
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the token's signature and standard claims and returns the
+// resulting principal.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed JWT")
+	}
+
+	var hdr jwtHeader
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, fmt.Errorf("decoding JWT header: %w", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWT signature: %w", err)
+	}
+
+	key, err := v.key(ctx, hdr.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(hdr.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("decoding JWT claims: %w", err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+
+	name, _ := claims["sub"].(string)
+	if name == "" {
+		name = "jwt"
+	}
+	return &Principal{Name: name, Role: v.role(claims)}, nil
+}
+
+func (v *JWTVerifier) checkClaims(claims map[string]any) error {
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != v.cfg.Issuer {
+		return fmt.Errorf("unexpected issuer %q", iss)
+	}
+
+	if v.cfg.Audience != "" && !hasAudience(claims["aud"], v.cfg.Audience) {
+		return fmt.Errorf("token audience does not include %q", v.cfg.Audience)
+	}
+
+	// A simulated skew can only make validation stricter: exp is checked
+	// against the later of the node and skewed clocks, and nbf against the
+	// earlier, so a skew never revives an expired token.
+	node, skewed := time.Now(), v.now()
+	latest, earliest := node, skewed
+	if skewed.After(node) {
+		latest, earliest = skewed, node
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token has no exp claim")
+	}
+	if latest.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return errors.New("token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && earliest.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token is not yet valid")
+	}
+	return nil
+}
+
+func hasAudience(aud any, want string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == want
+	case []any:
+		for _, v := range a {
+			if s, ok := v.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *JWTVerifier) role(claims map[string]any) Role {
+	var best Role
+	consider := func(s string) {
+		if r, err := ParseRole(s); err == nil && r.rank() > best.rank() {
+			best = r
+		}
+	}
+
+	switch c := claims[v.cfg.RoleClaim].(type) {
+	case string:
+		consider(c)
+	case []any:
+		for _, item := range c {
+			if s, ok := item.(string); ok {
+				consider(s)
+			}
+		}
+	}
+
+	if best == "" {
+		return v.cfg.DefaultRole
+	}
+	return best
+}
+
+// key returns the signing key with the given ID, refreshing the key set when
+// it is stale or the ID is unknown. Fetches happen outside the lock, one at a
+// time, and are started at most once per jwksMinRefresh whether or not they
+// succeed, so a slow or failing issuer cannot serialize or amplify requests.
+func (v *JWTVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	now := time.Now()
+	k, ok := v.lookup(kid)
+	if ok && now.Sub(v.fetched) <= jwksRefreshInterval {
+		v.mu.Unlock()
+		return k, nil
+	}
+
+	f := v.inflight
+	if f == nil {
+		if now.Sub(v.attempted) < jwksMinRefresh {
+			v.mu.Unlock()
+			if ok {
+				// Keep serving from the stale set rather than failing closed
+				// on a transient issuer outage.
+				return k, nil
+			}
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		f = &jwksFetch{done: make(chan struct{})}
+		v.inflight = f
+		v.attempted = now
+		jwksURL := v.jwksURL
+		v.mu.Unlock()
+
+		// Other requests share this fetch, so it must outlive this one.
+		keys, jwksURL, err := v.fetch(context.WithoutCancel(ctx), jwksURL)
+
+		v.mu.Lock()
+		if err == nil {
+			v.keys = keys
+			v.jwksURL = jwksURL
+			v.fetched = now
+		}
+		f.err = err
+		v.inflight = nil
+		v.mu.Unlock()
+		close(f.done)
+	} else {
+		v.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	v.mu.Lock()
+	k2, ok2 := v.lookup(kid)
+	v.mu.Unlock()
+	switch {
+	case ok2:
+		return k2, nil
+	case ok:
+		return k, nil
+	case f.err != nil:
+		return nil, f.err
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookup finds a key by ID. An empty ID matches only when exactly one key is
+// published.
+func (v *JWTVerifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k, true
+		}
+	}
+	k, ok := v.keys[kid]
+	return k, ok
+}
+
+// fetch retrieves the key set from jwksURL, discovering it from the issuer
+// first if empty, and returns the keys and the URL they came from.
+func (v *JWTVerifier) fetch(ctx context.Context, jwksURL string) (map[string]crypto.PublicKey, string, error) {
+	if jwksURL == "" {
+		var doc struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, v.cfg.Issuer+"/.well-known/openid-configuration", &doc); err != nil {
+			return nil, "", fmt.Errorf("fetching OIDC discovery document: %w", err)
+		}
+		if doc.JWKSURI == "" {
+			return nil, "", errors.New("OIDC discovery document has no jwks_uri")
+		}
+		jwksURL = doc.JWKSURI
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(ctx, jwksURL, &set); err != nil {
+		return nil, "", fmt.Errorf("fetching JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// Skip key types we do not understand rather than rejecting the set.
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, "", errors.New("JWKS contains no usable signing keys")
+	}
+	return keys, jwksURL, nil
+}
+
+func (v *JWTVerifier) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jwk is the subset of RFC 7517 needed for RSA and EC verification keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %s does not match RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, sig); err != nil {
+			return errors.New("invalid JWT signature")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s does not match EC key", alg)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid JWT signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid JWT signature")
+		}
+	default:
+		return errors.New("unsupported signing key")
+	}
+	return nil
+}
+
+func decodeSegment(seg string, out any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/wallclock"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	hdr, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	body, _ := json.Marshal(claims)
+	signed := b64(hdr) + "." + b64(body)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64(sig)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	hdr, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": kid})
+	body, _ := json.Marshal(claims)
+	signed := b64(hdr) + "." + b64(body)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signed + "." + b64(sig)
+}
+
+// newIssuer serves a discovery document and JWKS for the given keys.
+func newIssuer(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": srv.URL, "jwks_uri": srv.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec1", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes())},
+		}})
+	})
+	return srv, &fetches
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	srv, fetches := newIssuer(t, rsaKey, ecKey)
+
+	v := NewJWTVerifier(JWTConfig{Issuer: srv.URL, Audience: "hotpod"})
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{"iss": srv.URL, "aud": "hotpod", "sub": "ci-runner", "exp": exp}
+		for k, val := range extra {
+			c[k] = val
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantErr  bool
+		wantRole Role
+	}{
+		{"rsa with role", signRS256(t, rsaKey, "rsa1", claims(map[string]any{"hotpod_role": "mutate"})), false, RoleMutate},
+		{"ec with role list", signES256(t, ecKey, "ec1", claims(map[string]any{"hotpod_role": []string{"read", "chaos"}})), false, RoleChaos},
+		{"audience list", signRS256(t, rsaKey, "rsa1", claims(map[string]any{"aud": []string{"other", "hotpod"}})), false, ""},
+		{"wrong audience", signRS256(t, rsaKey, "rsa1", claims(map[string]any{"aud": "other"})), true, ""},
+		{"wrong issuer", signRS256(t, rsaKey, "rsa1", claims(map[string]any{"iss": "https://evil"})), true, ""},
+		{"expired", signRS256(t, rsaKey, "rsa1", claims(map[string]any{"exp": float64(time.Now().Add(-time.Hour).Unix())})), true, ""},
+		{"not yet valid", signRS256(t, rsaKey, "rsa1", claims(map[string]any{"nbf": float64(time.Now().Add(time.Hour).Unix())})), true, ""},
+		{"bad signature", signRS256(t, otherKey, "rsa1", claims(nil)), true, ""},
+		{"unknown kid", signRS256(t, rsaKey, "nope", claims(nil)), true, ""},
+	}
+
+	for _, tt := range tests {
+		p, err := v.Verify(context.Background(), tt.token)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (p.Role != tt.wantRole || p.Name != "ci-runner") {
+			t.Errorf("%s: principal = %+v, want role %q", tt.name, p, tt.wantRole)
+		}
+	}
+
+	// The unknown kid must not trigger a refetch within the rate limit window.
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", n)
+	}
+}
+
+func TestJWTVerifierIssuerOutage(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	v := NewJWTVerifier(JWTConfig{Issuer: srv.URL, JWKSURL: srv.URL + "/keys"})
+	token := signRS256(t, rsaKey, "rsa1", map[string]any{"iss": srv.URL, "exp": float64(time.Now().Add(time.Hour).Unix())})
+
+	// Concurrent requests share one fetch of the slow issuer.
+	errs := make(chan error, 5)
+	for range 5 {
+		go func() {
+			_, err := v.Verify(context.Background(), token)
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for range 5 {
+		if err := <-errs; err == nil {
+			t.Error("Verify() succeeded while the issuer is down")
+		}
+	}
+
+	// A failed fetch still counts against the refetch rate limit.
+	for range 3 {
+		if _, err := v.Verify(context.Background(), token); err == nil {
+			t.Error("Verify() succeeded while the issuer is down")
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", n)
+	}
+}
+
+func TestJWTVerifierClockSkew(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	srv, fetches := newIssuer(t, rsaKey, ecKey)
+	defer wallclock.SetSkew(0)
+
+	v := NewJWTVerifier(JWTConfig{Issuer: srv.URL})
+	token := func(kid string) string {
+		return signRS256(t, rsaKey, kid, map[string]any{"iss": srv.URL, "exp": float64(wallclock.Now().Add(time.Hour).Unix())})
+	}
+	if _, err := v.Verify(context.Background(), token("rsa1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A positive skew does not make the freshly fetched keys look stale.
+	wallclock.SetSkew(2 * time.Hour)
+	if _, err := v.Verify(context.Background(), token("rsa1")); err != nil {
+		t.Fatal(err)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times under positive skew, want 1", n)
+	}
+
+	// A negative skew does not hold off refetching for an unknown key once
+	// the rate limit has passed in real time.
+	wallclock.SetSkew(-2 * time.Hour)
+	v.mu.Lock()
+	v.attempted = time.Now().Add(-2 * jwksMinRefresh)
+	v.mu.Unlock()
+	v.Verify(context.Background(), token("rotated"))
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("JWKS fetched %d times under negative skew, want 2", n)
+	}
+}
+
+func TestAuthenticatorJWT(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	srv, _ := newIssuer(t, rsaKey, ecKey)
+
+	a := New("static", nil)
+	a.UseJWT(NewJWTVerifier(JWTConfig{Issuer: srv.URL, DefaultRole: RoleRead}))
+	if !a.Scoped() {
+		t.Error("Scoped() should be true with a JWT verifier")
+	}
+
+	token := signRS256(t, rsaKey, "rsa1", map[string]any{"iss": srv.URL, "sub": "svc", "exp": float64(time.Now().Add(time.Minute).Unix())})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if _, err := a.Authorize(req, RoleRead); err != nil {
+		t.Errorf("Authorize(read) error = %v", err)
+	}
+	if _, err := a.Authorize(req, RoleMutate); err != ErrForbidden {
+		t.Errorf("Authorize(mutate) error = %v, want ErrForbidden", err)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Admin-Token", "static")
+	if p, err := a.Authorize(req, RoleChaos); err != nil || p.Name != "admin" {
+		t.Errorf("static token: %+v, %v", p, err)
+	}
+}
+
+func TestJWTVerifierSkewOnlyRejects(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	srv, _ := newIssuer(t, rsaKey, ecKey)
+	defer wallclock.SetSkew(0)
+
+	v := NewJWTVerifier(JWTConfig{Issuer: srv.URL})
+	expired := signRS256(t, rsaKey, "rsa1", map[string]any{"iss": srv.URL, "exp": float64(time.Now().Add(-time.Hour).Unix())})
+	fresh := signRS256(t, rsaKey, "rsa1", map[string]any{"iss": srv.URL, "exp": float64(time.Now().Add(time.Hour).Unix())})
+
+	// Putting the clock behind does not revive an expired token.
+	wallclock.SetSkew(-2 * time.Hour)
+	if _, err := v.Verify(context.Background(), expired); err == nil {
+		t.Error("Verify() accepted an expired token under negative skew")
+	}
+	if _, err := v.Verify(context.Background(), fresh); err != nil {
+		t.Errorf("Verify() under negative skew error = %v", err)
+	}
+
+	// Putting the clock ahead expires a token early.
+	wallclock.SetSkew(2 * time.Hour)
+	if _, err := v.Verify(context.Background(), fresh); err == nil {
+		t.Error("Verify() accepted a token expired on the skewed clock")
+	}
+}
@@ -0,0 +1,51 @@
+// Package buildinfo describes the running binary: its VCS revision, build
+// date, Go version, and linked modules, so experiment reports can record
+// exactly which build produced their results.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Read returns the running binary's build. commit and date are the values
+// stamped via ldflags, if any; commit falls back to the VCS revision the go
+// tool embeds when building from a checkout.
+func Read(commit, date string) api.InfoBuild {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return api.InfoBuild{Commit: commit, Date: date, GoVersion: runtime.Version()}
+	}
+	return fromBuildInfo(info, commit, date)
+}
+
+func fromBuildInfo(info *debug.BuildInfo, commit, date string) api.InfoBuild {
+	b := api.InfoBuild{
+		Commit:    commit,
+		Date:      date,
+		GoVersion: info.GoVersion,
+		Module:    info.Main.Path,
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if b.Commit == "" {
+				b.Commit = s.Value
+			}
+		case "vcs.time":
+			b.CommitTime = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+	for _, dep := range info.Deps {
+		d := api.InfoDependency{Path: dep.Path, Version: dep.Version}
+		if dep.Replace != nil {
+			d.Replace = dep.Replace.Path + "@" + dep.Replace.Version
+		}
+		b.Dependencies = append(b.Dependencies, d)
+	}
+	return b
+}
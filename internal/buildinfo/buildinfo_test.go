@@ -0,0 +1,44 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestFromBuildInfo(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.24.1",
+		Main:      debug.Module{Path: "github.com/ripta/hotpod"},
+		Deps: []*debug.Module{
+			{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+			{Path: "golang.org/x/sys", Version: "v0.30.0", Replace: &debug.Module{Path: "../sys", Version: "(devel)"}},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "2025-01-02T03:04:05Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	b := fromBuildInfo(info, "", "2025-01-03T00:00:00Z")
+	if b.Commit != "abc123" || b.CommitTime != "2025-01-02T03:04:05Z" || !b.Modified {
+		t.Errorf("vcs = %q, %q, %t; want abc123, 2025-01-02T03:04:05Z, true", b.Commit, b.CommitTime, b.Modified)
+	}
+	if b.Date != "2025-01-03T00:00:00Z" || b.GoVersion != "go1.24.1" || b.Module != "github.com/ripta/hotpod" {
+		t.Errorf("build = %+v", b)
+	}
+	if len(b.Dependencies) != 2 || b.Dependencies[0].Version != "v3.0.1" || b.Dependencies[1].Replace != "../sys@(devel)" {
+		t.Errorf("dependencies = %+v", b.Dependencies)
+	}
+
+	if b := fromBuildInfo(info, "def456", ""); b.Commit != "def456" {
+		t.Errorf("commit = %q, want the stamped def456 over the VCS revision", b.Commit)
+	}
+}
+
+func TestRead(t *testing.T) {
+	if b := Read("", ""); b.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", b.GoVersion, runtime.Version())
+	}
+}
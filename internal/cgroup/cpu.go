@@ -0,0 +1,105 @@
+// Package cgroup reads the resource limits and accounting of the container's
+// cgroup, along with the process's OOM killer settings.
+package cgroup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRoot is where the container's cgroup filesystem is mounted.
+const DefaultRoot = "/sys/fs/cgroup"
+
+// ErrUnavailable is returned when a statistic cannot be found, such as
+// outside a container or on a platform without cgroups.
+var ErrUnavailable = errors.New("cgroup statistics unavailable")
+
+// cpuStatPaths are the cpu.stat locations relative to the root, for cgroup v2
+// and then the two common cgroup v1 mount layouts.
+var cpuStatPaths = []string{
+	"cpu.stat",
+	"cpu,cpuacct/cpu.stat",
+	"cpu/cpu.stat",
+}
+
+// CPUStat holds the CFS bandwidth counters from cpu.stat.
+type CPUStat struct {
+	// Periods is the number of enforcement periods that have elapsed
+	Periods uint64
+	// ThrottledPeriods is the number of periods in which the cgroup was
+	// throttled
+	ThrottledPeriods uint64
+	// Throttled is the total time the cgroup's tasks were throttled
+	Throttled time.Duration
+}
+
+// Sub returns the counters accumulated since prev.
+func (s CPUStat) Sub(prev CPUStat) CPUStat {
+	return CPUStat{
+		Periods:          s.Periods - prev.Periods,
+		ThrottledPeriods: s.ThrottledPeriods - prev.ThrottledPeriods,
+		Throttled:        s.Throttled - prev.Throttled,
+	}
+}
+
+// ReadCPUStat reads cpu.stat under root, which is normally DefaultRoot.
+func ReadCPUStat(root string) (CPUStat, error) {
+	for _, rel := range cpuStatPaths {
+		data, err := os.ReadFile(filepath.Join(root, rel))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return CPUStat{}, err
+		}
+		return parseCPUStat(data)
+	}
+	return CPUStat{}, ErrUnavailable
+}
+
+// parseCPUStat parses cpu.stat contents. cgroup v2 reports throttled_usec
+// while v1 reports throttled_time in nanoseconds.
+func parseCPUStat(data []byte) (CPUStat, error) {
+	var stat CPUStat
+	found := false
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), " ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return CPUStat{}, fmt.Errorf("cpu.stat %s: %w", key, err)
+		}
+
+		switch key {
+		case "nr_periods":
+			stat.Periods = n
+		case "nr_throttled":
+			stat.ThrottledPeriods = n
+		case "throttled_usec":
+			stat.Throttled = time.Duration(n) * time.Microsecond
+		case "throttled_time":
+			stat.Throttled = time.Duration(n)
+		default:
+			continue
+		}
+		found = true
+	}
+	if err := sc.Err(); err != nil {
+		return CPUStat{}, err
+	}
+	if !found {
+		return CPUStat{}, ErrUnavailable
+	}
+	return stat, nil
+}
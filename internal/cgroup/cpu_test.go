@@ -0,0 +1,74 @@
+package cgroup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadCPUStat(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		path    string
+		content string
+		want    CPUStat
+	}{
+		{
+			name:    "v2",
+			path:    "cpu.stat",
+			content: "usage_usec 1000\nuser_usec 600\nsystem_usec 400\nnr_periods 50\nnr_throttled 7\nthrottled_usec 125000\nnr_bursts 0\n",
+			want:    CPUStat{Periods: 50, ThrottledPeriods: 7, Throttled: 125 * time.Millisecond},
+		},
+		{
+			name:    "v1",
+			path:    "cpu,cpuacct/cpu.stat",
+			content: "nr_periods 20\nnr_throttled 3\nthrottled_time 2000000000\n",
+			want:    CPUStat{Periods: 20, ThrottledPeriods: 3, Throttled: 2 * time.Second},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, tt.path)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ReadCPUStat(root)
+			if err != nil {
+				t.Fatalf("ReadCPUStat() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReadCPUStat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCPUStatUnavailable(t *testing.T) {
+	if _, err := ReadCPUStat(t.TempDir()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("ReadCPUStat() error = %v, want ErrUnavailable", err)
+	}
+
+	// A cgroup without a CPU limit has no bandwidth counters.
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "cpu.stat"), []byte("usage_usec 1000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadCPUStat(root); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("ReadCPUStat() error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestCPUStatSub(t *testing.T) {
+	before := CPUStat{Periods: 10, ThrottledPeriods: 2, Throttled: time.Second}
+	after := CPUStat{Periods: 15, ThrottledPeriods: 5, Throttled: 3 * time.Second}
+	want := CPUStat{Periods: 5, ThrottledPeriods: 3, Throttled: 2 * time.Second}
+	if got := after.Sub(before); got != want {
+		t.Errorf("Sub() = %+v, want %+v", got, want)
+	}
+}
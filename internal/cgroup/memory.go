@@ -0,0 +1,100 @@
+package cgroup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MemoryEvents holds the cgroup v2 memory.events counters.
+type MemoryEvents struct {
+	// Low counts reclaims below memory.low
+	Low uint64
+	// High counts throttling for exceeding memory.high
+	High uint64
+	// Max counts allocations that hit memory.max
+	Max uint64
+	// OOM counts times the cgroup's OOM killer was invoked
+	OOM uint64
+	// OOMKill counts processes killed by the OOM killer
+	OOMKill uint64
+}
+
+// ReadMemoryEvents reads memory.events under root. It is only available with
+// cgroup v2.
+func ReadMemoryEvents(root string) (MemoryEvents, error) {
+	data, err := readFile(root, "memory.events")
+	if err != nil {
+		return MemoryEvents{}, err
+	}
+
+	var events MemoryEvents
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), " ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return MemoryEvents{}, fmt.Errorf("memory.events %s: %w", key, err)
+		}
+
+		switch key {
+		case "low":
+			events.Low = n
+		case "high":
+			events.High = n
+		case "max":
+			events.Max = n
+		case "oom":
+			events.OOM = n
+		case "oom_kill":
+			events.OOMKill = n
+		}
+	}
+	return events, sc.Err()
+}
+
+// ReadMemoryHigh returns the memory.high throttling threshold under root in
+// bytes, or 0 if none is set.
+func ReadMemoryHigh(root string) (int64, error) {
+	return readMemoryBytes(root, "memory.high")
+}
+
+// ReadMemoryCurrent returns the cgroup's current memory usage in bytes.
+func ReadMemoryCurrent(root string) (int64, error) {
+	return readMemoryBytes(root, "memory.current")
+}
+
+// readMemoryBytes reads a cgroup v2 memory file holding a byte count or max.
+func readMemoryBytes(root, name string) (int64, error) {
+	data, err := readFile(root, name)
+	if err != nil {
+		return 0, err
+	}
+
+	v := strings.TrimSpace(string(data))
+	if v == "max" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return n, nil
+}
+
+// readFile reads a file under root, mapping a missing file to ErrUnavailable.
+func readFile(root, name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrUnavailable
+	}
+	return data, err
+}
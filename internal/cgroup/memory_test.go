@@ -0,0 +1,71 @@
+package cgroup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestReadMemoryEvents(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"memory.events": "low 1\nhigh 42\nmax 3\noom 2\noom_kill 1\noom_group_kill 0\n",
+	})
+
+	got, err := ReadMemoryEvents(root)
+	if err != nil {
+		t.Fatalf("ReadMemoryEvents() error = %v", err)
+	}
+	want := MemoryEvents{Low: 1, High: 42, Max: 3, OOM: 2, OOMKill: 1}
+	if got != want {
+		t.Errorf("ReadMemoryEvents() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ReadMemoryEvents(t.TempDir()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("ReadMemoryEvents() error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestReadMemoryBytes(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"memory.high":    "max\n",
+		"memory.current": "1048576\n",
+	})
+
+	if high, err := ReadMemoryHigh(root); err != nil || high != 0 {
+		t.Errorf("ReadMemoryHigh() = %d, %v, want 0 for max", high, err)
+	}
+	if current, err := ReadMemoryCurrent(root); err != nil || current != 1<<20 {
+		t.Errorf("ReadMemoryCurrent() = %d, %v, want 1Mi", current, err)
+	}
+
+	root = writeFiles(t, map[string]string{"memory.high": "268435456\n"})
+	if high, err := ReadMemoryHigh(root); err != nil || high != 256<<20 {
+		t.Errorf("ReadMemoryHigh() = %d, %v, want 256Mi", high, err)
+	}
+}
+
+func TestReadOOMScoreAdj(t *testing.T) {
+	root := writeFiles(t, map[string]string{"oom_score_adj": "-997\n", "bad": "x\n"})
+
+	if got, err := ReadOOMScoreAdj(filepath.Join(root, "oom_score_adj")); err != nil || got != -997 {
+		t.Errorf("ReadOOMScoreAdj() = %d, %v, want -997", got, err)
+	}
+	if _, err := ReadOOMScoreAdj(filepath.Join(root, "bad")); err == nil {
+		t.Error("ReadOOMScoreAdj(bad) error = nil, want error")
+	}
+	if _, err := ReadOOMScoreAdj(filepath.Join(root, "missing")); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("ReadOOMScoreAdj(missing) error = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,31 @@
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultOOMScoreAdjPath is this process's OOM killer adjustment.
+const DefaultOOMScoreAdjPath = "/proc/self/oom_score_adj"
+
+// ReadOOMScoreAdj reads an oom_score_adj file, normally
+// DefaultOOMScoreAdjPath. The kubelet sets it from the pod's QoS class: -997
+// for Guaranteed, 1000 for BestEffort, and in between for Burstable.
+func ReadOOMScoreAdj(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, ErrUnavailable
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("oom_score_adj: %w", err)
+	}
+	return n, nil
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -18,8 +20,30 @@ const IOBasePath = "/tmp"
 type Config struct {
 	// Port is the HTTP server port (default: 8080)
 	Port int
+	// MgmtPort moves /healthz, /readyz, /startupz, /metrics, /custom-metrics,
+	// and /admin/* to a separate listener (default: 0 = served on Port)
+	MgmtPort int
+	// ProxyProtocol accepts PROXY protocol v1/v2 headers on Port: "optional"
+	// or "required", which still lets loopback clients omit the header
+	// (empty = disabled)
+	ProxyProtocol string
+	// TLS serves Port over HTTPS, with TLSCertFile and TLSKeyFile or a
+	// self-signed certificate (default: false)
+	TLS bool
+	// TLSCertFile is the serving certificate for Port
+	TLSCertFile string
+	// TLSKeyFile is the private key for TLSCertFile
+	TLSKeyFile string
 	// LogLevel is the slog level: debug, info, warn, error (default: info)
 	LogLevel string
+	// LogFormat is "json" (default), "logfmt", or "text"
+	LogFormat string
+	// LogOutput is "stdout" (default), "stderr", or a file path
+	LogOutput string
+	// LogMaxSize rotates a file log output once it exceeds this size (default: 100Mi, 0 = never)
+	LogMaxSize int64
+	// LogMaxFiles is the number of rotated log files kept (default: 3)
+	LogMaxFiles int
 	// StartupDelay is the time to wait before becoming ready
 	StartupDelay time.Duration
 	// StartupJitter adds random variance to StartupDelay
@@ -30,10 +54,32 @@ type Config struct {
 	ShutdownTimeout time.Duration
 	// DrainImmediately rejects new requests immediately on shutdown
 	DrainImmediately bool
+	// PreStopDelay is how long GET /prestop waits after marking not-ready (default: 5s)
+	PreStopDelay time.Duration
+	// HealthDelay delays every /healthz, /readyz, and /startupz response
+	HealthDelay time.Duration
+	// HealthDelayJitter adds a random delay of up to this much to HealthDelay
+	HealthDelayJitter time.Duration
+	// SigtermBehavior is how SIGTERM is handled: "graceful" (default), "ignore",
+	// "exit-immediately", or "crash-after"
+	SigtermBehavior string
+	// SigtermDelay is how long "crash-after" keeps serving before crashing (default: 10s)
+	SigtermDelay time.Duration
 	// RequestTimeout is the server-side timeout for all requests
 	RequestTimeout time.Duration
+	// RequestTimeouts overrides RequestTimeout per endpoint group as
+	// comma-separated group=duration pairs, e.g. io=30m,latency=1m (0 = none)
+	RequestTimeouts string
 	// MaxConcurrentOps is the max concurrent operations per type (<=0 to disable)
 	MaxConcurrentOps int
+	// AdmissionQueueTimeout is how long operations wait for a free slot when
+	// MaxConcurrentOps is reached before returning 429 (0 = reject immediately)
+	AdmissionQueueTimeout time.Duration
+	// ShedMaxInFlight sheds requests by priority once this many are in flight (0 = disabled)
+	ShedMaxInFlight int
+	// ShedMaxCPU sheds requests by priority once process CPU usage exceeds this,
+	// in Kubernetes CPU notation (0 = disabled)
+	ShedMaxCPU time.Duration
 	// MaxCPUDuration is the maximum duration for CPU load operations (default: 60s)
 	MaxCPUDuration time.Duration
 	// MaxMemorySize is the maximum memory allocation size in bytes (default: 1GB)
@@ -43,35 +89,249 @@ type Config struct {
 	// IODirName is the directory name for I/O operations under /tmp (default: hotpod)
 	// Must be lowercase alphanumeric with optional hyphens, no paths or special chars.
 	IODirName string
-	// EnablePprof enables pprof endpoints on a separate port (6060)
+	// EnablePprof enables pprof endpoints on a separate port
 	EnablePprof bool
+	// PprofBind is the address the pprof server binds to (default: localhost)
+	PprofBind string
+	// PprofPort is the pprof server port (default: 6060)
+	PprofPort int
+	// PprofAuth requires admin credentials on the pprof server
+	PprofAuth bool
 	// DisableChaos disables /fault/* chaos engineering endpoints
 	DisableChaos bool
+	// ChaosAllow is a comma-separated allowlist of chaos actions, or all,
+	// none, or safe for only non-destructive actions (empty = all)
+	ChaosAllow string
+	// ChaosCooldowns sets the minimum time between runs of chaos actions as
+	// comma-separated action=duration pairs, e.g. "crash=10m,oom=5m"
+	ChaosCooldowns string
+	// ChaosMaxDestructive limits how many destructive faults may be in
+	// effect at once (0 = unlimited)
+	ChaosMaxDestructive int
 	// DisableQueue disables /queue/* endpoints
 	DisableQueue bool
+	// ReadOnly rejects fault endpoints and anything that changes server
+	// state, such as admin mutations, queue operations, and the preStop
+	// hook, while keeping health, info, metrics, and load endpoints. It
+	// cannot be combined with StateFile.
+	ReadOnly bool
+	// DisableEndpoints is a comma-separated list of endpoint groups to
+	// disable, e.g. "io,memory,fault"; they can be re-enabled through
+	// /admin/endpoints
+	DisableEndpoints string
 	// QueueMaxDepth is the maximum number of items in the queue
 	QueueMaxDepth int
 	// QueueDefaultWorkers is the default number of queue workers
 	QueueDefaultWorkers int
-	// Mode is the operating mode: "app" (default) or "sidecar"
+	// ReadyQueueHigh marks the pod not ready once the queue depth exceeds it
+	// (0 = disabled)
+	ReadyQueueHigh int
+	// ReadyQueueLow marks the pod ready again once the queue depth falls to
+	// it (0 = half of ReadyQueueHigh)
+	ReadyQueueLow int
+	// Mode is the operating mode: "app" (default), "sidecar", "combined"
+	// (both in one process), or "init" (run InitCPU, InitIOSize, and InitSleep,
+	// then exit)
 	Mode string
+	// InitCPU is how long init mode burns CPU on each of InitCores cores (default: 0)
+	InitCPU time.Duration
+	// InitCores is the number of cores init mode burns (default: 1)
+	InitCores int
+	// InitIOSize is the bytes init mode writes and reads back (default: 0)
+	InitIOSize int64
+	// InitSleep is how long init mode sleeps after its work (default: 0)
+	InitSleep time.Duration
+	// InitExitCode is the exit code init mode returns on success (default: 0)
+	InitExitCode int
 	// SidecarCPUBaseline is the steady CPU burn per 1s cycle (default: 100ms = 100m)
 	SidecarCPUBaseline time.Duration
 	// SidecarCPUJitter is random CPU variance added each cycle (default: 10ms = 10m)
 	SidecarCPUJitter time.Duration
 	// SidecarMemoryBaseline is the steady memory allocation in bytes (default: 50MiB)
 	SidecarMemoryBaseline int64
-	// SidecarRequestOverhead is extra CPU burn per request (default: 0)
+	// SidecarMemoryJitter is random memory variance applied each second (default: 0)
+	SidecarMemoryJitter int64
+	// SidecarMemoryPeak is the level memory ramps to and back from the baseline
+	// (default: 0 = hold the baseline)
+	SidecarMemoryPeak int64
+	// SidecarMemoryRamp is how long each ramp from baseline to peak, or back,
+	// takes (default: 10m)
+	SidecarMemoryRamp time.Duration
+	// SidecarRequestOverhead is extra CPU burn per proxied request (default: 0)
 	SidecarRequestOverhead time.Duration
+	// SidecarProxyPort is the port the sidecar proxy listens on (default: 0 = disabled)
+	SidecarProxyPort int
+	// SidecarProxyTarget is the base URL the sidecar proxy forwards to, e.g.
+	// the application container at http://127.0.0.1:8080
+	SidecarProxyTarget string
 	// AdminToken is the authentication token for /admin/* endpoints (empty = open access)
 	AdminToken string
+	// AdminTokens is a comma-separated list of role-scoped name:role:token entries
+	AdminTokens string
+	// AdminTokensFile is a file of name:role:token entries, one per line
+	AdminTokensFile string
+	// OIDCIssuer enables bearer JWT authentication against this issuer (empty = disabled)
+	OIDCIssuer string
+	// OIDCAudience is the required aud claim, if set
+	OIDCAudience string
+	// OIDCJWKSURL overrides JWKS discovery from the issuer
+	OIDCJWKSURL string
+	// OIDCRoleClaim is the JWT claim holding the role (default: hotpod_role)
+	OIDCRoleClaim string
+	// OIDCDefaultRole is granted to valid JWTs without a role claim (empty = none)
+	OIDCDefaultRole string
+	// ProtectedBasicAuth is the user:password /protected requires as HTTP
+	// basic auth (empty = not required)
+	ProtectedBasicAuth string
+	// ProtectedHeader is a header /protected requires, as Name or
+	// Name=value (empty = not required)
+	ProtectedHeader string
+	// MetricsPushMode enables pushing metrics: "pushgateway" or "remote_write" (empty = disabled)
+	MetricsPushMode string
+	// MetricsPushURL is the Pushgateway base URL or remote_write endpoint
+	MetricsPushURL string
+	// MetricsPushInterval is how often metrics are pushed (default: 15s)
+	MetricsPushInterval time.Duration
+	// MetricsPushJob is the job label attached to pushed metrics (default: hotpod)
+	MetricsPushJob string
+	// MetricsDelay delays every /metrics response
+	MetricsDelay time.Duration
+	// MetricsDelayJitter adds a random delay of up to this much to MetricsDelay
+	MetricsDelayJitter time.Duration
+	// MetricsPadding inflates every /metrics response by about this many bytes
+	MetricsPadding int64
+	// ProfilingMode enables continuous profiling: "pyroscope" pushes profiles
+	// to ProfilingURL, "parca" expects Parca to scrape the pprof server
+	// (empty = disabled)
+	ProfilingMode string
+	// ProfilingURL is the Pyroscope server base URL
+	ProfilingURL string
+	// ProfilingAppName is the application name profiles are pushed under (default: hotpod)
+	ProfilingAppName string
+	// ProfilingLabels is a comma-separated list of key=value labels attached to pushed profiles
+	ProfilingLabels string
+	// ProfilingInterval is how long each pushed profile covers (default: 10s)
+	ProfilingInterval time.Duration
+	// ProfilingTypes is a comma-separated list of profile types to push (default: cpu,heap)
+	ProfilingTypes string
+	// ScheduleCPU is a background CPU load pattern in cores, e.g.
+	// "sine:period=24h,min=100m,max=1500m" (empty = disabled)
+	ScheduleCPU string
+	// ScheduleMemory is a background memory pattern in bytes, e.g.
+	// "step:0s=64Mi,2h=512Mi,period=4h" (empty = disabled)
+	ScheduleMemory string
+	// ScheduleQueue is a target queue depth pattern, e.g.
+	// "cron:0 9 * * 1-5=500;0 18 * * *=0" (empty = disabled)
+	ScheduleQueue string
+	// Controller runs the HotpodProfile controller in addition to the server
+	Controller bool
+	// ControllerResync is how often the controller reconciles profiles (default: 30s)
+	ControllerResync time.Duration
+	// ControllerToken is sent to target pods' admin APIs (default: AdminToken)
+	ControllerToken string
+	// FleetDNS is a headless Service hostname resolved to discover peers
+	FleetDNS string
+	// FleetSelector is a label selector used to discover peers via the Kubernetes API
+	FleetSelector string
+	// FleetPort is the peers' port for broadcast admin requests (default:
+	// MgmtPort if set, else Port)
+	FleetPort int
+	// CustomMetricsPort is the HTTPS port serving the custom metrics API
+	// (default: 0 = disabled)
+	CustomMetricsPort int
+	// CustomMetricsCertFile is the custom metrics API serving certificate
+	// (default: self-signed)
+	CustomMetricsCertFile string
+	// CustomMetricsKeyFile is the private key for CustomMetricsCertFile
+	CustomMetricsKeyFile string
+	// WorkProfiles is a JSON array of /work profiles to define at startup,
+	// adding to or replacing the built-in ones
+	WorkProfiles string
+	// WorkProfilesFile is a file holding a JSON array of /work profiles,
+	// applied after WorkProfiles
+	WorkProfilesFile string
+	// Dependencies is a comma-separated list of synthetic dependency checks
+	// that gate /readyz, all passing at startup
+	Dependencies string
+	// LeaderElection campaigns for a coordination.k8s.io Lease in the pod's namespace
+	LeaderElection bool
+	// LeaderLeaseName is the Lease object name (default: hotpod)
+	LeaderLeaseName string
+	// LeaderLeaseDuration is how long an unrenewed lease blocks other candidates (default: 15s)
+	LeaderLeaseDuration time.Duration
+	// LeaderRenewDeadline is how long the leader retries renewal before stepping down (default: 10s)
+	LeaderRenewDeadline time.Duration
+	// LeaderRetryPeriod is the interval between acquire and renew attempts (default: 2s)
+	LeaderRetryPeriod time.Duration
+	// StateFile persists fault configs, readiness overrides, and other runtime
+	// settings across restarts (empty = disabled)
+	StateFile string
+	// EventLogSize is the number of events retained for GET /events; 0 keeps
+	// none, though events still reach sinks (default: 1000)
+	EventLogSize int
+	// KubeEvents mirrors info-level and higher events as Kubernetes Events on the pod
+	KubeEvents bool
+	// Zone and Region label responses with the pod's location (empty = read
+	// from the node's topology labels)
+	Zone   string
+	Region string
+	// Variant labels responses, logs, and metrics with the deployment
+	// variant, e.g. stable or canary, so traffic splits can be measured from
+	// hotpod's own metrics (empty = unlabeled)
+	Variant string
+	// DumpDir is where POST /admin/dump writes goroutine and heap dumps
+	// (empty = dumps are returned in the response)
+	DumpDir string
+	// DumpMaxSize truncates each dump beyond this size (default: 64Mi, 0 = unlimited)
+	DumpMaxSize int64
+	// DumpMaxFiles is the number of dump files kept in DumpDir, oldest
+	// removed first (default: 20, 0 = unlimited)
+	DumpMaxFiles int
+	// NodePressure watches the pod's node and backs off load while it reports
+	// MemoryPressure or DiskPressure
+	NodePressure bool
+	// NodePressureInterval is how often the node's conditions are polled (default: 10s)
+	NodePressureInterval time.Duration
+	// SLOAvailability is the target fraction of non-5xx responses, e.g.
+	// 0.999 (0 = disabled)
+	SLOAvailability float64
+	// SLOLatency is the target fraction of responses faster than
+	// SLOLatencyThreshold (0 = disabled)
+	SLOLatency float64
+	// SLOLatencyThreshold is the latency a response must beat to count as
+	// good (default: 500ms)
+	SLOLatencyThreshold time.Duration
+	// SLOPeriod is the error budget period (default: 24h)
+	SLOPeriod time.Duration
+	// SLOWindows are comma-separated burn-rate windows (default: 5m,30m,1h,6h)
+	SLOWindows string
+	// ProbeTargets are comma-separated endpoints the synthetic prober calls:
+	// paths like /healthz on this server, or http(s) URLs (empty = disabled)
+	ProbeTargets string
+	// ProbeInterval is how often each target is probed (default: 30s)
+	ProbeInterval time.Duration
+	// ProbeTimeout bounds each probe (default: 5s)
+	ProbeTimeout time.Duration
+}
+
+// maxVariantLength bounds the variant, which becomes a metric label value.
+const maxVariantLength = 63
+
+func invalidVariantRune(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-')
 }
 
 // Load reads configuration from environment variables.
 func Load() (*Config, error) {
 	cfg := &Config{
 		Port:                   8080,
+		PprofBind:              "localhost",
+		PprofPort:              6060,
 		LogLevel:               "info",
+		LogFormat:              "json",
+		LogOutput:              "stdout",
+		LogMaxSize:             100 << 20, // 100MiB
+		LogMaxFiles:            3,
 		ShutdownTimeout:        30 * time.Second,
 		RequestTimeout:         5 * time.Minute,
 		MaxConcurrentOps:       100,
@@ -82,10 +342,35 @@ func Load() (*Config, error) {
 		QueueMaxDepth:          10000,
 		QueueDefaultWorkers:    1,
 		Mode:                   "app",
+		InitCores:              1,
+		PreStopDelay:           5 * time.Second,
+		SigtermBehavior:        "graceful",
+		SigtermDelay:           10 * time.Second,
 		SidecarCPUBaseline:     100 * time.Millisecond,
 		SidecarCPUJitter:       10 * time.Millisecond,
 		SidecarMemoryBaseline:  50 << 20, // 50MiB
+		SidecarMemoryRamp:      10 * time.Minute,
 		SidecarRequestOverhead: 0,
+		MetricsPushInterval:    15 * time.Second,
+		MetricsPushJob:         "hotpod",
+		ProfilingAppName:       "hotpod",
+		ProfilingInterval:      10 * time.Second,
+		ProfilingTypes:         "cpu,heap",
+		EventLogSize:           1000,
+		DumpMaxSize:            64 << 20, // 64MiB
+		DumpMaxFiles:           20,
+		NodePressureInterval:   10 * time.Second,
+		SLOLatencyThreshold:    500 * time.Millisecond,
+		SLOPeriod:              24 * time.Hour,
+		SLOWindows:             "5m,30m,1h,6h",
+		ProbeInterval:          30 * time.Second,
+		ProbeTimeout:           5 * time.Second,
+		ControllerResync:       30 * time.Second,
+		LeaderLeaseName:        "hotpod",
+		LeaderLeaseDuration:    15 * time.Second,
+		LeaderRenewDeadline:    10 * time.Second,
+		LeaderRetryPeriod:      2 * time.Second,
+		OIDCRoleClaim:          "hotpod_role",
 	}
 
 	var err error
@@ -93,7 +378,24 @@ func Load() (*Config, error) {
 	if cfg.Port, err = getEnvInt("HOTPOD_PORT", cfg.Port); err != nil {
 		return nil, err
 	}
+	if cfg.MgmtPort, err = getEnvInt("HOTPOD_MGMT_PORT", cfg.MgmtPort); err != nil {
+		return nil, err
+	}
+	cfg.ProxyProtocol = getEnvString("HOTPOD_PROXY_PROTOCOL", cfg.ProxyProtocol)
+	if cfg.TLS, err = getEnvBool("HOTPOD_TLS", cfg.TLS); err != nil {
+		return nil, err
+	}
+	cfg.TLSCertFile = getEnvString("HOTPOD_TLS_CERT_FILE", cfg.TLSCertFile)
+	cfg.TLSKeyFile = getEnvString("HOTPOD_TLS_KEY_FILE", cfg.TLSKeyFile)
 	cfg.LogLevel = getEnvString("HOTPOD_LOG_LEVEL", cfg.LogLevel)
+	cfg.LogFormat = getEnvString("HOTPOD_LOG_FORMAT", cfg.LogFormat)
+	cfg.LogOutput = getEnvString("HOTPOD_LOG_OUTPUT", cfg.LogOutput)
+	if cfg.LogMaxSize, err = getEnvSize("HOTPOD_LOG_MAX_SIZE", cfg.LogMaxSize); err != nil {
+		return nil, err
+	}
+	if cfg.LogMaxFiles, err = getEnvInt("HOTPOD_LOG_MAX_FILES", cfg.LogMaxFiles); err != nil {
+		return nil, err
+	}
 	if cfg.StartupDelay, err = getEnvDuration("HOTPOD_STARTUP_DELAY", cfg.StartupDelay); err != nil {
 		return nil, err
 	}
@@ -109,12 +411,35 @@ func Load() (*Config, error) {
 	if cfg.DrainImmediately, err = getEnvBool("HOTPOD_DRAIN_IMMEDIATELY", cfg.DrainImmediately); err != nil {
 		return nil, err
 	}
+	if cfg.PreStopDelay, err = getEnvDuration("HOTPOD_PRESTOP_DELAY", cfg.PreStopDelay); err != nil {
+		return nil, err
+	}
+	if cfg.HealthDelay, err = getEnvDuration("HOTPOD_HEALTH_DELAY", cfg.HealthDelay); err != nil {
+		return nil, err
+	}
+	if cfg.HealthDelayJitter, err = getEnvDuration("HOTPOD_HEALTH_DELAY_JITTER", cfg.HealthDelayJitter); err != nil {
+		return nil, err
+	}
+	cfg.SigtermBehavior = getEnvString("HOTPOD_SIGTERM_BEHAVIOR", cfg.SigtermBehavior)
+	if cfg.SigtermDelay, err = getEnvDuration("HOTPOD_SIGTERM_DELAY", cfg.SigtermDelay); err != nil {
+		return nil, err
+	}
 	if cfg.RequestTimeout, err = getEnvDuration("HOTPOD_REQUEST_TIMEOUT", cfg.RequestTimeout); err != nil {
 		return nil, err
 	}
+	cfg.RequestTimeouts = getEnvString("HOTPOD_REQUEST_TIMEOUTS", cfg.RequestTimeouts)
 	if cfg.MaxConcurrentOps, err = getEnvInt("HOTPOD_MAX_CONCURRENT_OPS", cfg.MaxConcurrentOps); err != nil {
 		return nil, err
 	}
+	if cfg.AdmissionQueueTimeout, err = getEnvDuration("HOTPOD_ADMISSION_QUEUE_TIMEOUT", cfg.AdmissionQueueTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.ShedMaxInFlight, err = getEnvInt("HOTPOD_SHED_MAX_IN_FLIGHT", cfg.ShedMaxInFlight); err != nil {
+		return nil, err
+	}
+	if cfg.ShedMaxCPU, err = getEnvCPU("HOTPOD_SHED_MAX_CPU", cfg.ShedMaxCPU); err != nil {
+		return nil, err
+	}
 	if cfg.MaxCPUDuration, err = getEnvDuration("HOTPOD_MAX_CPU_DURATION", cfg.MaxCPUDuration); err != nil {
 		return nil, err
 	}
@@ -128,19 +453,56 @@ func Load() (*Config, error) {
 	if cfg.EnablePprof, err = getEnvBool("HOTPOD_ENABLE_PPROF", cfg.EnablePprof); err != nil {
 		return nil, err
 	}
+	cfg.PprofBind = getEnvString("HOTPOD_PPROF_BIND", cfg.PprofBind)
+	if cfg.PprofPort, err = getEnvInt("HOTPOD_PPROF_PORT", cfg.PprofPort); err != nil {
+		return nil, err
+	}
+	if cfg.PprofAuth, err = getEnvBool("HOTPOD_PPROF_AUTH", cfg.PprofAuth); err != nil {
+		return nil, err
+	}
 	if cfg.DisableChaos, err = getEnvBool("HOTPOD_DISABLE_CHAOS", cfg.DisableChaos); err != nil {
 		return nil, err
 	}
+	cfg.ChaosAllow = getEnvString("HOTPOD_CHAOS_ALLOW", cfg.ChaosAllow)
+	cfg.ChaosCooldowns = getEnvString("HOTPOD_CHAOS_COOLDOWNS", cfg.ChaosCooldowns)
+	if cfg.ChaosMaxDestructive, err = getEnvInt("HOTPOD_CHAOS_MAX_DESTRUCTIVE", cfg.ChaosMaxDestructive); err != nil {
+		return nil, err
+	}
 	if cfg.DisableQueue, err = getEnvBool("HOTPOD_DISABLE_QUEUE", cfg.DisableQueue); err != nil {
 		return nil, err
 	}
+	if cfg.ReadOnly, err = getEnvBool("HOTPOD_READ_ONLY", cfg.ReadOnly); err != nil {
+		return nil, err
+	}
+	cfg.DisableEndpoints = getEnvString("HOTPOD_DISABLE_ENDPOINTS", cfg.DisableEndpoints)
 	if cfg.QueueMaxDepth, err = getEnvInt("HOTPOD_QUEUE_MAX_DEPTH", cfg.QueueMaxDepth); err != nil {
 		return nil, err
 	}
 	if cfg.QueueDefaultWorkers, err = getEnvInt("HOTPOD_QUEUE_DEFAULT_WORKERS", cfg.QueueDefaultWorkers); err != nil {
 		return nil, err
 	}
+	if cfg.ReadyQueueHigh, err = getEnvInt("HOTPOD_READY_QUEUE_HIGH", cfg.ReadyQueueHigh); err != nil {
+		return nil, err
+	}
+	if cfg.ReadyQueueLow, err = getEnvInt("HOTPOD_READY_QUEUE_LOW", cfg.ReadyQueueLow); err != nil {
+		return nil, err
+	}
 	cfg.Mode = getEnvString("HOTPOD_MODE", cfg.Mode)
+	if cfg.InitCPU, err = getEnvDuration("HOTPOD_INIT_CPU", cfg.InitCPU); err != nil {
+		return nil, err
+	}
+	if cfg.InitCores, err = getEnvInt("HOTPOD_INIT_CORES", cfg.InitCores); err != nil {
+		return nil, err
+	}
+	if cfg.InitIOSize, err = getEnvSize("HOTPOD_INIT_IO_SIZE", cfg.InitIOSize); err != nil {
+		return nil, err
+	}
+	if cfg.InitSleep, err = getEnvDuration("HOTPOD_INIT_SLEEP", cfg.InitSleep); err != nil {
+		return nil, err
+	}
+	if cfg.InitExitCode, err = getEnvInt("HOTPOD_INIT_EXIT_CODE", cfg.InitExitCode); err != nil {
+		return nil, err
+	}
 	if cfg.SidecarCPUBaseline, err = getEnvCPU("HOTPOD_SIDECAR_CPU_BASELINE", cfg.SidecarCPUBaseline); err != nil {
 		return nil, err
 	}
@@ -150,10 +512,138 @@ func Load() (*Config, error) {
 	if cfg.SidecarMemoryBaseline, err = getEnvSize("HOTPOD_SIDECAR_MEMORY_BASELINE", cfg.SidecarMemoryBaseline); err != nil {
 		return nil, err
 	}
+	if cfg.SidecarMemoryJitter, err = getEnvSize("HOTPOD_SIDECAR_MEMORY_JITTER", cfg.SidecarMemoryJitter); err != nil {
+		return nil, err
+	}
+	if cfg.SidecarMemoryPeak, err = getEnvSize("HOTPOD_SIDECAR_MEMORY_PEAK", cfg.SidecarMemoryPeak); err != nil {
+		return nil, err
+	}
+	if cfg.SidecarMemoryRamp, err = getEnvDuration("HOTPOD_SIDECAR_MEMORY_RAMP", cfg.SidecarMemoryRamp); err != nil {
+		return nil, err
+	}
 	if cfg.SidecarRequestOverhead, err = getEnvCPU("HOTPOD_SIDECAR_REQUEST_OVERHEAD", cfg.SidecarRequestOverhead); err != nil {
 		return nil, err
 	}
+	if cfg.SidecarProxyPort, err = getEnvInt("HOTPOD_SIDECAR_PROXY_PORT", cfg.SidecarProxyPort); err != nil {
+		return nil, err
+	}
+	cfg.SidecarProxyTarget = getEnvString("HOTPOD_SIDECAR_PROXY_TARGET", cfg.SidecarProxyTarget)
 	cfg.AdminToken = getEnvString("HOTPOD_ADMIN_TOKEN", cfg.AdminToken)
+	cfg.AdminTokens = getEnvString("HOTPOD_ADMIN_TOKENS", cfg.AdminTokens)
+	cfg.AdminTokensFile = getEnvString("HOTPOD_ADMIN_TOKENS_FILE", cfg.AdminTokensFile)
+	cfg.OIDCIssuer = getEnvString("HOTPOD_OIDC_ISSUER", cfg.OIDCIssuer)
+	cfg.OIDCAudience = getEnvString("HOTPOD_OIDC_AUDIENCE", cfg.OIDCAudience)
+	cfg.OIDCJWKSURL = getEnvString("HOTPOD_OIDC_JWKS_URL", cfg.OIDCJWKSURL)
+	cfg.OIDCRoleClaim = getEnvString("HOTPOD_OIDC_ROLE_CLAIM", cfg.OIDCRoleClaim)
+	cfg.OIDCDefaultRole = getEnvString("HOTPOD_OIDC_DEFAULT_ROLE", cfg.OIDCDefaultRole)
+	cfg.ProtectedBasicAuth = getEnvString("HOTPOD_PROTECTED_BASIC_AUTH", cfg.ProtectedBasicAuth)
+	cfg.ProtectedHeader = getEnvString("HOTPOD_PROTECTED_HEADER", cfg.ProtectedHeader)
+	cfg.ScheduleCPU = getEnvString("HOTPOD_SCHEDULE_CPU", cfg.ScheduleCPU)
+	cfg.ScheduleMemory = getEnvString("HOTPOD_SCHEDULE_MEMORY", cfg.ScheduleMemory)
+	cfg.ScheduleQueue = getEnvString("HOTPOD_SCHEDULE_QUEUE", cfg.ScheduleQueue)
+	if cfg.Controller, err = getEnvBool("HOTPOD_CONTROLLER", cfg.Controller); err != nil {
+		return nil, err
+	}
+	if cfg.ControllerResync, err = getEnvDuration("HOTPOD_CONTROLLER_RESYNC", cfg.ControllerResync); err != nil {
+		return nil, err
+	}
+	cfg.ControllerToken = getEnvString("HOTPOD_CONTROLLER_TOKEN", cfg.AdminToken)
+	cfg.StateFile = getEnvString("HOTPOD_STATE_FILE", cfg.StateFile)
+	cfg.FleetDNS = getEnvString("HOTPOD_FLEET_DNS", cfg.FleetDNS)
+	cfg.FleetSelector = getEnvString("HOTPOD_FLEET_SELECTOR", cfg.FleetSelector)
+	fleetPort := cfg.Port
+	if cfg.MgmtPort > 0 {
+		fleetPort = cfg.MgmtPort
+	}
+	if cfg.FleetPort, err = getEnvInt("HOTPOD_FLEET_PORT", fleetPort); err != nil {
+		return nil, err
+	}
+	if cfg.CustomMetricsPort, err = getEnvInt("HOTPOD_CUSTOM_METRICS_PORT", cfg.CustomMetricsPort); err != nil {
+		return nil, err
+	}
+	cfg.CustomMetricsCertFile = getEnvString("HOTPOD_CUSTOM_METRICS_CERT_FILE", cfg.CustomMetricsCertFile)
+	cfg.CustomMetricsKeyFile = getEnvString("HOTPOD_CUSTOM_METRICS_KEY_FILE", cfg.CustomMetricsKeyFile)
+	cfg.WorkProfiles = getEnvString("HOTPOD_WORK_PROFILES", cfg.WorkProfiles)
+	cfg.WorkProfilesFile = getEnvString("HOTPOD_WORK_PROFILES_FILE", cfg.WorkProfilesFile)
+	cfg.Dependencies = getEnvString("HOTPOD_DEPENDENCIES", cfg.Dependencies)
+	if cfg.LeaderElection, err = getEnvBool("HOTPOD_LEADER_ELECTION", cfg.LeaderElection); err != nil {
+		return nil, err
+	}
+	cfg.LeaderLeaseName = getEnvString("HOTPOD_LEADER_LEASE_NAME", cfg.LeaderLeaseName)
+	if cfg.LeaderLeaseDuration, err = getEnvDuration("HOTPOD_LEADER_LEASE_DURATION", cfg.LeaderLeaseDuration); err != nil {
+		return nil, err
+	}
+	if cfg.LeaderRenewDeadline, err = getEnvDuration("HOTPOD_LEADER_RENEW_DEADLINE", cfg.LeaderRenewDeadline); err != nil {
+		return nil, err
+	}
+	if cfg.LeaderRetryPeriod, err = getEnvDuration("HOTPOD_LEADER_RETRY_PERIOD", cfg.LeaderRetryPeriod); err != nil {
+		return nil, err
+	}
+	cfg.MetricsPushMode = getEnvString("HOTPOD_METRICS_PUSH_MODE", cfg.MetricsPushMode)
+	cfg.MetricsPushURL = getEnvString("HOTPOD_METRICS_PUSH_URL", cfg.MetricsPushURL)
+	if cfg.MetricsPushInterval, err = getEnvDuration("HOTPOD_METRICS_PUSH_INTERVAL", cfg.MetricsPushInterval); err != nil {
+		return nil, err
+	}
+	cfg.MetricsPushJob = getEnvString("HOTPOD_METRICS_PUSH_JOB", cfg.MetricsPushJob)
+	if cfg.MetricsDelay, err = getEnvDuration("HOTPOD_METRICS_DELAY", cfg.MetricsDelay); err != nil {
+		return nil, err
+	}
+	if cfg.MetricsDelayJitter, err = getEnvDuration("HOTPOD_METRICS_DELAY_JITTER", cfg.MetricsDelayJitter); err != nil {
+		return nil, err
+	}
+	if cfg.MetricsPadding, err = getEnvSize("HOTPOD_METRICS_PADDING", cfg.MetricsPadding); err != nil {
+		return nil, err
+	}
+	cfg.ProfilingMode = getEnvString("HOTPOD_PROFILING_MODE", cfg.ProfilingMode)
+	cfg.ProfilingURL = getEnvString("HOTPOD_PROFILING_URL", cfg.ProfilingURL)
+	cfg.ProfilingAppName = getEnvString("HOTPOD_PROFILING_APP_NAME", cfg.ProfilingAppName)
+	cfg.ProfilingLabels = getEnvString("HOTPOD_PROFILING_LABELS", cfg.ProfilingLabels)
+	if cfg.ProfilingInterval, err = getEnvDuration("HOTPOD_PROFILING_INTERVAL", cfg.ProfilingInterval); err != nil {
+		return nil, err
+	}
+	cfg.ProfilingTypes = getEnvString("HOTPOD_PROFILING_TYPES", cfg.ProfilingTypes)
+	if cfg.EventLogSize, err = getEnvInt("HOTPOD_EVENT_LOG_SIZE", cfg.EventLogSize); err != nil {
+		return nil, err
+	}
+	if cfg.KubeEvents, err = getEnvBool("HOTPOD_KUBE_EVENTS", cfg.KubeEvents); err != nil {
+		return nil, err
+	}
+	cfg.Zone = getEnvString("HOTPOD_ZONE", cfg.Zone)
+	cfg.Region = getEnvString("HOTPOD_REGION", cfg.Region)
+	cfg.Variant = getEnvString("HOTPOD_VARIANT", cfg.Variant)
+	cfg.DumpDir = getEnvString("HOTPOD_DUMP_DIR", cfg.DumpDir)
+	if cfg.DumpMaxSize, err = getEnvSize("HOTPOD_DUMP_MAX_SIZE", cfg.DumpMaxSize); err != nil {
+		return nil, err
+	}
+	if cfg.DumpMaxFiles, err = getEnvInt("HOTPOD_DUMP_MAX_FILES", cfg.DumpMaxFiles); err != nil {
+		return nil, err
+	}
+	if cfg.NodePressure, err = getEnvBool("HOTPOD_NODE_PRESSURE", cfg.NodePressure); err != nil {
+		return nil, err
+	}
+	if cfg.NodePressureInterval, err = getEnvDuration("HOTPOD_NODE_PRESSURE_INTERVAL", cfg.NodePressureInterval); err != nil {
+		return nil, err
+	}
+	if cfg.SLOAvailability, err = getEnvFloat("HOTPOD_SLO_AVAILABILITY", cfg.SLOAvailability); err != nil {
+		return nil, err
+	}
+	if cfg.SLOLatency, err = getEnvFloat("HOTPOD_SLO_LATENCY", cfg.SLOLatency); err != nil {
+		return nil, err
+	}
+	if cfg.SLOLatencyThreshold, err = getEnvDuration("HOTPOD_SLO_LATENCY_THRESHOLD", cfg.SLOLatencyThreshold); err != nil {
+		return nil, err
+	}
+	if cfg.SLOPeriod, err = getEnvDuration("HOTPOD_SLO_PERIOD", cfg.SLOPeriod); err != nil {
+		return nil, err
+	}
+	cfg.SLOWindows = getEnvString("HOTPOD_SLO_WINDOWS", cfg.SLOWindows)
+	cfg.ProbeTargets = getEnvString("HOTPOD_PROBE_TARGETS", cfg.ProbeTargets)
+	if cfg.ProbeInterval, err = getEnvDuration("HOTPOD_PROBE_INTERVAL", cfg.ProbeInterval); err != nil {
+		return nil, err
+	}
+	if cfg.ProbeTimeout, err = getEnvDuration("HOTPOD_PROBE_TIMEOUT", cfg.ProbeTimeout); err != nil {
+		return nil, err
+	}
 
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -181,6 +671,18 @@ func getEnvInt(key string, defaultVal int) (int, error) {
 	return i, nil
 }
 
+func getEnvFloat(key string, defaultVal float64) (float64, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultVal, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
 func getEnvDuration(key string, defaultVal time.Duration) (time.Duration, error) {
 	v, ok := os.LookupEnv(key)
 	if !ok {
@@ -322,12 +824,117 @@ func (c *Config) IOPath() string {
 	return filepath.Join(IOBasePath, c.IODirName)
 }
 
+// LocalURL returns the base URL for requests to this server's Port over
+// loopback, using https when TLS is on.
+func (c *Config) LocalURL() string {
+	scheme := "http"
+	if c.TLS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://127.0.0.1:%d", scheme, c.Port)
+}
+
+// FleetScheme returns the URL scheme for requests to peers on FleetPort. The
+// management listener is always plain HTTP; any other port is assumed to be
+// Port, which uses https when TLS is on.
+func (c *Config) FleetScheme() string {
+	if c.TLS && (c.MgmtPort == 0 || c.FleetPort != c.MgmtPort) {
+		return "https"
+	}
+	return "http"
+}
+
+// RunsApp reports whether the app endpoints (load, faults, and the queue) are
+// served.
+func (c *Config) RunsApp() bool {
+	return c.Mode == "app" || c.Mode == "combined"
+}
+
+// RunsSidecar reports whether the sidecar baseline runner is active.
+func (c *Config) RunsSidecar() bool {
+	return c.Mode == "sidecar" || c.Mode == "combined"
+}
+
+// Features returns the names of the optional features this configuration
+// turns on, in a fixed order, so a report can record how the server that
+// produced it was set up.
+func (c *Config) Features() []string {
+	features := []string{}
+	for _, f := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"tls", c.TLS},
+		{"proxy-protocol", c.ProxyProtocol != ""},
+		{"mgmt-port", c.MgmtPort > 0},
+		{"pprof", c.EnablePprof},
+		{"chaos", c.RunsApp() && !c.DisableChaos},
+		{"queue", c.RunsApp() && !c.DisableQueue},
+		{"read-only", c.ReadOnly},
+		{"disabled-endpoints", c.DisableEndpoints != ""},
+		{"load-shedding", c.ShedMaxInFlight > 0 || c.ShedMaxCPU > 0},
+		{"sidecar", c.RunsSidecar()},
+		{"sidecar-proxy", c.SidecarProxyPort > 0},
+		{"admin-auth", c.AdminToken != "" || c.AdminTokens != "" || c.AdminTokensFile != ""},
+		{"oidc", c.OIDCIssuer != ""},
+		{"metrics-push", c.MetricsPushMode != ""},
+		{"profiling", c.ProfilingMode != ""},
+		{"schedule", c.ScheduleCPU != "" || c.ScheduleMemory != "" || c.ScheduleQueue != ""},
+		{"controller", c.Controller},
+		{"fleet", c.FleetDNS != "" || c.FleetSelector != ""},
+		{"custom-metrics", c.CustomMetricsPort > 0},
+		{"leader-election", c.LeaderElection},
+		{"state-file", c.StateFile != ""},
+		{"kube-events", c.KubeEvents},
+		{"node-pressure", c.NodePressure},
+		{"slo", c.SLOAvailability > 0 || c.SLOLatency > 0},
+		{"probe", c.ProbeTargets != ""},
+	} {
+		if f.enabled {
+			features = append(features, f.name)
+		}
+	}
+	return features
+}
+
 // Validate checks that configuration values are valid.
 func (c *Config) Validate() error {
 	if c.Port < 1 || c.Port > 65535 {
 		return fmt.Errorf("port must be between 1 and 65535, got %d", c.Port)
 	}
 
+	if c.MgmtPort < 0 || c.MgmtPort > 65535 {
+		return fmt.Errorf("management port must be between 0 and 65535, got %d", c.MgmtPort)
+	}
+	if c.MgmtPort > 0 && c.MgmtPort == c.Port {
+		return fmt.Errorf("management port must differ from the server port %d", c.Port)
+	}
+
+	switch c.ProxyProtocol {
+	case "", "optional", "required":
+	default:
+		return fmt.Errorf("PROXY protocol must be one of: optional, required, got %q", c.ProxyProtocol)
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("TLS cert and key files must be set together")
+	}
+	if c.TLSCertFile != "" && !c.TLS {
+		return errors.New("TLS cert and key files require TLS to be enabled")
+	}
+
+	if c.EnablePprof {
+		if c.PprofPort < 1 || c.PprofPort > 65535 {
+			return fmt.Errorf("pprof port must be between 1 and 65535, got %d", c.PprofPort)
+		}
+		if c.PprofPort == c.Port {
+			return fmt.Errorf("pprof port must differ from the server port %d", c.Port)
+		}
+		if c.PprofPort == c.MgmtPort {
+			return fmt.Errorf("pprof port must differ from the management port %d", c.MgmtPort)
+		}
+	}
+
 	if c.StartupDelay < 0 {
 		return fmt.Errorf("startup delay must be non-negative, got %s", c.StartupDelay)
 	}
@@ -344,15 +951,82 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("shutdown timeout must be non-negative, got %s", c.ShutdownTimeout)
 	}
 
+	if c.PreStopDelay < 0 {
+		return fmt.Errorf("preStop delay must be non-negative, got %s", c.PreStopDelay)
+	}
+
+	if c.HealthDelay < 0 || c.HealthDelayJitter < 0 || c.HealthDelay+c.HealthDelayJitter > 5*time.Minute {
+		return fmt.Errorf("health delay and jitter must be non-negative and total at most 5m, got %s and %s", c.HealthDelay, c.HealthDelayJitter)
+	}
+
+	if c.MetricsDelay < 0 || c.MetricsDelayJitter < 0 || c.MetricsDelay+c.MetricsDelayJitter > 5*time.Minute {
+		return fmt.Errorf("metrics delay and jitter must be non-negative and total at most 5m, got %s and %s", c.MetricsDelay, c.MetricsDelayJitter)
+	}
+	if c.MetricsPadding < 0 || c.MetricsPadding > 64<<20 {
+		return fmt.Errorf("metrics padding must be between 0 and 64MB, got %d", c.MetricsPadding)
+	}
+
+	if c.ChaosMaxDestructive < 0 {
+		return fmt.Errorf("chaos max destructive must be non-negative, got %d", c.ChaosMaxDestructive)
+	}
+
+	switch c.SigtermBehavior {
+	case "", "graceful", "ignore", "exit-immediately", "crash-after":
+	default:
+		return fmt.Errorf("SIGTERM behavior must be one of: graceful, ignore, exit-immediately, crash-after, got %q", c.SigtermBehavior)
+	}
+
+	if c.SigtermDelay < 0 {
+		return fmt.Errorf("SIGTERM delay must be non-negative, got %s", c.SigtermDelay)
+	}
+
 	if c.RequestTimeout < 0 {
 		return fmt.Errorf("request timeout must be non-negative, got %s", c.RequestTimeout)
 	}
 
+	if _, err := c.RequestTimeoutOverrides(); err != nil {
+		return err
+	}
+
+	if _, err := c.DisabledEndpointGroups(); err != nil {
+		return err
+	}
+	if c.ReadOnly && c.StateFile != "" {
+		// Restored faults and overrides could not be cleared in read-only mode.
+		return errors.New("read-only mode cannot be combined with a state file")
+	}
+
+	if c.AdmissionQueueTimeout < 0 {
+		return fmt.Errorf("admission queue timeout must be non-negative, got %s", c.AdmissionQueueTimeout)
+	}
+
+	if c.ShedMaxInFlight < 0 {
+		return fmt.Errorf("shed max in-flight must be non-negative, got %d", c.ShedMaxInFlight)
+	}
+
+	if c.ShedMaxCPU < 0 {
+		return fmt.Errorf("shed max CPU must be non-negative, got %s", c.ShedMaxCPU)
+	}
+
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLevels[c.LogLevel] {
 		return fmt.Errorf("invalid log level %q, must be one of: debug, info, warn, error", c.LogLevel)
 	}
 
+	switch c.LogFormat {
+	case "", "json", "logfmt", "text":
+	default:
+		return fmt.Errorf("invalid log format %q, must be one of: json, logfmt, text", c.LogFormat)
+	}
+
+	if c.LogMaxSize < 0 {
+		return fmt.Errorf("log max size must be non-negative, got %d", c.LogMaxSize)
+	}
+
+	if c.LogMaxFiles < 0 {
+		return fmt.Errorf("log max files must be non-negative, got %d", c.LogMaxFiles)
+	}
+
 	if c.MaxCPUDuration < 0 {
 		return fmt.Errorf("max CPU duration must be non-negative, got %s", c.MaxCPUDuration)
 	}
@@ -369,8 +1043,25 @@ func (c *Config) Validate() error {
 		return err
 	}
 
-	if c.Mode != "app" && c.Mode != "sidecar" {
-		return fmt.Errorf("mode must be \"app\" or \"sidecar\", got %q", c.Mode)
+	switch c.Mode {
+	case "app", "sidecar", "combined", "init":
+	default:
+		return fmt.Errorf("mode must be \"app\", \"sidecar\", \"combined\", or \"init\", got %q", c.Mode)
+	}
+
+	if c.Mode == "init" {
+		if c.InitCPU < 0 || c.InitIOSize < 0 || c.InitSleep < 0 {
+			return errors.New("init CPU, I/O size, and sleep must be non-negative")
+		}
+		if c.InitCPU > 0 && c.InitCores < 1 {
+			return fmt.Errorf("init cores must be at least 1, got %d", c.InitCores)
+		}
+		if c.InitIOSize > c.MaxIOSize {
+			return fmt.Errorf("init I/O size %d exceeds max I/O size %d", c.InitIOSize, c.MaxIOSize)
+		}
+		if c.InitExitCode < 0 || c.InitExitCode > 255 {
+			return fmt.Errorf("init exit code must be between 0 and 255, got %d", c.InitExitCode)
+		}
 	}
 
 	if c.SidecarCPUBaseline < 0 || c.SidecarCPUBaseline > time.Second {
@@ -385,13 +1076,297 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("sidecar memory baseline must be non-negative, got %d", c.SidecarMemoryBaseline)
 	}
 
+	if c.SidecarMemoryJitter < 0 {
+		return fmt.Errorf("sidecar memory jitter must be non-negative, got %d", c.SidecarMemoryJitter)
+	}
+
+	if c.SidecarMemoryPeak < 0 {
+		return fmt.Errorf("sidecar memory peak must be non-negative, got %d", c.SidecarMemoryPeak)
+	}
+
+	if c.SidecarMemoryPeak > 0 && c.SidecarMemoryRamp <= 0 {
+		return fmt.Errorf("sidecar memory ramp must be positive when a peak is set, got %s", c.SidecarMemoryRamp)
+	}
+
 	if c.SidecarRequestOverhead < 0 {
 		return fmt.Errorf("sidecar request overhead must be non-negative, got %s", c.SidecarRequestOverhead)
 	}
 
+	if c.SidecarProxyPort < 0 || c.SidecarProxyPort > 65535 {
+		return fmt.Errorf("sidecar proxy port must be between 0 and 65535, got %d", c.SidecarProxyPort)
+	}
+
+	if c.SidecarProxyPort > 0 {
+		if c.SidecarProxyPort == c.Port {
+			return fmt.Errorf("sidecar proxy port must differ from the server port %d", c.Port)
+		}
+		if _, err := c.SidecarProxyURL(); err != nil {
+			return err
+		}
+	}
+
+	if c.EventLogSize < 0 {
+		return fmt.Errorf("event log size must be non-negative, got %d", c.EventLogSize)
+	}
+
+	if c.DumpMaxSize < 0 {
+		return fmt.Errorf("dump max size must be non-negative, got %d", c.DumpMaxSize)
+	}
+
+	if c.DumpMaxFiles < 0 {
+		return fmt.Errorf("dump max files must be non-negative, got %d", c.DumpMaxFiles)
+	}
+
+	if c.OIDCIssuer != "" {
+		if c.OIDCRoleClaim == "" {
+			return errors.New("OIDC role claim must not be empty")
+		}
+		switch c.OIDCDefaultRole {
+		case "", "read", "mutate", "chaos":
+		default:
+			return fmt.Errorf("OIDC default role must be one of: read, mutate, chaos, got %q", c.OIDCDefaultRole)
+		}
+	}
+
+	if c.FleetDNS != "" && c.FleetSelector != "" {
+		return errors.New("only one of fleet DNS and fleet selector may be set")
+	}
+
+	if (c.FleetDNS != "" || c.FleetSelector != "") && (c.FleetPort < 1 || c.FleetPort > 65535) {
+		return fmt.Errorf("fleet port must be between 1 and 65535, got %d", c.FleetPort)
+	}
+
+	if c.CustomMetricsPort < 0 || c.CustomMetricsPort > 65535 {
+		return fmt.Errorf("custom metrics port must be between 0 and 65535, got %d", c.CustomMetricsPort)
+	}
+
+	if c.CustomMetricsPort > 0 && c.CustomMetricsPort == c.Port {
+		return fmt.Errorf("custom metrics port must differ from the server port %d", c.Port)
+	}
+
+	if (c.CustomMetricsCertFile == "") != (c.CustomMetricsKeyFile == "") {
+		return errors.New("custom metrics cert file and key file must be set together")
+	}
+
+	if c.LeaderElection {
+		if c.LeaderLeaseName == "" {
+			return errors.New("leader lease name must not be empty")
+		}
+		if c.LeaderRetryPeriod <= 0 {
+			return fmt.Errorf("leader retry period must be positive, got %s", c.LeaderRetryPeriod)
+		}
+		if c.LeaderRenewDeadline <= c.LeaderRetryPeriod {
+			return fmt.Errorf("leader renew deadline (%s) must be greater than retry period (%s)", c.LeaderRenewDeadline, c.LeaderRetryPeriod)
+		}
+		if c.LeaderLeaseDuration < time.Second || c.LeaderLeaseDuration <= c.LeaderRenewDeadline {
+			return fmt.Errorf("leader lease duration (%s) must be at least 1s and greater than renew deadline (%s)", c.LeaderLeaseDuration, c.LeaderRenewDeadline)
+		}
+	}
+
+	if c.ReadyQueueHigh < 0 {
+		return fmt.Errorf("ready queue high watermark must be non-negative, got %d", c.ReadyQueueHigh)
+	}
+	if c.ReadyQueueLow < 0 || (c.ReadyQueueLow > 0 && c.ReadyQueueLow >= c.ReadyQueueHigh) {
+		return fmt.Errorf("ready queue low watermark (%d) must be non-negative and less than the high watermark (%d)", c.ReadyQueueLow, c.ReadyQueueHigh)
+	}
+
+	if c.ProtectedBasicAuth != "" {
+		if user, _, ok := strings.Cut(c.ProtectedBasicAuth, ":"); !ok || user == "" {
+			return errors.New("protected basic auth must be user:password")
+		}
+	}
+	if c.ProtectedHeader != "" {
+		if name, _, _ := strings.Cut(c.ProtectedHeader, "="); strings.TrimSpace(name) == "" || strings.ContainsAny(name, " \t:") {
+			return fmt.Errorf("protected header must be Name or Name=value, got %q", c.ProtectedHeader)
+		}
+	}
+
+	if len(c.Variant) > maxVariantLength || strings.ContainsFunc(c.Variant, invalidVariantRune) {
+		return fmt.Errorf("variant must be at most %d letters, digits, '.', '_', or '-', got %q", maxVariantLength, c.Variant)
+	}
+
+	if c.NodePressure && c.NodePressureInterval <= 0 {
+		return fmt.Errorf("node pressure interval must be positive, got %s", c.NodePressureInterval)
+	}
+
+	if c.SLOAvailability < 0 || c.SLOAvailability >= 1 {
+		return fmt.Errorf("SLO availability must be at least 0 and less than 1, got %g", c.SLOAvailability)
+	}
+	if c.SLOLatency < 0 || c.SLOLatency >= 1 {
+		return fmt.Errorf("SLO latency must be at least 0 and less than 1, got %g", c.SLOLatency)
+	}
+	if c.SLOLatency > 0 && c.SLOLatencyThreshold <= 0 {
+		return fmt.Errorf("SLO latency threshold must be positive, got %s", c.SLOLatencyThreshold)
+	}
+	if (c.SLOAvailability > 0 || c.SLOLatency > 0) && c.SLOPeriod <= 0 {
+		return fmt.Errorf("SLO period must be positive, got %s", c.SLOPeriod)
+	}
+
+	if c.ProbeTargets != "" {
+		if c.ProbeInterval <= 0 {
+			return fmt.Errorf("probe interval must be positive, got %s", c.ProbeInterval)
+		}
+		if c.ProbeTimeout <= 0 || c.ProbeTimeout > c.ProbeInterval {
+			return fmt.Errorf("probe timeout (%s) must be positive and at most the probe interval (%s)", c.ProbeTimeout, c.ProbeInterval)
+		}
+	}
+
+	if c.Controller && c.ControllerResync <= 0 {
+		return fmt.Errorf("controller resync must be positive, got %s", c.ControllerResync)
+	}
+
+	switch c.MetricsPushMode {
+	case "":
+	case "pushgateway", "remote_write":
+		if c.MetricsPushURL == "" {
+			return fmt.Errorf("metrics push URL is required when push mode is %q", c.MetricsPushMode)
+		}
+		if c.MetricsPushInterval <= 0 {
+			return fmt.Errorf("metrics push interval must be positive, got %s", c.MetricsPushInterval)
+		}
+		if c.MetricsPushJob == "" {
+			return errors.New("metrics push job must not be empty")
+		}
+	default:
+		return fmt.Errorf("metrics push mode must be \"pushgateway\" or \"remote_write\", got %q", c.MetricsPushMode)
+	}
+
+	switch c.ProfilingMode {
+	case "":
+	case "pyroscope":
+		if c.ProfilingURL == "" {
+			return errors.New("profiling URL is required when profiling mode is \"pyroscope\"")
+		}
+		if c.ProfilingInterval < time.Second {
+			return fmt.Errorf("profiling interval must be at least 1s, got %s", c.ProfilingInterval)
+		}
+		if c.ProfilingAppName == "" {
+			return errors.New("profiling app name must not be empty")
+		}
+		if _, err := c.ProfilingLabelSet(); err != nil {
+			return err
+		}
+		if _, err := c.ProfilingTypeList(); err != nil {
+			return err
+		}
+	case "parca":
+		if !c.EnablePprof {
+			return errors.New("profiling mode \"parca\" requires the pprof server (HOTPOD_ENABLE_PPROF) for Parca to scrape")
+		}
+	default:
+		return fmt.Errorf("profiling mode must be \"pyroscope\" or \"parca\", got %q", c.ProfilingMode)
+	}
+
 	return nil
 }
 
+// RequestTimeoutGroups are the endpoint groups whose timeout RequestTimeouts
+// can override, each named by the first segment of its paths.
+var RequestTimeoutGroups = []string{"cpu", "memory", "io", "work", "latency", "dns", "queue", "fault", "admin"}
+
+// RequestTimeoutOverrides parses RequestTimeouts into a map of endpoint group
+// to timeout.
+func (c *Config) RequestTimeoutOverrides() (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, pair := range strings.Split(c.RequestTimeouts, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		group, v, ok := strings.Cut(pair, "=")
+		group = strings.TrimSpace(group)
+		if !ok || !slices.Contains(RequestTimeoutGroups, group) {
+			return nil, fmt.Errorf("request timeouts must be comma-separated group=duration pairs with a group of %s, got %q", strings.Join(RequestTimeoutGroups, ", "), pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("request timeout for %s must be a non-negative duration, got %q", group, v)
+		}
+		timeouts[group] = d
+	}
+	return timeouts, nil
+}
+
+// GroupRequestTimeout returns the request timeout for an endpoint group,
+// applying any override from RequestTimeouts.
+func (c *Config) GroupRequestTimeout(group string) time.Duration {
+	timeouts, _ := c.RequestTimeoutOverrides()
+	if d, ok := timeouts[group]; ok {
+		return d
+	}
+	return c.RequestTimeout
+}
+
+// EndpointGroups are the endpoint groups DisableEndpoints can disable, each
+// named by the first segment of its paths.
+var EndpointGroups = []string{"cpu", "memory", "io", "work", "latency", "dns", "queue", "fault"}
+
+// DisabledEndpointGroups parses DisableEndpoints, rejecting unknown groups.
+func (c *Config) DisabledEndpointGroups() ([]string, error) {
+	var groups []string
+	for _, group := range strings.Split(c.DisableEndpoints, ",") {
+		group = strings.TrimSpace(group)
+		if group == "" {
+			continue
+		}
+		if !slices.Contains(EndpointGroups, group) {
+			return nil, fmt.Errorf("disabled endpoints must be comma-separated groups of %s, got %q", strings.Join(EndpointGroups, ", "), group)
+		}
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// ProfilingLabelSet parses ProfilingLabels into a map.
+func (c *Config) ProfilingLabelSet() (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(c.ProfilingLabels, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || strings.ContainsAny(k+v, "{}\"") {
+			return nil, fmt.Errorf("profiling labels must be comma-separated key=value pairs, got %q", pair)
+		}
+		labels[k] = v
+	}
+	return labels, nil
+}
+
+// ProfilingTypeList parses ProfilingTypes, rejecting unknown types.
+func (c *Config) ProfilingTypeList() ([]string, error) {
+	var types []string
+	for _, t := range strings.Split(c.ProfilingTypes, ",") {
+		t = strings.TrimSpace(t)
+		switch t {
+		case "":
+			continue
+		case "cpu", "heap", "goroutine", "mutex", "block":
+			types = append(types, t)
+		default:
+			return nil, fmt.Errorf("profiling type must be one of cpu, heap, goroutine, mutex, or block, got %q", t)
+		}
+	}
+	if len(types) == 0 {
+		return nil, errors.New("at least one profiling type is required")
+	}
+	return types, nil
+}
+
+// SidecarProxyURL parses SidecarProxyTarget, which must be an absolute http or
+// https URL.
+func (c *Config) SidecarProxyURL() (*url.URL, error) {
+	u, err := url.Parse(c.SidecarProxyTarget)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("sidecar proxy target must be an http or https URL, got %q", c.SidecarProxyTarget)
+	}
+	return u, nil
+}
+
 // validateIODirName ensures the I/O directory name is safe.
 // It must be non-empty, lowercase alphanumeric with optional hyphens,
 // no slashes, no special characters, no URL-encoded sequences.
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -45,6 +48,14 @@ var negativeDurationTests = []negativeDurationTest{
 	{"ShutdownDelay", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", ShutdownDelay: -1}},
 	{"ShutdownTimeout", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", ShutdownTimeout: -1}},
 	{"RequestTimeout", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", RequestTimeout: -1}},
+	{"HealthDelay", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", HealthDelay: -1}},
+	{"HealthDelayJitter", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", HealthDelayJitter: -1}},
+	{"MetricsDelay", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", MetricsDelay: -1}},
+	{"MetricsDelayJitter", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", MetricsDelayJitter: -1}},
+	{"ChaosMaxDestructive", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", ChaosMaxDestructive: -1}},
+	{"DumpMaxSize", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", DumpMaxSize: -1}},
+	{"DumpMaxFiles", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", DumpMaxFiles: -1}},
+	{"NodePressureInterval", Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", NodePressure: true, NodePressureInterval: -1}},
 }
 
 func TestLoadDefaults(t *testing.T) {
@@ -238,6 +249,53 @@ func TestLoadMaxCPUDurationDefault(t *testing.T) {
 	}
 }
 
+func TestLoadFleetPortDefault(t *testing.T) {
+	tests := []struct {
+		mgmt, fleet string
+		want        int
+	}{
+		{"", "", 8080},
+		{"9090", "", 9090},
+		{"9090", "7070", 7070},
+	}
+
+	for _, tt := range tests {
+		for key, v := range map[string]string{"HOTPOD_MGMT_PORT": tt.mgmt, "HOTPOD_FLEET_PORT": tt.fleet} {
+			t.Setenv(key, v)
+			if v == "" {
+				os.Unsetenv(key)
+			}
+		}
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.FleetPort != tt.want {
+			t.Errorf("mgmt port %q, fleet port %q: FleetPort = %d, want %d", tt.mgmt, tt.fleet, cfg.FleetPort, tt.want)
+		}
+	}
+}
+
+func TestFleetScheme(t *testing.T) {
+	tests := []struct {
+		tls             bool
+		mgmt, fleetPort int
+		want            string
+	}{
+		{false, 0, 8080, "http"},
+		{true, 0, 8080, "https"},
+		{true, 9090, 9090, "http"},
+		{true, 9090, 8080, "https"},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{Port: 8080, TLS: tt.tls, MgmtPort: tt.mgmt, FleetPort: tt.fleetPort}
+		if got := cfg.FleetScheme(); got != tt.want {
+			t.Errorf("tls %v, mgmt port %d, fleet port %d: FleetScheme() = %q, want %q", tt.tls, tt.mgmt, tt.fleetPort, got, tt.want)
+		}
+	}
+}
+
 func TestLoadMaxCPUDurationFromEnv(t *testing.T) {
 	os.Setenv("HOTPOD_MAX_CPU_DURATION", "30s")
 	os.Setenv("HOTPOD_MAX_MEMORY_SIZE", "512MB")
@@ -396,12 +454,35 @@ type modeValidationTest struct {
 var modeValidationTests = []modeValidationTest{
 	{"app", false},
 	{"sidecar", false},
+	{"combined", false},
+	{"init", false},
 	{"", true},
 	{"invalid", true},
 	{"APP", true},
 	{"SIDECAR", true},
 }
 
+func TestModePersonalities(t *testing.T) {
+	tests := []struct {
+		mode        string
+		wantApp     bool
+		wantSidecar bool
+	}{
+		{"app", true, false},
+		{"sidecar", false, true},
+		{"combined", true, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Mode: tt.mode}
+		if got := cfg.RunsApp(); got != tt.wantApp {
+			t.Errorf("RunsApp() Mode=%q = %v, want %v", tt.mode, got, tt.wantApp)
+		}
+		if got := cfg.RunsSidecar(); got != tt.wantSidecar {
+			t.Errorf("RunsSidecar() Mode=%q = %v, want %v", tt.mode, got, tt.wantSidecar)
+		}
+	}
+}
+
 func TestValidateMode(t *testing.T) {
 	for _, tt := range modeValidationTests {
 		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: tt.mode}
@@ -412,17 +493,43 @@ func TestValidateMode(t *testing.T) {
 	}
 }
 
+func TestValidateSigtermBehavior(t *testing.T) {
+	tests := []struct {
+		behavior string
+		wantErr  bool
+	}{
+		{"graceful", false},
+		{"ignore", false},
+		{"exit-immediately", false},
+		{"crash-after", false},
+		{"crash", true},
+		{"IGNORE", true},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", SigtermBehavior: tt.behavior}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate() SigtermBehavior=%q, error=%v, wantErr=%v", tt.behavior, err, tt.wantErr)
+		}
+	}
+}
+
 func TestLoadSidecarConfig(t *testing.T) {
 	os.Setenv("HOTPOD_MODE", "sidecar")
 	os.Setenv("HOTPOD_SIDECAR_CPU_BASELINE", "200m")
 	os.Setenv("HOTPOD_SIDECAR_CPU_JITTER", "20m")
 	os.Setenv("HOTPOD_SIDECAR_MEMORY_BASELINE", "100Mi")
 	os.Setenv("HOTPOD_SIDECAR_REQUEST_OVERHEAD", "5m")
+	os.Setenv("HOTPOD_SIDECAR_MEMORY_JITTER", "10Mi")
+	os.Setenv("HOTPOD_SIDECAR_MEMORY_PEAK", "150Mi")
+	os.Setenv("HOTPOD_SIDECAR_MEMORY_RAMP", "5m")
 	defer func() {
 		for _, key := range []string{
 			"HOTPOD_MODE", "HOTPOD_SIDECAR_CPU_BASELINE",
 			"HOTPOD_SIDECAR_CPU_JITTER", "HOTPOD_SIDECAR_MEMORY_BASELINE",
-			"HOTPOD_SIDECAR_REQUEST_OVERHEAD",
+			"HOTPOD_SIDECAR_REQUEST_OVERHEAD", "HOTPOD_SIDECAR_MEMORY_JITTER",
+			"HOTPOD_SIDECAR_MEMORY_PEAK", "HOTPOD_SIDECAR_MEMORY_RAMP",
 		} {
 			os.Unsetenv(key)
 		}
@@ -448,6 +555,42 @@ func TestLoadSidecarConfig(t *testing.T) {
 	if cfg.SidecarRequestOverhead != 5*time.Millisecond {
 		t.Errorf("SidecarRequestOverhead = %v, want 5ms", cfg.SidecarRequestOverhead)
 	}
+	if cfg.SidecarMemoryJitter != 10<<20 {
+		t.Errorf("SidecarMemoryJitter = %d, want %d (10Mi)", cfg.SidecarMemoryJitter, 10<<20)
+	}
+	if cfg.SidecarMemoryPeak != 150<<20 {
+		t.Errorf("SidecarMemoryPeak = %d, want %d (150Mi)", cfg.SidecarMemoryPeak, 150<<20)
+	}
+	if cfg.SidecarMemoryRamp != 5*time.Minute {
+		t.Errorf("SidecarMemoryRamp = %v, want 5m", cfg.SidecarMemoryRamp)
+	}
+}
+
+func TestValidateSidecarMemoryRamp(t *testing.T) {
+	base := Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "sidecar"}
+
+	// Valid: no peak, so the ramp is unused
+	if err := base.Validate(); err != nil {
+		t.Errorf("Validate() without peak should not error: %v", err)
+	}
+
+	// Invalid: peak without a ramp
+	base.SidecarMemoryPeak = 100 << 20
+	if err := base.Validate(); err == nil {
+		t.Error("Validate() peak without ramp should error")
+	}
+
+	// Valid: peak with a ramp
+	base.SidecarMemoryRamp = time.Minute
+	if err := base.Validate(); err != nil {
+		t.Errorf("Validate() peak with ramp should not error: %v", err)
+	}
+
+	// Invalid: negative jitter
+	base.SidecarMemoryJitter = -1
+	if err := base.Validate(); err == nil {
+		t.Error("Validate() negative jitter should error")
+	}
 }
 
 func TestLoadSidecarDefaults(t *testing.T) {
@@ -506,3 +649,462 @@ func TestValidateSidecarCPUBaselineRange(t *testing.T) {
 		t.Error("Validate() baseline<0 should error")
 	}
 }
+
+type metricsPushValidationTest struct {
+	name    string
+	mode    string
+	url     string
+	wantErr bool
+}
+
+var metricsPushValidationTests = []metricsPushValidationTest{
+	{"disabled", "", "", false},
+	{"pushgateway", "pushgateway", "http://pushgateway:9091", false},
+	{"remote_write", "remote_write", "http://prometheus:9090/api/v1/write", false},
+	{"missing URL", "remote_write", "", true},
+	{"unknown mode", "graphite", "http://example", true},
+}
+
+func TestValidateMetricsPush(t *testing.T) {
+	for _, tt := range metricsPushValidationTests {
+		cfg := &Config{
+			Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app",
+			MetricsPushMode: tt.mode, MetricsPushURL: tt.url,
+			MetricsPushInterval: 15 * time.Second, MetricsPushJob: "hotpod",
+		}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateOIDCDefaultRole(t *testing.T) {
+	base := Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", OIDCIssuer: "https://issuer", OIDCRoleClaim: "hotpod_role"}
+
+	for _, role := range []string{"", "read", "chaos"} {
+		cfg := base
+		cfg.OIDCDefaultRole = role
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("default role %q: unexpected error %v", role, err)
+		}
+	}
+
+	cfg := base
+	cfg.OIDCDefaultRole = "root"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for invalid OIDC default role")
+	}
+}
+
+func TestValidateReadOnlyStateFile(t *testing.T) {
+	base := Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", ReadOnly: true}
+	if err := base.Validate(); err != nil {
+		t.Errorf("read-only: unexpected error %v", err)
+	}
+
+	cfg := base
+	cfg.StateFile = "/var/lib/hotpod/state.json"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for read-only mode with a state file")
+	}
+}
+
+type pprofValidationTest struct {
+	name    string
+	enabled bool
+	port    int
+	wantErr bool
+}
+
+var pprofValidationTests = []pprofValidationTest{
+	{"disabled ignores port", false, 0, false},
+	{"default", true, 6060, false},
+	{"zero port", true, 0, true},
+	{"same as server", true, 8080, true},
+}
+
+func TestValidatePprof(t *testing.T) {
+	for _, tt := range pprofValidationTests {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", EnablePprof: tt.enabled, PprofPort: tt.port}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateMgmtPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		port    int
+		wantErr bool
+	}{
+		{"disabled", 0, false},
+		{"separate", 9090, false},
+		{"negative", -1, true},
+		{"too large", 70000, true},
+		{"same as server", 8080, true},
+		{"same as pprof", 6060, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", EnablePprof: true, PprofPort: 6060, MgmtPort: tt.port}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateReadyQueue(t *testing.T) {
+	tests := []struct {
+		name      string
+		high, low int
+		wantErr   bool
+	}{
+		{"disabled", 0, 0, false},
+		{"default low", 100, 0, false},
+		{"explicit low", 100, 20, false},
+		{"negative high", -1, 0, true},
+		{"low at high", 100, 100, true},
+		{"low without high", 0, 10, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", ReadyQueueHigh: tt.high, ReadyQueueLow: tt.low}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateSLO(t *testing.T) {
+	tests := []struct {
+		name                  string
+		availability, latency float64
+		threshold, period     time.Duration
+		wantErr               bool
+	}{
+		{"disabled", 0, 0, 0, 0, false},
+		{"availability", 0.999, 0, 0, time.Hour, false},
+		{"latency", 0, 0.99, time.Second, time.Hour, false},
+		{"availability of one", 1, 0, 0, time.Hour, true},
+		{"negative latency", 0, -0.5, time.Second, time.Hour, true},
+		{"latency without threshold", 0, 0.99, 0, time.Hour, true},
+		{"no period", 0.999, 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app",
+			SLOAvailability: tt.availability, SLOLatency: tt.latency, SLOLatencyThreshold: tt.threshold, SLOPeriod: tt.period}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateProbe(t *testing.T) {
+	tests := []struct {
+		name              string
+		targets           string
+		interval, timeout time.Duration
+		wantErr           bool
+	}{
+		{"disabled", "", 0, 0, false},
+		{"enabled", "/healthz", 30 * time.Second, 5 * time.Second, false},
+		{"no interval", "/healthz", 0, 5 * time.Second, true},
+		{"timeout beyond interval", "/healthz", time.Second, 5 * time.Second, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app",
+			ProbeTargets: tt.targets, ProbeInterval: tt.interval, ProbeTimeout: tt.timeout}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateProtected(t *testing.T) {
+	tests := []struct {
+		name       string
+		basic, hdr string
+		wantErr    bool
+	}{
+		{"disabled", "", "", false},
+		{"basic", "alice:s3cr:et", "", false},
+		{"header", "", "X-Forwarded-User", false},
+		{"header value", "", "X-Api-Key=secret", false},
+		{"no password separator", "alice", "", true},
+		{"no user", ":secret", "", true},
+		{"no header name", "", "=secret", true},
+		{"bad header name", "", "X Api Key", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app",
+			ProtectedBasicAuth: tt.basic, ProtectedHeader: tt.hdr}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateVariant(t *testing.T) {
+	for _, tt := range []struct {
+		variant string
+		wantErr bool
+	}{
+		{"", false},
+		{"canary", false},
+		{"v1.2_stable-b", false},
+		{"canary blue", true},
+		{`canary"`, true},
+		{strings.Repeat("a", 64), true},
+	} {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", Variant: tt.variant}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: Validate() error=%v, wantErr=%v", tt.variant, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateProxyProtocol(t *testing.T) {
+	for _, tt := range []struct {
+		mode    string
+		wantErr bool
+	}{
+		{"", false},
+		{"optional", false},
+		{"required", false},
+		{"v2", true},
+	} {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", ProxyProtocol: tt.mode}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: Validate() error=%v, wantErr=%v", tt.mode, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		tls     bool
+		cert    string
+		key     string
+		wantErr bool
+	}{
+		{"disabled", false, "", "", false},
+		{"self-signed", true, "", "", false},
+		{"files", true, "tls.crt", "tls.key", false},
+		{"cert without key", true, "tls.crt", "", true},
+		{"files without TLS", false, "tls.crt", "tls.key", true},
+	} {
+		cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", TLS: tt.tls, TLSCertFile: tt.cert, TLSKeyFile: tt.key}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+type profilingValidationTest struct {
+	name    string
+	mode    string
+	url     string
+	labels  string
+	types   string
+	pprof   bool
+	wantErr bool
+}
+
+var profilingValidationTests = []profilingValidationTest{
+	{"disabled", "", "", "", "", false, false},
+	{"pyroscope", "pyroscope", "http://pyroscope:4040", "env=dev, team=sre", "cpu,heap", false, false},
+	{"pyroscope without URL", "pyroscope", "", "", "cpu", false, true},
+	{"bad labels", "pyroscope", "http://pyroscope:4040", "env", "cpu", false, true},
+	{"unknown type", "pyroscope", "http://pyroscope:4040", "", "cpu,trace", false, true},
+	{"no types", "pyroscope", "http://pyroscope:4040", "", " , ", false, true},
+	{"parca", "parca", "", "", "", true, false},
+	{"parca without pprof", "parca", "", "", "", false, true},
+	{"unknown mode", "datadog", "", "", "", false, true},
+}
+
+func TestValidateProfiling(t *testing.T) {
+	for _, tt := range profilingValidationTests {
+		cfg := &Config{
+			Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app",
+			EnablePprof: tt.pprof, PprofPort: 6060,
+			ProfilingMode: tt.mode, ProfilingURL: tt.url, ProfilingAppName: "hotpod",
+			ProfilingLabels: tt.labels, ProfilingTypes: tt.types, ProfilingInterval: 10 * time.Second,
+		}
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateSidecarProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		port    int
+		target  string
+		wantErr bool
+	}{
+		{"disabled", 0, "", false},
+		{"valid", 15001, "http://127.0.0.1:8081", false},
+		{"https", 15001, "https://app.example:8443", false},
+		{"missing target", 15001, "", true},
+		{"relative target", 15001, "127.0.0.1:8081", true},
+		{"bad scheme", 15001, "ftp://127.0.0.1:8081", true},
+		{"same as server port", 8080, "http://127.0.0.1:8081", true},
+		{"out of range", 70000, "http://127.0.0.1:8081", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "sidecar", SidecarProxyPort: tt.port, SidecarProxyTarget: tt.target}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCustomMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		port    int
+		cert    string
+		key     string
+		wantErr bool
+	}{
+		{"disabled", 0, "", "", false},
+		{"self-signed", 6443, "", "", false},
+		{"cert and key", 6443, "/tls/tls.crt", "/tls/tls.key", false},
+		{"cert without key", 6443, "/tls/tls.crt", "", true},
+		{"key without cert", 6443, "", "/tls/tls.key", true},
+		{"same as server port", 8080, "", "", true},
+		{"out of range", 70000, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "app", CustomMetricsPort: tt.port, CustomMetricsCertFile: tt.cert, CustomMetricsKeyFile: tt.key}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateInitMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"work and exit code", func(c *Config) { c.InitCPU = time.Second; c.InitIOSize = 1 << 20; c.InitExitCode = 3 }, false},
+		{"negative sleep", func(c *Config) { c.InitSleep = -time.Second }, true},
+		{"zero cores", func(c *Config) { c.InitCPU = time.Second; c.InitCores = 0 }, true},
+		{"io over max", func(c *Config) { c.InitIOSize = 2 << 30 }, true},
+		{"exit code out of range", func(c *Config) { c.InitExitCode = 256 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Port: 8080, LogLevel: "info", IODirName: "test", Mode: "init", InitCores: 1, MaxIOSize: 1 << 30}
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDisabledEndpointGroups(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"io, memory,,io", []string{"io", "memory"}, false},
+		{"fault", []string{"fault"}, false},
+		{"admin", nil, true},
+		{"disk", nil, true},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{DisableEndpoints: tt.in}
+		got, err := cfg.DisabledEndpointGroups()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("DisabledEndpointGroups(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !slices.Equal(got, tt.want) {
+			t.Errorf("DisabledEndpointGroups(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{"sidecar mode", Config{Mode: "sidecar"}, []string{"sidecar"}},
+		{"app defaults", Config{Mode: "app"}, []string{"chaos", "queue"}},
+		{"app without chaos", Config{Mode: "app", DisableChaos: true, ReadOnly: true}, []string{"queue", "read-only"}},
+		{"combined", Config{Mode: "combined", DisableQueue: true, TLS: true, AdminTokensFile: "tokens", SLOLatency: 0.99}, []string{"tls", "chaos", "sidecar", "admin-auth", "slo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.Features(); !slices.Equal(got, tt.want) {
+				t.Errorf("Features() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestTimeoutOverrides(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{"", map[string]time.Duration{}, false},
+		{"io=30m, latency=1m,", map[string]time.Duration{"io": 30 * time.Minute, "latency": time.Minute}, false},
+		{"queue=0", map[string]time.Duration{"queue": 0}, false},
+		{"disk=1m", nil, true},
+		{"io", nil, true},
+		{"io=soon", nil, true},
+		{"io=-1m", nil, true},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{RequestTimeout: 5 * time.Minute, RequestTimeouts: tt.in}
+		got, err := cfg.RequestTimeoutOverrides()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("RequestTimeoutOverrides(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !maps.Equal(got, tt.want) {
+			t.Errorf("RequestTimeoutOverrides(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	cfg := Config{RequestTimeout: 5 * time.Minute, RequestTimeouts: "io=30m"}
+	if got := cfg.GroupRequestTimeout("io"); got != 30*time.Minute {
+		t.Errorf("GroupRequestTimeout(io) = %s, want 30m", got)
+	}
+	if got := cfg.GroupRequestTimeout("cpu"); got != 5*time.Minute {
+		t.Errorf("GroupRequestTimeout(cpu) = %s, want 5m", got)
+	}
+}
@@ -0,0 +1,241 @@
+// Package contract checks hotpod's JSON responses against JSON schemas
+// generated from pkg/api and committed under schemas/, so a change to a
+// response's shape shows up as a schema diff in review, and a handler that
+// drifts from its api type fails the suite. The same checks back
+// /admin/selftest.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// draft is the JSON Schema dialect the committed schemas declare. Only the
+// subset of it that Generate produces is validated.
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON schema, limited to the keywords needed to describe
+// encoding/json's output for the api types.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// Types is a schema's allowed JSON types, encoded as a string when there is
+// only one.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(b []byte) error {
+	var one string
+	if json.Unmarshal(b, &one) == nil {
+		*t = Types{one}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+var (
+	timeType = reflect.TypeFor[time.Time]()
+	rawType  = reflect.TypeFor[json.RawMessage]()
+)
+
+// Generate returns the schema of t's JSON encoding. Fields tagged omitempty
+// or omitzero are optional; pointers, slices, and maps may also be null. Extra
+// properties are allowed, since the api contract only ever adds fields.
+func Generate(t reflect.Type) *Schema {
+	s := generate(t)
+	s.Schema = draft
+	s.Title = t.Name()
+	return s
+}
+
+func generate(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case rawType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Pointer:
+		return nullable(generate(t.Elem()))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(&Schema{Type: Types{"string"}})
+		}
+		return nullable(&Schema{Type: Types{"array"}, Items: generate(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: generate(t.Elem())}
+	case reflect.Map:
+		return nullable(&Schema{Type: Types{"object"}, AdditionalProperties: generate(t.Elem())})
+	case reflect.Struct:
+		s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+		addFields(s, t)
+		return s
+	}
+	// Interfaces and anything else may hold any value.
+	return &Schema{}
+}
+
+// addFields adds the properties of struct t to s, flattening embedded
+// structs the way encoding/json does.
+func addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = generate(ft)
+		if o := strings.Split(opts, ","); !slices.Contains(o, "omitempty") && !slices.Contains(o, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func nullable(s *Schema) *Schema {
+	s.Type = append(s.Type, "null")
+	return s
+}
+
+// Validate checks that data is a single JSON value matching s.
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return fmt.Errorf("invalid JSON: trailing data after the first value")
+	}
+	return s.validate("$", v)
+}
+
+func (s *Schema) validate(path string, v any) error {
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return isType(t, v) }) {
+		return fmt.Errorf("%s: got %s, want %s", path, typeOf(v), strings.Join(s.Type, " or "))
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			p := s.Properties[name]
+			if p == nil {
+				p = s.AdditionalProperties
+			}
+			if p == nil {
+				continue
+			}
+			if err := p.validate(path+"."+name, v[name]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range v {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", path, v)
+			}
+		}
+	}
+	return nil
+}
+
+// isType reports whether v, as decoded with UseNumber, is of JSON type t.
+func isType(t string, v any) bool {
+	switch t {
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil || !strings.ContainsAny(n.String(), ".eE")
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	}
+	return typeOf(v) == t
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
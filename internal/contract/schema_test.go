@@ -0,0 +1,172 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the committed schemas from pkg/api")
+
+// TestSchemasUpToDate fails when an api type no longer matches its committed
+// schema. Run with -update to regenerate the schemas, then review the diff:
+// within a version, fields may only be added.
+func TestSchemasUpToDate(t *testing.T) {
+	want := map[string]bool{}
+	for _, typ := range SchemaTypes() {
+		name := typ.Name() + ".json"
+		want[name] = true
+
+		b, err := json.MarshalIndent(Generate(typ), "", "  ")
+		if err != nil {
+			t.Fatalf("marshalling %s: %v", name, err)
+		}
+		b = append(b, '\n')
+		path := filepath.Join("schemas", name)
+		if *update {
+			if err := os.WriteFile(path, b, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: %v; run go test ./internal/contract -update", name, err)
+			continue
+		}
+		if !bytes.Equal(got, b) {
+			t.Errorf("%s is out of date with pkg/api; run go test ./internal/contract -update and review the diff", name)
+		}
+	}
+
+	entries, err := os.ReadDir("schemas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if !want[e.Name()] {
+			if *update {
+				os.Remove(filepath.Join("schemas", e.Name()))
+				continue
+			}
+			t.Errorf("schemas/%s matches no api type", e.Name())
+		}
+	}
+}
+
+type testItem struct {
+	ID   int    `json:"id"`
+	Note string `json:"note,omitempty"`
+}
+
+type testEmbedded struct {
+	Kind string `json:"kind"`
+}
+
+type testResponse struct {
+	testEmbedded
+	Name    string            `json:"name"`
+	Ratio   float64           `json:"ratio"`
+	Enabled bool              `json:"enabled"`
+	When    time.Time         `json:"when"`
+	Until   time.Time         `json:"until,omitzero"`
+	Items   []testItem        `json:"items"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Next    *testItem         `json:"next,omitempty"`
+	Any     any               `json:"any,omitempty"`
+	Skipped string            `json:"-"`
+	hidden  string
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(reflect.TypeFor[testResponse]())
+	if s.Title != "testResponse" || s.Schema != draft {
+		t.Errorf("title = %q, $schema = %q", s.Title, s.Schema)
+	}
+	wantRequired := "kind,name,ratio,enabled,when,items"
+	if got := strings.Join(s.Required, ","); got != wantRequired {
+		t.Errorf("required = %s, want %s", got, wantRequired)
+	}
+	for name, want := range map[string]string{
+		"kind":    "string",
+		"ratio":   "number",
+		"enabled": "boolean",
+		"items":   "array,null",
+		"labels":  "object,null",
+		"next":    "object,null",
+		"until":   "string",
+		"any":     "",
+	} {
+		p := s.Properties[name]
+		if p == nil {
+			t.Errorf("missing property %s", name)
+			continue
+		}
+		if got := strings.Join(p.Type, ","); got != want {
+			t.Errorf("%s type = %s, want %s", name, got, want)
+		}
+	}
+	if len(s.Properties) != 10 {
+		t.Errorf("properties = %d, want 10 without skipped or unexported fields", len(s.Properties))
+	}
+	if s.Properties["when"].Format != "date-time" || s.Properties["items"].Items.Properties["id"].Type[0] != "integer" {
+		t.Errorf("when and items[].id not described: %+v", s.Properties)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := Generate(reflect.TypeFor[testResponse]())
+	valid, err := json.Marshal(testResponse{
+		testEmbedded: testEmbedded{Kind: "k"},
+		Name:         "n",
+		When:         time.Now(),
+		Items:        []testItem{{ID: 1}},
+		Next:         &testItem{ID: 2, Note: "x"},
+		Any:          []int{1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(valid); err != nil {
+		t.Errorf("valid response: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name, body, wantErr string
+	}{
+		{"not JSON", `{`, "invalid JSON"},
+		{"trailing", `{} {}`, "trailing data"},
+		{"missing", `{"kind":"k","name":"n","ratio":1,"enabled":true,"items":null}`, `missing required property "when"`},
+		{"wrong type", `{"kind":"k","name":1,"ratio":1,"enabled":true,"when":"2024-01-01T00:00:00Z","items":[]}`, "$.name: got number, want string"},
+		{"not integer", `{"kind":"k","name":"n","ratio":1,"enabled":true,"when":"2024-01-01T00:00:00Z","items":[{"id":1.5}]}`, "$.items[0].id"},
+		{"bad date", `{"kind":"k","name":"n","ratio":1,"enabled":true,"when":"yesterday","items":[]}`, "not a date-time"},
+		{"bad map value", `{"kind":"k","name":"n","ratio":1,"enabled":true,"when":"2024-01-01T00:00:00Z","items":[],"labels":{"a":1}}`, "$.labels.a"},
+	} {
+		err := s.Validate([]byte(tt.body))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// Unknown properties are allowed, since fields are only ever added.
+	if err := s.Validate([]byte(`{"kind":"k","name":"n","ratio":1,"enabled":true,"when":"2024-01-01T00:00:00Z","items":[],"extra":true}`)); err != nil {
+		t.Errorf("extra property: %v", err)
+	}
+}
+
+func TestSuiteSchemas(t *testing.T) {
+	for _, c := range Suite {
+		if Lookup(c.SchemaName()) == nil {
+			t.Errorf("%s: no committed schema for %s", c.Name, c.SchemaName())
+		}
+	}
+	if len(Quick(false)) == 0 || len(Quick(true)) <= len(Quick(false)) {
+		t.Errorf("quick checks = %d without load, %d with, want some of each", len(Quick(false)), len(Quick(true)))
+	}
+}
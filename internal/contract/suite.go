@@ -0,0 +1,187 @@
+package contract
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// schemaFS holds the committed schemas, one per api type, named after it.
+//
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// Check is a request and the response it must get: the status, and a body
+// matching the schema of Response's type.
+type Check struct {
+	Name   string
+	Method string
+	// Target is the request path and query
+	Target string
+	// Body is sent as JSON, if set
+	Body   string
+	Status int
+	// Response is a value of the api type the body must encode
+	Response any
+	// Quick checks are fast and leave no lasting state, so they can run on
+	// a live server through /admin/selftest
+	Quick bool
+	// Load checks need the load endpoints registered in app mode
+	Load bool
+}
+
+// SchemaName names the schema the check's response is validated against.
+func (c Check) SchemaName() string {
+	return reflect.TypeOf(c.Response).Name()
+}
+
+// Suite exercises every JSON endpoint that can run without side effects
+// outside the process. Admin requests carry no token, so the handler under
+// test must not require one.
+var Suite = []Check{
+	{Name: "healthz", Method: "GET", Target: "/healthz", Status: http.StatusOK, Response: api.HealthResponse{}, Quick: true},
+	{Name: "startupz", Method: "GET", Target: "/startupz", Status: http.StatusOK, Response: api.HealthResponse{}},
+	{Name: "info", Method: "GET", Target: "/info", Status: http.StatusOK, Response: api.InfoResponse{}, Quick: true},
+	{Name: "echo", Method: "GET", Target: "/echo", Status: http.StatusOK, Response: api.EchoResponse{}, Quick: true},
+	{Name: "session", Method: "GET", Target: "/session", Status: http.StatusOK, Response: api.SessionResponse{}},
+	{Name: "mirror source", Method: "GET", Target: "/mirror/source", Status: http.StatusOK, Response: api.MirrorResponse{}},
+	{Name: "burst", Method: "POST", Target: "/burst", Status: http.StatusOK, Response: api.BurstResponse{}},
+	{Name: "burst stats", Method: "GET", Target: "/burst/stats", Status: http.StatusOK, Response: api.BurstStats{}, Quick: true},
+	{Name: "invalid parameter", Method: "GET", Target: "/burst/stats?window=soon", Status: http.StatusBadRequest, Response: api.ErrorResponse{}, Quick: true},
+	{Name: "events", Method: "GET", Target: "/events?limit=10", Status: http.StatusOK, Response: api.EventsResponse{}},
+	{Name: "create event", Method: "POST", Target: "/events", Body: `{"message":"contract"}`, Status: http.StatusCreated, Response: api.Event{}},
+
+	{Name: "cpu", Method: "GET", Target: "/cpu?duration=10ms", Status: http.StatusOK, Response: api.CPUResponse{}, Quick: true, Load: true},
+	{Name: "memory", Method: "GET", Target: "/memory?size=1Mi&duration=10ms", Status: http.StatusOK, Response: api.MemoryResponse{}, Quick: true, Load: true},
+	{Name: "memory pressure", Method: "GET", Target: "/memory/pressure?size=1Mi&duration=10ms", Status: http.StatusOK, Response: api.MemoryPressureResponse{}, Load: true},
+	{Name: "io", Method: "GET", Target: "/io?size=64Ki", Status: http.StatusOK, Response: api.IOResponse{}, Load: true},
+	{Name: "upload", Method: "POST", Target: "/upload", Body: `"payload"`, Status: http.StatusOK, Response: api.UploadResponse{}, Load: true},
+	{Name: "work", Method: "GET", Target: "/work?profile=api", Status: http.StatusOK, Response: api.WorkResponse{}, Load: true},
+	{Name: "latency", Method: "GET", Target: "/latency?duration=1ms", Status: http.StatusOK, Response: api.LatencyResponse{}, Quick: true, Load: true},
+	{Name: "load error", Method: "GET", Target: "/cpu?duration=soon", Status: http.StatusBadRequest, Response: api.ErrorResponse{}, Quick: true, Load: true},
+	{Name: "enqueue", Method: "POST", Target: "/queue/enqueue?count=2", Status: http.StatusOK, Response: api.EnqueueResponse{}, Load: true},
+	{Name: "queue status", Method: "GET", Target: "/queue/status", Status: http.StatusOK, Response: api.QueueStatusResponse{}, Load: true},
+	{Name: "queue items", Method: "GET", Target: "/queue/items", Status: http.StatusOK, Response: api.QueueItemsResponse{}, Load: true},
+
+	{Name: "admin config", Method: "GET", Target: "/admin/config", Status: http.StatusOK, Response: api.AdminConfigResponse{}},
+	{Name: "admin faults", Method: "GET", Target: "/admin/faults", Status: http.StatusOK, Response: api.AdminFaultsResponse{}},
+	{Name: "admin header faults", Method: "GET", Target: "/admin/header-faults", Status: http.StatusOK, Response: api.AdminHeaderFaultsResponse{}},
+	{Name: "admin clock", Method: "GET", Target: "/admin/clock", Status: http.StatusOK, Response: api.AdminClockResponse{}},
+	{Name: "admin exit code", Method: "GET", Target: "/admin/exit-code", Status: http.StatusOK, Response: api.AdminExitCodeResponse{}},
+	{Name: "admin log level", Method: "GET", Target: "/admin/loglevel", Status: http.StatusOK, Response: api.AdminLogLevelResponse{}},
+	{Name: "admin drain status", Method: "GET", Target: "/admin/drain-status", Status: http.StatusOK, Response: api.DrainStatus{}},
+	{Name: "admin audit", Method: "GET", Target: "/admin/audit", Status: http.StatusOK, Response: api.AdminAuditResponse{}},
+	{Name: "admin endpoints", Method: "GET", Target: "/admin/endpoints", Status: http.StatusOK, Response: api.AdminEndpointsResponse{}},
+	{Name: "admin brownout", Method: "GET", Target: "/admin/brownout", Status: http.StatusOK, Response: api.BrownoutStatus{}},
+	{Name: "admin health delay", Method: "GET", Target: "/admin/health-delay", Status: http.StatusOK, Response: api.HealthDelaysResponse{}},
+	{Name: "admin metrics scrape", Method: "GET", Target: "/admin/metrics-scrape", Status: http.StatusOK, Response: api.AdminScrapeCostResponse{}},
+	{Name: "admin dependencies", Method: "GET", Target: "/admin/dependencies", Status: http.StatusOK, Response: api.DependenciesResponse{}},
+	{Name: "admin work profiles", Method: "GET", Target: "/admin/profiles", Status: http.StatusOK, Response: api.WorkProfilesResponse{}, Load: true},
+}
+
+// Path returns the path of the check's target, without its query.
+func (c Check) Path() string {
+	path, _, _ := strings.Cut(c.Target, "?")
+	return path
+}
+
+// SchemaTypes are the api types with committed schemas: every Response in Suite,
+// and responses checked elsewhere.
+func SchemaTypes() []reflect.Type {
+	seen := map[reflect.Type]bool{}
+	var types []reflect.Type
+	add := func(t reflect.Type) {
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	for _, c := range Suite {
+		add(reflect.TypeOf(c.Response))
+	}
+	add(reflect.TypeFor[api.AdminSelftestResponse]())
+	return types
+}
+
+// Quick returns the checks safe to run on a live server, leaving out load
+// checks unless app is set.
+func Quick(app bool) []Check {
+	var checks []Check
+	for _, c := range Suite {
+		if c.Quick && (app || !c.Load) {
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
+
+var loadSchemas = sync.OnceValue(func() map[string]*Schema {
+	schemas := map[string]*Schema{}
+	entries, _ := schemaFS.ReadDir("schemas")
+	for _, e := range entries {
+		var s Schema
+		b, err := schemaFS.ReadFile("schemas/" + e.Name())
+		if err != nil || json.Unmarshal(b, &s) != nil {
+			continue
+		}
+		schemas[strings.TrimSuffix(e.Name(), ".json")] = &s
+	}
+	return schemas
+})
+
+// Lookup returns the committed schema named name, or nil.
+func Lookup(name string) *Schema {
+	return loadSchemas()[name]
+}
+
+// Run sends each check's request to h, in order, and reports the results.
+func Run(ctx context.Context, h http.Handler, checks []Check) []api.SelftestCheck {
+	results := make([]api.SelftestCheck, 0, len(checks))
+	for _, c := range checks {
+		start := time.Now()
+		status, err := c.run(ctx, h)
+		result := api.SelftestCheck{
+			Name:     c.Name,
+			Method:   c.Method,
+			Target:   c.Target,
+			Status:   status,
+			Duration: time.Since(start).String(),
+			Passed:   err == nil,
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (c Check) run(ctx context.Context, h http.Handler) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, c.Method, c.Target, strings.NewReader(c.Body))
+	if err != nil {
+		return 0, err
+	}
+	if c.Body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != c.Status {
+		return rec.Code, fmt.Errorf("status %d, want %d: %s", rec.Code, c.Status, strings.TrimSpace(rec.Body.String()))
+	}
+	schema := Lookup(c.SchemaName())
+	if schema == nil {
+		return rec.Code, fmt.Errorf("no schema for %s", c.SchemaName())
+	}
+	return rec.Code, schema.Validate(rec.Body.Bytes())
+}
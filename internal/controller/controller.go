@@ -0,0 +1,301 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/internal/metrics"
+)
+
+// defaultPort is the hotpod port used when a profile does not specify one.
+const defaultPort = 8080
+
+// Controller periodically reconciles HotpodProfiles in a namespace.
+type Controller struct {
+	kube      *kube.Client
+	namespace string
+	// token is sent as X-Admin-Token to target pods
+	token  string
+	resync time.Duration
+	http   *http.Client
+	// podURL builds the base URL for a pod; overridable in tests
+	podURL func(ip string, port int) string
+
+	mu sync.Mutex
+	// applied maps profile UID to pod UID to the profile generation last
+	// applied to that pod
+	applied map[string]map[string]int64
+	// targets maps profile UID to pod UID to the base URL used, so settings
+	// can be reset when a profile is deleted
+	targets map[string]map[string]string
+	// statuses caches the last status written per profile UID
+	statuses map[string]HotpodProfileStatus
+}
+
+// New creates a controller for profiles in namespace.
+func New(client *kube.Client, namespace, token string, resync time.Duration) *Controller {
+	return &Controller{
+		kube:      client,
+		namespace: namespace,
+		token:     token,
+		resync:    resync,
+		http:      &http.Client{Timeout: 10 * time.Second},
+		podURL: func(ip string, port int) string {
+			return "http://" + net.JoinHostPort(ip, strconv.Itoa(port))
+		},
+		applied:  map[string]map[string]int64{},
+		targets:  map[string]map[string]string{},
+		statuses: map[string]HotpodProfileStatus{},
+	}
+}
+
+// Run reconciles every resync interval until ctx is cancelled.
+func (c *Controller) Run(ctx context.Context) {
+	slog.Info("hotpod profile controller started", "namespace", c.namespace, "resync", c.resync)
+
+	ticker := time.NewTicker(c.resync)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Reconcile(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("hotpod profile reconcile failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Controller) profilesPath() string {
+	return fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s", Group, Version, c.namespace, Resource)
+}
+
+// Reconcile applies all profiles once, and resets pods for profiles that no
+// longer exist.
+func (c *Controller) Reconcile(ctx context.Context) error {
+	var profiles HotpodProfileList
+	if err := c.kube.Do(ctx, http.MethodGet, c.profilesPath(), nil, &profiles); err != nil {
+		return fmt.Errorf("listing hotpod profiles: %w", err)
+	}
+
+	seen := make(map[string]bool, len(profiles.Items))
+	for i := range profiles.Items {
+		p := &profiles.Items[i]
+		seen[p.Metadata.UID] = true
+		if err := c.reconcileProfile(ctx, p); err != nil {
+			slog.Warn("hotpod profile not applied", "profile", p.Metadata.Name, "error", err)
+		}
+	}
+
+	c.mu.Lock()
+	var removed []map[string]string
+	for uid, targets := range c.targets {
+		if !seen[uid] {
+			removed = append(removed, targets)
+			delete(c.targets, uid)
+			delete(c.applied, uid)
+			delete(c.statuses, uid)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, targets := range removed {
+		for _, base := range targets {
+			if err := c.send(ctx, base, http.MethodPost, "/admin/reset", nil); err != nil {
+				slog.Warn("failed to reset pod after profile deletion", "target", base, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Controller) reconcileProfile(ctx context.Context, p *HotpodProfile) error {
+	selector, err := p.Spec.Selector.String()
+	if err != nil {
+		return err
+	}
+
+	var pods PodList
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods?labelSelector=%s", c.namespace, url.QueryEscape(selector))
+	if err := c.kube.Do(ctx, http.MethodGet, path, nil, &pods); err != nil {
+		return fmt.Errorf("listing pods: %w", err)
+	}
+
+	port := p.Spec.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	uid, gen := p.Metadata.UID, p.Metadata.Generation
+	c.mu.Lock()
+	applied := c.applied[uid]
+	if applied == nil {
+		applied = map[string]int64{}
+		c.applied[uid] = applied
+		c.targets[uid] = map[string]string{}
+	}
+	c.mu.Unlock()
+
+	status := HotpodProfileStatus{ObservedGeneration: gen}
+	current := make(map[string]bool, len(pods.Items))
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != "Running" || pod.Status.PodIP == "" {
+			continue
+		}
+		status.MatchedPods++
+		current[pod.Metadata.UID] = true
+
+		c.mu.Lock()
+		done := applied[pod.Metadata.UID] == gen
+		c.mu.Unlock()
+		if done {
+			status.AppliedPods++
+			continue
+		}
+
+		base := c.podURL(pod.Status.PodIP, port)
+		if err := c.apply(ctx, base, &p.Spec); err != nil {
+			slog.Warn("failed to apply hotpod profile", "profile", p.Metadata.Name, "pod", pod.Metadata.Name, "error", err)
+			status.FailedPods = append(status.FailedPods, pod.Metadata.Name)
+			metrics.ControllerApplyTotal.WithLabelValues("failure").Inc()
+			continue
+		}
+
+		slog.Info("applied hotpod profile", "profile", p.Metadata.Name, "generation", gen, "pod", pod.Metadata.Name)
+		metrics.ControllerApplyTotal.WithLabelValues("success").Inc()
+		status.AppliedPods++
+		c.mu.Lock()
+		applied[pod.Metadata.UID] = gen
+		c.targets[uid][pod.Metadata.UID] = base
+		c.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	for podUID := range applied {
+		if !current[podUID] {
+			delete(applied, podUID)
+			delete(c.targets[uid], podUID)
+		}
+	}
+	changed := !reflect.DeepEqual(c.statuses[uid], status)
+	c.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	return c.writeStatus(ctx, p, status)
+}
+
+func (c *Controller) writeStatus(ctx context.Context, p *HotpodProfile, status HotpodProfileStatus) error {
+	cached := status
+	status.LastSyncTime = time.Now().UTC().Format(time.RFC3339)
+
+	path := fmt.Sprintf("%s/%s/status", c.profilesPath(), p.Metadata.Name)
+	patch := map[string]any{"status": status}
+	if err := c.kube.DoWithContentType(ctx, http.MethodPatch, path, "application/merge-patch+json", patch, nil); err != nil {
+		return fmt.Errorf("updating status: %w", err)
+	}
+
+	c.mu.Lock()
+	c.statuses[p.Metadata.UID] = cached
+	c.mu.Unlock()
+	return nil
+}
+
+// apply sends the profile's settings to a single pod.
+func (c *Controller) apply(ctx context.Context, base string, spec *HotpodProfileSpec) error {
+	for _, er := range spec.ErrorRates {
+		params := url.Values{"rate": {strconv.FormatFloat(er.Rate, 'f', -1, 64)}}
+		if er.Endpoint != "" {
+			params.Set("endpoint", er.Endpoint)
+		}
+		if len(er.Codes) > 0 {
+			codes := make([]string, len(er.Codes))
+			for i, code := range er.Codes {
+				codes[i] = strconv.Itoa(code)
+			}
+			params.Set("codes", strings.Join(codes, ","))
+		}
+		if er.Duration != "" {
+			params.Set("duration", er.Duration)
+		}
+		if err := c.send(ctx, base, http.MethodPost, "/admin/error-rate", params); err != nil {
+			return err
+		}
+	}
+
+	if spec.Ready != nil {
+		params := url.Values{"state": {strconv.FormatBool(*spec.Ready)}}
+		if err := c.send(ctx, base, http.MethodPost, "/admin/ready", params); err != nil {
+			return err
+		}
+	}
+
+	if spec.QueuePaused != nil {
+		path := "/admin/queue/resume"
+		if *spec.QueuePaused {
+			path = "/admin/queue/pause"
+		}
+		if err := c.send(ctx, base, http.MethodPost, path, nil); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range spec.Actions {
+		if !strings.HasPrefix(a.Path, "/") {
+			return fmt.Errorf("action path %q must be absolute", a.Path)
+		}
+		method := strings.ToUpper(a.Method)
+		if method == "" {
+			method = http.MethodGet
+		}
+		params := url.Values{}
+		for k, v := range a.Params {
+			params.Set(k, v)
+		}
+		if err := c.send(ctx, base, method, a.Path, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Controller) send(ctx context.Context, base, method, path string, params url.Values) error {
+	target := base + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Admin-Token", c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/kube"
+)
+
+func TestLabelSelectorString(t *testing.T) {
+	s := LabelSelector{
+		MatchLabels: map[string]string{"b": "2", "a": "1"},
+		MatchExpressions: []LabelSelectorRequirement{
+			{Key: "tier", Operator: "In", Values: []string{"web", "api"}},
+			{Key: "canary", Operator: "DoesNotExist"},
+		},
+	}
+	got, err := s.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a=1,b=2,tier in (web,api),!canary"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if _, err := (LabelSelector{}).String(); err == nil {
+		t.Error("empty selector should error")
+	}
+}
+
+// fakeCluster serves a single profile and pod list, records status patches,
+// and acts as the target pod's admin API.
+type fakeCluster struct {
+	mu       sync.Mutex
+	profiles []HotpodProfile
+	podPort  int
+	calls    []string
+	statuses []HotpodProfileStatus
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/apis/") && r.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(HotpodProfileList{Items: f.profiles})
+	case strings.HasSuffix(r.URL.Path, "/status") && r.Method == http.MethodPatch:
+		var patch struct {
+			Status HotpodProfileStatus `json:"status"`
+		}
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &patch)
+		f.statuses = append(f.statuses, patch.Status)
+	case r.URL.Path == "/api/v1/namespaces/test/pods":
+		if r.URL.Query().Get("labelSelector") != "app=hotpod" {
+			http.Error(w, "bad selector", http.StatusBadRequest)
+			return
+		}
+		pods := PodList{Items: []Pod{{}, {}}}
+		pods.Items[0].Metadata = ObjectMeta{Name: "hotpod-a", UID: "pod-a"}
+		pods.Items[0].Status.Phase = "Running"
+		pods.Items[0].Status.PodIP = "127.0.0.1"
+		pods.Items[1].Metadata = ObjectMeta{Name: "hotpod-b", UID: "pod-b"}
+		pods.Items[1].Status.Phase = "Pending"
+		json.NewEncoder(w).Encode(pods)
+	case strings.HasPrefix(r.URL.Path, "/admin/") || r.URL.Path == "/cpu":
+		f.calls = append(f.calls, r.Method+" "+r.URL.RequestURI()+" token="+r.Header.Get("X-Admin-Token"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	ready := false
+	f := &fakeCluster{profiles: []HotpodProfile{{
+		Metadata: ObjectMeta{Name: "errors", UID: "prof-1", Generation: 1},
+		Spec: HotpodProfileSpec{
+			Selector:   LabelSelector{MatchLabels: map[string]string{"app": "hotpod"}},
+			ErrorRates: []ErrorRate{{Rate: 0.1, Codes: []int{503}}},
+			Ready:      &ready,
+			Actions:    []Action{{Path: "/cpu", Params: map[string]string{"duration": "1s"}}},
+		},
+	}}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	c := New(kube.NewForTesting(srv.URL, "test"), "test", "secret", 0)
+	c.podURL = func(ip string, port int) string {
+		if port != defaultPort {
+			t.Errorf("port = %d, want default %d", port, defaultPort)
+		}
+		return srv.URL
+	}
+
+	ctx := context.Background()
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	want := []string{
+		"POST /admin/error-rate?codes=503&rate=0.1 token=secret",
+		"POST /admin/ready?state=false token=secret",
+		"GET /cpu?duration=1s token=secret",
+	}
+	if strings.Join(f.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls =\n%s\nwant\n%s", strings.Join(f.calls, "\n"), strings.Join(want, "\n"))
+	}
+	if len(f.statuses) != 1 || f.statuses[0].MatchedPods != 1 || f.statuses[0].AppliedPods != 1 || f.statuses[0].ObservedGeneration != 1 {
+		t.Errorf("statuses = %+v", f.statuses)
+	}
+
+	// A second pass at the same generation neither re-applies nor rewrites status.
+	f.calls = nil
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.calls) != 0 || len(f.statuses) != 1 {
+		t.Errorf("second reconcile: calls=%v statuses=%d", f.calls, len(f.statuses))
+	}
+
+	// Deleting the profile resets the pod.
+	f.profiles = nil
+	if err := c.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.calls) != 1 || !strings.HasPrefix(f.calls[0], "POST /admin/reset") {
+		t.Errorf("after delete: calls = %v", f.calls)
+	}
+}
+
+func TestReconcileNewGeneration(t *testing.T) {
+	f := &fakeCluster{profiles: []HotpodProfile{{
+		Metadata: ObjectMeta{Name: "p", UID: "prof-1", Generation: 1},
+		Spec: HotpodProfileSpec{
+			Selector:   LabelSelector{MatchLabels: map[string]string{"app": "hotpod"}},
+			Port:       9090,
+			ErrorRates: []ErrorRate{{Endpoint: "/cpu", Rate: 0.5}},
+		},
+	}}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	c := New(kube.NewForTesting(srv.URL, "test"), "test", "", 0)
+	var ports []string
+	c.podURL = func(ip string, port int) string {
+		ports = append(ports, strconv.Itoa(port))
+		return srv.URL
+	}
+
+	c.Reconcile(context.Background())
+	f.profiles[0].Metadata.Generation = 2
+	f.profiles[0].Spec.ErrorRates[0].Rate = 0
+	c.Reconcile(context.Background())
+
+	if len(f.calls) != 2 || !strings.Contains(f.calls[1], "rate=0 ") {
+		t.Errorf("calls = %v", f.calls)
+	}
+	if strings.Join(ports, ",") != "9090,9090" {
+		t.Errorf("ports = %v", ports)
+	}
+}
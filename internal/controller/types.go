@@ -0,0 +1,142 @@
+// Package controller implements an optional in-cluster controller that applies
+// HotpodProfile custom resources to matching hotpod pods through their admin
+// APIs, so fleet-wide experiments can be declared rather than scripted.
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CRD coordinates for HotpodProfile.
+const (
+	Group    = "hotpod.ripta.github.io"
+	Version  = "v1alpha1"
+	Resource = "hotpodprofiles"
+)
+
+// ObjectMeta is the subset of Kubernetes object metadata the controller uses.
+type ObjectMeta struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace,omitempty"`
+	UID        string            `json:"uid,omitempty"`
+	Generation int64             `json:"generation,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// HotpodProfile declares settings to apply to selected hotpod pods.
+type HotpodProfile struct {
+	Metadata ObjectMeta          `json:"metadata"`
+	Spec     HotpodProfileSpec   `json:"spec"`
+	Status   HotpodProfileStatus `json:"status,omitempty"`
+}
+
+// HotpodProfileList is a list of profiles.
+type HotpodProfileList struct {
+	Items []HotpodProfile `json:"items"`
+}
+
+// HotpodProfileSpec is the desired state for selected pods.
+type HotpodProfileSpec struct {
+	// Selector chooses pods in the profile's namespace
+	Selector LabelSelector `json:"selector"`
+	// Port is the hotpod HTTP port on each pod (default: 8080)
+	Port int `json:"port,omitempty"`
+	// ErrorRates configures fault injection via /admin/error-rate
+	ErrorRates []ErrorRate `json:"errorRates,omitempty"`
+	// Ready, if set, overrides readiness via /admin/ready
+	Ready *bool `json:"ready,omitempty"`
+	// QueuePaused, if set, pauses or resumes the work queue
+	QueuePaused *bool `json:"queuePaused,omitempty"`
+	// Actions are arbitrary requests sent once per pod per profile generation,
+	// e.g. to start load with /cpu
+	Actions []Action `json:"actions,omitempty"`
+}
+
+// ErrorRate is an error injection setting for one endpoint, or globally when
+// Endpoint is empty.
+type ErrorRate struct {
+	Endpoint string  `json:"endpoint,omitempty"`
+	Rate     float64 `json:"rate"`
+	Codes    []int   `json:"codes,omitempty"`
+	Duration string  `json:"duration,omitempty"`
+}
+
+// Action is a single HTTP request to send to each selected pod.
+type Action struct {
+	Method string            `json:"method,omitempty"`
+	Path   string            `json:"path"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// HotpodProfileStatus reports the outcome of the last reconcile.
+type HotpodProfileStatus struct {
+	ObservedGeneration int64    `json:"observedGeneration,omitempty"`
+	MatchedPods        int      `json:"matchedPods"`
+	AppliedPods        int      `json:"appliedPods"`
+	FailedPods         []string `json:"failedPods,omitempty"`
+	LastSyncTime       string   `json:"lastSyncTime,omitempty"`
+}
+
+// LabelSelector is a Kubernetes label selector.
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// LabelSelectorRequirement is a set-based selector requirement.
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// String renders the selector in the labelSelector query parameter syntax.
+func (s LabelSelector) String() (string, error) {
+	var parts []string
+
+	keys := make([]string, 0, len(s.MatchLabels))
+	for k := range s.MatchLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+s.MatchLabels[k])
+	}
+
+	for _, req := range s.MatchExpressions {
+		switch req.Operator {
+		case "In":
+			parts = append(parts, fmt.Sprintf("%s in (%s)", req.Key, strings.Join(req.Values, ",")))
+		case "NotIn":
+			parts = append(parts, fmt.Sprintf("%s notin (%s)", req.Key, strings.Join(req.Values, ",")))
+		case "Exists":
+			parts = append(parts, req.Key)
+		case "DoesNotExist":
+			parts = append(parts, "!"+req.Key)
+		default:
+			return "", fmt.Errorf("unsupported selector operator %q", req.Operator)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", errors.New("selector must not be empty")
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// Pod is the subset of a core/v1 Pod the controller uses.
+type Pod struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Status   struct {
+		Phase string `json:"phase"`
+		PodIP string `json:"podIP"`
+	} `json:"status"`
+}
+
+// PodList is a list of pods.
+type PodList struct {
+	Items []Pod `json:"items"`
+}
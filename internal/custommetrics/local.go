@@ -0,0 +1,61 @@
+// Package custommetrics serves hotpod's queue depth and a settable synthetic
+// utilization through the Kubernetes custom metrics API
+// (custom.metrics.k8s.io/v1beta2), so HorizontalPodAutoscalers can scale on
+// custom metrics without deploying prometheus-adapter.
+package custommetrics
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/queue"
+)
+
+// Metric names served through the custom metrics API.
+const (
+	// MetricQueueDepth is the number of items in the pod's work queue
+	MetricQueueDepth = "queue_depth"
+	// MetricSyntheticUtilization is a value set through the admin API, for
+	// driving an HPA to an exact utilization
+	MetricSyntheticUtilization = "synthetic_utilization"
+)
+
+// metricNames lists the served metrics in discovery order.
+var metricNames = []string{MetricQueueDepth, MetricSyntheticUtilization}
+
+// Local reports this pod's metric values.
+type Local struct {
+	queue *queue.Queue
+	// utilization holds the float64 bits of the synthetic utilization
+	utilization atomic.Uint64
+}
+
+// NewLocal creates a source for this pod's metrics. q may be nil if the queue
+// is unavailable, in which case queue depth is reported as zero.
+func NewLocal(q *queue.Queue) *Local {
+	return &Local{queue: q}
+}
+
+// SetUtilization sets the synthetic utilization.
+func (l *Local) SetUtilization(v float64) {
+	l.utilization.Store(math.Float64bits(v))
+	metrics.SyntheticUtilization.Set(v)
+}
+
+// Utilization returns the synthetic utilization.
+func (l *Local) Utilization() float64 {
+	return math.Float64frombits(l.utilization.Load())
+}
+
+// Values returns the current value of every served metric.
+func (l *Local) Values() map[string]float64 {
+	depth := 0
+	if l.queue != nil {
+		depth = l.queue.Depth()
+	}
+	return map[string]float64{
+		MetricQueueDepth:           float64(depth),
+		MetricSyntheticUtilization: l.Utilization(),
+	}
+}
@@ -0,0 +1,386 @@
+package custommetrics
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/fleet"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+const (
+	// Group is the API group served.
+	Group = "custom.metrics.k8s.io"
+	// Version is the API version served.
+	Version = "v1beta2"
+	// GroupVersion is the API group and version served.
+	GroupVersion = Group + "/" + Version
+)
+
+// LocalPath is the path on each hotpod's main port that reports its own
+// metric values, for aggregation across peers.
+const LocalPath = "/custom-metrics"
+
+// peerTimeout bounds each request for a peer's metric values.
+const peerTimeout = 2 * time.Second
+
+// shutdownTimeout bounds how long in-flight requests may take to finish once
+// the server is stopped.
+const shutdownTimeout = 5 * time.Second
+
+// Server implements the read-only subset of the custom metrics API that the
+// HPA controller uses. The Kubernetes API aggregator proxies requests to it
+// once an APIService for v1beta2.custom.metrics.k8s.io points at its Service.
+//
+// Pod metrics are gathered from every peer found by the fleet discoverer, or
+// only this pod without one; the HPA's label selector is not applied, so the
+// discoverer's selector should match the scale target's pods. Metrics on any
+// other object (e.g. a Deployment) aggregate across the same pods: queue depth
+// is summed and synthetic utilization is averaged.
+//
+// Requests are not authenticated, so access should be limited with a
+// NetworkPolicy.
+type Server struct {
+	local      *Local
+	podName    string
+	namespace  string
+	discoverer fleet.Discoverer
+	peerScheme string
+	client     *http.Client
+	now        func() time.Time
+}
+
+// NewServer creates a custom metrics API server for the pod podName in
+// namespace, which may be empty to serve any namespace. discoverer may be nil;
+// peers are fetched over peerScheme.
+func NewServer(local *Local, podName, namespace string, discoverer fleet.Discoverer, peerScheme string) *Server {
+	return &Server{
+		local:      local,
+		podName:    podName,
+		namespace:  namespace,
+		discoverer: discoverer,
+		peerScheme: peerScheme,
+		client:     fleet.NewClient(peerTimeout, peerScheme),
+		now:        time.Now,
+	}
+}
+
+// Handler returns the API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /apis/"+Group, s.group)
+	mux.HandleFunc("GET /apis/"+GroupVersion, s.resources)
+	mux.HandleFunc("GET /apis/"+GroupVersion+"/namespaces/{namespace}/pods/{name}/{metric}", s.podMetric)
+	mux.HandleFunc("GET /apis/"+GroupVersion+"/namespaces/{namespace}/{resource}/{name}/{metric}", s.objectMetric)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// Run serves the API over TLS on addr until ctx is cancelled. Without a
+// certificate and key file, a self-signed certificate is generated, which the
+// APIService must accept with insecureSkipTLSVerify.
+func (s *Server) Run(ctx context.Context, addr, certFile, keyFile string) error {
+	var cert tls.Certificate
+	var err error
+	if certFile != "" {
+		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+	} else {
+		now := time.Now()
+		cert, err = fault.SelfSignedCertificate([]string{"hotpod-custom-metrics"}, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	}
+	if err != nil {
+		return fmt.Errorf("loading certificate: %w", err)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ServeTLS(ln, "", "")
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) group(w http.ResponseWriter, r *http.Request) {
+	gv := map[string]string{"groupVersion": GroupVersion, "version": Version}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"kind":             "APIGroup",
+		"apiVersion":       "v1",
+		"name":             Group,
+		"versions":         []map[string]string{gv},
+		"preferredVersion": gv,
+	})
+}
+
+func (s *Server) resources(w http.ResponseWriter, r *http.Request) {
+	var resources []map[string]any
+	for _, resource := range []string{"pods", "deployments", "services"} {
+		for _, name := range metricNames {
+			resources = append(resources, map[string]any{
+				"name":         resource + "/" + name,
+				"singularName": "",
+				"namespaced":   true,
+				"kind":         "MetricValueList",
+				"verbs":        []string{"get"},
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"kind":         "APIResourceList",
+		"apiVersion":   "v1",
+		"groupVersion": GroupVersion,
+		"resources":    resources,
+	})
+}
+
+// podMetric serves GET .../namespaces/{namespace}/pods/{name}/{metric}, where
+// name may be * for every pod.
+func (s *Server) podMetric(w http.ResponseWriter, r *http.Request) {
+	metric, ok := s.checkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	name := r.PathValue("name")
+	var items []metricValue
+	for _, p := range s.podValues(r.Context()) {
+		if name != "*" && name != p.name {
+			continue
+		}
+		items = append(items, s.newValue(r, "Pod", "/v1", p.name, metric, p.values[metric]))
+	}
+	if name != "*" && len(items) == 0 {
+		writeStatus(w, http.StatusNotFound, "NotFound", fmt.Sprintf("metric %s for pod %s not found", metric, name))
+		return
+	}
+	writeList(w, items)
+}
+
+// objectMetric serves GET .../namespaces/{namespace}/{resource}/{name}/{metric}
+// by aggregating over every pod.
+func (s *Server) objectMetric(w http.ResponseWriter, r *http.Request) {
+	metric, ok := s.checkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	pods := s.podValues(r.Context())
+	var total float64
+	for _, p := range pods {
+		total += p.values[metric]
+	}
+	if metric == MetricSyntheticUtilization && len(pods) > 0 {
+		total /= float64(len(pods))
+	}
+
+	resource := r.PathValue("resource")
+	kind, apiVersion := objectKind(resource)
+	writeList(w, []metricValue{s.newValue(r, kind, apiVersion, r.PathValue("name"), metric, total)})
+}
+
+// checkRequest validates the namespace and metric name.
+func (s *Server) checkRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if ns := r.PathValue("namespace"); s.namespace != "" && ns != s.namespace {
+		writeStatus(w, http.StatusNotFound, "NotFound", fmt.Sprintf("metrics are only served for namespace %q, not %q", s.namespace, ns))
+		return "", false
+	}
+	metric := r.PathValue("metric")
+	if !slices.Contains(metricNames, metric) {
+		writeStatus(w, http.StatusNotFound, "NotFound", fmt.Sprintf("metric %q not found; available metrics: %s", metric, strings.Join(metricNames, ", ")))
+		return "", false
+	}
+	return metric, true
+}
+
+type podValues struct {
+	name   string
+	values map[string]float64
+}
+
+// podValues gathers metric values from every peer, or only this pod without a
+// discoverer. Peers that cannot be reached are skipped.
+func (s *Server) podValues(ctx context.Context) []podValues {
+	self := podValues{name: s.podName, values: s.local.Values()}
+	if s.discoverer == nil {
+		return []podValues{self}
+	}
+
+	peers, err := s.discoverer.Peers(ctx)
+	if err != nil {
+		slog.Warn("custom metrics peer discovery failed", "error", err)
+		return []podValues{self}
+	}
+
+	results := make([]*podValues, len(peers))
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		if p.Name == s.podName {
+			results[i] = &self
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values, err := s.fetch(ctx, p.Addr)
+			if err != nil {
+				slog.Debug("failed to fetch peer custom metrics", "peer", p.Name, "error", err)
+				return
+			}
+			results[i] = &podValues{name: p.Name, values: values}
+		}()
+	}
+	wg.Wait()
+
+	var out []podValues
+	for _, v := range results {
+		if v != nil {
+			out = append(out, *v)
+		}
+	}
+	return out
+}
+
+func (s *Server) fetch(ctx context.Context, addr string) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.peerScheme+"://"+addr+LocalPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer returned %d", resp.StatusCode)
+	}
+
+	var body api.CustomMetricsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Metrics, nil
+}
+
+// objectKind maps a resource name, which may be qualified with its group
+// (e.g. deployments.apps), to the kind and API version reported in
+// describedObject.
+func objectKind(resource string) (kind, apiVersion string) {
+	resource, _, _ = strings.Cut(resource, ".")
+	switch resource {
+	case "deployments":
+		return "Deployment", "apps/v1"
+	case "statefulsets":
+		return "StatefulSet", "apps/v1"
+	case "services":
+		return "Service", "/v1"
+	case "namespaces":
+		return "Namespace", "/v1"
+	}
+	return resource, ""
+}
+
+type objectReference struct {
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+type metricIdentifier struct {
+	Name string `json:"name"`
+}
+
+type metricValue struct {
+	DescribedObject objectReference  `json:"describedObject"`
+	Metric          metricIdentifier `json:"metric"`
+	Timestamp       time.Time        `json:"timestamp"`
+	Value           string           `json:"value"`
+}
+
+func (s *Server) newValue(r *http.Request, kind, apiVersion, name, metric string, v float64) metricValue {
+	return metricValue{
+		DescribedObject: objectReference{Kind: kind, Namespace: r.PathValue("namespace"), Name: name, APIVersion: apiVersion},
+		Metric:          metricIdentifier{Name: metric},
+		Timestamp:       s.now().UTC(),
+		Value:           formatQuantity(v),
+	}
+}
+
+// formatQuantity renders v as a Kubernetes quantity, in milli-units unless it
+// is a whole number.
+func formatQuantity(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%dm", int64(math.Round(v*1000)))
+}
+
+func writeList(w http.ResponseWriter, items []metricValue) {
+	if items == nil {
+		items = []metricValue{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"kind":       "MetricValueList",
+		"apiVersion": GroupVersion,
+		"metadata":   map[string]any{},
+		"items":      items,
+	})
+}
+
+// writeStatus writes a Kubernetes Status error.
+func writeStatus(w http.ResponseWriter, code int, reason, message string) {
+	writeJSON(w, code, map[string]any{
+		"kind":       "Status",
+		"apiVersion": "v1",
+		"metadata":   map[string]any{},
+		"status":     "Failure",
+		"message":    message,
+		"reason":     reason,
+		"code":       code,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to encode custom metrics response", "error", err)
+	}
+}
@@ -0,0 +1,211 @@
+package custommetrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/fleet"
+	"github.com/ripta/hotpod/internal/queue"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+type staticDiscoverer []fleet.Peer
+
+func (d staticDiscoverer) Peers(ctx context.Context) ([]fleet.Peer, error) {
+	return d, nil
+}
+
+type metricList struct {
+	Kind  string        `json:"kind"`
+	Items []metricValue `json:"items"`
+}
+
+// newTestServer returns a server for hotpod-0 holding three queued items and
+// 0.5 utilization, with a peer hotpod-1 holding five items and 0.25
+// utilization, and an unreachable peer hotpod-2.
+func newTestServer(t *testing.T) http.Handler {
+	t.Helper()
+
+	q := queue.New(10)
+	for range 3 {
+		if err := q.Enqueue(&queue.Item{Priority: queue.PriorityNormal, EnqueuedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	local := NewLocal(q)
+	local.SetUtilization(0.5)
+
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(api.CustomMetricsResponse{Pod: "hotpod-1", Metrics: map[string]float64{
+			MetricQueueDepth:           5,
+			MetricSyntheticUtilization: 0.25,
+		}})
+	}))
+	t.Cleanup(peer.Close)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	d := staticDiscoverer{
+		{Name: "hotpod-0", Addr: "unused"},
+		{Name: "hotpod-1", Addr: strings.TrimPrefix(peer.URL, "http://")},
+		{Name: "hotpod-2", Addr: strings.TrimPrefix(down.URL, "http://")},
+	}
+	return NewServer(local, "hotpod-0", "default", d, "http").Handler()
+}
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	return rec
+}
+
+func TestDiscovery(t *testing.T) {
+	h := newTestServer(t)
+
+	rec := get(t, h, "/apis/custom.metrics.k8s.io/v1beta2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var list struct {
+		GroupVersion string `json:"groupVersion"`
+		Resources    []struct {
+			Name string `json:"name"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if list.GroupVersion != GroupVersion || len(list.Resources) == 0 || list.Resources[0].Name != "pods/queue_depth" {
+		t.Errorf("resource list = %+v", list)
+	}
+
+	if rec := get(t, h, "/apis/custom.metrics.k8s.io"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"APIGroup"`) {
+		t.Errorf("group = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPodMetrics(t *testing.T) {
+	h := newTestServer(t)
+
+	tests := []struct {
+		name string
+		path string
+		want map[string]string
+	}{
+		{"all pods", "/apis/custom.metrics.k8s.io/v1beta2/namespaces/default/pods/*/queue_depth", map[string]string{"hotpod-0": "3", "hotpod-1": "5"}},
+		{"one peer", "/apis/custom.metrics.k8s.io/v1beta2/namespaces/default/pods/hotpod-1/synthetic_utilization", map[string]string{"hotpod-1": "250m"}},
+		{"self", "/apis/custom.metrics.k8s.io/v1beta2/namespaces/default/pods/hotpod-0/synthetic_utilization", map[string]string{"hotpod-0": "500m"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(t, h, tt.path)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+			}
+			var list metricList
+			if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+			got := map[string]string{}
+			for _, item := range list.Items {
+				if item.DescribedObject.Kind != "Pod" || item.DescribedObject.Namespace != "default" {
+					t.Errorf("described object = %+v", item.DescribedObject)
+				}
+				got[item.DescribedObject.Name] = item.Value
+			}
+			if list.Kind != "MetricValueList" || len(got) != len(tt.want) {
+				t.Fatalf("list = %+v, want values %v", list, tt.want)
+			}
+			for name, v := range tt.want {
+				if got[name] != v {
+					t.Errorf("%s = %q, want %q", name, got[name], v)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectMetrics(t *testing.T) {
+	h := newTestServer(t)
+
+	tests := []struct {
+		metric string
+		want   string
+	}{
+		{MetricQueueDepth, "8"},
+		{MetricSyntheticUtilization, "375m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			rec := get(t, h, "/apis/custom.metrics.k8s.io/v1beta2/namespaces/default/deployments.apps/hotpod/"+tt.metric)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+			}
+			var list metricList
+			if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+			if len(list.Items) != 1 || list.Items[0].Value != tt.want || list.Items[0].DescribedObject.Name != "hotpod" {
+				t.Errorf("list = %+v, want one value %s", list, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsNotFound(t *testing.T) {
+	h := newTestServer(t)
+
+	for _, path := range []string{
+		"/apis/custom.metrics.k8s.io/v1beta2/namespaces/other/pods/*/queue_depth",
+		"/apis/custom.metrics.k8s.io/v1beta2/namespaces/default/pods/*/requests_per_second",
+		"/apis/custom.metrics.k8s.io/v1beta2/namespaces/default/pods/hotpod-2/queue_depth",
+	} {
+		rec := get(t, h, path)
+		if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), `"NotFound"`) {
+			t.Errorf("%s: %d %s, want 404 Status", path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{42, "42"},
+		{0.8, "800m"},
+		{1.2345, "1235m"},
+	}
+	for _, tt := range tests {
+		if got := formatQuantity(tt.v); got != tt.want {
+			t.Errorf("formatQuantity(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestObjectKind(t *testing.T) {
+	tests := []struct {
+		resource       string
+		wantKind       string
+		wantAPIVersion string
+	}{
+		{"deployments.apps", "Deployment", "apps/v1"},
+		{"deployments", "Deployment", "apps/v1"},
+		{"services", "Service", "/v1"},
+		{"widgets.example.com", "widgets", ""},
+	}
+	for _, tt := range tests {
+		kind, apiVersion := objectKind(tt.resource)
+		if kind != tt.wantKind || apiVersion != tt.wantAPIVersion {
+			t.Errorf("objectKind(%q) = %q, %q, want %q, %q", tt.resource, kind, apiVersion, tt.wantKind, tt.wantAPIVersion)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package events records structured lifecycle, fault, scenario, and admin
+// events in a bounded ring buffer so test runs can be reconstructed later.
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Event is a single recorded occurrence.
+type Event = api.Event
+
+// Event types.
+const (
+	TypeLifecycle = "lifecycle"
+	TypeFault     = "fault"
+	TypeScenario  = "scenario"
+	TypeAdmin     = "admin"
+)
+
+// DefaultCapacity is the number of events retained by the default log.
+const DefaultCapacity = 1000
+
+// Sink receives a copy of every recorded event. Send must not block.
+type Sink interface {
+	Send(Event)
+}
+
+// Log is a thread-safe ring buffer of events.
+type Log struct {
+	mu    sync.RWMutex
+	buf   []Event
+	next  int
+	full  bool
+	seq   uint64
+	sinks []Sink
+	now   func() time.Time
+}
+
+// New creates an event log retaining at most capacity events. A log with no
+// capacity retains nothing but still passes events to its sinks.
+func New(capacity int) *Log {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &Log{
+		buf: make([]Event, capacity),
+		now: wallclock.Now,
+	}
+}
+
+// AddSink registers a sink that receives every subsequently recorded event.
+func (l *Log) AddSink(s Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, s)
+}
+
+// Record appends an event and returns it.
+func (l *Log) Record(level slog.Level, typ, msg string, attrs map[string]any) Event {
+	l.mu.Lock()
+	l.seq++
+	ev := Event{
+		ID:      l.seq,
+		Time:    l.now(),
+		Level:   LevelString(level),
+		Type:    typ,
+		Message: msg,
+		Attrs:   attrs,
+	}
+	if len(l.buf) > 0 {
+		l.buf[l.next] = ev
+		l.next = (l.next + 1) % len(l.buf)
+		if l.next == 0 {
+			l.full = true
+		}
+	}
+	sinks := l.sinks
+	l.mu.Unlock()
+
+	for _, s := range sinks {
+		s.Send(ev)
+	}
+	return ev
+}
+
+// Filter selects events from the log. Zero values match everything.
+type Filter struct {
+	// Since excludes events recorded before this time
+	Since time.Time
+	// AfterID excludes events with an ID less than or equal to this value
+	AfterID uint64
+	// MinLevel excludes events below this severity
+	MinLevel slog.Level
+	// Type restricts results to a single event type
+	Type string
+	// Limit caps the number of (most recent) events returned
+	Limit int
+}
+
+// List returns matching events in chronological order.
+func (l *Log) List(f Filter) []Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var ordered []Event
+	if l.full {
+		ordered = append(ordered, l.buf[l.next:]...)
+	}
+	ordered = append(ordered, l.buf[:l.next]...)
+
+	result := make([]Event, 0, len(ordered))
+	for _, ev := range ordered {
+		if ev.ID <= f.AfterID {
+			continue
+		}
+		if !f.Since.IsZero() && ev.Time.Before(f.Since) {
+			continue
+		}
+		if lvl, err := ParseLevel(ev.Level); err == nil && lvl < f.MinLevel {
+			continue
+		}
+		if f.Type != "" && ev.Type != f.Type {
+			continue
+		}
+		result = append(result, ev)
+	}
+
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[len(result)-f.Limit:]
+	}
+	return result
+}
+
+// Len returns the number of retained events.
+func (l *Log) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.full {
+		return len(l.buf)
+	}
+	return l.next
+}
+
+// LevelString returns the lowercase name of a slog level.
+func LevelString(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// ParseLevel parses debug, info, warn, or error (case-insensitive).
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid level %q, must be one of: debug, info, warn, error", s)
+	}
+}
+
+// Default is the process-wide event log.
+var Default = New(DefaultCapacity)
+
+// Record appends an event to the default log.
+func Record(level slog.Level, typ, msg string, attrs map[string]any) Event {
+	return Default.Record(level, typ, msg, attrs)
+}
@@ -0,0 +1,150 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/kube"
+)
+
+func TestLogRecordAndList(t *testing.T) {
+	l := New(10)
+	l.Record(slog.LevelInfo, TypeLifecycle, "ready", nil)
+	l.Record(slog.LevelWarn, TypeFault, "crash", map[string]any{"exit_code": 1})
+
+	got := l.List(Filter{})
+	if len(got) != 2 {
+		t.Fatalf("List() returned %d events, want 2", len(got))
+	}
+	if got[0].ID != 1 || got[1].ID != 2 {
+		t.Errorf("IDs = %d,%d, want 1,2", got[0].ID, got[1].ID)
+	}
+	if got[1].Level != "warn" {
+		t.Errorf("Level = %q, want warn", got[1].Level)
+	}
+}
+
+func TestLogRingBufferWraps(t *testing.T) {
+	l := New(3)
+	for range 5 {
+		l.Record(slog.LevelInfo, TypeAdmin, "x", nil)
+	}
+
+	if l.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", l.Len())
+	}
+	got := l.List(Filter{})
+	if got[0].ID != 3 || got[2].ID != 5 {
+		t.Errorf("retained IDs %d..%d, want 3..5", got[0].ID, got[2].ID)
+	}
+}
+
+type sinkFunc func(Event)
+
+func (f sinkFunc) Send(ev Event) { f(ev) }
+
+func TestLogZeroCapacity(t *testing.T) {
+	l := New(0)
+	var sent []Event
+	l.AddSink(sinkFunc(func(ev Event) { sent = append(sent, ev) }))
+	ev := l.Record(slog.LevelInfo, TypeAdmin, "x", nil)
+
+	if l.Len() != 0 || len(l.List(Filter{})) != 0 {
+		t.Errorf("Len() = %d, want nothing retained", l.Len())
+	}
+	if len(sent) != 1 || sent[0].ID != ev.ID {
+		t.Errorf("sink received %+v, want %+v", sent, ev)
+	}
+}
+
+type filterTest struct {
+	name   string
+	filter Filter
+	want   int
+}
+
+func TestLogFilter(t *testing.T) {
+	l := New(10)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	i := 0
+	l.now = func() time.Time {
+		i++
+		return base.Add(time.Duration(i) * time.Minute)
+	}
+
+	l.Record(slog.LevelDebug, TypeScenario, "a", nil)
+	l.Record(slog.LevelInfo, TypeAdmin, "b", nil)
+	l.Record(slog.LevelError, TypeFault, "c", nil)
+
+	tests := []filterTest{
+		{"all", Filter{MinLevel: slog.LevelDebug}, 3},
+		{"min level warn", Filter{MinLevel: slog.LevelWarn}, 1},
+		{"type admin", Filter{MinLevel: slog.LevelDebug, Type: TypeAdmin}, 1},
+		{"since", Filter{MinLevel: slog.LevelDebug, Since: base.Add(2 * time.Minute)}, 2},
+		{"after id", Filter{MinLevel: slog.LevelDebug, AfterID: 2}, 1},
+		{"limit", Filter{MinLevel: slog.LevelDebug, Limit: 2}, 2},
+	}
+
+	for _, tt := range tests {
+		if got := len(l.List(tt.filter)); got != tt.want {
+			t.Errorf("%s: got %d events, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, s := range []string{"debug", "INFO", "warn", "warning", "error"} {
+		if _, err := ParseLevel(s); err != nil {
+			t.Errorf("ParseLevel(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) should error")
+	}
+}
+
+func TestKubeSinkPost(t *testing.T) {
+	got := make(chan kubeEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/ns/events" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var ev kubeEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		got <- ev
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	sink := NewKubeSink(kube.NewForTesting(srv.URL, "ns"), "hotpod-0", "ns", slog.LevelInfo)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sink.Run(ctx)
+
+	l := New(10)
+	l.AddSink(sink)
+	l.Record(slog.LevelDebug, TypeFault, "ignored", nil)
+	l.Record(slog.LevelWarn, TypeFault, "crash scheduled", nil)
+
+	select {
+	case ev := <-got:
+		if ev.Message != "crash scheduled" {
+			t.Errorf("Message = %q, want crash scheduled", ev.Message)
+		}
+		if ev.Type != "Warning" || ev.Reason != "HotpodFault" {
+			t.Errorf("Type/Reason = %s/%s, want Warning/HotpodFault", ev.Type, ev.Reason)
+		}
+		if ev.InvolvedObject["name"] != "hotpod-0" {
+			t.Errorf("involvedObject.name = %q", ev.InvolvedObject["name"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for kubernetes event")
+	}
+}
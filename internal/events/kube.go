@@ -0,0 +1,123 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ripta/hotpod/internal/kube"
+)
+
+// KubeSink mirrors events as Kubernetes Events attached to the hotpod pod.
+// Events are delivered asynchronously; if the buffer is full they are dropped
+// rather than blocking the caller.
+type KubeSink struct {
+	client    *kube.Client
+	podName   string
+	namespace string
+	minLevel  slog.Level
+	ch        chan Event
+}
+
+// NewKubeSink creates a sink that posts events at or above minLevel for the
+// given pod.
+func NewKubeSink(client *kube.Client, podName, namespace string, minLevel slog.Level) *KubeSink {
+	return &KubeSink{
+		client:    client,
+		podName:   podName,
+		namespace: namespace,
+		minLevel:  minLevel,
+		ch:        make(chan Event, 100),
+	}
+}
+
+// Send queues an event for delivery.
+func (s *KubeSink) Send(ev Event) {
+	if lvl, err := ParseLevel(ev.Level); err == nil && lvl < s.minLevel {
+		return
+	}
+	select {
+	case s.ch <- ev:
+	default:
+		slog.Debug("kubernetes event sink full, dropping event", "event_id", ev.ID)
+	}
+}
+
+// Run delivers queued events until the context is cancelled.
+func (s *KubeSink) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-s.ch:
+			reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			if err := s.post(reqCtx, ev); err != nil {
+				slog.Warn("failed to create kubernetes event", "event_id", ev.ID, "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// kubeEvent is the subset of core/v1 Event that hotpod populates.
+type kubeEvent struct {
+	APIVersion         string            `json:"apiVersion"`
+	Kind               string            `json:"kind"`
+	Metadata           map[string]string `json:"metadata"`
+	InvolvedObject     map[string]string `json:"involvedObject"`
+	Reason             string            `json:"reason"`
+	Message            string            `json:"message"`
+	Type               string            `json:"type"`
+	Source             map[string]string `json:"source"`
+	FirstTimestamp     string            `json:"firstTimestamp"`
+	LastTimestamp      string            `json:"lastTimestamp"`
+	Count              int               `json:"count"`
+	ReportingComponent string            `json:"reportingComponent"`
+	ReportingInstance  string            `json:"reportingInstance"`
+}
+
+func (s *KubeSink) post(ctx context.Context, ev Event) error {
+	evType := "Normal"
+	if ev.Level == "warn" || ev.Level == "error" {
+		evType = "Warning"
+	}
+
+	ts := ev.Time.UTC().Format(time.RFC3339)
+	body := kubeEvent{
+		APIVersion: "v1",
+		Kind:       "Event",
+		Metadata: map[string]string{
+			"generateName": s.podName + ".",
+			"namespace":    s.namespace,
+		},
+		InvolvedObject: map[string]string{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"name":       s.podName,
+			"namespace":  s.namespace,
+		},
+		Reason:             reason(ev.Type),
+		Message:            ev.Message,
+		Type:               evType,
+		Source:             map[string]string{"component": "hotpod"},
+		FirstTimestamp:     ts,
+		LastTimestamp:      ts,
+		Count:              1,
+		ReportingComponent: "hotpod",
+		ReportingInstance:  s.podName,
+	}
+
+	path := fmt.Sprintf("/api/v1/namespaces/%s/events", s.namespace)
+	return s.client.Do(ctx, "POST", path, body, nil)
+}
+
+// reason converts an event type into a CamelCase Kubernetes event reason,
+// e.g. "fault" becomes "HotpodFault".
+func reason(typ string) string {
+	if typ == "" {
+		return "Hotpod"
+	}
+	return "Hotpod" + strings.ToUpper(typ[:1]) + typ[1:]
+}
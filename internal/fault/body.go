@@ -0,0 +1,134 @@
+package fault
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"text/template"
+)
+
+// BodyFormat selects how the body of an injected error is rendered.
+type BodyFormat string
+
+const (
+	// BodyJSON is hotpod's own JSON error body (the default).
+	BodyJSON BodyFormat = "json"
+	// BodyProblem is an RFC 9457 application/problem+json body.
+	BodyProblem BodyFormat = "problem"
+	// BodyHTML mimics an error page served by a proxy or load balancer.
+	BodyHTML BodyFormat = "html"
+	// BodyText is a plain-text status line.
+	BodyText BodyFormat = "text"
+	// BodyInvalidJSON declares application/json but sends malformed JSON.
+	BodyInvalidJSON BodyFormat = "invalid-json"
+	// BodyTruncated declares a Content-Length and then sends only half of
+	// the body, so the client sees an unexpected EOF.
+	BodyTruncated BodyFormat = "truncated"
+	// BodyEmpty sends no body and no Content-Type.
+	BodyEmpty BodyFormat = "empty"
+)
+
+// ParseBodyFormat parses a body format name. An empty string is BodyJSON.
+func ParseBodyFormat(s string) (BodyFormat, error) {
+	switch f := BodyFormat(s); f {
+	case "":
+		return BodyJSON, nil
+	case BodyJSON, BodyProblem, BodyHTML, BodyText, BodyInvalidJSON, BodyTruncated, BodyEmpty:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown body format %q (want json, problem, html, text, invalid-json, truncated, or empty)", s)
+}
+
+// ErrorBody describes the response body of an injected error. A nil
+// *ErrorBody renders the default JSON body.
+type ErrorBody struct {
+	// Format selects the default body and content type
+	Format BodyFormat
+	// ContentType overrides the format's content type if set
+	ContentType string
+	// Template overrides the format's body if set; it is a text/template
+	// executed with BodyData
+	Template string
+
+	tmpl *template.Template
+}
+
+// BodyData is the data available to an ErrorBody template.
+type BodyData struct {
+	Status     int
+	StatusText string
+	Path       string
+}
+
+// NewErrorBody validates the format and compiles the template.
+func NewErrorBody(format, contentType, tmpl string) (*ErrorBody, error) {
+	f, err := ParseBodyFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &ErrorBody{Format: f, ContentType: contentType, Template: tmpl}
+	if tmpl != "" {
+		b.tmpl, err = template.New("body").Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// Render returns the content type and body to send for status, along with
+// the Content-Length to declare. The declared length exceeds len(body) only
+// for BodyTruncated. An empty content type means none should be sent.
+func (b *ErrorBody) Render(status int, path string) (contentType string, body []byte, declared int) {
+	format := BodyJSON
+	if b != nil {
+		format = b.Format
+	}
+
+	contentType, body = defaultBody(format, status, path)
+	if b == nil {
+		return contentType, body, len(body)
+	}
+
+	if b.tmpl != nil {
+		var buf bytes.Buffer
+		data := BodyData{Status: status, StatusText: http.StatusText(status), Path: path}
+		if err := b.tmpl.Execute(&buf, data); err != nil {
+			slog.Warn("failed to render injected error body template", "error", err)
+		} else {
+			body = buf.Bytes()
+		}
+	}
+	if b.ContentType != "" {
+		contentType = b.ContentType
+	}
+
+	declared = len(body)
+	if format == BodyTruncated {
+		body = body[:len(body)/2]
+	}
+	return contentType, body, declared
+}
+
+func defaultBody(format BodyFormat, status int, path string) (string, []byte) {
+	text := http.StatusText(status)
+	switch format {
+	case BodyProblem:
+		return "application/problem+json", fmt.Appendf(nil, `{"type":"about:blank","title":%s,"status":%d,"detail":"injected fault","instance":%s}`,
+			strconv.Quote(text), status, strconv.Quote(path))
+	case BodyHTML:
+		title := html.EscapeString(fmt.Sprintf("%d %s", status, text))
+		return "text/html; charset=utf-8", fmt.Appendf(nil, "<html>\r\n<head><title>%s</title></head>\r\n<body>\r\n<center><h1>%s</h1></center>\r\n</body>\r\n</html>\r\n", title, title)
+	case BodyText:
+		return "text/plain; charset=utf-8", fmt.Appendf(nil, "%d %s: injected fault\n", status, text)
+	case BodyInvalidJSON:
+		return "application/json", fmt.Appendf(nil, `{"error":"injected fault","code":"FAULT_INJECTED","status":%d,`, status)
+	case BodyEmpty:
+		return "", nil
+	}
+	return "application/json", fmt.Appendf(nil, `{"error":"injected fault","code":"FAULT_INJECTED","status":%d}`, status)
+}
@@ -0,0 +1,73 @@
+package fault
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestErrorBodyRender(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          string
+		contentType     string
+		tmpl            string
+		wantContentType string
+		wantValidJSON   bool
+		wantContains    string
+		wantTruncated   bool
+	}{
+		{name: "default", wantContentType: "application/json", wantValidJSON: true, wantContains: "FAULT_INJECTED"},
+		{name: "problem", format: "problem", wantContentType: "application/problem+json", wantValidJSON: true, wantContains: `"instance":"/cpu"`},
+		{name: "html", format: "html", wantContentType: "text/html; charset=utf-8", wantContains: "<h1>503 Service Unavailable</h1>"},
+		{name: "text", format: "text", wantContentType: "text/plain; charset=utf-8", wantContains: "503 Service Unavailable"},
+		{name: "invalid json", format: "invalid-json", wantContentType: "application/json"},
+		{name: "truncated", format: "truncated", wantContentType: "application/json", wantTruncated: true},
+		{name: "empty", format: "empty"},
+		{
+			name:            "template",
+			format:          "json",
+			contentType:     "application/vnd.example+json",
+			tmpl:            `{"code":{{.Status}},"message":"{{.StatusText}}"}`,
+			wantContentType: "application/vnd.example+json",
+			wantValidJSON:   true,
+			wantContains:    `"message":"Service Unavailable"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b *ErrorBody
+			if tt.name != "default" {
+				var err error
+				b, err = NewErrorBody(tt.format, tt.contentType, tt.tmpl)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ct, body, declared := b.Render(503, "/cpu")
+			if ct != tt.wantContentType {
+				t.Errorf("content type = %q, want %q", ct, tt.wantContentType)
+			}
+			if got := json.Valid(body); got != tt.wantValidJSON {
+				t.Errorf("json.Valid(%q) = %v, want %v", body, got, tt.wantValidJSON)
+			}
+			if !strings.Contains(string(body), tt.wantContains) {
+				t.Errorf("body %q does not contain %q", body, tt.wantContains)
+			}
+			if got := declared > len(body); got != tt.wantTruncated {
+				t.Errorf("declared %d for %d-byte body, want truncated = %v", declared, len(body), tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestNewErrorBodyInvalid(t *testing.T) {
+	if _, err := NewErrorBody("xml", "", ""); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if _, err := NewErrorBody("json", "", "{{.Status"); err == nil {
+		t.Error("expected error for malformed template")
+	}
+}
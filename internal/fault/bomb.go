@@ -0,0 +1,66 @@
+package fault
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+)
+
+// MaxBombSize bounds the decompressed size of a response bomb. At gzip's
+// best ratio of about 1000:1 this still sends around 16MiB on the wire.
+const MaxBombSize = 16 << 30
+
+// Response bomb encodings.
+const (
+	// BombGzip sends the body gzip-compressed, so it expands to its full size
+	// only when decompressed.
+	BombGzip = "gzip"
+	// BombIdentity sends the body at its full size.
+	BombIdentity = "identity"
+)
+
+// bombChunk is the run of zeros written at a time.
+var bombChunk = make([]byte, 64<<10)
+
+// WriteBomb writes size zero bytes to w, gzip-compressed if encoding is
+// BombGzip, stopping early if ctx is cancelled. It returns the number of
+// decompressed bytes written.
+func WriteBomb(ctx context.Context, w io.Writer, size int64, encoding string) (int64, error) {
+	var gz *gzip.Writer
+	switch encoding {
+	case BombGzip:
+		gz, _ = gzip.NewWriterLevel(w, gzip.BestCompression)
+		w = gz
+	case BombIdentity:
+	default:
+		return 0, fmt.Errorf("encoding must be %s or %s", BombGzip, BombIdentity)
+	}
+
+	slog.Warn("response bomb sent", "size", size, "encoding", encoding)
+	events.Record(slog.LevelWarn, events.TypeFault, "response bomb sent", map[string]any{
+		"size":     size,
+		"encoding": encoding,
+	})
+	metrics.FaultBombsTotal.WithLabelValues(encoding).Inc()
+
+	var written int64
+	for written < size {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, err := w.Write(bombChunk[:min(int64(len(bombChunk)), size-written)])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	if gz != nil {
+		return written, gz.Close()
+	}
+	return written, nil
+}
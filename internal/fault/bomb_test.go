@@ -0,0 +1,44 @@
+package fault
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestWriteBomb(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := WriteBomb(context.Background(), &buf, 1<<20+1, BombGzip)
+	if err != nil || n != 1<<20+1 {
+		t.Fatalf("WriteBomb() = %d, %v, want %d", n, err, 1<<20+1)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil || len(b) != 1<<20+1 || bytes.ContainsFunc(b, func(r rune) bool { return r != 0 }) {
+		t.Errorf("decompressed %d bytes (%v), want %d zero bytes", len(b), err, 1<<20+1)
+	}
+
+	buf.Reset()
+	if n, err := WriteBomb(context.Background(), &buf, 1000, BombIdentity); err != nil || n != 1000 || buf.Len() != 1000 {
+		t.Errorf("identity WriteBomb() = %d, %v with %d bytes, want 1000", n, err, buf.Len())
+	}
+
+	if _, err := WriteBomb(context.Background(), io.Discard, 1, "br"); err == nil {
+		t.Error("WriteBomb() with an unknown encoding succeeded")
+	}
+}
+
+func TestWriteBombCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, err := WriteBomb(ctx, io.Discard, 1<<30, BombIdentity)
+	if !errors.Is(err, context.Canceled) || n != 0 {
+		t.Errorf("WriteBomb() = %d, %v, want to stop at once", n, err)
+	}
+}
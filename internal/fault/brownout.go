@@ -0,0 +1,177 @@
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Bounds on a brownout's parameters.
+const (
+	// MaxBrownoutLatency bounds the latency a brownout adds at its peak.
+	MaxBrownoutLatency = time.Minute
+	// MaxBrownoutDuration bounds a brownout's ramp and hold, each long
+	// enough for slow-burn alert windows.
+	MaxBrownoutDuration = 24 * time.Hour
+)
+
+// BrownoutConfig describes a brownout: latency and error rate that rise
+// linearly from zero to their peak over a ramp, then hold there, so SLO
+// burn-rate alerts can be tested against slow degradations rather than
+// binary failures.
+type BrownoutConfig struct {
+	// MaxLatency is the latency added to each request at the peak
+	MaxLatency time.Duration
+	// MaxErrorRate is the fraction of requests failed at the peak
+	MaxErrorRate float64
+	// Codes are the status codes failed requests select from (empty = 500)
+	Codes []int
+	// Ramp is how long the brownout takes to reach its peak
+	Ramp time.Duration
+	// Hold is how long the peak lasts before the brownout ends (0 = until
+	// cleared)
+	Hold time.Duration
+}
+
+// Validate checks that the brownout's parameters are valid.
+func (c BrownoutConfig) Validate() error {
+	if c.MaxLatency < 0 || c.MaxLatency > MaxBrownoutLatency {
+		return fmt.Errorf("max_latency must be between 0 and %s", MaxBrownoutLatency)
+	}
+	if c.MaxErrorRate < 0 || c.MaxErrorRate > 1 {
+		return errors.New("max_error_rate must be between 0 and 1")
+	}
+	if c.MaxLatency == 0 && c.MaxErrorRate == 0 {
+		return errors.New("max_latency or max_error_rate is required")
+	}
+	for _, code := range c.Codes {
+		if code < 100 || code > 599 {
+			return errors.New("codes must be valid HTTP status codes (100-599)")
+		}
+	}
+	if c.Ramp <= 0 || c.Ramp > MaxBrownoutDuration {
+		return fmt.Errorf("ramp must be positive and at most %s", MaxBrownoutDuration)
+	}
+	if c.Hold < 0 || c.Hold > MaxBrownoutDuration {
+		return fmt.Errorf("hold must be between 0 and %s", MaxBrownoutDuration)
+	}
+	return nil
+}
+
+// Brownout holds the brownout in effect, if any. It takes effect through
+// middleware that consults Degrade. It is safe for concurrent use.
+type Brownout struct {
+	mu      sync.Mutex
+	cfg     BrownoutConfig
+	started time.Time
+}
+
+// NewBrownout creates a brownout holder with no brownout in effect.
+func NewBrownout() *Brownout {
+	return &Brownout{}
+}
+
+// Start begins a brownout at now, replacing any brownout in effect.
+func (b *Brownout) Start(cfg BrownoutConfig, now time.Time) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.cfg = cfg
+	b.started = now
+	b.mu.Unlock()
+	metrics.FaultBrownoutLevel.Set(0)
+
+	slog.Warn("brownout started", "max_latency", cfg.MaxLatency, "max_error_rate", cfg.MaxErrorRate, "ramp", cfg.Ramp, "hold", cfg.Hold)
+	events.Record(slog.LevelWarn, events.TypeFault, "brownout started", map[string]any{
+		"max_latency":    cfg.MaxLatency.String(),
+		"max_error_rate": cfg.MaxErrorRate,
+		"ramp":           cfg.Ramp.String(),
+		"hold":           cfg.Hold.String(),
+	})
+	return nil
+}
+
+// Stop ends the brownout in effect, if any.
+func (b *Brownout) Stop() {
+	b.mu.Lock()
+	b.started = time.Time{}
+	b.mu.Unlock()
+	metrics.FaultBrownoutLevel.Set(0)
+
+	slog.Info("brownout stopped")
+	events.Record(slog.LevelInfo, events.TypeFault, "brownout stopped", nil)
+}
+
+// levelLocked returns how far the brownout has ramped at now, and false if
+// no brownout is in effect.
+func (b *Brownout) levelLocked(now time.Time) (float64, bool) {
+	if b.started.IsZero() || now.Before(b.started) {
+		return 0, false
+	}
+	elapsed := now.Sub(b.started)
+	if b.cfg.Hold > 0 && elapsed >= b.cfg.Ramp+b.cfg.Hold {
+		return 0, false
+	}
+	return min(1, float64(elapsed)/float64(b.cfg.Ramp)), true
+}
+
+// Degrade returns the latency to add to a request arriving at now, and the
+// status code to fail it with, or zero to let it through. It is safe to
+// call on a nil receiver, which never degrades.
+func (b *Brownout) Degrade(now time.Time) (time.Duration, int) {
+	if b == nil {
+		return 0, 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	level, ok := b.levelLocked(now)
+	if !ok {
+		return 0, 0
+	}
+	metrics.FaultBrownoutLevel.Set(level)
+
+	delay := time.Duration(level * float64(b.cfg.MaxLatency))
+	if rand.Float64() >= level*b.cfg.MaxErrorRate {
+		return delay, 0
+	}
+	return delay, (&ErrorConfig{Codes: b.cfg.Codes}).SelectCode()
+}
+
+// Status returns the brownout in effect at now.
+func (b *Brownout) Status(now time.Time) api.BrownoutStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	level, ok := b.levelLocked(now)
+	if !ok {
+		return api.BrownoutStatus{}
+	}
+	started := b.started
+	status := api.BrownoutStatus{
+		Active:       true,
+		Level:        level,
+		ErrorRate:    level * b.cfg.MaxErrorRate,
+		MaxErrorRate: b.cfg.MaxErrorRate,
+		Codes:        b.cfg.Codes,
+		Ramp:         b.cfg.Ramp.String(),
+		Started:      &started,
+	}
+	if b.cfg.MaxLatency > 0 {
+		status.Latency = time.Duration(level * float64(b.cfg.MaxLatency)).String()
+		status.MaxLatency = b.cfg.MaxLatency.String()
+	}
+	if b.cfg.Hold > 0 {
+		until := started.Add(b.cfg.Ramp + b.cfg.Hold)
+		status.Hold = b.cfg.Hold.String()
+		status.Until = &until
+	}
+	return status
+}
@@ -0,0 +1,75 @@
+package fault
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBrownoutConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		cfg     BrownoutConfig
+		wantErr bool
+	}{
+		{"latency", BrownoutConfig{MaxLatency: time.Second, Ramp: time.Hour}, false},
+		{"errors", BrownoutConfig{MaxErrorRate: 0.2, Codes: []int{503}, Ramp: time.Hour, Hold: time.Hour}, false},
+		{"nothing", BrownoutConfig{Ramp: time.Hour}, true},
+		{"long latency", BrownoutConfig{MaxLatency: 2 * MaxBrownoutLatency, Ramp: time.Hour}, true},
+		{"rate above one", BrownoutConfig{MaxErrorRate: 1.5, Ramp: time.Hour}, true},
+		{"bad code", BrownoutConfig{MaxErrorRate: 0.5, Codes: []int{42}, Ramp: time.Hour}, true},
+		{"no ramp", BrownoutConfig{MaxLatency: time.Second}, true},
+		{"negative hold", BrownoutConfig{MaxLatency: time.Second, Ramp: time.Hour, Hold: -time.Second}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBrownout(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	b := NewBrownout()
+	if delay, status := b.Degrade(now); delay != 0 || status != 0 {
+		t.Errorf("idle brownout degraded a request: %s, %d", delay, status)
+	}
+
+	cfg := BrownoutConfig{MaxLatency: time.Second, MaxErrorRate: 1, Codes: []int{503}, Ramp: 10 * time.Minute, Hold: 5 * time.Minute}
+	if err := b.Start(cfg, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		at        time.Duration
+		wantDelay time.Duration
+		wantLevel float64
+		active    bool
+	}{
+		{0, 0, 0, true},
+		{5 * time.Minute, 500 * time.Millisecond, 0.5, true},
+		{10 * time.Minute, time.Second, 1, true},
+		{14 * time.Minute, time.Second, 1, true},
+		{15 * time.Minute, 0, 0, false},
+	} {
+		delay, _ := b.Degrade(now.Add(tt.at))
+		st := b.Status(now.Add(tt.at))
+		if delay != tt.wantDelay || st.Level != tt.wantLevel || st.Active != tt.active {
+			t.Errorf("at %s: delay = %s, level = %g, active = %t, want %s, %g, %t", tt.at, delay, st.Level, st.Active, tt.wantDelay, tt.wantLevel, tt.active)
+		}
+	}
+	if _, status := b.Degrade(now.Add(10 * time.Minute)); status != 503 {
+		t.Errorf("status at peak = %d, want 503", status)
+	}
+
+	b.Stop()
+	if st := b.Status(now.Add(time.Minute)); st.Active {
+		t.Errorf("Status() = %+v, want inactive", st)
+	}
+}
+
+func TestBrownoutNil(t *testing.T) {
+	var b *Brownout
+	if delay, status := b.Degrade(time.Now()); delay != 0 || status != 0 {
+		t.Errorf("nil brownout degraded a request: %s, %d", delay, status)
+	}
+}
@@ -4,12 +4,18 @@ import (
 	"context"
 	"log/slog"
 	"os"
-	"sync"
 	"time"
+
+	"github.com/ripta/hotpod/internal/events"
 )
 
 // Crash terminates the process after an optional delay.
 func Crash(delay time.Duration, exitCode int) {
+	crashPending.Store(true)
+	events.Record(slog.LevelWarn, events.TypeFault, "crash scheduled", map[string]any{
+		"delay":     delay.String(),
+		"exit_code": exitCode,
+	})
 	if delay > 0 {
 		slog.Warn("crash scheduled", "delay", delay, "exit_code", exitCode)
 		time.Sleep(delay)
@@ -18,11 +24,31 @@ func Crash(delay time.Duration, exitCode int) {
 	os.Exit(exitCode)
 }
 
+// Panic panics with message after an optional delay. Called on its own
+// goroutine, nothing recovers the panic and the runtime terminates the
+// process with a stack trace, as an uncaught goroutine panic would.
+func Panic(delay time.Duration, message string) {
+	crashPending.Store(true)
+	events.Record(slog.LevelWarn, events.TypeFault, "goroutine panic scheduled", map[string]any{
+		"delay":   delay.String(),
+		"message": message,
+	})
+	if delay > 0 {
+		slog.Warn("goroutine panic scheduled", "delay", delay, "message", message)
+		time.Sleep(delay)
+	}
+	slog.Error("panicking in background goroutine", "message", message)
+	panic(message)
+}
+
 // Hang blocks the current goroutine for the specified duration.
 // If duration is 0 or negative, blocks indefinitely.
 // Returns true if the hang was interrupted by context cancellation.
 func Hang(ctx context.Context, duration time.Duration) bool {
 	slog.Warn("hang initiated", "duration", duration)
+	events.Record(slog.LevelWarn, events.TypeFault, "hang initiated", map[string]any{
+		"duration": duration.String(),
+	})
 
 	if duration <= 0 {
 		// Block indefinitely until context is cancelled
@@ -37,51 +63,3 @@ func Hang(ctx context.Context, duration time.Duration) bool {
 		return true
 	}
 }
-
-// oomMu protects oomSink from concurrent access.
-var oomMu sync.Mutex
-
-// oomSink prevents the garbage collector from collecting OOM allocations.
-// This is intentionally a package-level variable to ensure allocations persist.
-var oomSink [][]byte
-
-// OOM allocates memory at the specified rate until the process is killed.
-// Rate is in bytes per second. Returns only if context is cancelled.
-// Note: Only one OOM simulation should run at a time per process.
-func OOM(ctx context.Context, rate int64) {
-	oomMu.Lock()
-	defer oomMu.Unlock()
-
-	slog.Warn("OOM simulation started", "rate_bytes_per_sec", rate)
-
-	// Use a slice of slices to prevent garbage collection
-	oomSink = nil // Reset from any previous run
-
-	// Calculate allocation size per tick (10 ticks per second)
-	tickInterval := 100 * time.Millisecond
-	allocSize := max(rate/10, 1024) // Minimum 1KB per tick
-
-	ticker := time.NewTicker(tickInterval)
-	defer ticker.Stop()
-
-	totalAllocated := int64(0)
-	for {
-		select {
-		case <-ctx.Done():
-			slog.Info("OOM simulation cancelled", "total_allocated", totalAllocated)
-			return
-		case <-ticker.C:
-			// Allocate memory and touch it to ensure it's actually allocated
-			buf := make([]byte, allocSize)
-			for i := range buf {
-				buf[i] = byte(i)
-			}
-			oomSink = append(oomSink, buf)
-			totalAllocated += allocSize
-
-			if totalAllocated%(100<<20) == 0 { // Log every 100MB
-				slog.Info("OOM progress", "allocated_mb", totalAllocated>>20)
-			}
-		}
-	}
-}
@@ -0,0 +1,144 @@
+package fault
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Latency distribution kinds. Uniform jitter rarely produces the long tails
+// real services show, so these shape latency for percentile-based alerting
+// tests.
+const (
+	// DistNormal spreads latency symmetrically around the base duration.
+	DistNormal = "normal"
+	// DistLognormal skews latency right, with the base duration as median.
+	DistLognormal = "lognormal"
+	// DistPareto gives a heavy tail, with the base duration as minimum.
+	DistPareto = "pareto"
+	// DistBimodal mixes the base duration with a slow mode, as from cache
+	// misses or retries.
+	DistBimodal = "bimodal"
+)
+
+// Distribution defaults, used when a parameter is left zero.
+const (
+	defaultSigma        = 0.5
+	defaultAlpha        = 1.5
+	defaultSlowFraction = 0.1
+)
+
+// Distribution shapes latency around a base duration. The zero value
+// returns the base duration unchanged.
+type Distribution struct {
+	// Kind is normal, lognormal, pareto, or bimodal (empty = constant)
+	Kind string
+	// StdDev is the normal distribution's standard deviation
+	StdDev time.Duration
+	// Sigma is the lognormal distribution's shape (default: 0.5)
+	Sigma float64
+	// Alpha is the pareto distribution's shape; lower is heavier-tailed
+	// (default: 1.5)
+	Alpha float64
+	// Slow is the bimodal distribution's slow mode
+	Slow time.Duration
+	// SlowFraction is the fraction of bimodal samples in the slow mode
+	// (default: 0.1)
+	SlowFraction float64
+}
+
+// Validate checks that the distribution's kind and parameters are valid.
+func (d Distribution) Validate() error {
+	switch d.Kind {
+	case "":
+	case DistNormal:
+		if d.StdDev <= 0 {
+			return errors.New("stddev must be positive for the normal distribution")
+		}
+	case DistLognormal:
+		if d.Sigma < 0 {
+			return errors.New("sigma must be non-negative")
+		}
+	case DistPareto:
+		if d.Alpha < 0 {
+			return errors.New("alpha must be non-negative")
+		}
+	case DistBimodal:
+		if d.Slow <= 0 {
+			return errors.New("slow must be positive for the bimodal distribution")
+		}
+		if d.SlowFraction < 0 || d.SlowFraction > 1 {
+			return errors.New("slow_fraction must be between 0 and 1")
+		}
+	default:
+		return fmt.Errorf("distribution must be %s, %s, %s, or %s", DistNormal, DistLognormal, DistPareto, DistBimodal)
+	}
+	return nil
+}
+
+// Sample returns a latency drawn from the distribution around base, never
+// negative.
+func (d Distribution) Sample(base time.Duration) time.Duration {
+	var v float64
+	switch d.Kind {
+	case DistNormal:
+		v = float64(base) + rand.NormFloat64()*float64(d.StdDev)
+	case DistLognormal:
+		v = float64(base) * math.Exp(cmp.Or(d.Sigma, defaultSigma)*rand.NormFloat64())
+	case DistPareto:
+		// Inverse transform sampling, with 1-U in (0, 1] to avoid dividing by zero.
+		v = float64(base) / math.Pow(1-rand.Float64(), 1/cmp.Or(d.Alpha, defaultAlpha))
+	case DistBimodal:
+		v = float64(base)
+		if rand.Float64() < cmp.Or(d.SlowFraction, defaultSlowFraction) {
+			v = float64(d.Slow)
+		}
+	default:
+		return base
+	}
+	if v <= 0 {
+		return 0
+	}
+	return time.Duration(min(v, math.MaxInt64))
+}
+
+// API returns the distribution as JSON, or nil for the zero value.
+func (d Distribution) API() *api.LatencyDistribution {
+	if d.Kind == "" {
+		return nil
+	}
+	a := &api.LatencyDistribution{Kind: d.Kind, Sigma: d.Sigma, Alpha: d.Alpha, SlowFraction: d.SlowFraction}
+	if d.StdDev > 0 {
+		a.StdDev = d.StdDev.String()
+	}
+	if d.Slow > 0 {
+		a.Slow = d.Slow.String()
+	}
+	return a
+}
+
+// DistributionFromAPI converts and validates a JSON distribution. A nil
+// distribution is the zero value.
+func DistributionFromAPI(a *api.LatencyDistribution) (Distribution, error) {
+	if a == nil {
+		return Distribution{}, nil
+	}
+	d := Distribution{Kind: a.Kind, Sigma: a.Sigma, Alpha: a.Alpha, SlowFraction: a.SlowFraction}
+	var err error
+	if a.StdDev != "" {
+		if d.StdDev, err = time.ParseDuration(a.StdDev); err != nil {
+			return d, fmt.Errorf("stddev must be a duration, got %q", a.StdDev)
+		}
+	}
+	if a.Slow != "" {
+		if d.Slow, err = time.ParseDuration(a.Slow); err != nil {
+			return d, fmt.Errorf("slow must be a duration, got %q", a.Slow)
+		}
+	}
+	return d, d.Validate()
+}
@@ -0,0 +1,98 @@
+package fault
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestDistributionValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		d       Distribution
+		wantErr bool
+	}{
+		{"constant", Distribution{}, false},
+		{"normal", Distribution{Kind: DistNormal, StdDev: time.Millisecond}, false},
+		{"normal without stddev", Distribution{Kind: DistNormal}, true},
+		{"lognormal", Distribution{Kind: DistLognormal}, false},
+		{"negative sigma", Distribution{Kind: DistLognormal, Sigma: -1}, true},
+		{"pareto", Distribution{Kind: DistPareto, Alpha: 2}, false},
+		{"negative alpha", Distribution{Kind: DistPareto, Alpha: -1}, true},
+		{"bimodal", Distribution{Kind: DistBimodal, Slow: time.Second, SlowFraction: 0.2}, false},
+		{"bimodal without slow", Distribution{Kind: DistBimodal}, true},
+		{"bimodal fraction above one", Distribution{Kind: DistBimodal, Slow: time.Second, SlowFraction: 2}, true},
+		{"unknown", Distribution{Kind: "uniform"}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.d.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// samples draws n sorted samples from d around base.
+func samples(d Distribution, base time.Duration, n int) []time.Duration {
+	s := make([]time.Duration, n)
+	for i := range s {
+		s[i] = d.Sample(base)
+	}
+	slices.Sort(s)
+	return s
+}
+
+func TestDistributionSample(t *testing.T) {
+	const base = 100 * time.Millisecond
+	const n = 10000
+
+	if got := (Distribution{}).Sample(base); got != base {
+		t.Errorf("constant sample = %s, want %s", got, base)
+	}
+
+	s := samples(Distribution{Kind: DistNormal, StdDev: 200 * time.Millisecond}, base, n)
+	if s[0] < 0 {
+		t.Errorf("normal sample %s is negative", s[0])
+	}
+
+	s = samples(Distribution{Kind: DistLognormal, Sigma: 1}, base, n)
+	if median := s[n/2]; median < 80*time.Millisecond || median > 120*time.Millisecond {
+		t.Errorf("lognormal median = %s, want about %s", median, base)
+	}
+	if p99 := s[n*99/100]; p99 < 5*base {
+		t.Errorf("lognormal p99 = %s, want a long tail", p99)
+	}
+
+	s = samples(Distribution{Kind: DistPareto, Alpha: 1}, base, n)
+	if s[0] < base {
+		t.Errorf("pareto minimum = %s, want at least %s", s[0], base)
+	}
+	if p99 := s[n*99/100]; p99 < 50*base {
+		t.Errorf("pareto p99 = %s, want a heavy tail", p99)
+	}
+
+	s = samples(Distribution{Kind: DistBimodal, Slow: time.Second, SlowFraction: 0.2}, base, n)
+	slow := n - slices.Index(s, time.Second)
+	if slow < n/10 || slow > n*3/10 {
+		t.Errorf("bimodal slow samples = %d of %d, want about 20%%", slow, n)
+	}
+}
+
+func TestDistributionAPI(t *testing.T) {
+	if (Distribution{}).API() != nil {
+		t.Error("constant distribution API() is not nil")
+	}
+	d := Distribution{Kind: DistBimodal, Slow: time.Second, SlowFraction: 0.3}
+	got, err := DistributionFromAPI(d.API())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != d {
+		t.Errorf("round trip = %+v, want %+v", got, d)
+	}
+	if _, err := DistributionFromAPI(&api.LatencyDistribution{Kind: DistNormal, StdDev: "wide"}); err == nil {
+		t.Error("DistributionFromAPI accepted an invalid stddev")
+	}
+}
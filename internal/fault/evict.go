@@ -0,0 +1,47 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/kube"
+)
+
+// evictPending is set once the pod's deletion is accepted, until the
+// process is terminated.
+var evictPending atomic.Bool
+
+// deleteOptions is the subset of meta/v1 DeleteOptions that Evict sets.
+type deleteOptions struct {
+	APIVersion         string `json:"apiVersion"`
+	Kind               string `json:"kind"`
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+}
+
+// Evict deletes the pod name in namespace through the Kubernetes API, so
+// the kubelet terminates it gracefully and its controller replaces it, as
+// opposed to Crash, which restarts the container in place. gracePeriod
+// overrides the pod's terminationGracePeriodSeconds when non-negative.
+func Evict(ctx context.Context, client *kube.Client, namespace, name string, gracePeriod int64) error {
+	opts := deleteOptions{APIVersion: "v1", Kind: "DeleteOptions"}
+	if gracePeriod >= 0 {
+		opts.GracePeriodSeconds = &gracePeriod
+	}
+
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", namespace, name)
+	if err := client.Do(ctx, "DELETE", path, opts, nil); err != nil {
+		return fmt.Errorf("deleting pod %s/%s: %w", namespace, name, err)
+	}
+
+	evictPending.Store(true)
+	slog.Warn("pod deletion requested", "pod", name, "namespace", namespace, "grace_period", gracePeriod)
+	events.Record(slog.LevelWarn, events.TypeFault, "pod deletion requested", map[string]any{
+		"pod":          name,
+		"namespace":    namespace,
+		"grace_period": gracePeriod,
+	})
+	return nil
+}
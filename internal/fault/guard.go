@@ -0,0 +1,245 @@
+package fault
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// chaosActions maps each chaos action the guard admits, named after its
+// endpoint, to whether it is destructive: its effects outlive the request,
+// or reach beyond this pod.
+var chaosActions = map[string]bool{
+	"crash":      true,
+	"oom":        true,
+	"zombie":     true,
+	"threads":    true,
+	"deadlock":   true,
+	"ports":      true,
+	"leader":     true,
+	"evict":      true,
+	"hang":       false,
+	"error":      false,
+	"contention": false,
+	"panic":      false,
+	"schedule":   false,
+	"storm":      false,
+	"brownout":   false,
+	"bomb":       false,
+}
+
+// Keywords accepted by ParseChaosAllow in place of action names.
+const (
+	// ChaosAllowAll permits every chaos action.
+	ChaosAllowAll = "all"
+	// ChaosAllowNone permits no chaos action, as a kill switch.
+	ChaosAllowNone = "none"
+	// ChaosAllowSafe permits every non-destructive chaos action.
+	ChaosAllowSafe = "safe"
+)
+
+// Reasons a guard rejects a chaos action.
+const (
+	// GuardNotAllowed is an action outside the allowlist.
+	GuardNotAllowed = "not_allowed"
+	// GuardCooldown is an action repeated within its cooldown.
+	GuardCooldown = "cooldown"
+	// GuardLimit is a destructive action while the maximum number of other
+	// destructive faults are in effect.
+	GuardLimit = "limit"
+)
+
+// crashPending is set once a crash is scheduled, until the process exits.
+var crashPending atomic.Bool
+
+// destructiveActive reports, for destructive actions that leave state
+// behind, whether that state is still in effect. Actions without an entry
+// only count toward cooldowns.
+var destructiveActive = map[string]func() bool{
+	"crash":    crashPending.Load,
+	"oom":      func() bool { return OOMState().State != api.OOMStateIdle },
+	"zombie":   func() bool { return ZombieCount() > 0 },
+	"threads":  func() bool { return ThreadsHeld() > 0 },
+	"deadlock": func() bool { return DeadlockCount() > 0 },
+	"ports":    func() bool { return PortStressState().Running },
+	"evict":    evictPending.Load,
+}
+
+// ChaosActions returns the names of every chaos action, sorted.
+func ChaosActions() []string {
+	return slices.Sorted(maps.Keys(chaosActions))
+}
+
+// IsDestructive reports whether action is a destructive chaos action.
+func IsDestructive(action string) bool {
+	return chaosActions[action]
+}
+
+// ParseChaosAllow parses a comma-separated allowlist of chaos actions and
+// the keywords all, none, and safe. An empty string or all returns nil,
+// which permits every action.
+func ParseChaosAllow(s string) (map[string]bool, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	allow := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case ChaosAllowAll:
+			return nil, nil
+		case ChaosAllowNone:
+		case ChaosAllowSafe:
+			for action, destructive := range chaosActions {
+				if !destructive {
+					allow[action] = true
+				}
+			}
+		default:
+			if _, ok := chaosActions[name]; !ok {
+				return nil, fmt.Errorf("unknown chaos action %q (want all, none, safe, or one of %s)", name, strings.Join(ChaosActions(), ", "))
+			}
+			allow[name] = true
+		}
+	}
+	return allow, nil
+}
+
+// ParseChaosCooldowns parses comma-separated action=duration pairs.
+func ParseChaosCooldowns(s string) (map[string]time.Duration, error) {
+	cooldowns := map[string]time.Duration{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, v, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if _, known := chaosActions[name]; !ok || !known {
+			return nil, fmt.Errorf("chaos cooldowns must be comma-separated action=duration pairs with an action of %s, got %q", strings.Join(ChaosActions(), ", "), pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("chaos cooldown for %s must be a non-negative duration, got %q", name, v)
+		}
+		cooldowns[name] = d
+	}
+	return cooldowns, nil
+}
+
+// GuardConfig holds the blast radius guardrails for chaos actions.
+type GuardConfig struct {
+	// Allow is the set of permitted actions (nil = all)
+	Allow map[string]bool
+	// Cooldowns is the minimum time between admitted runs of each action
+	Cooldowns map[string]time.Duration
+	// MaxDestructive is how many destructive faults may be in effect at
+	// once (0 = unlimited)
+	MaxDestructive int
+}
+
+// GuardError is a chaos action rejected by a Guard.
+type GuardError struct {
+	Action string
+	// Reason is GuardNotAllowed, GuardCooldown, or GuardLimit
+	Reason string
+	// RetryAfter is how long until a cooldown ends
+	RetryAfter time.Duration
+	msg        string
+}
+
+func (e *GuardError) Error() string {
+	return e.msg
+}
+
+// Guard limits the blast radius of chaos actions, so shared clusters can
+// permit only the faults they can tolerate. It is safe for concurrent use.
+type Guard struct {
+	cfg GuardConfig
+
+	mu   sync.Mutex
+	last map[string]time.Time
+
+	// active reports whether a destructive action is in effect
+	active func(action string) bool
+}
+
+// NewGuard creates a guard enforcing cfg.
+func NewGuard(cfg GuardConfig) *Guard {
+	return &Guard{
+		cfg:  cfg,
+		last: map[string]time.Time{},
+		active: func(action string) bool {
+			f, ok := destructiveActive[action]
+			return ok && f()
+		},
+	}
+}
+
+// Allowed reports whether action is on the allowlist. It is safe to call on
+// a nil receiver, which allows every action.
+func (g *Guard) Allowed(action string) bool {
+	return g == nil || g.cfg.Allow == nil || g.cfg.Allow[action]
+}
+
+// Admit checks whether action may start now, returning a *GuardError if it
+// is rejected. An admitted action starts its cooldown. Rejections are logged
+// and recorded as events. It is safe to call on a nil receiver, which admits
+// every action.
+func (g *Guard) Admit(action string) error {
+	if g == nil {
+		return nil
+	}
+	err := g.admit(action, time.Now())
+	if err != nil {
+		metrics.FaultChaosRejectedTotal.WithLabelValues(action, err.Reason).Inc()
+		slog.Warn("chaos action rejected", "action", action, "reason", err.Reason, "error", err.msg)
+		events.Record(slog.LevelWarn, events.TypeFault, "chaos action rejected", map[string]any{
+			"action": action,
+			"reason": err.Reason,
+		})
+		return err
+	}
+	return nil
+}
+
+func (g *Guard) admit(action string, now time.Time) *GuardError {
+	if !g.Allowed(action) {
+		return &GuardError{Action: action, Reason: GuardNotAllowed, msg: fmt.Sprintf("chaos action %s is not allowed by HOTPOD_CHAOS_ALLOW", action)}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if cd := g.cfg.Cooldowns[action]; cd > 0 {
+		if last, ok := g.last[action]; ok && now.Sub(last) < cd {
+			wait := cd - now.Sub(last)
+			return &GuardError{Action: action, Reason: GuardCooldown, RetryAfter: wait, msg: fmt.Sprintf("chaos action %s is cooling down for another %s", action, wait.Round(time.Second))}
+		}
+	}
+
+	if IsDestructive(action) && g.cfg.MaxDestructive > 0 {
+		var others []string
+		for name := range destructiveActive {
+			if name != action && g.active(name) {
+				others = append(others, name)
+			}
+		}
+		if len(others) >= g.cfg.MaxDestructive {
+			slices.Sort(others)
+			return &GuardError{Action: action, Reason: GuardLimit, msg: fmt.Sprintf("chaos action %s would exceed %d concurrent destructive faults (active: %s)", action, g.cfg.MaxDestructive, strings.Join(others, ", "))}
+		}
+	}
+
+	g.last[action] = now
+	return nil
+}
@@ -0,0 +1,124 @@
+package fault
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseChaosAllow(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantNil bool
+		wantErr bool
+	}{
+		{in: "", wantNil: true},
+		{in: "all", wantNil: true},
+		{in: "none", want: []string{}},
+		{in: "safe", want: []string{"bomb", "brownout", "contention", "error", "hang", "panic", "schedule", "storm"}},
+		{in: "safe, oom", want: []string{"bomb", "brownout", "contention", "error", "hang", "oom", "panic", "schedule", "storm"}},
+		{in: "crash,ports", want: []string{"crash", "ports"}},
+		{in: "crash,reboot", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseChaosAllow(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseChaosAllow(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if (got == nil) != tt.wantNil {
+			t.Errorf("ParseChaosAllow(%q) = %v, want nil %v", tt.in, got, tt.wantNil)
+			continue
+		}
+		if names := slices.Sorted(maps.Keys(got)); !tt.wantNil && !slices.Equal(names, tt.want) {
+			t.Errorf("ParseChaosAllow(%q) = %v, want %v", tt.in, names, tt.want)
+		}
+	}
+}
+
+func TestParseChaosCooldowns(t *testing.T) {
+	got, err := ParseChaosCooldowns("crash=10m, oom=30s")
+	if err != nil {
+		t.Fatalf("ParseChaosCooldowns() error = %v", err)
+	}
+	if want := map[string]time.Duration{"crash": 10 * time.Minute, "oom": 30 * time.Second}; !maps.Equal(got, want) {
+		t.Errorf("ParseChaosCooldowns() = %v, want %v", got, want)
+	}
+
+	for _, in := range []string{"crash", "reboot=1m", "oom=soon", "oom=-1s"} {
+		if _, err := ParseChaosCooldowns(in); err == nil {
+			t.Errorf("ParseChaosCooldowns(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestGuardAdmit(t *testing.T) {
+	allow, _ := ParseChaosAllow("safe,oom,threads,zombie")
+	g := NewGuard(GuardConfig{
+		Allow:          allow,
+		Cooldowns:      map[string]time.Duration{"hang": time.Minute},
+		MaxDestructive: 1,
+	})
+	active := map[string]bool{}
+	g.active = func(action string) bool { return active[action] }
+
+	now := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		action     string
+		offset     time.Duration
+		active     []string
+		wantReason string
+	}{
+		{action: "crash", wantReason: GuardNotAllowed},
+		{action: "hang"},
+		{action: "hang", offset: 30 * time.Second, wantReason: GuardCooldown},
+		{action: "hang", offset: time.Minute},
+		{action: "oom"},
+		// Another destructive fault is in effect.
+		{action: "threads", active: []string{"oom"}, wantReason: GuardLimit},
+		// More of the fault already in effect is not another fault.
+		{action: "oom", active: []string{"oom"}},
+		// Non-destructive actions are not limited.
+		{action: "error", active: []string{"oom", "zombie"}},
+	}
+	for i, step := range steps {
+		clear(active)
+		for _, a := range step.active {
+			active[a] = true
+		}
+		err := g.admit(step.action, now.Add(step.offset))
+		var reason string
+		if err != nil {
+			reason = err.Reason
+		}
+		if reason != step.wantReason {
+			t.Errorf("step %d: admit(%s) reason = %q, want %q (%v)", i, step.action, reason, step.wantReason, err)
+		}
+	}
+}
+
+func TestGuardCooldownRetryAfter(t *testing.T) {
+	g := NewGuard(GuardConfig{Cooldowns: map[string]time.Duration{"crash": time.Minute}})
+	if err := g.Admit("crash"); err != nil {
+		t.Fatalf("first Admit() error = %v", err)
+	}
+	err := g.Admit("crash")
+	var ge *GuardError
+	if !errors.As(err, &ge) || ge.Reason != GuardCooldown {
+		t.Fatalf("second Admit() error = %v, want cooldown", err)
+	}
+	if ge.RetryAfter <= 0 || ge.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %s, want within 1m", ge.RetryAfter)
+	}
+
+	var nilGuard *Guard
+	if err := nilGuard.Admit("crash"); err != nil {
+		t.Errorf("nil guard Admit() error = %v, want nil", err)
+	}
+}
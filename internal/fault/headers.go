@@ -0,0 +1,122 @@
+package fault
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderCorruption names a way of corrupting response headers.
+type HeaderCorruption string
+
+const (
+	// CorruptContentLengthLong declares more bytes than are sent, so the
+	// client sees an unexpected EOF.
+	CorruptContentLengthLong HeaderCorruption = "content-length-long"
+	// CorruptContentLengthShort declares fewer bytes than the body, so the
+	// client reads a truncated body as if it were complete.
+	CorruptContentLengthShort HeaderCorruption = "content-length-short"
+	// CorruptContentType replaces the content type with one that does not
+	// match the body.
+	CorruptContentType HeaderCorruption = "content-type"
+	// CorruptHugeCookie adds a Set-Cookie header larger than browsers and
+	// most proxies accept.
+	CorruptHugeCookie HeaderCorruption = "huge-cookie"
+	// CorruptConnectionClose sends Connection: close, so the server closes
+	// the connection after the response; over HTTP/2 it sends GOAWAY.
+	CorruptConnectionClose HeaderCorruption = "connection-close"
+)
+
+// hugeCookieSize is the value size of the CorruptHugeCookie cookie, well past
+// the 4KiB browsers allow and the 8KiB many proxies buffer for headers.
+const hugeCookieSize = 16 << 10
+
+// ParseHeaderCorruption parses a header corruption name.
+func ParseHeaderCorruption(s string) (HeaderCorruption, error) {
+	switch c := HeaderCorruption(s); c {
+	case CorruptContentLengthLong, CorruptContentLengthShort, CorruptContentType, CorruptHugeCookie, CorruptConnectionClose:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown header corruption %q (want content-length-long, content-length-short, content-type, huge-cookie, or connection-close)", s)
+}
+
+// HeaderConfig holds the response header faults for an endpoint.
+type HeaderConfig struct {
+	// Rate is the probability of applying the faults (0.0 to 1.0)
+	Rate float64
+	// Set adds or overwrites response headers
+	Set map[string]string
+	// Remove deletes response headers
+	Remove []string
+	// Corrupt lists corruptions to apply after Set and Remove
+	Corrupt []HeaderCorruption
+	// ExpiresAt is when this configuration expires (zero means never)
+	ExpiresAt time.Time
+}
+
+// IsExpired returns true if the configuration has expired.
+func (c *HeaderConfig) IsExpired() bool {
+	if c.ExpiresAt.IsZero() {
+		return false
+	}
+	return time.Now().After(c.ExpiresAt)
+}
+
+// ShouldInject returns true if the faults should be applied based on the rate.
+func (c *HeaderConfig) ShouldInject() bool {
+	if c.Rate <= 0 {
+		return false
+	}
+	if c.Rate >= 1 {
+		return true
+	}
+	return rand.Float64() < c.Rate
+}
+
+// NeedsBodyLength reports whether Apply needs the final body length, which
+// requires the response to be buffered.
+func (c *HeaderConfig) NeedsBodyLength() bool {
+	for _, corruption := range c.Corrupt {
+		if corruption == CorruptContentLengthLong || corruption == CorruptContentLengthShort {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply mutates h. bodyLen is the length of the response body, or -1 if it
+// is not known.
+func (c *HeaderConfig) Apply(h http.Header, bodyLen int) {
+	for k, v := range c.Set {
+		h.Set(k, v)
+	}
+	for _, k := range c.Remove {
+		h.Del(k)
+	}
+
+	for _, corruption := range c.Corrupt {
+		switch corruption {
+		case CorruptContentLengthLong:
+			if bodyLen >= 0 {
+				h.Set("Content-Length", strconv.Itoa(bodyLen+bodyLen/2+1))
+			}
+		case CorruptContentLengthShort:
+			if bodyLen > 0 {
+				h.Set("Content-Length", strconv.Itoa(bodyLen/2))
+			}
+		case CorruptContentType:
+			if strings.HasPrefix(h.Get("Content-Type"), "text/html") {
+				h.Set("Content-Type", "application/json")
+			} else {
+				h.Set("Content-Type", "text/html; charset=utf-8")
+			}
+		case CorruptHugeCookie:
+			h.Add("Set-Cookie", "hotpod_huge="+strings.Repeat("x", hugeCookieSize)+"; Path=/")
+		case CorruptConnectionClose:
+			h.Set("Connection", "close")
+		}
+	}
+}
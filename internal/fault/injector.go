@@ -4,14 +4,25 @@ import (
 	"math/rand/v2"
 	"sync"
 	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
 )
 
+// GlobalEndpoint is the endpoint label reported for the global error rate.
+const GlobalEndpoint = "*"
+
 // ErrorConfig holds the error injection configuration for an endpoint.
 type ErrorConfig struct {
 	// Rate is the probability of injecting an error (0.0 to 1.0)
 	Rate float64
 	// Codes is the list of HTTP status codes to randomly select from
 	Codes []int
+	// Delay is how long to wait before writing an injected error
+	Delay time.Duration
+	// DelayDist shapes Delay (zero value = constant)
+	DelayDist Distribution
+	// Body renders the injected error body (nil means the default JSON body)
+	Body *ErrorBody
 	// ExpiresAt is when this configuration expires (zero means never)
 	ExpiresAt time.Time
 }
@@ -35,6 +46,15 @@ func (c *ErrorConfig) ShouldInject() bool {
 	return rand.Float64() < c.Rate
 }
 
+// MaxErrorDelay caps how long an injected error may be delayed, including
+// any tail drawn from DelayDist.
+const MaxErrorDelay = 5 * time.Minute
+
+// SampleDelay returns how long to wait before writing an injected error.
+func (c *ErrorConfig) SampleDelay() time.Duration {
+	return min(c.DelayDist.Sample(c.Delay), MaxErrorDelay)
+}
+
 // SelectCode returns a random status code from the configured codes.
 func (c *ErrorConfig) SelectCode() int {
 	if len(c.Codes) == 0 {
@@ -53,12 +73,16 @@ type Injector struct {
 	configs map[string]*ErrorConfig
 	// globalConfig applies to all endpoints if set
 	globalConfig *ErrorConfig
+	// headerConfigs maps endpoint paths to response header faults; the
+	// empty path applies to all endpoints
+	headerConfigs map[string]*HeaderConfig
 }
 
 // NewInjector creates a new error injector.
 func NewInjector() *Injector {
 	return &Injector{
-		configs: make(map[string]*ErrorConfig),
+		configs:       make(map[string]*ErrorConfig),
+		headerConfigs: make(map[string]*HeaderConfig),
 	}
 }
 
@@ -71,6 +95,7 @@ func (i *Injector) SetEndpointConfig(endpoint string, cfg *ErrorConfig) {
 	} else {
 		i.configs[endpoint] = cfg
 	}
+	i.updateMetrics(cfg)
 }
 
 // SetGlobalConfig sets the global error configuration that applies to all endpoints.
@@ -78,6 +103,7 @@ func (i *Injector) SetGlobalConfig(cfg *ErrorConfig) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 	i.globalConfig = cfg
+	i.updateMetrics(cfg)
 }
 
 // GetConfig returns the error configuration for an endpoint.
@@ -100,6 +126,17 @@ func (i *Injector) GetConfig(endpoint string) *ErrorConfig {
 	return nil
 }
 
+// GetEndpointConfig returns the endpoint-specific error configuration,
+// ignoring the global configuration. Returns nil if none is set.
+func (i *Injector) GetEndpointConfig(endpoint string) *ErrorConfig {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if cfg, ok := i.configs[endpoint]; ok && !cfg.IsExpired() {
+		return cfg
+	}
+	return nil
+}
+
 // ShouldInjectError checks if an error should be injected for the given endpoint.
 // Returns the status code to inject, or 0 if no error should be injected.
 func (i *Injector) ShouldInjectError(endpoint string) int {
@@ -119,6 +156,29 @@ func (i *Injector) Reset() {
 	defer i.mu.Unlock()
 	i.configs = make(map[string]*ErrorConfig)
 	i.globalConfig = nil
+	i.headerConfigs = make(map[string]*HeaderConfig)
+	i.updateMetrics(nil)
+}
+
+// Replace atomically swaps the whole error configuration for the given global
+// and endpoint configurations. Entries with a non-positive rate are dropped.
+// Header faults are left untouched.
+func (i *Injector) Replace(global *ErrorConfig, endpoints map[string]*ErrorConfig) {
+	configs := make(map[string]*ErrorConfig, len(endpoints))
+	for endpoint, cfg := range endpoints {
+		if cfg != nil && cfg.Rate > 0 {
+			configs[endpoint] = cfg
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.configs = configs
+	i.globalConfig = global
+	i.updateMetrics(global)
+	for _, cfg := range configs {
+		i.updateMetrics(cfg)
+	}
 }
 
 // GetGlobalConfig returns the current global error configuration, or nil if not set.
@@ -144,6 +204,51 @@ func (i *Injector) GetEndpointConfigs() map[string]*ErrorConfig {
 	return result
 }
 
+// SetHeaderConfig sets the response header faults for an endpoint, or for
+// all endpoints if endpoint is empty. A nil config removes them.
+func (i *Injector) SetHeaderConfig(endpoint string, cfg *HeaderConfig) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if cfg == nil || cfg.Rate <= 0 {
+		delete(i.headerConfigs, endpoint)
+	} else {
+		i.headerConfigs[endpoint] = cfg
+	}
+}
+
+// GetHeaderConfig returns the response header faults for an endpoint,
+// falling back to those for all endpoints. Returns nil if none apply.
+func (i *Injector) GetHeaderConfig(endpoint string) *HeaderConfig {
+	if cfg := i.GetEndpointHeaderConfig(endpoint); cfg != nil {
+		return cfg
+	}
+	return i.GetEndpointHeaderConfig("")
+}
+
+// GetEndpointHeaderConfig returns the response header faults configured for
+// exactly endpoint. Returns nil if none are set.
+func (i *Injector) GetEndpointHeaderConfig(endpoint string) *HeaderConfig {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if cfg, ok := i.headerConfigs[endpoint]; ok && !cfg.IsExpired() {
+		return cfg
+	}
+	return nil
+}
+
+// GetHeaderConfigs returns a copy of all response header fault configurations.
+func (i *Injector) GetHeaderConfigs() map[string]*HeaderConfig {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	result := make(map[string]*HeaderConfig, len(i.headerConfigs))
+	for k, v := range i.headerConfigs {
+		if !v.IsExpired() {
+			result[k] = v
+		}
+	}
+	return result
+}
+
 // GetEndpointRate returns the current error rate for an endpoint (for metrics).
 func (i *Injector) GetEndpointRate(endpoint string) float64 {
 	cfg := i.GetConfig(endpoint)
@@ -152,3 +257,25 @@ func (i *Injector) GetEndpointRate(endpoint string) float64 {
 	}
 	return cfg.Rate
 }
+
+// updateMetrics republishes the configured rates. Callers must hold i.mu. If
+// changed expires, the rates are republished again once it has.
+func (i *Injector) updateMetrics(changed *ErrorConfig) {
+	metrics.FaultErrorRate.Reset()
+	if i.globalConfig != nil && !i.globalConfig.IsExpired() {
+		metrics.FaultErrorRate.WithLabelValues(GlobalEndpoint).Set(i.globalConfig.Rate)
+	}
+	for endpoint, cfg := range i.configs {
+		if !cfg.IsExpired() {
+			metrics.FaultErrorRate.WithLabelValues(endpoint).Set(cfg.Rate)
+		}
+	}
+
+	if changed != nil && !changed.ExpiresAt.IsZero() {
+		time.AfterFunc(time.Until(changed.ExpiresAt)+time.Millisecond, func() {
+			i.mu.Lock()
+			defer i.mu.Unlock()
+			i.updateMetrics(nil)
+		})
+	}
+}
@@ -3,6 +3,10 @@ package fault
 import (
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ripta/hotpod/internal/metrics"
 )
 
 func TestErrorConfigIsExpired(t *testing.T) {
@@ -203,6 +207,26 @@ func TestInjectorReset(t *testing.T) {
 	}
 }
 
+func TestInjectorReplace(t *testing.T) {
+	inj := NewInjector()
+
+	inj.SetEndpointConfig("/old", &ErrorConfig{Rate: 1, Codes: []int{500}})
+	inj.Replace(nil, map[string]*ErrorConfig{
+		"/new":  {Rate: 0.5, Codes: []int{503}},
+		"/zero": {Rate: 0},
+	})
+
+	if inj.GetConfig("/old") != nil {
+		t.Error("replace should drop endpoints not in the new set")
+	}
+	if cfg := inj.GetConfig("/new"); cfg == nil || cfg.Rate != 0.5 {
+		t.Errorf("/new config = %+v, want rate 0.5", cfg)
+	}
+	if inj.GetConfig("/zero") != nil {
+		t.Error("replace should drop zero-rate entries")
+	}
+}
+
 func TestInjectorGetEndpointRate(t *testing.T) {
 	inj := NewInjector()
 
@@ -217,3 +241,23 @@ func TestInjectorGetEndpointRate(t *testing.T) {
 		t.Errorf("rate = %f, want 0.75", rate)
 	}
 }
+
+func TestInjectorRateMetric(t *testing.T) {
+	inj := NewInjector()
+	defer inj.Reset()
+
+	inj.SetGlobalConfig(&ErrorConfig{Rate: 0.25})
+	inj.SetEndpointConfig("/cpu", &ErrorConfig{Rate: 0.5, ExpiresAt: time.Now().Add(20 * time.Millisecond)})
+
+	if got := testutil.ToFloat64(metrics.FaultErrorRate.WithLabelValues(GlobalEndpoint)); got != 0.25 {
+		t.Errorf("global rate metric = %v, want 0.25", got)
+	}
+	if got := testutil.ToFloat64(metrics.FaultErrorRate.WithLabelValues("/cpu")); got != 0.5 {
+		t.Errorf("/cpu rate metric = %v, want 0.5", got)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := testutil.CollectAndCount(metrics.FaultErrorRate); n != 1 {
+		t.Errorf("rate metric series = %d, want 1 after /cpu expired", n)
+	}
+}
@@ -0,0 +1,149 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+)
+
+// MaxDeadlocks bounds the number of deadlocked goroutine pairs.
+const MaxDeadlocks = 100
+
+// deadlockMu protects deadlocks.
+var deadlockMu sync.Mutex
+
+// deadlocks counts deadlocked goroutine pairs created so far.
+var deadlocks int
+
+// Deadlock creates count pairs of goroutines that acquire two mutexes in
+// opposite order and block forever. The runtime does not detect the deadlock
+// while other goroutines run, so the pairs persist until the process exits;
+// they are visible in goroutine dumps and mutex/block profiles.
+func Deadlock(count int) (int, error) {
+	deadlockMu.Lock()
+	defer deadlockMu.Unlock()
+
+	if deadlocks+count > MaxDeadlocks {
+		return deadlocks, fmt.Errorf("at most %d deadlocks may exist at once, %d already exist", MaxDeadlocks, deadlocks)
+	}
+
+	for range count {
+		deadlockPair()
+	}
+	deadlocks += count
+	metrics.FaultDeadlockedGoroutines.Set(float64(2 * deadlocks))
+
+	slog.Warn("deadlock created", "pairs", count, "total_pairs", deadlocks)
+	events.Record(slog.LevelWarn, events.TypeFault, "deadlock created", map[string]any{
+		"pairs":       count,
+		"total_pairs": deadlocks,
+	})
+	return deadlocks, nil
+}
+
+// deadlockPair starts two goroutines that each hold one lock while waiting
+// for the other's, and returns once both hold their first lock.
+func deadlockPair() {
+	var a, b sync.Mutex
+	var holding sync.WaitGroup
+	holding.Add(2)
+
+	go func() {
+		a.Lock()
+		holding.Done()
+		holding.Wait()
+		b.Lock()
+	}()
+	go func() {
+		b.Lock()
+		holding.Done()
+		holding.Wait()
+		a.Lock()
+	}()
+
+	holding.Wait()
+}
+
+// DeadlockCount returns the number of deadlocked goroutine pairs.
+func DeadlockCount() int {
+	deadlockMu.Lock()
+	defer deadlockMu.Unlock()
+	return deadlocks
+}
+
+// ContentionResult summarizes a mutex contention run.
+type ContentionResult struct {
+	Acquisitions int64
+	Wait         time.Duration
+}
+
+// Contention runs workers goroutines that repeatedly lock a single shared
+// mutex and spin for hold inside it, until duration elapses or ctx is
+// cancelled. Mutex and block profiling are enabled for the run so the
+// contention shows up in /debug/pprof/mutex and /debug/pprof/block.
+func Contention(ctx context.Context, workers int, hold, duration time.Duration) ContentionResult {
+	prevFraction := runtime.SetMutexProfileFraction(1)
+	runtime.SetBlockProfileRate(int(time.Microsecond))
+	defer func() {
+		runtime.SetMutexProfileFraction(prevFraction)
+		runtime.SetBlockProfileRate(0)
+	}()
+
+	slog.Warn("mutex contention started", "workers", workers, "hold", hold, "duration", duration)
+	events.Record(slog.LevelWarn, events.TypeFault, "mutex contention started", map[string]any{
+		"workers":  workers,
+		"hold":     hold.String(),
+		"duration": duration.String(),
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		statMu sync.Mutex
+		result ContentionResult
+		wg     sync.WaitGroup
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var acquired int64
+			var waited time.Duration
+			for ctx.Err() == nil {
+				start := time.Now()
+				mu.Lock()
+				w := time.Since(start)
+				spin(hold)
+				mu.Unlock()
+
+				acquired++
+				waited += w
+				metrics.FaultContentionAcquisitionsTotal.Inc()
+				metrics.FaultContentionWaitSecondsTotal.Add(w.Seconds())
+			}
+
+			statMu.Lock()
+			result.Acquisitions += acquired
+			result.Wait += waited
+			statMu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	slog.Info("mutex contention finished", "acquisitions", result.Acquisitions, "wait", result.Wait)
+	return result
+}
+
+// spin busy-waits for d, keeping the lock holder on-CPU.
+func spin(d time.Duration) {
+	for start := time.Now(); time.Since(start) < d; {
+	}
+}
@@ -0,0 +1,32 @@
+package fault
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeadlock(t *testing.T) {
+	before := DeadlockCount()
+	total, err := Deadlock(2)
+	if err != nil {
+		t.Fatalf("Deadlock: %v", err)
+	}
+	if total != before+2 {
+		t.Errorf("total = %d, want %d", total, before+2)
+	}
+
+	if _, err := Deadlock(MaxDeadlocks); err == nil {
+		t.Error("expected error above MaxDeadlocks")
+	}
+}
+
+func TestContention(t *testing.T) {
+	result := Contention(context.Background(), 4, 100*time.Microsecond, 50*time.Millisecond)
+	if result.Acquisitions == 0 {
+		t.Error("expected mutex acquisitions")
+	}
+	if result.Wait <= 0 {
+		t.Error("expected time spent waiting for the mutex")
+	}
+}
@@ -0,0 +1,192 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// OOMStatus reports the state of the current or last OOM simulation.
+type OOMStatus = api.OOMStatus
+
+// ErrOOMRunning is returned when an OOM simulation is already allocating.
+var ErrOOMRunning = errors.New("OOM simulation already allocating")
+
+// OOMConfig configures an OOM simulation.
+type OOMConfig struct {
+	// Rate is the allocation rate in bytes per second
+	Rate int64
+	// Target stops allocating once this many bytes are held (0 = no target)
+	Target int64
+	// Reached, when non-nil, stops allocating once it reports true
+	Reached func() bool
+	// Until describes Reached in the status
+	Until string
+}
+
+// oom holds the state of the single simulation. The allocating goroutine
+// owns its buffers until it stops; if it stopped at a target, they move to
+// sink and are held until the simulation is stopped or restarted.
+var oom struct {
+	// ctl serializes StartOOM and StopOOM; mu guards the fields below
+	ctl       sync.Mutex
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+	status    OOMStatus
+	allocated atomic.Int64
+	sink      [][]byte
+}
+
+// StartOOM begins allocating memory at cfg.Rate. Without a target, it
+// allocates until the process is killed. With one, the memory is held once
+// the target is reached, keeping the container near its limit until StopOOM
+// is called. Memory held by a previous simulation is released first.
+func StartOOM(cfg OOMConfig) error {
+	oom.ctl.Lock()
+	defer oom.ctl.Unlock()
+	oom.mu.Lock()
+	defer oom.mu.Unlock()
+
+	if oom.status.State == api.OOMStateAllocating {
+		return ErrOOMRunning
+	}
+	if oom.cancel != nil {
+		oom.cancel()
+	}
+	oom.sink = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := time.Now()
+	oom.cancel = cancel
+	oom.done = make(chan struct{})
+	oom.allocated.Store(0)
+	oom.status = OOMStatus{
+		State:     api.OOMStateAllocating,
+		Rate:      cfg.Rate,
+		Until:     cfg.Until,
+		Target:    cfg.Target,
+		StartedAt: &started,
+	}
+
+	slog.Warn("OOM simulation started", "rate_bytes_per_sec", cfg.Rate, "target", cfg.Target, "until", cfg.Until)
+	events.Record(slog.LevelWarn, events.TypeFault, "OOM simulation started", map[string]any{
+		"rate_bytes_per_sec": cfg.Rate,
+		"target":             cfg.Target,
+		"until":              cfg.Until,
+	})
+
+	go runOOM(ctx, cfg, oom.done)
+	return nil
+}
+
+// StopOOM cancels the active simulation and releases any held memory,
+// returning the number of bytes released.
+func StopOOM() int64 {
+	oom.ctl.Lock()
+	defer oom.ctl.Unlock()
+
+	oom.mu.Lock()
+	cancel, done := oom.cancel, oom.done
+	oom.mu.Unlock()
+	if cancel == nil {
+		return 0
+	}
+
+	cancel()
+	<-done
+
+	oom.mu.Lock()
+	released := oom.allocated.Swap(0)
+	oom.sink = nil
+	oom.status.State = api.OOMStateIdle
+	oom.mu.Unlock()
+
+	if released > 0 {
+		// Return the pages to the OS now rather than waiting for the
+		// scavenger, so container usage drops as soon as the call returns.
+		debug.FreeOSMemory()
+		slog.Info("OOM simulation memory released", "released", released)
+		events.Record(slog.LevelInfo, events.TypeFault, "OOM simulation memory released", map[string]any{
+			"released": released,
+		})
+	}
+	return released
+}
+
+// OOMState returns the status of the current or last simulation.
+func OOMState() OOMStatus {
+	oom.mu.Lock()
+	defer oom.mu.Unlock()
+
+	s := oom.status
+	if s.State == "" {
+		s.State = api.OOMStateIdle
+	}
+	s.Allocated = oom.allocated.Load()
+	return s
+}
+
+// runOOM allocates in ticks of a tenth of the rate until ctx is cancelled or
+// the target is reached, then hands its buffers to oom.sink to be held.
+func runOOM(ctx context.Context, cfg OOMConfig, done chan struct{}) {
+	defer close(done)
+
+	// Calculate allocation size per tick (10 ticks per second)
+	tickInterval := 100 * time.Millisecond
+	allocSize := max(cfg.Rate/10, 1024) // Minimum 1KB per tick
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	// Use a slice of slices to prevent garbage collection
+	var sink [][]byte
+	totalAllocated := int64(0)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("OOM simulation cancelled", "total_allocated", totalAllocated)
+			events.Record(slog.LevelInfo, events.TypeFault, "OOM simulation cancelled", map[string]any{
+				"total_allocated": totalAllocated,
+			})
+			return
+		case <-ticker.C:
+			if (cfg.Target > 0 && totalAllocated >= cfg.Target) || (cfg.Reached != nil && cfg.Reached()) {
+				slog.Warn("OOM simulation reached target, holding", "total_allocated", totalAllocated)
+				events.Record(slog.LevelWarn, events.TypeFault, "OOM simulation reached target", map[string]any{
+					"total_allocated": totalAllocated,
+				})
+				oom.mu.Lock()
+				oom.sink = sink
+				oom.status.State = api.OOMStateHolding
+				oom.mu.Unlock()
+				return
+			}
+
+			size := allocSize
+			if cfg.Target > 0 {
+				size = min(size, cfg.Target-totalAllocated)
+			}
+
+			// Allocate memory and touch it to ensure it's actually allocated
+			buf := make([]byte, size)
+			for i := range buf {
+				buf[i] = byte(i)
+			}
+			sink = append(sink, buf)
+			totalAllocated += size
+			oom.allocated.Store(totalAllocated)
+
+			if totalAllocated%(100<<20) == 0 { // Log every 100MB
+				slog.Info("OOM progress", "allocated_mb", totalAllocated>>20)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package fault
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// waitOOMState polls until the simulation reaches state.
+func waitOOMState(t *testing.T, state string) OOMStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s := OOMState()
+		if s.State == state {
+			return s
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("state = %q, want %q", s.State, state)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOOMStopsWhenReached(t *testing.T) {
+	t.Cleanup(func() { StopOOM() })
+
+	var checks atomic.Int32
+	if err := StartOOM(OOMConfig{Rate: 10 << 10, Reached: func() bool { return checks.Add(1) > 2 }}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := waitOOMState(t, api.OOMStateHolding)
+	if want := int64(2 << 10); s.Allocated != want {
+		t.Errorf("allocated = %d, want %d from 2 ticks before the target was reached", s.Allocated, want)
+	}
+}
+
+func TestOOMHoldsTarget(t *testing.T) {
+	t.Cleanup(func() { StopOOM() })
+
+	// 400KiB per tick does not divide the target, so the last tick is short.
+	const target = 1 << 20
+	if err := StartOOM(OOMConfig{Rate: 4 << 20, Target: target}); err != nil {
+		t.Fatal(err)
+	}
+	if err := StartOOM(OOMConfig{Rate: 4 << 20}); !errors.Is(err, ErrOOMRunning) {
+		t.Errorf("second StartOOM() error = %v, want ErrOOMRunning", err)
+	}
+
+	s := waitOOMState(t, api.OOMStateHolding)
+	if s.Allocated != target || s.Target != target {
+		t.Errorf("status = %+v, want %d bytes allocated and held", s, target)
+	}
+
+	if released := StopOOM(); released != target {
+		t.Errorf("StopOOM() = %d, want %d", released, target)
+	}
+	if s := OOMState(); s.State != api.OOMStateIdle || s.Allocated != 0 {
+		t.Errorf("status after stop = %+v, want idle with nothing allocated", s)
+	}
+	oom.mu.Lock()
+	defer oom.mu.Unlock()
+	if oom.sink != nil {
+		t.Error("held memory was not released")
+	}
+}
+
+func TestOOMStopWhileAllocating(t *testing.T) {
+	if err := StartOOM(OOMConfig{Rate: 1 << 20}); err != nil {
+		t.Fatal(err)
+	}
+	StopOOM()
+	if s := OOMState(); s.State != api.OOMStateIdle {
+		t.Errorf("state = %q, want %q", s.State, api.OOMStateIdle)
+	}
+	if err := StartOOM(OOMConfig{Rate: 1 << 20, Target: 1 << 10}); err != nil {
+		t.Errorf("StartOOM() after stop error = %v", err)
+	}
+	StopOOM()
+}
@@ -0,0 +1,250 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// PortStressStatus reports progress of the current or last run.
+type PortStressStatus = api.PortStressStatus
+
+// ErrPortStressRunning is returned when a port exhaustion run is already active.
+var ErrPortStressRunning = errors.New("port exhaustion already running")
+
+// MaxPortStressRate bounds the connections started per second. Far beyond
+// what a host can dial, it keeps the pacing interval positive.
+const MaxPortStressRate = 1e6
+
+// PortStressConfig configures an ephemeral port exhaustion run.
+type PortStressConfig struct {
+	// Target is the host:port to connect to
+	Target string
+	// Rate is the number of connections started per second (0 = as fast as workers allow)
+	Rate float64
+	// Concurrency is the number of dialing workers
+	Concurrency int
+	// Hold keeps each connection open before closing it
+	Hold time.Duration
+	// Duration bounds the run
+	Duration time.Duration
+	// DialTimeout bounds each connection attempt
+	DialTimeout time.Duration
+}
+
+// portStress holds the state of the single active run.
+var portStress struct {
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	target  string
+	started time.Time
+	running bool
+	counts  map[string]*atomic.Int64
+}
+
+// StartPortStress begins opening short-lived outbound connections to
+// cfg.Target. Each closed connection leaves its local port in TIME_WAIT, so a
+// high rate exhausts the ephemeral port range, or the SNAT ports of a cloud
+// NAT gateway when the target is outside the cluster.
+func StartPortStress(cfg PortStressConfig) error {
+	if !(cfg.Rate >= 0 && cfg.Rate <= MaxPortStressRate) {
+		return fmt.Errorf("rate must be between 0 and %g, got %g", MaxPortStressRate, cfg.Rate)
+	}
+
+	portStress.mu.Lock()
+	defer portStress.mu.Unlock()
+
+	if portStress.running {
+		return ErrPortStressRunning
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Duration)
+	portStress.cancel = cancel
+	portStress.target = cfg.Target
+	portStress.started = time.Now()
+	portStress.running = true
+	portStress.counts = map[string]*atomic.Int64{}
+	for _, outcome := range portOutcomes {
+		portStress.counts[outcome] = &atomic.Int64{}
+	}
+
+	slog.Warn("port exhaustion started", "target", cfg.Target, "rate", cfg.Rate, "concurrency", cfg.Concurrency, "duration", cfg.Duration)
+	events.Record(slog.LevelWarn, events.TypeFault, "port exhaustion started", map[string]any{
+		"target":      cfg.Target,
+		"rate":        cfg.Rate,
+		"concurrency": cfg.Concurrency,
+		"duration":    cfg.Duration.String(),
+	})
+
+	go runPortStress(ctx, cfg, portStress.counts)
+	return nil
+}
+
+// StopPortStress cancels the active run, if any, and reports whether one was running.
+func StopPortStress() bool {
+	portStress.mu.Lock()
+	defer portStress.mu.Unlock()
+	if !portStress.running {
+		return false
+	}
+	portStress.cancel()
+	return true
+}
+
+// PortStressState returns the status of the current or last run.
+func PortStressState() PortStressStatus {
+	portStress.mu.Lock()
+	defer portStress.mu.Unlock()
+
+	s := PortStressStatus{Running: portStress.running, Target: portStress.target}
+	if portStress.counts == nil {
+		return s
+	}
+	started := portStress.started
+	s.StartedAt = &started
+	for outcome, c := range portStress.counts {
+		n := c.Load()
+		s.Attempts += n
+		if outcome == portOutcomeConnected {
+			s.Connected = n
+			continue
+		}
+		if n > 0 {
+			if s.Errors == nil {
+				s.Errors = map[string]int64{}
+			}
+			s.Errors[outcome] = n
+		}
+	}
+	return s
+}
+
+const (
+	portOutcomeConnected    = "connected"
+	portOutcomeAddrNotAvail = "addr_unavailable"
+	portOutcomeRefused      = "refused"
+	portOutcomeTimeout      = "timeout"
+	portOutcomeTooManyOpen  = "too_many_open_files"
+	portOutcomeOther        = "error"
+)
+
+var portOutcomes = []string{
+	portOutcomeConnected,
+	portOutcomeAddrNotAvail,
+	portOutcomeRefused,
+	portOutcomeTimeout,
+	portOutcomeTooManyOpen,
+	portOutcomeOther,
+}
+
+func runPortStress(ctx context.Context, cfg PortStressConfig, counts map[string]*atomic.Int64) {
+	defer func() {
+		portStress.mu.Lock()
+		portStress.running = false
+		portStress.cancel()
+		portStress.mu.Unlock()
+
+		s := PortStressState()
+		slog.Info("port exhaustion finished", "attempts", s.Attempts, "connected", s.Connected, "errors", s.Errors)
+		events.Record(slog.LevelInfo, events.TypeFault, "port exhaustion finished", map[string]any{
+			"attempts":  s.Attempts,
+			"connected": s.Connected,
+		})
+	}()
+
+	jobs := make(chan struct{})
+	var wg sync.WaitGroup
+	for range cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := net.Dialer{Timeout: cfg.DialTimeout}
+			for range jobs {
+				outcome := dialOnce(ctx, &d, cfg.Target, cfg.Hold)
+				if outcome == "" {
+					continue
+				}
+				counts[outcome].Add(1)
+				metrics.FaultPortConnectsTotal.WithLabelValues(outcome).Inc()
+			}
+		}()
+	}
+
+	var tick <-chan time.Time
+	if cfg.Rate > 0 {
+		ticker := time.NewTicker(max(time.Duration(float64(time.Second)/cfg.Rate), time.Nanosecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for ctx.Err() == nil {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				continue
+			}
+		}
+		select {
+		case jobs <- struct{}{}:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// dialOnce opens one connection, holds it, and closes it from our side so
+// the local port enters TIME_WAIT. It returns an empty outcome for a dial
+// aborted because the run ended, which says nothing about the target.
+func dialOnce(ctx context.Context, d *net.Dialer, target string, hold time.Duration) string {
+	conn, err := d.DialContext(ctx, "tcp", target)
+	if err != nil {
+		if ctx.Err() != nil || runEnded(ctx) {
+			return ""
+		}
+		return classifyDialError(err)
+	}
+	if hold > 0 {
+		select {
+		case <-time.After(hold):
+		case <-ctx.Done():
+		}
+	}
+	conn.Close()
+	return portOutcomeConnected
+}
+
+// runEnded reports whether ctx's deadline has passed. The dialer applies that
+// deadline to the socket itself, so a dial can time out a moment before
+// ctx.Err() is set.
+func runEnded(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && !time.Now().Before(deadline)
+}
+
+func classifyDialError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.EADDRNOTAVAIL):
+		return portOutcomeAddrNotAvail
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return portOutcomeRefused
+	case errors.Is(err, syscall.EMFILE), errors.Is(err, syscall.ENFILE):
+		return portOutcomeTooManyOpen
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return portOutcomeTimeout
+	default:
+		return portOutcomeOther
+	}
+}
@@ -0,0 +1,94 @@
+package fault
+
+import (
+	"errors"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPortStress(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+
+	cfg := PortStressConfig{
+		Target:      ln.Addr().String(),
+		Rate:        200,
+		Concurrency: 2,
+		Duration:    100 * time.Millisecond,
+		DialTimeout: time.Second,
+	}
+	if err := StartPortStress(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := StartPortStress(cfg); !errors.Is(err, ErrPortStressRunning) {
+		t.Errorf("second start error = %v, want ErrPortStressRunning", err)
+	}
+
+	s := waitPortStressDone(t)
+	if s.Connected == 0 || s.Attempts != s.Connected {
+		t.Errorf("status = %+v, want only successful connections", s)
+	}
+}
+
+func TestPortStressRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := ln.Addr().String()
+	ln.Close()
+
+	if err := StartPortStress(PortStressConfig{Target: target, Rate: 100, Concurrency: 1, Duration: time.Minute, DialTimeout: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !StopPortStress() {
+		t.Error("StopPortStress() = false, want true")
+	}
+
+	s := waitPortStressDone(t)
+	if s.Errors[portOutcomeRefused] == 0 {
+		t.Errorf("errors = %v, want refused", s.Errors)
+	}
+}
+
+func TestPortStressRate(t *testing.T) {
+	for _, rate := range []float64{-1, 1e10, math.Inf(1), math.NaN()} {
+		if err := StartPortStress(PortStressConfig{Target: "127.0.0.1:1", Rate: rate, Concurrency: 1, Duration: time.Minute}); err == nil {
+			StopPortStress()
+			t.Errorf("StartPortStress(rate=%g) = nil, want an error", rate)
+		}
+	}
+	if PortStressState().Running {
+		t.Error("a run started with an invalid rate")
+	}
+}
+
+func waitPortStressDone(t *testing.T) PortStressStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s := PortStressState()
+		if !s.Running {
+			return s
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("port stress did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
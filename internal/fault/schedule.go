@@ -0,0 +1,319 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+)
+
+// Kinds of fault the chaos schedule can trigger.
+const (
+	// ChaosHang stalls every request until the fault ends.
+	ChaosHang = "hang"
+	// ChaosErrorBurst fails a fraction of requests with an error status.
+	ChaosErrorBurst = "error-burst"
+	// ChaosLatencySpike adds a fixed delay to every request.
+	ChaosLatencySpike = "latency-spike"
+)
+
+// Chaos schedule limits.
+const (
+	// MinChaosInterval bounds how often the schedule rolls for a fault.
+	MinChaosInterval = time.Second
+	// MaxChaosDuration bounds how long a triggered fault lasts.
+	MaxChaosDuration = time.Hour
+)
+
+// chaosTick is how often Run checks whether to roll or end a fault.
+const chaosTick = 250 * time.Millisecond
+
+// ChaosFault is one row of the chaos schedule's probability table.
+type ChaosFault struct {
+	// Kind is ChaosHang, ChaosErrorBurst, or ChaosLatencySpike
+	Kind string
+	// Probability is the chance of triggering this fault on each roll
+	Probability float64
+	// Duration is how long the fault lasts once triggered
+	Duration time.Duration
+	// Rate is the fraction of requests an error burst fails (0 means all)
+	Rate float64
+	// Codes are the status codes an error burst selects from (empty = 500)
+	Codes []int
+	// Delay is the latency a latency spike adds to each request
+	Delay time.Duration
+}
+
+// Validate checks that the fault's kind and parameters are valid.
+func (f ChaosFault) Validate() error {
+	switch f.Kind {
+	case ChaosHang, ChaosErrorBurst, ChaosLatencySpike:
+	default:
+		return fmt.Errorf("kind must be one of: hang, error-burst, latency-spike, got %q", f.Kind)
+	}
+	if f.Probability < 0 || f.Probability > 1 {
+		return errors.New("probability must be between 0 and 1")
+	}
+	if f.Duration <= 0 || f.Duration > MaxChaosDuration {
+		return fmt.Errorf("duration must be positive and at most %s", MaxChaosDuration)
+	}
+	if f.Rate < 0 || f.Rate > 1 {
+		return errors.New("rate must be between 0 and 1")
+	}
+	for _, code := range f.Codes {
+		if code < 100 || code > 599 {
+			return errors.New("codes must be valid HTTP status codes (100-599)")
+		}
+	}
+	if f.Kind == ChaosLatencySpike && (f.Delay <= 0 || f.Delay > MaxChaosDuration) {
+		return fmt.Errorf("delay must be positive and at most %s for latency-spike", MaxChaosDuration)
+	}
+	return nil
+}
+
+// ShouldFail reports whether an error burst fails the current request.
+func (f ChaosFault) ShouldFail() bool {
+	return f.Rate <= 0 || f.Rate >= 1 || rand.Float64() < f.Rate
+}
+
+// SelectCode returns a random status code from the configured codes.
+func (f ChaosFault) SelectCode() int {
+	return (&ErrorConfig{Codes: f.Codes}).SelectCode()
+}
+
+// ChaosWindow is a daily UTC time range during which the schedule may
+// trigger faults. A window whose end is before its start spans midnight.
+type ChaosWindow struct {
+	// Start and End are offsets from midnight UTC
+	Start, End time.Duration
+}
+
+// ParseChaosWindow parses a window of the form "HH:MM-HH:MM".
+func ParseChaosWindow(s string) (ChaosWindow, error) {
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return ChaosWindow{}, fmt.Errorf("window %q must be of the form HH:MM-HH:MM", s)
+	}
+	var w ChaosWindow
+	var err error
+	if w.Start, err = parseClock(start); err != nil {
+		return ChaosWindow{}, fmt.Errorf("window %q: %w", s, err)
+	}
+	if w.End, err = parseClock(end); err != nil {
+		return ChaosWindow{}, fmt.Errorf("window %q: %w", s, err)
+	}
+	if w.Start == w.End {
+		return ChaosWindow{}, fmt.Errorf("window %q is empty", s)
+	}
+	return w, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String formats the window as "HH:MM-HH:MM".
+func (w ChaosWindow) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+// Contains reports whether t falls within the window.
+func (w ChaosWindow) Contains(t time.Time) bool {
+	t = t.UTC()
+	offset := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// ChaosScheduleConfig is a chaos schedule: every Interval, within any of the
+// Windows, one fault is picked from the probability table, or none with the
+// remaining probability.
+type ChaosScheduleConfig struct {
+	// Interval is how often to roll for a fault; zero disables the schedule
+	Interval time.Duration
+	// Windows restrict rolls to daily UTC time ranges (empty = always)
+	Windows []ChaosWindow
+	// Faults is the probability table
+	Faults []ChaosFault
+}
+
+// Enabled reports whether the schedule rolls for faults.
+func (c ChaosScheduleConfig) Enabled() bool {
+	return c.Interval > 0 && len(c.Faults) > 0
+}
+
+// Validate checks the interval and every fault, and that the probabilities
+// sum to at most 1.
+func (c ChaosScheduleConfig) Validate() error {
+	if c.Interval == 0 && len(c.Faults) == 0 && len(c.Windows) == 0 {
+		return nil
+	}
+	if c.Interval < MinChaosInterval {
+		return fmt.Errorf("interval must be at least %s", MinChaosInterval)
+	}
+	if len(c.Faults) == 0 {
+		return errors.New("faults must not be empty")
+	}
+	total := 0.0
+	for i, f := range c.Faults {
+		if err := f.Validate(); err != nil {
+			return fmt.Errorf("faults[%d]: %w", i, err)
+		}
+		total += f.Probability
+	}
+	if total > 1+1e-9 {
+		return fmt.Errorf("fault probabilities must sum to at most 1, got %g", total)
+	}
+	return nil
+}
+
+// ChaosSchedule triggers randomized faults in the background, turning hotpod
+// into a self-contained chaos monkey. Faults take effect through middleware
+// that consults Active. It is safe for concurrent use.
+type ChaosSchedule struct {
+	mu     sync.Mutex
+	cfg    ChaosScheduleConfig
+	next   time.Time
+	active *ChaosFault
+	until  time.Time
+
+	// roll returns a number in [0, 1) to pick from the probability table
+	roll func() float64
+}
+
+// NewChaosSchedule creates a disabled chaos schedule.
+func NewChaosSchedule() *ChaosSchedule {
+	return &ChaosSchedule{roll: rand.Float64}
+}
+
+// Set replaces the schedule and ends any active fault. A zero config
+// disables the schedule.
+func (s *ChaosSchedule) Set(cfg ChaosScheduleConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	s.next = time.Time{}
+	s.end()
+	return nil
+}
+
+// Get returns the schedule.
+func (s *ChaosSchedule) Get() ChaosScheduleConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// Active returns the fault in effect at now and when it ends. It is safe to
+// call on a nil receiver, which never has an active fault.
+func (s *ChaosSchedule) Active(now time.Time) (ChaosFault, time.Time, bool) {
+	if s == nil {
+		return ChaosFault{}, time.Time{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil || !now.Before(s.until) {
+		return ChaosFault{}, time.Time{}, false
+	}
+	return *s.active, s.until, true
+}
+
+// Run rolls for faults until ctx is cancelled.
+func (s *ChaosSchedule) Run(ctx context.Context) {
+	ticker := time.NewTicker(chaosTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.tick(now)
+		}
+	}
+}
+
+// tick ends an expired fault and, when a roll is due within a window, picks
+// the next one. No fault is rolled while another is active.
+func (s *ChaosSchedule) tick(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active != nil {
+		if now.Before(s.until) {
+			return
+		}
+		s.end()
+	}
+	if !s.cfg.Enabled() || now.Before(s.next) {
+		return
+	}
+	s.next = now.Add(s.cfg.Interval)
+	if !s.inWindow(now) {
+		return
+	}
+
+	r := s.roll()
+	for _, f := range s.cfg.Faults {
+		if r -= f.Probability; r < 0 {
+			s.start(f, now)
+			return
+		}
+	}
+}
+
+func (s *ChaosSchedule) inWindow(now time.Time) bool {
+	if len(s.cfg.Windows) == 0 {
+		return true
+	}
+	for _, w := range s.cfg.Windows {
+		if w.Contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// start activates f. The caller must hold s.mu.
+func (s *ChaosSchedule) start(f ChaosFault, now time.Time) {
+	s.active, s.until = &f, now.Add(f.Duration)
+	metrics.FaultChaosTriggeredTotal.WithLabelValues(f.Kind).Inc()
+	metrics.FaultChaosActive.WithLabelValues(f.Kind).Set(1)
+
+	slog.Warn("chaos schedule triggered fault", "kind", f.Kind, "duration", f.Duration)
+	events.Record(slog.LevelWarn, events.TypeFault, "chaos schedule triggered fault", map[string]any{
+		"kind":     f.Kind,
+		"duration": f.Duration.String(),
+	})
+}
+
+// end deactivates the active fault, if any. The caller must hold s.mu.
+func (s *ChaosSchedule) end() {
+	if s.active == nil {
+		return
+	}
+	metrics.FaultChaosActive.WithLabelValues(s.active.Kind).Set(0)
+	slog.Info("chaos schedule fault ended", "kind", s.active.Kind)
+	events.Record(slog.LevelInfo, events.TypeFault, "chaos schedule fault ended", map[string]any{
+		"kind": s.active.Kind,
+	})
+	s.active, s.until = nil, time.Time{}
+}
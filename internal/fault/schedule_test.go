@@ -0,0 +1,141 @@
+package fault
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChaosScheduleConfigValidate(t *testing.T) {
+	hang := ChaosFault{Kind: ChaosHang, Probability: 0.5, Duration: time.Minute}
+	tests := []struct {
+		name    string
+		cfg     ChaosScheduleConfig
+		wantErr bool
+	}{
+		{"zero", ChaosScheduleConfig{}, false},
+		{"valid", ChaosScheduleConfig{Interval: time.Minute, Faults: []ChaosFault{hang}}, false},
+		{"short interval", ChaosScheduleConfig{Interval: time.Millisecond, Faults: []ChaosFault{hang}}, true},
+		{"no faults", ChaosScheduleConfig{Interval: time.Minute}, true},
+		{"unknown kind", ChaosScheduleConfig{Interval: time.Minute, Faults: []ChaosFault{{Kind: "crash", Duration: time.Minute}}}, true},
+		{"no duration", ChaosScheduleConfig{Interval: time.Minute, Faults: []ChaosFault{{Kind: ChaosHang, Probability: 0.1}}}, true},
+		{"spike without delay", ChaosScheduleConfig{Interval: time.Minute, Faults: []ChaosFault{{Kind: ChaosLatencySpike, Probability: 0.1, Duration: time.Minute}}}, true},
+		{"bad code", ChaosScheduleConfig{Interval: time.Minute, Faults: []ChaosFault{{Kind: ChaosErrorBurst, Probability: 0.1, Duration: time.Minute, Codes: []int{700}}}}, true},
+		{"probabilities over 1", ChaosScheduleConfig{Interval: time.Minute, Faults: []ChaosFault{hang, hang, hang}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseChaosWindow(t *testing.T) {
+	tests := []struct {
+		window string
+		time   string
+		want   bool
+	}{
+		{"09:00-17:00", "2024-03-05T09:00:00Z", true},
+		{"09:00-17:00", "2024-03-05T16:59:00Z", true},
+		{"09:00-17:00", "2024-03-05T17:00:00Z", false},
+		{"22:00-02:00", "2024-03-05T23:30:00Z", true},
+		{"22:00-02:00", "2024-03-05T01:00:00Z", true},
+		{"22:00-02:00", "2024-03-05T12:00:00Z", false},
+	}
+	for _, tt := range tests {
+		w, err := ParseChaosWindow(tt.window)
+		if err != nil {
+			t.Fatalf("ParseChaosWindow(%q) error = %v", tt.window, err)
+		}
+		if w.String() != tt.window {
+			t.Errorf("String() = %q, want %q", w.String(), tt.window)
+		}
+		ts, _ := time.Parse(time.RFC3339, tt.time)
+		if got := w.Contains(ts); got != tt.want {
+			t.Errorf("%q contains %s = %v, want %v", tt.window, tt.time, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "09:00", "9-17", "25:00-26:00", "09:00-09:00"} {
+		if _, err := ParseChaosWindow(s); err == nil {
+			t.Errorf("ParseChaosWindow(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestChaosScheduleTick(t *testing.T) {
+	s := NewChaosSchedule()
+	err := s.Set(ChaosScheduleConfig{
+		Interval: time.Minute,
+		Faults: []ChaosFault{
+			{Kind: ChaosHang, Probability: 0.2, Duration: 10 * time.Second},
+			{Kind: ChaosLatencySpike, Probability: 0.3, Duration: 10 * time.Second, Delay: time.Second},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		offset time.Duration
+		roll   float64
+		want   string
+	}{
+		// 0.25 falls past hang's 0.2 into latency-spike's share.
+		{0, 0.25, ChaosLatencySpike},
+		// The fault is still active, and no roll is due.
+		{5 * time.Second, 0.1, ChaosLatencySpike},
+		// The fault has ended, and the next roll is not due until 1m.
+		{30 * time.Second, 0.1, ""},
+		{time.Minute, 0.1, ChaosHang},
+		// 0.9 is past both faults' shares, so nothing triggers.
+		{2 * time.Minute, 0.9, ""},
+	}
+	for _, step := range steps {
+		s.roll = func() float64 { return step.roll }
+		now := start.Add(step.offset)
+		s.tick(now)
+		var got string
+		if f, _, ok := s.Active(now); ok {
+			got = f.Kind
+		}
+		if got != step.want {
+			t.Errorf("at %s: active = %q, want %q", step.offset, got, step.want)
+		}
+	}
+}
+
+func TestChaosScheduleWindows(t *testing.T) {
+	w, _ := ParseChaosWindow("09:00-17:00")
+	s := NewChaosSchedule()
+	s.roll = func() float64 { return 0 }
+	if err := s.Set(ChaosScheduleConfig{
+		Interval: time.Minute,
+		Windows:  []ChaosWindow{w},
+		Faults:   []ChaosFault{{Kind: ChaosHang, Probability: 1, Duration: time.Second}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	night := time.Date(2024, 3, 5, 3, 0, 0, 0, time.UTC)
+	s.tick(night)
+	if _, _, ok := s.Active(night); ok {
+		t.Error("fault triggered outside the window")
+	}
+
+	day := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	s.tick(day)
+	if _, _, ok := s.Active(day); !ok {
+		t.Error("no fault triggered inside the window")
+	}
+
+	if err := s.Set(ChaosScheduleConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := s.Active(day); ok {
+		t.Error("fault still active after the schedule was cleared")
+	}
+}
@@ -0,0 +1,141 @@
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// MaxStormDuration bounds how long a 503 storm lasts.
+const MaxStormDuration = time.Hour
+
+// StormConfig describes a 503 storm: a window during which a fraction of
+// all requests fail with 503 and a Retry-After header, to exercise client
+// retry budgets and retry-storm protections.
+type StormConfig struct {
+	// Rate is the fraction of requests failed (0 means all)
+	Rate float64
+	// RetryAfter are the Retry-After values each response picks from at
+	// random (empty = no header)
+	RetryAfter []time.Duration
+	// HTTPDate sends Retry-After as an HTTP date instead of seconds
+	HTTPDate bool
+	// Duration is how long the storm lasts
+	Duration time.Duration
+}
+
+// Validate checks that the storm's parameters are valid.
+func (c StormConfig) Validate() error {
+	if c.Rate < 0 || c.Rate > 1 {
+		return errors.New("rate must be between 0 and 1")
+	}
+	for _, d := range c.RetryAfter {
+		if d < 0 || d > MaxStormDuration {
+			return fmt.Errorf("retry_after values must be between 0 and %s", MaxStormDuration)
+		}
+	}
+	if c.Duration <= 0 || c.Duration > MaxStormDuration {
+		return fmt.Errorf("duration must be positive and at most %s", MaxStormDuration)
+	}
+	return nil
+}
+
+// Storm holds the 503 storm in effect, if any. It takes effect through
+// middleware that consults Reject. It is safe for concurrent use.
+type Storm struct {
+	mu       sync.Mutex
+	cfg      StormConfig
+	until    time.Time
+	rejected int64
+}
+
+// NewStorm creates a storm holder with no storm in effect.
+func NewStorm() *Storm {
+	return &Storm{}
+}
+
+// Start begins a storm at now, replacing any storm in effect.
+func (s *Storm) Start(cfg StormConfig, now time.Time) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.cfg = cfg
+	s.until = now.Add(cfg.Duration)
+	s.rejected = 0
+	s.mu.Unlock()
+
+	slog.Warn("503 storm started", "rate", cfg.Rate, "retry_after", cfg.RetryAfter, "duration", cfg.Duration)
+	events.Record(slog.LevelWarn, events.TypeFault, "503 storm started", map[string]any{
+		"rate":      cfg.Rate,
+		"duration":  cfg.Duration.String(),
+		"http_date": cfg.HTTPDate,
+	})
+	return nil
+}
+
+// Stop ends the storm in effect, if any.
+func (s *Storm) Stop() {
+	s.mu.Lock()
+	s.until = time.Time{}
+	s.mu.Unlock()
+
+	slog.Info("503 storm stopped")
+	events.Record(slog.LevelInfo, events.TypeFault, "503 storm stopped", nil)
+}
+
+// Reject reports whether a request arriving at now is failed by the storm,
+// and if so, the Retry-After header to send, which may be empty. It is safe
+// to call on a nil receiver, which never rejects.
+func (s *Storm) Reject(now time.Time) (retryAfter string, reject bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !now.Before(s.until) {
+		return "", false
+	}
+	if s.cfg.Rate > 0 && s.cfg.Rate < 1 && rand.Float64() >= s.cfg.Rate {
+		return "", false
+	}
+	s.rejected++
+
+	if len(s.cfg.RetryAfter) == 0 {
+		return "", true
+	}
+	d := s.cfg.RetryAfter[rand.IntN(len(s.cfg.RetryAfter))]
+	if s.cfg.HTTPDate {
+		return now.Add(d).UTC().Format(http.TimeFormat), true
+	}
+	return strconv.Itoa(int(math.Ceil(d.Seconds()))), true
+}
+
+// Status returns the storm in effect at now.
+func (s *Storm) Status(now time.Time) api.StormStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := api.StormStatus{Rejected: s.rejected}
+	if !now.Before(s.until) {
+		return status
+	}
+	until := s.until
+	status.Active = true
+	status.Rate = s.cfg.Rate
+	status.HTTPDate = s.cfg.HTTPDate
+	status.Until = &until
+	for _, d := range s.cfg.RetryAfter {
+		status.RetryAfter = append(status.RetryAfter, d.String())
+	}
+	return status
+}
@@ -0,0 +1,74 @@
+package fault
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStormConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		cfg     StormConfig
+		wantErr bool
+	}{
+		{"all", StormConfig{Duration: time.Minute}, false},
+		{"rate", StormConfig{Rate: 0.5, RetryAfter: []time.Duration{time.Second, 5 * time.Second}, Duration: time.Minute}, false},
+		{"negative rate", StormConfig{Rate: -0.1, Duration: time.Minute}, true},
+		{"rate above one", StormConfig{Rate: 1.1, Duration: time.Minute}, true},
+		{"negative retry after", StormConfig{RetryAfter: []time.Duration{-time.Second}, Duration: time.Minute}, true},
+		{"no duration", StormConfig{}, true},
+		{"long duration", StormConfig{Duration: 2 * MaxStormDuration}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStorm(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := NewStorm()
+	if _, reject := s.Reject(now); reject {
+		t.Error("idle storm rejected a request")
+	}
+
+	if err := s.Start(StormConfig{RetryAfter: []time.Duration{1500 * time.Millisecond}, Duration: time.Minute}, now); err != nil {
+		t.Fatal(err)
+	}
+	retryAfter, reject := s.Reject(now)
+	if !reject || retryAfter != "2" {
+		t.Errorf("Reject() = %q, %t, want \"2\", true", retryAfter, reject)
+	}
+	if st := s.Status(now); !st.Active || st.Rejected != 1 || len(st.RetryAfter) != 1 {
+		t.Errorf("Status() = %+v, want active with 1 rejection", st)
+	}
+	if _, reject := s.Reject(now.Add(time.Minute)); reject {
+		t.Error("storm rejected a request after it ended")
+	}
+
+	if err := s.Start(StormConfig{RetryAfter: []time.Duration{time.Minute}, HTTPDate: true, Duration: time.Minute}, now); err != nil {
+		t.Fatal(err)
+	}
+	retryAfter, _ = s.Reject(now)
+	if want := now.Add(time.Minute).Format(http.TimeFormat); retryAfter != want {
+		t.Errorf("Retry-After = %q, want %q", retryAfter, want)
+	}
+
+	s.Stop()
+	if _, reject := s.Reject(now); reject {
+		t.Error("stopped storm rejected a request")
+	}
+	if st := s.Status(now); st.Active {
+		t.Errorf("Status() = %+v, want inactive", st)
+	}
+}
+
+func TestStormNil(t *testing.T) {
+	var s *Storm
+	if _, reject := s.Reject(time.Now()); reject {
+		t.Error("nil storm rejected a request")
+	}
+}
@@ -0,0 +1,110 @@
+package fault
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+)
+
+// MaxThreads bounds the OS threads held at once. The Go runtime aborts the
+// process when it exceeds 10000 threads, so this stays safely below.
+const MaxThreads = 8000
+
+// threadMu protects threadRelease and threadsHeld.
+var threadMu sync.Mutex
+
+// threadRelease is closed to release all held threads.
+var threadRelease = make(chan struct{})
+
+// threadsHeld is the number of goroutines currently pinning an OS thread.
+var threadsHeld int
+
+// Threads pins count goroutines to their own OS threads and parks them for
+// duration (0 = until ReleaseThreads). A parked locked goroutine keeps its
+// thread, so the runtime must create a new one for each. If a container
+// thread or PID limit is hit, the Go runtime cannot create a thread and
+// aborts the process, which is the failure this fault exists to provoke.
+func Threads(count int, duration time.Duration) (int, error) {
+	threadMu.Lock()
+	if threadsHeld+count > MaxThreads {
+		held := threadsHeld
+		threadMu.Unlock()
+		return 0, fmt.Errorf("at most %d threads may be held at once, %d already held", MaxThreads, held)
+	}
+	threadsHeld += count
+	release := threadRelease
+	threadMu.Unlock()
+
+	slog.Warn("thread explosion started", "count", count, "duration", duration)
+	events.Record(slog.LevelWarn, events.TypeFault, "thread explosion started", map[string]any{
+		"count":    count,
+		"duration": duration.String(),
+	})
+
+	// expired is closed rather than sent on so every goroutine observes it.
+	expired := make(chan struct{})
+	if duration > 0 {
+		time.AfterFunc(duration, func() { close(expired) })
+	}
+
+	var locked sync.WaitGroup
+	locked.Add(count)
+	for range count {
+		go func() {
+			// Exiting without UnlockOSThread terminates the thread rather than
+			// returning it to the runtime's pool.
+			runtime.LockOSThread()
+			locked.Done()
+
+			select {
+			case <-release:
+			case <-expired:
+			}
+
+			threadMu.Lock()
+			threadsHeld--
+			metrics.FaultThreadsHeld.Set(float64(threadsHeld))
+			threadMu.Unlock()
+		}()
+	}
+	locked.Wait()
+
+	metrics.FaultThreadsHeld.Set(float64(ThreadsHeld()))
+	slog.Info("threads pinned", "held", ThreadsHeld(), "threads_created", ThreadsCreated())
+	return count, nil
+}
+
+// ReleaseThreads unparks all held goroutines, letting their threads exit,
+// and returns how many were released.
+func ReleaseThreads() int {
+	threadMu.Lock()
+	defer threadMu.Unlock()
+
+	n := threadsHeld
+	close(threadRelease)
+	threadRelease = make(chan struct{})
+
+	if n > 0 {
+		slog.Info("thread explosion released", "count", n)
+		events.Record(slog.LevelInfo, events.TypeFault, "thread explosion released", map[string]any{"count": n})
+	}
+	return n
+}
+
+// ThreadsHeld returns the number of goroutines currently pinning an OS thread.
+func ThreadsHeld() int {
+	threadMu.Lock()
+	defer threadMu.Unlock()
+	return threadsHeld
+}
+
+// ThreadsCreated returns the number of OS threads the runtime has created.
+func ThreadsCreated() int {
+	return pprof.Lookup("threadcreate").Count()
+}
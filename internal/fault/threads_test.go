@@ -0,0 +1,48 @@
+package fault
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThreadsHoldAndRelease(t *testing.T) {
+	started, err := Threads(50, 0)
+	if err != nil {
+		t.Fatalf("Threads: %v", err)
+	}
+	if started != 50 || ThreadsHeld() != 50 {
+		t.Fatalf("started = %d, held = %d, want 50", started, ThreadsHeld())
+	}
+	if got := ThreadsCreated(); got < 50 {
+		t.Errorf("ThreadsCreated() = %d, want at least 50", got)
+	}
+
+	if n := ReleaseThreads(); n != 50 {
+		t.Errorf("ReleaseThreads() = %d, want 50", n)
+	}
+	waitThreadsHeld(t, 0)
+}
+
+func TestThreadsExpire(t *testing.T) {
+	if _, err := Threads(5, 20*time.Millisecond); err != nil {
+		t.Fatalf("Threads: %v", err)
+	}
+	waitThreadsHeld(t, 0)
+}
+
+func TestThreadsLimit(t *testing.T) {
+	if _, err := Threads(MaxThreads+1, time.Millisecond); err == nil {
+		t.Error("expected error above MaxThreads")
+	}
+}
+
+func waitThreadsHeld(t *testing.T, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for ThreadsHeld() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("ThreadsHeld() = %d, want %d", ThreadsHeld(), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,193 @@
+package fault
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+)
+
+// Bad certificates a TLS listener can present instead of its own.
+const (
+	// TLSCertExpired is a certificate whose validity ended a day ago.
+	TLSCertExpired = "expired"
+	// TLSCertWrongSAN is a certificate for a name no client connects to.
+	TLSCertWrongSAN = "wrong-san"
+)
+
+// MaxTLSHandshakeDelay bounds the injected handshake delay.
+const MaxTLSHandshakeDelay = 5 * time.Minute
+
+// wrongSANHost is the only name on the TLSCertWrongSAN certificate.
+const wrongSANHost = "wrong-san.hotpod.invalid"
+
+// TLSConfig holds the TLS handshake faults.
+type TLSConfig struct {
+	// DelayRate is the probability of delaying a handshake (0.0 to 1.0)
+	DelayRate float64
+	// Delay is how long delayed handshakes stall before continuing
+	Delay time.Duration
+	// FailRate is the probability of aborting a handshake (0.0 to 1.0)
+	FailRate float64
+	// Cert is TLSCertExpired or TLSCertWrongSAN to present a bad certificate
+	// on every handshake (empty = the real certificate)
+	Cert string
+}
+
+// Validate checks that rates, delay, and certificate are valid.
+func (c TLSConfig) Validate() error {
+	if c.DelayRate < 0 || c.DelayRate > 1 || c.FailRate < 0 || c.FailRate > 1 {
+		return errors.New("delay_rate and fail_rate must be between 0 and 1")
+	}
+	if c.Delay < 0 || c.Delay > MaxTLSHandshakeDelay {
+		return fmt.Errorf("delay must be between 0 and %s", MaxTLSHandshakeDelay)
+	}
+	if c.DelayRate > 0 && c.Delay == 0 {
+		return errors.New("delay is required with delay_rate")
+	}
+	switch c.Cert {
+	case "", TLSCertExpired, TLSCertWrongSAN:
+	default:
+		return fmt.Errorf("cert must be one of: expired, wrong-san, got %q", c.Cert)
+	}
+	return nil
+}
+
+// TLSFaults serves a certificate and injects faults into TLS handshakes. It
+// is safe for concurrent use.
+type TLSFaults struct {
+	cert tls.Certificate
+
+	mu  sync.RWMutex
+	cfg TLSConfig
+
+	// bad caches generated bad certificates by kind
+	badMu sync.Mutex
+	bad   map[string]*tls.Certificate
+}
+
+// NewTLSFaults creates handshake faults for a listener serving cert, with no
+// faults enabled.
+func NewTLSFaults(cert tls.Certificate) *TLSFaults {
+	return &TLSFaults{cert: cert, bad: map[string]*tls.Certificate{}}
+}
+
+// Set replaces the handshake faults. A zero config disables them.
+func (f *TLSFaults) Set(cfg TLSConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cfg = cfg
+	return nil
+}
+
+// Get returns the handshake faults.
+func (f *TLSFaults) Get() TLSConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.cfg
+}
+
+// ServerConfig returns a TLS server configuration that applies the faults.
+func (f *TLSFaults) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetCertificate:     f.getCertificate,
+		GetConfigForClient: f.onClientHello,
+	}
+}
+
+// onClientHello runs once per handshake, after the ClientHello is read, to
+// stall or abort it.
+func (f *TLSFaults) onClientHello(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+	cfg := f.Get()
+	if cfg.FailRate > 0 && mrand.Float64() < cfg.FailRate {
+		metrics.FaultTLSHandshakeFaultsTotal.WithLabelValues("fail").Inc()
+		return nil, errors.New("injected TLS handshake failure")
+	}
+	if cfg.DelayRate > 0 && mrand.Float64() < cfg.DelayRate {
+		metrics.FaultTLSHandshakeFaultsTotal.WithLabelValues("delay").Inc()
+		t := time.NewTimer(cfg.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-hello.Context().Done():
+			return nil, hello.Context().Err()
+		}
+	}
+	// A nil config continues the handshake with ServerConfig.
+	return nil, nil
+}
+
+func (f *TLSFaults) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	kind := f.Get().Cert
+	if kind == "" {
+		return &f.cert, nil
+	}
+	metrics.FaultTLSHandshakeFaultsTotal.WithLabelValues("cert_" + kind).Inc()
+	return f.badCertificate(kind)
+}
+
+// badCertificate returns the certificate for kind, generating it once.
+func (f *TLSFaults) badCertificate(kind string) (*tls.Certificate, error) {
+	f.badMu.Lock()
+	defer f.badMu.Unlock()
+	if cert, ok := f.bad[kind]; ok {
+		return cert, nil
+	}
+
+	now := time.Now()
+	var cert tls.Certificate
+	var err error
+	switch kind {
+	case TLSCertExpired:
+		cert, err = SelfSignedCertificate([]string{"localhost"}, now.Add(-30*24*time.Hour), now.Add(-24*time.Hour))
+	case TLSCertWrongSAN:
+		cert, err = SelfSignedCertificate([]string{wrongSANHost}, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.bad[kind] = &cert
+	return &cert, nil
+}
+
+// SelfSignedCertificate generates a throwaway serving certificate for hosts,
+// valid from notBefore to notAfter.
+func SelfSignedCertificate(hosts []string, notBefore, notAfter time.Time) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
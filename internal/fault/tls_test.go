@@ -0,0 +1,114 @@
+package fault
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestTLSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     TLSConfig
+		wantErr bool
+	}{
+		{"zero", TLSConfig{}, false},
+		{"delay", TLSConfig{DelayRate: 0.5, Delay: time.Second}, false},
+		{"fail and cert", TLSConfig{FailRate: 0.1, Cert: TLSCertExpired}, false},
+		{"delay rate without delay", TLSConfig{DelayRate: 0.5}, true},
+		{"rate too high", TLSConfig{FailRate: 1.5}, true},
+		{"delay too long", TLSConfig{DelayRate: 1, Delay: time.Hour}, true},
+		{"unknown cert", TLSConfig{Cert: "revoked"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// handshake connects to a TLS listener using f and returns the connection
+// state, including the server's certificate.
+func handshake(t *testing.T, f *TLSFaults) (*tls.ConnectionState, error) {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", f.ServerConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{InsecureSkipVerify: true, ServerName: "localhost"})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	state := conn.ConnectionState()
+	return &state, nil
+}
+
+func TestTLSFaults(t *testing.T) {
+	now := time.Now()
+	cert, err := SelfSignedCertificate([]string{"localhost"}, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewTLSFaults(cert)
+
+	if state, err := handshake(t, f); err != nil {
+		t.Fatalf("handshake without faults: %v", err)
+	} else if state.PeerCertificates[0].VerifyHostname("localhost") != nil {
+		t.Error("real certificate should be valid for localhost")
+	}
+
+	f.Set(TLSConfig{FailRate: 1})
+	if _, err := handshake(t, f); err == nil {
+		t.Error("handshake succeeded, want injected failure")
+	}
+
+	f.Set(TLSConfig{DelayRate: 1, Delay: 50 * time.Millisecond})
+	start := time.Now()
+	if _, err := handshake(t, f); err != nil {
+		t.Fatalf("delayed handshake: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("handshake took %v, want at least 50ms", elapsed)
+	}
+
+	f.Set(TLSConfig{Cert: TLSCertExpired})
+	if state, err := handshake(t, f); err != nil {
+		t.Fatalf("expired cert handshake: %v", err)
+	} else if !state.PeerCertificates[0].NotAfter.Before(time.Now()) {
+		t.Errorf("certificate NotAfter = %v, want in the past", state.PeerCertificates[0].NotAfter)
+	}
+
+	f.Set(TLSConfig{Cert: TLSCertWrongSAN})
+	if state, err := handshake(t, f); err != nil {
+		t.Fatalf("wrong SAN handshake: %v", err)
+	} else if state.PeerCertificates[0].VerifyHostname("localhost") == nil {
+		t.Error("wrong SAN certificate should not be valid for localhost")
+	}
+}
+
+func TestSelfSignedCertificate(t *testing.T) {
+	now := time.Now()
+	cert, err := SelfSignedCertificate([]string{"example.test", "other.test"}, now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.VerifyHostname("other.test") != nil {
+		t.Errorf("DNS names = %v, want other.test", leaf.DNSNames)
+	}
+}
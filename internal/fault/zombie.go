@@ -0,0 +1,118 @@
+package fault
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+)
+
+// zombieChildEnv marks a re-executed hotpod binary as a zombie child that
+// should exit immediately.
+const zombieChildEnv = "HOTPOD_ZOMBIE_CHILD"
+
+// MaxZombies bounds the number of unreaped children held at once.
+const MaxZombies = 10000
+
+// zombieMu protects zombies.
+var zombieMu sync.Mutex
+
+// zombies holds exited children that are intentionally never waited on, so
+// they remain in the process table as defunct entries.
+var zombies []*os.Process
+
+// RunZombieChild exits the process immediately if it was started as a zombie
+// child. It must be called at the very start of main.
+func RunZombieChild() {
+	if os.Getenv(zombieChildEnv) == "1" {
+		os.Exit(0)
+	}
+}
+
+// Zombies starts count child processes that exit immediately and are never
+// reaped, leaving them defunct until Reap is called or the process exits. An
+// init process such as tini reaps them only after hotpod itself exits, since
+// they remain hotpod's children. It returns the number created, which may be
+// short of count if a PID limit is reached.
+func Zombies(count int) (int, error) {
+	zombieMu.Lock()
+	defer zombieMu.Unlock()
+
+	if len(zombies)+count > MaxZombies {
+		return 0, fmt.Errorf("at most %d zombies may exist at once, %d already exist", MaxZombies, len(zombies))
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("locating executable: %w", err)
+	}
+
+	attr := &os.ProcAttr{
+		Env:   append(os.Environ(), zombieChildEnv+"=1"),
+		Files: []*os.File{nil, nil, nil},
+	}
+
+	created := 0
+	for created < count {
+		p, err := os.StartProcess(exe, []string{exe}, attr)
+		if err != nil {
+			err = fmt.Errorf("starting child %d: %w", created+1, err)
+			slog.Warn("zombie creation stopped early", "created", created, "requested", count, "error", err)
+			recordZombies(created, count, err)
+			return created, err
+		}
+		zombies = append(zombies, p)
+		created++
+	}
+
+	recordZombies(created, count, nil)
+	return created, nil
+}
+
+func recordZombies(created, requested int, err error) {
+	metrics.FaultZombies.Set(float64(len(zombies)))
+	attrs := map[string]any{
+		"created":   created,
+		"requested": requested,
+		"total":     len(zombies),
+	}
+	if err != nil {
+		attrs["error"] = err.Error()
+	}
+	slog.Warn("zombie processes created", "created", created, "total", len(zombies))
+	events.Record(slog.LevelWarn, events.TypeFault, "zombie processes created", attrs)
+}
+
+// ZombieCount returns the number of unreaped children.
+func ZombieCount() int {
+	zombieMu.Lock()
+	defer zombieMu.Unlock()
+	return len(zombies)
+}
+
+// Reap waits on all zombie children, removing them from the process table,
+// and returns how many were reaped.
+func Reap() int {
+	zombieMu.Lock()
+	defer zombieMu.Unlock()
+
+	n := 0
+	for _, p := range zombies {
+		if _, err := p.Wait(); err != nil {
+			slog.Warn("failed to reap zombie", "pid", p.Pid, "error", err)
+			continue
+		}
+		n++
+	}
+	zombies = nil
+	metrics.FaultZombies.Set(0)
+
+	if n > 0 {
+		slog.Info("zombie processes reaped", "count", n)
+		events.Record(slog.LevelInfo, events.TypeFault, "zombie processes reaped", map[string]any{"count": n})
+	}
+	return n
+}
@@ -0,0 +1,62 @@
+package fault
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain lets the test binary act as its own zombie child.
+func TestMain(m *testing.M) {
+	RunZombieChild()
+	os.Exit(m.Run())
+}
+
+func TestZombies(t *testing.T) {
+	created, err := Zombies(3)
+	if err != nil {
+		t.Fatalf("Zombies: %v", err)
+	}
+	if created != 3 || ZombieCount() != 3 {
+		t.Fatalf("created = %d, count = %d, want 3", created, ZombieCount())
+	}
+
+	if runtime.GOOS == "linux" {
+		zombieMu.Lock()
+		pid := zombies[0].Pid
+		zombieMu.Unlock()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+			if err != nil {
+				t.Fatalf("reading child stat: %v", err)
+			}
+			// The state follows the parenthesized command name.
+			fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+			if fields[0] == "Z" {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("child state = %s, want Z", fields[0])
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	if n := Reap(); n != 3 {
+		t.Errorf("Reap() = %d, want 3", n)
+	}
+	if ZombieCount() != 0 {
+		t.Errorf("count after reap = %d, want 0", ZombieCount())
+	}
+}
+
+func TestZombiesLimit(t *testing.T) {
+	if _, err := Zombies(MaxZombies + 1); err == nil {
+		t.Error("expected error above MaxZombies")
+	}
+}
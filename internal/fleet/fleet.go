@@ -0,0 +1,213 @@
+// Package fleet discovers peer hotpod pods and fans admin commands out to
+// them, so multi-replica experiments can be driven with a single call.
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Peer is a discovered hotpod instance.
+type Peer = api.FleetPeer
+
+// Result is the outcome of sending a command to one peer.
+type Result = api.FleetResult
+
+// Discoverer finds peers.
+type Discoverer interface {
+	Peers(ctx context.Context) ([]Peer, error)
+}
+
+// DNSDiscoverer resolves a headless Service name to pod IPs.
+type DNSDiscoverer struct {
+	Host     string
+	Port     int
+	Resolver *net.Resolver
+}
+
+// Peers implements Discoverer.
+func (d *DNSDiscoverer) Peers(ctx context.Context) ([]Peer, error) {
+	r := d.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+
+	addrs, err := r.LookupHost(ctx, d.Host)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", d.Host, err)
+	}
+	sort.Strings(addrs)
+
+	peers := make([]Peer, len(addrs))
+	for i, a := range addrs {
+		addr := net.JoinHostPort(a, strconv.Itoa(d.Port))
+		peers[i] = Peer{Name: addr, Addr: addr}
+	}
+	return peers, nil
+}
+
+// KubeDiscoverer lists running pods matching a label selector.
+type KubeDiscoverer struct {
+	Client    *kube.Client
+	Namespace string
+	Selector  string
+	Port      int
+}
+
+// Peers implements Discoverer.
+func (d *KubeDiscoverer) Peers(ctx context.Context) ([]Peer, error) {
+	var pods struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Status struct {
+				Phase string `json:"phase"`
+				PodIP string `json:"podIP"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods?labelSelector=%s", d.Namespace, url.QueryEscape(d.Selector))
+	if err := d.Client.Do(ctx, http.MethodGet, path, nil, &pods); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+
+	var peers []Peer
+	for _, p := range pods.Items {
+		if p.Status.Phase != "Running" || p.Status.PodIP == "" {
+			continue
+		}
+		peers = append(peers, Peer{Name: p.Metadata.Name, Addr: net.JoinHostPort(p.Status.PodIP, strconv.Itoa(d.Port))})
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Name < peers[j].Name })
+	return peers, nil
+}
+
+// Command is an HTTP request to send to every peer.
+type Command api.FleetCommand
+
+// Validate checks that the command can be broadcast. Broadcasting a broadcast
+// is rejected to avoid fan-out loops, under any version prefix.
+func (c *Command) Validate() error {
+	if c.Method == "" {
+		c.Method = http.MethodPost
+	}
+	c.Method = strings.ToUpper(c.Method)
+	if !strings.HasPrefix(c.Path, "/") {
+		return errors.New("path must be absolute")
+	}
+	_, unversioned := api.SplitVersion(c.Path)
+	if strings.HasPrefix(path.Clean(unversioned), "/admin/broadcast") {
+		return errors.New("cannot broadcast a broadcast")
+	}
+	return nil
+}
+
+// maxResultBody bounds the response body kept per peer.
+const maxResultBody = 2 << 10
+
+// maxConcurrency bounds simultaneous peer requests.
+const maxConcurrency = 16
+
+// Broadcaster sends commands to peers.
+type Broadcaster struct {
+	Client *http.Client
+	// Scheme is the peers' URL scheme, "http" or "https"
+	Scheme string
+}
+
+// NewBroadcaster creates a broadcaster with a per-request timeout, sending
+// to peers over scheme.
+func NewBroadcaster(timeout time.Duration, scheme string) *Broadcaster {
+	return &Broadcaster{Client: NewClient(timeout, scheme), Scheme: scheme}
+}
+
+// NewClient returns a client for requests to peers over scheme with a
+// per-request timeout. Peer certificates are not verified over https, since
+// they are usually self-signed.
+func NewClient(timeout time.Duration, scheme string) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if scheme == "https" {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	return client
+}
+
+// Broadcast sends cmd to every peer concurrently, copying the given headers
+// (typically credentials) onto each request. Results are in peer order.
+func (b *Broadcaster) Broadcast(ctx context.Context, peers []Peer, cmd Command, header http.Header) []Result {
+	results := make([]Result, len(peers))
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+
+	for i, p := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = b.send(ctx, p, cmd, header)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (b *Broadcaster) send(ctx context.Context, p Peer, cmd Command, header http.Header) Result {
+	res := Result{Peer: p.Name, Addr: p.Addr}
+
+	target := b.Scheme + "://" + p.Addr + cmd.Path
+	if len(cmd.Params) > 0 {
+		q := url.Values{}
+		for k, v := range cmd.Params {
+			q.Set(k, v)
+		}
+		target += "?" + q.Encode()
+	}
+
+	var body io.Reader
+	if cmd.Body != "" {
+		body = strings.NewReader(cmd.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, cmd.Method, target, body)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+
+	res.Status = resp.StatusCode
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResultBody))
+	res.Body = string(bytes.TrimSpace(data))
+	return res
+}
@@ -0,0 +1,111 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/kube"
+)
+
+func TestBroadcast(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Admin-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/admin/error-rate" || r.URL.Query().Get("rate") != "0.2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ok.Close()
+
+	peers := []Peer{
+		{Name: "a", Addr: strings.TrimPrefix(ok.URL, "http://")},
+		{Name: "dead", Addr: "127.0.0.1:1"},
+	}
+	cmd := Command{Path: "/admin/error-rate", Params: map[string]string{"rate": "0.2"}}
+	if err := cmd.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	header := http.Header{"X-Admin-Token": {"secret"}}
+	results := NewBroadcaster(time.Second, "http").Broadcast(context.Background(), peers, cmd, header)
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].Status != http.StatusOK || results[0].Body != `{"ok":true}` {
+		t.Errorf("result[0] = %+v", results[0])
+	}
+	if results[1].Error == "" {
+		t.Errorf("result[1] should have an error: %+v", results[1])
+	}
+}
+
+func TestBroadcastTLS(t *testing.T) {
+	peer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer peer.Close()
+
+	peers := []Peer{{Name: "a", Addr: strings.TrimPrefix(peer.URL, "https://")}}
+	cmd := Command{Path: "/admin/ready"}
+	if err := cmd.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	results := NewBroadcaster(time.Second, "https").Broadcast(context.Background(), peers, cmd, nil)
+	if results[0].Status != http.StatusOK {
+		t.Errorf("result = %+v, want 200 from a self-signed peer", results[0])
+	}
+}
+
+func TestCommandValidate(t *testing.T) {
+	for _, cmd := range []Command{
+		{Path: "admin/ready"},
+		{Path: "/admin/broadcast"},
+		{Path: "/v1/admin/broadcast"},
+		{Path: "/v2/admin/broadcast?x=1"},
+		{Path: "/admin/../admin/broadcast"},
+	} {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should error", cmd)
+		}
+	}
+
+	cmd := Command{Method: "delete", Path: "/v1/admin/replay"}
+	if err := cmd.Validate(); err != nil || cmd.Method != "DELETE" {
+		t.Errorf("Validate() = %v, method = %q", err, cmd.Method)
+	}
+}
+
+func TestKubeDiscoverer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("labelSelector") != "app=hotpod" {
+			http.Error(w, "bad selector", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{
+			{"metadata": map[string]string{"name": "b"}, "status": map[string]string{"phase": "Running", "podIP": "10.0.0.2"}},
+			{"metadata": map[string]string{"name": "a"}, "status": map[string]string{"phase": "Running", "podIP": "10.0.0.1"}},
+			{"metadata": map[string]string{"name": "c"}, "status": map[string]string{"phase": "Pending"}},
+		}})
+	}))
+	defer srv.Close()
+
+	d := &KubeDiscoverer{Client: kube.NewForTesting(srv.URL, "ns"), Namespace: "ns", Selector: "app=hotpod", Port: 8080}
+	peers, err := d.Peers(context.Background())
+	if err != nil {
+		t.Fatalf("Peers() error = %v", err)
+	}
+	if len(peers) != 2 || peers[0].Name != "a" || peers[0].Addr != "10.0.0.1:8080" {
+		t.Errorf("peers = %+v", peers)
+	}
+}
@@ -1,25 +1,35 @@
 package handlers
 
 import (
-	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"mime"
 	"net/http"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ripta/hotpod/internal/audit"
+	"github.com/ripta/hotpod/internal/auth"
 	"github.com/ripta/hotpod/internal/config"
+	"github.com/ripta/hotpod/internal/events"
 	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/logging"
 	"github.com/ripta/hotpod/internal/queue"
 	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 // AdminHandlers provides admin endpoint handlers for runtime configuration.
 type AdminHandlers struct {
-	// token is the authentication token (empty = open access)
-	token string
+	// authn validates admin credentials (no tokens = open access)
+	authn *auth.Authenticator
 	// lifecycle is the server lifecycle manager
 	lifecycle *server.Lifecycle
 	// injector is the fault injection manager
@@ -30,17 +40,20 @@ type AdminHandlers struct {
 	queue *queue.Queue
 	// workerPool is the queue worker pool (nil in sidecar mode)
 	workerPool *queue.WorkerPool
+	// auditLog records admin mutations
+	auditLog *audit.Log
 }
 
 // NewAdminHandlers creates handlers for admin endpoints.
-func NewAdminHandlers(token string, lc *server.Lifecycle, injector *fault.Injector, cfg *config.Config, q *queue.Queue, wp *queue.WorkerPool) *AdminHandlers {
+func NewAdminHandlers(authn *auth.Authenticator, lc *server.Lifecycle, injector *fault.Injector, cfg *config.Config, q *queue.Queue, wp *queue.WorkerPool, auditLog *audit.Log) *AdminHandlers {
 	return &AdminHandlers{
-		token:      token,
+		authn:      authn,
 		lifecycle:  lc,
 		injector:   injector,
 		cfg:        cfg,
 		queue:      q,
 		workerPool: wp,
+		auditLog:   auditLog,
 	}
 }
 
@@ -51,30 +64,47 @@ func (h *AdminHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /admin/config", h.Config)
 	mux.HandleFunc("POST /admin/reset", h.Reset)
 	mux.HandleFunc("POST /admin/error-rate", h.ErrorRate)
+	mux.HandleFunc("GET /admin/faults", h.Faults)
+	mux.HandleFunc("POST /admin/faults", h.SetFaults)
+	mux.HandleFunc("DELETE /admin/faults", h.ClearFaults)
+	mux.HandleFunc("GET /admin/header-faults", h.HeaderFaults)
+	mux.HandleFunc("POST /admin/header-faults", h.SetHeaderFault)
+	mux.HandleFunc("DELETE /admin/header-faults", h.ClearHeaderFaults)
 	mux.HandleFunc("POST /admin/queue/pause", h.QueuePause)
 	mux.HandleFunc("POST /admin/queue/resume", h.QueueResume)
+	mux.HandleFunc("GET /admin/queue/fault", h.QueueFault)
+	mux.HandleFunc("POST /admin/queue/fault", h.SetQueueFault)
+	mux.HandleFunc("DELETE /admin/queue/fault", h.ClearQueueFault)
+	mux.HandleFunc("GET /admin/audit", h.Audit)
+	mux.HandleFunc("GET /admin/drain-status", h.DrainStatus)
+	mux.HandleFunc("GET /admin/loglevel", h.LogLevel)
+	mux.HandleFunc("POST /admin/loglevel", h.SetLogLevel)
+	mux.HandleFunc("GET /admin/exit-code", h.ExitCode)
+	mux.HandleFunc("POST /admin/exit-code", h.SetExitCode)
+	mux.HandleFunc("DELETE /admin/exit-code", h.ClearExitCode)
+	mux.HandleFunc("GET /admin/clock", h.Clock)
+	mux.HandleFunc("POST /admin/clock", h.SetClock)
+	mux.HandleFunc("DELETE /admin/clock", h.ClearClock)
 }
 
-func (h *AdminHandlers) authenticate(w http.ResponseWriter, r *http.Request) bool {
-	if h.token == "" {
-		return true
-	}
-	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(h.token)) == 1 {
+// authorize checks that the caller holds the required role, writing a 401 or
+// 403 response and returning false if not.
+func authorize(authn *auth.Authenticator, w http.ResponseWriter, r *http.Request, required auth.Role) bool {
+	_, err := authn.Authorize(r, required)
+	switch {
+	case err == nil:
 		return true
+	case errors.Is(err, auth.ErrForbidden):
+		writeError(w, http.StatusForbidden, errcode.Forbidden, "admin token does not have the "+string(required)+" role")
+	default:
+		slog.Debug("admin authentication failed", "path", r.URL.Path, "error", err)
+		writeError(w, http.StatusUnauthorized, errcode.Unauthorized, "invalid or missing admin token")
 	}
-	writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid or missing admin token")
 	return false
 }
 
-// AdminReadyResponse is the JSON response for POST /admin/ready.
-type AdminReadyResponse struct {
-	Ready    bool   `json:"ready"`
-	Override *bool  `json:"override"`
-	State    string `json:"state"`
-}
-
 func (h *AdminHandlers) Ready(w http.ResponseWriter, r *http.Request) {
-	if !h.authenticate(w, r) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
 		return
 	}
 
@@ -96,11 +126,15 @@ func (h *AdminHandlers) Ready(w http.ResponseWriter, r *http.Request) {
 			h.lifecycle.SetReadyOverride(&v)
 		}
 	default:
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "state must be true, false, or empty")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "state must be true, false, or empty")
 		return
 	}
 
-	resp := AdminReadyResponse{
+	events.Record(slog.LevelInfo, events.TypeAdmin, "readiness override changed", map[string]any{
+		"override": h.lifecycle.ReadyOverride(),
+	})
+
+	resp := api.AdminReadyResponse{
 		Ready:    h.lifecycle.IsReady(),
 		Override: h.lifecycle.ReadyOverride(),
 		State:    h.lifecycle.State().String(),
@@ -112,21 +146,8 @@ func (h *AdminHandlers) Ready(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// AdminGCMemStats holds memory stats for the GC response.
-type AdminGCMemStats struct {
-	Alloc uint64 `json:"alloc"`
-	Sys   uint64 `json:"sys"`
-	NumGC uint32 `json:"num_gc"`
-}
-
-// AdminGCResponse is the JSON response for POST /admin/gc.
-type AdminGCResponse struct {
-	Before AdminGCMemStats `json:"before"`
-	After  AdminGCMemStats `json:"after"`
-}
-
 func (h *AdminHandlers) GC(w http.ResponseWriter, r *http.Request) {
-	if !h.authenticate(w, r) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
 		return
 	}
 
@@ -138,13 +159,18 @@ func (h *AdminHandlers) GC(w http.ResponseWriter, r *http.Request) {
 	var afterStats runtime.MemStats
 	runtime.ReadMemStats(&afterStats)
 
-	resp := AdminGCResponse{
-		Before: AdminGCMemStats{
+	events.Record(slog.LevelInfo, events.TypeAdmin, "garbage collection forced", map[string]any{
+		"alloc_before": beforeStats.Alloc,
+		"alloc_after":  afterStats.Alloc,
+	})
+
+	resp := api.AdminGCResponse{
+		Before: api.AdminGCMemStats{
 			Alloc: beforeStats.Alloc,
 			Sys:   beforeStats.Sys,
 			NumGC: beforeStats.NumGC,
 		},
-		After: AdminGCMemStats{
+		After: api.AdminGCMemStats{
 			Alloc: afterStats.Alloc,
 			Sys:   afterStats.Sys,
 			NumGC: afterStats.NumGC,
@@ -157,86 +183,45 @@ func (h *AdminHandlers) GC(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// AdminConfigFaultEndpoint holds per-endpoint fault injection config.
-type AdminConfigFaultEndpoint struct {
-	Rate      float64 `json:"rate"`
-	Codes     []int   `json:"codes"`
-	ExpiresAt string  `json:"expires_at,omitempty"`
-}
-
-// AdminConfigFault holds fault injection state.
-type AdminConfigFault struct {
-	Global    *AdminConfigFaultEndpoint            `json:"global"`
-	Endpoints map[string]*AdminConfigFaultEndpoint `json:"endpoints,omitempty"`
-}
-
-// AdminConfigQueue holds queue state for the config response.
-type AdminConfigQueue struct {
-	Available bool `json:"available"`
-	Depth     int  `json:"depth,omitempty"`
-	Paused    bool `json:"paused,omitempty"`
-	Workers   int  `json:"workers,omitempty"`
-}
-
-// AdminConfigLimits holds configuration limits.
-type AdminConfigLimits struct {
-	MaxCPUDuration   string `json:"max_cpu_duration"`
-	MaxMemorySize    string `json:"max_memory_size"`
-	MaxIOSize        string `json:"max_io_size"`
-	MaxConcurrentOps int    `json:"max_concurrent_ops"`
-	RequestTimeout   string `json:"request_timeout"`
-}
-
-// AdminConfigSidecar holds sidecar configuration.
-type AdminConfigSidecar struct {
-	Active          bool   `json:"active"`
-	CPUBaseline     string `json:"cpu_baseline,omitempty"`
-	CPUJitter       string `json:"cpu_jitter,omitempty"`
-	MemoryBaseline  string `json:"memory_baseline,omitempty"`
-	RequestOverhead string `json:"request_overhead,omitempty"`
-}
-
-// AdminConfigResponse is the JSON response for GET /admin/config.
-type AdminConfigResponse struct {
-	Mode    string             `json:"mode"`
-	Limits  AdminConfigLimits  `json:"limits"`
-	Fault   AdminConfigFault   `json:"fault"`
-	Queue   AdminConfigQueue   `json:"queue"`
-	Sidecar AdminConfigSidecar `json:"sidecar"`
+func newAdminConfigFaultEndpoint(cfg *fault.ErrorConfig) *api.AdminConfigFaultEndpoint {
+	entry := &api.AdminConfigFaultEndpoint{
+		Rate:  cfg.Rate,
+		Codes: cfg.Codes,
+	}
+	if cfg.Delay > 0 {
+		entry.Delay = cfg.Delay.String()
+	}
+	entry.DelayDistribution = cfg.DelayDist.API()
+	if !cfg.ExpiresAt.IsZero() {
+		entry.ExpiresAt = cfg.ExpiresAt.Format(time.RFC3339)
+	}
+	if cfg.Body != nil {
+		entry.BodyFormat = string(cfg.Body.Format)
+		entry.ContentType = cfg.Body.ContentType
+		entry.Body = cfg.Body.Template
+	}
+	return entry
 }
 
 func (h *AdminHandlers) Config(w http.ResponseWriter, r *http.Request) {
-	if !h.authenticate(w, r) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
 		return
 	}
 
-	faultState := AdminConfigFault{}
+	faultState := api.AdminConfigFault{}
 	if gc := h.injector.GetGlobalConfig(); gc != nil {
-		faultState.Global = &AdminConfigFaultEndpoint{
-			Rate:  gc.Rate,
-			Codes: gc.Codes,
-		}
-		if !gc.ExpiresAt.IsZero() {
-			faultState.Global.ExpiresAt = gc.ExpiresAt.Format(time.RFC3339)
-		}
+		faultState.Global = newAdminConfigFaultEndpoint(gc)
 	}
 
 	epConfigs := h.injector.GetEndpointConfigs()
 	if len(epConfigs) > 0 {
-		faultState.Endpoints = make(map[string]*AdminConfigFaultEndpoint, len(epConfigs))
+		faultState.Endpoints = make(map[string]*api.AdminConfigFaultEndpoint, len(epConfigs))
 		for ep, ec := range epConfigs {
-			entry := &AdminConfigFaultEndpoint{
-				Rate:  ec.Rate,
-				Codes: ec.Codes,
-			}
-			if !ec.ExpiresAt.IsZero() {
-				entry.ExpiresAt = ec.ExpiresAt.Format(time.RFC3339)
-			}
-			faultState.Endpoints[ep] = entry
+			faultState.Endpoints[ep] = newAdminConfigFaultEndpoint(ec)
 		}
 	}
 
-	queueState := AdminConfigQueue{
+	queueState := api.AdminConfigQueue{
 		Available: h.queue != nil,
 	}
 	if h.queue != nil {
@@ -247,54 +232,59 @@ func (h *AdminHandlers) Config(w http.ResponseWriter, r *http.Request) {
 		queueState.Workers = h.workerPool.ActiveWorkers()
 	}
 
-	sidecarState := AdminConfigSidecar{
-		Active: h.cfg.Mode == "sidecar",
+	sidecarState := api.AdminConfigSidecar{
+		Active: h.cfg.RunsSidecar(),
 	}
-	if h.cfg.Mode == "sidecar" {
+	if h.cfg.RunsSidecar() {
 		sidecarState.CPUBaseline = h.cfg.SidecarCPUBaseline.String()
 		sidecarState.CPUJitter = h.cfg.SidecarCPUJitter.String()
 		sidecarState.MemoryBaseline = formatSize(h.cfg.SidecarMemoryBaseline)
+		if h.cfg.SidecarMemoryJitter > 0 {
+			sidecarState.MemoryJitter = formatSize(h.cfg.SidecarMemoryJitter)
+		}
+		if h.cfg.SidecarMemoryPeak > 0 {
+			sidecarState.MemoryPeak = formatSize(h.cfg.SidecarMemoryPeak)
+			sidecarState.MemoryRamp = h.cfg.SidecarMemoryRamp.String()
+		}
 		sidecarState.RequestOverhead = h.cfg.SidecarRequestOverhead.String()
+		if h.cfg.SidecarProxyPort > 0 {
+			sidecarState.ProxyPort = h.cfg.SidecarProxyPort
+			sidecarState.ProxyTarget = h.cfg.SidecarProxyTarget
+		}
 	}
 
-	resp := AdminConfigResponse{
-		Mode: h.cfg.Mode,
-		Limits: AdminConfigLimits{
-			MaxCPUDuration:   h.cfg.MaxCPUDuration.String(),
-			MaxMemorySize:    formatSize(h.cfg.MaxMemorySize),
-			MaxIOSize:        formatSize(h.cfg.MaxIOSize),
-			MaxConcurrentOps: h.cfg.MaxConcurrentOps,
-			RequestTimeout:   h.cfg.RequestTimeout.String(),
+	resp := api.AdminConfigResponse{
+		Mode:     h.cfg.Mode,
+		ReadOnly: h.cfg.ReadOnly,
+		Limits: api.AdminConfigLimits{
+			MaxCPUDuration:        h.cfg.MaxCPUDuration.String(),
+			MaxMemorySize:         formatSize(h.cfg.MaxMemorySize),
+			MaxIOSize:             formatSize(h.cfg.MaxIOSize),
+			MaxConcurrentOps:      h.cfg.MaxConcurrentOps,
+			AdmissionQueueTimeout: h.cfg.AdmissionQueueTimeout.String(),
+			RequestTimeout:        h.cfg.RequestTimeout.String(),
+			RequestTimeouts:       formatRequestTimeouts(h.cfg),
 		},
 		Fault:   faultState,
 		Queue:   queueState,
 		Sidecar: sidecarState,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		slog.Warn("failed to encode admin config response", "error", err)
-	}
-}
-
-// AdminResetResponse is the JSON response for POST /admin/reset.
-type AdminResetResponse struct {
-	FaultReset           bool `json:"fault_reset"`
-	QueueCleared         int  `json:"queue_cleared"`
-	WorkersStopped       bool `json:"workers_stopped"`
-	ReadyOverrideCleared bool `json:"ready_override_cleared"`
+	writeNegotiated(w, r, resp)
 }
 
 func (h *AdminHandlers) Reset(w http.ResponseWriter, r *http.Request) {
-	if !h.authenticate(w, r) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
 		return
 	}
 
 	h.injector.Reset()
 
-	resp := AdminResetResponse{
+	resp := api.AdminResetResponse{
 		FaultReset:           true,
 		ReadyOverrideCleared: true,
+		ExitCodeCleared:      true,
+		ClockSkewCleared:     true,
 	}
 
 	if h.queue != nil {
@@ -302,10 +292,17 @@ func (h *AdminHandlers) Reset(w http.ResponseWriter, r *http.Request) {
 	}
 	if h.workerPool != nil {
 		h.workerPool.Stop()
+		h.workerPool.SetFaults(queue.WorkerFaults{})
 		resp.WorkersStopped = true
 	}
 
 	h.lifecycle.SetReadyOverride(nil)
+	h.lifecycle.SetExitCode(-1)
+	wallclock.SetSkew(0)
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "runtime state reset", map[string]any{
+		"queue_cleared": resp.QueueCleared,
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -313,16 +310,15 @@ func (h *AdminHandlers) Reset(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// AdminErrorRateResponse is the JSON response for POST /admin/error-rate.
-type AdminErrorRateResponse struct {
-	Endpoint string  `json:"endpoint"`
-	Rate     float64 `json:"rate"`
-	Codes    []int   `json:"codes"`
-	Duration string  `json:"duration,omitempty"`
-}
-
+// ErrorRate configures a single error injection rule from query parameters,
+// or several rules at once when the body is a JSON api.FaultRulesRequest.
 func (h *AdminHandlers) ErrorRate(w http.ResponseWriter, r *http.Request) {
-	if !h.authenticate(w, r) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	if isJSONRequest(r) {
+		h.applyFaultRules(w, r)
 		return
 	}
 
@@ -330,16 +326,16 @@ func (h *AdminHandlers) ErrorRate(w http.ResponseWriter, r *http.Request) {
 
 	rateStr := r.URL.Query().Get("rate")
 	if rateStr == "" {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "rate is required")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate is required")
 		return
 	}
 	rate, err := strconv.ParseFloat(rateStr, 64)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "rate must be a number")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate must be a number")
 		return
 	}
 	if rate < 0 || rate > 1 {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "rate must be between 0 and 1")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate must be between 0 and 1")
 		return
 	}
 
@@ -350,11 +346,11 @@ func (h *AdminHandlers) ErrorRate(w http.ResponseWriter, r *http.Request) {
 			s = strings.TrimSpace(s)
 			code, err := strconv.Atoi(s)
 			if err != nil {
-				writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "codes must be comma-separated integers")
+				writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "codes must be comma-separated integers")
 				return
 			}
 			if code < 100 || code > 599 {
-				writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "codes must be valid HTTP status codes (100-599)")
+				writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "codes must be valid HTTP status codes (100-599)")
 				return
 			}
 			codes = append(codes, code)
@@ -368,11 +364,38 @@ func (h *AdminHandlers) ErrorRate(w http.ResponseWriter, r *http.Request) {
 		Codes: codes,
 	}
 
+	if format := r.URL.Query().Get("body_format"); format != "" {
+		body, err := fault.NewErrorBody(format, "", "")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+			return
+		}
+		cfg.Body = body
+	}
+
+	delayStr := r.URL.Query().Get("delay")
+	if delayStr != "" {
+		d, err := time.ParseDuration(delayStr)
+		if err != nil || d < 0 {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "invalid delay")
+			return
+		}
+		if d > maxFaultDelay {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "delay exceeds maximum of "+maxFaultDelay.String())
+			return
+		}
+		cfg.Delay = d
+	}
+	if cfg.DelayDist, err = parseDistribution(r); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
 	durationStr := r.URL.Query().Get("duration")
 	if durationStr != "" {
 		d, err := time.ParseDuration(durationStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "invalid duration")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "invalid duration")
 			return
 		}
 		cfg.ExpiresAt = time.Now().Add(d)
@@ -384,11 +407,23 @@ func (h *AdminHandlers) ErrorRate(w http.ResponseWriter, r *http.Request) {
 		h.injector.SetEndpointConfig(endpoint, cfg)
 	}
 
-	resp := AdminErrorRateResponse{
+	events.Record(slog.LevelInfo, events.TypeAdmin, "error rate configured", map[string]any{
+		"endpoint": endpoint,
+		"rate":     rate,
+		"codes":    codes,
+		"delay":    delayStr,
+		"duration": durationStr,
+	})
+
+	resp := api.AdminErrorRateResponse{
 		Endpoint: endpoint,
 		Rate:     rate,
 		Codes:    codes,
 	}
+	if delayStr != "" {
+		resp.Delay = delayStr
+	}
+	resp.DelayDistribution = cfg.DelayDist.API()
 	if durationStr != "" {
 		resp.Duration = durationStr
 	}
@@ -399,50 +434,648 @@ func (h *AdminHandlers) ErrorRate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// AdminQueuePauseResponse is the JSON response for POST /admin/queue/pause.
-type AdminQueuePauseResponse struct {
-	Paused bool `json:"paused"`
-}
-
 func (h *AdminHandlers) QueuePause(w http.ResponseWriter, r *http.Request) {
-	if !h.authenticate(w, r) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
 		return
 	}
 
 	if h.queue == nil {
-		writeError(w, http.StatusNotFound, "QUEUE_NOT_AVAILABLE", "queue is not available in this mode")
+		writeError(w, http.StatusNotFound, errcode.QueueNotAvailable, "queue is not available in this mode")
 		return
 	}
 
 	h.queue.Pause()
+	events.Record(slog.LevelInfo, events.TypeAdmin, "queue paused", nil)
 
-	resp := AdminQueuePauseResponse{Paused: true}
+	resp := api.AdminQueuePauseResponse{Paused: true}
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Warn("failed to encode admin queue pause response", "error", err)
 	}
 }
 
-// AdminQueueResumeResponse is the JSON response for POST /admin/queue/resume.
-type AdminQueueResumeResponse struct {
-	Paused bool `json:"paused"`
-}
-
 func (h *AdminHandlers) QueueResume(w http.ResponseWriter, r *http.Request) {
-	if !h.authenticate(w, r) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
 		return
 	}
 
 	if h.queue == nil {
-		writeError(w, http.StatusNotFound, "QUEUE_NOT_AVAILABLE", "queue is not available in this mode")
+		writeError(w, http.StatusNotFound, errcode.QueueNotAvailable, "queue is not available in this mode")
 		return
 	}
 
 	h.queue.Resume()
+	events.Record(slog.LevelInfo, events.TypeAdmin, "queue resumed", nil)
 
-	resp := AdminQueueResumeResponse{Paused: false}
+	resp := api.AdminQueueResumeResponse{Paused: false}
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Warn("failed to encode admin queue resume response", "error", err)
 	}
 }
+
+// QueueFault reports the faults injected into queue workers.
+func (h *AdminHandlers) QueueFault(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	if h.workerPool == nil {
+		writeError(w, http.StatusNotFound, errcode.QueueNotAvailable, "queue is not available in this mode")
+		return
+	}
+	h.writeQueueFault(w)
+}
+
+// SetQueueFault replaces the faults injected into queue workers with a JSON
+// api.QueueFault body.
+func (h *AdminHandlers) SetQueueFault(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+	if h.workerPool == nil {
+		writeError(w, http.StatusNotFound, errcode.QueueNotAvailable, "queue is not available in this mode")
+		return
+	}
+
+	var req api.QueueFault
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFaultRulesBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "body must be a JSON queue fault: "+err.Error())
+		return
+	}
+
+	f := queue.WorkerFaults{PanicRate: req.PanicRate, StallRate: req.StallRate, DuplicateRate: req.DuplicateRate}
+	if req.Stall != "" {
+		d, err := time.ParseDuration(req.Stall)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("stall must be a duration like 5s, got %q", req.Stall))
+			return
+		}
+		f.Stall = d
+	}
+	if err := f.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	h.workerPool.SetFaults(f)
+
+	slog.Warn("queue worker faults set", "panic_rate", f.PanicRate, "stall_rate", f.StallRate, "stall", f.Stall, "duplicate_rate", f.DuplicateRate)
+	events.Record(slog.LevelWarn, events.TypeFault, "queue worker faults set", map[string]any{
+		"panic_rate":     f.PanicRate,
+		"stall_rate":     f.StallRate,
+		"stall":          f.Stall.String(),
+		"duplicate_rate": f.DuplicateRate,
+	})
+	h.writeQueueFault(w)
+}
+
+// ClearQueueFault stops injecting faults into queue workers.
+func (h *AdminHandlers) ClearQueueFault(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+	if h.workerPool == nil {
+		writeError(w, http.StatusNotFound, errcode.QueueNotAvailable, "queue is not available in this mode")
+		return
+	}
+
+	h.workerPool.SetFaults(queue.WorkerFaults{})
+	slog.Info("queue worker faults cleared")
+	events.Record(slog.LevelInfo, events.TypeFault, "queue worker faults cleared", nil)
+	h.writeQueueFault(w)
+}
+
+func (h *AdminHandlers) writeQueueFault(w http.ResponseWriter) {
+	f := h.workerPool.Faults()
+	resp := api.AdminQueueFaultResponse{
+		Enabled: f.Enabled(),
+		Fault:   api.QueueFault{PanicRate: f.PanicRate, StallRate: f.StallRate, DuplicateRate: f.DuplicateRate},
+	}
+	if f.Stall > 0 {
+		resp.Fault.Stall = f.Stall.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode admin queue fault response", "error", err)
+	}
+}
+
+func (h *AdminHandlers) Audit(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+
+	var afterID uint64
+	if v := r.URL.Query().Get("after_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "after_id must be a non-negative integer")
+			return
+		}
+		afterID = id
+	}
+
+	limit, err := parseInt(r, "limit", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if limit < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "limit must be non-negative")
+		return
+	}
+
+	var entries []audit.Entry
+	if h.auditLog != nil {
+		entries = h.auditLog.List(afterID, limit)
+	}
+
+	resp := api.AdminAuditResponse{Count: len(entries), Entries: entries}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode admin audit response", "error", err)
+	}
+}
+
+// DrainStatus handles GET /admin/drain-status, reporting open connections,
+// in-flight requests, and per-phase shutdown statistics.
+func (h *AdminHandlers) DrainStatus(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.lifecycle.DrainStatus()); err != nil {
+		slog.Warn("failed to encode drain status response", "error", err)
+	}
+}
+
+// LogLevel handles GET /admin/loglevel.
+func (h *AdminHandlers) LogLevel(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	writeLogLevel(w, api.AdminLogLevelResponse{Level: logging.Level()})
+}
+
+// SetLogLevel handles POST /admin/loglevel?level=debug|info|warn|error,
+// changing the log level without a restart.
+func (h *AdminHandlers) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	previous := logging.Level()
+	level := r.URL.Query().Get("level")
+	if _, err := logging.ParseLevel(level); err != nil || level == "" {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "level must be one of: debug, info, warn, error")
+		return
+	}
+	logging.SetLevel(level)
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "log level changed", map[string]any{
+		"level":    logging.Level(),
+		"previous": previous,
+	})
+	writeLogLevel(w, api.AdminLogLevelResponse{Level: logging.Level(), Previous: previous})
+}
+
+func writeLogLevel(w http.ResponseWriter, resp api.AdminLogLevelResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode log level response", "error", err)
+	}
+}
+
+// ExitCode handles GET /admin/exit-code.
+func (h *AdminHandlers) ExitCode(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeExitCode(w)
+}
+
+// SetExitCode handles POST /admin/exit-code?code=N. The process keeps running
+// normally and exits with code after the next graceful shutdown, unlike
+// /fault/crash which terminates immediately.
+func (h *AdminHandlers) SetExitCode(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	codeStr := r.URL.Query().Get("code")
+	code, err := strconv.Atoi(codeStr)
+	if err != nil || code < 0 || code > 255 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "code must be an integer between 0 and 255")
+		return
+	}
+
+	h.lifecycle.SetExitCode(code)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "shutdown exit code set", map[string]any{"exit_code": code})
+	h.writeExitCode(w)
+}
+
+// ClearExitCode handles DELETE /admin/exit-code, restoring the normal exit status.
+func (h *AdminHandlers) ClearExitCode(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	h.lifecycle.SetExitCode(-1)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "shutdown exit code cleared", nil)
+	h.writeExitCode(w)
+}
+
+func (h *AdminHandlers) writeExitCode(w http.ResponseWriter) {
+	var resp api.AdminExitCodeResponse
+	if code, ok := h.lifecycle.ExitCode(); ok {
+		resp.ExitCode = &code
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode exit code response", "error", err)
+	}
+}
+
+// maxClockSkew caps the simulated clock skew in either direction.
+const maxClockSkew = 365 * 24 * time.Hour
+
+// Clock handles GET /admin/clock.
+func (h *AdminHandlers) Clock(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	writeClock(w)
+}
+
+// SetClock handles POST /admin/clock?skew=5m. The skew shifts every
+// externally visible timestamp (health and /info responses, events, the Date
+// header, JWT validation, and hotpod_time_seconds) without touching the
+// node clock. Negative skews put the clock behind. It requires the chaos
+// role, since a skew can make JWT validation reject otherwise valid tokens.
+func (h *AdminHandlers) SetClock(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleChaos) {
+		return
+	}
+
+	skew, err := time.ParseDuration(r.URL.Query().Get("skew"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "skew must be a duration such as 5m or -90s")
+		return
+	}
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "skew exceeds maximum of "+maxClockSkew.String())
+		return
+	}
+
+	previous := wallclock.Skew()
+	wallclock.SetSkew(skew)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "clock skew set", map[string]any{
+		"skew":     skew.String(),
+		"previous": previous.String(),
+	})
+	writeClock(w)
+}
+
+// ClearClock handles DELETE /admin/clock, removing any skew.
+func (h *AdminHandlers) ClearClock(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	wallclock.SetSkew(0)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "clock skew cleared", nil)
+	writeClock(w)
+}
+
+func writeClock(w http.ResponseWriter) {
+	now := time.Now()
+	skew := wallclock.Skew()
+	resp := api.AdminClockResponse{
+		Skew:        skew.String(),
+		SkewSeconds: skew.Seconds(),
+		Time:        now.Add(skew).UTC().Format(time.RFC3339Nano),
+		NodeTime:    now.UTC().Format(time.RFC3339Nano),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode admin clock response", "error", err)
+	}
+}
+
+// maxFaultDelay caps how long an injected error may be delayed.
+const maxFaultDelay = fault.MaxErrorDelay
+
+// maxFaultRulesBody caps the size of a JSON fault rules request.
+const maxFaultRulesBody = 1 << 20
+
+// Faults lists all active error injection rules.
+func (h *AdminHandlers) Faults(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeFaults(w)
+}
+
+// SetFaults applies a JSON api.FaultRulesRequest.
+func (h *AdminHandlers) SetFaults(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+	h.applyFaultRules(w, r)
+}
+
+// ClearFaults removes all error injection rules.
+func (h *AdminHandlers) ClearFaults(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	h.injector.Reset()
+	events.Record(slog.LevelInfo, events.TypeAdmin, "error injection rules cleared", nil)
+	h.writeFaults(w)
+}
+
+// applyFaultRules validates every rule in the request before applying any,
+// so a bad rule never leaves a partially applied experiment behind.
+func (h *AdminHandlers) applyFaultRules(w http.ResponseWriter, r *http.Request) {
+	var req api.FaultRulesRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFaultRulesBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "body must be a JSON object with a rules array: "+err.Error())
+		return
+	}
+	if len(req.Rules) == 0 && !req.Replace {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rules must not be empty")
+		return
+	}
+
+	var global *fault.ErrorConfig
+	endpoints := make(map[string]*fault.ErrorConfig, len(req.Rules))
+	seen := make(map[string]bool, len(req.Rules))
+	for i, rule := range req.Rules {
+		if seen[rule.Endpoint] {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("rules[%d]: duplicate endpoint %q", i, rule.Endpoint))
+			return
+		}
+		seen[rule.Endpoint] = true
+
+		cfg, err := faultRuleConfig(rule)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("rules[%d]: %v", i, err))
+			return
+		}
+		if rule.Endpoint == "" {
+			global = cfg
+		} else {
+			endpoints[rule.Endpoint] = cfg
+		}
+	}
+
+	if req.Replace {
+		h.injector.Replace(global, endpoints)
+	} else {
+		if seen[""] {
+			h.injector.SetGlobalConfig(global)
+		}
+		for endpoint, cfg := range endpoints {
+			h.injector.SetEndpointConfig(endpoint, cfg)
+		}
+	}
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "error injection rules configured", map[string]any{
+		"rules":   len(req.Rules),
+		"replace": req.Replace,
+	})
+
+	h.writeFaults(w)
+}
+
+func (h *AdminHandlers) writeFaults(w http.ResponseWriter) {
+	resp := api.AdminFaultsResponse{Rules: []api.AdminFaultRule{}}
+	if gc := h.injector.GetGlobalConfig(); gc != nil {
+		resp.Rules = append(resp.Rules, api.AdminFaultRule{AdminConfigFaultEndpoint: *newAdminConfigFaultEndpoint(gc)})
+	}
+
+	epConfigs := h.injector.GetEndpointConfigs()
+	endpoints := make([]string, 0, len(epConfigs))
+	for ep := range epConfigs {
+		endpoints = append(endpoints, ep)
+	}
+	sort.Strings(endpoints)
+	for _, ep := range endpoints {
+		resp.Rules = append(resp.Rules, api.AdminFaultRule{
+			Endpoint:                 ep,
+			AdminConfigFaultEndpoint: *newAdminConfigFaultEndpoint(epConfigs[ep]),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode admin faults response", "error", err)
+	}
+}
+
+func faultRuleConfig(rule api.FaultRule) (*fault.ErrorConfig, error) {
+	if rule.Endpoint != "" && !strings.HasPrefix(rule.Endpoint, "/") {
+		return nil, errors.New("endpoint must start with /")
+	}
+	if rule.Rate < 0 || rule.Rate > 1 {
+		return nil, errors.New("rate must be between 0 and 1")
+	}
+
+	cfg := &fault.ErrorConfig{Rate: rule.Rate, Codes: rule.Codes}
+	for _, code := range rule.Codes {
+		if code < 100 || code > 599 {
+			return nil, errors.New("codes must be valid HTTP status codes (100-599)")
+		}
+	}
+	if len(cfg.Codes) == 0 {
+		cfg.Codes = []int{500}
+	}
+
+	if rule.BodyFormat != "" || rule.ContentType != "" || rule.Body != "" {
+		body, err := fault.NewErrorBody(rule.BodyFormat, rule.ContentType, rule.Body)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Body = body
+	}
+
+	if rule.Delay != "" {
+		d, err := time.ParseDuration(rule.Delay)
+		if err != nil || d < 0 {
+			return nil, errors.New("invalid delay")
+		}
+		if d > maxFaultDelay {
+			return nil, fmt.Errorf("delay exceeds maximum of %s", maxFaultDelay)
+		}
+		cfg.Delay = d
+	}
+	dist, err := fault.DistributionFromAPI(rule.DelayDistribution)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DelayDist = dist
+
+	if rule.Duration != "" {
+		d, err := time.ParseDuration(rule.Duration)
+		if err != nil || d <= 0 {
+			return nil, errors.New("invalid duration")
+		}
+		cfg.ExpiresAt = time.Now().Add(d)
+	}
+	return cfg, nil
+}
+
+// HeaderFaults lists all active response header fault rules.
+func (h *AdminHandlers) HeaderFaults(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeHeaderFaults(w)
+}
+
+// SetHeaderFault configures response header faults for one endpoint from a
+// JSON api.HeaderFaultRule. A rate of 0 removes the endpoint's rule.
+func (h *AdminHandlers) SetHeaderFault(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	var rule api.HeaderFaultRule
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFaultRulesBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rule); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "body must be a JSON header fault rule: "+err.Error())
+		return
+	}
+
+	cfg, err := headerRuleConfig(rule)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	h.injector.SetHeaderConfig(rule.Endpoint, cfg)
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "header faults configured", map[string]any{
+		"endpoint": rule.Endpoint,
+		"rate":     rule.Rate,
+		"corrupt":  rule.Corrupt,
+		"duration": rule.Duration,
+	})
+
+	h.writeHeaderFaults(w)
+}
+
+// ClearHeaderFaults removes the header fault rule for the endpoint query
+// parameter, or all header fault rules if it is absent.
+func (h *AdminHandlers) ClearHeaderFaults(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	if r.URL.Query().Has("endpoint") {
+		h.injector.SetHeaderConfig(r.URL.Query().Get("endpoint"), nil)
+	} else {
+		for endpoint := range h.injector.GetHeaderConfigs() {
+			h.injector.SetHeaderConfig(endpoint, nil)
+		}
+	}
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "header faults cleared", map[string]any{
+		"endpoint": r.URL.Query().Get("endpoint"),
+	})
+
+	h.writeHeaderFaults(w)
+}
+
+func (h *AdminHandlers) writeHeaderFaults(w http.ResponseWriter) {
+	configs := h.injector.GetHeaderConfigs()
+	endpoints := make([]string, 0, len(configs))
+	for ep := range configs {
+		endpoints = append(endpoints, ep)
+	}
+	sort.Strings(endpoints)
+
+	resp := api.AdminHeaderFaultsResponse{Rules: make([]api.HeaderFaultRule, 0, len(endpoints))}
+	for _, ep := range endpoints {
+		cfg := configs[ep]
+		rule := api.HeaderFaultRule{
+			Endpoint: ep,
+			Rate:     cfg.Rate,
+			Set:      cfg.Set,
+			Remove:   cfg.Remove,
+		}
+		for _, c := range cfg.Corrupt {
+			rule.Corrupt = append(rule.Corrupt, string(c))
+		}
+		if !cfg.ExpiresAt.IsZero() {
+			rule.ExpiresAt = cfg.ExpiresAt.Format(time.RFC3339)
+		}
+		resp.Rules = append(resp.Rules, rule)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode admin header faults response", "error", err)
+	}
+}
+
+func headerRuleConfig(rule api.HeaderFaultRule) (*fault.HeaderConfig, error) {
+	if rule.Endpoint != "" && !strings.HasPrefix(rule.Endpoint, "/") {
+		return nil, errors.New("endpoint must start with /")
+	}
+	if rule.Rate < 0 || rule.Rate > 1 {
+		return nil, errors.New("rate must be between 0 and 1")
+	}
+	if rule.Rate > 0 && len(rule.Set) == 0 && len(rule.Remove) == 0 && len(rule.Corrupt) == 0 {
+		return nil, errors.New("at least one of set, remove, or corrupt is required")
+	}
+
+	cfg := &fault.HeaderConfig{Rate: rule.Rate, Set: rule.Set, Remove: rule.Remove}
+	for k := range rule.Set {
+		if !isValidHeaderName(k) {
+			return nil, fmt.Errorf("invalid header name %q", k)
+		}
+	}
+	for _, name := range rule.Corrupt {
+		c, err := fault.ParseHeaderCorruption(name)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Corrupt = append(cfg.Corrupt, c)
+	}
+
+	if rule.Duration != "" {
+		d, err := time.ParseDuration(rule.Duration)
+		if err != nil || d <= 0 {
+			return nil, errors.New("invalid duration")
+		}
+		cfg.ExpiresAt = time.Now().Add(d)
+	}
+	return cfg, nil
+}
+
+// isValidHeaderName reports whether name is a valid HTTP header field name,
+// since net/http silently drops headers that are not.
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c >= 0x7f || c <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// isJSONRequest reports whether the request carries a JSON body.
+func isJSONRequest(r *http.Request) bool {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mt == "application/json"
+}
@@ -4,15 +4,21 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/jonboulle/clockwork"
 
+	"github.com/ripta/hotpod/internal/audit"
+	"github.com/ripta/hotpod/internal/auth"
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/logging"
 	"github.com/ripta/hotpod/internal/queue"
 	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 type adminEndpoint struct {
@@ -26,8 +32,17 @@ var adminEndpoints = []adminEndpoint{
 	{"GET", "/admin/config"},
 	{"POST", "/admin/reset"},
 	{"POST", "/admin/error-rate"},
+	{"GET", "/admin/faults"},
+	{"DELETE", "/admin/faults"},
+	{"GET", "/admin/header-faults"},
+	{"DELETE", "/admin/header-faults"},
+	{"GET", "/admin/clock"},
+	{"DELETE", "/admin/clock"},
 	{"POST", "/admin/queue/pause"},
 	{"POST", "/admin/queue/resume"},
+	{"GET", "/admin/queue/fault"},
+	{"DELETE", "/admin/queue/fault"},
+	{"GET", "/admin/audit"},
 }
 
 func newTestLifecycle() *server.Lifecycle {
@@ -54,7 +69,7 @@ func newTestAdminHandlers(token string) (*AdminHandlers, *queue.Queue, *queue.Wo
 	cfg := newTestConfig()
 	q := queue.New(100)
 	wp := queue.NewWorkerPool(q)
-	h := NewAdminHandlers(token, lc, inj, cfg, q, wp)
+	h := NewAdminHandlers(auth.New(token, nil), lc, inj, cfg, q, wp, audit.New(100))
 	return h, q, wp
 }
 
@@ -142,7 +157,7 @@ func TestAdminReadyForceTrue(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminReadyResponse
+	var resp api.AdminReadyResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -167,7 +182,7 @@ func TestAdminReadyForceFalse(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminReadyResponse
+	var resp api.AdminReadyResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -188,7 +203,7 @@ func TestAdminReadyToggle(t *testing.T) {
 	rec := httptest.NewRecorder()
 	h.Ready(rec, req)
 
-	var resp AdminReadyResponse
+	var resp api.AdminReadyResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -237,7 +252,7 @@ func TestAdminGC(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminGCResponse
+	var resp api.AdminGCResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -262,7 +277,7 @@ func TestAdminConfig(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminConfigResponse
+	var resp api.AdminConfigResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -296,7 +311,7 @@ func TestAdminConfigWithFaultInjection(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminConfigResponse
+	var resp api.AdminConfigResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -335,7 +350,7 @@ func TestAdminReset(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminResetResponse
+	var resp api.AdminResetResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -374,7 +389,7 @@ func TestAdminErrorRateGlobal(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminErrorRateResponse
+	var resp api.AdminErrorRateResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -402,7 +417,7 @@ func TestAdminErrorRateEndpoint(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminErrorRateResponse
+	var resp api.AdminErrorRateResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -427,7 +442,7 @@ func TestAdminErrorRateWithDuration(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminErrorRateResponse
+	var resp api.AdminErrorRateResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -437,6 +452,32 @@ func TestAdminErrorRateWithDuration(t *testing.T) {
 	}
 }
 
+func TestAdminErrorRateDelayDistribution(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("")
+
+	req := httptest.NewRequest("POST", "/admin/error-rate?rate=0.5&delay=100ms&distribution=lognormal&sigma=1.5", nil)
+	rec := httptest.NewRecorder()
+
+	h.ErrorRate(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var resp api.AdminErrorRateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if d := resp.DelayDistribution; d == nil || d.Kind != "lognormal" || d.Sigma != 1.5 {
+		t.Errorf("delay distribution = %+v, want lognormal with sigma 1.5", d)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ErrorRate(rec, httptest.NewRequest("POST", "/admin/error-rate?rate=0.5&distribution=pareto&alpha=-1", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid distribution: status = %d, want 400", rec.Code)
+	}
+}
+
 func TestAdminErrorRateMissingRate(t *testing.T) {
 	h, _, _ := newTestAdminHandlers("")
 
@@ -492,6 +533,210 @@ func TestAdminErrorRateInvalidDuration(t *testing.T) {
 	}
 }
 
+func TestAdminErrorRateJSONBody(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("")
+
+	body := `{"rules":[{"endpoint":"/cpu","rate":0.5,"codes":[503],"delay":"100ms","body_format":"problem"},{"endpoint":"/io","rate":1,"duration":"5m"}]}`
+	req := httptest.NewRequest("POST", "/admin/error-rate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ErrorRate(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	cpu := h.injector.GetConfig("/cpu")
+	if cpu == nil || cpu.Rate != 0.5 || cpu.Delay != 100*time.Millisecond || cpu.Body.Format != fault.BodyProblem {
+		t.Errorf("/cpu config = %+v", cpu)
+	}
+	io := h.injector.GetConfig("/io")
+	if io == nil || io.ExpiresAt.IsZero() || io.Codes[0] != 500 {
+		t.Errorf("/io config = %+v", io)
+	}
+}
+
+func TestAdminSetFaults(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("")
+	h.injector.SetEndpointConfig("/memory", &fault.ErrorConfig{Rate: 1, Codes: []int{500}})
+
+	tests := []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantRules []string
+	}{
+		{
+			name:      "merge",
+			body:      `{"rules":[{"rate":0.1},{"endpoint":"/cpu","rate":0.5}]}`,
+			wantCode:  http.StatusOK,
+			wantRules: []string{"", "/cpu", "/memory"},
+		},
+		{
+			name:      "replace",
+			body:      `{"replace":true,"rules":[{"endpoint":"/io","rate":0.2}]}`,
+			wantCode:  http.StatusOK,
+			wantRules: []string{"/io"},
+		},
+		{
+			name:     "invalid rule leaves state untouched",
+			body:     `{"replace":true,"rules":[{"endpoint":"/cpu","rate":0.5},{"endpoint":"/work","rate":2}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "duplicate endpoint",
+			body:     `{"rules":[{"endpoint":"/cpu","rate":0.5},{"endpoint":"/cpu","rate":0.1}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown body format",
+			body:     `{"rules":[{"endpoint":"/cpu","rate":0.5,"body_format":"xml"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "delay too long",
+			body:     `{"rules":[{"endpoint":"/cpu","rate":0.5,"delay":"1h"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown field",
+			body:     `{"rules":[{"endpoint":"/cpu","probability":0.5}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "empty rules",
+			body:     `{"rules":[]}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/admin/faults", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.SetFaults(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var resp api.AdminFaultsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+			var got []string
+			for _, rule := range resp.Rules {
+				got = append(got, rule.Endpoint)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantRules, ",") {
+				t.Errorf("rules = %q, want %q", got, tt.wantRules)
+			}
+		})
+	}
+
+	if h.injector.GetConfig("/io") == nil {
+		t.Error("rejected requests should not modify existing rules")
+	}
+}
+
+func TestAdminClearFaults(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("")
+	h.injector.SetEndpointConfig("/cpu", &fault.ErrorConfig{Rate: 1, Codes: []int{500}})
+
+	rec := httptest.NewRecorder()
+	h.ClearFaults(rec, httptest.NewRequest("DELETE", "/admin/faults", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if h.injector.GetConfig("/cpu") != nil {
+		t.Error("expected faults to be cleared")
+	}
+}
+
+func TestAdminHeaderFaults(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("")
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"connection close", `{"endpoint":"/cpu","rate":0.1,"corrupt":["connection-close"]}`, http.StatusOK},
+		{"valid", `{"endpoint":"/cpu","rate":1,"remove":["Access-Control-Allow-Origin"],"corrupt":["huge-cookie"],"duration":"5m"}`, http.StatusOK},
+		{"unknown corruption", `{"endpoint":"/cpu","rate":1,"corrupt":["gremlins"]}`, http.StatusBadRequest},
+		{"invalid header name", `{"endpoint":"/cpu","rate":1,"set":{"Bad Header":"x"}}`, http.StatusBadRequest},
+		{"no actions", `{"endpoint":"/cpu","rate":1}`, http.StatusBadRequest},
+		{"bad rate", `{"endpoint":"/cpu","rate":2,"remove":["Server"]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.SetHeaderFault(rec, httptest.NewRequest("POST", "/admin/header-faults", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+		})
+	}
+
+	cfg := h.injector.GetHeaderConfig("/cpu")
+	if cfg == nil || len(cfg.Corrupt) != 1 || cfg.ExpiresAt.IsZero() {
+		t.Fatalf("/cpu header config = %+v", cfg)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ClearHeaderFaults(rec, httptest.NewRequest("DELETE", "/admin/header-faults?endpoint=/cpu", nil))
+	var resp api.AdminHeaderFaultsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(resp.Rules) != 0 {
+		t.Errorf("rules after clear = %+v, want none", resp.Rules)
+	}
+}
+
+func TestAdminClock(t *testing.T) {
+	defer wallclock.SetSkew(0)
+	h, _, _ := newTestAdminHandlers("")
+
+	tests := []struct {
+		skew     string
+		wantCode int
+		wantSkew time.Duration
+	}{
+		{"5m", http.StatusOK, 5 * time.Minute},
+		{"-90s", http.StatusOK, -90 * time.Second},
+		{"", http.StatusBadRequest, -90 * time.Second},
+		{"tomorrow", http.StatusBadRequest, -90 * time.Second},
+		{"9000h", http.StatusBadRequest, -90 * time.Second},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.SetClock(rec, httptest.NewRequest("POST", "/admin/clock?skew="+tt.skew, nil))
+		if rec.Code != tt.wantCode {
+			t.Errorf("skew=%s: status = %d, want %d", tt.skew, rec.Code, tt.wantCode)
+		}
+		if got := wallclock.Skew(); got != tt.wantSkew {
+			t.Errorf("skew=%s: Skew() = %v, want %v", tt.skew, got, tt.wantSkew)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ClearClock(rec, httptest.NewRequest("DELETE", "/admin/clock", nil))
+	var resp api.AdminClockResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.SkewSeconds != 0 || wallclock.Skew() != 0 {
+		t.Errorf("skew after clear = %v, want 0", resp.Skew)
+	}
+}
+
 func TestAdminQueuePause(t *testing.T) {
 	h, q, _ := newTestAdminHandlers("")
 
@@ -528,11 +773,63 @@ func TestAdminQueueResume(t *testing.T) {
 	}
 }
 
+func TestAdminQueueFault(t *testing.T) {
+	h, _, wp := newTestAdminHandlers("")
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	for _, tt := range []struct {
+		method     string
+		body       string
+		wantStatus int
+		want       api.AdminQueueFaultResponse
+	}{
+		{"GET", "", http.StatusOK, api.AdminQueueFaultResponse{}},
+		{
+			"POST",
+			`{"panic_rate":0.1,"stall_rate":0.2,"stall":"5s","duplicate_rate":0.3}`,
+			http.StatusOK,
+			api.AdminQueueFaultResponse{Enabled: true, Fault: api.QueueFault{PanicRate: 0.1, StallRate: 0.2, Stall: "5s", DuplicateRate: 0.3}},
+		},
+		{"POST", `{"panic_rate":1.5}`, http.StatusBadRequest, api.AdminQueueFaultResponse{}},
+		{"POST", `{"stall_rate":0.5}`, http.StatusBadRequest, api.AdminQueueFaultResponse{}},
+		{"POST", `{"stall_rate":0.5,"stall":"soon"}`, http.StatusBadRequest, api.AdminQueueFaultResponse{}},
+		{"POST", `{"crash_rate":0.5}`, http.StatusBadRequest, api.AdminQueueFaultResponse{}},
+		{"DELETE", "", http.StatusOK, api.AdminQueueFaultResponse{}},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, "/admin/queue/fault", strings.NewReader(tt.body)))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.body, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp api.AdminQueueFaultResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp != tt.want {
+			t.Errorf("%s %s: response = %+v, want %+v", tt.method, tt.body, resp, tt.want)
+		}
+	}
+	if wp.Faults().Enabled() {
+		t.Error("worker faults still enabled after DELETE")
+	}
+
+	h = NewAdminHandlers(auth.New("", nil), newTestLifecycle(), fault.NewInjector(), newTestConfig(), nil, nil, nil)
+	rec := httptest.NewRecorder()
+	h.SetQueueFault(rec, httptest.NewRequest("POST", "/admin/queue/fault", strings.NewReader(`{"panic_rate":0.1}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status with no worker pool = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
 func TestAdminQueuePauseNilQueue(t *testing.T) {
 	lc := newTestLifecycle()
 	inj := fault.NewInjector()
 	cfg := newTestConfig()
-	h := NewAdminHandlers("", lc, inj, cfg, nil, nil)
+	h := NewAdminHandlers(auth.New("", nil), lc, inj, cfg, nil, nil, nil)
 
 	req := httptest.NewRequest("POST", "/admin/queue/pause", nil)
 	rec := httptest.NewRecorder()
@@ -548,7 +845,7 @@ func TestAdminQueueResumeNilQueue(t *testing.T) {
 	lc := newTestLifecycle()
 	inj := fault.NewInjector()
 	cfg := newTestConfig()
-	h := NewAdminHandlers("", lc, inj, cfg, nil, nil)
+	h := NewAdminHandlers(auth.New("", nil), lc, inj, cfg, nil, nil, nil)
 
 	req := httptest.NewRequest("POST", "/admin/queue/resume", nil)
 	rec := httptest.NewRecorder()
@@ -564,7 +861,7 @@ func TestAdminResetNilQueue(t *testing.T) {
 	lc := newTestLifecycle()
 	inj := fault.NewInjector()
 	cfg := newTestConfig()
-	h := NewAdminHandlers("", lc, inj, cfg, nil, nil)
+	h := NewAdminHandlers(auth.New("", nil), lc, inj, cfg, nil, nil, nil)
 
 	req := httptest.NewRequest("POST", "/admin/reset", nil)
 	rec := httptest.NewRecorder()
@@ -575,7 +872,7 @@ func TestAdminResetNilQueue(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminResetResponse
+	var resp api.AdminResetResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -600,7 +897,7 @@ func TestAdminErrorRateDefaultCodes(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp AdminErrorRateResponse
+	var resp api.AdminErrorRateResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -609,3 +906,154 @@ func TestAdminErrorRateDefaultCodes(t *testing.T) {
 		t.Errorf("codes = %v, want [500]", resp.Codes)
 	}
 }
+
+func TestAdminAuditList(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("")
+	h.auditLog.Record(audit.Entry{Method: "POST", Path: "/admin/ready", Status: 200})
+	h.auditLog.Record(audit.Entry{Method: "POST", Path: "/admin/gc", Status: 200})
+
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	req := httptest.NewRequest("GET", "/admin/audit?after_id=1", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var resp api.AdminAuditResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 1 || resp.Entries[0].Path != "/admin/gc" {
+		t.Errorf("got %+v, want only /admin/gc", resp)
+	}
+}
+
+func TestAdminAuditRequiresAuth(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("secret")
+
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	req := httptest.NewRequest("GET", "/admin/audit", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestAdminScopedRoles(t *testing.T) {
+	tokens, err := auth.ParseTokens("dash:read:r,ops:mutate:m,sre:chaos:c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wallclock.SetSkew(0)
+	h, _, _ := newTestAdminHandlers("")
+	h.authn = auth.New("", tokens)
+
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	tests := []struct {
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"GET", "/admin/config", "r", http.StatusOK},
+		{"POST", "/admin/gc", "r", http.StatusForbidden},
+		{"POST", "/admin/gc", "m", http.StatusOK},
+		{"POST", "/admin/clock?skew=1m", "m", http.StatusForbidden},
+		{"POST", "/admin/clock?skew=1m", "c", http.StatusOK},
+		{"GET", "/admin/config", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("X-Admin-Token", tt.token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s token=%q: status = %d, want %d", tt.method, tt.path, tt.token, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestAdminLogLevel(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("")
+	defer logging.SetLevel("info")
+
+	tests := []struct {
+		query string
+		want  int
+		level string
+	}{
+		{"level=debug", http.StatusOK, "debug"},
+		{"level=loud", http.StatusBadRequest, "debug"},
+		{"", http.StatusBadRequest, "debug"},
+		{"level=warn", http.StatusOK, "warn"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.SetLogLevel(rec, httptest.NewRequest("POST", "/admin/loglevel?"+tt.query, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%q: status = %d, want %d", tt.query, rec.Code, tt.want)
+		}
+		if got := logging.Level(); got != tt.level {
+			t.Errorf("%q: level = %q, want %q", tt.query, got, tt.level)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.LogLevel(rec, httptest.NewRequest("GET", "/admin/loglevel", nil))
+	var resp api.AdminLogLevelResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Level != "warn" {
+		t.Errorf("GET level = %q, want warn", resp.Level)
+	}
+}
+
+func TestAdminExitCode(t *testing.T) {
+	h, _, _ := newTestAdminHandlers("")
+
+	tests := []struct {
+		method string
+		query  string
+		want   int
+		code   int
+		set    bool
+	}{
+		{"POST", "code=3", http.StatusOK, 3, true},
+		{"POST", "code=256", http.StatusBadRequest, 3, true},
+		{"POST", "", http.StatusBadRequest, 3, true},
+		{"POST", "code=0", http.StatusOK, 0, true},
+		{"DELETE", "", http.StatusOK, -1, false},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, "/admin/exit-code?"+tt.query, nil)
+		if tt.method == "DELETE" {
+			h.ClearExitCode(rec, req)
+		} else {
+			h.SetExitCode(rec, req)
+		}
+		if rec.Code != tt.want {
+			t.Errorf("%s %q: status = %d, want %d", tt.method, tt.query, rec.Code, tt.want)
+		}
+		code, ok := h.lifecycle.ExitCode()
+		if ok != tt.set || (ok && code != tt.code) {
+			t.Errorf("%s %q: ExitCode() = %d, %v; want %d, %v", tt.method, tt.query, code, ok, tt.code, tt.set)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+package handlers
+
+import "golang.org/x/sys/unix"
+
+// affinitySupported reports whether CPU burn threads can be bound to CPUs.
+const affinitySupported = true
+
+// setThreadAffinity binds the calling OS thread to cpu. The caller must have
+// locked its goroutine to the thread.
+func setThreadAffinity(cpu int) error {
+	var set unix.CPUSet
+	set.Set(cpu)
+	return unix.SchedSetaffinity(0, &set)
+}
+
+// allowedCPUs returns the CPUs this process may run on, such as the
+// exclusive cores assigned by the kubelet's static CPU manager policy.
+func allowedCPUs() ([]int, error) {
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &set); err != nil {
+		return nil, err
+	}
+
+	var cpus []int
+	for cpu := 0; len(cpus) < set.Count(); cpu++ {
+		if set.IsSet(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
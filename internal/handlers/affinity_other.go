@@ -0,0 +1,20 @@
+//go:build !linux
+
+package handlers
+
+import "errors"
+
+// affinitySupported reports whether CPU burn threads can be bound to CPUs.
+const affinitySupported = false
+
+var errAffinityUnsupported = errors.New("CPU affinity is only supported on Linux")
+
+// setThreadAffinity is not implemented on this platform.
+func setThreadAffinity(int) error {
+	return errAffinityUnsupported
+}
+
+// allowedCPUs is not implemented on this platform.
+func allowedCPUs() ([]int, error) {
+	return nil, errAffinityUnsupported
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// BrownoutHandlers starts and stops gradual brownouts.
+type BrownoutHandlers struct {
+	enabled  bool
+	authn    *auth.Authenticator
+	guard    *fault.Guard
+	brownout *fault.Brownout
+}
+
+// NewBrownoutHandlers creates handlers for the brownout admin endpoints.
+// Starting a brownout is refused when chaos is disabled, and is subject to
+// guard, which may be nil.
+func NewBrownoutHandlers(enabled bool, authn *auth.Authenticator, guard *fault.Guard, brownout *fault.Brownout) *BrownoutHandlers {
+	return &BrownoutHandlers{enabled: enabled, authn: authn, guard: guard, brownout: brownout}
+}
+
+// Register adds brownout routes to the mux.
+func (h *BrownoutHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/brownout", h.Get)
+	mux.HandleFunc("POST /admin/brownout", h.Start)
+	mux.HandleFunc("DELETE /admin/brownout", h.Stop)
+}
+
+// Get handles GET /admin/brownout.
+func (h *BrownoutHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeStatus(w)
+}
+
+// Start handles POST
+// /admin/brownout?max_latency=D&max_error_rate=R&codes=C[,C...]&ramp=D&hold=D.
+// Latency and error rate rise linearly from zero to their maximums over
+// ramp, then hold there for hold, or until cleared if hold is zero.
+// Starting a brownout requires the chaos role.
+func (h *BrownoutHandlers) Start(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		writeError(w, http.StatusForbidden, errcode.ChaosDisabled, "chaos endpoints are disabled")
+		return
+	}
+	if !authorize(h.authn, w, r, auth.RoleChaos) {
+		return
+	}
+
+	var cfg fault.BrownoutConfig
+	var err error
+	if cfg.MaxLatency, err = parseDuration(r, "max_latency", 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if cfg.MaxErrorRate, err = parseFloat(r, "max_error_rate", 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if v := r.URL.Query().Get("codes"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "codes must be comma-separated integers")
+				return
+			}
+			cfg.Codes = append(cfg.Codes, code)
+		}
+	}
+	if cfg.Ramp, err = parseDuration(r, "ramp", 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if cfg.Hold, err = parseDuration(r, "hold", 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	if !admitChaos(h.guard, w, "brownout") {
+		return
+	}
+	if err := h.brownout.Start(cfg, time.Now()); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	h.writeStatus(w)
+}
+
+// Stop handles DELETE /admin/brownout, ending the brownout at once.
+func (h *BrownoutHandlers) Stop(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+	h.brownout.Stop()
+	h.writeStatus(w)
+}
+
+func (h *BrownoutHandlers) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.brownout.Status(time.Now())); err != nil {
+		slog.Warn("failed to encode brownout response", "error", err)
+	}
+}
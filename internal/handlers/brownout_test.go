@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestBrownoutHandlersDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	NewBrownoutHandlers(false, auth.New("", nil), nil, fault.NewBrownout()).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/brownout?max_latency=1s&ramp=1m", nil))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "CHAOS_DISABLED") {
+		t.Errorf("status = %d, body = %s, want 403 CHAOS_DISABLED", rec.Code, rec.Body)
+	}
+}
+
+func TestBrownoutHandlers(t *testing.T) {
+	mux := http.NewServeMux()
+	NewBrownoutHandlers(true, auth.New("", nil), nil, fault.NewBrownout()).Register(mux)
+
+	for _, tt := range []struct {
+		method     string
+		query      string
+		wantStatus int
+		wantActive bool
+	}{
+		{"GET", "", http.StatusOK, false},
+		{"POST", "?ramp=1m", http.StatusBadRequest, false},
+		{"POST", "?max_latency=1s", http.StatusBadRequest, false},
+		{"POST", "?max_error_rate=0.5&codes=five&ramp=1m", http.StatusBadRequest, false},
+		{"POST", "?max_latency=2s&max_error_rate=0.5&codes=502,503&ramp=1h&hold=30m", http.StatusOK, true},
+		{"GET", "", http.StatusOK, true},
+		{"DELETE", "", http.StatusOK, false},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, "/admin/brownout"+tt.query, nil))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.query, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if rec.Code == http.StatusBadRequest {
+			continue
+		}
+		var resp api.BrownoutStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Active != tt.wantActive {
+			t.Errorf("%s %s: active = %t, want %t", tt.method, tt.query, resp.Active, tt.wantActive)
+		}
+		if tt.method == "POST" && (resp.MaxLatency != "2s" || resp.MaxErrorRate != 0.5 || len(resp.Codes) != 2 || resp.Until == nil) {
+			t.Errorf("POST: response = %+v, want 2s, 0.5 with 2 codes and an end", resp)
+		}
+	}
+}
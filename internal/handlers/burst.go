@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// Bounds on burst measurement.
+const (
+	// maxBurstArrivals bounds the arrivals held; the oldest are overwritten
+	// first.
+	maxBurstArrivals = 100000
+	// defaultBurstWindow is the window stats cover when none is given.
+	defaultBurstWindow = time.Minute
+	// maxBurstWindow bounds the window stats may cover.
+	maxBurstWindow = time.Hour
+)
+
+// BurstHandlers records when requests to /burst arrive and reports the
+// spacing between them, so client-side rate limiters and queue-based
+// smoothing can be validated from the server's side: point the client at
+// /burst, then read /burst/stats.
+type BurstHandlers struct {
+	mu sync.Mutex
+	// arrivals is a ring of arrival times, oldest at next once full
+	arrivals []time.Time
+	next     int
+	seq      int64
+}
+
+// NewBurstHandlers creates handlers for the burst endpoints.
+func NewBurstHandlers() *BurstHandlers {
+	return &BurstHandlers{}
+}
+
+// Register adds burst routes to the mux.
+func (h *BurstHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/burst", h.Burst)
+	mux.HandleFunc("GET /burst/stats", h.Stats)
+	mux.HandleFunc("DELETE /burst/stats", h.Reset)
+}
+
+// Burst handles /burst for any method, recording the request's arrival.
+func (h *BurstHandlers) Burst(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	resp := api.BurstResponse{}
+
+	h.mu.Lock()
+	if n := len(h.arrivals); n > 0 {
+		// next is 0 until the ring is full, so this is the latest either way.
+		gap := now.Sub(h.arrivals[(h.next+n-1)%n])
+		resp.InterArrival = gap.String()
+		metrics.BurstInterArrivalSeconds.Observe(gap.Seconds())
+	}
+	if len(h.arrivals) < maxBurstArrivals {
+		h.arrivals = append(h.arrivals, now)
+	} else {
+		h.arrivals[h.next] = now
+		h.next = (h.next + 1) % maxBurstArrivals
+	}
+	h.seq++
+	resp.Seq = h.seq
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode burst response", "error", err)
+	}
+}
+
+// Stats handles GET /burst/stats?window=D, summarizing the arrivals within
+// window of now (default: 1m).
+func (h *BurstHandlers) Stats(w http.ResponseWriter, r *http.Request) {
+	window, err := parseDuration(r, "window", defaultBurstWindow)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if window <= 0 || window > maxBurstWindow {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("window must be positive and at most %s", maxBurstWindow))
+		return
+	}
+
+	now := time.Now()
+	h.mu.Lock()
+	arrivals := h.windowLocked(now.Add(-window))
+	total := h.seq
+	h.mu.Unlock()
+
+	resp := summarizeArrivals(arrivals)
+	resp.Window = window.String()
+	resp.Total = total
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode burst stats response", "error", err)
+	}
+}
+
+// Reset handles DELETE /burst/stats, forgetting every arrival.
+func (h *BurstHandlers) Reset(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.arrivals = nil
+	h.next = 0
+	h.seq = 0
+	h.mu.Unlock()
+
+	resp := summarizeArrivals(nil)
+	resp.Window = defaultBurstWindow.String()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode burst stats response", "error", err)
+	}
+}
+
+// windowLocked returns the arrivals after cutoff, oldest first.
+func (h *BurstHandlers) windowLocked(cutoff time.Time) []time.Time {
+	ordered := append(slices.Clone(h.arrivals[h.next:]), h.arrivals[:h.next]...)
+	i, _ := slices.BinarySearchFunc(ordered, cutoff, func(t, cutoff time.Time) int {
+		return t.Compare(cutoff)
+	})
+	return ordered[i:]
+}
+
+// summarizeArrivals computes the rate, burstiness, and inter-arrival
+// percentiles of arrivals, which must be oldest first.
+func summarizeArrivals(arrivals []time.Time) api.BurstStats {
+	stats := api.BurstStats{Count: len(arrivals)}
+	if len(arrivals) == 0 {
+		return stats
+	}
+
+	// The busiest second, by a window sliding over the arrivals.
+	for lo, hi := 0, 0; hi < len(arrivals); hi++ {
+		for arrivals[hi].Sub(arrivals[lo]) >= time.Second {
+			lo++
+		}
+		stats.PeakPerSecond = max(stats.PeakPerSecond, hi-lo+1)
+	}
+	if len(arrivals) < 2 {
+		return stats
+	}
+
+	gaps := make([]time.Duration, len(arrivals)-1)
+	var sum float64
+	for i := range gaps {
+		gaps[i] = arrivals[i+1].Sub(arrivals[i])
+		sum += float64(gaps[i])
+	}
+	mean := sum / float64(len(gaps))
+	var variance float64
+	for _, g := range gaps {
+		variance += (float64(g) - mean) * (float64(g) - mean)
+	}
+	variance /= float64(len(gaps))
+
+	span := arrivals[len(arrivals)-1].Sub(arrivals[0])
+	if span > 0 {
+		stats.Rate = float64(len(gaps)) / span.Seconds()
+	}
+	if mean > 0 {
+		stats.CV = math.Sqrt(variance) / mean
+	}
+
+	slices.Sort(gaps)
+	pct := func(p float64) string {
+		return gaps[int(p*float64(len(gaps)-1))].String()
+	}
+	stats.InterArrival = &api.BurstInterArrival{
+		Min:  gaps[0].String(),
+		Mean: time.Duration(mean).String(),
+		P50:  pct(0.50),
+		P90:  pct(0.90),
+		P99:  pct(0.99),
+		Max:  gaps[len(gaps)-1].String(),
+	}
+	return stats
+}
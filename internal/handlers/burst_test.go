@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestBurst(t *testing.T) {
+	h := NewBurstHandlers()
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	for i := 1; i <= 3; i++ {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/burst", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var resp api.BurstResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Seq != int64(i) || (i > 1) != (resp.InterArrival != "") {
+			t.Errorf("request %d: response = %+v", i, resp)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/burst/stats?window=10s", nil))
+	var stats api.BurstStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("failed to parse stats: %v", err)
+	}
+	if stats.Count != 3 || stats.Total != 3 || stats.PeakPerSecond != 3 || stats.Window != "10s" || stats.InterArrival == nil {
+		t.Errorf("stats = %+v, want 3 arrivals in the window", stats)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("DELETE", "/burst/stats", nil))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/burst/stats", nil))
+	stats = api.BurstStats{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("failed to parse stats: %v", err)
+	}
+	if stats.Count != 0 || stats.Total != 0 || stats.InterArrival != nil {
+		t.Errorf("stats after reset = %+v, want none", stats)
+	}
+}
+
+func TestBurstWindow(t *testing.T) {
+	h := NewBurstHandlers()
+	now := time.Now()
+	for i := range 5 {
+		h.arrivals = append(h.arrivals, now.Add(time.Duration(i-4)*time.Minute))
+	}
+	if got := len(h.windowLocked(now.Add(-90 * time.Second))); got != 2 {
+		t.Errorf("arrivals within 90s = %d, want 2", got)
+	}
+
+	// A full ring wraps, with the oldest arrival at next.
+	h.arrivals = []time.Time{now.Add(-time.Minute), now.Add(-3 * time.Minute), now.Add(-2 * time.Minute)}
+	h.next = 1
+	got := h.windowLocked(now.Add(-150 * time.Second))
+	if len(got) != 2 || !got[0].Equal(now.Add(-2*time.Minute)) {
+		t.Errorf("wrapped window = %v, want the two newest arrivals oldest first", got)
+	}
+}
+
+func TestSummarizeArrivals(t *testing.T) {
+	start := time.Now()
+	var even []time.Time
+	for i := range 11 {
+		even = append(even, start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	stats := summarizeArrivals(even)
+	if stats.Count != 11 || stats.PeakPerSecond != 10 || math.Abs(stats.Rate-10) > 0.01 || stats.CV > 0.01 {
+		t.Errorf("even arrivals: stats = %+v, want rate 10, peak 10, cv 0", stats)
+	}
+	if stats.InterArrival.P50 != "100ms" || stats.InterArrival.Max != "100ms" {
+		t.Errorf("even arrivals: inter-arrival = %+v, want 100ms throughout", stats.InterArrival)
+	}
+
+	// Ten arrivals at once, then one nine seconds later.
+	var bursty []time.Time
+	for range 10 {
+		bursty = append(bursty, start)
+	}
+	bursty = append(bursty, start.Add(9*time.Second))
+	stats = summarizeArrivals(bursty)
+	if stats.PeakPerSecond != 10 || stats.CV < 1 {
+		t.Errorf("bursty arrivals: stats = %+v, want peak 10 and cv above 1", stats)
+	}
+}
+
+func TestBurstStatsInvalid(t *testing.T) {
+	h := NewBurstHandlers()
+	for _, q := range []string{"window=abc", "window=0s", "window=2h"} {
+		rec := httptest.NewRecorder()
+		h.Stats(rec, httptest.NewRequest("GET", "/burst/stats?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", q, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
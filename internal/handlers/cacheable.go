@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// maxCacheableSize bounds the body of a cacheable response.
+const maxCacheableSize = 16 << 20
+
+// ServedAtHeader carries when the origin served a /cacheable response, so
+// responses served from a cache can be told apart by their age.
+const ServedAtHeader = "X-Hotpod-Served-At"
+
+// CacheableHandlers provides the /cacheable endpoint, a controllable origin
+// for validating CDN and proxy caches.
+type CacheableHandlers struct {
+	// modified is the default Last-Modified time
+	modified time.Time
+}
+
+// NewCacheableHandlers creates handlers for the cacheable endpoint, whose
+// content is last modified now unless a request says otherwise.
+func NewCacheableHandlers() *CacheableHandlers {
+	return &CacheableHandlers{modified: wallclock.Now().Truncate(time.Second)}
+}
+
+// Register adds cacheable routes to the mux.
+func (h *CacheableHandlers) Register(mux *http.ServeMux) {
+	// GET also matches HEAD.
+	mux.HandleFunc("GET /cacheable", h.Cacheable)
+}
+
+// Cacheable handles GET and HEAD
+// /cacheable?version=V&size=N&cache_control=C&etag=strong|weak|none&last_modified=T&vary=H.
+// The body is derived from version alone, so its ETag changes only when
+// version does. last_modified is an HTTP date, an RFC 3339 time, or none;
+// it defaults to when hotpod started. Conditional and range requests are
+// answered as net/http's ServeContent does, with If-None-Match taking
+// precedence over If-Modified-Since.
+func (h *CacheableHandlers) Cacheable(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	version := q.Get("version")
+	if version == "" {
+		version = "1"
+	}
+
+	size, err := parseSize(r, "size", 1<<10)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if size < 0 || size > maxCacheableSize {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("size must be between 0 and %s", formatSize(maxCacheableSize)))
+		return
+	}
+
+	cacheControl := "public, max-age=60"
+	if q.Has("cache_control") {
+		cacheControl = q.Get("cache_control")
+	}
+	if strings.ContainsAny(cacheControl, "\r\n") {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "cache_control must be a single line")
+		return
+	}
+
+	etagKind := q.Get("etag")
+	switch etagKind {
+	case "":
+		etagKind = "strong"
+	case "strong", "weak", "none":
+	default:
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "etag must be strong, weak, or none")
+		return
+	}
+
+	modified := h.modified
+	switch v := q.Get("last_modified"); v {
+	case "":
+	case "none":
+		modified = time.Time{}
+	default:
+		if modified, err = http.ParseTime(v); err != nil {
+			if modified, err = time.Parse(time.RFC3339, v); err != nil {
+				writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "last_modified must be an HTTP date, an RFC 3339 time, or none")
+				return
+			}
+		}
+	}
+
+	vary := q.Get("vary")
+	if strings.ContainsAny(vary, "\r\n") {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "vary must be a single line")
+		return
+	}
+
+	body := cacheableBody(version, size)
+	if cacheControl != "" {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+	if vary != "" {
+		w.Header().Set("Vary", vary)
+	}
+	if etagKind != "none" {
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+		if etagKind == "weak" {
+			etag = "W/" + etag
+		}
+		w.Header().Set("ETag", etag)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set(ServedAtHeader, wallclock.Now().UTC().Format(time.RFC3339Nano))
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
+
+// cacheableBody returns size bytes of text identifying version.
+func cacheableBody(version string, size int64) []byte {
+	if size == 0 {
+		return nil
+	}
+	line := []byte(fmt.Sprintf("hotpod cacheable content version %s\n", version))
+	return bytes.Repeat(line, int(size)/len(line)+1)[:size]
+}
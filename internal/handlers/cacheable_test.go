@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cacheableRequest(h *CacheableHandlers, query string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/cacheable?"+query, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.Cacheable(rec, req)
+	return rec
+}
+
+func TestCacheable(t *testing.T) {
+	h := NewCacheableHandlers()
+
+	rec := cacheableRequest(h, "size=100&cache_control=private,+max-age=5&vary=Accept-Encoding", nil)
+	if rec.Code != http.StatusOK || rec.Body.Len() != 100 {
+		t.Fatalf("status = %d with %d bytes, want 200 with 100", rec.Code, rec.Body.Len())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "private, max-age=5" {
+		t.Errorf("Cache-Control = %q, want the requested value", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", got)
+	}
+	if !strings.Contains(rec.Body.String(), "version 1") || rec.Header().Get(ServedAtHeader) == "" {
+		t.Errorf("body = %q with headers %v, want version 1 content stamped with the serve time", rec.Body, rec.Header())
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" || rec.Header().Get("Last-Modified") == "" {
+		t.Fatalf("headers = %v, want an ETag and Last-Modified", rec.Header())
+	}
+	if other := cacheableRequest(h, "size=100&version=2", nil).Header().Get("ETag"); other == etag {
+		t.Errorf("ETag for version 2 = %q, want it to differ from version 1", other)
+	}
+	if same := cacheableRequest(h, "size=100", nil).Header().Get("ETag"); same != etag {
+		t.Errorf("ETag on repeat = %q, want %q", same, etag)
+	}
+}
+
+func TestCacheableConditional(t *testing.T) {
+	h := NewCacheableHandlers()
+	first := cacheableRequest(h, "", nil)
+	etag, modified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	later := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	earlier := "Mon, 02 Jan 2006 15:04:05 GMT"
+
+	tests := []struct {
+		name   string
+		query  string
+		header http.Header
+		want   int
+	}{
+		{"matching etag", "", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"matching weak etag", "", http.Header{"If-None-Match": {"W/" + etag}}, http.StatusNotModified},
+		{"stale etag", "version=2", http.Header{"If-None-Match": {etag}}, http.StatusOK},
+		{"not modified since", "", http.Header{"If-Modified-Since": {modified}}, http.StatusNotModified},
+		{"modified since", "", http.Header{"If-Modified-Since": {earlier}}, http.StatusOK},
+		{"etag takes precedence", "", http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {later}}, http.StatusOK},
+		{"no validators", "etag=none&last_modified=none", http.Header{"If-None-Match": {etag}, "If-Modified-Since": {later}}, http.StatusOK},
+		{"explicit last modified", "last_modified=2006-01-02T15:04:05Z", http.Header{"If-Modified-Since": {earlier}}, http.StatusNotModified},
+		{"range", "size=100", http.Header{"Range": {"bytes=0-9"}}, http.StatusPartialContent},
+	}
+	for _, tt := range tests {
+		rec := cacheableRequest(h, tt.query, tt.header)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+
+	if got := cacheableRequest(h, "etag=weak", nil).Header().Get("ETag"); got != "W/"+etag {
+		t.Errorf("weak ETag = %q, want %q", got, "W/"+etag)
+	}
+}
+
+func TestCacheableInvalid(t *testing.T) {
+	h := NewCacheableHandlers()
+
+	for _, q := range []string{"size=x", "size=17Mi", "etag=medium", "last_modified=yesterday", "cache_control=a%0d%0ab"} {
+		if rec := cacheableRequest(h, q, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
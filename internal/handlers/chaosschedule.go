@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// maxChaosScheduleBody bounds POST /admin/chaos/schedule request bodies.
+const maxChaosScheduleBody = 64 << 10
+
+// ChaosScheduleHandlers reads and sets the background chaos schedule.
+type ChaosScheduleHandlers struct {
+	enabled  bool
+	authn    *auth.Authenticator
+	guard    *fault.Guard
+	schedule *fault.ChaosSchedule
+}
+
+// NewChaosScheduleHandlers creates handlers for the chaos schedule admin
+// endpoints. Setting a schedule is refused when chaos is disabled, and is
+// subject to guard, which may be nil.
+func NewChaosScheduleHandlers(enabled bool, authn *auth.Authenticator, guard *fault.Guard, schedule *fault.ChaosSchedule) *ChaosScheduleHandlers {
+	return &ChaosScheduleHandlers{enabled: enabled, authn: authn, guard: guard, schedule: schedule}
+}
+
+// Register adds chaos schedule routes to the mux.
+func (h *ChaosScheduleHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/chaos/schedule", h.Get)
+	mux.HandleFunc("POST /admin/chaos/schedule", h.Set)
+	mux.HandleFunc("DELETE /admin/chaos/schedule", h.Clear)
+}
+
+// Get handles GET /admin/chaos/schedule.
+func (h *ChaosScheduleHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeSchedule(w)
+}
+
+// Set handles POST /admin/chaos/schedule with a JSON schedule body,
+// replacing the schedule and ending any active fault. Scheduling faults
+// requires the chaos role.
+func (h *ChaosScheduleHandlers) Set(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		writeError(w, http.StatusForbidden, errcode.ChaosDisabled, "chaos endpoints are disabled")
+		return
+	}
+	if !authorize(h.authn, w, r, auth.RoleChaos) {
+		return
+	}
+
+	var req api.ChaosSchedule
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxChaosScheduleBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "body must be a JSON chaos schedule: "+err.Error())
+		return
+	}
+	cfg, err := chaosScheduleConfig(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if !admitChaos(h.guard, w, "schedule") {
+		return
+	}
+	h.schedule.Set(cfg)
+
+	slog.Warn("chaos schedule set", "interval", cfg.Interval, "faults", len(cfg.Faults), "windows", req.Windows)
+	events.Record(slog.LevelWarn, events.TypeFault, "chaos schedule set", map[string]any{
+		"interval": cfg.Interval.String(),
+		"faults":   len(cfg.Faults),
+		"windows":  req.Windows,
+	})
+	h.writeSchedule(w)
+}
+
+// Clear handles DELETE /admin/chaos/schedule, disabling the schedule and
+// ending any active fault.
+func (h *ChaosScheduleHandlers) Clear(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	h.schedule.Set(fault.ChaosScheduleConfig{})
+	slog.Info("chaos schedule cleared")
+	events.Record(slog.LevelInfo, events.TypeFault, "chaos schedule cleared", nil)
+	h.writeSchedule(w)
+}
+
+// chaosScheduleConfig converts a JSON schedule, whose durations and windows
+// are strings, to a fault.ChaosScheduleConfig.
+func chaosScheduleConfig(req api.ChaosSchedule) (fault.ChaosScheduleConfig, error) {
+	var cfg fault.ChaosScheduleConfig
+	var err error
+	if cfg.Interval, err = time.ParseDuration(req.Interval); err != nil {
+		return cfg, fmt.Errorf("interval must be a duration like 5m, got %q", req.Interval)
+	}
+	for _, s := range req.Windows {
+		w, err := fault.ParseChaosWindow(s)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Windows = append(cfg.Windows, w)
+	}
+	for i, f := range req.Faults {
+		cf := fault.ChaosFault{Kind: f.Kind, Probability: f.Probability, Rate: f.Rate, Codes: f.Codes}
+		if cf.Duration, err = time.ParseDuration(f.Duration); err != nil {
+			return cfg, fmt.Errorf("faults[%d]: duration must be a duration like 30s, got %q", i, f.Duration)
+		}
+		if f.Delay != "" {
+			if cf.Delay, err = time.ParseDuration(f.Delay); err != nil {
+				return cfg, fmt.Errorf("faults[%d]: delay must be a duration like 500ms, got %q", i, f.Delay)
+			}
+		}
+		cfg.Faults = append(cfg.Faults, cf)
+	}
+	return cfg, nil
+}
+
+func (h *ChaosScheduleHandlers) writeSchedule(w http.ResponseWriter) {
+	cfg := h.schedule.Get()
+	resp := api.AdminChaosScheduleResponse{
+		Enabled:  cfg.Enabled(),
+		Schedule: api.ChaosSchedule{Faults: []api.ChaosScheduleFault{}},
+	}
+	if cfg.Interval > 0 {
+		resp.Schedule.Interval = cfg.Interval.String()
+	}
+	for _, win := range cfg.Windows {
+		resp.Schedule.Windows = append(resp.Schedule.Windows, win.String())
+	}
+	for _, f := range cfg.Faults {
+		sf := api.ChaosScheduleFault{
+			Kind:        f.Kind,
+			Probability: f.Probability,
+			Duration:    f.Duration.String(),
+			Rate:        f.Rate,
+			Codes:       f.Codes,
+		}
+		if f.Delay > 0 {
+			sf.Delay = f.Delay.String()
+		}
+		resp.Schedule.Faults = append(resp.Schedule.Faults, sf)
+	}
+	if f, until, ok := h.schedule.Active(time.Now()); ok {
+		resp.Active = &api.ChaosScheduleActive{Kind: f.Kind, Until: until.UTC()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode chaos schedule response", "error", err)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestChaosScheduleHandlersDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	NewChaosScheduleHandlers(false, auth.New("", nil), nil, fault.NewChaosSchedule()).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/chaos/schedule", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "CHAOS_DISABLED") {
+		t.Errorf("status = %d, body = %s, want 403 CHAOS_DISABLED", rec.Code, rec.Body)
+	}
+}
+
+func TestChaosScheduleHandlers(t *testing.T) {
+	mux := http.NewServeMux()
+	NewChaosScheduleHandlers(true, auth.New("", nil), nil, fault.NewChaosSchedule()).Register(mux)
+
+	empty := api.AdminChaosScheduleResponse{Schedule: api.ChaosSchedule{Faults: []api.ChaosScheduleFault{}}}
+	for _, tt := range []struct {
+		method     string
+		body       string
+		wantStatus int
+		want       api.AdminChaosScheduleResponse
+	}{
+		{"GET", "", http.StatusOK, empty},
+		{
+			"POST",
+			`{"interval":"10m","windows":["09:00-17:00"],"faults":[` +
+				`{"kind":"error-burst","probability":0.1,"duration":"30s","rate":0.5,"codes":[503]},` +
+				`{"kind":"latency-spike","probability":0.2,"duration":"1m","delay":"500ms"}]}`,
+			http.StatusOK,
+			api.AdminChaosScheduleResponse{
+				Enabled: true,
+				Schedule: api.ChaosSchedule{
+					Interval: "10m0s",
+					Windows:  []string{"09:00-17:00"},
+					Faults: []api.ChaosScheduleFault{
+						{Kind: "error-burst", Probability: 0.1, Duration: "30s", Rate: 0.5, Codes: []int{503}},
+						{Kind: "latency-spike", Probability: 0.2, Duration: "1m0s", Delay: "500ms"},
+					},
+				},
+			},
+		},
+		{"POST", `{"interval":"10m","faults":[{"kind":"crash","probability":0.1,"duration":"1m"}]}`, http.StatusBadRequest, api.AdminChaosScheduleResponse{}},
+		{"POST", `{"interval":"10m","faults":[{"kind":"hang","probability":0.6,"duration":"1m"},{"kind":"hang","probability":0.6,"duration":"1m"}]}`, http.StatusBadRequest, api.AdminChaosScheduleResponse{}},
+		{"POST", `{"interval":"10m","windows":["9am-5pm"],"faults":[{"kind":"hang","probability":0.1,"duration":"1m"}]}`, http.StatusBadRequest, api.AdminChaosScheduleResponse{}},
+		{"POST", `{"interval":"soon"}`, http.StatusBadRequest, api.AdminChaosScheduleResponse{}},
+		{"POST", `{"period":"10m"}`, http.StatusBadRequest, api.AdminChaosScheduleResponse{}},
+		{"DELETE", "", http.StatusOK, empty},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, "/admin/chaos/schedule", strings.NewReader(tt.body)))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.body, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp api.AdminChaosScheduleResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if !reflect.DeepEqual(resp, tt.want) {
+			t.Errorf("%s %s: response = %+v, want %+v", tt.method, tt.body, resp, tt.want)
+		}
+	}
+}
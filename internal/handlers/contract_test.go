@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/config"
+	"github.com/ripta/hotpod/internal/contract"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/health"
+	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/queue"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// newContractMux registers every handler the contract suite exercises, with
+// admin endpoints open.
+func newContractMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	cfg := newTestConfig()
+	cfg.IODirName = "hotpod-contract-test"
+	authn := auth.New("", nil)
+	lc := newTestLifecycle()
+	tracker := load.NewTracker(100)
+
+	mux := http.NewServeMux()
+	NewHealthHandlers(lc, health.NewDependencies(), health.NewDelays()).Register(mux)
+	NewInfoHandlers("test", api.InfoBuild{}, lc, cfg).Register(mux)
+	NewEchoHandlers().Register(mux)
+	NewSessionHandlers("pod-a").Register(mux)
+	NewMirrorHandlers().Register(mux)
+	NewBurstHandlers().Register(mux)
+	NewEventsHandlers(auth.New("", nil), events.New(100)).Register(mux)
+
+	NewCPUHandlers(tracker, cfg).Register(mux)
+	NewMemoryHandlers(tracker, cfg).Register(mux)
+	NewIOHandlers(tracker, cfg).Register(mux)
+	work := NewWorkHandlers(tracker, cfg)
+	work.Register(mux)
+	NewWorkProfileHandlers(authn, work.Profiles()).Register(mux)
+	NewLatencyHandlers(tracker).Register(mux)
+	NewQueueHandlers(true, queue.New(100), 1).Register(mux)
+
+	admin, _, _ := newTestAdminHandlers("")
+	admin.Register(mux)
+	NewEndpointHandlers(authn, server.NewEndpointGates(config.EndpointGroups, nil)).Register(mux)
+	NewBrownoutHandlers(true, authn, nil, fault.NewBrownout()).Register(mux)
+	NewHealthDelayHandlers(authn, health.NewDelays()).Register(mux)
+	NewScrapeCostHandlers(authn, metrics.NewScrapeCost()).Register(mux)
+	NewDependencyHandlers(authn, health.NewDependencies()).Register(mux)
+	NewSelftestHandlers(authn, mux, nil, true).Register(mux)
+	return mux
+}
+
+func TestContractSuite(t *testing.T) {
+	mux := newContractMux(t)
+	for _, r := range contract.Run(context.Background(), mux, contract.Suite) {
+		if !r.Passed {
+			t.Errorf("%s (%s %s): %s", r.Name, r.Method, r.Target, r.Error)
+		}
+	}
+}
+
+func TestSelftest(t *testing.T) {
+	mux := newContractMux(t)
+
+	for _, app := range []bool{true, false} {
+		rec := httptest.NewRecorder()
+		NewSelftestHandlers(auth.New("", nil), mux, nil, app).Selftest(rec, httptest.NewRequest("GET", "/admin/selftest", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		if err := contract.Lookup("AdminSelftestResponse").Validate(rec.Body.Bytes()); err != nil {
+			t.Errorf("response does not match its schema: %v", err)
+		}
+		var resp api.AdminSelftestResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if !resp.Passed || len(resp.Checks) != len(contract.Quick(app)) {
+			t.Errorf("app=%t: response = %+v, want every quick check passed", app, resp)
+		}
+	}
+
+	// A route that drifts from its schema fails its check.
+	broken := http.NewServeMux()
+	broken.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":true}`))
+	})
+	rec := httptest.NewRecorder()
+	NewSelftestHandlers(auth.New("", nil), broken, nil, false).Selftest(rec, httptest.NewRequest("GET", "/admin/selftest", nil))
+	var resp api.AdminSelftestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Passed || resp.Checks[0].Passed || resp.Checks[0].Error == "" {
+		t.Errorf("broken healthz: response = %+v, want a failed check", resp)
+	}
+}
+
+func TestSelftestSkipsDisabledGroups(t *testing.T) {
+	var loads atomic.Int32
+	mux := newContractMux(t)
+	target := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cpu" || r.URL.Path == "/memory" {
+			loads.Add(1)
+		}
+		mux.ServeHTTP(w, r)
+	})
+	gates := server.NewEndpointGates(config.EndpointGroups, []string{"cpu", "memory"})
+
+	rec := httptest.NewRecorder()
+	NewSelftestHandlers(auth.New("", nil), target, gates, true).Selftest(rec, httptest.NewRequest("GET", "/admin/selftest", nil))
+	var resp api.AdminSelftestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if !resp.Passed {
+		t.Errorf("response = %+v, want passed with disabled groups skipped", resp)
+	}
+	if n := loads.Load(); n != 0 {
+		t.Errorf("disabled load endpoints called %d times, want 0", n)
+	}
+	skipped := 0
+	for _, c := range resp.Checks {
+		if c.Skipped {
+			skipped++
+		}
+	}
+	// cpu, memory, and the cpu parameter error check.
+	if skipped != 3 {
+		t.Errorf("skipped %d checks, want 3", skipped)
+	}
+}
@@ -5,28 +5,67 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"math"
+	"math/rand/v2"
 	"net/http"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/ripta/hotpod/internal/cgroup"
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 const (
 	intensityLow    = "low"
 	intensityMedium = "medium"
 	intensityHigh   = "high"
+	// intensityCache walks a working set larger than the last-level cache,
+	// stressing memory bandwidth rather than the ALU.
+	intensityCache = "cache"
+	// intensityVector runs dense floating-point multiply-adds, the most
+	// power-hungry kind of work, to provoke frequency scaling and thermal
+	// throttling.
+	intensityVector = "vector"
+
+	// cacheWorkingSet is the array size for intensityCache, shared by all of
+	// a request's goroutines.
+	cacheWorkingSet = 64 << 20
+	// cacheWalkStep is the number of random accesses per iteration.
+	cacheWalkStep = 1024
+
+	// vectorDim is the matrix dimension for intensityVector; three matrices
+	// of this size fit comfortably in L1 and L2, keeping the FPU fed.
+	vectorDim = 32
 )
 
+// errInvalidIntensity is returned for an unknown intensity.
+var errInvalidIntensity = errors.New("intensity must be low, medium, high, cache, or vector")
+
+// validIntensity reports whether intensity names a CPU burn mode.
+func validIntensity(intensity string) bool {
+	switch intensity {
+	case intensityLow, intensityMedium, intensityHigh, intensityCache, intensityVector:
+		return true
+	}
+	return false
+}
+
 // CPUHandlers provides the /cpu endpoint handler.
 type CPUHandlers struct {
 	tracker     *load.Tracker
 	maxDuration time.Duration
+	// cgroupRoot is where throttling statistics are read from
+	cgroupRoot string
 }
 
 // NewCPUHandlers creates handlers for CPU load endpoints.
@@ -34,6 +73,7 @@ func NewCPUHandlers(tracker *load.Tracker, cfg *config.Config) *CPUHandlers {
 	return &CPUHandlers{
 		tracker:     tracker,
 		maxDuration: cfg.MaxCPUDuration,
+		cgroupRoot:  cgroup.DefaultRoot,
 	}
 }
 
@@ -42,42 +82,24 @@ func (h *CPUHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /cpu", h.CPU)
 }
 
-// CPUResponse is the JSON response for /cpu.
-type CPUResponse struct {
-	// RequestedDuration is the duration parameter value
-	RequestedDuration string `json:"requested_duration"`
-	// ActualDuration is how long the operation actually took
-	ActualDuration string `json:"actual_duration"`
-	// Cores is the number of goroutines used for CPU work
-	Cores int `json:"cores"`
-	// Intensity is the intensity level used
-	Intensity string `json:"intensity"`
-	// Iterations is the total number of work iterations completed
-	Iterations int64 `json:"iterations"`
-	// Cancelled indicates if the operation was cancelled
-	Cancelled bool `json:"cancelled,omitempty"`
-	// LimitApplied indicates if the duration was capped by the safety limit
-	LimitApplied bool `json:"limit_applied,omitempty"`
-}
-
 func (h *CPUHandlers) CPU(w http.ResponseWriter, r *http.Request) {
 	duration, err := parseDuration(r, "duration", 1*time.Second)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 	if duration < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "duration must be non-negative")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "duration must be non-negative")
 		return
 	}
 
 	cores, err := parseInt(r, "cores", 1)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 	if cores < 1 {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "cores must be at least 1")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "cores must be at least 1")
 		return
 	}
 
@@ -85,8 +107,14 @@ func (h *CPUHandlers) CPU(w http.ResponseWriter, r *http.Request) {
 	if intensity == "" {
 		intensity = intensityMedium
 	}
-	if intensity != intensityLow && intensity != intensityMedium && intensity != intensityHigh {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "intensity must be low, medium, or high")
+	if !validIntensity(intensity) {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, errInvalidIntensity.Error())
+		return
+	}
+
+	pinning, err := parsePinning(r, cores)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 
@@ -96,18 +124,19 @@ func (h *CPUHandlers) CPU(w http.ResponseWriter, r *http.Request) {
 		limitApplied = true
 	}
 
-	release, err := h.tracker.Acquire(load.OpTypeCPU)
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeCPU)
 	if err != nil {
-		writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "concurrent operation limit exceeded")
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
 		return
 	}
 	defer release()
 
+	before, statErr := cgroup.ReadCPUStat(h.cgroupRoot)
 	start := time.Now()
-	iterations, cancelled := burnCPU(r.Context(), duration, cores, intensity)
+	iterations, cancelled := burnPinnedCPU(r.Context(), duration, cores, intensity, pinning)
 	elapsed := time.Since(start)
 
-	resp := CPUResponse{
+	resp := api.CPUResponse{
 		RequestedDuration: duration.String(),
 		ActualDuration:    elapsed.String(),
 		Cores:             cores,
@@ -115,6 +144,18 @@ func (h *CPUHandlers) CPU(w http.ResponseWriter, r *http.Request) {
 		Iterations:        iterations,
 		Cancelled:         cancelled,
 		LimitApplied:      limitApplied,
+		Pinned:            pinning.lockThread,
+		CPUs:              pinning.cpus,
+	}
+	if statErr == nil {
+		if after, err := cgroup.ReadCPUStat(h.cgroupRoot); err == nil {
+			delta := after.Sub(before)
+			resp.Throttling = &api.CPUThrottling{
+				ThrottledDuration: delta.Throttled.String(),
+				Periods:           delta.Periods,
+				ThrottledPeriods:  delta.ThrottledPeriods,
+			}
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -123,20 +164,137 @@ func (h *CPUHandlers) CPU(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// cpuPinning controls how CPU burn goroutines are scheduled.
+type cpuPinning struct {
+	// lockThread locks each goroutine to its own OS thread
+	lockThread bool
+	// cpus binds the goroutines' threads round-robin to these CPUs
+	cpus []int
+}
+
+// parsePinning parses the pin and cpus parameters for /cpu. cpus is a Linux
+// CPU list such as 2,3 or 0-3 and implies pin; every CPU must be in this
+// process's affinity mask, and there must be no more CPUs than cores.
+func parsePinning(r *http.Request, cores int) (cpuPinning, error) {
+	var pinning cpuPinning
+	if v := r.URL.Query().Get("pin"); v != "" {
+		pin, err := strconv.ParseBool(v)
+		if err != nil {
+			return pinning, errors.New("pin must be true or false")
+		}
+		pinning.lockThread = pin
+	}
+
+	v := r.URL.Query().Get("cpus")
+	if v == "" {
+		return pinning, nil
+	}
+	if !affinitySupported {
+		return pinning, errors.New("cpus is only supported on Linux")
+	}
+	cpus, err := parseCPUList(v)
+	if err != nil {
+		return pinning, err
+	}
+	if len(cpus) > cores {
+		return pinning, fmt.Errorf("cpus lists %d CPUs but only %d cores were requested", len(cpus), cores)
+	}
+
+	allowed, err := allowedCPUs()
+	if err != nil {
+		return pinning, fmt.Errorf("reading CPU affinity: %w", err)
+	}
+	for _, cpu := range cpus {
+		if !slices.Contains(allowed, cpu) {
+			return pinning, fmt.Errorf("cpu %d is not available to this process (allowed: %s)", cpu, formatCPUList(allowed))
+		}
+	}
+
+	pinning.lockThread = true
+	pinning.cpus = cpus
+	return pinning, nil
+}
+
+// maxCPUIndex bounds CPU numbers accepted in CPU lists.
+const maxCPUIndex = 4095
+
+// parseCPUList parses a Linux CPU list such as 0-3,8 into sorted, distinct
+// CPU numbers.
+func parseCPUList(s string) ([]int, error) {
+	var cpus []int
+	for part := range strings.SplitSeq(s, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil || first < 0 || first > maxCPUIndex {
+			return nil, fmt.Errorf("invalid CPU %q in cpus", part)
+		}
+		last := first
+		if isRange {
+			last, err = strconv.Atoi(hi)
+			if err != nil || last < first || last > maxCPUIndex {
+				return nil, fmt.Errorf("invalid CPU range %q in cpus", part)
+			}
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	slices.Sort(cpus)
+	return slices.Compact(cpus), nil
+}
+
+// formatCPUList formats sorted CPU numbers as a Linux CPU list.
+func formatCPUList(cpus []int) string {
+	var parts []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(cpus[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
 // burnCPU performs CPU-intensive work across multiple goroutines.
 // Returns the total iterations completed and whether the operation was cancelled.
 func burnCPU(ctx context.Context, duration time.Duration, cores int, intensity string) (int64, bool) {
+	return burnPinnedCPU(ctx, duration, cores, intensity, cpuPinning{})
+}
+
+// burnPinnedCPU is burnCPU with the goroutines scheduled according to pinning.
+func burnPinnedCPU(ctx context.Context, duration time.Duration, cores int, intensity string, pinning cpuPinning) (int64, bool) {
 	var totalIterations atomic.Int64
 	var wg sync.WaitGroup
 
+	var workingSet []uint64
+	if intensity == intensityCache {
+		workingSet = newCacheWorkingSet()
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, duration)
 	defer cancel()
 
-	for range cores {
+	for i := range cores {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			iterations := cpuWork(ctx, intensity)
+			if pinning.lockThread {
+				runtime.LockOSThread()
+				if len(pinning.cpus) == 0 {
+					defer runtime.UnlockOSThread()
+				} else if err := setThreadAffinity(pinning.cpus[i%len(pinning.cpus)]); err != nil {
+					slog.Warn("failed to set CPU affinity", "cpu", pinning.cpus[i%len(pinning.cpus)], "error", err)
+				}
+				// With an affinity set, the goroutine exits still locked so the
+				// runtime discards the thread rather than reusing its mask.
+			}
+			iterations := cpuWork(ctx, intensity, workingSet)
 			totalIterations.Add(iterations)
 		}()
 	}
@@ -147,9 +305,9 @@ func burnCPU(ctx context.Context, duration time.Duration, cores int, intensity s
 	return totalIterations.Load(), cancelled
 }
 
-// cpuWork performs CPU-intensive work until context is done.
-// Returns the number of iterations completed.
-func cpuWork(ctx context.Context, intensity string) int64 {
+// cpuWork performs CPU-intensive work until context is done. workingSet is
+// only used by intensityCache. Returns the number of iterations completed.
+func cpuWork(ctx context.Context, intensity string, workingSet []uint64) int64 {
 	var iterations int64
 
 	switch intensity {
@@ -192,7 +350,81 @@ func cpuWork(ctx context.Context, intensity string) int64 {
 				iterations++
 			}
 		}
+	case intensityCache:
+		return cacheWork(ctx, workingSet)
+	case intensityVector:
+		return vectorWork(ctx)
 	}
 
 	return iterations
 }
+
+// newCacheWorkingSet allocates the array walked by intensityCache. Every page
+// is written so reads hit memory rather than the shared zero page.
+func newCacheWorkingSet() []uint64 {
+	data := make([]uint64, cacheWorkingSet/8)
+	for i := range data {
+		data[i] = uint64(i)
+	}
+	return data
+}
+
+// cacheWork reads random words of data until ctx is done, so nearly every
+// access misses the CPU caches. len(data) must be a power of two. Returns the
+// number of iterations of cacheWalkStep accesses.
+func cacheWork(ctx context.Context, data []uint64) int64 {
+	var iterations int64
+	var sum uint64
+	mask := uint64(len(data) - 1)
+
+	// xorshift64 is cheap enough that the loads, not the index
+	// computation, dominate.
+	x := rand.Uint64() | 1
+	for {
+		select {
+		case <-ctx.Done():
+			return iterations
+		default:
+			for range cacheWalkStep {
+				x ^= x << 13
+				x ^= x >> 7
+				x ^= x << 17
+				sum += data[x&mask]
+			}
+			iterations++
+		}
+	}
+}
+
+// vectorWork multiplies small dense matrices until ctx is done. The inner loop
+// is a chain of fused multiply-adds over contiguous rows, which the compiler
+// lowers to hardware FMA instructions on CPUs that have them. Products
+// accumulate into c across iterations, growing only linearly, so the values
+// stay finite. Returns the number of matrix multiplications completed.
+func vectorWork(ctx context.Context) int64 {
+	var iterations int64
+	var a, b, c [vectorDim][vectorDim]float64
+	for i := range vectorDim {
+		for j := range vectorDim {
+			a[i][j] = rand.Float64()
+			b[i][j] = rand.Float64()
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return iterations
+		default:
+			for i := range vectorDim {
+				for k := range vectorDim {
+					aik := a[i][k]
+					for j := range vectorDim {
+						c[i][j] = math.FMA(aik, b[k][j], c[i][j])
+					}
+				}
+			}
+			iterations++
+		}
+	}
+}
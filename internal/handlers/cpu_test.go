@@ -3,13 +3,18 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 func testConfig() *config.Config {
@@ -40,7 +45,7 @@ func TestCPUDefault(t *testing.T) {
 		t.Errorf("elapsed = %v, want >= 1s (default duration)", elapsed)
 	}
 
-	var resp CPUResponse
+	var resp api.CPUResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -70,7 +75,7 @@ func TestCPUCustomParams(t *testing.T) {
 		t.Errorf("elapsed = %v, want ~100ms", elapsed)
 	}
 
-	var resp CPUResponse
+	var resp api.CPUResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -86,7 +91,7 @@ func TestCPUIntensityLevels(t *testing.T) {
 	tracker := load.NewTracker(100)
 	h := NewCPUHandlers(tracker, testConfig())
 
-	levels := []string{"low", "medium", "high"}
+	levels := []string{"low", "medium", "high", "cache", "vector"}
 	for _, level := range levels {
 		req := httptest.NewRequest("GET", "/cpu?duration=50ms&intensity="+level, nil)
 		rec := httptest.NewRecorder()
@@ -97,7 +102,7 @@ func TestCPUIntensityLevels(t *testing.T) {
 			t.Errorf("intensity=%s: status = %d, want %d", level, rec.Code, http.StatusOK)
 		}
 
-		var resp CPUResponse
+		var resp api.CPUResponse
 		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 			t.Fatalf("intensity=%s: failed to parse response: %v", level, err)
 		}
@@ -189,7 +194,7 @@ func TestCPUCancellation(t *testing.T) {
 		t.Error("handler did not return after cancellation")
 	}
 
-	var resp CPUResponse
+	var resp api.CPUResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -217,7 +222,7 @@ func TestCPUMaxDurationLimit(t *testing.T) {
 		t.Errorf("elapsed = %v, want <= 300ms (limit should cap at 100ms)", elapsed)
 	}
 
-	var resp CPUResponse
+	var resp api.CPUResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -241,3 +246,113 @@ func TestCPURegister(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 }
+
+func TestCPUThrottling(t *testing.T) {
+	h := NewCPUHandlers(load.NewTracker(100), testConfig())
+
+	h.cgroupRoot = t.TempDir()
+	rec := httptest.NewRecorder()
+	h.CPU(rec, httptest.NewRequest("GET", "/cpu?duration=10ms", nil))
+	var resp api.CPUResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Throttling != nil {
+		t.Errorf("response.Throttling = %+v without cgroup statistics, want nil", resp.Throttling)
+	}
+
+	h.cgroupRoot = t.TempDir()
+	stat := "nr_periods 10\nnr_throttled 2\nthrottled_usec 5000\n"
+	if err := os.WriteFile(filepath.Join(h.cgroupRoot, "cpu.stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	h.CPU(rec, httptest.NewRequest("GET", "/cpu?duration=10ms", nil))
+	resp = api.CPUResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	want := api.CPUThrottling{ThrottledDuration: "0s"}
+	if resp.Throttling == nil || *resp.Throttling != want {
+		t.Errorf("response.Throttling = %+v, want %+v", resp.Throttling, want)
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{in: "3", want: []int{3}},
+		{in: "0-3", want: []int{0, 1, 2, 3}},
+		{in: "6,0-1,1", want: []int{0, 1, 6}},
+		{in: "", wantErr: true},
+		{in: "a", wantErr: true},
+		{in: "3-1", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "0-99999", wantErr: true},
+	} {
+		got, err := parseCPUList(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCPUList(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseCPUList(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+		if !tt.wantErr {
+			if again, _ := parseCPUList(formatCPUList(got)); !slices.Equal(again, got) {
+				t.Errorf("formatCPUList(%v) = %q does not round-trip", got, formatCPUList(got))
+			}
+		}
+	}
+
+	if got := formatCPUList([]int{0, 1, 2, 5, 7, 8}); got != "0-2,5,7-8" {
+		t.Errorf("formatCPUList() = %q, want 0-2,5,7-8", got)
+	}
+}
+
+func TestCPUPinning(t *testing.T) {
+	h := NewCPUHandlers(load.NewTracker(100), testConfig())
+
+	rec := httptest.NewRecorder()
+	h.CPU(rec, httptest.NewRequest("GET", "/cpu?duration=10ms&cores=2&pin=true", nil))
+	var resp api.CPUResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if !resp.Pinned || resp.CPUs != nil || resp.Iterations == 0 {
+		t.Errorf("response = %+v, want pinned without CPUs", resp)
+	}
+
+	for _, query := range []string{"pin=maybe", "cpus=x", "cores=1&cpus=0-1", "cpus=4095"} {
+		rec := httptest.NewRecorder()
+		h.CPU(rec, httptest.NewRequest("GET", "/cpu?duration=10ms&"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	if !affinitySupported {
+		return
+	}
+	allowed, err := allowedCPUs()
+	if err != nil {
+		t.Fatalf("allowedCPUs() error = %v", err)
+	}
+	rec = httptest.NewRecorder()
+	h.CPU(rec, httptest.NewRequest("GET", fmt.Sprintf("/cpu?duration=10ms&cores=2&cpus=%d", allowed[0]), nil))
+	resp = api.CPUResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if !resp.Pinned || !slices.Equal(resp.CPUs, allowed[:1]) {
+		t.Errorf("response = %+v, want pinned to CPU %d", resp, allowed[0])
+	}
+
+	// The pinned threads exited, so the process's affinity is unchanged.
+	if after, _ := allowedCPUs(); !slices.Equal(after, allowed) {
+		t.Errorf("allowedCPUs() = %v after pinning, want %v", after, allowed)
+	}
+}
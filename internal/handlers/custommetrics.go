@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/custommetrics"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// CustomMetricsHandlers reports and adjusts the values served through the
+// custom metrics API.
+type CustomMetricsHandlers struct {
+	authn   *auth.Authenticator
+	local   *custommetrics.Local
+	podName string
+}
+
+// NewCustomMetricsHandlers creates handlers for the custom metrics endpoints.
+func NewCustomMetricsHandlers(authn *auth.Authenticator, local *custommetrics.Local, podName string) *CustomMetricsHandlers {
+	return &CustomMetricsHandlers{authn: authn, local: local, podName: podName}
+}
+
+// Register adds custom metrics routes to the mux.
+func (h *CustomMetricsHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+custommetrics.LocalPath, h.Values)
+	mux.HandleFunc("POST /admin/custom-metrics", h.Set)
+}
+
+// Values handles GET /custom-metrics, reporting this pod's values. Peers call
+// it to aggregate metrics across the fleet, so it is not authenticated.
+func (h *CustomMetricsHandlers) Values(w http.ResponseWriter, r *http.Request) {
+	h.writeValues(w)
+}
+
+// Set handles POST /admin/custom-metrics?synthetic_utilization=0.8.
+func (h *CustomMetricsHandlers) Set(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	v := r.URL.Query().Get(custommetrics.MetricSyntheticUtilization)
+	if v == "" {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "synthetic_utilization is required")
+		return
+	}
+	utilization, err := strconv.ParseFloat(v, 64)
+	if err != nil || utilization < 0 || math.IsInf(utilization, 0) || math.IsNaN(utilization) {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "synthetic_utilization must be a non-negative number")
+		return
+	}
+
+	h.local.SetUtilization(utilization)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "synthetic utilization set", map[string]any{
+		"synthetic_utilization": utilization,
+	})
+	h.writeValues(w)
+}
+
+func (h *CustomMetricsHandlers) writeValues(w http.ResponseWriter) {
+	resp := api.CustomMetricsResponse{Pod: h.podName, Metrics: h.local.Values()}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode custom metrics response", "error", err)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/custommetrics"
+	"github.com/ripta/hotpod/internal/fleet"
+	"github.com/ripta/hotpod/internal/queue"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestCustomMetricsSet(t *testing.T) {
+	q := queue.New(10)
+	local := custommetrics.NewLocal(q)
+	mux := http.NewServeMux()
+	NewCustomMetricsHandlers(auth.New("", nil), local, "hotpod-0").Register(mux)
+
+	for _, tt := range []struct {
+		query      string
+		wantStatus int
+	}{
+		{"", http.StatusBadRequest},
+		{"synthetic_utilization=high", http.StatusBadRequest},
+		{"synthetic_utilization=-1", http.StatusBadRequest},
+		{"synthetic_utilization=NaN", http.StatusBadRequest},
+		{"synthetic_utilization=0.75", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/custom-metrics?"+tt.query, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%q: status = %d, want %d", tt.query, rec.Code, tt.wantStatus)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/custom-metrics", nil))
+	var resp api.CustomMetricsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Pod != "hotpod-0" || resp.Metrics[custommetrics.MetricSyntheticUtilization] != 0.75 || resp.Metrics[custommetrics.MetricQueueDepth] != 0 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+type staticDiscoverer []fleet.Peer
+
+func (d staticDiscoverer) Peers(ctx context.Context) ([]fleet.Peer, error) {
+	return d, nil
+}
+
+// TestCustomMetricsSplitListeners runs a peer with separate traffic and
+// management listeners and aggregates its metrics through the management
+// port, which is the fleet port default when a management port is set.
+func TestCustomMetricsSplitListeners(t *testing.T) {
+	peerLocal := custommetrics.NewLocal(queue.New(10))
+	peerLocal.SetUtilization(0.25)
+	mux := http.NewServeMux()
+	NewCustomMetricsHandlers(auth.New("", nil), peerLocal, "hotpod-1").Register(mux)
+
+	traffic := httptest.NewServer(server.SplitManagement(mux, false))
+	t.Cleanup(traffic.Close)
+	mgmt := httptest.NewServer(server.SplitManagement(mux, true))
+	t.Cleanup(mgmt.Close)
+
+	resp, err := http.Get(traffic.URL + custommetrics.LocalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("traffic listener status = %d, want 404", resp.StatusCode)
+	}
+
+	d := staticDiscoverer{{Name: "hotpod-1", Addr: strings.TrimPrefix(mgmt.URL, "http://")}}
+	h := custommetrics.NewServer(custommetrics.NewLocal(queue.New(10)), "hotpod-0", "default", d, "http").Handler()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/apis/custom.metrics.k8s.io/v1beta2/namespaces/default/pods/hotpod-1/synthetic_utilization", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), `"value":"250m"`) {
+		t.Errorf("body = %s, want hotpod-1 at 250m", rec.Body)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/health"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// maxDependencyBody bounds POST /admin/dependencies request bodies.
+const maxDependencyBody = 4 << 10
+
+// DependencyHandlers lists and toggles the synthetic dependency checks that
+// gate /readyz.
+type DependencyHandlers struct {
+	authn        *auth.Authenticator
+	dependencies *health.Dependencies
+}
+
+// NewDependencyHandlers creates handlers for the dependency admin endpoints.
+func NewDependencyHandlers(authn *auth.Authenticator, deps *health.Dependencies) *DependencyHandlers {
+	return &DependencyHandlers{authn: authn, dependencies: deps}
+}
+
+// Register adds dependency routes to the mux.
+func (h *DependencyHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/dependencies", h.List)
+	mux.HandleFunc("POST /admin/dependencies", h.Set)
+	mux.HandleFunc("DELETE /admin/dependencies/{name}", h.Delete)
+}
+
+// List handles GET /admin/dependencies.
+func (h *DependencyHandlers) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeDependencies(w)
+}
+
+// Set handles POST /admin/dependencies with a JSON check body, adding a
+// check or toggling the one of the same name.
+func (h *DependencyHandlers) Set(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	var check api.DependencyCheck
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDependencyBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&check); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "body must be a JSON dependency check: "+err.Error())
+		return
+	}
+	if err := h.dependencies.Set(check); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	level := slog.LevelInfo
+	if check.Failing {
+		level = slog.LevelWarn
+	}
+	events.Record(level, events.TypeAdmin, "dependency check set", map[string]any{
+		"dependency": check.Name,
+		"failing":    check.Failing,
+	})
+	h.writeDependencies(w)
+}
+
+// Delete handles DELETE /admin/dependencies/{name}.
+func (h *DependencyHandlers) Delete(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	name := r.PathValue("name")
+	if !h.dependencies.Delete(name) {
+		writeError(w, http.StatusNotFound, errcode.DependencyNotFound, "no dependency check named "+name)
+		return
+	}
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "dependency check deleted", map[string]any{
+		"dependency": name,
+	})
+	h.writeDependencies(w)
+}
+
+func (h *DependencyHandlers) writeDependencies(w http.ResponseWriter) {
+	checks := h.dependencies.List()
+	resp := api.DependenciesResponse{Count: len(checks), Checks: checks}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode dependencies response", "error", err)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/health"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestDependencyHandlers(t *testing.T) {
+	deps := health.NewDependencies()
+	mux := http.NewServeMux()
+	NewDependencyHandlers(auth.New("", nil), deps).Register(mux)
+
+	for _, tt := range []struct {
+		method      string
+		path        string
+		body        string
+		wantStatus  int
+		wantFailing int
+	}{
+		{"GET", "/admin/dependencies", "", http.StatusOK, 0},
+		{"POST", "/admin/dependencies", `{"name":"db","failing":true,"message":"connection refused"}`, http.StatusOK, 1},
+		{"POST", "/admin/dependencies", `{"name":"cache"}`, http.StatusOK, 1},
+		{"POST", "/admin/dependencies", `{"name":"cache","failing":true}`, http.StatusOK, 2},
+		{"POST", "/admin/dependencies", `{"name":"Bad Name"}`, http.StatusBadRequest, 0},
+		{"POST", "/admin/dependencies", `{"name":"db","latency":"1s"}`, http.StatusBadRequest, 0},
+		{"DELETE", "/admin/dependencies/cache", "", http.StatusOK, 1},
+		{"DELETE", "/admin/dependencies/cache", "", http.StatusNotFound, 0},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s %s: status = %d, want %d: %s", tt.method, tt.path, tt.body, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp api.DependenciesResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		failing := 0
+		for _, c := range resp.Checks {
+			if c.Failing {
+				failing++
+			}
+		}
+		if failing != tt.wantFailing {
+			t.Errorf("%s %s %s: failing = %d, want %d", tt.method, tt.path, tt.body, failing, tt.wantFailing)
+		}
+	}
+}
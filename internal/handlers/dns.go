@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+const (
+	maxDNSCount       = 10000
+	maxDNSConcurrency = 100
+	// maxDNSRate bounds lookups started per second, keeping the pacing
+	// interval positive.
+	maxDNSRate = 1e6
+	// maxDNSAnswers bounds the sample of answers included in the response.
+	maxDNSAnswers = 10
+)
+
+// dnsTypes lists the record types accepted by the type parameter. "host"
+// resolves A and AAAA records together, as most clients do.
+var dnsTypes = []string{"host", "a", "aaaa", "cname", "mx", "ns", "srv", "txt"}
+
+// lookupFunc performs one DNS lookup of the given record type.
+type lookupFunc func(ctx context.Context, res *net.Resolver, qtype, name string) ([]string, error)
+
+// DNSHandlers provides the /dns endpoint handler.
+type DNSHandlers struct {
+	tracker *load.Tracker
+	lookup  lookupFunc
+}
+
+// NewDNSHandlers creates handlers for DNS load endpoints.
+func NewDNSHandlers(tracker *load.Tracker) *DNSHandlers {
+	return &DNSHandlers{tracker: tracker, lookup: lookupDNS}
+}
+
+// Register adds DNS load routes to the mux.
+func (h *DNSHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /dns", h.DNS)
+}
+
+// DNS handles GET /dns, performing count lookups of name at up to rate per
+// second. fqdn=true appends a trailing dot so the resolv.conf search list and
+// ndots are bypassed; resolver=go uses Go's built-in resolver, bypassing libc
+// and any nscd cache; server=host:port queries that server directly instead
+// of the configured nameservers (e.g. to bypass NodeLocal DNSCache).
+func (h *DNSHandlers) DNS(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	name := q.Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "name is required")
+		return
+	}
+
+	qtype := strings.ToLower(q.Get("type"))
+	if qtype == "" {
+		qtype = "host"
+	}
+	if !slices.Contains(dnsTypes, qtype) {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "type must be one of: "+strings.Join(dnsTypes, ", "))
+		return
+	}
+
+	count, err := parseInt(r, "count", 10)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if count < 1 || count > maxDNSCount {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("count must be between 1 and %d", maxDNSCount))
+		return
+	}
+
+	rate, err := parseFloat(r, "rate", 0)
+	if err != nil || !(rate >= 0 && rate <= maxDNSRate) {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("rate must be between 0 and %g", float64(maxDNSRate)))
+		return
+	}
+
+	concurrency, err := parseInt(r, "concurrency", 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if concurrency < 1 || concurrency > maxDNSConcurrency {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("concurrency must be between 1 and %d", maxDNSConcurrency))
+		return
+	}
+
+	timeout, err := parseDuration(r, "timeout", 5*time.Second)
+	if err != nil || timeout <= 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "timeout must be a positive duration")
+		return
+	}
+
+	if fqdn := q.Get("fqdn"); fqdn != "" {
+		b, err := strconv.ParseBool(fqdn)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "fqdn must be true or false")
+			return
+		}
+		if b && !strings.HasSuffix(name, ".") {
+			name += "."
+		}
+	}
+
+	resolverName := q.Get("resolver")
+	if resolverName == "" {
+		resolverName = "system"
+	}
+	if resolverName != "system" && resolverName != "go" {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "resolver must be system or go")
+		return
+	}
+
+	server := q.Get("server")
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+	}
+
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeDNS)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
+		return
+	}
+	defer release()
+
+	res := newResolver(resolverName == "go", server)
+	start := time.Now()
+	resp := h.run(r.Context(), res, qtype, name, count, rate, concurrency, timeout)
+	resp.Resolver = resolverName
+	resp.Server = server
+	resp.ActualDuration = time.Since(start).String()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode dns response", "error", err)
+	}
+}
+
+// run performs the lookups with the given concurrency, pacing starts at rate
+// per second when rate is positive.
+func (h *DNSHandlers) run(ctx context.Context, res *net.Resolver, qtype, name string, count int, rate float64, concurrency int, timeout time.Duration) api.DNSResponse {
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		resp      = api.DNSResponse{Name: name, Type: qtype, Errors: map[string]int{}}
+	)
+
+	jobs := make(chan struct{})
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				lctx, cancel := context.WithTimeout(ctx, timeout)
+				start := time.Now()
+				answers, err := h.lookup(lctx, res, qtype, name)
+				elapsed := time.Since(start)
+				cancel()
+
+				outcome := "success"
+				if err != nil {
+					outcome = classifyDNSError(err)
+				}
+				metrics.DNSLookupDuration.WithLabelValues(qtype, outcome).Observe(elapsed.Seconds())
+
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				if err != nil {
+					resp.Failed++
+					resp.Errors[outcome]++
+				} else {
+					resp.Succeeded++
+					resp.Answers = answers[:min(len(answers), maxDNSAnswers)]
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var tick <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(max(time.Duration(float64(time.Second)/rate), time.Nanosecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+dispatch:
+	for i := range count {
+		if i > 0 && tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				resp.Cancelled = true
+				break dispatch
+			}
+		}
+		select {
+		case jobs <- struct{}{}:
+		case <-ctx.Done():
+			resp.Cancelled = true
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	resp.Count = len(latencies)
+	resp.Latency = summarizeLatencies(latencies)
+	if len(resp.Errors) == 0 {
+		resp.Errors = nil
+	}
+	return resp
+}
+
+// newResolver returns a resolver that optionally uses the pure Go
+// implementation and sends all queries to server.
+func newResolver(preferGo bool, server string) *net.Resolver {
+	if server == "" {
+		return &net.Resolver{PreferGo: preferGo}
+	}
+	var d net.Dialer
+	return &net.Resolver{
+		// A custom Dial is only honored by the Go resolver.
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+func lookupDNS(ctx context.Context, res *net.Resolver, qtype, name string) ([]string, error) {
+	switch qtype {
+	case "a", "aaaa":
+		network := "ip4"
+		if qtype == "aaaa" {
+			network = "ip6"
+		}
+		ips, err := res.LookupIP(ctx, network, name)
+		out := make([]string, len(ips))
+		for i, ip := range ips {
+			out[i] = ip.String()
+		}
+		return out, err
+	case "cname":
+		cname, err := res.LookupCNAME(ctx, name)
+		return []string{cname}, err
+	case "mx":
+		mxs, err := res.LookupMX(ctx, name)
+		out := make([]string, len(mxs))
+		for i, mx := range mxs {
+			out[i] = fmt.Sprintf("%d %s", mx.Pref, mx.Host)
+		}
+		return out, err
+	case "ns":
+		nss, err := res.LookupNS(ctx, name)
+		out := make([]string, len(nss))
+		for i, ns := range nss {
+			out[i] = ns.Host
+		}
+		return out, err
+	case "srv":
+		_, srvs, err := res.LookupSRV(ctx, "", "", name)
+		out := make([]string, len(srvs))
+		for i, srv := range srvs {
+			out[i] = fmt.Sprintf("%d %d %d %s", srv.Priority, srv.Weight, srv.Port, srv.Target)
+		}
+		return out, err
+	case "txt":
+		return res.LookupTXT(ctx, name)
+	default:
+		return res.LookupHost(ctx, name)
+	}
+}
+
+// classifyDNSError maps a lookup error to a coarse outcome label.
+func classifyDNSError(err error) string {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return "not_found"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &dnsErr) && dnsErr.IsTimeout:
+		return "timeout"
+	case errors.As(err, &dnsErr) && dnsErr.IsTemporary:
+		return "temporary"
+	default:
+		return "error"
+	}
+}
+
+func summarizeLatencies(ds []time.Duration) api.DNSLatency {
+	if len(ds) == 0 {
+		return api.DNSLatency{}
+	}
+	sorted := slices.Clone(ds)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	pct := func(p float64) string {
+		return sorted[int(p*float64(len(sorted)-1))].String()
+	}
+	return api.DNSLatency{
+		Min:  sorted[0].String(),
+		Mean: (total / time.Duration(len(sorted))).String(),
+		P50:  pct(0.50),
+		P90:  pct(0.90),
+		P99:  pct(0.99),
+		Max:  sorted[len(sorted)-1].String(),
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestDNSLookups(t *testing.T) {
+	var calls atomic.Int32
+	var gotName atomic.Value
+	h := NewDNSHandlers(load.NewTracker(0))
+	h.lookup = func(ctx context.Context, res *net.Resolver, qtype, name string) ([]string, error) {
+		gotName.Store(name)
+		if calls.Add(1)%2 == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	h.DNS(rec, httptest.NewRequest("GET", "/dns?name=svc.ns&count=10&concurrency=3&fqdn=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var resp api.DNSResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Count != 10 || resp.Succeeded != 5 || resp.Failed != 5 {
+		t.Errorf("count/succeeded/failed = %d/%d/%d, want 10/5/5", resp.Count, resp.Succeeded, resp.Failed)
+	}
+	if resp.Errors["not_found"] != 5 {
+		t.Errorf("errors = %v", resp.Errors)
+	}
+	if gotName.Load() != "svc.ns." || resp.Name != "svc.ns." {
+		t.Errorf("looked up %q, want trailing dot", gotName.Load())
+	}
+	if resp.Latency.P99 == "" || len(resp.Answers) != 1 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestDNSInvalidParams(t *testing.T) {
+	h := NewDNSHandlers(load.NewTracker(0))
+
+	for _, q := range []string{
+		"",
+		"name=x&type=ptr",
+		"name=x&count=0",
+		"name=x&rate=-1",
+		"name=x&rate=1e10",
+		"name=x&rate=inf",
+		"name=x&rate=NaN",
+		"name=x&concurrency=1000",
+		"name=x&resolver=libc",
+		"name=x&fqdn=maybe",
+	} {
+		rec := httptest.NewRecorder()
+		h.DNS(rec, httptest.NewRequest("GET", "/dns?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
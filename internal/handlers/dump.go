@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	runtimepprof "runtime/pprof"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// dumpExtensions maps each dump kind to the extension of its file.
+// Goroutine dumps are text stack traces; heap dumps are pprof profiles.
+var dumpExtensions = map[string]string{
+	"goroutine": "txt",
+	"heap":      "pb.gz",
+}
+
+// DumpHandlers captures goroutine and heap dumps on demand, for debugging
+// long-running experiments without exec access.
+type DumpHandlers struct {
+	authn *auth.Authenticator
+	// dir is where dumps are written (empty = returned in the response)
+	dir string
+	// maxSize truncates each dump (0 = unlimited)
+	maxSize int64
+	// maxFiles is how many dump files are kept in dir (0 = unlimited)
+	maxFiles int
+	// mu serializes dumps, so pruning sees every file written
+	mu sync.Mutex
+}
+
+// NewDumpHandlers creates handlers for on-demand dumps written to dir.
+func NewDumpHandlers(authn *auth.Authenticator, dir string, maxSize int64, maxFiles int) *DumpHandlers {
+	return &DumpHandlers{authn: authn, dir: dir, maxSize: maxSize, maxFiles: maxFiles}
+}
+
+// Register adds dump routes to the mux.
+func (h *DumpHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/dump", h.Dump)
+}
+
+// Dump handles POST /admin/dump?kinds=goroutine,heap&inline=true. Goroutine
+// dumps hold every goroutine's full stack, as a SIGQUIT prints them, and heap
+// dumps are pprof profiles for go tool pprof. Dumps are written to the dump
+// directory, or returned in the response with inline=true or when no
+// directory is configured.
+func (h *DumpHandlers) Dump(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	kinds := []string{"goroutine", "heap"}
+	if v := r.URL.Query().Get("kinds"); v != "" {
+		kinds = nil
+		for _, kind := range strings.Split(v, ",") {
+			kind = strings.TrimSpace(kind)
+			if _, ok := dumpExtensions[kind]; !ok {
+				writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("kinds must be goroutine, heap, or both, got %q", kind))
+				return
+			}
+			if !slices.Contains(kinds, kind) {
+				kinds = append(kinds, kind)
+			}
+		}
+	}
+
+	inline := h.dir == ""
+	if v := r.URL.Query().Get("inline"); v != "" {
+		var err error
+		if inline, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "inline must be a boolean")
+			return
+		}
+	}
+	if !inline && h.dir == "" {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "no dump directory is configured; set HOTPOD_DUMP_DIR or use inline=true")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stamp := wallclock.Now().UTC().Format("20060102T150405.000Z")
+	resp := api.AdminDumpResponse{Dumps: make([]api.Dump, 0, len(kinds))}
+	for _, kind := range kinds {
+		data, truncated, err := captureDump(kind, h.maxSize)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, errcode.ProfileFailed, err.Error())
+			return
+		}
+
+		d := api.Dump{Kind: kind, Size: len(data), Truncated: truncated}
+		if inline {
+			d.Data = data
+		} else {
+			d.Path = filepath.Join(h.dir, fmt.Sprintf("hotpod-%s-%s.%s", kind, stamp, dumpExtensions[kind]))
+			if err := writeDump(d.Path, data); err != nil {
+				writeError(w, http.StatusInternalServerError, errcode.ProfileFailed, err.Error())
+				return
+			}
+		}
+		resp.Dumps = append(resp.Dumps, d)
+	}
+	if !inline {
+		h.prune()
+	}
+
+	slog.Info("dumps captured", "kinds", kinds, "inline", inline)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "dumps captured", map[string]any{
+		"kinds":  kinds,
+		"inline": inline,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode dump response", "error", err)
+	}
+}
+
+// captureDump renders a dump of kind, truncated to maxSize when positive.
+func captureDump(kind string, maxSize int64) (data []byte, truncated bool, err error) {
+	debug := 0
+	if kind == "goroutine" {
+		debug = 2
+	}
+	var buf bytes.Buffer
+	if err := runtimepprof.Lookup(kind).WriteTo(&buf, debug); err != nil {
+		return nil, false, fmt.Errorf("failed to capture %s dump: %w", kind, err)
+	}
+	data = buf.Bytes()
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return data[:maxSize], true, nil
+	}
+	return data, false, nil
+}
+
+// writeDump writes data to path, creating its directory if needed. Dumps
+// can hold request data, so they are readable only by the owner.
+func writeDump(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create dump directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write dump: %w", err)
+	}
+	return nil
+}
+
+// prune removes the oldest dump files beyond maxFiles (must hold lock).
+func (h *DumpHandlers) prune() {
+	if h.maxFiles <= 0 {
+		return
+	}
+
+	type dumpFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []dumpFile
+	for kind, ext := range dumpExtensions {
+		paths, _ := filepath.Glob(filepath.Join(h.dir, "hotpod-"+kind+"-*."+ext))
+		for _, path := range paths {
+			if info, err := os.Stat(path); err == nil {
+				files = append(files, dumpFile{path: path, modTime: info.ModTime()})
+			}
+		}
+	}
+	if len(files) <= h.maxFiles {
+		return
+	}
+
+	slices.SortFunc(files, func(a, b dumpFile) int {
+		if c := a.modTime.Compare(b.modTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	for _, f := range files[:len(files)-h.maxFiles] {
+		if err := os.Remove(f.path); err != nil {
+			slog.Warn("failed to remove old dump", "path", f.path, "error", err)
+		}
+	}
+}
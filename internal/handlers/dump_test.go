@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestDumpInline(t *testing.T) {
+	h := NewDumpHandlers(auth.New("", nil), "", 0, 0)
+
+	rec := httptest.NewRecorder()
+	h.Dump(rec, httptest.NewRequest("POST", "/admin/dump", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp api.AdminDumpResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(resp.Dumps) != 2 {
+		t.Fatalf("got %d dumps, want 2", len(resp.Dumps))
+	}
+	if d := resp.Dumps[0]; d.Kind != "goroutine" || d.Path != "" || !strings.Contains(string(d.Data), "TestDumpInline") {
+		t.Errorf("goroutine dump = %s at %q, want a stack including this test", d.Kind, d.Path)
+	}
+	if d := resp.Dumps[1]; d.Kind != "heap" || !bytes.HasPrefix(d.Data, []byte{0x1f, 0x8b}) || d.Size != len(d.Data) {
+		t.Errorf("heap dump = %s of size %d, want a gzipped profile", d.Kind, d.Size)
+	}
+}
+
+func TestDumpToDirectory(t *testing.T) {
+	dir := t.TempDir()
+	h := NewDumpHandlers(auth.New("", nil), dir, 100, 3)
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		h.Dump(rec, httptest.NewRequest("POST", "/admin/dump", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		var resp api.AdminDumpResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		for _, d := range resp.Dumps {
+			if d.Data != nil || d.Size != 100 || !d.Truncated || filepath.Dir(d.Path) != dir {
+				t.Errorf("dump = %+v, want a truncated 100 byte file in %s", d, dir)
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Four dumps were written, and the oldest was pruned.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("dump directory has %d files, want 3", len(entries))
+	}
+}
+
+func TestDumpInvalid(t *testing.T) {
+	h := NewDumpHandlers(auth.New("", nil), "", 0, 0)
+	for _, query := range []string{"kinds=threads", "inline=maybe", "inline=false"} {
+		rec := httptest.NewRecorder()
+		h.Dump(rec, httptest.NewRequest("POST", "/admin/dump?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ripta/hotpod/internal/proxyproto"
+	"github.com/ripta/hotpod/internal/topology"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// EchoHandlers provides the /echo endpoint, which reports how a request
+// arrived, to verify what proxies and load balancers pass through.
+type EchoHandlers struct{}
+
+// NewEchoHandlers creates handlers for the echo endpoint.
+func NewEchoHandlers() *EchoHandlers {
+	return &EchoHandlers{}
+}
+
+// Register adds echo routes to the mux.
+func (h *EchoHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/echo", h.Echo)
+}
+
+// Echo handles /echo for any method.
+func (h *EchoHandlers) Echo(w http.ResponseWriter, r *http.Request) {
+	resp := api.EchoResponse{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.RawQuery,
+		Host:       r.Host,
+		Proto:      r.Proto,
+		RemoteAddr: r.RemoteAddr,
+		Headers:    r.Header,
+		Topology:   topology.Current(),
+	}
+	if pc := proxyproto.FromContext(r.Context()); pc != nil {
+		if hdr, _ := pc.Header(); hdr != nil {
+			resp.Proxy = &api.EchoProxy{Version: hdr.Version, Addr: pc.ProxyAddr().String()}
+			if hdr.Source != nil {
+				resp.Proxy.Source = hdr.Source.String()
+				resp.Proxy.Destination = hdr.Destination.String()
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode echo response", "error", err)
+	}
+}
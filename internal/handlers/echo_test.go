@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/proxyproto"
+	"github.com/ripta/hotpod/internal/topology"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestEcho(t *testing.T) {
+	mux := http.NewServeMux()
+	NewEchoHandlers().Register(mux)
+
+	req := httptest.NewRequest("POST", "/echo?x=1", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp api.EchoResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Method != "POST" || resp.Query != "x=1" || resp.RemoteAddr != req.RemoteAddr {
+		t.Errorf("response = %+v", resp)
+	}
+	if got := resp.Headers["X-Forwarded-For"]; len(got) != 1 || got[0] != "203.0.113.7" {
+		t.Errorf("X-Forwarded-For = %v", got)
+	}
+	if resp.Proxy != nil {
+		t.Errorf("proxy = %+v, want nil", resp.Proxy)
+	}
+}
+
+func TestEchoTopology(t *testing.T) {
+	t.Setenv("POD_NAME", "hotpod-0")
+	if _, err := topology.Resolve(context.Background(), nil, "zone-a", ""); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	NewEchoHandlers().Echo(rec, httptest.NewRequest("GET", "/echo", nil))
+	var resp api.EchoResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Topology == nil || resp.Topology.Pod != "hotpod-0" || resp.Topology.Zone != "zone-a" {
+		t.Errorf("topology = %+v, want hotpod-0 in zone-a", resp.Topology)
+	}
+}
+
+func TestEchoProxyProtocol(t *testing.T) {
+	mux := http.NewServeMux()
+	NewEchoHandlers().Register(mux)
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Listener = proxyproto.NewListener(srv.Listener, proxyproto.ModeRequired)
+	srv.Config.ConnContext = proxyproto.ConnContext
+	srv.Start()
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("PROXY TCP4 198.51.100.9 192.0.2.2 40000 80\r\nGET /echo HTTP/1.1\r\nHost: hotpod\r\nConnection: close\r\n\r\n"))
+
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var resp api.EchoResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.RemoteAddr != "198.51.100.9:40000" {
+		t.Errorf("remote_addr = %q, want the PROXY source", resp.RemoteAddr)
+	}
+	if resp.Proxy == nil || resp.Proxy.Version != 1 || resp.Proxy.Addr != conn.LocalAddr().String() || resp.Proxy.Destination != "192.0.2.2:80" {
+		t.Errorf("proxy = %+v", resp.Proxy)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// EndpointHandlers reads and toggles which endpoint groups are enabled.
+type EndpointHandlers struct {
+	authn *auth.Authenticator
+	gates *server.EndpointGates
+}
+
+// NewEndpointHandlers creates handlers for the endpoint group admin
+// endpoints.
+func NewEndpointHandlers(authn *auth.Authenticator, gates *server.EndpointGates) *EndpointHandlers {
+	return &EndpointHandlers{authn: authn, gates: gates}
+}
+
+// Register adds endpoint group routes to the mux.
+func (h *EndpointHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/endpoints", h.Get)
+	mux.HandleFunc("POST /admin/endpoints", h.Set)
+}
+
+// Get handles GET /admin/endpoints.
+func (h *EndpointHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeStatus(w)
+}
+
+// Set handles POST /admin/endpoints?enable=G[,G...]&disable=G[,G...],
+// leaving groups named in neither as they are.
+func (h *EndpointHandlers) Set(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	changes := map[string]bool{}
+	for _, param := range []string{"enable", "disable"} {
+		for _, group := range strings.Split(r.URL.Query().Get(param), ",") {
+			if group = strings.TrimSpace(group); group == "" {
+				continue
+			}
+			if _, ok := changes[group]; ok {
+				writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "endpoint group "+group+" is named more than once")
+				return
+			}
+			changes[group] = param == "enable"
+		}
+	}
+	if len(changes) == 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "enable or disable is required")
+		return
+	}
+	if err := h.gates.Set(changes); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	h.writeStatus(w)
+}
+
+func (h *EndpointHandlers) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(api.AdminEndpointsResponse{Groups: h.gates.Status()}); err != nil {
+		slog.Warn("failed to encode endpoints response", "error", err)
+	}
+}
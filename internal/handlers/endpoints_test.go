@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestEndpointHandlers(t *testing.T) {
+	mux := http.NewServeMux()
+	gates := server.NewEndpointGates([]string{"cpu", "io", "fault"}, []string{"fault"})
+	NewEndpointHandlers(auth.New("", nil), gates).Register(mux)
+
+	for _, tt := range []struct {
+		method      string
+		query       string
+		wantStatus  int
+		wantEnabled map[string]bool
+	}{
+		{"GET", "", http.StatusOK, map[string]bool{"cpu": true, "io": true, "fault": false}},
+		{"POST", "", http.StatusBadRequest, nil},
+		{"POST", "?disable=disk", http.StatusBadRequest, nil},
+		{"POST", "?enable=io&disable=io", http.StatusBadRequest, nil},
+		{"POST", "?enable=fault&disable=cpu,io", http.StatusOK, map[string]bool{"cpu": false, "io": false, "fault": true}},
+		{"GET", "", http.StatusOK, map[string]bool{"cpu": false, "io": false, "fault": true}},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, "/admin/endpoints"+tt.query, nil))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.query, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		var resp api.AdminEndpointsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if len(resp.Groups) != len(tt.wantEnabled) {
+			t.Fatalf("%s %s: groups = %+v, want %d", tt.method, tt.query, resp.Groups, len(tt.wantEnabled))
+		}
+		for _, g := range resp.Groups {
+			if g.Enabled != tt.wantEnabled[g.Name] {
+				t.Errorf("%s %s: %s enabled = %t, want %t", tt.method, tt.query, g.Name, g.Enabled, tt.wantEnabled[g.Name])
+			}
+		}
+	}
+}
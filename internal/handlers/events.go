@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// EventsHandlers provides the /events endpoint handlers.
+type EventsHandlers struct {
+	authn *auth.Authenticator
+	log   *events.Log
+}
+
+// NewEventsHandlers creates handlers for the event log. Recording an event
+// requires the mutate role, since it fans out to every sink.
+func NewEventsHandlers(authn *auth.Authenticator, log *events.Log) *EventsHandlers {
+	return &EventsHandlers{authn: authn, log: log}
+}
+
+// Register adds event routes to the mux.
+func (h *EventsHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /events", h.List)
+	mux.HandleFunc("POST /events", h.Create)
+}
+
+func (h *EventsHandlers) List(w http.ResponseWriter, r *http.Request) {
+	filter := events.Filter{MinLevel: slog.LevelDebug}
+
+	if v := r.URL.Query().Get("since"); v != "" {
+		if ts, err := time.Parse(time.RFC3339, v); err == nil {
+			filter.Since = ts
+		} else if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			filter.Since = wallclock.Now().Add(-d)
+		} else {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "since must be an RFC3339 timestamp or a non-negative duration")
+			return
+		}
+	}
+
+	if v := r.URL.Query().Get("after_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "after_id must be a non-negative integer")
+			return
+		}
+		filter.AfterID = id
+	}
+
+	if v := r.URL.Query().Get("level"); v != "" {
+		lvl, err := events.ParseLevel(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+			return
+		}
+		filter.MinLevel = lvl
+	}
+
+	filter.Type = r.URL.Query().Get("type")
+
+	limit, err := parseInt(r, "limit", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if limit < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "limit must be non-negative")
+		return
+	}
+	filter.Limit = limit
+
+	list := h.log.List(filter)
+	resp := api.EventsResponse{Count: len(list), Events: list}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode events response", "error", err)
+	}
+}
+
+// Create records a scenario event, letting external drivers (e.g. k6 scripts)
+// annotate the timeline with phase markers.
+func (h *EventsHandlers) Create(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	var req api.CreateEventRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "body must be a JSON object")
+		return
+	}
+	if req.Message == "" {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "message is required")
+		return
+	}
+
+	level := slog.LevelInfo
+	if req.Level != "" {
+		lvl, err := events.ParseLevel(req.Level)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+			return
+		}
+		level = lvl
+	}
+
+	ev := h.log.Record(level, events.TypeScenario, req.Message, req.Attrs)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(ev); err != nil {
+		slog.Warn("failed to encode event response", "error", err)
+	}
+}
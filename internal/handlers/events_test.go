@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func newTestEventsMux() (*http.ServeMux, *events.Log) {
+	log := events.New(100)
+	mux := http.NewServeMux()
+	NewEventsHandlers(auth.New("", nil), log).Register(mux)
+	return mux, log
+}
+
+func TestEventsList(t *testing.T) {
+	mux, log := newTestEventsMux()
+	log.Record(slog.LevelInfo, events.TypeLifecycle, "ready", nil)
+	log.Record(slog.LevelWarn, events.TypeFault, "crash", nil)
+
+	req := httptest.NewRequest("GET", "/events?level=warn", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var resp api.EventsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 1 || resp.Events[0].Message != "crash" {
+		t.Errorf("got %+v, want single crash event", resp)
+	}
+}
+
+func TestEventsListSinceDuration(t *testing.T) {
+	mux, log := newTestEventsMux()
+	log.Record(slog.LevelInfo, events.TypeAdmin, "recent", nil)
+
+	req := httptest.NewRequest("GET", "/events?since=1m", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	var resp api.EventsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 1 {
+		t.Errorf("Count = %d, want 1", resp.Count)
+	}
+}
+
+var invalidEventsQueries = []string{
+	"since=yesterday",
+	"level=verbose",
+	"after_id=-1",
+	"limit=-5",
+}
+
+func TestEventsListInvalid(t *testing.T) {
+	mux, _ := newTestEventsMux()
+
+	for _, q := range invalidEventsQueries {
+		req := httptest.NewRequest("GET", "/events?"+q, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestEventsCreate(t *testing.T) {
+	mux, log := newTestEventsMux()
+
+	body := `{"message":"ramp phase started","attrs":{"vus":50}}`
+	req := httptest.NewRequest("POST", "/events", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201", rec.Code)
+	}
+
+	got := log.List(events.Filter{Type: events.TypeScenario})
+	if len(got) != 1 || got[0].Message != "ramp phase started" {
+		t.Errorf("recorded events = %+v", got)
+	}
+}
+
+func TestEventsCreateMissingMessage(t *testing.T) {
+	mux, _ := newTestEventsMux()
+
+	req := httptest.NewRequest("POST", "/events", strings.NewReader(`{}`))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestEventsCreateRequiresToken(t *testing.T) {
+	log := events.New(100)
+	mux := http.NewServeMux()
+	NewEventsHandlers(auth.New("secret", nil), log).Register(mux)
+
+	for _, tt := range []struct {
+		token      string
+		wantStatus int
+	}{
+		{"", http.StatusUnauthorized},
+		{"wrong", http.StatusUnauthorized},
+		{"secret", http.StatusCreated},
+	} {
+		req := httptest.NewRequest("POST", "/events", strings.NewReader(`{"message":"phase"}`))
+		if tt.token != "" {
+			req.Header.Set("X-Admin-Token", tt.token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("token %q: status = %d, want %d", tt.token, rec.Code, tt.wantStatus)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/events", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /events status = %d, want 200 without a token", rec.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// EvictHandlers deletes hotpod's own pod through the Kubernetes API, for
+// comparing controller-driven pod replacement against process crashes.
+type EvictHandlers struct {
+	chaosEnabled bool
+	authn        *auth.Authenticator
+	guard        *fault.Guard
+	client       *kube.Client
+	pod          string
+	namespace    string
+}
+
+// NewEvictHandlers creates handlers that delete pod in namespace. A nil
+// client means the Kubernetes API is unavailable; a nil guard applies no
+// guardrails.
+func NewEvictHandlers(chaosEnabled bool, authn *auth.Authenticator, guard *fault.Guard, client *kube.Client, pod, namespace string) *EvictHandlers {
+	return &EvictHandlers{chaosEnabled: chaosEnabled, authn: authn, guard: guard, client: client, pod: pod, namespace: namespace}
+}
+
+// Register adds evict routes to the mux.
+func (h *EvictHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /fault/evict", h.Evict)
+}
+
+// Evict handles POST /fault/evict?grace_period=N, deleting the pod with an
+// optional grace period override in seconds. The service account needs
+// permission to delete pods. The kubelet then sends SIGTERM as for any
+// other pod deletion, so the response usually arrives before shutdown.
+func (h *EvictHandlers) Evict(w http.ResponseWriter, r *http.Request) {
+	if !chaosAllowed(h.chaosEnabled, h.authn, w, r) {
+		return
+	}
+	if h.client == nil {
+		writeError(w, http.StatusNotFound, errcode.KubeAPIUnavailable, "the Kubernetes API is not available")
+		return
+	}
+
+	gracePeriod := int64(-1)
+	if v := r.URL.Query().Get("grace_period"); v != "" {
+		var err error
+		if gracePeriod, err = strconv.ParseInt(v, 10, 64); err != nil || gracePeriod < 0 {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "grace_period must be a non-negative number of seconds")
+			return
+		}
+	}
+
+	if !admitChaos(h.guard, w, "evict") {
+		return
+	}
+
+	if err := fault.Evict(r.Context(), h.client, h.namespace, h.pod, gracePeriod); err != nil {
+		writeError(w, http.StatusBadGateway, errcode.FaultFailed, err.Error())
+		return
+	}
+
+	resp := api.EvictResponse{Pod: h.pod, Namespace: h.namespace}
+	if gracePeriod >= 0 {
+		resp.GracePeriodSeconds = &gracePeriod
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode evict response", "error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestEvictDeletesPod(t *testing.T) {
+	var method, path, body string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(b)
+		w.Write([]byte(`{}`))
+	}))
+	defer apiServer.Close()
+
+	h := NewEvictHandlers(true, nil, nil, kube.NewForTesting(apiServer.URL, "ns"), "hotpod-0", "ns")
+	rec := httptest.NewRecorder()
+	h.Evict(rec, httptest.NewRequest("POST", "/fault/evict?grace_period=5", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body)
+	}
+	if method != "DELETE" || path != "/api/v1/namespaces/ns/pods/hotpod-0" {
+		t.Errorf("API request = %s %s, want DELETE of the pod", method, path)
+	}
+	if want := `{"apiVersion":"v1","kind":"DeleteOptions","gracePeriodSeconds":5}`; body != want {
+		t.Errorf("API request body = %s, want %s", body, want)
+	}
+
+	var resp api.EvictResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Pod != "hotpod-0" || resp.GracePeriodSeconds == nil || *resp.GracePeriodSeconds != 5 {
+		t.Errorf("response = %+v, want pod hotpod-0 with a 5s grace period", resp)
+	}
+}
+
+func TestEvictAPIError(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"pods \"hotpod-0\" is forbidden"}`))
+	}))
+	defer apiServer.Close()
+
+	h := NewEvictHandlers(true, nil, nil, kube.NewForTesting(apiServer.URL, "ns"), "hotpod-0", "ns")
+	rec := httptest.NewRecorder()
+	h.Evict(rec, httptest.NewRequest("POST", "/fault/evict", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", rec.Code)
+	}
+}
+
+func TestEvictUnavailable(t *testing.T) {
+	tests := []struct {
+		name  string
+		h     *EvictHandlers
+		query string
+		want  int
+	}{
+		{"chaos disabled", NewEvictHandlers(false, nil, nil, kube.NewForTesting("http://127.0.0.1:0", "ns"), "p", "ns"), "", http.StatusForbidden},
+		{"no client", NewEvictHandlers(true, nil, nil, nil, "p", "ns"), "", http.StatusNotFound},
+		{"negative grace period", NewEvictHandlers(true, nil, nil, kube.NewForTesting("http://127.0.0.1:0", "ns"), "p", "ns"), "?grace_period=-1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.h.Evict(rec, httptest.NewRequest("POST", "/fault/evict"+tt.query, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
@@ -3,23 +3,44 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"math/rand/v2"
+	"net"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/cgroup"
+	"github.com/ripta/hotpod/internal/events"
 	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 // FaultHandlers provides chaos engineering endpoint handlers.
 type FaultHandlers struct {
 	enabled bool
+	// authn, when configured with role-scoped tokens, restricts faults to
+	// callers holding the chaos role
+	authn *auth.Authenticator
+	// guard limits the blast radius of faults (nil = no limits)
+	guard *fault.Guard
+	// cgroupRoot is where /fault/oom reads memory.high and memory.events
+	cgroupRoot string
 }
 
-// NewFaultHandlers creates handlers for chaos engineering endpoints.
-func NewFaultHandlers(enabled bool) *FaultHandlers {
+// NewFaultHandlers creates handlers for chaos engineering endpoints. guard
+// may be nil to start faults without guardrails.
+func NewFaultHandlers(enabled bool, authn *auth.Authenticator, guard *fault.Guard) *FaultHandlers {
 	return &FaultHandlers{
-		enabled: enabled,
+		enabled:    enabled,
+		authn:      authn,
+		guard:      guard,
+		cgroupRoot: cgroup.DefaultRoot,
 	}
 }
 
@@ -27,27 +48,73 @@ func NewFaultHandlers(enabled bool) *FaultHandlers {
 func (h *FaultHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("POST /fault/crash", h.Crash)
 	mux.HandleFunc("POST /fault/hang", h.Hang)
+	mux.HandleFunc("POST /fault/panic", h.Panic)
 	mux.HandleFunc("POST /fault/oom", h.OOM)
+	mux.HandleFunc("GET /fault/oom", h.OOMStatus)
+	mux.HandleFunc("DELETE /fault/oom", h.StopOOM)
 	mux.HandleFunc("GET /fault/error", h.Error)
+	mux.HandleFunc("GET /fault/bomb", h.Bomb)
+	mux.HandleFunc("POST /fault/zombie", h.Zombie)
+	mux.HandleFunc("DELETE /fault/zombie", h.ReapZombies)
+	mux.HandleFunc("POST /fault/threads", h.Threads)
+	mux.HandleFunc("DELETE /fault/threads", h.ReleaseThreads)
+	mux.HandleFunc("POST /fault/deadlock", h.Deadlock)
+	mux.HandleFunc("POST /fault/contention", h.Contention)
+	mux.HandleFunc("POST /fault/ports", h.Ports)
+	mux.HandleFunc("GET /fault/ports", h.PortsStatus)
+	mux.HandleFunc("DELETE /fault/ports", h.StopPorts)
 }
 
-// CrashResponse is the JSON response for /fault/crash (sent before crashing).
-type CrashResponse struct {
-	Message   string `json:"message"`
-	Delay     string `json:"delay"`
-	ExitCode  int    `json:"exit_code"`
-	Scheduled bool   `json:"scheduled"`
+// allowed checks that chaos endpoints are enabled and, when role-scoped admin
+// tokens are configured, that the caller holds the chaos role.
+func (h *FaultHandlers) allowed(w http.ResponseWriter, r *http.Request) bool {
+	return chaosAllowed(h.enabled, h.authn, w, r)
+}
+
+// chaosAllowed implements the gate shared by all /fault/* handlers.
+func chaosAllowed(enabled bool, authn *auth.Authenticator, w http.ResponseWriter, r *http.Request) bool {
+	if !enabled {
+		writeError(w, http.StatusForbidden, errcode.ChaosDisabled, "chaos endpoints are disabled")
+		return false
+	}
+	if authn.Scoped() {
+		return authorize(authn, w, r, auth.RoleChaos)
+	}
+	return true
+}
+
+// admit checks the blast radius guardrails before starting action.
+func (h *FaultHandlers) admit(w http.ResponseWriter, action string) bool {
+	return admitChaos(h.guard, w, action)
+}
+
+// admitChaos implements the guardrail check shared by all chaos actions,
+// writing the rejection if the guard refuses action.
+func admitChaos(guard *fault.Guard, w http.ResponseWriter, action string) bool {
+	var ge *fault.GuardError
+	if err := guard.Admit(action); !errors.As(err, &ge) {
+		return true
+	}
+	switch ge.Reason {
+	case fault.GuardCooldown:
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(ge.RetryAfter.Seconds()))))
+		writeError(w, http.StatusTooManyRequests, errcode.ChaosCooldown, ge.Error())
+	case fault.GuardLimit:
+		writeError(w, http.StatusConflict, errcode.ChaosLimitReached, ge.Error())
+	default:
+		writeError(w, http.StatusForbidden, errcode.ChaosNotAllowed, ge.Error())
+	}
+	return false
 }
 
 func (h *FaultHandlers) Crash(w http.ResponseWriter, r *http.Request) {
-	if !h.enabled {
-		writeError(w, http.StatusForbidden, "CHAOS_DISABLED", "chaos endpoints are disabled")
+	if !h.allowed(w, r) {
 		return
 	}
 
 	delay, err := parseDuration(r, "delay", 0)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 
@@ -56,16 +123,20 @@ func (h *FaultHandlers) Crash(w http.ResponseWriter, r *http.Request) {
 	if exitCodeStr != "" {
 		exitCode, err = strconv.Atoi(exitCodeStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "exit_code must be an integer")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "exit_code must be an integer")
 			return
 		}
 		if exitCode < 0 || exitCode > 255 {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "exit_code must be between 0 and 255")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "exit_code must be between 0 and 255")
 			return
 		}
 	}
 
-	resp := CrashResponse{
+	if !h.admit(w, "crash") {
+		return
+	}
+
+	resp := api.CrashResponse{
 		Message:   "crash scheduled",
 		Delay:     delay.String(),
 		ExitCode:  exitCode,
@@ -78,29 +149,80 @@ func (h *FaultHandlers) Crash(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Flush the response before crashing
-	if f, ok := w.(http.Flusher); ok {
-		f.Flush()
-	}
+	http.NewResponseController(w).Flush()
 
 	go fault.Crash(delay, exitCode)
 }
 
-// HangResponse is the JSON response for /fault/hang.
-type HangResponse struct {
-	Message   string `json:"message"`
-	Duration  string `json:"duration"`
-	Cancelled bool   `json:"cancelled,omitempty"`
+// Panic panics inside the handler, for the Recovery middleware to turn into
+// a 500, or with goroutine=true responds and then panics in a background
+// goroutine, which crashes the process. The background panic is guarded as
+// a crash, since its blast radius is the same.
+func (h *FaultHandlers) Panic(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+
+	delay, err := parseDuration(r, "delay", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	goroutine := r.URL.Query().Get("goroutine") == "true"
+	if delay > 0 && !goroutine {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "delay requires goroutine=true")
+		return
+	}
+	message := r.URL.Query().Get("message")
+	if message == "" {
+		message = "injected panic"
+	}
+
+	action := "panic"
+	if goroutine {
+		action = "crash"
+	}
+	if !h.admit(w, action) {
+		return
+	}
+
+	if !goroutine {
+		slog.Warn("panicking in handler", "message", message)
+		events.Record(slog.LevelWarn, events.TypeFault, "handler panic injected", map[string]any{
+			"message": message,
+		})
+		panic(message)
+	}
+
+	resp := api.PanicResponse{
+		Message:   "goroutine panic scheduled",
+		Delay:     delay.String(),
+		Goroutine: true,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode panic response", "error", err)
+	}
+
+	// Flush the response before panicking
+	http.NewResponseController(w).Flush()
+
+	go fault.Panic(delay, message)
 }
 
 func (h *FaultHandlers) Hang(w http.ResponseWriter, r *http.Request) {
-	if !h.enabled {
-		writeError(w, http.StatusForbidden, "CHAOS_DISABLED", "chaos endpoints are disabled")
+	if !h.allowed(w, r) {
 		return
 	}
 
 	duration, err := parseDuration(r, "duration", 0)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	if !h.admit(w, "hang") {
 		return
 	}
 
@@ -114,9 +236,7 @@ func (h *FaultHandlers) Hang(w http.ResponseWriter, r *http.Request) {
 			slog.Warn("failed to write partial response", "error", err)
 			return
 		}
-		if f, ok := w.(http.Flusher); ok {
-			f.Flush()
-		}
+		http.NewResponseController(w).Flush()
 
 		cancelled := fault.Hang(r.Context(), duration)
 
@@ -134,7 +254,7 @@ func (h *FaultHandlers) Hang(w http.ResponseWriter, r *http.Request) {
 	// Normal mode: hang first, then respond
 	cancelled := fault.Hang(r.Context(), duration)
 
-	resp := HangResponse{
+	resp := api.HangResponse{
 		Message:   "hang completed",
 		Duration:  duration.String(),
 		Cancelled: cancelled,
@@ -146,60 +266,179 @@ func (h *FaultHandlers) Hang(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// OOMResponse is the JSON response for /fault/oom (sent before OOM starts).
-type OOMResponse struct {
-	Message string `json:"message"`
-	Rate    string `json:"rate"`
-	Started bool   `json:"started"`
+// Bomb handles GET /fault/bomb?size=N&encoding=gzip|identity, responding
+// with size zero bytes. With gzip (the default), the body is sent
+// gzip-compressed and expands about a thousandfold when decompressed, to
+// test proxy decompression limits; with identity, it is sent at full size
+// with a Content-Length, to test response size limits.
+func (h *FaultHandlers) Bomb(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+
+	size, err := parseSize(r, "size", 1<<30)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if size < 1 || size > fault.MaxBombSize {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("size must be between 1 and %s", formatSize(fault.MaxBombSize)))
+		return
+	}
+
+	encoding := r.URL.Query().Get("encoding")
+	switch encoding {
+	case "":
+		encoding = fault.BombGzip
+	case fault.BombGzip, fault.BombIdentity:
+	default:
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("encoding must be %s or %s", fault.BombGzip, fault.BombIdentity))
+		return
+	}
+
+	if !h.admit(w, "bomb") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if encoding == fault.BombGzip {
+		w.Header().Set("Content-Encoding", "gzip")
+	} else {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	if _, err := fault.WriteBomb(r.Context(), w, size, encoding); err != nil {
+		slog.Debug("response bomb ended early", "error", err)
+	}
 }
 
 func (h *FaultHandlers) OOM(w http.ResponseWriter, r *http.Request) {
-	if !h.enabled {
-		writeError(w, http.StatusForbidden, "CHAOS_DISABLED", "chaos endpoints are disabled")
+	if !h.allowed(w, r) {
 		return
 	}
 
 	rate, err := parseSize(r, "rate", 100<<20) // Default 100MB/s
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 	if rate <= 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "rate must be positive")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate must be positive")
+		return
+	}
+
+	target, err := parseSize(r, "target", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if target < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "target must be positive")
 		return
 	}
 
-	resp := OOMResponse{
+	resp := api.OOMResponse{
 		Message: "OOM simulation started",
 		Rate:    formatSize(rate) + "/s",
 		Started: true,
+		Target:  target,
+	}
+	cfg := fault.OOMConfig{Rate: rate, Target: target}
+
+	switch until := r.URL.Query().Get("until"); until {
+	case "":
+	case oomUntilHigh:
+		if target > 0 {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "target and until are mutually exclusive")
+			return
+		}
+		high, err := cgroup.ReadMemoryHigh(h.cgroupRoot)
+		if err != nil || high == 0 {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "until=high requires a cgroup v2 memory.high limit")
+			return
+		}
+		if cfg.Reached, err = h.memoryHighReached(high); err != nil {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "until=high requires cgroup v2 memory.events: "+err.Error())
+			return
+		}
+		cfg.Until = until
+		resp.Until = until
+		resp.Target = high
+	default:
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "until must be high")
+		return
+	}
+	if resp.Target > 0 {
+		resp.Message = "OOM simulation started, holding at target"
+	}
+
+	if !h.admit(w, "oom") {
+		return
+	}
+
+	// The simulation runs in the background so it survives the request and,
+	// without a target, continues allocating until the process is killed
+	if err := fault.StartOOM(cfg); errors.Is(err, fault.ErrOOMRunning) {
+		writeError(w, http.StatusConflict, errcode.FaultRunning, err.Error())
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Warn("failed to encode oom response", "error", err)
 	}
+}
+
+// OOMStatus handles GET /fault/oom.
+func (h *FaultHandlers) OOMStatus(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+	writeOOMStatus(w, fault.OOMState())
+}
 
-	// Flush the response before starting OOM
-	if f, ok := w.(http.Flusher); ok {
-		f.Flush()
+// StopOOM handles DELETE /fault/oom, cancelling the simulation and releasing
+// any memory it holds.
+func (h *FaultHandlers) StopOOM(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
 	}
+	released := fault.StopOOM()
+	status := fault.OOMState()
+	status.Released = released
+	writeOOMStatus(w, status)
+}
 
-	// Run OOM in background goroutine - use Background context so it survives
-	// request cancellation and continues allocating until the process is killed
-	go fault.OOM(context.Background(), rate)
+func writeOOMStatus(w http.ResponseWriter, status api.OOMStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		slog.Warn("failed to encode oom response", "error", err)
+	}
 }
 
-// ErrorResponse is the JSON response for /fault/error.
-type ErrorResponse struct {
-	Injected bool   `json:"injected"`
-	Status   int    `json:"status,omitempty"`
-	Message  string `json:"message"`
+// oomUntilHigh stops /fault/oom once the container is under memory.high
+// pressure.
+const oomUntilHigh = "high"
+
+// memoryHighReached returns a check for memory.high pressure: usage at the
+// threshold, or the kernel throttling allocations since the check was made.
+// Throttled allocations may keep usage just under the threshold, so the
+// memory.events high counter is the more reliable signal.
+func (h *FaultHandlers) memoryHighReached(high int64) (func() bool, error) {
+	start, err := cgroup.ReadMemoryEvents(h.cgroupRoot)
+	if err != nil {
+		return nil, err
+	}
+	return func() bool {
+		if events, err := cgroup.ReadMemoryEvents(h.cgroupRoot); err == nil && events.High > start.High {
+			return true
+		}
+		current, err := cgroup.ReadMemoryCurrent(h.cgroupRoot)
+		return err == nil && current >= high
+	}, nil
 }
 
 func (h *FaultHandlers) Error(w http.ResponseWriter, r *http.Request) {
-	if !h.enabled {
-		writeError(w, http.StatusForbidden, "CHAOS_DISABLED", "chaos endpoints are disabled")
+	if !h.allowed(w, r) {
 		return
 	}
 
@@ -209,11 +448,11 @@ func (h *FaultHandlers) Error(w http.ResponseWriter, r *http.Request) {
 		var err error
 		rate, err = strconv.ParseFloat(rateStr, 64)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "rate must be a number")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate must be a number")
 			return
 		}
 		if rate < 0 || rate > 1 {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "rate must be between 0 and 1")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate must be between 0 and 1")
 			return
 		}
 	}
@@ -224,18 +463,22 @@ func (h *FaultHandlers) Error(w http.ResponseWriter, r *http.Request) {
 		var err error
 		status, err = strconv.Atoi(statusStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "status must be an integer")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "status must be an integer")
 			return
 		}
 		if status < 400 || status > 599 {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "status must be between 400 and 599")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "status must be between 400 and 599")
 			return
 		}
 	}
 
+	if !h.admit(w, "error") {
+		return
+	}
+
 	// Decide whether to inject error based on rate
 	if rand.Float64() < rate {
-		resp := ErrorResponse{
+		resp := api.FaultErrorResponse{
 			Injected: true,
 			Status:   status,
 			Message:  "injected error",
@@ -248,7 +491,7 @@ func (h *FaultHandlers) Error(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := ErrorResponse{
+	resp := api.FaultErrorResponse{
 		Injected: false,
 		Message:  "no error injected",
 	}
@@ -257,3 +500,296 @@ func (h *FaultHandlers) Error(w http.ResponseWriter, r *http.Request) {
 		slog.Warn("failed to encode error response", "error", err)
 	}
 }
+
+// Zombie handles POST /fault/zombie, leaving count (default: 1) defunct
+// child processes unreaped. A PID limit that stops creation early is
+// reported in the response rather than as a failure.
+func (h *FaultHandlers) Zombie(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+
+	count, err := parseInt(r, "count", 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if count < 1 || count > fault.MaxZombies {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("count must be between 1 and %d", fault.MaxZombies))
+		return
+	}
+
+	if !h.admit(w, "zombie") {
+		return
+	}
+
+	created, err := fault.Zombies(count)
+	resp := api.ZombieResponse{Created: created, Total: fault.ZombieCount()}
+	if err != nil {
+		if created == 0 {
+			writeError(w, http.StatusInternalServerError, errcode.FaultFailed, err.Error())
+			return
+		}
+		resp.Error = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode zombie response", "error", err)
+	}
+}
+
+// ReapZombies handles DELETE /fault/zombie, reaping all zombie children.
+func (h *FaultHandlers) ReapZombies(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+
+	resp := api.ZombieResponse{Reaped: fault.Reap(), Total: fault.ZombieCount()}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode zombie response", "error", err)
+	}
+}
+
+// Threads handles POST /fault/threads, pinning count (default: 100) OS
+// threads for duration (default: 60s, 0 = until released).
+func (h *FaultHandlers) Threads(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+
+	count, err := parseInt(r, "count", 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if count < 1 || count > fault.MaxThreads {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("count must be between 1 and %d", fault.MaxThreads))
+		return
+	}
+
+	duration, err := parseDuration(r, "duration", 60*time.Second)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if duration < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "duration must be non-negative")
+		return
+	}
+
+	if !h.admit(w, "threads") {
+		return
+	}
+
+	started, err := fault.Threads(count, duration)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	writeThreadsResponse(w, api.ThreadsResponse{Started: started, Duration: duration.String()})
+}
+
+// ReleaseThreads handles DELETE /fault/threads, releasing all pinned threads.
+func (h *FaultHandlers) ReleaseThreads(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+	writeThreadsResponse(w, api.ThreadsResponse{Released: fault.ReleaseThreads()})
+}
+
+func writeThreadsResponse(w http.ResponseWriter, resp api.ThreadsResponse) {
+	resp.Held = fault.ThreadsHeld()
+	resp.ThreadsCreated = fault.ThreadsCreated()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode threads response", "error", err)
+	}
+}
+
+// Deadlock handles POST /fault/deadlock, permanently deadlocking count
+// (default: 1) pairs of goroutines. Only a restart clears them.
+func (h *FaultHandlers) Deadlock(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+
+	count, err := parseInt(r, "count", 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if count < 1 || count > fault.MaxDeadlocks {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("count must be between 1 and %d", fault.MaxDeadlocks))
+		return
+	}
+
+	if !h.admit(w, "deadlock") {
+		return
+	}
+
+	total, err := fault.Deadlock(count)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(api.DeadlockResponse{Created: count, TotalPairs: total}); err != nil {
+		slog.Warn("failed to encode deadlock response", "error", err)
+	}
+}
+
+// Contention handles POST /fault/contention, running workers (default: 8)
+// goroutines that hammer one mutex, each holding it for hold (default: 1ms),
+// for duration (default: 10s). With async=true it returns immediately.
+func (h *FaultHandlers) Contention(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+
+	workers, err := parseInt(r, "workers", 8)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if workers < 2 || workers > 1000 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "workers must be between 2 and 1000")
+		return
+	}
+
+	hold, err := parseDuration(r, "hold", time.Millisecond)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if hold <= 0 || hold > time.Second {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "hold must be between 0 and 1s")
+		return
+	}
+
+	duration, err := parseDuration(r, "duration", 10*time.Second)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if duration <= 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "duration must be positive")
+		return
+	}
+
+	if !h.admit(w, "contention") {
+		return
+	}
+
+	resp := api.ContentionResponse{Workers: workers, Hold: hold.String(), Duration: duration.String()}
+	if r.URL.Query().Get("async") == "true" {
+		go fault.Contention(context.Background(), workers, hold, duration)
+		resp.Async = true
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			slog.Warn("failed to encode contention response", "error", err)
+		}
+		return
+	}
+
+	result := fault.Contention(r.Context(), workers, hold, duration)
+	resp.Acquisitions = result.Acquisitions
+	resp.TotalWait = result.Wait.String()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode contention response", "error", err)
+	}
+}
+
+// Ports handles POST /fault/ports, starting a background run that opens
+// short-lived connections to target at rate per second (default: 0 =
+// unpaced) from concurrency (default: 50) workers for duration (default: 30s).
+func (h *FaultHandlers) Ports(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+
+	target := r.URL.Query().Get("target")
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "target must be host:port")
+		return
+	}
+
+	rate, err := parseFloat(r, "rate", 0)
+	if err != nil || !(rate >= 0 && rate <= fault.MaxPortStressRate) {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("rate must be between 0 and %g", fault.MaxPortStressRate))
+		return
+	}
+
+	concurrency, err := parseInt(r, "concurrency", 50)
+	if err != nil || concurrency < 1 || concurrency > 1000 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "concurrency must be between 1 and 1000")
+		return
+	}
+
+	hold, err := parseDuration(r, "hold", 0)
+	if err != nil || hold < 0 || hold > time.Minute {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "hold must be between 0 and 1m")
+		return
+	}
+
+	duration, err := parseDuration(r, "duration", 30*time.Second)
+	if err != nil || duration <= 0 || duration > 10*time.Minute {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "duration must be between 0 and 10m")
+		return
+	}
+
+	if !h.admit(w, "ports") {
+		return
+	}
+
+	err = fault.StartPortStress(fault.PortStressConfig{
+		Target:      target,
+		Rate:        rate,
+		Concurrency: concurrency,
+		Hold:        hold,
+		Duration:    duration,
+		DialTimeout: 2 * time.Second,
+	})
+	if errors.Is(err, fault.ErrPortStressRunning) {
+		writeError(w, http.StatusConflict, errcode.FaultRunning, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(fault.PortStressState()); err != nil {
+		slog.Warn("failed to encode ports response", "error", err)
+	}
+}
+
+// PortsStatus handles GET /fault/ports.
+func (h *FaultHandlers) PortsStatus(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(fault.PortStressState()); err != nil {
+		slog.Warn("failed to encode ports response", "error", err)
+	}
+}
+
+// StopPorts handles DELETE /fault/ports.
+func (h *FaultHandlers) StopPorts(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(w, r) {
+		return
+	}
+	fault.StopPortStress()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(fault.PortStressState()); err != nil {
+		slog.Warn("failed to encode ports response", "error", err)
+	}
+}
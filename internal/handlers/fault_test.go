@@ -1,21 +1,36 @@
 package handlers
 
 import (
+	"compress/gzip"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 var faultEndpoints = []endpoint{
 	{"POST", "/fault/crash"},
 	{"POST", "/fault/hang"},
+	{"POST", "/fault/panic"},
 	{"POST", "/fault/oom"},
+	{"GET", "/fault/oom"},
+	{"DELETE", "/fault/oom"},
 	{"GET", "/fault/error"},
+	{"GET", "/fault/bomb"},
 }
 
 func TestFaultCrashDisabled(t *testing.T) {
-	h := NewFaultHandlers(false)
+	h := NewFaultHandlers(false, nil, nil)
 
 	req := httptest.NewRequest("POST", "/fault/crash", nil)
 	rec := httptest.NewRecorder()
@@ -28,7 +43,7 @@ func TestFaultCrashDisabled(t *testing.T) {
 }
 
 func TestFaultCrashInvalidExitCode(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	testCases := []string{"-1", "256", "abc"}
 	for _, exitCode := range testCases {
@@ -44,7 +59,7 @@ func TestFaultCrashInvalidExitCode(t *testing.T) {
 }
 
 func TestFaultCrashInvalidDelay(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	req := httptest.NewRequest("POST", "/fault/crash?delay=invalid", nil)
 	rec := httptest.NewRecorder()
@@ -57,7 +72,7 @@ func TestFaultCrashInvalidDelay(t *testing.T) {
 }
 
 func TestFaultHangDisabled(t *testing.T) {
-	h := NewFaultHandlers(false)
+	h := NewFaultHandlers(false, nil, nil)
 
 	req := httptest.NewRequest("POST", "/fault/hang", nil)
 	rec := httptest.NewRecorder()
@@ -70,7 +85,7 @@ func TestFaultHangDisabled(t *testing.T) {
 }
 
 func TestFaultHangInvalidDuration(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	req := httptest.NewRequest("POST", "/fault/hang?duration=invalid", nil)
 	rec := httptest.NewRecorder()
@@ -83,7 +98,7 @@ func TestFaultHangInvalidDuration(t *testing.T) {
 }
 
 func TestFaultHangShortDuration(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	req := httptest.NewRequest("POST", "/fault/hang?duration=10ms", nil)
 	rec := httptest.NewRecorder()
@@ -94,7 +109,7 @@ func TestFaultHangShortDuration(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp HangResponse
+	var resp api.HangResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -107,7 +122,7 @@ func TestFaultHangShortDuration(t *testing.T) {
 }
 
 func TestFaultOOMDisabled(t *testing.T) {
-	h := NewFaultHandlers(false)
+	h := NewFaultHandlers(false, nil, nil)
 
 	req := httptest.NewRequest("POST", "/fault/oom", nil)
 	rec := httptest.NewRecorder()
@@ -120,7 +135,7 @@ func TestFaultOOMDisabled(t *testing.T) {
 }
 
 func TestFaultOOMInvalidRate(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	testCases := []string{"invalid", "-1", "0"}
 	for _, rate := range testCases {
@@ -135,8 +150,90 @@ func TestFaultOOMInvalidRate(t *testing.T) {
 	}
 }
 
+func TestFaultOOMUntilHigh(t *testing.T) {
+	h := NewFaultHandlers(true, nil, nil)
+	h.cgroupRoot = t.TempDir()
+	t.Cleanup(func() { fault.StopOOM() })
+
+	for _, query := range []string{"until=forever", "until=high"} {
+		rec := httptest.NewRecorder()
+		h.OOM(rec, httptest.NewRequest("POST", "/fault/oom?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s without memory.high: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	// Usage already at memory.high, so the simulation stops before
+	// allocating anything.
+	for name, content := range map[string]string{
+		"memory.high":    "67108864\n",
+		"memory.current": "67108864\n",
+		"memory.events":  "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n",
+	} {
+		if err := os.WriteFile(filepath.Join(h.cgroupRoot, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec := httptest.NewRecorder()
+	h.OOM(rec, httptest.NewRequest("POST", "/fault/oom?until=high", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var resp api.OOMResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Until != "high" || resp.Target != 64<<20 {
+		t.Errorf("response = %+v, want until high with a 64Mi target", resp)
+	}
+}
+
+func TestFaultOOMTargetHold(t *testing.T) {
+	h := NewFaultHandlers(true, nil, nil)
+	t.Cleanup(func() { fault.StopOOM() })
+
+	for _, query := range []string{"target=invalid", "target=-1", "target=1Mi&until=high"} {
+		rec := httptest.NewRecorder()
+		h.OOM(rec, httptest.NewRequest("POST", "/fault/oom?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.OOM(rec, httptest.NewRequest("POST", "/fault/oom?rate=10Mi&target=1Mi", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var resp api.OOMResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Target != 1<<20 {
+		t.Errorf("target = %d, want %d", resp.Target, 1<<20)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for fault.OOMState().State != api.OOMStateHolding {
+		if time.Now().After(deadline) {
+			t.Fatalf("state = %q, want %q", fault.OOMState().State, api.OOMStateHolding)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	rec = httptest.NewRecorder()
+	h.StopOOM(rec, httptest.NewRequest("DELETE", "/fault/oom", nil))
+	var status api.OOMStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to parse status: %v", err)
+	}
+	if status.State != api.OOMStateIdle || status.Released != 1<<20 {
+		t.Errorf("status = %+v, want idle with 1Mi released", status)
+	}
+}
+
 func TestFaultErrorDisabled(t *testing.T) {
-	h := NewFaultHandlers(false)
+	h := NewFaultHandlers(false, nil, nil)
 
 	req := httptest.NewRequest("GET", "/fault/error", nil)
 	rec := httptest.NewRecorder()
@@ -149,7 +246,7 @@ func TestFaultErrorDisabled(t *testing.T) {
 }
 
 func TestFaultErrorInvalidRate(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	testCases := []string{"invalid", "-0.1", "1.5"}
 	for _, rate := range testCases {
@@ -165,7 +262,7 @@ func TestFaultErrorInvalidRate(t *testing.T) {
 }
 
 func TestFaultErrorInvalidStatus(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	testCases := []string{"invalid", "200", "399", "600"}
 	for _, status := range testCases {
@@ -181,7 +278,7 @@ func TestFaultErrorInvalidStatus(t *testing.T) {
 }
 
 func TestFaultErrorAlwaysInject(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	req := httptest.NewRequest("GET", "/fault/error?rate=1&status=503", nil)
 	rec := httptest.NewRecorder()
@@ -192,7 +289,7 @@ func TestFaultErrorAlwaysInject(t *testing.T) {
 		t.Errorf("status = %d, want 503", rec.Code)
 	}
 
-	var resp ErrorResponse
+	var resp api.FaultErrorResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -205,7 +302,7 @@ func TestFaultErrorAlwaysInject(t *testing.T) {
 }
 
 func TestFaultErrorNeverInject(t *testing.T) {
-	h := NewFaultHandlers(true)
+	h := NewFaultHandlers(true, nil, nil)
 
 	req := httptest.NewRequest("GET", "/fault/error?rate=0", nil)
 	rec := httptest.NewRecorder()
@@ -216,7 +313,7 @@ func TestFaultErrorNeverInject(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp ErrorResponse
+	var resp api.FaultErrorResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -226,7 +323,7 @@ func TestFaultErrorNeverInject(t *testing.T) {
 }
 
 func TestFaultRegister(t *testing.T) {
-	h := NewFaultHandlers(false)
+	h := NewFaultHandlers(false, nil, nil)
 
 	mux := http.NewServeMux()
 	h.Register(mux)
@@ -241,3 +338,128 @@ func TestFaultRegister(t *testing.T) {
 		}
 	}
 }
+
+func TestFaultScopedTokensRequireChaosRole(t *testing.T) {
+	tokens, err := auth.ParseTokens("dash:read:r,ops:chaos:c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewFaultHandlers(true, auth.New("", tokens), nil)
+
+	tests := []struct {
+		token string
+		want  int
+	}{
+		{"", http.StatusUnauthorized},
+		{"r", http.StatusForbidden},
+		{"c", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/fault/error?rate=0", nil)
+		if tt.token != "" {
+			req.Header.Set("X-Admin-Token", tt.token)
+		}
+		rec := httptest.NewRecorder()
+		h.Error(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("token %q: status = %d, want %d", tt.token, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestFaultPanic(t *testing.T) {
+	mux := http.NewServeMux()
+	NewFaultHandlers(true, nil, nil).Register(mux)
+	handler := server.Recovery(mux)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/fault/panic?message=boom", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "INTERNAL_ERROR") {
+		t.Errorf("status = %d, body = %s, want 500 INTERNAL_ERROR", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/fault/panic?delay=1s", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("delay without goroutine: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestFaultGuardrails(t *testing.T) {
+	allow, err := fault.ParseChaosAllow("safe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard := fault.NewGuard(fault.GuardConfig{Allow: allow, Cooldowns: map[string]time.Duration{"error": time.Hour}})
+	h := NewFaultHandlers(true, nil, guard)
+
+	rec := httptest.NewRecorder()
+	h.Crash(rec, httptest.NewRequest("POST", "/fault/crash?delay=1h", nil))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "CHAOS_NOT_ALLOWED") {
+		t.Errorf("crash: status = %d, body = %s, want 403 CHAOS_NOT_ALLOWED", rec.Code, rec.Body)
+	}
+
+	// A goroutine panic crashes the process, so it is guarded as a crash.
+	rec = httptest.NewRecorder()
+	h.Panic(rec, httptest.NewRequest("POST", "/fault/panic?goroutine=true&delay=1h", nil))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "CHAOS_NOT_ALLOWED") {
+		t.Errorf("goroutine panic: status = %d, body = %s, want 403 CHAOS_NOT_ALLOWED", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Error(rec, httptest.NewRequest("GET", "/fault/error?rate=0", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first error: status = %d, want 200", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Error(rec, httptest.NewRequest("GET", "/fault/error?rate=0", nil))
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "CHAOS_COOLDOWN") {
+		t.Errorf("second error: status = %d, body = %s, want 429 CHAOS_COOLDOWN", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "3600" {
+		t.Errorf("Retry-After = %q, want 3600", got)
+	}
+}
+
+func TestFaultBomb(t *testing.T) {
+	h := NewFaultHandlers(true, nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.Bomb(rec, httptest.NewRequest("GET", "/fault/bomb?size=8Mi", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", got)
+	}
+	if rec.Body.Len() > 64<<10 {
+		t.Errorf("compressed body = %d bytes, want a highly compressed body", rec.Body.Len())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := io.Copy(io.Discard, zr); err != nil || n != 8<<20 {
+		t.Errorf("decompressed %d bytes (%v), want %d", n, err, 8<<20)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Bomb(rec, httptest.NewRequest("GET", "/fault/bomb?size=100Ki&encoding=identity", nil))
+	if rec.Body.Len() != 100<<10 || rec.Header().Get("Content-Length") != "102400" || rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("identity body = %d bytes with headers %v, want 102400 bytes uncompressed", rec.Body.Len(), rec.Header())
+	}
+}
+
+func TestFaultBombInvalid(t *testing.T) {
+	h := NewFaultHandlers(true, nil, nil)
+
+	for _, q := range []string{"size=0", "size=17Gi", "size=x", "encoding=br"} {
+		rec := httptest.NewRecorder()
+		h.Bomb(rec, httptest.NewRequest("GET", "/fault/bomb?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/fleet"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// maxBroadcastBody bounds the JSON command accepted by POST /admin/broadcast.
+const maxBroadcastBody = 64 << 10
+
+// FleetHandlers provides peer discovery and broadcast endpoints.
+type FleetHandlers struct {
+	authn       *auth.Authenticator
+	discoverer  fleet.Discoverer
+	broadcaster *fleet.Broadcaster
+}
+
+// NewFleetHandlers creates handlers for fleet endpoints. A nil discoverer
+// means fleet coordination is not configured.
+func NewFleetHandlers(authn *auth.Authenticator, d fleet.Discoverer, b *fleet.Broadcaster) *FleetHandlers {
+	return &FleetHandlers{authn: authn, discoverer: d, broadcaster: b}
+}
+
+// Register adds fleet routes to the mux.
+func (h *FleetHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/peers", h.Peers)
+	mux.HandleFunc("POST /admin/broadcast", h.Broadcast)
+}
+
+// Peers handles GET /admin/peers.
+func (h *FleetHandlers) Peers(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	peers, ok := h.discover(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(api.FleetPeersResponse{Count: len(peers), Peers: peers}); err != nil {
+		slog.Warn("failed to encode peers response", "error", err)
+	}
+}
+
+// Broadcast handles POST /admin/broadcast. The body is a JSON command such as
+// {"path": "/admin/error-rate", "params": {"rate": "0.2"}}. The caller's
+// credentials are forwarded so each peer enforces its own authorization.
+func (h *FleetHandlers) Broadcast(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	var cmd fleet.Command
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBroadcastBody)).Decode(&cmd); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "invalid JSON body: "+err.Error())
+		return
+	}
+	if err := cmd.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	peers, ok := h.discover(w, r)
+	if !ok {
+		return
+	}
+
+	header := http.Header{}
+	for _, k := range []string{"X-Admin-Token", "Authorization"} {
+		if v := r.Header.Get(k); v != "" {
+			header.Set(k, v)
+		}
+	}
+
+	results := h.broadcaster.Broadcast(r.Context(), peers, cmd, header)
+	resp := api.FleetBroadcastResponse{Peers: len(peers), Results: results}
+	for _, res := range results {
+		if res.Error == "" && res.Status/100 == 2 {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "command broadcast to fleet", map[string]any{
+		"method":    cmd.Method,
+		"path":      cmd.Path,
+		"peers":     resp.Peers,
+		"succeeded": resp.Succeeded,
+		"failed":    resp.Failed,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode broadcast response", "error", err)
+	}
+}
+
+func (h *FleetHandlers) discover(w http.ResponseWriter, r *http.Request) ([]fleet.Peer, bool) {
+	if h.discoverer == nil {
+		writeError(w, http.StatusNotFound, errcode.FleetNotConfigured, "peer discovery is not configured")
+		return nil, false
+	}
+	peers, err := h.discoverer.Peers(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, errcode.DiscoveryFailed, err.Error())
+		return nil, false
+	}
+	return peers, true
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fleet"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+type staticPeers []fleet.Peer
+
+func (s staticPeers) Peers(context.Context) ([]fleet.Peer, error) {
+	return s, nil
+}
+
+func TestFleetBroadcast(t *testing.T) {
+	var hits atomic.Int32
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer peer.Close()
+
+	addr := strings.TrimPrefix(peer.URL, "http://")
+	h := NewFleetHandlers(auth.New("", nil), staticPeers{{Name: "p1", Addr: addr}, {Name: "p2", Addr: addr}}, fleet.NewBroadcaster(time.Second, "http"))
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	body := `{"path": "/admin/error-rate", "params": {"rate": "0.2"}}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/broadcast", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp api.FleetBroadcastResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Peers != 2 || resp.Succeeded != 2 || hits.Load() != 2 {
+		t.Errorf("resp = %+v, hits = %d", resp, hits.Load())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/peers", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"count":2`) {
+		t.Errorf("peers: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestFleetNotConfigured(t *testing.T) {
+	h := NewFleetHandlers(auth.New("", nil), nil, fleet.NewBroadcaster(time.Second, "http"))
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/broadcast", strings.NewReader(`{"path": "/admin/gc"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/broadcast", strings.NewReader(`{"path": "/admin/broadcast"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("recursive broadcast status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// Framing modes. Those marked raw hijack the connection to write bytes
+// net/http would refuse to, so they need HTTP/1.x.
+const (
+	// framingChunkedTrailers streams a chunked body followed by trailers.
+	framingChunkedTrailers = "chunked-trailers"
+	// framingContentLengthLong declares more bytes than are sent, then
+	// closes the connection, so the client sees an unexpected EOF.
+	framingContentLengthLong = "content-length-long"
+	// framingContentLengthShort declares half the bytes that are sent
+	// (raw), leaving the rest on the connection where a client reusing it
+	// reads them as the start of the next response.
+	framingContentLengthShort = "content-length-short"
+	// framingChunkedEOF sends chunks but closes the connection before the
+	// terminating chunk (raw).
+	framingChunkedEOF = "chunked-eof"
+	// framingChunkedInvalid sends a chunk whose size line is not hex (raw).
+	framingChunkedInvalid = "chunked-invalid"
+	// framingChunkedContentLength sends both Transfer-Encoding: chunked and
+	// a Content-Length, which RFC 9112 says a proxy must not forward as is
+	// (raw).
+	framingChunkedContentLength = "chunked-content-length"
+	// framingEOFHeaders closes the connection partway through the response
+	// headers (raw).
+	framingEOFHeaders = "eof-headers"
+)
+
+// maxFramingSize bounds the body of a framing response.
+const maxFramingSize = 16 << 20
+
+// Framing trailers, declared up front and sent after a chunked body.
+const (
+	framingChunksTrailer   = "X-Hotpod-Chunks"
+	framingChecksumTrailer = "X-Hotpod-Checksum"
+)
+
+// FramingHandlers provides the /framing endpoint, which produces responses
+// with unusual or broken HTTP/1.1 framing to exercise how proxies and
+// clients handle them.
+type FramingHandlers struct{}
+
+// NewFramingHandlers creates handlers for the framing endpoint.
+func NewFramingHandlers() *FramingHandlers {
+	return &FramingHandlers{}
+}
+
+// Register adds framing routes to the mux.
+func (h *FramingHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /framing", h.Framing)
+}
+
+// Framing handles GET /framing?mode=M&size=N&chunks=C, responding with a
+// size-byte text body framed as mode describes. chunks is the number of
+// chunks chunked modes split the body into.
+func (h *FramingHandlers) Framing(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("mode")
+	raw := false
+	switch mode {
+	case framingChunkedTrailers, framingContentLengthLong:
+	case framingContentLengthShort, framingChunkedEOF, framingChunkedInvalid, framingChunkedContentLength, framingEOFHeaders:
+		raw = true
+	default:
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("mode must be %s, %s, %s, %s, %s, %s, or %s",
+			framingChunkedTrailers, framingContentLengthLong, framingContentLengthShort, framingChunkedEOF,
+			framingChunkedInvalid, framingChunkedContentLength, framingEOFHeaders))
+		return
+	}
+
+	size, err := parseSize(r, "size", 1<<10)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if size < 2 || size > maxFramingSize {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("size must be between 2 and %s", formatSize(maxFramingSize)))
+		return
+	}
+
+	chunks, err := parseInt(r, "chunks", 4)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if chunks < 1 || int64(chunks) > size {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "chunks must be between 1 and size")
+		return
+	}
+
+	if raw && r.ProtoMajor != 1 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("mode %s requires HTTP/1.x", mode))
+		return
+	}
+
+	body := framingBody(size)
+	switch mode {
+	case framingChunkedTrailers:
+		writeChunkedTrailers(w, body, chunks)
+	case framingContentLengthLong:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)*2))
+		if _, err := w.Write(body); err != nil {
+			slog.Debug("framing response write ended early", "error", err)
+		}
+	default:
+		writeRawFraming(w, mode, body, chunks)
+	}
+}
+
+// framingBody returns size bytes of newline-separated text.
+func framingBody(size int64) []byte {
+	line := []byte("hotpod framing test\n")
+	return bytes.Repeat(line, int(size)/len(line)+1)[:size]
+}
+
+// splitChunks splits body into n nearly equal chunks.
+func splitChunks(body []byte, n int) [][]byte {
+	out := make([][]byte, 0, n)
+	for i := range n {
+		out = append(out, body[len(body)*i/n:len(body)*(i+1)/n])
+	}
+	return out
+}
+
+func writeChunkedTrailers(w http.ResponseWriter, body []byte, chunks int) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Trailer", framingChunksTrailer+", "+framingChecksumTrailer)
+	w.WriteHeader(http.StatusOK)
+	for _, chunk := range splitChunks(body, chunks) {
+		if _, err := w.Write(chunk); err != nil {
+			slog.Debug("framing response write ended early", "error", err)
+			return
+		}
+		// Flushing each chunk keeps net/http from coalescing them.
+		if err := rc.Flush(); err != nil {
+			slog.Debug("framing response cannot be flushed", "error", err)
+		}
+	}
+	sum := sha256.Sum256(body)
+	w.Header().Set(framingChunksTrailer, strconv.Itoa(chunks))
+	w.Header().Set(framingChecksumTrailer, "sha256:"+hex.EncodeToString(sum[:]))
+}
+
+// writeRawFraming hijacks the connection and writes the response for a raw
+// mode byte by byte, then closes the connection.
+func writeRawFraming(w http.ResponseWriter, mode string, body []byte, chunks int) {
+	conn, bufrw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errcode.InternalError, "connection cannot be hijacked: "+err.Error())
+		return
+	}
+	defer conn.Close()
+
+	hdr := w.Header().Clone()
+	hdr.Set("Content-Type", "text/plain; charset=utf-8")
+	hdr.Set("Date", wallclock.Now().UTC().Format(http.TimeFormat))
+	switch mode {
+	case framingContentLengthShort:
+		hdr.Set("Content-Length", strconv.Itoa(len(body)/2))
+	case framingChunkedContentLength:
+		hdr.Set("Content-Length", strconv.Itoa(len(body)))
+		fallthrough
+	case framingChunkedEOF, framingChunkedInvalid:
+		hdr.Set("Transfer-Encoding", "chunked")
+	}
+
+	if mode == framingEOFHeaders {
+		// Stop partway through a header line.
+		bufrw.WriteString("HTTP/1.1 200 OK\r\nContent-Type: text/pl")
+		flushRaw(bufrw)
+		return
+	}
+
+	bufrw.WriteString("HTTP/1.1 200 OK\r\n")
+	hdr.Write(bufrw)
+	bufrw.WriteString("\r\n")
+
+	switch mode {
+	case framingContentLengthShort:
+		bufrw.Write(body)
+	case framingChunkedEOF:
+		// Send all but the last chunk, and no terminating chunk.
+		parts := splitChunks(body, max(chunks, 2))
+		for _, chunk := range parts[:len(parts)-1] {
+			writeRawChunk(bufrw, chunk)
+		}
+	case framingChunkedInvalid:
+		parts := splitChunks(body, max(chunks, 2))
+		writeRawChunk(bufrw, parts[0])
+		fmt.Fprintf(bufrw, "zz\r\n%s\r\n0\r\n\r\n", parts[1])
+	case framingChunkedContentLength:
+		for _, chunk := range splitChunks(body, chunks) {
+			writeRawChunk(bufrw, chunk)
+		}
+		bufrw.WriteString("0\r\n\r\n")
+	}
+	flushRaw(bufrw)
+}
+
+func writeRawChunk(w *bufio.ReadWriter, chunk []byte) {
+	fmt.Fprintf(w, "%x\r\n%s\r\n", len(chunk), chunk)
+}
+
+func flushRaw(w *bufio.ReadWriter) {
+	if err := w.Flush(); err != nil {
+		slog.Debug("raw framing response write ended early", "error", err)
+	}
+}
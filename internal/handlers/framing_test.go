@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newFramingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	NewFramingHandlers().Register(mux)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// rawGet sends a GET for path over a new connection and returns everything
+// the server sends before closing it.
+func rawGet(t *testing.T, ts *httptest.Server, path string) string {
+	t.Helper()
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: hotpod\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestFramingChunkedTrailers(t *testing.T) {
+	ts := newFramingServer(t)
+
+	resp, err := http.Get(ts.URL + "/framing?mode=chunked-trailers&size=100&chunks=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.TransferEncoding) != 1 || resp.TransferEncoding[0] != "chunked" {
+		t.Errorf("transfer encoding = %v, want chunked", resp.TransferEncoding)
+	}
+	sum := sha256.Sum256(body)
+	if got, want := resp.Trailer.Get(framingChecksumTrailer), "sha256:"+hex.EncodeToString(sum[:]); got != want || len(body) != 100 {
+		t.Errorf("checksum trailer = %q for %d bytes, want %q for 100", got, len(body), want)
+	}
+	if got := resp.Trailer.Get(framingChunksTrailer); got != "5" {
+		t.Errorf("chunks trailer = %q, want 5", got)
+	}
+}
+
+func TestFramingBroken(t *testing.T) {
+	ts := newFramingServer(t)
+
+	for _, mode := range []string{"content-length-long", "chunked-eof", "chunked-invalid", "eof-headers"} {
+		resp, err := http.Get(ts.URL + "/framing?mode=" + mode)
+		if err == nil {
+			_, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		if err == nil {
+			t.Errorf("%s: response read cleanly, want a framing error", mode)
+		}
+		if mode == "content-length-long" && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%s: error = %v, want an unexpected EOF", mode, err)
+		}
+	}
+}
+
+func TestFramingRaw(t *testing.T) {
+	ts := newFramingServer(t)
+
+	out := rawGet(t, ts, "/framing?mode=content-length-short&size=100")
+	head, body, _ := strings.Cut(out, "\r\n\r\n")
+	if !strings.Contains(head, "Content-Length: 50") || len(body) != 100 {
+		t.Errorf("content-length-short sent %d body bytes with headers %q, want 100 with Content-Length: 50", len(body), head)
+	}
+
+	out = rawGet(t, ts, "/framing?mode=chunked-content-length&size=100")
+	head, _, _ = strings.Cut(out, "\r\n\r\n")
+	if !strings.Contains(head, "Transfer-Encoding: chunked") || !strings.Contains(head, "Content-Length: 100") {
+		t.Errorf("chunked-content-length headers = %q, want both framings", head)
+	}
+
+	if _, err := http.ReadResponse(bufio.NewReader(strings.NewReader(rawGet(t, ts, "/framing?mode=eof-headers"))), nil); err == nil {
+		t.Error("eof-headers response parsed cleanly")
+	}
+}
+
+func TestFramingInvalid(t *testing.T) {
+	h := NewFramingHandlers()
+
+	for _, q := range []string{"", "mode=gzip", "mode=chunked-eof&size=1", "mode=chunked-eof&size=64Mi", "mode=chunked-eof&chunks=0", "mode=chunked-eof&size=10&chunks=11"} {
+		rec := httptest.NewRecorder()
+		h.Framing(rec, httptest.NewRequest("GET", "/framing?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400", q, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/framing?mode=chunked-eof", nil)
+	req.ProtoMajor = 2
+	rec := httptest.NewRecorder()
+	h.Framing(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("raw mode over HTTP/2: status = %d, want 400", rec.Code)
+	}
+}
@@ -4,18 +4,34 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/ripta/hotpod/internal/health"
 	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 // HealthHandlers provides health check endpoint handlers.
 type HealthHandlers struct {
-	lifecycle *server.Lifecycle
+	lifecycle    *server.Lifecycle
+	dependencies *health.Dependencies
+	delays       *health.Delays
+	// backlog, when set, makes /readyz report not ready under a queue backlog
+	backlog *health.Backlog
 }
 
-// NewHealthHandlers creates handlers for health endpoints.
-func NewHealthHandlers(lc *server.Lifecycle) *HealthHandlers {
-	return &HealthHandlers{lifecycle: lc}
+// NewHealthHandlers creates handlers for health endpoints. Failing
+// dependency checks in deps make /readyz report not ready, and each probe
+// responds after its delay in delays. Either may be nil.
+func NewHealthHandlers(lc *server.Lifecycle, deps *health.Dependencies, delays *health.Delays) *HealthHandlers {
+	return &HealthHandlers{lifecycle: lc, dependencies: deps, delays: delays}
+}
+
+// SetBacklog gates /readyz on a queue backlog as well. The queue is created
+// after the health endpoints, so it is set separately.
+func (h *HealthHandlers) SetBacklog(b *health.Backlog) {
+	h.backlog = b
 }
 
 // Register adds health routes to the mux.
@@ -25,45 +41,57 @@ func (h *HealthHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /startupz", h.Startupz)
 }
 
-// HealthResponse is the JSON response for health endpoints.
-type HealthResponse struct {
-	// Status is "ok", "not_ready", or "starting"
-	Status string `json:"status"`
-	// Reason explains why the server is not ready (omitted when ok)
-	Reason string `json:"reason,omitempty"`
-	// Remaining is the time until startup completes (only for /startupz)
-	Remaining string `json:"remaining,omitempty"`
+// healthTime formats the current wall clock for health responses.
+func healthTime() string {
+	return wallclock.Now().UTC().Format(time.RFC3339Nano)
 }
 
 func (h *HealthHandlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	h.delays.Wait(r.Context(), health.ProbeHealthz)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(HealthResponse{Status: "ok"}); err != nil {
+	if err := json.NewEncoder(w).Encode(api.HealthResponse{Status: "ok", Time: healthTime()}); err != nil {
 		slog.Warn("failed to encode healthz response", "error", err)
 	}
 }
 
 func (h *HealthHandlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	h.delays.Wait(r.Context(), health.ProbeReadyz)
 	w.Header().Set("Content-Type", "application/json")
 
-	var resp HealthResponse
+	var resp api.HealthResponse
 	var status int
 
-	switch h.lifecycle.State() {
-	case server.StateStarting:
+	switch state := h.lifecycle.State(); {
+	case state == server.StateReady && h.lifecycle.InPreStop():
+		status = http.StatusServiceUnavailable
+		resp = api.HealthResponse{Status: "not_ready", Reason: "preStop hook in progress"}
+	case state == server.StateStarting:
 		status = http.StatusServiceUnavailable
-		resp = HealthResponse{Status: "not_ready", Reason: "server is starting"}
-	case server.StateShuttingDown:
+		resp = api.HealthResponse{Status: "not_ready", Reason: "server is starting"}
+	case state == server.StateShuttingDown:
 		status = http.StatusServiceUnavailable
-		resp = HealthResponse{Status: "not_ready", Reason: "server is shutting down"}
-	case server.StateReady:
+		resp = api.HealthResponse{Status: "not_ready", Reason: "server is shutting down"}
+	case state == server.StateReady:
+		if err := h.dependencies.Err(); err != nil {
+			status = http.StatusServiceUnavailable
+			resp = api.HealthResponse{Status: "not_ready", Reason: err.Error()}
+			break
+		}
+		if err := h.backlog.Err(); err != nil {
+			status = http.StatusServiceUnavailable
+			resp = api.HealthResponse{Status: "not_ready", Reason: err.Error()}
+			break
+		}
 		status = http.StatusOK
-		resp = HealthResponse{Status: "ok"}
+		resp = api.HealthResponse{Status: "ok"}
 	default:
 		status = http.StatusInternalServerError
-		resp = HealthResponse{Status: "error", Reason: "unknown server state"}
+		resp = api.HealthResponse{Status: "error", Reason: "unknown server state"}
 	}
 
+	resp.Time = healthTime()
+	resp.Checks = h.dependencies.List()
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Warn("failed to encode readyz response", "error", err)
@@ -71,15 +99,17 @@ func (h *HealthHandlers) Readyz(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *HealthHandlers) Startupz(w http.ResponseWriter, r *http.Request) {
+	h.delays.Wait(r.Context(), health.ProbeStartupz)
 	w.Header().Set("Content-Type", "application/json")
 
 	if h.lifecycle.State() == server.StateStarting {
 		remaining := h.lifecycle.StartupRemaining()
 		w.WriteHeader(http.StatusServiceUnavailable)
-		if err := json.NewEncoder(w).Encode(HealthResponse{
+		if err := json.NewEncoder(w).Encode(api.HealthResponse{
 			Status:    "starting",
 			Reason:    "startup in progress",
 			Remaining: remaining.String(),
+			Time:      healthTime(),
 		}); err != nil {
 			slog.Warn("failed to encode startupz response", "error", err)
 		}
@@ -87,7 +117,7 @@ func (h *HealthHandlers) Startupz(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(HealthResponse{Status: "ok"}); err != nil {
+	if err := json.NewEncoder(w).Encode(api.HealthResponse{Status: "ok", Time: healthTime()}); err != nil {
 		slog.Warn("failed to encode startupz response", "error", err)
 	}
 }
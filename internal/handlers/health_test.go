@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ripta/hotpod/internal/health"
 	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 type healthHandlerTest struct {
@@ -24,7 +26,7 @@ var healthHandlerTests = []healthHandlerTest{
 
 func TestHealthz(t *testing.T) {
 	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
-	h := NewHealthHandlers(lc)
+	h := NewHealthHandlers(lc, nil, nil)
 
 	req := httptest.NewRequest("GET", "/healthz", nil)
 	rec := httptest.NewRecorder()
@@ -35,7 +37,7 @@ func TestHealthz(t *testing.T) {
 		t.Errorf("Healthz status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp HealthResponse
+	var resp api.HealthResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -49,7 +51,7 @@ func TestReadyzWhenReady(t *testing.T) {
 	// Give it a moment to become ready
 	time.Sleep(10 * time.Millisecond)
 
-	h := NewHealthHandlers(lc)
+	h := NewHealthHandlers(lc, nil, nil)
 
 	req := httptest.NewRequest("GET", "/readyz", nil)
 	rec := httptest.NewRecorder()
@@ -60,7 +62,7 @@ func TestReadyzWhenReady(t *testing.T) {
 		t.Errorf("Readyz status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp HealthResponse
+	var resp api.HealthResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -71,7 +73,7 @@ func TestReadyzWhenReady(t *testing.T) {
 
 func TestReadyzDuringStartup(t *testing.T) {
 	lc := server.NewLifecycle(1*time.Hour, 0, 0, 30*time.Second, false)
-	h := NewHealthHandlers(lc)
+	h := NewHealthHandlers(lc, nil, nil)
 
 	req := httptest.NewRequest("GET", "/readyz", nil)
 	rec := httptest.NewRecorder()
@@ -82,7 +84,7 @@ func TestReadyzDuringStartup(t *testing.T) {
 		t.Errorf("Readyz status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
 	}
 
-	var resp HealthResponse
+	var resp api.HealthResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -94,12 +96,70 @@ func TestReadyzDuringStartup(t *testing.T) {
 	}
 }
 
+func TestReadyzDependencies(t *testing.T) {
+	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
+	time.Sleep(10 * time.Millisecond)
+
+	deps := health.NewDependencies()
+	deps.Set(api.DependencyCheck{Name: "cache"})
+	deps.Set(api.DependencyCheck{Name: "db", Failing: true, Message: "connection refused"})
+	h := NewHealthHandlers(lc, deps, nil)
+
+	rec := httptest.NewRecorder()
+	h.Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Readyz status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	var resp api.HealthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Reason != "dependency check failing: db: connection refused" || len(resp.Checks) != 2 {
+		t.Errorf("Readyz response = %+v, want db failing with 2 checks", resp)
+	}
+
+	deps.Set(api.DependencyCheck{Name: "db"})
+	rec = httptest.NewRecorder()
+	h.Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Readyz status after recovery = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestReadyzQueueBacklog(t *testing.T) {
+	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
+	time.Sleep(10 * time.Millisecond)
+
+	depth := 3
+	backlog := health.NewBacklog(2, 1)
+	backlog.Watch(func() int { return depth })
+	h := NewHealthHandlers(lc, nil, nil)
+	h.SetBacklog(backlog)
+
+	for _, tt := range []struct {
+		depth int
+		want  int
+	}{
+		{3, http.StatusServiceUnavailable},
+		{2, http.StatusServiceUnavailable},
+		{1, http.StatusOK},
+		{2, http.StatusOK},
+	} {
+		depth = tt.depth
+		rec := httptest.NewRecorder()
+		h.Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+		if rec.Code != tt.want {
+			t.Errorf("depth %d: Readyz status = %d, want %d", tt.depth, rec.Code, tt.want)
+		}
+	}
+}
+
 func TestStartupzWhenReady(t *testing.T) {
 	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
 	// Give it a moment to become ready
 	time.Sleep(10 * time.Millisecond)
 
-	h := NewHealthHandlers(lc)
+	h := NewHealthHandlers(lc, nil, nil)
 
 	req := httptest.NewRequest("GET", "/startupz", nil)
 	rec := httptest.NewRecorder()
@@ -113,7 +173,7 @@ func TestStartupzWhenReady(t *testing.T) {
 
 func TestStartupzDuringStartup(t *testing.T) {
 	lc := server.NewLifecycle(1*time.Hour, 0, 0, 30*time.Second, false)
-	h := NewHealthHandlers(lc)
+	h := NewHealthHandlers(lc, nil, nil)
 
 	req := httptest.NewRequest("GET", "/startupz", nil)
 	rec := httptest.NewRecorder()
@@ -124,7 +184,7 @@ func TestStartupzDuringStartup(t *testing.T) {
 		t.Errorf("Startupz status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
 	}
 
-	var resp HealthResponse
+	var resp api.HealthResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -138,7 +198,7 @@ func TestStartupzDuringStartup(t *testing.T) {
 
 func TestHealthHandlersRegister(t *testing.T) {
 	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
-	h := NewHealthHandlers(lc)
+	h := NewHealthHandlers(lc, nil, nil)
 
 	mux := http.NewServeMux()
 	h.Register(mux)
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/health"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// HealthDelayHandlers reads and sets the response delay of the health probe
+// endpoints.
+type HealthDelayHandlers struct {
+	authn  *auth.Authenticator
+	delays *health.Delays
+}
+
+// NewHealthDelayHandlers creates handlers for the health delay admin endpoints.
+func NewHealthDelayHandlers(authn *auth.Authenticator, delays *health.Delays) *HealthDelayHandlers {
+	return &HealthDelayHandlers{authn: authn, delays: delays}
+}
+
+// Register adds health delay routes to the mux.
+func (h *HealthDelayHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/health-delay", h.Get)
+	mux.HandleFunc("POST /admin/health-delay", h.Set)
+	mux.HandleFunc("DELETE /admin/health-delay", h.Clear)
+}
+
+// Get handles GET /admin/health-delay.
+func (h *HealthDelayHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeDelays(w)
+}
+
+// Set handles POST /admin/health-delay?delay=D&jitter=J&probe=P. Without a
+// probe, the delay applies to /healthz, /readyz, and /startupz; a zero delay
+// and jitter disables it.
+func (h *HealthDelayHandlers) Set(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	delay, err := parseDuration(r, "delay", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	jitter, err := parseDuration(r, "jitter", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	probe := r.URL.Query().Get("probe")
+	if err := h.delays.Set(probe, health.Delay{Fixed: delay, Jitter: jitter}); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	slog.Info("health delay set", "probe", probe, "delay", delay, "jitter", jitter)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "health delay set", map[string]any{
+		"probe":  probe,
+		"delay":  delay.String(),
+		"jitter": jitter.String(),
+	})
+	h.writeDelays(w)
+}
+
+// Clear handles DELETE /admin/health-delay, disabling all probe delays.
+func (h *HealthDelayHandlers) Clear(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	h.delays.Clear()
+	slog.Info("health delays cleared")
+	events.Record(slog.LevelInfo, events.TypeAdmin, "health delays cleared", nil)
+	h.writeDelays(w)
+}
+
+func (h *HealthDelayHandlers) writeDelays(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(api.HealthDelaysResponse{Delays: h.delays.List()}); err != nil {
+		slog.Warn("failed to encode health delay response", "error", err)
+	}
+}
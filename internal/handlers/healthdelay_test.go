@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/health"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestHealthDelayHandlers(t *testing.T) {
+	delays := health.NewDelays()
+	mux := http.NewServeMux()
+	NewHealthDelayHandlers(auth.New("", nil), delays).Register(mux)
+
+	for _, tt := range []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantDelays int
+	}{
+		{"GET", "/admin/health-delay", http.StatusOK, 0},
+		{"POST", "/admin/health-delay?delay=2s", http.StatusOK, 3},
+		{"POST", "/admin/health-delay?probe=startupz&delay=0s", http.StatusOK, 2},
+		{"POST", "/admin/health-delay?probe=readyz&delay=1s&jitter=500ms", http.StatusOK, 2},
+		{"POST", "/admin/health-delay?probe=livez&delay=1s", http.StatusBadRequest, 0},
+		{"POST", "/admin/health-delay?delay=10m", http.StatusBadRequest, 0},
+		{"POST", "/admin/health-delay?jitter=soon", http.StatusBadRequest, 0},
+		{"DELETE", "/admin/health-delay", http.StatusOK, 0},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.path, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp api.HealthDelaysResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if len(resp.Delays) != tt.wantDelays {
+			t.Errorf("%s %s: delays = %+v, want %d", tt.method, tt.path, resp.Delays, tt.wantDelays)
+		}
+	}
+}
+
+func TestHealthzDelay(t *testing.T) {
+	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
+	delays := health.NewDelays()
+	delays.Set(health.ProbeHealthz, health.Delay{Fixed: 30 * time.Millisecond})
+	h := NewHealthHandlers(lc, nil, delays)
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	h.Healthz(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Healthz took %s, want at least 30ms", elapsed)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Healthz status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	start = time.Now()
+	h.Readyz(httptest.NewRecorder(), httptest.NewRequest("GET", "/readyz", nil))
+	if elapsed := time.Since(start); elapsed >= 30*time.Millisecond {
+		t.Errorf("Readyz took %s, want no delay", elapsed)
+	}
+}
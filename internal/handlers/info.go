@@ -1,29 +1,39 @@
 package handlers
 
 import (
-	"encoding/json"
-	"log/slog"
 	"net/http"
 	"runtime"
 	"time"
 
+	"github.com/ripta/hotpod/internal/cgroup"
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 // InfoHandlers provides the /info endpoint handler.
 type InfoHandlers struct {
 	version   string
+	build     api.InfoBuild
 	lifecycle *server.Lifecycle
 	config    *config.Config
+	// cgroupRoot and oomScoreAdjPath locate the container's memory events
+	// and OOM settings
+	cgroupRoot      string
+	oomScoreAdjPath string
 }
 
-// NewInfoHandlers creates handlers for the info endpoint.
-func NewInfoHandlers(version string, lifecycle *server.Lifecycle, cfg *config.Config) *InfoHandlers {
+// NewInfoHandlers creates handlers for the info endpoint, reporting build as
+// the running binary's build.
+func NewInfoHandlers(version string, build api.InfoBuild, lifecycle *server.Lifecycle, cfg *config.Config) *InfoHandlers {
 	return &InfoHandlers{
-		version:   version,
-		lifecycle: lifecycle,
-		config:    cfg,
+		version:         version,
+		build:           build,
+		lifecycle:       lifecycle,
+		config:          cfg,
+		cgroupRoot:      cgroup.DefaultRoot,
+		oomScoreAdjPath: cgroup.DefaultOOMScoreAdjPath,
 	}
 }
 
@@ -32,81 +42,42 @@ func (h *InfoHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /info", h.Info)
 }
 
-// InfoResponse is the JSON response for /info.
-type InfoResponse struct {
-	Version   string        `json:"version"`
-	Uptime    string        `json:"uptime"`
-	Lifecycle InfoLifecycle `json:"lifecycle"`
-	Resources InfoResources `json:"resources"`
-	Config    InfoConfig    `json:"config"`
-}
-
-// InfoLifecycle contains lifecycle state information.
-type InfoLifecycle struct {
-	State            string `json:"state"`
-	StartedAt        string `json:"started_at"`
-	ReadyAt          string `json:"ready_at,omitempty"`
-	StartupComplete  bool   `json:"startup_complete"`
-	ShuttingDown     bool   `json:"shutting_down"`
-	InFlightRequests int64  `json:"in_flight_requests"`
-}
-
-// InfoResources contains runtime resource information.
-type InfoResources struct {
-	CPUCores    int    `json:"cpu_cores"`
-	MemoryTotal uint64 `json:"memory_total"`
-	MemoryUsed  uint64 `json:"memory_used"`
-	Goroutines  int    `json:"goroutines"`
-}
-
-// InfoConfig contains configuration information.
-type InfoConfig struct {
-	Port             int    `json:"port"`
-	LogLevel         string `json:"log_level"`
-	MaxCPUDuration   string `json:"max_cpu_duration"`
-	MaxMemorySize    string `json:"max_memory_size"`
-	MaxIOSize        string `json:"max_io_size"`
-	IOPath           string `json:"io_path"`
-	MaxConcurrentOps int    `json:"max_concurrent_ops"`
-	RequestTimeout   string `json:"request_timeout"`
-	StartupDelay     string `json:"startup_delay"`
-	StartupJitter    string `json:"startup_jitter"`
-	ShutdownDelay    string `json:"shutdown_delay"`
-	ShutdownTimeout  string `json:"shutdown_timeout"`
-	DrainImmediately bool   `json:"drain_immediately"`
-}
-
 func (h *InfoHandlers) Info(w http.ResponseWriter, r *http.Request) {
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
 
+	skew := wallclock.Skew()
 	startedAt := h.lifecycle.StartTime()
 	readyAt := h.lifecycle.ReadyTime()
 	uptime := time.Since(startedAt)
 
-	lifecycle := InfoLifecycle{
+	lifecycle := api.InfoLifecycle{
 		State:            h.lifecycle.State().String(),
-		StartedAt:        startedAt.Format(time.RFC3339),
+		StartedAt:        startedAt.Add(skew).Format(time.RFC3339),
 		StartupComplete:  h.lifecycle.IsReady(),
 		ShuttingDown:     h.lifecycle.IsShuttingDown(),
 		InFlightRequests: h.lifecycle.InFlightRequests(),
 	}
 	if !readyAt.IsZero() {
-		lifecycle.ReadyAt = readyAt.Format(time.RFC3339)
+		lifecycle.ReadyAt = readyAt.Add(skew).Format(time.RFC3339)
 	}
 
-	resp := InfoResponse{
-		Version:   h.version,
-		Uptime:    uptime.Round(time.Second).String(),
-		Lifecycle: lifecycle,
-		Resources: InfoResources{
+	resp := api.InfoResponse{
+		Version:    h.version,
+		APIVersion: api.Version,
+		Variant:    h.config.Variant,
+		Uptime:     uptime.Round(time.Second).String(),
+		Time:       wallclock.Now().UTC().Format(time.RFC3339Nano),
+		Lifecycle:  lifecycle,
+		Resources: api.InfoResources{
 			CPUCores:    runtime.NumCPU(),
 			MemoryTotal: memStats.Sys,
 			MemoryUsed:  memStats.Alloc,
 			Goroutines:  runtime.NumGoroutine(),
 		},
-		Config: InfoConfig{
+		Config: api.InfoConfig{
 			Port:             h.config.Port,
+			MgmtPort:         h.config.MgmtPort,
 			LogLevel:         h.config.LogLevel,
 			MaxCPUDuration:   h.config.MaxCPUDuration.String(),
 			MaxMemorySize:    formatSize(h.config.MaxMemorySize),
@@ -114,16 +85,55 @@ func (h *InfoHandlers) Info(w http.ResponseWriter, r *http.Request) {
 			IOPath:           h.config.IOPath(),
 			MaxConcurrentOps: h.config.MaxConcurrentOps,
 			RequestTimeout:   h.config.RequestTimeout.String(),
+			RequestTimeouts:  formatRequestTimeouts(h.config),
 			StartupDelay:     h.config.StartupDelay.String(),
 			StartupJitter:    h.config.StartupJitter.String(),
 			ShutdownDelay:    h.config.ShutdownDelay.String(),
 			ShutdownTimeout:  h.config.ShutdownTimeout.String(),
 			DrainImmediately: h.config.DrainImmediately,
+			SigtermBehavior:  h.config.SigtermBehavior,
 		},
+		Build:    h.build,
+		Features: h.config.Features(),
+	}
+	if skew != 0 {
+		resp.ClockSkew = skew.String()
 	}
+	h.addMemoryControls(&resp.Resources)
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		slog.Warn("failed to encode info response", "error", err)
+	writeNegotiated(w, r, resp)
+}
+
+// addMemoryControls reports the OOM score adjustment and cgroup memory
+// settings, omitting any that cannot be read.
+func (h *InfoHandlers) addMemoryControls(res *api.InfoResources) {
+	if adj, err := cgroup.ReadOOMScoreAdj(h.oomScoreAdjPath); err == nil {
+		res.OOMScoreAdj = &adj
+	}
+	if high, err := cgroup.ReadMemoryHigh(h.cgroupRoot); err == nil {
+		res.MemoryHigh = high
+	}
+	if events, err := cgroup.ReadMemoryEvents(h.cgroupRoot); err == nil {
+		res.MemoryEvents = &api.MemoryEvents{
+			Low:     events.Low,
+			High:    events.High,
+			Max:     events.Max,
+			OOM:     events.OOM,
+			OOMKill: events.OOMKill,
+		}
+	}
+}
+
+// formatRequestTimeouts returns the per-endpoint-group request timeout
+// overrides, or nil if there are none.
+func formatRequestTimeouts(cfg *config.Config) map[string]string {
+	timeouts, _ := cfg.RequestTimeoutOverrides()
+	if len(timeouts) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(timeouts))
+	for group, d := range timeouts {
+		out[group] = d.String()
 	}
+	return out
 }
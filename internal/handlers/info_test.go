@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 func TestInfoEndpoint(t *testing.T) {
@@ -22,13 +26,19 @@ func TestInfoEndpoint(t *testing.T) {
 		MaxConcurrentOps: 100,
 		RequestTimeout:   5 * time.Minute,
 		ShutdownTimeout:  30 * time.Second,
+		ReadOnly:         true,
 	}
 
 	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
 	// Wait a bit for lifecycle to become ready
 	time.Sleep(10 * time.Millisecond)
 
-	h := NewInfoHandlers("test-version", lc, cfg)
+	build := api.InfoBuild{
+		Commit:       "abc123",
+		GoVersion:    "go1.24.1",
+		Dependencies: []api.InfoDependency{{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"}},
+	}
+	h := NewInfoHandlers("test-version", build, lc, cfg)
 
 	mux := http.NewServeMux()
 	h.Register(mux)
@@ -41,7 +51,7 @@ func TestInfoEndpoint(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp InfoResponse
+	var resp api.InfoResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -49,6 +59,9 @@ func TestInfoEndpoint(t *testing.T) {
 	if resp.Version != "test-version" {
 		t.Errorf("response.Version = %q, want \"test-version\"", resp.Version)
 	}
+	if resp.APIVersion != api.Version {
+		t.Errorf("response.APIVersion = %q, want %q", resp.APIVersion, api.Version)
+	}
 
 	if resp.Lifecycle.State != "ready" {
 		t.Errorf("response.Lifecycle.State = %q, want \"ready\"", resp.Lifecycle.State)
@@ -77,6 +90,13 @@ func TestInfoEndpoint(t *testing.T) {
 	if resp.Config.MaxConcurrentOps != 100 {
 		t.Errorf("response.Config.MaxConcurrentOps = %d, want 100", resp.Config.MaxConcurrentOps)
 	}
+
+	if resp.Build.Commit != "abc123" || resp.Build.GoVersion != "go1.24.1" || len(resp.Build.Dependencies) != 1 {
+		t.Errorf("response.Build = %+v, want %+v", resp.Build, build)
+	}
+	if !slices.Equal(resp.Features, []string{"read-only"}) {
+		t.Errorf("response.Features = %v, want [read-only]", resp.Features)
+	}
 }
 
 func TestInfoDuringStartup(t *testing.T) {
@@ -95,13 +115,13 @@ func TestInfoDuringStartup(t *testing.T) {
 	// Create lifecycle with startup delay
 	lc := server.NewLifecycle(1*time.Second, 0, 0, 30*time.Second, false)
 
-	h := NewInfoHandlers("test-version", lc, cfg)
+	h := NewInfoHandlers("test-version", api.InfoBuild{}, lc, cfg)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	rec := httptest.NewRecorder()
 	h.Info(rec, req)
 
-	var resp InfoResponse
+	var resp api.InfoResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -133,7 +153,7 @@ func TestInfoContentType(t *testing.T) {
 	}
 
 	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
-	h := NewInfoHandlers("test-version", lc, cfg)
+	h := NewInfoHandlers("test-version", api.InfoBuild{}, lc, cfg)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	rec := httptest.NewRecorder()
@@ -144,3 +164,40 @@ func TestInfoContentType(t *testing.T) {
 		t.Errorf("Content-Type = %q, want \"application/json\"", contentType)
 	}
 }
+
+func TestInfoMemoryControls(t *testing.T) {
+	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
+	h := NewInfoHandlers("test-version", api.InfoBuild{}, lc, &config.Config{})
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"memory.high":   "536870912\n",
+		"memory.events": "low 0\nhigh 7\nmax 0\noom 1\noom_kill 1\n",
+		"oom_score_adj": "-997\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.cgroupRoot = dir
+	h.oomScoreAdjPath = filepath.Join(dir, "oom_score_adj")
+
+	rec := httptest.NewRecorder()
+	h.Info(rec, httptest.NewRequest("GET", "/info", nil))
+	var resp api.InfoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	res := resp.Resources
+	if res.OOMScoreAdj == nil || *res.OOMScoreAdj != -997 {
+		t.Errorf("resources.OOMScoreAdj = %v, want -997", res.OOMScoreAdj)
+	}
+	if res.MemoryHigh != 512<<20 {
+		t.Errorf("resources.MemoryHigh = %d, want 512Mi", res.MemoryHigh)
+	}
+	want := api.MemoryEvents{High: 7, OOM: 1, OOMKill: 1}
+	if res.MemoryEvents == nil || *res.MemoryEvents != want {
+		t.Errorf("resources.MemoryEvents = %+v, want %+v", res.MemoryEvents, want)
+	}
+}
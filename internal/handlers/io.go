@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"math/rand/v2"
 	"net/http"
@@ -14,6 +16,8 @@ import (
 
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 const (
@@ -43,38 +47,17 @@ func NewIOHandlers(tracker *load.Tracker, cfg *config.Config) *IOHandlers {
 // Register adds I/O load routes to the mux.
 func (h *IOHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /io", h.IO)
-}
-
-// IOResponse is the JSON response for /io.
-type IOResponse struct {
-	// RequestedSize is the size parameter value in bytes
-	RequestedSize int64 `json:"requested_size"`
-	// RequestedSizeHuman is the human-readable size
-	RequestedSizeHuman string `json:"requested_size_human"`
-	// Operation is the I/O operation type
-	Operation string `json:"operation"`
-	// Sync indicates if fsync was used
-	Sync bool `json:"sync"`
-	// ActualDuration is how long the operation took
-	ActualDuration string `json:"actual_duration"`
-	// BytesWritten is the number of bytes written
-	BytesWritten int64 `json:"bytes_written,omitempty"`
-	// BytesRead is the number of bytes read
-	BytesRead int64 `json:"bytes_read,omitempty"`
-	// Cancelled indicates if the operation was cancelled
-	Cancelled bool `json:"cancelled,omitempty"`
-	// LimitApplied indicates if the size was capped by the safety limit
-	LimitApplied bool `json:"limit_applied,omitempty"`
+	mux.HandleFunc("POST /upload", h.Upload)
 }
 
 func (h *IOHandlers) IO(w http.ResponseWriter, r *http.Request) {
 	size, err := parseSize(r, "size", 10<<20)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 	if size < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "size must be non-negative")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "size must be non-negative")
 		return
 	}
 
@@ -83,7 +66,7 @@ func (h *IOHandlers) IO(w http.ResponseWriter, r *http.Request) {
 		operation = ioOpWrite
 	}
 	if operation != ioOpWrite && operation != ioOpRead && operation != ioOpMixed {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "operation must be write, read, or mixed")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "operation must be write, read, or mixed")
 		return
 	}
 
@@ -92,29 +75,64 @@ func (h *IOHandlers) IO(w http.ResponseWriter, r *http.Request) {
 	if syncParam != "" {
 		doSync, err = strconv.ParseBool(syncParam)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "sync must be true or false")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "sync must be true or false")
 			return
 		}
 	}
 
+	duration, err := parseDuration(r, "duration", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if duration < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "duration must be non-negative")
+		return
+	}
+
+	rate, err := parseSize(r, "rate", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if rate < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate must be non-negative")
+		return
+	}
+	if rate > 0 && duration == 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate requires duration")
+		return
+	}
+	if duration > 0 && size == 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "size must be positive with duration")
+		return
+	}
+
 	limitApplied := false
 	if h.maxSize > 0 && size > h.maxSize {
 		size = h.maxSize
 		limitApplied = true
 	}
 
-	release, err := h.tracker.Acquire(load.OpTypeIO)
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeIO)
 	if err != nil {
-		writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "concurrent operation limit exceeded")
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
 		return
 	}
 	defer release()
 
 	start := time.Now()
-	bytesWritten, bytesRead, cancelled := h.performIO(r.Context(), size, operation, doSync)
+	var bytesWritten, bytesRead int64
+	var cancelled bool
+	passes := 0
+	if duration > 0 {
+		bytesWritten, bytesRead, passes, cancelled = sustainIO(r.Context(), h.ioPath, size, operation, doSync, duration, rate)
+	} else {
+		bytesWritten, bytesRead, cancelled = performIO(r.Context(), h.ioPath, size, operation, doSync)
+	}
 	elapsed := time.Since(start)
 
-	resp := IOResponse{
+	resp := api.IOResponse{
 		RequestedSize:      size,
 		RequestedSizeHuman: formatSize(size),
 		Operation:          operation,
@@ -125,6 +143,14 @@ func (h *IOHandlers) IO(w http.ResponseWriter, r *http.Request) {
 		Cancelled:          cancelled,
 		LimitApplied:       limitApplied,
 	}
+	if duration > 0 {
+		resp.Duration = duration.String()
+		resp.Rate = rate
+		resp.Passes = passes
+		if elapsed > 0 {
+			resp.ActualRate = int64(float64(bytesWritten+bytesRead) / elapsed.Seconds())
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -132,13 +158,169 @@ func (h *IOHandlers) IO(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *IOHandlers) performIO(ctx context.Context, size int64, operation string, doSync bool) (bytesWritten, bytesRead int64, cancelled bool) {
-	if err := os.MkdirAll(h.ioPath, 0750); err != nil {
-		slog.Error("failed to create I/O directory", "path", h.ioPath, "error", err)
+// sustainIO repeats an I/O operation of size bytes until duration elapses,
+// limiting throughput to rate bytes per second when positive. The operation
+// is only reported as cancelled if ctx ends before the duration.
+func sustainIO(ctx context.Context, dir string, size int64, operation string, doSync bool, duration time.Duration, rate int64) (bytesWritten, bytesRead int64, passes int, cancelled bool) {
+	runCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	pace := newIOPacer(rate)
+	for runCtx.Err() == nil {
+		written, read, passCancelled := performPacedIO(runCtx, dir, size, operation, doSync, pace)
+		bytesWritten += written
+		bytesRead += read
+		if !passCancelled {
+			passes++
+		}
+		if written == 0 && read == 0 && !passCancelled {
+			// The pass failed outright; errors were already logged.
+			break
+		}
+	}
+
+	return bytesWritten, bytesRead, passes, ctx.Err() != nil
+}
+
+// Upload handles POST /upload?rate=R&delay=D, reading and discarding the
+// request body. With rate, the body is read at about rate bytes per second
+// in small reads, so the server is the slow side of the connection, to
+// measure client write timeouts and load balancer handling of slow
+// servers. The body stalls only once the kernel's socket buffers fill, so
+// rates are best tested with bodies well beyond them. With delay, nothing
+// is read until it elapses.
+func (h *IOHandlers) Upload(w http.ResponseWriter, r *http.Request) {
+	rate, err := parseSize(r, "rate", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if rate < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "rate must be non-negative")
+		return
+	}
+
+	delay, err := parseDuration(r, "delay", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if delay < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "delay must be non-negative")
+		return
+	}
+
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeIO)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
+		return
+	}
+	defer release()
+
+	ctx := r.Context()
+	start := time.Now()
+	resp := api.UploadResponse{}
+	if rate > 0 {
+		resp.Rate = formatSize(rate) + "/s"
+	}
+	if delay > 0 {
+		resp.Delay = delay.String()
+	}
+
+	var body io.Reader = r.Body
+	if h.maxSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, h.maxSize)
+	}
+	// Small reads keep a slow upload steady rather than bursty: about ten a
+	// second at the requested rate.
+	buf := make([]byte, ioBlockSize)
+	if rate > 0 {
+		buf = buf[:min(max(rate/10, 1), ioBlockSize)]
+	}
+
+	var readErr error
+	resp.Cancelled = sleep(ctx, delay)
+	pace := newIOPacer(rate)
+	for !resp.Cancelled {
+		n, err := body.Read(buf)
+		resp.BytesRead += int64(n)
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+		resp.Cancelled = !pace.wait(ctx, int64(n))
+	}
+	resp.ActualDuration = time.Since(start).String()
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(readErr, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, errcode.InvalidParameter, fmt.Sprintf("body must not exceed %s", formatSize(tooLarge.Limit)))
+		return
+	case readErr != nil:
+		slog.Debug("upload ended early", "bytes_read", resp.BytesRead, "error", readErr)
+		resp.Cancelled = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode upload response", "error", err)
+	}
+}
+
+// ioPacer spaces out I/O so it averages rate bytes per second. A nil pacer
+// does not limit throughput.
+type ioPacer struct {
+	rate  int64
+	start time.Time
+	done  int64
+}
+
+func newIOPacer(rate int64) *ioPacer {
+	if rate <= 0 {
+		return nil
+	}
+	return &ioPacer{rate: rate, start: time.Now()}
+}
+
+// wait records n bytes of I/O and sleeps until the running total is back on
+// schedule. It reports false if ctx ends first.
+func (p *ioPacer) wait(ctx context.Context, n int64) bool {
+	if p == nil {
+		return true
+	}
+	p.done += n
+
+	due := p.start.Add(time.Duration(float64(p.done) / float64(p.rate) * float64(time.Second)))
+	delay := time.Until(due)
+	if delay <= 0 {
+		return true
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// performIO runs an I/O operation against a temporary file in dir.
+func performIO(ctx context.Context, dir string, size int64, operation string, doSync bool) (bytesWritten, bytesRead int64, cancelled bool) {
+	return performPacedIO(ctx, dir, size, operation, doSync, nil)
+}
+
+// performPacedIO is performIO with throughput limited by pace.
+func performPacedIO(ctx context.Context, dir string, size int64, operation string, doSync bool, pace *ioPacer) (bytesWritten, bytesRead int64, cancelled bool) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		slog.Error("failed to create I/O directory", "path", dir, "error", err)
 		return 0, 0, false
 	}
 
-	filename := filepath.Join(h.ioPath, fmt.Sprintf("hotpod-%d-%d.tmp", time.Now().UnixNano(), rand.Uint64()))
+	filename := filepath.Join(dir, fmt.Sprintf("hotpod-%d-%d.tmp", time.Now().UnixNano(), rand.Uint64()))
 	defer func() {
 		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
 			slog.Warn("failed to remove temp file", "file", filename, "error", err)
@@ -147,20 +329,21 @@ func (h *IOHandlers) performIO(ctx context.Context, size int64, operation string
 
 	switch operation {
 	case ioOpWrite:
-		bytesWritten, cancelled = h.writeFile(ctx, filename, size, doSync)
+		bytesWritten, cancelled = writeFile(ctx, filename, size, doSync, pace)
 	case ioOpRead:
-		bytesWritten, cancelled = h.writeFile(ctx, filename, size, false)
+		// Only the reads are paced; the file is set up as fast as possible.
+		bytesWritten, cancelled = writeFile(ctx, filename, size, false, nil)
 		if !cancelled {
-			bytesRead, cancelled = h.readFile(ctx, filename, size)
+			bytesRead, cancelled = readFile(ctx, filename, size, pace)
 		}
 	case ioOpMixed:
-		bytesWritten, bytesRead, cancelled = h.mixedIO(ctx, filename, size, doSync)
+		bytesWritten, bytesRead, cancelled = mixedIO(ctx, filename, size, doSync, pace)
 	}
 
 	return bytesWritten, bytesRead, cancelled
 }
 
-func (h *IOHandlers) writeFile(ctx context.Context, filename string, size int64, doSync bool) (bytesWritten int64, cancelled bool) {
+func writeFile(ctx context.Context, filename string, size int64, doSync bool, pace *ioPacer) (bytesWritten int64, cancelled bool) {
 	f, err := os.Create(filename)
 	if err != nil {
 		slog.Error("failed to create file", "file", filename, "error", err)
@@ -188,6 +371,9 @@ func (h *IOHandlers) writeFile(ctx context.Context, filename string, size int64,
 		}
 		bytesWritten += int64(n)
 		remaining -= int64(n)
+		if !pace.wait(ctx, int64(n)) {
+			return bytesWritten, true
+		}
 	}
 
 	if doSync {
@@ -199,7 +385,7 @@ func (h *IOHandlers) writeFile(ctx context.Context, filename string, size int64,
 	return bytesWritten, false
 }
 
-func (h *IOHandlers) readFile(ctx context.Context, filename string, size int64) (bytesRead int64, cancelled bool) {
+func readFile(ctx context.Context, filename string, size int64, pace *ioPacer) (bytesRead int64, cancelled bool) {
 	f, err := os.Open(filename)
 	if err != nil {
 		slog.Error("failed to open file for reading", "file", filename, "error", err)
@@ -229,12 +415,15 @@ func (h *IOHandlers) readFile(ctx context.Context, filename string, size int64)
 
 		bytesRead += int64(n)
 		remaining -= int64(n)
+		if !pace.wait(ctx, int64(n)) {
+			return bytesRead, true
+		}
 	}
 
 	return bytesRead, false
 }
 
-func (h *IOHandlers) mixedIO(ctx context.Context, filename string, size int64, doSync bool) (bytesWritten, bytesRead int64, cancelled bool) {
+func mixedIO(ctx context.Context, filename string, size int64, doSync bool, pace *ioPacer) (bytesWritten, bytesRead int64, cancelled bool) {
 	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		slog.Error("failed to create file for mixed I/O", "file", filename, "error", err)
@@ -266,6 +455,9 @@ func (h *IOHandlers) mixedIO(ctx context.Context, filename string, size int64, d
 			}
 			bytesWritten += int64(n)
 			remaining -= int64(n)
+			if !pace.wait(ctx, int64(n)) {
+				return bytesWritten, bytesRead, true
+			}
 
 			if doSync {
 				if err := f.Sync(); err != nil {
@@ -285,6 +477,9 @@ func (h *IOHandlers) mixedIO(ctx context.Context, filename string, size int64, d
 				return bytesWritten, bytesRead, false
 			}
 			bytesRead += int64(n)
+			if !pace.wait(ctx, int64(n)) {
+				return bytesWritten, bytesRead, true
+			}
 
 			// Seek forward to continue writing
 			if _, err := f.Seek(0, 2); err != nil {
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 func TestIODefault(t *testing.T) {
@@ -24,7 +26,7 @@ func TestIODefault(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp IOResponse
+	var resp api.IOResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -63,7 +65,7 @@ func TestIOOperations(t *testing.T) {
 			t.Errorf("operation=%s: status = %d, want %d", tt.operation, rec.Code, http.StatusOK)
 		}
 
-		var resp IOResponse
+		var resp api.IOResponse
 		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 			t.Fatalf("operation=%s: failed to parse response: %v", tt.operation, err)
 		}
@@ -94,7 +96,7 @@ func TestIOWithSync(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp IOResponse
+	var resp api.IOResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -202,7 +204,7 @@ func TestIOCancellation(t *testing.T) {
 		t.Error("handler did not return after cancellation")
 	}
 
-	var resp IOResponse
+	var resp api.IOResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -226,7 +228,7 @@ func TestIOMaxSizeLimit(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp IOResponse
+	var resp api.IOResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -253,3 +255,128 @@ func TestIORegister(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 }
+
+func TestIODuration(t *testing.T) {
+	h := NewIOHandlers(load.NewTracker(100), testConfig())
+
+	for _, op := range []string{"write", "read", "mixed"} {
+		req := httptest.NewRequest("GET", "/io?size=64Ki&duration=300ms&rate=1Mi&operation="+op, nil)
+		rec := httptest.NewRecorder()
+
+		start := time.Now()
+		h.IO(rec, req)
+		elapsed := time.Since(start)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", op, rec.Code, http.StatusOK)
+		}
+		if elapsed < 300*time.Millisecond {
+			t.Errorf("%s: elapsed = %s, want at least 300ms", op, elapsed)
+		}
+
+		var resp api.IOResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: failed to parse response: %v", op, err)
+		}
+		if resp.Cancelled || resp.Duration != "300ms" || resp.Rate != 1<<20 {
+			t.Errorf("%s: response = %+v", op, resp)
+		}
+		// 300ms at 1Mi/s is about 300Ki, plus the unpaced setup writes of
+		// read passes.
+		paced := resp.BytesWritten + resp.BytesRead
+		if op == "read" {
+			paced = resp.BytesRead
+		}
+		if paced < 128<<10 || paced > 512<<10 {
+			t.Errorf("%s: paced bytes = %d, want about 300Ki", op, paced)
+		}
+		if resp.Passes < 2 {
+			t.Errorf("%s: passes = %d, want at least 2", op, resp.Passes)
+		}
+	}
+}
+
+func TestIODurationInvalid(t *testing.T) {
+	h := NewIOHandlers(load.NewTracker(100), testConfig())
+
+	for _, query := range []string{
+		"duration=-1s",
+		"duration=soon",
+		"rate=1Mi",
+		"duration=1s&rate=-1",
+		"duration=1s&size=0",
+	} {
+		rec := httptest.NewRecorder()
+		h.IO(rec, httptest.NewRequest("GET", "/io?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestUpload(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewIOHandlers(tracker, testConfig())
+
+	req := httptest.NewRequest("POST", "/upload", bytes.NewReader(make([]byte, 100<<10)))
+	rec := httptest.NewRecorder()
+	h.Upload(rec, req)
+
+	var resp api.UploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if rec.Code != http.StatusOK || resp.BytesRead != 100<<10 || resp.Cancelled {
+		t.Errorf("status = %d, response = %+v, want all 102400 bytes read", rec.Code, resp)
+	}
+}
+
+func TestUploadSlow(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewIOHandlers(tracker, testConfig())
+
+	// 1000 bytes at 10000 bytes/s takes about 100ms, after a 20ms delay.
+	req := httptest.NewRequest("POST", "/upload?rate=10000&delay=20ms", bytes.NewReader(make([]byte, 1000)))
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	h.Upload(rec, req)
+	elapsed := time.Since(start)
+
+	var resp api.UploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.BytesRead != 1000 || resp.Rate == "" || resp.Delay != "20ms" {
+		t.Errorf("response = %+v, want 1000 bytes read slowly", resp)
+	}
+	if elapsed < 100*time.Millisecond {
+		t.Errorf("elapsed = %v, want >= 100ms", elapsed)
+	}
+}
+
+func TestUploadTooLarge(t *testing.T) {
+	tracker := load.NewTracker(100)
+	cfg := testConfig()
+	cfg.MaxIOSize = 1024
+	h := NewIOHandlers(tracker, cfg)
+
+	rec := httptest.NewRecorder()
+	h.Upload(rec, httptest.NewRequest("POST", "/upload", bytes.NewReader(make([]byte, 2048))))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestUploadInvalid(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewIOHandlers(tracker, testConfig())
+
+	for _, q := range []string{"rate=x", "rate=-1", "delay=x", "delay=-1s"} {
+		rec := httptest.NewRecorder()
+		h.Upload(rec, httptest.NewRequest("POST", "/upload?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
@@ -3,14 +3,20 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"math/rand/v2"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/ripta/hotpod/internal/fault"
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/internal/requestid"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 // LatencyHandlers provides the /latency endpoint handler.
@@ -26,53 +32,123 @@ func NewLatencyHandlers(tracker *load.Tracker) *LatencyHandlers {
 // Register adds latency routes to the mux.
 func (h *LatencyHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /latency", h.Latency)
+	mux.HandleFunc("GET /stream-latency", h.StreamLatency)
 }
 
-// LatencyResponse is the JSON response for /latency.
-type LatencyResponse struct {
-	// RequestedDuration is the duration parameter value
-	RequestedDuration string `json:"requested_duration"`
-	// ActualDuration is how long the operation actually took
-	ActualDuration string `json:"actual_duration"`
-	// Jitter is the jitter parameter value
-	Jitter string `json:"jitter,omitempty"`
-	// Status is the HTTP status code returned
-	Status int `json:"status"`
-	// Cancelled indicates if the operation was cancelled
-	Cancelled bool `json:"cancelled,omitempty"`
+// Adaptive latency curves. Each maps utilization u = concurrency/capacity to a
+// multiplier applied to the base duration.
+const (
+	curveLinear      = "linear"
+	curveQuadratic   = "quadratic"
+	curveExponential = "exponential"
+	curveMM1         = "mm1"
+)
+
+// maxMM1Utilization caps utilization for the M/M/1 curve, whose response time
+// is unbounded as utilization approaches 1.
+const maxMM1Utilization = 0.99
+
+// adaptiveMultiplier returns the latency multiplier for the given curve at
+// utilization u.
+func adaptiveMultiplier(curve string, u float64) (float64, error) {
+	switch curve {
+	case curveLinear:
+		return 1 + u, nil
+	case curveQuadratic:
+		return 1 + u*u, nil
+	case curveExponential:
+		return math.Exp(u), nil
+	case curveMM1:
+		// Mean time in an M/M/1 system relative to service time: 1/(1-ρ).
+		return 1 / (1 - min(u, maxMM1Utilization)), nil
+	default:
+		return 0, fmt.Errorf("curve must be %s, %s, %s, or %s", curveLinear, curveQuadratic, curveExponential, curveMM1)
+	}
 }
 
+// Latency handles GET /latency?duration=D&jitter=J&status=S, sleeping for
+// duration before responding. With distribution=normal|lognormal|pareto|
+// bimodal, the sleep is sampled around duration instead (see
+// parseDistribution), capped at max; with mode=adaptive, it is scaled by the
+// curve at the current concurrency.
 func (h *LatencyHandlers) Latency(w http.ResponseWriter, r *http.Request) {
 	duration, err := parseDuration(r, "duration", 100*time.Millisecond)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 
 	jitter, err := parseDuration(r, "jitter", 0)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 
 	status, err := parseInt(r, "status", http.StatusOK)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 	if status < 100 || status > 599 {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "status must be between 100 and 599")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "status must be between 100 and 599")
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != "fixed" && mode != "adaptive" {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "mode must be fixed or adaptive")
+		return
+	}
+
+	curve := r.URL.Query().Get("curve")
+	if curve == "" {
+		curve = curveLinear
+	}
+	if _, err := adaptiveMultiplier(curve, 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 
-	release, err := h.tracker.Acquire(load.OpTypeLatency)
+	capacity, err := parseInt(r, "capacity", 10)
 	if err != nil {
-		writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "concurrent operation limit exceeded")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if capacity < 1 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "capacity must be at least 1")
+		return
+	}
+
+	maxDuration, err := parseDuration(r, "max", 30*time.Second)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	dist, err := parseDistribution(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeLatency)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
 		return
 	}
 	defer release()
 
 	actualDuration := duration
+	if dist.Kind != "" {
+		actualDuration = min(dist.Sample(duration), maxDuration)
+	}
+	var concurrency int64
+	var multiplier float64
+	if mode == "adaptive" {
+		concurrency = h.tracker.Count(load.OpTypeLatency)
+		multiplier, _ = adaptiveMultiplier(curve, float64(concurrency)/float64(capacity))
+		actualDuration = min(time.Duration(float64(actualDuration)*multiplier), maxDuration)
+	}
 	if jitter > 0 {
 		actualDuration += time.Duration(rand.Int64N(int64(jitter)))
 	}
@@ -81,7 +157,7 @@ func (h *LatencyHandlers) Latency(w http.ResponseWriter, r *http.Request) {
 	cancelled := sleep(r.Context(), actualDuration)
 	elapsed := time.Since(start)
 
-	resp := LatencyResponse{
+	resp := api.LatencyResponse{
 		RequestedDuration: duration.String(),
 		ActualDuration:    elapsed.String(),
 		Status:            status,
@@ -90,6 +166,12 @@ func (h *LatencyHandlers) Latency(w http.ResponseWriter, r *http.Request) {
 	if jitter > 0 {
 		resp.Jitter = jitter.String()
 	}
+	resp.Distribution = dist.API()
+	if mode == "adaptive" {
+		resp.Curve = curve
+		resp.Concurrency = concurrency
+		resp.Multiplier = multiplier
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -98,6 +180,137 @@ func (h *LatencyHandlers) Latency(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// maxStreamPhase bounds each phase of a streamed response.
+const maxStreamPhase = 10 * time.Minute
+
+// StreamLatency handles GET
+// /stream-latency?ttfb=D&body_duration=D&chunks=N&status=S, waiting ttfb
+// before sending the response header, then streaming chunks newline-delimited
+// JSON lines evenly over body_duration, so proxies whose timeouts distinguish
+// time-to-first-byte from total duration can be tested separately. The last
+// line summarizes the response.
+func (h *LatencyHandlers) StreamLatency(w http.ResponseWriter, r *http.Request) {
+	ttfb, err := parseDuration(r, "ttfb", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	bodyDuration, err := parseDuration(r, "body_duration", time.Second)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if ttfb < 0 || ttfb > maxStreamPhase || bodyDuration < 0 || bodyDuration > maxStreamPhase {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("ttfb and body_duration must be between 0 and %s", maxStreamPhase))
+		return
+	}
+
+	chunks, err := parseInt(r, "chunks", 10)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if chunks < 1 || chunks > 10000 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "chunks must be between 1 and 10000")
+		return
+	}
+
+	status, err := parseInt(r, "status", http.StatusOK)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if status < 200 || status > 599 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "status must be between 200 and 599")
+		return
+	}
+
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeLatency)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
+		return
+	}
+	defer release()
+
+	ctx := r.Context()
+	start := time.Now()
+	if sleep(ctx, ttfb) {
+		// Nothing has been sent yet, so a server-side timeout can still be
+		// reported; a client that went away will not see either.
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			writeError(w, http.StatusServiceUnavailable, errcode.OperationTimeout, "request timeout exceeded")
+		}
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(status)
+	if err := rc.Flush(); err != nil {
+		slog.Debug("stream latency response cannot be flushed", "error", err)
+	}
+	actualTTFB := time.Since(start)
+
+	enc := json.NewEncoder(w)
+	resp := api.StreamLatencyResponse{
+		TTFB:         ttfb.String(),
+		BodyDuration: bodyDuration.String(),
+		Chunks:       chunks,
+		Status:       status,
+		ActualTTFB:   actualTTFB.String(),
+	}
+	bodyStart := time.Now()
+	for i := 1; i <= chunks; i++ {
+		due := bodyStart.Add(bodyDuration * time.Duration(i) / time.Duration(chunks))
+		if sleep(ctx, time.Until(due)) {
+			resp.Cancelled = true
+			break
+		}
+		chunk := api.StreamChunk{Chunk: i, Elapsed: time.Since(start).String()}
+		if err := enc.Encode(chunk); err != nil {
+			slog.Debug("stream latency response ended early", "error", err)
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			slog.Debug("stream latency response cannot be flushed", "error", err)
+		}
+		resp.Sent = i
+	}
+
+	resp.ActualBodyDuration = time.Since(bodyStart).String()
+	resp.ActualDuration = time.Since(start).String()
+	if err := enc.Encode(resp); err != nil {
+		slog.Debug("failed to encode stream latency summary", "error", err)
+	}
+}
+
+// parseDistribution parses a latency distribution from the distribution
+// query parameter and its shape parameters: stddev for normal, sigma for
+// lognormal, alpha for pareto, and slow and slow_fraction for bimodal.
+func parseDistribution(r *http.Request) (fault.Distribution, error) {
+	d := fault.Distribution{Kind: r.URL.Query().Get("distribution")}
+	if d.Kind == "" {
+		return d, nil
+	}
+	var err error
+	if d.StdDev, err = parseDuration(r, "stddev", 0); err != nil {
+		return d, err
+	}
+	if d.Sigma, err = parseFloat(r, "sigma", 0); err != nil {
+		return d, err
+	}
+	if d.Alpha, err = parseFloat(r, "alpha", 0); err != nil {
+		return d, err
+	}
+	if d.Slow, err = parseDuration(r, "slow", 0); err != nil {
+		return d, err
+	}
+	if d.SlowFraction, err = parseFloat(r, "slow_fraction", 0); err != nil {
+		return d, err
+	}
+	return d, d.Validate()
+}
+
 func sleep(ctx context.Context, d time.Duration) (cancelled bool) {
 	timer := time.NewTimer(d)
 	defer timer.Stop()
@@ -130,10 +343,23 @@ func parseInt(r *http.Request, key string, defaultVal int) (int, error) {
 	return i, nil
 }
 
-func writeError(w http.ResponseWriter, status int, code, message string) {
+func parseFloat(r *http.Request, key string, defaultVal float64) (float64, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return defaultVal, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func writeError(w http.ResponseWriter, status int, code errcode.Code, message string) {
+	// The RequestID middleware has already set the response header.
+	resp := errcode.Response(code, message, w.Header().Get(requestid.Header))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	resp := map[string]string{"error": message, "code": code}
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Warn("failed to encode error response", "error", err)
 	}
@@ -3,12 +3,17 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/internal/requestid"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 func TestLatencyDefault(t *testing.T) {
@@ -30,7 +35,7 @@ func TestLatencyDefault(t *testing.T) {
 		t.Errorf("elapsed = %v, want >= 100ms (default duration)", elapsed)
 	}
 
-	var resp LatencyResponse
+	var resp api.LatencyResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -68,7 +73,7 @@ func TestLatencyCustomStatus(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
 	}
 
-	var resp LatencyResponse
+	var resp api.LatencyResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -114,12 +119,27 @@ func TestLatencyTooManyOps(t *testing.T) {
 
 	req := httptest.NewRequest("GET", "/latency?duration=1ms", nil)
 	rec := httptest.NewRecorder()
+	rec.Header().Set(requestid.Header, "req-123")
 
 	h.Latency(rec, req)
 
 	if rec.Code != http.StatusTooManyRequests {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
 	}
+
+	var resp api.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Code != string(errcode.TooManyRequests) || !resp.Retryable {
+		t.Errorf("code = %q, retryable = %v, want %q, true", resp.Code, resp.Retryable, errcode.TooManyRequests)
+	}
+	if resp.RequestID != "req-123" {
+		t.Errorf("request_id = %q, want %q", resp.RequestID, "req-123")
+	}
+	if resp.DocURL != errcode.TooManyRequests.DocURL() {
+		t.Errorf("doc_url = %q, want %q", resp.DocURL, errcode.TooManyRequests.DocURL())
+	}
 }
 
 func TestLatencyCancellation(t *testing.T) {
@@ -145,7 +165,7 @@ func TestLatencyCancellation(t *testing.T) {
 		t.Error("handler did not return after cancellation")
 	}
 
-	var resp LatencyResponse
+	var resp api.LatencyResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -163,7 +183,7 @@ func TestLatencyJitter(t *testing.T) {
 
 	h.Latency(rec, req)
 
-	var resp LatencyResponse
+	var resp api.LatencyResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -187,3 +207,192 @@ func TestLatencyRegister(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 }
+
+type adaptiveMultiplierTest struct {
+	curve string
+	u     float64
+	want  float64
+}
+
+var adaptiveMultiplierTests = []adaptiveMultiplierTest{
+	{curveLinear, 0, 1},
+	{curveLinear, 2, 3},
+	{curveQuadratic, 2, 5},
+	{curveExponential, 0, 1},
+	{curveMM1, 0.5, 2},
+	{curveMM1, 5, 100},
+}
+
+func TestAdaptiveMultiplier(t *testing.T) {
+	for _, tt := range adaptiveMultiplierTests {
+		got, err := adaptiveMultiplier(tt.curve, tt.u)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.curve, err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("adaptiveMultiplier(%s, %v) = %v, want %v", tt.curve, tt.u, got, tt.want)
+		}
+	}
+
+	if _, err := adaptiveMultiplier("cubic", 1); err == nil {
+		t.Error("expected error for unknown curve")
+	}
+}
+
+func TestLatencyAdaptive(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewLatencyHandlers(tracker)
+
+	// Hold three other latency slots so this request sees concurrency 4.
+	for range 3 {
+		release, err := tracker.Acquire(load.OpTypeLatency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer release()
+	}
+
+	req := httptest.NewRequest("GET", "/latency?mode=adaptive&duration=10ms&capacity=2&curve=linear", nil)
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	h.Latency(rec, req)
+	elapsed := time.Since(start)
+
+	var resp api.LatencyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Concurrency != 4 || resp.Multiplier != 3 {
+		t.Errorf("concurrency = %d, multiplier = %v; want 4, 3", resp.Concurrency, resp.Multiplier)
+	}
+	if elapsed < 30*time.Millisecond {
+		t.Errorf("elapsed = %v, want >= 30ms", elapsed)
+	}
+}
+
+func TestLatencyAdaptiveInvalid(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewLatencyHandlers(tracker)
+
+	for _, q := range []string{"mode=bogus", "mode=adaptive&curve=cubic", "mode=adaptive&capacity=0"} {
+		rec := httptest.NewRecorder()
+		h.Latency(rec, httptest.NewRequest("GET", "/latency?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestLatencyDistribution(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewLatencyHandlers(tracker)
+
+	// Every sample lands in the slow mode.
+	req := httptest.NewRequest("GET", "/latency?duration=1ms&distribution=bimodal&slow=30ms&slow_fraction=1", nil)
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	h.Latency(rec, req)
+	elapsed := time.Since(start)
+
+	var resp api.LatencyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Distribution == nil || resp.Distribution.Kind != "bimodal" || resp.Distribution.Slow != "30ms" {
+		t.Errorf("distribution = %+v, want bimodal with a 30ms slow mode", resp.Distribution)
+	}
+	if elapsed < 30*time.Millisecond {
+		t.Errorf("elapsed = %v, want >= 30ms", elapsed)
+	}
+}
+
+func TestLatencyDistributionInvalid(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewLatencyHandlers(tracker)
+
+	for _, q := range []string{"distribution=uniform", "distribution=normal", "distribution=pareto&alpha=x", "distribution=bimodal&slow=1s&slow_fraction=2"} {
+		rec := httptest.NewRecorder()
+		h.Latency(rec, httptest.NewRequest("GET", "/latency?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestStreamLatency(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewLatencyHandlers(tracker)
+
+	req := httptest.NewRequest("GET", "/stream-latency?ttfb=30ms&body_duration=40ms&chunks=4&status=202", nil)
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	h.StreamLatency(rec, req)
+	elapsed := time.Since(start)
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if !rec.Flushed {
+		t.Error("response was not flushed")
+	}
+	if elapsed < 70*time.Millisecond {
+		t.Errorf("elapsed = %v, want >= 70ms", elapsed)
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 4 chunks and a summary:\n%s", len(lines), rec.Body)
+	}
+	var chunk api.StreamChunk
+	if err := json.Unmarshal([]byte(lines[3]), &chunk); err != nil || chunk.Chunk != 4 {
+		t.Errorf("last chunk = %+v (%v), want chunk 4", chunk, err)
+	}
+	var resp api.StreamLatencyResponse
+	if err := json.Unmarshal([]byte(lines[4]), &resp); err != nil {
+		t.Fatalf("failed to parse summary: %v", err)
+	}
+	if resp.Sent != 4 || resp.Cancelled {
+		t.Errorf("summary = %+v, want 4 chunks sent", resp)
+	}
+	if ttfb, _ := time.ParseDuration(resp.ActualTTFB); ttfb < 30*time.Millisecond {
+		t.Errorf("actual ttfb = %v, want >= 30ms", ttfb)
+	}
+}
+
+func TestStreamLatencyTimeout(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewLatencyHandlers(tracker)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest("GET", "/stream-latency?ttfb=1s", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	h.StreamLatency(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	var resp api.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Code != string(errcode.OperationTimeout) {
+		t.Errorf("code = %q, want %q", resp.Code, errcode.OperationTimeout)
+	}
+}
+
+func TestStreamLatencyInvalid(t *testing.T) {
+	tracker := load.NewTracker(100)
+	h := NewLatencyHandlers(tracker)
+
+	for _, q := range []string{"ttfb=x", "ttfb=-1s", "body_duration=11m", "chunks=0", "status=100"} {
+		rec := httptest.NewRecorder()
+		h.StreamLatency(rec, httptest.NewRequest("GET", "/stream-latency?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/leader"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// defaultLeaderHold is how long a dropped leader stays out of the election
+// when no hold is given.
+const defaultLeaderHold = 30 * time.Second
+
+// LeaderHandlers provides leader election status and leadership faults.
+type LeaderHandlers struct {
+	elector      *leader.Elector
+	chaosEnabled bool
+	authn        *auth.Authenticator
+	guard        *fault.Guard
+}
+
+// NewLeaderHandlers creates handlers for leader election endpoints. A nil
+// elector means leader election is disabled; a nil guard applies no
+// guardrails to leadership faults.
+func NewLeaderHandlers(elector *leader.Elector, chaosEnabled bool, authn *auth.Authenticator, guard *fault.Guard) *LeaderHandlers {
+	return &LeaderHandlers{elector: elector, chaosEnabled: chaosEnabled, authn: authn, guard: guard}
+}
+
+// Register adds leader routes to the mux.
+func (h *LeaderHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /leader", h.Status)
+	mux.HandleFunc("POST /fault/leader/drop", h.Drop)
+	mux.HandleFunc("POST /fault/leader/stall", h.Stall)
+}
+
+// Status handles GET /leader.
+func (h *LeaderHandlers) Status(w http.ResponseWriter, r *http.Request) {
+	status := leader.Status{}
+	if h.elector != nil {
+		status = h.elector.Status()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// Drop handles POST /fault/leader/drop, releasing the lease and sitting out
+// the election for hold (default: 30s).
+func (h *LeaderHandlers) Drop(w http.ResponseWriter, r *http.Request) {
+	hold, ok := h.fault(w, r, "hold")
+	if !ok {
+		return
+	}
+
+	wasLeader := h.elector.Status().Leader
+	h.elector.Drop(hold)
+	slog.Warn("leadership drop requested", "hold", hold, "was_leader", wasLeader)
+	events.Record(slog.LevelWarn, events.TypeFault, "leadership drop requested", map[string]any{"hold": hold.String()})
+	writeLeaderFault(w, "drop", hold, wasLeader)
+}
+
+// Stall handles POST /fault/leader/stall, suspending lease renewal for
+// duration (default: 30s) without stepping down.
+func (h *LeaderHandlers) Stall(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.fault(w, r, "duration")
+	if !ok {
+		return
+	}
+
+	wasLeader := h.elector.Status().Leader
+	h.elector.Stall(d)
+	slog.Warn("leadership stall requested", "duration", d, "was_leader", wasLeader)
+	events.Record(slog.LevelWarn, events.TypeFault, "leadership stall requested", map[string]any{"duration": d.String()})
+	writeLeaderFault(w, "stall", d, wasLeader)
+}
+
+// fault applies the chaos gate, checks that leader election is enabled,
+// parses the named duration parameter, and applies the guardrails.
+func (h *LeaderHandlers) fault(w http.ResponseWriter, r *http.Request, param string) (time.Duration, bool) {
+	if !chaosAllowed(h.chaosEnabled, h.authn, w, r) {
+		return 0, false
+	}
+	if h.elector == nil {
+		writeError(w, http.StatusNotFound, errcode.LeaderElectionDisabled, "leader election is not enabled")
+		return 0, false
+	}
+
+	d, err := parseDuration(r, param, defaultLeaderHold)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return 0, false
+	}
+	if d <= 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, param+" must be positive")
+		return 0, false
+	}
+	if !admitChaos(h.guard, w, "leader") {
+		return 0, false
+	}
+	return d, true
+}
+
+func writeLeaderFault(w http.ResponseWriter, fault string, d time.Duration, wasLeader bool) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(api.LeaderFaultResponse{Fault: fault, Duration: d.String(), WasLeader: wasLeader})
+}
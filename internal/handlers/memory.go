@@ -3,14 +3,19 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/rand/v2"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/internal/rusage"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 const (
@@ -36,42 +41,27 @@ func NewMemoryHandlers(tracker *load.Tracker, cfg *config.Config) *MemoryHandler
 // Register adds memory load routes to the mux.
 func (h *MemoryHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /memory", h.Memory)
-}
-
-// MemoryResponse is the JSON response for /memory.
-type MemoryResponse struct {
-	// RequestedSize is the size parameter value in bytes
-	RequestedSize int64 `json:"requested_size"`
-	// RequestedSizeHuman is the human-readable size
-	RequestedSizeHuman string `json:"requested_size_human"`
-	// Duration is how long the memory was held
-	Duration string `json:"duration"`
-	// Pattern is the fill pattern used
-	Pattern string `json:"pattern"`
-	// Cancelled indicates if the operation was cancelled
-	Cancelled bool `json:"cancelled,omitempty"`
-	// LimitApplied indicates if the size was capped by the safety limit
-	LimitApplied bool `json:"limit_applied,omitempty"`
+	mux.HandleFunc("GET /memory/pressure", h.Pressure)
 }
 
 func (h *MemoryHandlers) Memory(w http.ResponseWriter, r *http.Request) {
 	size, err := parseSize(r, "size", 10<<20) // Default 10MB
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 	if size < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "size must be non-negative")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "size must be non-negative")
 		return
 	}
 
 	duration, err := parseDuration(r, "duration", 10*time.Second)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 	if duration < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "duration must be non-negative")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "duration must be non-negative")
 		return
 	}
 
@@ -80,7 +70,7 @@ func (h *MemoryHandlers) Memory(w http.ResponseWriter, r *http.Request) {
 		pattern = patternRandom
 	}
 	if pattern != patternZero && pattern != patternRandom && pattern != patternSequential {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "pattern must be zero, random, or sequential")
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "pattern must be zero, random, or sequential")
 		return
 	}
 
@@ -90,16 +80,16 @@ func (h *MemoryHandlers) Memory(w http.ResponseWriter, r *http.Request) {
 		limitApplied = true
 	}
 
-	release, err := h.tracker.Acquire(load.OpTypeMemory)
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeMemory)
 	if err != nil {
-		writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "concurrent operation limit exceeded")
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
 		return
 	}
 	defer release()
 
 	cancelled := holdMemory(r.Context(), size, duration, pattern)
 
-	resp := MemoryResponse{
+	resp := api.MemoryResponse{
 		RequestedSize:      size,
 		RequestedSizeHuman: formatSize(size),
 		Duration:           duration.String(),
@@ -114,6 +104,122 @@ func (h *MemoryHandlers) Memory(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Page access orders for /memory/pressure.
+const (
+	accessRandom     = "random"
+	accessSequential = "sequential"
+)
+
+// Pressure handles GET /memory/pressure?size=512Mi&duration=30s&access=random,
+// writing to every page of a working set until the duration elapses. Sized
+// above the container's memory limit on a node with swap, or under memory.high
+// throttling, this produces a steady stream of major faults.
+func (h *MemoryHandlers) Pressure(w http.ResponseWriter, r *http.Request) {
+	size, err := parseSize(r, "size", 256<<20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if size <= 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "size must be positive")
+		return
+	}
+
+	duration, err := parseDuration(r, "duration", 10*time.Second)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if duration <= 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "duration must be positive")
+		return
+	}
+
+	access := r.URL.Query().Get("access")
+	if access == "" {
+		access = accessRandom
+	}
+	if access != accessRandom && access != accessSequential {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "access must be random or sequential")
+		return
+	}
+
+	limitApplied := false
+	if h.maxSize > 0 && size > h.maxSize {
+		size = h.maxSize
+		limitApplied = true
+	}
+
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeMemory)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
+		return
+	}
+	defer release()
+
+	before, faultErr := rusage.PageFaults()
+	start := time.Now()
+	passes, pages, cancelled := touchPages(r.Context(), size, duration, access)
+	elapsed := time.Since(start)
+
+	resp := api.MemoryPressureResponse{
+		RequestedSize:      size,
+		RequestedSizeHuman: formatSize(size),
+		Duration:           duration.String(),
+		Access:             access,
+		Passes:             passes,
+		PagesTouched:       pages,
+		Cancelled:          cancelled,
+		LimitApplied:       limitApplied,
+	}
+	if after, err := rusage.PageFaults(); faultErr == nil && err == nil {
+		delta := after.Sub(before)
+		resp.MinorFaults = delta.Minor
+		resp.MajorFaults = delta.Major
+		resp.MinorFaultRate = float64(delta.Minor) / elapsed.Seconds()
+		resp.MajorFaultRate = float64(delta.Major) / elapsed.Seconds()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode memory pressure response", "error", err)
+	}
+}
+
+// touchPages allocates size bytes and writes one byte per page, in the given
+// order, until duration elapses. Writing dirties each page, so reclaiming it
+// requires swapping it out. Returns the completed passes, the pages touched,
+// and whether the operation was cancelled.
+func touchPages(ctx context.Context, size int64, duration time.Duration, access string) (passes, touched int64, cancelled bool) {
+	data := make([]byte, size)
+	pageSize := os.Getpagesize()
+	numPages := (len(data) + pageSize - 1) / pageSize
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	order := make([]int32, numPages)
+	for i := range order {
+		order[i] = int32(i)
+	}
+
+	for {
+		if access == accessRandom {
+			rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		}
+		for i, page := range order {
+			// Checking the context every page would dominate the cost of
+			// touches that hit memory.
+			if i%256 == 0 && ctx.Err() != nil {
+				return passes, touched, errors.Is(ctx.Err(), context.Canceled)
+			}
+			data[int(page)*pageSize]++
+			touched++
+		}
+		passes++
+	}
+}
+
 // holdMemory allocates and fills memory, holding it for the specified duration.
 // Returns true if the operation was cancelled before completion.
 func holdMemory(ctx context.Context, size int64, duration time.Duration, pattern string) bool {
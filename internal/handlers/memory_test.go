@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 func TestMemoryDefault(t *testing.T) {
@@ -31,7 +33,7 @@ func TestMemoryDefault(t *testing.T) {
 		t.Errorf("elapsed = %v, want >= 100ms", elapsed)
 	}
 
-	var resp MemoryResponse
+	var resp api.MemoryResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -56,7 +58,7 @@ func TestMemoryCustomParams(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp MemoryResponse
+	var resp api.MemoryResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -83,7 +85,7 @@ func TestMemoryPatterns(t *testing.T) {
 			t.Errorf("pattern=%s: status = %d, want %d", pattern, rec.Code, http.StatusOK)
 		}
 
-		var resp MemoryResponse
+		var resp api.MemoryResponse
 		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 			t.Fatalf("pattern=%s: failed to parse response: %v", pattern, err)
 		}
@@ -175,7 +177,7 @@ func TestMemoryCancellation(t *testing.T) {
 		t.Error("handler did not return after cancellation")
 	}
 
-	var resp MemoryResponse
+	var resp api.MemoryResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -201,7 +203,7 @@ func TestMemoryMaxSizeLimit(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp MemoryResponse
+	var resp api.MemoryResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -284,3 +286,55 @@ func TestFillMemory(t *testing.T) {
 		t.Error("random pattern: all bytes are zero, expected random data")
 	}
 }
+
+func TestMemoryPressure(t *testing.T) {
+	h := NewMemoryHandlers(load.NewTracker(100), testConfig())
+
+	for _, access := range []string{"random", "sequential"} {
+		req := httptest.NewRequest("GET", "/memory/pressure?size=8Mi&duration=100ms&access="+access, nil)
+		rec := httptest.NewRecorder()
+		h.Pressure(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", access, rec.Code, http.StatusOK)
+		}
+		var resp api.MemoryPressureResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: failed to parse response: %v", access, err)
+		}
+		if resp.Access != access || resp.Passes == 0 || resp.Cancelled {
+			t.Errorf("%s: response = %+v", access, resp)
+		}
+		if resp.PagesTouched < resp.Passes*int64(8<<20/os.Getpagesize()) {
+			t.Errorf("%s: pages touched = %d over %d passes, want every page per pass", access, resp.PagesTouched, resp.Passes)
+		}
+	}
+}
+
+func TestMemoryPressureInvalid(t *testing.T) {
+	h := NewMemoryHandlers(load.NewTracker(100), testConfig())
+
+	for _, query := range []string{"size=0", "size=big", "duration=0s", "duration=-1s", "access=backwards"} {
+		rec := httptest.NewRecorder()
+		h.Pressure(rec, httptest.NewRequest("GET", "/memory/pressure?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestMemoryPressureLimit(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxMemorySize = 1 << 20
+	h := NewMemoryHandlers(load.NewTracker(100), cfg)
+
+	rec := httptest.NewRecorder()
+	h.Pressure(rec, httptest.NewRequest("GET", "/memory/pressure?size=1Gi&duration=10ms", nil))
+	var resp api.MemoryPressureResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if !resp.LimitApplied || resp.RequestedSize != 1<<20 {
+		t.Errorf("response = %+v, want size capped at 1Mi", resp)
+	}
+}
@@ -3,18 +3,42 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/ripta/hotpod/internal/metrics"
 )
 
 // MetricsHandlers provides the /metrics endpoint handler.
-type MetricsHandlers struct{}
+type MetricsHandlers struct {
+	cost *metrics.ScrapeCost
+}
 
 // NewMetricsHandlers creates handlers for the Prometheus metrics endpoint.
-func NewMetricsHandlers() *MetricsHandlers {
-	return &MetricsHandlers{}
+// cost may be nil to serve metrics without delay or padding.
+func NewMetricsHandlers(cost *metrics.ScrapeCost) *MetricsHandlers {
+	return &MetricsHandlers{cost: cost}
 }
 
 // Register adds metrics routes to the mux.
 func (h *MetricsHandlers) Register(mux *http.ServeMux) {
-	mux.Handle("GET /metrics", promhttp.Handler())
+	if h.cost == nil {
+		mux.Handle("GET /metrics", promhttp.Handler())
+		return
+	}
+
+	// Padding series are served from their own registry, so they reach
+	// Prometheus scrapes but not pushed metrics.
+	padding := prometheus.NewRegistry()
+	padding.MustRegister(h.cost)
+	handler := promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, padding}, promhttp.HandlerOpts{}))
+
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		h.cost.Wait(r.Context())
+		if r.Context().Err() != nil {
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
 }
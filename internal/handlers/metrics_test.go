@@ -8,7 +8,7 @@ import (
 )
 
 func TestMetricsEndpoint(t *testing.T) {
-	h := NewMetricsHandlers()
+	h := NewMetricsHandlers(nil)
 
 	mux := http.NewServeMux()
 	h.Register(mux)
@@ -45,7 +45,7 @@ func TestMetricsEndpoint(t *testing.T) {
 }
 
 func TestMetricsContentType(t *testing.T) {
-	h := NewMetricsHandlers()
+	h := NewMetricsHandlers(nil)
 
 	mux := http.NewServeMux()
 	h.Register(mux)
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// MirrorHeader marks a request as a mirrored copy, for proxies that do not
+// rewrite the host of the copies they send.
+const MirrorHeader = "X-Hotpod-Mirror"
+
+// shadowSuffix is appended to the host of mirrored requests by Envoy, and so
+// by Istio.
+const shadowSuffix = "-shadow"
+
+// MirrorHandlers counts requests to a source and a sink endpoint by whether
+// they are mirrored copies, so a proxy's mirroring percentage can be checked
+// by comparing the two: route live traffic to /mirror/source and mirror it
+// to /mirror/sink.
+type MirrorHandlers struct {
+	source, sink mirrorCounts
+}
+
+type mirrorCounts struct {
+	total, mirrored atomic.Int64
+}
+
+func (c *mirrorCounts) api() api.MirrorCounts {
+	return api.MirrorCounts{Total: c.total.Load(), Mirrored: c.mirrored.Load()}
+}
+
+// NewMirrorHandlers creates handlers for the mirror endpoints.
+func NewMirrorHandlers() *MirrorHandlers {
+	return &MirrorHandlers{}
+}
+
+// Register adds mirror routes to the mux.
+func (h *MirrorHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/mirror/source", h.Source)
+	mux.HandleFunc("/mirror/sink", h.Sink)
+}
+
+// Source handles /mirror/source for any method.
+func (h *MirrorHandlers) Source(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, "source", &h.source)
+}
+
+// Sink handles /mirror/sink for any method.
+func (h *MirrorHandlers) Sink(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, "sink", &h.sink)
+}
+
+func (h *MirrorHandlers) serve(w http.ResponseWriter, r *http.Request, endpoint string, c *mirrorCounts) {
+	mirrored := isMirrored(r)
+	c.total.Add(1)
+	label := "false"
+	if mirrored {
+		c.mirrored.Add(1)
+		label = "true"
+	}
+	metrics.MirrorRequestsTotal.WithLabelValues(endpoint, label).Inc()
+
+	resp := api.MirrorResponse{
+		Endpoint: endpoint,
+		Mirrored: mirrored,
+		Host:     r.Host,
+		Source:   h.source.api(),
+		Sink:     h.sink.api(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode mirror response", "error", err)
+	}
+}
+
+// isMirrored reports whether r carries MirrorHeader or a host ending in
+// shadowSuffix, with or without a port.
+func isMirrored(r *http.Request) bool {
+	if r.Header.Get(MirrorHeader) != "" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	return strings.HasSuffix(host, shadowSuffix)
+}
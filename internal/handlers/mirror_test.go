@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestMirror(t *testing.T) {
+	h := NewMirrorHandlers()
+	mux := http.NewServeMux()
+	h.Register(mux)
+	before := testutil.ToFloat64(metrics.MirrorRequestsTotal.WithLabelValues("sink", "true"))
+
+	tests := []struct {
+		path, host, header string
+		want               bool
+	}{
+		{"/mirror/source", "hotpod", "", false},
+		{"/mirror/source", "hotpod:8080", "", false},
+		{"/mirror/sink", "hotpod-shadow", "", true},
+		{"/mirror/sink", "hotpod-shadow:8080", "", true},
+		{"/mirror/sink", "hotpod", "1", true},
+	}
+	var last api.MirrorResponse
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", tt.path, nil)
+		req.Host = tt.host
+		if tt.header != "" {
+			req.Header.Set(MirrorHeader, tt.header)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s: status = %d, want 200", tt.path, tt.host, rec.Code)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &last); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if last.Mirrored != tt.want {
+			t.Errorf("%s %s: mirrored = %v, want %v", tt.path, tt.host, last.Mirrored, tt.want)
+		}
+	}
+
+	if want := (api.MirrorCounts{Total: 2}); last.Source != want {
+		t.Errorf("source = %+v, want %+v", last.Source, want)
+	}
+	if want := (api.MirrorCounts{Total: 3, Mirrored: 3}); last.Sink != want {
+		t.Errorf("sink = %+v, want %+v", last.Sink, want)
+	}
+	if got := testutil.ToFloat64(metrics.MirrorRequestsTotal.WithLabelValues("sink", "true")) - before; got != 3 {
+		t.Errorf("mirrored sink requests = %v, want 3", got)
+	}
+}
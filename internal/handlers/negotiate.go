@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Media types an informational endpoint can respond with.
+const (
+	mediaJSON     = "application/json"
+	mediaYAML     = "application/yaml"
+	mediaProtobuf = "application/x-protobuf"
+)
+
+// mediaAliases maps accepted media types to the one served.
+var mediaAliases = map[string]string{
+	"application/json":       mediaJSON,
+	"application/*":          mediaJSON,
+	"*/*":                    mediaJSON,
+	"application/yaml":       mediaYAML,
+	"application/x-yaml":     mediaYAML,
+	"text/yaml":              mediaYAML,
+	"application/x-protobuf": mediaProtobuf,
+	"application/protobuf":   mediaProtobuf,
+}
+
+// negotiate picks the response media type from the Accept header: the
+// supported type with the highest quality, the earliest listed on a tie. It
+// falls back to JSON when nothing listed is supported.
+func negotiate(r *http.Request) string {
+	best, bestQ := mediaJSON, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		served, ok := mediaAliases[mt]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = served, q
+		}
+	}
+	return best
+}
+
+// writeNegotiated writes v as JSON, YAML, or protobuf according to the
+// Accept header. YAML and protobuf carry the same fields as the JSON
+// encoding; protobuf responses are a google.protobuf.Struct message.
+func writeNegotiated(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Add("Vary", "Accept")
+
+	media := negotiate(r)
+	if media == mediaJSON {
+		w.Header().Set("Content-Type", mediaJSON)
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			slog.Warn("failed to encode response", "error", err)
+		}
+		return
+	}
+
+	body, err := encodeAs(media, v)
+	if err != nil {
+		slog.Warn("failed to encode response", "media_type", media, "error", err)
+		w.Header().Set("Content-Type", mediaJSON)
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			slog.Warn("failed to encode response", "error", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", media)
+	w.Write(body)
+}
+
+// encodeAs encodes v as YAML or protobuf by way of its JSON encoding, so
+// field names and omitted fields match.
+func encodeAs(media string, v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	generic = normalizeNumbers(generic)
+
+	if media == mediaYAML {
+		return yaml.Marshal(generic)
+	}
+	msg, err := structpb.NewValue(generic)
+	if err != nil {
+		return nil, err
+	}
+	if s := msg.GetStructValue(); s != nil {
+		return proto.Marshal(s)
+	}
+	return proto.Marshal(msg)
+}
+
+// normalizeNumbers replaces json.Number values with int64 or float64, so
+// integers are not written in exponent form.
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeNumbers(e)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
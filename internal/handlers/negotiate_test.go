@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", mediaJSON},
+		{"*/*", mediaJSON},
+		{"text/html", mediaJSON},
+		{"application/yaml", mediaYAML},
+		{"application/x-yaml", mediaYAML},
+		{"text/yaml; charset=utf-8", mediaYAML},
+		{"application/x-protobuf", mediaProtobuf},
+		{"application/json, application/yaml", mediaJSON},
+		{"application/json;q=0.5, application/yaml", mediaYAML},
+		{"application/x-protobuf;q=0.9, */*;q=0.1", mediaProtobuf},
+		{"application/yaml;q=bogus, application/x-protobuf", mediaProtobuf},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/info", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if got := negotiate(req); got != tt.want {
+				t.Errorf("negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteNegotiated(t *testing.T) {
+	resp := api.QueueStatusResponse{QueueDepth: 1 << 30, Workers: 4, OldestItemAge: "1s", Paused: true}
+
+	t.Run("yaml", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/queue/status", nil)
+		req.Header.Set("Accept", "application/yaml")
+		rec := httptest.NewRecorder()
+		writeNegotiated(rec, req, resp)
+
+		if ct := rec.Header().Get("Content-Type"); ct != mediaYAML {
+			t.Errorf("Content-Type = %q, want %q", ct, mediaYAML)
+		}
+		if !strings.Contains(rec.Body.String(), "queue_depth: 1073741824\n") {
+			t.Errorf("body = %q, want integer queue_depth", rec.Body.String())
+		}
+		var got map[string]any
+		if err := yaml.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to decode yaml: %v", err)
+		}
+		if got["oldest_item_age"] != "1s" || got["paused"] != true {
+			t.Errorf("decoded = %v", got)
+		}
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/queue/status", nil)
+		req.Header.Set("Accept", "application/x-protobuf")
+		rec := httptest.NewRecorder()
+		writeNegotiated(rec, req, resp)
+
+		if ct := rec.Header().Get("Content-Type"); ct != mediaProtobuf {
+			t.Errorf("Content-Type = %q, want %q", ct, mediaProtobuf)
+		}
+		var got structpb.Struct
+		if err := proto.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to decode protobuf: %v", err)
+		}
+		fields := got.GetFields()
+		if fields["workers"].GetNumberValue() != 4 || fields["oldest_item_age"].GetStringValue() != "1s" {
+			t.Errorf("decoded = %v", fields)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/queue/status", nil)
+		rec := httptest.NewRecorder()
+		writeNegotiated(rec, req, resp)
+
+		if ct := rec.Header().Get("Content-Type"); ct != mediaJSON {
+			t.Errorf("Content-Type = %q, want %q", ct, mediaJSON)
+		}
+		if v := rec.Header().Get("Vary"); v != "Accept" {
+			t.Errorf("Vary = %q, want Accept", v)
+		}
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+}
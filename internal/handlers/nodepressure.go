@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/nodepressure"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// NodePressureHandlers reports the node pressure watcher's view of the node
+// and toggles backing off.
+type NodePressureHandlers struct {
+	authn   *auth.Authenticator
+	watcher *nodepressure.Watcher
+}
+
+// NewNodePressureHandlers creates handlers for the node pressure admin
+// endpoints. watcher is nil when the watcher is disabled.
+func NewNodePressureHandlers(authn *auth.Authenticator, watcher *nodepressure.Watcher) *NodePressureHandlers {
+	return &NodePressureHandlers{authn: authn, watcher: watcher}
+}
+
+// Register adds node pressure routes to the mux.
+func (h *NodePressureHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/node-pressure", h.Get)
+	mux.HandleFunc("POST /admin/node-pressure", h.Set)
+}
+
+func (h *NodePressureHandlers) checkEnabled(w http.ResponseWriter) bool {
+	if h.watcher == nil {
+		writeError(w, http.StatusNotFound, errcode.NodePressureDisabled, "node pressure watcher is not enabled")
+		return false
+	}
+	return true
+}
+
+// Get handles GET /admin/node-pressure.
+func (h *NodePressureHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) || !h.checkEnabled(w) {
+		return
+	}
+	h.writeStatus(w)
+}
+
+// Set handles POST /admin/node-pressure?enabled=BOOL, turning backing off on
+// or off. The node is still watched while backing off is off.
+func (h *NodePressureHandlers) Set(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) || !h.checkEnabled(w) {
+		return
+	}
+
+	enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "enabled must be a boolean")
+		return
+	}
+	h.watcher.SetEnabled(enabled)
+
+	slog.Info("node pressure back-off toggled", "enabled", enabled)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "node pressure back-off toggled", map[string]any{"enabled": enabled})
+	h.writeStatus(w)
+}
+
+func (h *NodePressureHandlers) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.watcher.Status()); err != nil {
+		slog.Warn("failed to encode node pressure response", "error", err)
+	}
+}
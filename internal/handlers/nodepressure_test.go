@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/internal/nodepressure"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestNodePressureToggle(t *testing.T) {
+	watcher := nodepressure.New(kube.NewForTesting("http://127.0.0.1:0", "ns"), "node-1", time.Second)
+	h := NewNodePressureHandlers(nil, watcher)
+
+	rec := httptest.NewRecorder()
+	h.Set(rec, httptest.NewRequest("POST", "/admin/node-pressure?enabled=false", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var status api.NodePressureStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if status.Enabled || status.Node != "node-1" {
+		t.Errorf("status = %+v, want node-1 with back-off disabled", status)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Set(rec, httptest.NewRequest("POST", "/admin/node-pressure?enabled=maybe", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("enabled=maybe: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestNodePressureDisabled(t *testing.T) {
+	h := NewNodePressureHandlers(nil, nil)
+	rec := httptest.NewRecorder()
+	h.Get(rec, httptest.NewRequest("GET", "/admin/node-pressure", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// maxPreStopDelay bounds the delay accepted by GET /prestop; kubelet kills the
+// container once terminationGracePeriodSeconds elapses regardless.
+const maxPreStopDelay = 10 * time.Minute
+
+// PreStopHandlers provides an endpoint suitable for a pod preStop httpGet hook.
+type PreStopHandlers struct {
+	lifecycle *server.Lifecycle
+	// delay is how long the hook waits when no delay parameter is given
+	delay time.Duration
+}
+
+// NewPreStopHandlers creates handlers for the preStop hook endpoint.
+func NewPreStopHandlers(lc *server.Lifecycle, delay time.Duration) *PreStopHandlers {
+	return &PreStopHandlers{lifecycle: lc, delay: delay}
+}
+
+// Register adds preStop routes to the mux.
+func (h *PreStopHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /prestop", h.PreStop)
+}
+
+// PreStop handles GET /prestop. It marks the server not-ready, waits for the
+// delay (default: HOTPOD_PRESTOP_DELAY) so endpoints can be removed from load
+// balancers, and reports the in-flight requests remaining when it returns.
+// In-flight counts exclude the hook request itself.
+func (h *PreStopHandlers) PreStop(w http.ResponseWriter, r *http.Request) {
+	delay, err := parseDuration(r, "delay", h.delay)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if delay < 0 || delay > maxPreStopDelay {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "delay must be between 0 and "+maxPreStopDelay.String())
+		return
+	}
+
+	h.lifecycle.BeginPreStop()
+	before := h.inFlight()
+
+	start := time.Now()
+	interrupted := false
+	select {
+	case <-time.After(delay):
+	case <-r.Context().Done():
+		interrupted = true
+	}
+	waited := time.Since(start)
+
+	after := h.inFlight()
+	slog.Info("preStop hook complete", "waited", waited, "in_flight_before", before, "in_flight_after", after)
+
+	resp := api.PreStopResponse{
+		Delay:          delay.String(),
+		Waited:         waited.Round(time.Millisecond).String(),
+		Interrupted:    interrupted,
+		InFlightBefore: before,
+		InFlightAfter:  after,
+		Ready:          h.lifecycle.IsReady(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode prestop response", "error", err)
+	}
+}
+
+// inFlight returns in-flight requests other than the hook itself, which is
+// counted when it passes through the request tracking middleware.
+func (h *PreStopHandlers) inFlight() int64 {
+	n := h.lifecycle.InFlightRequests() - 1
+	if n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestPreStopMarksNotReady(t *testing.T) {
+	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
+	h := NewPreStopHandlers(lc, time.Hour)
+	health := NewHealthHandlers(lc, nil, nil)
+
+	done := lc.TrackRequest()
+	defer done()
+
+	rec := httptest.NewRecorder()
+	h.PreStop(rec, httptest.NewRequest("GET", "/prestop?delay=10ms", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var resp api.PreStopResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Delay != "10ms" || resp.Ready {
+		t.Errorf("response = %+v", resp)
+	}
+	// The tracked request stands in for the hook itself.
+	if resp.InFlightBefore != 0 || resp.InFlightAfter != 0 {
+		t.Errorf("in-flight = %d/%d, want 0/0", resp.InFlightBefore, resp.InFlightAfter)
+	}
+
+	rec = httptest.NewRecorder()
+	health.Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Readyz status after preStop = %d, want 503", rec.Code)
+	}
+}
+
+func TestPreStopInvalidDelay(t *testing.T) {
+	lc := server.NewLifecycle(0, 0, 0, 30*time.Second, false)
+	h := NewPreStopHandlers(lc, 0)
+
+	for _, q := range []string{"delay=abc", "delay=-1s", "delay=1h"} {
+		rec := httptest.NewRecorder()
+		h.PreStop(rec, httptest.NewRequest("GET", "/prestop?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+	if lc.InPreStop() {
+		t.Error("invalid requests should not begin preStop")
+	}
+}
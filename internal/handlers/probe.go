@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/probe"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// ProbeHandlers reports the synthetic prober's latest results.
+type ProbeHandlers struct {
+	authn  *auth.Authenticator
+	prober *probe.Prober
+}
+
+// NewProbeHandlers creates handlers for the prober admin endpoint. prober is
+// nil when no probe targets are configured.
+func NewProbeHandlers(authn *auth.Authenticator, prober *probe.Prober) *ProbeHandlers {
+	return &ProbeHandlers{authn: authn, prober: prober}
+}
+
+// Register adds prober routes to the mux.
+func (h *ProbeHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/probes", h.Get)
+}
+
+// Get handles GET /admin/probes.
+func (h *ProbeHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	if h.prober == nil {
+		writeError(w, http.StatusNotFound, errcode.ProberDisabled, "no probe targets are configured")
+		return
+	}
+
+	resp := api.ProbesResponse{Interval: h.prober.Interval().String(), Results: h.prober.Results()}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode probes response", "error", err)
+	}
+}
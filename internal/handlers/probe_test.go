@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/probe"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestProbeHandlersDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	NewProbeHandlers(auth.New("", nil), nil).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/probes", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "PROBER_DISABLED") {
+		t.Errorf("status = %d, body = %s, want 404 PROBER_DISABLED", rec.Code, rec.Body)
+	}
+}
+
+func TestProbeHandlers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	targets, err := probe.ParseTargets("/healthz", ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prober := probe.New(targets, time.Minute, time.Second)
+	prober.ProbeAll(context.Background())
+
+	mux := http.NewServeMux()
+	NewProbeHandlers(auth.New("", nil), prober).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/probes", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp api.ProbesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Interval != "1m0s" || len(resp.Results) != 1 || !resp.Results[0].Success || resp.Results[0].Target != "/healthz" {
+		t.Errorf("response = %+v, want one successful /healthz probe", resp)
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+const (
+	// defaultProfileDuration is used for timed profiles without a seconds
+	// parameter.
+	defaultProfileDuration = 10 * time.Second
+	// maxProfileDuration bounds timed profiles.
+	maxProfileDuration = 5 * time.Minute
+)
+
+// NewPprofHandler returns the standard /debug/pprof/ handlers on their own mux.
+// With requireAuth, callers must hold the read role.
+func NewPprofHandler(authn *auth.Authenticator, requireAuth bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	if !requireAuth {
+		return mux
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(authn, w, r, auth.RoleRead) {
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ProfileHandlers captures runtime profiles through the main port, for
+// clusters where the pprof port is not reachable.
+type ProfileHandlers struct {
+	authn *auth.Authenticator
+	// requestTimeout bounds timed profiles, which must finish before the
+	// request times out (zero means no bound)
+	requestTimeout time.Duration
+	// mu serializes timed profiles, which share process-wide profiler state
+	mu sync.Mutex
+}
+
+// NewProfileHandlers creates handlers for on-demand profiles.
+func NewProfileHandlers(authn *auth.Authenticator, requestTimeout time.Duration) *ProfileHandlers {
+	return &ProfileHandlers{authn: authn, requestTimeout: requestTimeout}
+}
+
+// Register adds profile routes to the mux.
+func (h *ProfileHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/profile", h.Profile)
+}
+
+// Profile handles POST /admin/profile?type=cpu&seconds=N and returns the
+// profile as a download. cpu and trace record for the given duration. heap,
+// allocs, goroutine, and threadcreate are point-in-time snapshots. block and
+// mutex enable sampling for the given duration first, since both are off by
+// default. Snapshots accept debug=1 or debug=2 for a text rendering.
+func (h *ProfileHandlers) Profile(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	typ := r.URL.Query().Get("type")
+	if typ == "" {
+		typ = "cpu"
+	}
+
+	timed := typ == "cpu" || typ == "trace"
+	sampled := typ == "block" || typ == "mutex"
+	if !timed && !sampled && runtimepprof.Lookup(typ) == nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "type must be one of: cpu, trace, heap, allocs, goroutine, threadcreate, block, mutex")
+		return
+	}
+
+	def := time.Duration(0)
+	if timed || sampled {
+		def = defaultProfileDuration
+	}
+	seconds, err := parseInt(r, "seconds", int(def/time.Second))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	duration := time.Duration(seconds) * time.Second
+	if duration < 0 || duration > maxProfileDuration {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "seconds must be between 0 and "+strconv.Itoa(int(maxProfileDuration/time.Second)))
+		return
+	}
+	if timed && duration == 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "seconds must be positive for "+typ+" profiles")
+		return
+	}
+	if h.requestTimeout > 0 && duration >= h.requestTimeout {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "seconds must be shorter than the request timeout of "+h.requestTimeout.String())
+		return
+	}
+
+	debug, err := parseInt(r, "debug", 0)
+	if err != nil || debug < 0 || debug > 2 || (debug > 0 && timed) {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "debug must be 0, 1, or 2, and is not supported for cpu or trace")
+		return
+	}
+
+	if (timed || sampled) && duration > 0 {
+		if !h.mu.TryLock() {
+			writeError(w, http.StatusConflict, errcode.ProfileInProgress, "another timed profile is already running")
+			return
+		}
+		defer h.mu.Unlock()
+	}
+
+	slog.Info("capturing profile", "type", typ, "duration", duration)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "profile capture started", map[string]any{
+		"type":     typ,
+		"duration": duration.String(),
+	})
+
+	var buf bytes.Buffer
+	switch {
+	case typ == "cpu":
+		if err := runtimepprof.StartCPUProfile(&buf); err != nil {
+			writeError(w, http.StatusConflict, errcode.ProfileInProgress, "CPU profiling is already enabled: "+err.Error())
+			return
+		}
+		cancelled := sleep(r.Context(), duration)
+		runtimepprof.StopCPUProfile()
+		if cancelled {
+			return
+		}
+	case typ == "trace":
+		if err := trace.Start(&buf); err != nil {
+			writeError(w, http.StatusConflict, errcode.ProfileInProgress, "tracing is already enabled: "+err.Error())
+			return
+		}
+		cancelled := sleep(r.Context(), duration)
+		trace.Stop()
+		if cancelled {
+			return
+		}
+	default:
+		if sampled && duration > 0 && sampleContention(r.Context(), typ, duration) {
+			return
+		}
+		if err := runtimepprof.Lookup(typ).WriteTo(&buf, debug); err != nil {
+			writeError(w, http.StatusInternalServerError, errcode.ProfileFailed, err.Error())
+			return
+		}
+	}
+
+	ext := "pb.gz"
+	contentType := "application/octet-stream"
+	if typ == "trace" {
+		ext = "trace"
+	} else if debug > 0 {
+		ext = "txt"
+		contentType = "text/plain; charset=utf-8"
+	}
+	filename := fmt.Sprintf("hotpod-%s-%s.%s", typ, wallclock.Now().UTC().Format("20060102T150405Z"), ext)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.Warn("failed to write profile", "type", typ, "error", err)
+	}
+}
+
+// sampleContention enables block or mutex sampling for duration. It returns
+// true if ctx was cancelled first.
+func sampleContention(ctx context.Context, typ string, duration time.Duration) (cancelled bool) {
+	if typ == "mutex" {
+		prev := runtime.SetMutexProfileFraction(1)
+		defer runtime.SetMutexProfileFraction(prev)
+	} else {
+		runtime.SetBlockProfileRate(int(time.Microsecond))
+		defer runtime.SetBlockProfileRate(0)
+	}
+	return sleep(ctx, duration)
+}
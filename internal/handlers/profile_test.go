@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+)
+
+func TestProfile(t *testing.T) {
+	h := NewProfileHandlers(auth.New("", nil), 5*time.Second)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantGzip bool
+		wantText string
+	}{
+		{name: "heap", query: "type=heap", wantCode: http.StatusOK, wantGzip: true},
+		{name: "goroutine text", query: "type=goroutine&debug=1", wantCode: http.StatusOK, wantText: "goroutine profile"},
+		{name: "cpu", query: "type=cpu&seconds=1", wantCode: http.StatusOK, wantGzip: true},
+		{name: "mutex", query: "type=mutex&seconds=0", wantCode: http.StatusOK, wantGzip: true},
+		{name: "unknown type", query: "type=nope", wantCode: http.StatusBadRequest},
+		{name: "cpu without duration", query: "type=cpu&seconds=0", wantCode: http.StatusBadRequest},
+		{name: "longer than request timeout", query: "type=cpu&seconds=5", wantCode: http.StatusBadRequest},
+		{name: "debug on cpu", query: "type=cpu&seconds=1&debug=1", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.Profile(rec, httptest.NewRequest("POST", "/admin/profile?"+tt.query, nil))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if !strings.HasPrefix(rec.Header().Get("Content-Disposition"), "attachment;") {
+				t.Errorf("Content-Disposition = %q, want attachment", rec.Header().Get("Content-Disposition"))
+			}
+			if tt.wantGzip && !bytes.HasPrefix(rec.Body.Bytes(), []byte{0x1f, 0x8b}) {
+				t.Error("expected a gzipped protobuf profile")
+			}
+			if tt.wantText != "" && !strings.Contains(rec.Body.String(), tt.wantText) {
+				t.Errorf("body does not contain %q", tt.wantText)
+			}
+		})
+	}
+}
+
+func TestPprofHandlerAuth(t *testing.T) {
+	authn := auth.New("secret", nil)
+
+	tests := []struct {
+		requireAuth bool
+		token       string
+		wantCode    int
+	}{
+		{false, "", http.StatusOK},
+		{true, "", http.StatusUnauthorized},
+		{true, "secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/debug/pprof/", nil)
+		if tt.token != "" {
+			req.Header.Set("X-Admin-Token", tt.token)
+		}
+		rec := httptest.NewRecorder()
+		NewPprofHandler(authn, tt.requireAuth).ServeHTTP(rec, req)
+		if rec.Code != tt.wantCode {
+			t.Errorf("requireAuth=%v token=%q: status = %d, want %d", tt.requireAuth, tt.token, rec.Code, tt.wantCode)
+		}
+	}
+}
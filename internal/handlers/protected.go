@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// ProtectedHandlers provides the /protected endpoint, which requires
+// configured credentials, to verify ingress auth annotations, auth proxies
+// such as oauth2-proxy, and mesh authorization policies in front of it.
+type ProtectedHandlers struct {
+	// user and password are the required basic auth credentials, if any
+	user, password string
+	// header is the required header, if any; value, if set, is its
+	// required value
+	header, value string
+}
+
+// NewProtectedHandlers creates handlers for the protected endpoint.
+// basicAuth is user:password and header is Name or Name=value, both
+// validated by config; either may be empty to not require it.
+func NewProtectedHandlers(basicAuth, header string) *ProtectedHandlers {
+	h := &ProtectedHandlers{}
+	if basicAuth != "" {
+		h.user, h.password, _ = strings.Cut(basicAuth, ":")
+	}
+	if header != "" {
+		name, value, _ := strings.Cut(header, "=")
+		h.header, h.value = http.CanonicalHeaderKey(strings.TrimSpace(name)), value
+	}
+	return h
+}
+
+// Register adds protected routes to the mux.
+func (h *ProtectedHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/protected", h.Protected)
+}
+
+// Protected handles /protected for any method. Every configured requirement
+// must be met: a request missing a credential gets a 401, and one with a
+// wrong credential gets a 403.
+func (h *ProtectedHandlers) Protected(w http.ResponseWriter, r *http.Request) {
+	if h.user == "" && h.header == "" {
+		writeError(w, http.StatusNotFound, errcode.ProtectedDisabled, "no credentials are configured for /protected")
+		return
+	}
+
+	resp := api.ProtectedResponse{Authorized: true, Checks: []string{}}
+	if h.user != "" {
+		user, password, ok := r.BasicAuth()
+		if !ok {
+			metrics.ProtectedRequestsTotal.WithLabelValues("unauthorized").Inc()
+			w.Header().Set("WWW-Authenticate", `Basic realm="hotpod"`)
+			writeError(w, http.StatusUnauthorized, errcode.Unauthorized, "basic auth credentials are required")
+			return
+		}
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(h.user)) == 1
+		passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) == 1
+		if !userOK || !passwordOK {
+			metrics.ProtectedRequestsTotal.WithLabelValues("forbidden").Inc()
+			writeError(w, http.StatusForbidden, errcode.Forbidden, "basic auth credentials are invalid")
+			return
+		}
+		resp.User = user
+		resp.Checks = append(resp.Checks, "basic")
+	}
+
+	if h.header != "" {
+		v, ok := r.Header[h.header]
+		if !ok {
+			metrics.ProtectedRequestsTotal.WithLabelValues("unauthorized").Inc()
+			writeError(w, http.StatusUnauthorized, errcode.Unauthorized, h.header+" header is required")
+			return
+		}
+		if h.value != "" && subtle.ConstantTimeCompare([]byte(v[0]), []byte(h.value)) != 1 {
+			metrics.ProtectedRequestsTotal.WithLabelValues("forbidden").Inc()
+			writeError(w, http.StatusForbidden, errcode.Forbidden, h.header+" header is invalid")
+			return
+		}
+		resp.Checks = append(resp.Checks, "header")
+	}
+
+	metrics.ProtectedRequestsTotal.WithLabelValues("allowed").Inc()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode protected response", "error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+func TestProtected(t *testing.T) {
+	h := NewProtectedHandlers("alice:s3cr:et", "x-api-key=secret")
+
+	tests := []struct {
+		name           string
+		user, password string
+		key            string
+		want           int
+		wantCode       errcode.Code
+	}{
+		{"no credentials", "", "", "secret", http.StatusUnauthorized, errcode.Unauthorized},
+		{"wrong password", "alice", "nope", "secret", http.StatusForbidden, errcode.Forbidden},
+		{"wrong user", "bob", "s3cr:et", "secret", http.StatusForbidden, errcode.Forbidden},
+		{"no header", "alice", "s3cr:et", "", http.StatusUnauthorized, errcode.Unauthorized},
+		{"wrong header", "alice", "s3cr:et", "guess", http.StatusForbidden, errcode.Forbidden},
+		{"allowed", "alice", "s3cr:et", "secret", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/protected", nil)
+		if tt.user != "" {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+		if tt.key != "" {
+			req.Header.Set("X-Api-Key", tt.key)
+		}
+		rec := httptest.NewRecorder()
+		h.Protected(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+			continue
+		}
+		if tt.want != http.StatusOK {
+			var resp api.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Code != string(tt.wantCode) {
+				t.Errorf("%s: code = %q (%v), want %q", tt.name, resp.Code, err, tt.wantCode)
+			}
+			continue
+		}
+		var resp api.ProtectedResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if !resp.Authorized || resp.User != "alice" || !slices.Equal(resp.Checks, []string{"basic", "header"}) {
+			t.Errorf("%s: response = %+v, want alice authorized by both checks", tt.name, resp)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.Protected(rec, httptest.NewRequest("GET", "/protected", nil))
+	if got := rec.Header().Get("WWW-Authenticate"); got == "" {
+		t.Error("401 without WWW-Authenticate")
+	}
+}
+
+func TestProtectedHeaderPresence(t *testing.T) {
+	h := NewProtectedHandlers("", "X-Forwarded-User")
+
+	rec := httptest.NewRecorder()
+	h.Protected(rec, httptest.NewRequest("GET", "/protected", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("without header: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("X-Forwarded-User", "anyone")
+	rec = httptest.NewRecorder()
+	h.Protected(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("with header: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestProtectedDisabled(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewProtectedHandlers("", "").Protected(rec, httptest.NewRequest("GET", "/protected", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -3,13 +3,17 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/queue"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 // QueueHandlers provides queue endpoint handlers.
@@ -34,8 +38,11 @@ func NewQueueHandlers(enabled bool, q *queue.Queue, defaultWorkers int) *QueueHa
 func (h *QueueHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("POST /queue/enqueue", h.Enqueue)
 	mux.HandleFunc("POST /queue/process", h.Process)
+	mux.HandleFunc("POST /queue/workers", h.Workers)
 	mux.HandleFunc("GET /queue/status", h.Status)
 	mux.HandleFunc("POST /queue/clear", h.Clear)
+	mux.HandleFunc("GET /queue/items", h.Items)
+	mux.HandleFunc("GET /queue/items/{id}", h.Item)
 }
 
 // Queue returns the underlying queue for admin operations.
@@ -48,105 +55,207 @@ func (h *QueueHandlers) WorkerPool() *queue.WorkerPool {
 	return h.workerPool
 }
 
-// EnqueueResponse is the JSON response for /queue/enqueue.
-type EnqueueResponse struct {
-	Enqueued             int    `json:"enqueued"`
-	QueueDepth           int    `json:"queue_depth"`
-	EstimatedProcessTime string `json:"estimated_process_time"`
-	Rejected             int    `json:"rejected,omitempty"`
-	RejectionReason      string `json:"rejection_reason,omitempty"`
-}
-
+// Enqueue handles POST /queue/enqueue?count=10&priority=high. With a JSON
+// body (see api.EnqueueRequest), items are described individually instead, so
+// one request can mix priorities, processing times, payload sizes, and
+// delayed delivery. Every item is validated before any is enqueued.
 func (h *QueueHandlers) Enqueue(w http.ResponseWriter, r *http.Request) {
 	if !h.enabled {
-		writeError(w, http.StatusForbidden, "QUEUE_DISABLED", "queue endpoints are disabled")
+		writeError(w, http.StatusForbidden, errcode.QueueDisabled, "queue endpoints are disabled")
+		return
+	}
+
+	var items []*queue.Item
+	var err error
+	if isJSONRequest(r) {
+		items, err = parseEnqueueBody(w, r)
+	} else {
+		items, err = parseEnqueueQuery(r)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 
+	enqueued := 0
+	rejected := 0
+	reason := "queue full"
+	var totalProcessing time.Duration
+	for _, item := range items {
+		totalProcessing += item.ProcessingTime
+		if err := h.queue.Enqueue(item); err != nil {
+			rejected++
+			if errors.Is(err, queue.ErrPayloadLimit) {
+				reason = "payload limit reached"
+			}
+		} else {
+			enqueued++
+		}
+	}
+
+	depth := h.queue.Depth()
+	estimatedTime := time.Duration(float64(depth) * float64(totalProcessing) / float64(len(items)))
+
+	resp := api.EnqueueResponse{
+		Enqueued:             enqueued,
+		QueueDepth:           depth,
+		EstimatedProcessTime: estimatedTime.String(),
+	}
+
+	if rejected > 0 {
+		resp.Rejected = rejected
+		resp.RejectionReason = reason
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode enqueue response", "error", err)
+	}
+}
+
+const (
+	// maxEnqueueCount bounds the number of items in one enqueue request.
+	maxEnqueueCount = 10000
+	// maxEnqueueBody bounds the size of a JSON enqueue request.
+	maxEnqueueBody = 1 << 20
+	// maxPayloadSize bounds the memory held while processing one item.
+	maxPayloadSize = 100 << 20
+	// maxBatchSize bounds the number of items a worker processes at once.
+	maxBatchSize = 1000
+)
+
+// parseEnqueueQuery builds identical items from the count, processing_time,
+// and priority query parameters.
+func parseEnqueueQuery(r *http.Request) ([]*queue.Item, error) {
 	countStr := r.URL.Query().Get("count")
 	count := 1
 	if countStr != "" {
 		var err error
 		count, err = strconv.Atoi(countStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "count must be an integer")
-			return
+			return nil, errors.New("count must be an integer")
 		}
 		if count < 1 {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "count must be at least 1")
-			return
+			return nil, errors.New("count must be at least 1")
 		}
-		if count > 10000 {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "count must not exceed 10000")
-			return
+		if count > maxEnqueueCount {
+			return nil, fmt.Errorf("count must not exceed %d", maxEnqueueCount)
 		}
 	}
 
 	processingTime, err := parseDuration(r, "processing_time", 100*time.Millisecond)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
-		return
+		return nil, err
 	}
 
 	priority := r.URL.Query().Get("priority")
 	if priority == "" {
 		priority = queue.PriorityNormal
 	}
-	if priority != queue.PriorityHigh && priority != queue.PriorityNormal && priority != queue.PriorityLow {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "priority must be high, normal, or low")
-		return
+	if !validPriority(priority) {
+		return nil, errors.New("priority must be high, normal, or low")
 	}
 
-	enqueued := 0
-	rejected := 0
-	now := time.Now()
+	return newItems(nil, count, queue.Item{Priority: priority, ProcessingTime: processingTime}), nil
+}
 
-	for i := range count {
-		item := &queue.Item{
-			ID:             fmt.Sprintf("%d-%d", now.UnixNano(), i),
-			Priority:       priority,
-			ProcessingTime: processingTime,
-			EnqueuedAt:     now,
-		}
+// parseEnqueueBody builds items from an api.EnqueueRequest body.
+func parseEnqueueBody(w http.ResponseWriter, r *http.Request) ([]*queue.Item, error) {
+	var req api.EnqueueRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEnqueueBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return nil, errors.New("body must be a JSON object with an items array: " + err.Error())
+	}
+	if len(req.Items) == 0 {
+		return nil, errors.New("items must not be empty")
+	}
 
-		if err := h.queue.Enqueue(item); err != nil {
-			rejected++
-		} else {
-			enqueued++
+	var items []*queue.Item
+	for i, spec := range req.Items {
+		template, count, err := parseEnqueueItem(spec)
+		if err != nil {
+			return nil, fmt.Errorf("items[%d]: %w", i, err)
+		}
+		if len(items)+count > maxEnqueueCount {
+			return nil, fmt.Errorf("total count must not exceed %d", maxEnqueueCount)
 		}
+		items = newItems(items, count, template)
 	}
+	return items, nil
+}
 
-	depth := h.queue.Depth()
-	estimatedTime := time.Duration(depth) * processingTime
+// parseEnqueueItem validates one item description, returning the item to
+// copy and how many copies to make.
+func parseEnqueueItem(spec api.EnqueueItem) (queue.Item, int, error) {
+	item := queue.Item{
+		Priority:       spec.Priority,
+		ProcessingTime: 100 * time.Millisecond,
+		NotBefore:      spec.NotBefore,
+	}
 
-	resp := EnqueueResponse{
-		Enqueued:             enqueued,
-		QueueDepth:           depth,
-		EstimatedProcessTime: estimatedTime.String(),
+	count := spec.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 {
+		return item, 0, errors.New("count must be at least 1")
 	}
 
-	if rejected > 0 {
-		resp.Rejected = rejected
-		resp.RejectionReason = "queue full"
+	if item.Priority == "" {
+		item.Priority = queue.PriorityNormal
+	}
+	if !validPriority(item.Priority) {
+		return item, 0, errors.New("priority must be high, normal, or low")
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		slog.Warn("failed to encode enqueue response", "error", err)
+	if spec.ProcessingTime != "" {
+		d, err := time.ParseDuration(spec.ProcessingTime)
+		if err != nil {
+			return item, 0, fmt.Errorf("invalid processing_time: %w", err)
+		}
+		if d < 0 {
+			return item, 0, errors.New("processing_time must be non-negative")
+		}
+		item.ProcessingTime = d
+	}
+
+	if spec.PayloadSize != "" {
+		size, err := config.ParseSize(spec.PayloadSize)
+		if err != nil {
+			return item, 0, fmt.Errorf("invalid payload_size: %w", err)
+		}
+		if size < 0 || size > maxPayloadSize {
+			return item, 0, fmt.Errorf("payload_size must be between 0 and %s", formatSize(maxPayloadSize))
+		}
+		item.PayloadSize = size
+	}
+
+	return item, count, nil
+}
+
+// newItems appends count copies of template to items, each with its own ID.
+func newItems(items []*queue.Item, count int, template queue.Item) []*queue.Item {
+	now := time.Now()
+	for range count {
+		item := template
+		item.ID = fmt.Sprintf("%d-%d", now.UnixNano(), len(items))
+		item.EnqueuedAt = now
+		items = append(items, &item)
 	}
+	return items
 }
 
-// ProcessResponse is the JSON response for /queue/process.
-type ProcessResponse struct {
-	Workers       int    `json:"workers"`
-	CPUPerItem    string `json:"cpu_per_item"`
-	MemoryPerItem string `json:"memory_per_item"`
-	Started       bool   `json:"started"`
+func validPriority(priority string) bool {
+	return priority == queue.PriorityHigh || priority == queue.PriorityNormal || priority == queue.PriorityLow
 }
 
+// Process handles POST /queue/process?workers=4&cpu_per_item=50ms&batch_size=10
+// by (re)starting the worker pool. With batch_size above 1, cpu_per_item and
+// memory_per_item are spent once per batch, amortized across its items.
 func (h *QueueHandlers) Process(w http.ResponseWriter, r *http.Request) {
 	if !h.enabled {
-		writeError(w, http.StatusForbidden, "QUEUE_DISABLED", "queue endpoints are disabled")
+		writeError(w, http.StatusForbidden, errcode.QueueDisabled, "queue endpoints are disabled")
 		return
 	}
 
@@ -156,38 +265,49 @@ func (h *QueueHandlers) Process(w http.ResponseWriter, r *http.Request) {
 		var err error
 		workers, err = strconv.Atoi(workersStr)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "workers must be an integer")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "workers must be an integer")
 			return
 		}
 		if workers < 1 {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "workers must be at least 1")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "workers must be at least 1")
 			return
 		}
 		if workers > 100 {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "workers must not exceed 100")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "workers must not exceed 100")
 			return
 		}
 	}
 
 	cpuPerItem, err := parseDuration(r, "cpu_per_item", 0)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
 		return
 	}
 
 	memoryPerItem, err := parseSize(r, "memory_per_item", 0)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", err.Error())
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	batchSize, err := parseInt(r, "batch_size", 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if batchSize < 1 || batchSize > maxBatchSize {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("batch_size must be between 1 and %d", maxBatchSize))
 		return
 	}
 
 	// XXX: use background context since workers run independently
-	h.workerPool.Start(context.Background(), workers, cpuPerItem, memoryPerItem)
+	h.workerPool.Start(context.Background(), workers, cpuPerItem, memoryPerItem, batchSize)
 
-	resp := ProcessResponse{
+	resp := api.ProcessResponse{
 		Workers:       workers,
 		CPUPerItem:    cpuPerItem.String(),
 		MemoryPerItem: formatSize(memoryPerItem),
+		BatchSize:     batchSize,
 		Started:       true,
 	}
 
@@ -197,29 +317,57 @@ func (h *QueueHandlers) Process(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// StatusResponse is the JSON response for /queue/status.
-type StatusResponse struct {
-	QueueDepth          int    `json:"queue_depth"`
-	HighPriorityDepth   int    `json:"high_priority_depth"`
-	NormalPriorityDepth int    `json:"normal_priority_depth"`
-	LowPriorityDepth    int    `json:"low_priority_depth"`
-	ItemsEnqueuedTotal  int64  `json:"items_enqueued_total"`
-	ItemsProcessedTotal int64  `json:"items_processed_total"`
-	ItemsFailedTotal    int64  `json:"items_failed_total"`
-	ActiveWorkers       int    `json:"active_workers"`
-	OldestItemAge       string `json:"oldest_item_age"`
-	Paused              bool   `json:"paused"`
+// Workers handles POST /queue/workers?count=N, resizing the running worker
+// pool. Unlike /queue/process, in-progress items are not interrupted: retired
+// workers exit after finishing their current item. A count of zero idles the
+// pool without stopping it.
+func (h *QueueHandlers) Workers(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		writeError(w, http.StatusForbidden, errcode.QueueDisabled, "queue endpoints are disabled")
+		return
+	}
+
+	if r.URL.Query().Get("count") == "" {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "count is required")
+		return
+	}
+	count, err := parseInt(r, "count", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if count < 0 || count > 100 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "count must be between 0 and 100")
+		return
+	}
+
+	previous, err := h.workerPool.Scale(count)
+	if err != nil {
+		writeError(w, http.StatusConflict, errcode.PoolNotRunning, "worker pool is not running; start it with POST /queue/process")
+		return
+	}
+
+	resp := api.QueueWorkersResponse{
+		Workers:       count,
+		Previous:      previous,
+		ActiveWorkers: h.workerPool.ActiveWorkers(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode workers response", "error", err)
+	}
 }
 
 func (h *QueueHandlers) Status(w http.ResponseWriter, r *http.Request) {
 	if !h.enabled {
-		writeError(w, http.StatusForbidden, "QUEUE_DISABLED", "queue endpoints are disabled")
+		writeError(w, http.StatusForbidden, errcode.QueueDisabled, "queue endpoints are disabled")
 		return
 	}
 
 	stats := h.queue.Stats()
 
-	resp := StatusResponse{
+	resp := api.QueueStatusResponse{
 		QueueDepth:          stats.Depth,
 		HighPriorityDepth:   stats.HighDepth,
 		NormalPriorityDepth: stats.NormalDepth,
@@ -227,32 +375,25 @@ func (h *QueueHandlers) Status(w http.ResponseWriter, r *http.Request) {
 		ItemsEnqueuedTotal:  stats.EnqueuedTotal,
 		ItemsProcessedTotal: stats.ProcessedTotal,
 		ItemsFailedTotal:    stats.FailedTotal,
+		Workers:             h.workerPool.Workers(),
 		ActiveWorkers:       h.workerPool.ActiveWorkers(),
 		OldestItemAge:       stats.OldestItemAge.Round(time.Millisecond).String(),
 		Paused:              stats.Paused,
+		DelayedDepth:        stats.DelayedDepth,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		slog.Warn("failed to encode status response", "error", err)
-	}
-}
-
-// ClearResponse is the JSON response for /queue/clear.
-type ClearResponse struct {
-	Cleared    int `json:"cleared"`
-	QueueDepth int `json:"queue_depth"`
+	writeNegotiated(w, r, resp)
 }
 
 func (h *QueueHandlers) Clear(w http.ResponseWriter, r *http.Request) {
 	if !h.enabled {
-		writeError(w, http.StatusForbidden, "QUEUE_DISABLED", "queue endpoints are disabled")
+		writeError(w, http.StatusForbidden, errcode.QueueDisabled, "queue endpoints are disabled")
 		return
 	}
 
 	cleared := h.queue.Clear()
 
-	resp := ClearResponse{
+	resp := api.QueueClearResponse{
 		Cleared:    cleared,
 		QueueDepth: h.queue.Depth(),
 	}
@@ -262,3 +403,101 @@ func (h *QueueHandlers) Clear(w http.ResponseWriter, r *http.Request) {
 		slog.Warn("failed to encode clear response", "error", err)
 	}
 }
+
+// defaultItemsLimit and maxItemsLimit bound the page size of GET /queue/items.
+const (
+	defaultItemsLimit = 100
+	maxItemsLimit     = 1000
+)
+
+// Items handles GET /queue/items?priority=high&offset=0&limit=100, listing
+// queued items in dequeue order. Positions shift as workers dequeue items, so
+// pages fetched while the queue is processing may overlap or skip items.
+func (h *QueueHandlers) Items(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		writeError(w, http.StatusForbidden, errcode.QueueDisabled, "queue endpoints are disabled")
+		return
+	}
+
+	priority := r.URL.Query().Get("priority")
+	if priority != "" && !validPriority(priority) {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "priority must be high, normal, or low")
+		return
+	}
+
+	offset, err := parseInt(r, "offset", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if offset < 0 {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "offset must be non-negative")
+		return
+	}
+
+	limit, err := parseInt(r, "limit", defaultItemsLimit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if limit < 1 || limit > maxItemsLimit {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, fmt.Sprintf("limit must be between 1 and %d", maxItemsLimit))
+		return
+	}
+
+	items, start := h.queue.Items(priority)
+	resp := api.QueueItemsResponse{
+		Total:  len(items),
+		Offset: offset,
+		Limit:  limit,
+		Items:  []api.QueueItem{},
+	}
+
+	now := time.Now()
+	end := min(offset+limit, len(items))
+	for i := offset; i < end; i++ {
+		resp.Items = append(resp.Items, newQueueItem(items[i], start+i, now))
+	}
+	if end < len(items) {
+		resp.NextOffset = end
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode items response", "error", err)
+	}
+}
+
+// Item handles GET /queue/items/{id}, reporting an item's position and wait
+// time while it remains queued.
+func (h *QueueHandlers) Item(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		writeError(w, http.StatusForbidden, errcode.QueueDisabled, "queue endpoints are disabled")
+		return
+	}
+
+	id := r.PathValue("id")
+	item, position, ok := h.queue.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, errcode.ItemNotFound, fmt.Sprintf("item %q is not queued", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newQueueItem(item, position, time.Now())); err != nil {
+		slog.Warn("failed to encode item response", "error", err)
+	}
+}
+
+func newQueueItem(item queue.Item, position int, now time.Time) api.QueueItem {
+	return api.QueueItem{
+		ID:             item.ID,
+		Priority:       item.Priority,
+		Position:       position,
+		ProcessingTime: item.ProcessingTime.String(),
+		PayloadSize:    item.PayloadSize,
+		EnqueuedAt:     item.EnqueuedAt,
+		NotBefore:      item.NotBefore,
+		Wait:           now.Sub(item.EnqueuedAt).Round(time.Millisecond).String(),
+	}
+}
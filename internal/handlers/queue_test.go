@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ripta/hotpod/internal/queue"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 type endpoint struct {
@@ -17,8 +21,11 @@ type endpoint struct {
 var queueEndpoints = []endpoint{
 	{"POST", "/queue/enqueue"},
 	{"POST", "/queue/process"},
+	{"POST", "/queue/workers"},
 	{"GET", "/queue/status"},
 	{"POST", "/queue/clear"},
+	{"GET", "/queue/items"},
+	{"GET", "/queue/items/1"},
 }
 
 func TestQueueEnqueueDisabled(t *testing.T) {
@@ -48,7 +55,7 @@ func TestQueueEnqueueDefault(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp EnqueueResponse
+	var resp api.EnqueueResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -74,7 +81,7 @@ func TestQueueEnqueueMultiple(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp EnqueueResponse
+	var resp api.EnqueueResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -128,7 +135,7 @@ func TestQueueEnqueueQueueFull(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp EnqueueResponse
+	var resp api.EnqueueResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -169,7 +176,7 @@ func TestQueueProcessDefault(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp ProcessResponse
+	var resp api.ProcessResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -177,6 +184,9 @@ func TestQueueProcessDefault(t *testing.T) {
 	if resp.Workers != 2 {
 		t.Errorf("workers = %d, want 2 (default)", resp.Workers)
 	}
+	if resp.BatchSize != 1 {
+		t.Errorf("batch_size = %d, want 1 (default)", resp.BatchSize)
+	}
 	if !resp.Started {
 		t.Error("expected started = true")
 	}
@@ -199,6 +209,69 @@ func TestQueueProcessInvalidWorkers(t *testing.T) {
 	}
 }
 
+func TestQueueProcessInvalidBatchSize(t *testing.T) {
+	q := queue.New(100)
+	h := NewQueueHandlers(true, q, 1)
+
+	for _, size := range []string{"invalid", "0", "1001"} {
+		req := httptest.NewRequest("POST", "/queue/process?batch_size="+size, nil)
+		rec := httptest.NewRecorder()
+
+		h.Process(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("batch_size=%s: status = %d, want %d", size, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestQueueWorkers(t *testing.T) {
+	q := queue.New(100)
+	h := NewQueueHandlers(true, q, 1)
+	mux := http.NewServeMux()
+	h.Register(mux)
+	defer h.WorkerPool().Stop()
+
+	post := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/queue/workers?"+query, nil))
+		return rec
+	}
+
+	if rec := post("count=2"); rec.Code != http.StatusConflict {
+		t.Errorf("scale before process: status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	h.WorkerPool().Start(t.Context(), 1, 0, 0, 1)
+	for _, query := range []string{"", "count=x", "count=-1", "count=101"} {
+		if rec := post(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	rec := post("count=4")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp api.QueueWorkersResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Workers != 4 || resp.Previous != 1 {
+		t.Errorf("response = %+v, want 4 workers, previously 1", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/queue/status", nil))
+	var status api.QueueStatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to parse status: %v", err)
+	}
+	if status.Workers != 4 {
+		t.Errorf("status workers = %d, want 4", status.Workers)
+	}
+}
+
 func TestQueueStatusDisabled(t *testing.T) {
 	q := queue.New(100)
 	h := NewQueueHandlers(false, q, 1)
@@ -233,7 +306,7 @@ func TestQueueStatus(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp StatusResponse
+	var resp api.QueueStatusResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -280,7 +353,7 @@ func TestQueueClear(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp ClearResponse
+	var resp api.QueueClearResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -293,6 +366,190 @@ func TestQueueClear(t *testing.T) {
 	}
 }
 
+func TestQueueEnqueueJSON(t *testing.T) {
+	q := queue.New(100)
+	h := NewQueueHandlers(true, q, 1)
+
+	notBefore := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	body := `{"items":[
+		{"count":2,"priority":"high","processing_time":"1s","payload_size":"2Ki"},
+		{"processing_time":"3s","not_before":"` + notBefore + `"}
+	]}`
+	req := httptest.NewRequest("POST", "/queue/enqueue", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.Enqueue(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp api.EnqueueResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Enqueued != 3 || resp.QueueDepth != 3 || resp.EstimatedProcessTime != "5s" {
+		t.Errorf("response = %+v", resp)
+	}
+
+	items, _ := q.Items("")
+	if len(items) != 3 {
+		t.Fatalf("queued %d items, want 3", len(items))
+	}
+	if items[0].Priority != queue.PriorityHigh || items[0].ProcessingTime != time.Second || items[0].PayloadSize != 2<<10 {
+		t.Errorf("first item = %+v", items[0])
+	}
+	if items[0].ID == items[1].ID {
+		t.Errorf("items share ID %q", items[0].ID)
+	}
+	if items[2].Priority != queue.PriorityNormal || items[2].NotBefore.IsZero() {
+		t.Errorf("delayed item = %+v", items[2])
+	}
+	if stats := q.Stats(); stats.DelayedDepth != 1 {
+		t.Errorf("delayed depth = %d, want 1", stats.DelayedDepth)
+	}
+}
+
+func TestQueueEnqueuePayloadLimit(t *testing.T) {
+	q := queue.New(100)
+	q.SetMaxPayload(5 << 10)
+	h := NewQueueHandlers(true, q, 1)
+
+	body := `{"items":[{"count":3,"payload_size":"2Ki"}]}`
+	req := httptest.NewRequest("POST", "/queue/enqueue", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.Enqueue(rec, req)
+
+	var resp api.EnqueueResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Enqueued != 2 || resp.Rejected != 1 || resp.RejectionReason != "payload limit reached" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestQueueEnqueueJSONInvalid(t *testing.T) {
+	for _, body := range []string{
+		`not json`,
+		`{"items":[]}`,
+		`{"items":[{"priority":"urgent"}]}`,
+		`{"items":[{"count":-1}]}`,
+		`{"items":[{"processing_time":"soon"}]}`,
+		`{"items":[{"payload_size":"1Ti"}]}`,
+		`{"items":[{"not_before":"tomorrow"}]}`,
+		`{"items":[{"count":6000},{"count":6000}]}`,
+		`{"items":[{"size":1}]}`,
+	} {
+		q := queue.New(100)
+		req := httptest.NewRequest("POST", "/queue/enqueue", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		NewQueueHandlers(true, q, 1).Enqueue(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+		if q.Depth() != 0 {
+			t.Errorf("%s: enqueued %d items from an invalid request", body, q.Depth())
+		}
+	}
+}
+
+func TestQueueItems(t *testing.T) {
+	q := queue.New(100)
+	for _, item := range []*queue.Item{
+		{ID: "n1", Priority: queue.PriorityNormal, EnqueuedAt: time.Now()},
+		{ID: "n2", Priority: queue.PriorityNormal, EnqueuedAt: time.Now()},
+		{ID: "h1", Priority: queue.PriorityHigh, EnqueuedAt: time.Now()},
+		{ID: "n3", Priority: queue.PriorityNormal, EnqueuedAt: time.Now()},
+		{ID: "l1", Priority: queue.PriorityLow, EnqueuedAt: time.Now()},
+	} {
+		if err := q.Enqueue(item); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+	}
+	mux := http.NewServeMux()
+	NewQueueHandlers(true, q, 1).Register(mux)
+
+	tests := []struct {
+		name          string
+		query         string
+		wantTotal     int
+		wantIDs       []string
+		wantPositions []int
+		wantNext      int
+	}{
+		{"all", "", 5, []string{"h1", "n1", "n2", "n3", "l1"}, []int{0, 1, 2, 3, 4}, 0},
+		{"first page", "limit=2", 5, []string{"h1", "n1"}, []int{0, 1}, 2},
+		{"second page", "limit=2&offset=2", 5, []string{"n2", "n3"}, []int{2, 3}, 4},
+		{"past the end", "offset=10", 5, nil, nil, 0},
+		{"by priority", "priority=normal&offset=1", 3, []string{"n2", "n3"}, []int{2, 3}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest("GET", "/queue/items?"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			var resp api.QueueItemsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+			var ids []string
+			var positions []int
+			for _, item := range resp.Items {
+				ids = append(ids, item.ID)
+				positions = append(positions, item.Position)
+			}
+			if resp.Total != tt.wantTotal || !slices.Equal(ids, tt.wantIDs) || !slices.Equal(positions, tt.wantPositions) || resp.NextOffset != tt.wantNext {
+				t.Errorf("response = total %d, ids %v, positions %v, next %d; want %d, %v, %v, %d",
+					resp.Total, ids, positions, resp.NextOffset, tt.wantTotal, tt.wantIDs, tt.wantPositions, tt.wantNext)
+			}
+		})
+	}
+
+	for _, query := range []string{"priority=urgent", "limit=0", "limit=5000", "offset=-1", "offset=x"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/queue/items?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestQueueItem(t *testing.T) {
+	q := queue.New(100)
+	enqueuedAt := time.Now().Add(-time.Minute)
+	q.Enqueue(&queue.Item{ID: "a", Priority: queue.PriorityLow, ProcessingTime: time.Second, EnqueuedAt: enqueuedAt})
+	q.Enqueue(&queue.Item{ID: "b", Priority: queue.PriorityHigh, EnqueuedAt: enqueuedAt})
+	mux := http.NewServeMux()
+	NewQueueHandlers(true, q, 1).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/queue/items/a", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var item api.QueueItem
+	if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if item.ID != "a" || item.Priority != queue.PriorityLow || item.Position != 1 || item.ProcessingTime != "1s" {
+		t.Errorf("item = %+v", item)
+	}
+	if wait, err := time.ParseDuration(item.Wait); err != nil || wait < time.Minute {
+		t.Errorf("wait = %q, want at least 1m", item.Wait)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/queue/items/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing item: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
 func TestQueueRegister(t *testing.T) {
 	q := queue.New(100)
 	h := NewQueueHandlers(false, q, 1)
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/replay"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// maxReplayLogSize bounds the request log accepted by POST /admin/replay.
+const maxReplayLogSize = 32 << 20
+
+// ReplayHandlers provides endpoints to replay recorded traffic.
+type ReplayHandlers struct {
+	authn  *auth.Authenticator
+	player *replay.Player
+}
+
+// NewReplayHandlers creates handlers for replay endpoints.
+func NewReplayHandlers(authn *auth.Authenticator, player *replay.Player) *ReplayHandlers {
+	return &ReplayHandlers{authn: authn, player: player}
+}
+
+// Register adds replay routes to the mux.
+func (h *ReplayHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/replay", h.Start)
+	mux.HandleFunc("GET /admin/replay", h.Status)
+	mux.HandleFunc("DELETE /admin/replay", h.Stop)
+}
+
+// Start handles POST /admin/replay. The request body is the request log;
+// format (csv or json), speed, and loop are query parameters.
+func (h *ReplayHandlers) Start(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = replay.FormatJSON
+		if r.Header.Get("Content-Type") == "text/csv" {
+			format = replay.FormatCSV
+		}
+	}
+
+	speed := 1.0
+	if v := r.URL.Query().Get("speed"); v != "" {
+		var err error
+		if speed, err = strconv.ParseFloat(v, 64); err != nil || speed <= 0 {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "speed must be a positive number")
+			return
+		}
+	}
+
+	loop, _ := strconv.ParseBool(r.URL.Query().Get("loop"))
+
+	records, err := replay.Parse(io.LimitReader(r.Body, maxReplayLogSize), format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	if err := h.player.Start(records, speed, loop); err != nil {
+		if errors.Is(err, replay.ErrRunning) {
+			writeError(w, http.StatusConflict, errcode.ReplayRunning, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	events.Record(slog.LevelInfo, events.TypeScenario, "replay started", map[string]any{
+		"records": len(records),
+		"speed":   speed,
+		"loop":    loop,
+	})
+
+	writeReplayStatus(w, http.StatusAccepted, h.player.Status())
+}
+
+// Status handles GET /admin/replay.
+func (h *ReplayHandlers) Status(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	writeReplayStatus(w, http.StatusOK, h.player.Status())
+}
+
+// Stop handles DELETE /admin/replay.
+func (h *ReplayHandlers) Stop(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	h.player.Stop()
+	events.Record(slog.LevelInfo, events.TypeScenario, "replay stopped", nil)
+	writeReplayStatus(w, http.StatusOK, h.player.Status())
+}
+
+func writeReplayStatus(w http.ResponseWriter, status int, s replay.Status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(s); err != nil {
+		slog.Warn("failed to encode replay status", "error", err)
+	}
+}
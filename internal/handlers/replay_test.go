@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/replay"
+)
+
+func TestReplayStartAndStatus(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	player := replay.NewPlayer(target.URL)
+	defer player.Stop()
+	h := NewReplayHandlers(auth.New("", nil), player)
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	req := httptest.NewRequest("POST", "/admin/replay?format=csv&speed=10", strings.NewReader("0,/cpu,duration=1ms\n1,/latency\n"))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+
+	var status replay.Status
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if status.Total != 2 || status.Speed != 10 {
+		t.Errorf("status = %+v", status)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/replay", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET status = %d, want 200", rec.Code)
+	}
+}
+
+func TestReplayInvalid(t *testing.T) {
+	h := NewReplayHandlers(auth.New("", nil), replay.NewPlayer("http://127.0.0.1:0"))
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	for _, q := range []string{"format=csv&speed=0", "format=csv&speed=x", "format=xml"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/replay?"+q, strings.NewReader("0,/cpu\n")))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// ScrapeCostHandlers reads and sets the delay and padding of /metrics
+// responses.
+type ScrapeCostHandlers struct {
+	authn *auth.Authenticator
+	cost  *metrics.ScrapeCost
+}
+
+// NewScrapeCostHandlers creates handlers for the scrape cost admin endpoints.
+func NewScrapeCostHandlers(authn *auth.Authenticator, cost *metrics.ScrapeCost) *ScrapeCostHandlers {
+	return &ScrapeCostHandlers{authn: authn, cost: cost}
+}
+
+// Register adds scrape cost routes to the mux.
+func (h *ScrapeCostHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/metrics-scrape", h.Get)
+	mux.HandleFunc("POST /admin/metrics-scrape", h.Set)
+	mux.HandleFunc("DELETE /admin/metrics-scrape", h.Clear)
+}
+
+// Get handles GET /admin/metrics-scrape.
+func (h *ScrapeCostHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeCost(w)
+}
+
+// Set handles POST /admin/metrics-scrape?delay=D&jitter=J&padding=SIZE,
+// replacing the scrape cost; omitted parameters are zero.
+func (h *ScrapeCostHandlers) Set(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	delay, err := parseDuration(r, "delay", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	jitter, err := parseDuration(r, "jitter", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	padding, err := parseSize(r, "padding", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "invalid padding: "+err.Error())
+		return
+	}
+	if err := h.cost.Set(delay, jitter, padding); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	slog.Info("metrics scrape cost set", "delay", delay, "jitter", jitter, "padding", padding)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "metrics scrape cost set", map[string]any{
+		"delay":   delay.String(),
+		"jitter":  jitter.String(),
+		"padding": padding,
+	})
+	h.writeCost(w)
+}
+
+// Clear handles DELETE /admin/metrics-scrape, removing the delay and padding.
+func (h *ScrapeCostHandlers) Clear(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	h.cost.Set(0, 0, 0)
+	slog.Info("metrics scrape cost cleared")
+	events.Record(slog.LevelInfo, events.TypeAdmin, "metrics scrape cost cleared", nil)
+	h.writeCost(w)
+}
+
+func (h *ScrapeCostHandlers) writeCost(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(scrapeCostResponse(h.cost)); err != nil {
+		slog.Warn("failed to encode scrape cost response", "error", err)
+	}
+}
+
+func scrapeCostResponse(cost *metrics.ScrapeCost) api.AdminScrapeCostResponse {
+	delay, jitter, padding := cost.Get()
+	resp := api.AdminScrapeCostResponse{
+		Delay:        delay.String(),
+		Padding:      formatSize(padding),
+		PaddingBytes: padding,
+	}
+	if jitter > 0 {
+		resp.Jitter = jitter.String()
+	}
+	return resp
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestScrapeCostHandlers(t *testing.T) {
+	cost := metrics.NewScrapeCost()
+	mux := http.NewServeMux()
+	NewScrapeCostHandlers(auth.New("", nil), cost).Register(mux)
+
+	for _, tt := range []struct {
+		method      string
+		path        string
+		wantStatus  int
+		wantPadding int64
+	}{
+		{"GET", "/admin/metrics-scrape", http.StatusOK, 0},
+		{"POST", "/admin/metrics-scrape?delay=2s&padding=1MB", http.StatusOK, 1 << 20},
+		{"POST", "/admin/metrics-scrape?delay=10m", http.StatusBadRequest, 0},
+		{"POST", "/admin/metrics-scrape?padding=1GB", http.StatusBadRequest, 0},
+		{"POST", "/admin/metrics-scrape?padding=lots", http.StatusBadRequest, 0},
+		{"DELETE", "/admin/metrics-scrape", http.StatusOK, 0},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.path, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp api.AdminScrapeCostResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.PaddingBytes != tt.wantPadding {
+			t.Errorf("%s %s: padding = %d, want %d", tt.method, tt.path, resp.PaddingBytes, tt.wantPadding)
+		}
+	}
+}
+
+func TestMetricsScrapeCost(t *testing.T) {
+	cost := metrics.NewScrapeCost()
+	mux := http.NewServeMux()
+	NewMetricsHandlers(cost).Register(mux)
+
+	scrape := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		return rec
+	}
+
+	base := scrape()
+	if strings.Contains(base.Body.String(), "hotpod_scrape_padding") {
+		t.Error("padding series present without padding configured")
+	}
+
+	cost.Set(30*time.Millisecond, 0, 64<<10)
+	start := time.Now()
+	rec := scrape()
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("elapsed = %v, want at least 30ms", elapsed)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if grown := rec.Body.Len() - base.Body.Len(); grown < 64<<10 {
+		t.Errorf("payload grew by %d bytes, want at least %d", grown, 64<<10)
+	}
+}
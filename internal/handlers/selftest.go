@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/contract"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// SelftestHandlers runs the quick contract checks against the server's own
+// routes.
+type SelftestHandlers struct {
+	authn  *auth.Authenticator
+	target http.Handler
+	gates  *server.EndpointGates
+	app    bool
+}
+
+// NewSelftestHandlers creates handlers for the self-test endpoint, sending
+// checks to target, normally the server's mux. Load checks run only if app
+// is set, as the load endpoints are only registered in app mode. Checks of
+// endpoint groups disabled in gates, which may be nil, are skipped.
+func NewSelftestHandlers(authn *auth.Authenticator, target http.Handler, gates *server.EndpointGates, app bool) *SelftestHandlers {
+	return &SelftestHandlers{authn: authn, target: target, gates: gates, app: app}
+}
+
+// Register adds self-test routes to the mux.
+func (h *SelftestHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/selftest", h.Selftest)
+}
+
+// Selftest handles GET /admin/selftest, sending each quick contract check
+// straight to the routes, bypassing middleware other than endpoint gates, and
+// validating the responses against their committed schemas. It responds 200
+// whether or not every check passed; see passed.
+func (h *SelftestHandlers) Selftest(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+
+	start := time.Now()
+	resp := api.AdminSelftestResponse{Passed: true}
+	var checks []contract.Check
+	for _, c := range contract.Quick(h.app) {
+		if h.gates.Allows(c.Path()) {
+			checks = append(checks, c)
+			continue
+		}
+		resp.Checks = append(resp.Checks, api.SelftestCheck{Name: c.Name, Method: c.Method, Target: c.Target, Passed: true, Skipped: true})
+	}
+	resp.Checks = append(resp.Checks, contract.Run(r.Context(), h.target, checks)...)
+	for _, c := range resp.Checks {
+		if !c.Passed {
+			resp.Passed = false
+			slog.Warn("self-test check failed", "check", c.Name, "error", c.Error)
+		}
+	}
+	resp.Duration = time.Since(start).String()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode selftest response", "error", err)
+	}
+}
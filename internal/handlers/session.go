@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// Session token carriers. The cookie suits cookie-based affinity; the header
+// suits load balancers that hash on a request header.
+const (
+	SessionCookie = "hotpod_session"
+	SessionHeader = "X-Hotpod-Session"
+)
+
+// maxSessionToken bounds accepted session tokens.
+const maxSessionToken = 256
+
+// SessionHandlers issues session tokens naming the pod that issued them, and
+// reports whether later requests carrying a token land on that same pod, to
+// verify sessionAffinity and consistent-hash load balancing.
+type SessionHandlers struct {
+	pod string
+}
+
+// NewSessionHandlers creates handlers for the session endpoint, issuing
+// tokens on behalf of pod.
+func NewSessionHandlers(pod string) *SessionHandlers {
+	return &SessionHandlers{pod: pod}
+}
+
+// Register adds session routes to the mux.
+func (h *SessionHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /session", h.Session)
+}
+
+// Session handles GET /session?reset=true. A token is read from the
+// X-Hotpod-Session header, or else the hotpod_session cookie; without one,
+// or with reset=true, a new token is issued. The token is returned in both
+// the header and the cookie, so the client can replay whichever its load
+// balancer keys on.
+func (h *SessionHandlers) Session(w http.ResponseWriter, r *http.Request) {
+	token, source := r.Header.Get(SessionHeader), "header"
+	if token == "" {
+		if c, err := r.Cookie(SessionCookie); err == nil {
+			token, source = c.Value, "cookie"
+		}
+	}
+	if r.URL.Query().Get("reset") == "true" {
+		token, source = "", ""
+	}
+
+	resp := api.SessionResponse{Pod: h.pod, Source: source}
+	if token == "" {
+		resp.Session = newSessionID()
+		resp.IssuedBy = h.pod
+		resp.New = true
+		resp.SamePod = true
+		token = resp.Session + ":" + h.pod
+	} else {
+		id, issuer, ok := strings.Cut(token, ":")
+		if !ok || id == "" || issuer == "" || len(token) > maxSessionToken {
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "session token must be ID:POD")
+			return
+		}
+		resp.Session = id
+		resp.IssuedBy = issuer
+		resp.SamePod = issuer == h.pod
+	}
+
+	result := "other_pod"
+	switch {
+	case resp.New:
+		result = "new"
+	case resp.SamePod:
+		result = "same_pod"
+	}
+	metrics.SessionRequestsTotal.WithLabelValues(result).Inc()
+
+	w.Header().Set(SessionHeader, token)
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode session response", "error", err)
+	}
+}
+
+// newSessionID returns a random 64-bit session ID.
+func newSessionID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
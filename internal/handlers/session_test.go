@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func sessionRequest(t *testing.T, h *SessionHandlers, req *http.Request) (*httptest.ResponseRecorder, api.SessionResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Session(rec, req)
+	var resp api.SessionResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return rec, resp
+}
+
+func TestSessionCookie(t *testing.T) {
+	podA, podB := NewSessionHandlers("pod-a"), NewSessionHandlers("pod-b")
+
+	rec, first := sessionRequest(t, podA, httptest.NewRequest("GET", "/session", nil))
+	if !first.New || !first.SamePod || first.IssuedBy != "pod-a" || first.Session == "" {
+		t.Fatalf("first response = %+v, want a new session issued by pod-a", first)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != SessionCookie || rec.Header().Get(SessionHeader) != cookies[0].Value {
+		t.Fatalf("cookies = %v, want one session cookie matching the header", cookies)
+	}
+
+	req := httptest.NewRequest("GET", "/session", nil)
+	req.AddCookie(cookies[0])
+	if _, resp := sessionRequest(t, podA, req); resp.New || !resp.SamePod || resp.Session != first.Session || resp.Source != "cookie" {
+		t.Errorf("pod-a response = %+v, want the same session on its issuer", resp)
+	}
+
+	req = httptest.NewRequest("GET", "/session", nil)
+	req.AddCookie(cookies[0])
+	if _, resp := sessionRequest(t, podB, req); resp.SamePod || resp.IssuedBy != "pod-a" || resp.Pod != "pod-b" {
+		t.Errorf("pod-b response = %+v, want a session issued elsewhere", resp)
+	}
+
+	req = httptest.NewRequest("GET", "/session?reset=true", nil)
+	req.AddCookie(cookies[0])
+	if _, resp := sessionRequest(t, podB, req); !resp.New || resp.Session == first.Session || resp.IssuedBy != "pod-b" {
+		t.Errorf("reset response = %+v, want a new session from pod-b", resp)
+	}
+}
+
+func TestSessionInvalidToken(t *testing.T) {
+	req := httptest.NewRequest("GET", "/session", nil)
+	req.Header.Set(SessionHeader, "no-issuer")
+	if rec, _ := sessionRequest(t, NewSessionHandlers("pod-a"), req); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/config"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/sidecar"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// SidecarHandlers exposes and adjusts the sidecar runner at runtime.
+type SidecarHandlers struct {
+	authn  *auth.Authenticator
+	runner *sidecar.Runner
+}
+
+// NewSidecarHandlers creates handlers for the sidecar endpoints. A nil runner
+// means sidecar mode is not enabled.
+func NewSidecarHandlers(authn *auth.Authenticator, runner *sidecar.Runner) *SidecarHandlers {
+	return &SidecarHandlers{authn: authn, runner: runner}
+}
+
+// Register adds sidecar routes to the mux.
+func (h *SidecarHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/sidecar", h.Status)
+	mux.HandleFunc("POST /admin/sidecar", h.Update)
+}
+
+// Status handles GET /admin/sidecar, reporting the runner's current settings.
+// Unlike /admin/config, it reflects changes made through POST /admin/sidecar.
+func (h *SidecarHandlers) Status(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) || !h.enabled(w) {
+		return
+	}
+	h.writeStatus(w)
+}
+
+// Update handles POST /admin/sidecar?cpu_baseline=200m&memory_baseline=100Mi.
+// CPU parameters (cpu_baseline, cpu_jitter) use Kubernetes CPU notation and
+// memory parameters (memory_baseline, memory_jitter, memory_peak) use size
+// notation; memory_ramp is a duration. Omitted parameters keep their current
+// values, so multi-step experiments can change one setting at a time.
+func (h *SidecarHandlers) Update(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) || !h.enabled(w) {
+		return
+	}
+
+	previous := h.runner.Config()
+	cfg, err := parseSidecarConfig(r, previous)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	h.runner.SetConfig(cfg)
+	events.Record(slog.LevelInfo, events.TypeAdmin, "sidecar config updated", map[string]any{
+		"cpu_baseline":    cfg.CPUBaseline.String(),
+		"cpu_jitter":      cfg.CPUJitter.String(),
+		"memory_baseline": formatSize(cfg.MemoryBaseline),
+		"memory_jitter":   formatSize(cfg.MemoryJitter),
+		"memory_peak":     formatSize(cfg.MemoryPeak),
+		"memory_ramp":     cfg.MemoryRamp.String(),
+	})
+	h.writeStatus(w)
+}
+
+func (h *SidecarHandlers) enabled(w http.ResponseWriter) bool {
+	if h.runner == nil {
+		writeError(w, http.StatusNotFound, errcode.SidecarDisabled, "sidecar mode is not enabled")
+		return false
+	}
+	return true
+}
+
+// parseSidecarConfig applies the request's parameters on top of cfg.
+func parseSidecarConfig(r *http.Request, cfg sidecar.Config) (sidecar.Config, error) {
+	var err error
+	if cfg.CPUBaseline, err = parseCPU(r, "cpu_baseline", cfg.CPUBaseline); err != nil {
+		return cfg, err
+	}
+	if cfg.CPUJitter, err = parseCPU(r, "cpu_jitter", cfg.CPUJitter); err != nil {
+		return cfg, err
+	}
+	sizes := []struct {
+		key string
+		dst *int64
+	}{
+		{"memory_baseline", &cfg.MemoryBaseline},
+		{"memory_jitter", &cfg.MemoryJitter},
+		{"memory_peak", &cfg.MemoryPeak},
+	}
+	for _, s := range sizes {
+		if *s.dst, err = parseSize(r, s.key, *s.dst); err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", s.key, err)
+		}
+		if *s.dst < 0 {
+			return cfg, errors.New(s.key + " must be non-negative")
+		}
+	}
+	if cfg.MemoryRamp, err = parseDuration(r, "memory_ramp", cfg.MemoryRamp); err != nil {
+		return cfg, fmt.Errorf("invalid memory_ramp: %w", err)
+	}
+
+	switch {
+	case cfg.CPUBaseline < 0 || cfg.CPUBaseline > time.Second:
+		return cfg, errors.New("cpu_baseline must be between 0 and 1000m")
+	case cfg.CPUJitter < 0:
+		return cfg, errors.New("cpu_jitter must be non-negative")
+	case cfg.MemoryPeak > 0 && cfg.MemoryRamp <= 0:
+		return cfg, errors.New("memory_ramp must be positive when memory_peak is set")
+	}
+	return cfg, nil
+}
+
+// parseCPU parses a Kubernetes CPU quantity (e.g. 200m) from a query parameter.
+func parseCPU(r *http.Request, key string, defaultVal time.Duration) (time.Duration, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return defaultVal, nil
+	}
+	d, err := config.ParseCPU(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func (h *SidecarHandlers) writeStatus(w http.ResponseWriter) {
+	cfg := h.runner.Config()
+	resp := api.AdminSidecarResponse{
+		CPUBaseline:    cfg.CPUBaseline.String(),
+		CPUJitter:      cfg.CPUJitter.String(),
+		MemoryBaseline: formatSize(cfg.MemoryBaseline),
+		MemoryHeld:     formatSize(h.runner.HeldMemory()),
+	}
+	if cfg.MemoryJitter > 0 {
+		resp.MemoryJitter = formatSize(cfg.MemoryJitter)
+	}
+	if cfg.MemoryPeak > 0 {
+		resp.MemoryPeak = formatSize(cfg.MemoryPeak)
+		resp.MemoryRamp = cfg.MemoryRamp.String()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode sidecar response", "error", err)
+	}
+}
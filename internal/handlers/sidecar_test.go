@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/sidecar"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestSidecarUpdate(t *testing.T) {
+	runner := sidecar.New(sidecar.Config{CPUBaseline: 100 * time.Millisecond, CPUJitter: 10 * time.Millisecond, MemoryBaseline: 1 << 20})
+	mux := http.NewServeMux()
+	NewSidecarHandlers(auth.New("", nil), runner).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/sidecar?cpu_baseline=250m&memory_peak=4Mi&memory_ramp=1m", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp api.AdminSidecarResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.CPUBaseline != "250ms" || resp.CPUJitter != "10ms" || resp.MemoryPeak != "4.0MB" || resp.MemoryRamp != "1m0s" {
+		t.Errorf("response = %+v", resp)
+	}
+
+	want := sidecar.Config{CPUBaseline: 250 * time.Millisecond, CPUJitter: 10 * time.Millisecond, MemoryBaseline: 1 << 20, MemoryPeak: 4 << 20, MemoryRamp: time.Minute}
+	if got := runner.Config(); got != want {
+		t.Errorf("runner config = %+v, want %+v", got, want)
+	}
+}
+
+func TestSidecarUpdateInvalid(t *testing.T) {
+	runner := sidecar.New(sidecar.Config{})
+	mux := http.NewServeMux()
+	NewSidecarHandlers(auth.New("", nil), runner).Register(mux)
+
+	for _, q := range []string{
+		"cpu_baseline=2",
+		"cpu_baseline=x",
+		"cpu_jitter=-10m",
+		"memory_baseline=lots",
+		"memory_peak=10Mi&memory_ramp=0s",
+		"memory_ramp=soon",
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/sidecar?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+	if got := runner.Config(); got != (sidecar.Config{}) {
+		t.Errorf("runner config changed by invalid requests: %+v", got)
+	}
+}
+
+func TestSidecarDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	NewSidecarHandlers(auth.New("", nil), nil).Register(mux)
+
+	for _, method := range []string{"GET", "POST"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, "/admin/sidecar", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s status = %d, want 404", method, rec.Code)
+		}
+	}
+}
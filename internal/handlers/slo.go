@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/slo"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// SLOHandlers reports hotpod's own traffic against its SLO objectives.
+type SLOHandlers struct {
+	authn   *auth.Authenticator
+	tracker *slo.Tracker
+}
+
+// NewSLOHandlers creates handlers for the SLO admin endpoint. tracker is nil
+// when no objective is configured.
+func NewSLOHandlers(authn *auth.Authenticator, tracker *slo.Tracker) *SLOHandlers {
+	return &SLOHandlers{authn: authn, tracker: tracker}
+}
+
+// Register adds SLO routes to the mux.
+func (h *SLOHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/slo", h.Get)
+}
+
+// Get handles GET /admin/slo, reporting each SLI's error budget remaining
+// over the SLO period and its burn rate over each window.
+func (h *SLOHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	if h.tracker == nil {
+		writeError(w, http.StatusNotFound, errcode.SLODisabled, "no SLO objective is configured")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.tracker.Status(time.Now())); err != nil {
+		slog.Warn("failed to encode SLO response", "error", err)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/slo"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestSLOHandlersDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	NewSLOHandlers(auth.New("", nil), nil).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/slo", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "SLO_DISABLED") {
+		t.Errorf("status = %d, body = %s, want 404 SLO_DISABLED", rec.Code, rec.Body)
+	}
+}
+
+func TestSLOHandlers(t *testing.T) {
+	tracker := slo.New(slo.Config{Availability: 0.99, Period: time.Hour, Windows: []time.Duration{5 * time.Minute}})
+	tracker.Record(time.Now(), 200, time.Millisecond)
+	tracker.Record(time.Now(), 500, time.Millisecond)
+
+	mux := http.NewServeMux()
+	NewSLOHandlers(auth.New("", nil), tracker).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/slo", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp api.SLOStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Period != "1h0m0s" || len(resp.SLIs) != 1 {
+		t.Fatalf("response = %+v, want one SLI over 1h", resp)
+	}
+	if s := resp.SLIs[0]; s.Name != "availability" || s.Total != 2 || s.Bad != 1 || s.BurnRates["5m0s"] <= 1 {
+		t.Errorf("SLI = %+v, want 1 bad of 2 burning faster than 1x", s)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// defaultStormDuration is how long a storm lasts when no duration is given.
+const defaultStormDuration = time.Minute
+
+// StormHandlers starts and stops 503 storms.
+type StormHandlers struct {
+	enabled bool
+	authn   *auth.Authenticator
+	guard   *fault.Guard
+	storm   *fault.Storm
+}
+
+// NewStormHandlers creates handlers for the 503 storm endpoints. A nil guard
+// applies no guardrails.
+func NewStormHandlers(enabled bool, authn *auth.Authenticator, guard *fault.Guard, storm *fault.Storm) *StormHandlers {
+	return &StormHandlers{enabled: enabled, authn: authn, guard: guard, storm: storm}
+}
+
+// Register adds storm routes to the mux.
+func (h *StormHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /fault/storm", h.Start)
+	mux.HandleFunc("GET /fault/storm", h.Status)
+	mux.HandleFunc("DELETE /fault/storm", h.Stop)
+}
+
+// Start handles POST
+// /fault/storm?rate=R&retry_after=D[,D...]&http_date=true&duration=D,
+// failing rate of all data-plane requests (default: all) with 503 for
+// duration (default: 1m). Each response carries a Retry-After picked at
+// random from retry_after, in seconds or as an HTTP date.
+func (h *StormHandlers) Start(w http.ResponseWriter, r *http.Request) {
+	if !chaosAllowed(h.enabled, h.authn, w, r) {
+		return
+	}
+
+	var cfg fault.StormConfig
+	var err error
+	if cfg.Rate, err = parseFloat(r, "rate", 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if cfg.Duration, err = parseDuration(r, "duration", defaultStormDuration); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if v := r.URL.Query().Get("retry_after"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			d, err := time.ParseDuration(strings.TrimSpace(s))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "retry_after must be a comma-separated list of durations")
+				return
+			}
+			cfg.RetryAfter = append(cfg.RetryAfter, d)
+		}
+	}
+	cfg.HTTPDate = r.URL.Query().Get("http_date") == "true"
+	if err := cfg.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	if !admitChaos(h.guard, w, "storm") {
+		return
+	}
+	if err := h.storm.Start(cfg, time.Now()); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	h.writeStatus(w)
+}
+
+// Status handles GET /fault/storm.
+func (h *StormHandlers) Status(w http.ResponseWriter, r *http.Request) {
+	if !chaosAllowed(h.enabled, h.authn, w, r) {
+		return
+	}
+	h.writeStatus(w)
+}
+
+// Stop handles DELETE /fault/storm, ending the storm early.
+func (h *StormHandlers) Stop(w http.ResponseWriter, r *http.Request) {
+	if !chaosAllowed(h.enabled, h.authn, w, r) {
+		return
+	}
+	h.storm.Stop()
+	h.writeStatus(w)
+}
+
+func (h *StormHandlers) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.storm.Status(time.Now())); err != nil {
+		slog.Warn("failed to encode storm response", "error", err)
+	}
+}
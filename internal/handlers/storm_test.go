@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestStormHandlersDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	NewStormHandlers(false, auth.New("", nil), nil, fault.NewStorm()).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/fault/storm", nil))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "CHAOS_DISABLED") {
+		t.Errorf("status = %d, body = %s, want 403 CHAOS_DISABLED", rec.Code, rec.Body)
+	}
+}
+
+func TestStormHandlers(t *testing.T) {
+	mux := http.NewServeMux()
+	NewStormHandlers(true, auth.New("", nil), nil, fault.NewStorm()).Register(mux)
+
+	for _, tt := range []struct {
+		method     string
+		query      string
+		wantStatus int
+		wantActive bool
+	}{
+		{"GET", "", http.StatusOK, false},
+		{"POST", "?rate=2", http.StatusBadRequest, false},
+		{"POST", "?retry_after=soon", http.StatusBadRequest, false},
+		{"POST", "?duration=2h", http.StatusBadRequest, false},
+		{"POST", "?rate=0.5&retry_after=1s,5s&http_date=true&duration=30s", http.StatusAccepted, true},
+		{"GET", "", http.StatusOK, true},
+		{"DELETE", "", http.StatusOK, false},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, "/fault/storm"+tt.query, nil))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.query, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if rec.Code == http.StatusBadRequest {
+			continue
+		}
+		var resp api.StormStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Active != tt.wantActive {
+			t.Errorf("%s %s: active = %t, want %t", tt.method, tt.query, resp.Active, tt.wantActive)
+		}
+		if tt.method == "POST" && (resp.Rate != 0.5 || !resp.HTTPDate || len(resp.RetryAfter) != 2) {
+			t.Errorf("POST: response = %+v, want rate 0.5 with 2 Retry-After values as dates", resp)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// TLSFaultHandlers reads and sets the TLS handshake faults of the main
+// listener.
+type TLSFaultHandlers struct {
+	authn  *auth.Authenticator
+	faults *fault.TLSFaults
+}
+
+// NewTLSFaultHandlers creates handlers for the TLS fault admin endpoints.
+// faults is nil when TLS is disabled.
+func NewTLSFaultHandlers(authn *auth.Authenticator, faults *fault.TLSFaults) *TLSFaultHandlers {
+	return &TLSFaultHandlers{authn: authn, faults: faults}
+}
+
+// Register adds TLS fault routes to the mux.
+func (h *TLSFaultHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/tls-faults", h.Get)
+	mux.HandleFunc("POST /admin/tls-faults", h.Set)
+	mux.HandleFunc("DELETE /admin/tls-faults", h.Clear)
+}
+
+func (h *TLSFaultHandlers) checkEnabled(w http.ResponseWriter) bool {
+	if h.faults == nil {
+		writeError(w, http.StatusForbidden, errcode.TLSDisabled, "TLS is not enabled")
+		return false
+	}
+	return true
+}
+
+// Get handles GET /admin/tls-faults.
+func (h *TLSFaultHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) || !h.checkEnabled(w) {
+		return
+	}
+	h.writeFaults(w)
+}
+
+// Set handles POST
+// /admin/tls-faults?delay=D&delay_rate=R&fail_rate=R&cert=expired|wrong-san,
+// replacing the handshake faults; omitted parameters are zero.
+func (h *TLSFaultHandlers) Set(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) || !h.checkEnabled(w) {
+		return
+	}
+
+	var cfg fault.TLSConfig
+	var err error
+	if cfg.Delay, err = parseDuration(r, "delay", 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if cfg.DelayRate, err = parseFloat(r, "delay_rate", 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	if cfg.FailRate, err = parseFloat(r, "fail_rate", 0); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+	cfg.Cert = r.URL.Query().Get("cert")
+	if err := h.faults.Set(cfg); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	slog.Warn("TLS faults set", "delay", cfg.Delay, "delay_rate", cfg.DelayRate, "fail_rate", cfg.FailRate, "cert", cfg.Cert)
+	events.Record(slog.LevelWarn, events.TypeFault, "TLS faults set", map[string]any{
+		"delay":      cfg.Delay.String(),
+		"delay_rate": cfg.DelayRate,
+		"fail_rate":  cfg.FailRate,
+		"cert":       cfg.Cert,
+	})
+	h.writeFaults(w)
+}
+
+// Clear handles DELETE /admin/tls-faults, disabling all handshake faults.
+func (h *TLSFaultHandlers) Clear(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) || !h.checkEnabled(w) {
+		return
+	}
+
+	h.faults.Set(fault.TLSConfig{})
+	slog.Info("TLS faults cleared")
+	events.Record(slog.LevelInfo, events.TypeFault, "TLS faults cleared", nil)
+	h.writeFaults(w)
+}
+
+func (h *TLSFaultHandlers) writeFaults(w http.ResponseWriter) {
+	cfg := h.faults.Get()
+	resp := api.AdminTLSFaultsResponse{
+		DelayRate: cfg.DelayRate,
+		FailRate:  cfg.FailRate,
+		Cert:      cfg.Cert,
+	}
+	if cfg.Delay > 0 {
+		resp.Delay = cfg.Delay.String()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode TLS faults response", "error", err)
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestTLSFaultHandlersDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	NewTLSFaultHandlers(auth.New("", nil), nil).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/tls-faults?fail_rate=1", nil))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "TLS_DISABLED") {
+		t.Errorf("status = %d, body = %s, want 403 TLS_DISABLED", rec.Code, rec.Body)
+	}
+}
+
+func TestTLSFaultHandlers(t *testing.T) {
+	now := time.Now()
+	cert, err := fault.SelfSignedCertificate([]string{"localhost"}, now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	faults := fault.NewTLSFaults(cert)
+	mux := http.NewServeMux()
+	NewTLSFaultHandlers(auth.New("", nil), faults).Register(mux)
+
+	for _, tt := range []struct {
+		method     string
+		path       string
+		wantStatus int
+		want       api.AdminTLSFaultsResponse
+	}{
+		{"GET", "/admin/tls-faults", http.StatusOK, api.AdminTLSFaultsResponse{}},
+		{"POST", "/admin/tls-faults?delay=2s&delay_rate=0.5&cert=wrong-san", http.StatusOK, api.AdminTLSFaultsResponse{Delay: "2s", DelayRate: 0.5, Cert: "wrong-san"}},
+		{"POST", "/admin/tls-faults?fail_rate=2", http.StatusBadRequest, api.AdminTLSFaultsResponse{}},
+		{"POST", "/admin/tls-faults?cert=revoked", http.StatusBadRequest, api.AdminTLSFaultsResponse{}},
+		{"POST", "/admin/tls-faults?fail_rate=0.25", http.StatusOK, api.AdminTLSFaultsResponse{FailRate: 0.25}},
+		{"DELETE", "/admin/tls-faults", http.StatusOK, api.AdminTLSFaultsResponse{}},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.path, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp api.AdminTLSFaultsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp != tt.want {
+			t.Errorf("%s %s: response = %+v, want %+v", tt.method, tt.path, resp, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	_ "embed"
+	"log/slog"
+	"net/http"
+)
+
+//go:embed ui/index.html
+var uiIndex []byte
+
+// UIHandlers serves the embedded web dashboard. The page only calls the
+// public API, so admin actions still require a token when auth is enabled.
+type UIHandlers struct{}
+
+// NewUIHandlers creates handlers for the web dashboard.
+func NewUIHandlers() *UIHandlers {
+	return &UIHandlers{}
+}
+
+// Register adds UI routes to the mux.
+func (h *UIHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /ui", h.Index)
+	mux.HandleFunc("GET /ui/{$}", h.Index)
+}
+
+// Index serves the dashboard page.
+func (h *UIHandlers) Index(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+	if _, err := w.Write(uiIndex); err != nil {
+		slog.Warn("failed to write UI", "error", err)
+	}
+}
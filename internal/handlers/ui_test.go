@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUIIndex(t *testing.T) {
+	mux := http.NewServeMux()
+	NewUIHandlers().Register(mux)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/ui", http.StatusOK},
+		{"/ui/", http.StatusOK},
+		{"/ui/other", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+				t.Errorf("Content-Type = %q, want text/html", ct)
+			}
+			if !strings.Contains(rec.Body.String(), "<title>hotpod</title>") {
+				t.Error("body does not contain the dashboard page")
+			}
+		})
+	}
+}
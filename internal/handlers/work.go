@@ -1,70 +1,318 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"math/rand/v2"
 	"net/http"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ripta/hotpod/internal/config"
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/internal/topology"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
+// builtinWorkProfiles are always defined, though they may be redefined.
+var builtinWorkProfiles = []api.WorkProfile{
+	{Name: "web", CPU: "20ms", Cores: 1, Intensity: intensityMedium, Memory: "5Mi", Latency: "50ms"},
+	{Name: "api", CPU: "50ms", Cores: 1, Intensity: intensityMedium, Memory: "2Mi", Latency: "20ms"},
+	{Name: "worker", CPU: "200ms", Cores: 2, Intensity: intensityHigh, Memory: "50Mi", Latency: "100ms"},
+	{Name: "heavy", CPU: "500ms", Cores: 4, Intensity: intensityHigh, Memory: "100Mi", Latency: "10ms"},
+}
+
+// maxWorkProfiles bounds the number of defined profiles.
+const maxWorkProfiles = 100
+
+var workProfileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
 // workProfile defines the parameters for a composite workload.
 type workProfile struct {
+	spec        api.WorkProfile
 	cpuDuration time.Duration
 	cpuCores    int
 	intensity   string
 	memorySize  int64
 	latency     time.Duration
+	ioSize      int64
+	// downstreamURL is the resolved target of the outbound call, if any
+	downstreamURL string
 }
 
-var workProfiles = map[string]workProfile{
-	"web": {
-		cpuDuration: 20 * time.Millisecond,
-		cpuCores:    1,
-		intensity:   intensityMedium,
-		memorySize:  5 << 20, // 5MB
-		latency:     50 * time.Millisecond,
-	},
-	"api": {
-		cpuDuration: 50 * time.Millisecond,
-		cpuCores:    1,
-		intensity:   intensityMedium,
-		memorySize:  2 << 20, // 2MB
-		latency:     20 * time.Millisecond,
-	},
-	"worker": {
-		cpuDuration: 200 * time.Millisecond,
-		cpuCores:    2,
-		intensity:   intensityHigh,
-		memorySize:  50 << 20, // 50MB
-		latency:     100 * time.Millisecond,
-	},
-	"heavy": {
-		cpuDuration: 500 * time.Millisecond,
-		cpuCores:    4,
-		intensity:   intensityHigh,
-		memorySize:  100 << 20, // 100MB
-		latency:     10 * time.Millisecond,
-	},
+// WorkProfiles holds the /work profiles: the built-in ones plus any defined
+// through configuration or POST /admin/profiles.
+type WorkProfiles struct {
+	ioDir     string
+	maxIOSize int64
+	// selfURL is this pod's base URL for self-calls (empty = unavailable)
+	selfURL string
+	client  *http.Client
+
+	mu       sync.RWMutex
+	profiles map[string]workProfile
+}
+
+// NewWorkProfiles creates a set of the built-in profiles. Profile I/O goes to
+// ioDir and is limited to maxIOSize bytes when positive. Downstream self-calls
+// go to selfURL, and profiles may not make them if it is empty. An https
+// selfURL is not verified, since the local certificate is usually self-signed.
+func NewWorkProfiles(ioDir string, maxIOSize int64, selfURL string) *WorkProfiles {
+	client := &http.Client{Timeout: downstreamTimeout}
+	if strings.HasPrefix(selfURL, "https://") {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	p := &WorkProfiles{
+		ioDir:     ioDir,
+		maxIOSize: maxIOSize,
+		selfURL:   selfURL,
+		client:    client,
+		profiles:  map[string]workProfile{},
+	}
+	for _, spec := range builtinWorkProfiles {
+		if err := p.Define(spec); err != nil {
+			panic(fmt.Sprintf("invalid built-in work profile %q: %v", spec.Name, err))
+		}
+	}
+	return p
+}
+
+// ParseWorkProfiles decodes a JSON array of profiles, as given in
+// HOTPOD_WORK_PROFILES or HOTPOD_WORK_PROFILES_FILE.
+func ParseWorkProfiles(data []byte) ([]api.WorkProfile, error) {
+	var specs []api.WorkProfile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&specs); err != nil {
+		return nil, fmt.Errorf("work profiles must be a JSON array: %w", err)
+	}
+	return specs, nil
+}
+
+// Names returns the sorted profile names.
+func (p *WorkProfiles) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.profiles))
+	for name := range p.profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// List returns every profile, sorted by name.
+func (p *WorkProfiles) List() []api.WorkProfile {
+	names := p.Names()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	specs := make([]api.WorkProfile, 0, len(names))
+	for _, name := range names {
+		if profile, ok := p.profiles[name]; ok {
+			specs = append(specs, profile.spec)
+		}
+	}
+	return specs
+}
+
+// Define adds or replaces a profile, including a built-in one.
+func (p *WorkProfiles) Define(spec api.WorkProfile) error {
+	profile, err := p.parse(spec)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.profiles[spec.Name]; !ok && len(p.profiles) >= maxWorkProfiles {
+		return fmt.Errorf("at most %d profiles may be defined", maxWorkProfiles)
+	}
+	p.profiles[spec.Name] = profile
+	return nil
+}
+
+// Delete removes a custom profile, or restores a redefined built-in profile
+// to its default. It reports false if the profile does not exist.
+func (p *WorkProfiles) Delete(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.profiles[name]; !ok {
+		return false
+	}
+	delete(p.profiles, name)
+	for _, spec := range builtinWorkProfiles {
+		if spec.Name == name {
+			// Built-ins were validated by NewWorkProfiles.
+			p.profiles[name], _ = p.parse(spec)
+		}
+	}
+	return true
+}
+
+// parse validates spec and fills in defaults.
+func (p *WorkProfiles) parse(spec api.WorkProfile) (workProfile, error) {
+	if !workProfileName.MatchString(spec.Name) {
+		return workProfile{}, errors.New("name must be 1-63 lowercase letters, digits, hyphens, or underscores")
+	}
+
+	if spec.Cores == 0 {
+		spec.Cores = 1
+	}
+	if spec.Intensity == "" {
+		spec.Intensity = intensityMedium
+	}
+	spec.Builtin = false
+	spec.Builtin = slices.Contains(builtinWorkProfiles, spec)
+
+	profile := workProfile{spec: spec, cpuCores: spec.Cores, intensity: spec.Intensity}
+	if spec.Cores < 1 || spec.Cores > 64 {
+		return profile, errors.New("cores must be between 1 and 64")
+	}
+	if !validIntensity(spec.Intensity) {
+		return profile, errInvalidIntensity
+	}
+
+	durations := []struct {
+		key   string
+		value string
+		dst   *time.Duration
+	}{
+		{"cpu", spec.CPU, &profile.cpuDuration},
+		{"latency", spec.Latency, &profile.latency},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return profile, fmt.Errorf("invalid %s: %w", d.key, err)
+		}
+		if v < 0 {
+			return profile, fmt.Errorf("%s must be non-negative", d.key)
+		}
+		*d.dst = v
+	}
+
+	sizes := []struct {
+		key   string
+		value string
+		dst   *int64
+	}{
+		{"memory", spec.Memory, &profile.memorySize},
+		{"io", spec.IO, &profile.ioSize},
+	}
+	for _, s := range sizes {
+		if s.value == "" {
+			continue
+		}
+		v, err := config.ParseSize(s.value)
+		if err != nil {
+			return profile, fmt.Errorf("invalid %s: %w", s.key, err)
+		}
+		*s.dst = v
+	}
+	if p.maxIOSize > 0 && profile.ioSize > p.maxIOSize {
+		return profile, fmt.Errorf("io must not exceed %s", formatSize(p.maxIOSize))
+	}
+
+	if err := p.parseDownstream(&profile); err != nil {
+		return profile, err
+	}
+
+	return profile, nil
+}
+
+// lookup returns the named profile, checking the variance.
+func (p *WorkProfiles) lookup(profileName string, variance float64) (workProfile, error) {
+	p.mu.RLock()
+	profile, ok := p.profiles[profileName]
+	p.mu.RUnlock()
+	if !ok {
+		return workProfile{}, fmt.Errorf("profile must be one of: %s", strings.Join(p.Names(), ", "))
+	}
+	if variance < 0 || variance > 1 {
+		return workProfile{}, errors.New("variance must be between 0 and 1")
+	}
+	return profile, nil
+}
+
+// Run runs one iteration of the named work profile, varying its parameters by
+// up to variance (0 to 1). CPU time and memory are capped at maxCPU and
+// maxMemory when positive. It backs GET /work and the one-shot hotpod run
+// command.
+func (p *WorkProfiles) Run(ctx context.Context, profileName string, variance float64, maxCPU time.Duration, maxMemory int64) (*api.WorkResponse, error) {
+	profile, err := p.lookup(profileName, variance)
+	if err != nil {
+		return nil, err
+	}
+
+	cpuDuration := applyVariance(profile.cpuDuration, variance)
+	memorySize := applyVarianceInt64(profile.memorySize, variance)
+	latency := applyVariance(profile.latency, variance)
+
+	limitsApplied := false
+	if maxCPU > 0 && cpuDuration > maxCPU {
+		cpuDuration = maxCPU
+		limitsApplied = true
+	}
+	if maxMemory > 0 && memorySize > maxMemory {
+		memorySize = maxMemory
+		limitsApplied = true
+	}
+
+	start := time.Now()
+	result := runWorkload(ctx, profile, cpuDuration, memorySize, latency, p.ioDir, p.client)
+	elapsed := time.Since(start)
+
+	return &api.WorkResponse{
+		Profile:         profileName,
+		Variance:        variance,
+		ActualDuration:  elapsed.String(),
+		CPUDuration:     cpuDuration.String(),
+		CPUIterations:   result.cpuIterations,
+		MemorySize:      memorySize,
+		MemorySizeHuman: formatSize(memorySize),
+		Latency:         latency.String(),
+		IOBytes:         result.ioBytes,
+		Downstream:      result.downstream,
+		Cancelled:       result.cancelled,
+		LimitsApplied:   limitsApplied,
+		Topology:        topology.Current(),
+	}, nil
 }
 
 // WorkHandlers provides the /work endpoint handler.
 type WorkHandlers struct {
 	tracker       *load.Tracker
+	profiles      *WorkProfiles
 	maxCPUDur     time.Duration
 	maxMemorySize int64
 }
 
-// NewWorkHandlers creates handlers for composite work endpoints.
+// NewWorkHandlers creates handlers for composite work endpoints, starting
+// with the built-in profiles.
 func NewWorkHandlers(tracker *load.Tracker, cfg *config.Config) *WorkHandlers {
 	return &WorkHandlers{
 		tracker:       tracker,
+		profiles:      NewWorkProfiles(cfg.IOPath(), cfg.MaxIOSize, cfg.LocalURL()),
 		maxCPUDur:     cfg.MaxCPUDuration,
 		maxMemorySize: cfg.MaxMemorySize,
 	}
@@ -75,93 +323,45 @@ func (h *WorkHandlers) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /work", h.Work)
 }
 
-// WorkResponse is the JSON response for /work.
-type WorkResponse struct {
-	// Profile is the workload profile used
-	Profile string `json:"profile"`
-	// Variance is the variance multiplier applied
-	Variance float64 `json:"variance"`
-	// ActualDuration is the total time for the composite workload
-	ActualDuration string `json:"actual_duration"`
-	// CPUDuration is how long CPU work ran
-	CPUDuration string `json:"cpu_duration"`
-	// CPUIterations is the number of CPU work iterations
-	CPUIterations int64 `json:"cpu_iterations"`
-	// MemorySize is the amount of memory allocated
-	MemorySize int64 `json:"memory_size"`
-	// MemorySizeHuman is the human-readable memory size
-	MemorySizeHuman string `json:"memory_size_human"`
-	// Latency is the simulated latency duration
-	Latency string `json:"latency"`
-	// Cancelled indicates if the operation was cancelled
-	Cancelled bool `json:"cancelled,omitempty"`
-	// LimitsApplied indicates if any limits were applied
-	LimitsApplied bool `json:"limits_applied,omitempty"`
+// Profiles returns the work profiles for admin operations.
+func (h *WorkHandlers) Profiles() *WorkProfiles {
+	return h.profiles
 }
 
+// Work handles GET /work?profile=web&variance=0.2, running one iteration of a
+// composite workload.
 func (h *WorkHandlers) Work(w http.ResponseWriter, r *http.Request) {
 	profileName := r.URL.Query().Get("profile")
 	if profileName == "" {
 		profileName = "web"
 	}
 
-	profile, ok := workProfiles[profileName]
-	if !ok {
-		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "profile must be web, api, worker, or heavy")
-		return
-	}
-
 	varianceStr := r.URL.Query().Get("variance")
 	variance := 0.0
 	if varianceStr != "" {
 		var err error
 		variance, err = strconv.ParseFloat(varianceStr, 64)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "variance must be a number")
-			return
-		}
-		if variance < 0 || variance > 1 {
-			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "variance must be between 0 and 1")
+			writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "variance must be a number")
 			return
 		}
 	}
-
-	cpuDuration := applyVariance(profile.cpuDuration, variance)
-	memorySize := applyVarianceInt64(profile.memorySize, variance)
-	latency := applyVariance(profile.latency, variance)
-
-	limitsApplied := false
-	if h.maxCPUDur > 0 && cpuDuration > h.maxCPUDur {
-		cpuDuration = h.maxCPUDur
-		limitsApplied = true
-	}
-	if h.maxMemorySize > 0 && memorySize > h.maxMemorySize {
-		memorySize = h.maxMemorySize
-		limitsApplied = true
+	if _, err := h.profiles.lookup(profileName, variance); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
 	}
 
-	release, err := h.tracker.Acquire(load.OpTypeWork)
+	release, err := h.tracker.AcquireContext(r.Context(), load.OpTypeWork)
 	if err != nil {
-		writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "concurrent operation limit exceeded")
+		writeError(w, http.StatusTooManyRequests, errcode.TooManyRequests, "concurrent operation limit exceeded")
 		return
 	}
 	defer release()
 
-	start := time.Now()
-	cpuIterations, cancelled := h.runWorkload(r.Context(), cpuDuration, profile.cpuCores, profile.intensity, memorySize, latency)
-	elapsed := time.Since(start)
-
-	resp := WorkResponse{
-		Profile:         profileName,
-		Variance:        variance,
-		ActualDuration:  elapsed.String(),
-		CPUDuration:     cpuDuration.String(),
-		CPUIterations:   cpuIterations,
-		MemorySize:      memorySize,
-		MemorySizeHuman: formatSize(memorySize),
-		Latency:         latency.String(),
-		Cancelled:       cancelled,
-		LimitsApplied:   limitsApplied,
+	resp, err := h.profiles.Run(r.Context(), profileName, variance, h.maxCPUDur, h.maxMemorySize)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -170,15 +370,26 @@ func (h *WorkHandlers) Work(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *WorkHandlers) runWorkload(ctx context.Context, cpuDuration time.Duration, cpuCores int, intensity string, memorySize int64, latency time.Duration) (cpuIterations int64, cancelled bool) {
+// workResult is the outcome of one composite workload.
+type workResult struct {
+	cpuIterations int64
+	ioBytes       int64
+	downstream    *api.WorkDownstream
+	cancelled     bool
+}
+
+// runWorkload runs the profile's components concurrently: CPU burn, memory
+// held for the CPU duration, latency, I/O, and the downstream call.
+func runWorkload(ctx context.Context, profile workProfile, cpuDuration time.Duration, memorySize int64, latency time.Duration, ioDir string, client *http.Client) workResult {
 	var wg sync.WaitGroup
-	var cpuCancelled, memCancelled, sleepCancelled bool
+	var result workResult
+	var cpuCancelled, memCancelled, sleepCancelled, ioCancelled bool
 
 	wg.Add(3)
 
 	go func() {
 		defer wg.Done()
-		cpuIterations, cpuCancelled = burnCPU(ctx, cpuDuration, cpuCores, intensity)
+		result.cpuIterations, cpuCancelled = burnCPU(ctx, cpuDuration, profile.cpuCores, profile.intensity)
 	}()
 
 	go func() {
@@ -191,10 +402,27 @@ func (h *WorkHandlers) runWorkload(ctx context.Context, cpuDuration time.Duratio
 		sleepCancelled = sleep(ctx, latency)
 	}()
 
+	if profile.ioSize > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			written, read, cancelled := performIO(ctx, ioDir, profile.ioSize, ioOpRead, false)
+			result.ioBytes, ioCancelled = written+read, cancelled
+		}()
+	}
+
+	if profile.downstreamURL != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result.downstream = callDownstream(ctx, client, profile.downstreamURL)
+		}()
+	}
+
 	wg.Wait()
 
-	cancelled = cpuCancelled || memCancelled || sleepCancelled
-	return cpuIterations, cancelled
+	result.cancelled = cpuCancelled || memCancelled || sleepCancelled || ioCancelled
+	return result
 }
 
 // applyVariance applies a random variance multiplier to a duration.
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ripta/hotpod/internal/load"
+	"github.com/ripta/hotpod/pkg/api"
 )
 
 func TestWorkDefault(t *testing.T) {
@@ -24,7 +26,7 @@ func TestWorkDefault(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp WorkResponse
+	var resp api.WorkResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -54,7 +56,7 @@ func TestWorkProfiles(t *testing.T) {
 			t.Errorf("profile=%s: status = %d, want %d", profile, rec.Code, http.StatusOK)
 		}
 
-		var resp WorkResponse
+		var resp api.WorkResponse
 		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 			t.Fatalf("profile=%s: failed to parse response: %v", profile, err)
 		}
@@ -77,7 +79,7 @@ func TestWorkWithVariance(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp WorkResponse
+	var resp api.WorkResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -173,7 +175,7 @@ func TestWorkCancellation(t *testing.T) {
 		t.Error("handler did not return after cancellation")
 	}
 
-	var resp WorkResponse
+	var resp api.WorkResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -198,7 +200,7 @@ func TestWorkLimitsApplied(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	var resp WorkResponse
+	var resp api.WorkResponse
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("failed to parse response: %v", err)
 	}
@@ -258,3 +260,113 @@ func TestApplyVarianceInt64(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkCustomProfile(t *testing.T) {
+	h := NewWorkHandlers(load.NewTracker(100), testConfig())
+	if err := h.Profiles().Define(api.WorkProfile{Name: "checkout", CPU: "5ms", Cores: 2, Memory: "1Mi", IO: "64Ki"}); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.Work(rec, httptest.NewRequest("GET", "/work?profile=checkout", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	var resp api.WorkResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Profile != "checkout" || resp.MemorySize != 1<<20 || resp.IOBytes != 2*64<<10 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestWorkProfileDefineValidation(t *testing.T) {
+	profiles := NewWorkProfiles(t.TempDir(), 0, "")
+
+	for _, tt := range []struct {
+		name string
+		spec api.WorkProfile
+	}{
+		{"empty name", api.WorkProfile{}},
+		{"bad cpu", api.WorkProfile{Name: "a", CPU: "fast"}},
+		{"negative latency", api.WorkProfile{Name: "a", Latency: "-1s"}},
+		{"too many cores", api.WorkProfile{Name: "a", Cores: 65}},
+		{"bad intensity", api.WorkProfile{Name: "a", Intensity: "extreme"}},
+		{"bad memory", api.WorkProfile{Name: "a", Memory: "lots"}},
+	} {
+		if err := profiles.Define(tt.spec); err == nil {
+			t.Errorf("%s: Define() error = nil, want error", tt.name)
+		}
+	}
+
+	if err := profiles.Define(api.WorkProfile{Name: "minimal"}); err != nil {
+		t.Fatalf("Define(minimal) error = %v", err)
+	}
+	names := strings.Join(profiles.Names(), ",")
+	if names != "api,heavy,minimal,web,worker" {
+		t.Errorf("Names() = %s", names)
+	}
+}
+
+func TestWorkDownstream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer upstream.Close()
+
+	self := http.NewServeMux()
+	NewLatencyHandlers(load.NewTracker(10)).Register(self)
+	selfServer := httptest.NewServer(self)
+	defer selfServer.Close()
+
+	profiles := NewWorkProfiles(t.TempDir(), 0, selfServer.URL)
+	for _, tt := range []struct {
+		spec        api.WorkProfile
+		wantStatus  int
+		minDuration time.Duration
+	}{
+		{api.WorkProfile{Name: "external", Downstream: upstream.URL + "/ok"}, http.StatusOK, 0},
+		{api.WorkProfile{Name: "failing", Downstream: upstream.URL + "/fail"}, http.StatusServiceUnavailable, 0},
+		{api.WorkProfile{Name: "self", Downstream: "self", DownstreamLatency: "30ms"}, http.StatusOK, 30 * time.Millisecond},
+	} {
+		if err := profiles.Define(tt.spec); err != nil {
+			t.Fatalf("%s: Define() error = %v", tt.spec.Name, err)
+		}
+		resp, err := profiles.Run(context.Background(), tt.spec.Name, 0, 0, 0)
+		if err != nil {
+			t.Fatalf("%s: Run() error = %v", tt.spec.Name, err)
+		}
+		if resp.Downstream == nil || resp.Downstream.Status != tt.wantStatus || resp.Downstream.Error != "" {
+			t.Fatalf("%s: downstream = %+v, want status %d", tt.spec.Name, resp.Downstream, tt.wantStatus)
+		}
+		if d, _ := time.ParseDuration(resp.Downstream.Duration); d < tt.minDuration {
+			t.Errorf("%s: downstream duration = %s, want at least %s", tt.spec.Name, d, tt.minDuration)
+		}
+	}
+}
+
+func TestWorkDownstreamValidation(t *testing.T) {
+	withSelf := NewWorkProfiles(t.TempDir(), 0, "http://127.0.0.1:8080")
+	withoutSelf := NewWorkProfiles(t.TempDir(), 0, "")
+
+	for _, tt := range []struct {
+		name     string
+		profiles *WorkProfiles
+		spec     api.WorkProfile
+	}{
+		{"not a url", withSelf, api.WorkProfile{Name: "a", Downstream: "backend:8080"}},
+		{"bad scheme", withSelf, api.WorkProfile{Name: "a", Downstream: "ftp://backend/"}},
+		{"latency without downstream", withSelf, api.WorkProfile{Name: "a", DownstreamLatency: "1s"}},
+		{"latency with url", withSelf, api.WorkProfile{Name: "a", Downstream: "http://backend/", DownstreamLatency: "1s"}},
+		{"bad latency", withSelf, api.WorkProfile{Name: "a", Downstream: "self", DownstreamLatency: "soon"}},
+		{"self without server", withoutSelf, api.WorkProfile{Name: "a", Downstream: "self"}},
+	} {
+		if err := tt.profiles.Define(tt.spec); err == nil {
+			t.Errorf("%s: Define() error = nil, want error", tt.name)
+		}
+	}
+}
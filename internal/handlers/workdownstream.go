@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+const (
+	// downstreamSelf is the WorkProfile.Downstream value for a self-call.
+	downstreamSelf = "self"
+	// defaultDownstreamLatency is how long a self-call waits by default.
+	defaultDownstreamLatency = 50 * time.Millisecond
+	// downstreamTimeout bounds each downstream call, in addition to the
+	// request context.
+	downstreamTimeout = 30 * time.Second
+)
+
+// Downstream call outcomes, used as the metric label.
+const (
+	downstreamSuccess   = "success"
+	downstreamHTTPError = "http_error"
+	downstreamError     = "error"
+)
+
+// parseDownstream resolves the profile's downstream target. A self-call goes
+// through this pod's /latency endpoint, so it passes through the same
+// middleware, fault injection, and concurrency limits as external traffic.
+func (p *WorkProfiles) parseDownstream(profile *workProfile) error {
+	spec := &profile.spec
+	switch spec.Downstream {
+	case "":
+		if spec.DownstreamLatency != "" {
+			return errors.New("downstream_latency requires downstream")
+		}
+		return nil
+
+	case downstreamSelf:
+		if p.selfURL == "" {
+			return errors.New("downstream self-calls require the HTTP server")
+		}
+		latency := defaultDownstreamLatency
+		if spec.DownstreamLatency != "" {
+			d, err := time.ParseDuration(spec.DownstreamLatency)
+			if err != nil {
+				return fmt.Errorf("invalid downstream_latency: %w", err)
+			}
+			if d < 0 {
+				return errors.New("downstream_latency must be non-negative")
+			}
+			latency = d
+		}
+		spec.DownstreamLatency = latency.String()
+		profile.downstreamURL = p.selfURL + "/latency?" + url.Values{"duration": {latency.String()}}.Encode()
+		return nil
+
+	default:
+		if spec.DownstreamLatency != "" {
+			return errors.New("downstream_latency applies only to self-calls")
+		}
+		u, err := url.Parse(spec.Downstream)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("downstream must be self or an http or https URL")
+		}
+		profile.downstreamURL = u.String()
+		return nil
+	}
+}
+
+// callDownstream makes the outbound GET for a /work downstream phase. Errors
+// are reported in the result rather than failing the request, since a slow or
+// failing dependency is part of the workload being simulated.
+func callDownstream(ctx context.Context, client *http.Client, target string) *api.WorkDownstream {
+	result := &api.WorkDownstream{URL: target}
+	start := time.Now()
+	outcome := downstreamError
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = client.Do(req); err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			result.Status = resp.StatusCode
+			outcome = downstreamSuccess
+			if resp.StatusCode >= 400 {
+				outcome = downstreamHTTPError
+			}
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	elapsed := time.Since(start)
+	result.Duration = elapsed.String()
+	metrics.WorkDownstreamSeconds.WithLabelValues(outcome).Observe(elapsed.Seconds())
+	return result
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// maxWorkProfileBody bounds POST /admin/profiles request bodies.
+const maxWorkProfileBody = 64 << 10
+
+// WorkProfileHandlers lists and edits the /work profiles at runtime.
+type WorkProfileHandlers struct {
+	authn    *auth.Authenticator
+	profiles *WorkProfiles
+}
+
+// NewWorkProfileHandlers creates handlers for the work profile admin endpoints.
+func NewWorkProfileHandlers(authn *auth.Authenticator, profiles *WorkProfiles) *WorkProfileHandlers {
+	return &WorkProfileHandlers{authn: authn, profiles: profiles}
+}
+
+// Register adds work profile routes to the mux.
+func (h *WorkProfileHandlers) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/profiles", h.List)
+	mux.HandleFunc("POST /admin/profiles", h.Define)
+	mux.HandleFunc("DELETE /admin/profiles/{name}", h.Delete)
+}
+
+// List handles GET /admin/profiles.
+func (h *WorkProfileHandlers) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleRead) {
+		return
+	}
+	h.writeProfiles(w)
+}
+
+// Define handles POST /admin/profiles with a JSON profile body, adding a
+// profile or replacing one of the same name.
+func (h *WorkProfileHandlers) Define(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	var spec api.WorkProfile
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWorkProfileBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, "body must be a JSON work profile: "+err.Error())
+		return
+	}
+	if err := h.profiles.Define(spec); err != nil {
+		writeError(w, http.StatusBadRequest, errcode.InvalidParameter, err.Error())
+		return
+	}
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "work profile defined", map[string]any{
+		"profile": spec.Name,
+	})
+	h.writeProfiles(w)
+}
+
+// Delete handles DELETE /admin/profiles/{name}. Deleting a built-in profile
+// restores its default definition.
+func (h *WorkProfileHandlers) Delete(w http.ResponseWriter, r *http.Request) {
+	if !authorize(h.authn, w, r, auth.RoleMutate) {
+		return
+	}
+
+	name := r.PathValue("name")
+	if !h.profiles.Delete(name) {
+		writeError(w, http.StatusNotFound, errcode.ProfileNotFound, "no work profile named "+name)
+		return
+	}
+
+	events.Record(slog.LevelInfo, events.TypeAdmin, "work profile deleted", map[string]any{
+		"profile": name,
+	})
+	h.writeProfiles(w)
+}
+
+func (h *WorkProfileHandlers) writeProfiles(w http.ResponseWriter) {
+	profiles := h.profiles.List()
+	resp := api.WorkProfilesResponse{Count: len(profiles), Profiles: profiles}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode work profiles response", "error", err)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestWorkProfileHandlers(t *testing.T) {
+	profiles := NewWorkProfiles(t.TempDir(), 1<<20, "")
+	mux := http.NewServeMux()
+	NewWorkProfileHandlers(auth.New("", nil), profiles).Register(mux)
+
+	for _, tt := range []struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCount  int
+	}{
+		{"GET", "/admin/profiles", "", http.StatusOK, 4},
+		{"POST", "/admin/profiles", `{"name":"checkout","cpu":"5ms","memory":"1Mi","io":"64Ki"}`, http.StatusOK, 5},
+		{"POST", "/admin/profiles", `{"name":"web","cpu":"1ms"}`, http.StatusOK, 5},
+		{"POST", "/admin/profiles", `{"name":"Bad Name"}`, http.StatusBadRequest, 0},
+		{"POST", "/admin/profiles", `{"name":"big","io":"2Mi"}`, http.StatusBadRequest, 0},
+		{"POST", "/admin/profiles", `{"name":"x","gpu":"1"}`, http.StatusBadRequest, 0},
+		{"DELETE", "/admin/profiles/checkout", "", http.StatusOK, 4},
+		{"DELETE", "/admin/profiles/checkout", "", http.StatusNotFound, 0},
+		{"DELETE", "/admin/profiles/web", "", http.StatusOK, 4},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s %s: status = %d, want %d: %s", tt.method, tt.path, tt.body, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp api.WorkProfilesResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Count != tt.wantCount {
+			t.Errorf("%s %s: count = %d, want %d", tt.method, tt.path, resp.Count, tt.wantCount)
+		}
+	}
+
+	// Deleting the redefined web profile restored the default.
+	for _, p := range profiles.List() {
+		if p.Name == "web" && (!p.Builtin || p.CPU != "20ms") {
+			t.Errorf("web profile = %+v, want the built-in default", p)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/ripta/hotpod/internal/events"
+)
+
+// Backlog gates readiness on a queue's depth, with hysteresis: readiness is
+// lost once the depth exceeds the high watermark, and regained only once it
+// falls to the low watermark or below, so load balancers see backpressure
+// rather than flapping. The depth is sampled whenever readiness is checked.
+type Backlog struct {
+	high, low int
+
+	mu    sync.Mutex
+	depth func() int
+	over  bool
+}
+
+// NewBacklog creates a backlog gate with the given watermarks. A low
+// watermark of zero defaults to half the high one.
+func NewBacklog(high, low int) *Backlog {
+	if low == 0 {
+		low = high / 2
+	}
+	return &Backlog{high: high, low: low}
+}
+
+// Watch sets the function reporting the queue depth. Until it is called the
+// backlog never gates readiness.
+func (b *Backlog) Watch(depth func() int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.depth = depth
+}
+
+// Err returns an error while the backlog is over its high watermark and has
+// not yet drained to its low one. It is safe to call on a nil receiver,
+// which never gates readiness.
+func (b *Backlog) Err() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.depth == nil {
+		return nil
+	}
+
+	depth := b.depth()
+	switch {
+	case !b.over && depth > b.high:
+		b.over = true
+		slog.Warn("queue backlog over high watermark, not ready", "depth", depth, "high", b.high)
+		events.Record(slog.LevelWarn, events.TypeLifecycle, "queue backlog over high watermark", map[string]any{
+			"depth": depth,
+			"high":  b.high,
+		})
+	case b.over && depth <= b.low:
+		b.over = false
+		slog.Info("queue backlog drained to low watermark, ready", "depth", depth, "low", b.low)
+		events.Record(slog.LevelInfo, events.TypeLifecycle, "queue backlog drained to low watermark", map[string]any{
+			"depth": depth,
+			"low":   b.low,
+		})
+	}
+	if !b.over {
+		return nil
+	}
+	return fmt.Errorf("queue backlog of %d items has not drained to %d", depth, b.low)
+}
@@ -0,0 +1,43 @@
+package health
+
+import "testing"
+
+func TestBacklogHysteresis(t *testing.T) {
+	depth := 0
+	b := NewBacklog(10, 4)
+	if err := b.Err(); err != nil {
+		t.Errorf("Err() before Watch = %v, want nil", err)
+	}
+	b.Watch(func() int { return depth })
+
+	steps := []struct {
+		depth    int
+		notReady bool
+	}{
+		{5, false},
+		{10, false},
+		{11, true},
+		{7, true},
+		{5, true},
+		{4, false},
+		{8, false},
+	}
+	for _, s := range steps {
+		depth = s.depth
+		if err := b.Err(); (err != nil) != s.notReady {
+			t.Errorf("depth %d: Err() = %v, want not ready %v", s.depth, err, s.notReady)
+		}
+	}
+}
+
+func TestBacklogDefaultLow(t *testing.T) {
+	b := NewBacklog(10, 0)
+	if b.low != 5 {
+		t.Errorf("low = %d, want 5", b.low)
+	}
+
+	var nilBacklog *Backlog
+	if err := nilBacklog.Err(); err != nil {
+		t.Errorf("nil Err() = %v, want nil", err)
+	}
+}
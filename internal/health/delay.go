@@ -0,0 +1,143 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Probe endpoint names.
+const (
+	ProbeHealthz  = "healthz"
+	ProbeReadyz   = "readyz"
+	ProbeStartupz = "startupz"
+)
+
+// Probes lists the probe endpoints in order.
+var Probes = []string{ProbeHealthz, ProbeReadyz, ProbeStartupz}
+
+// MaxDelay bounds a probe delay, including jitter.
+const MaxDelay = 5 * time.Minute
+
+// Delay is the response delay for a probe endpoint: Fixed plus a random
+// amount of up to Jitter.
+type Delay struct {
+	Fixed  time.Duration
+	Jitter time.Duration
+}
+
+// IsZero reports whether the delay is disabled.
+func (d Delay) IsZero() bool {
+	return d.Fixed == 0 && d.Jitter == 0
+}
+
+// Validate checks that the delay is non-negative and within MaxDelay.
+func (d Delay) Validate() error {
+	if d.Fixed < 0 || d.Jitter < 0 {
+		return errors.New("delay and jitter must be non-negative")
+	}
+	if d.Fixed+d.Jitter > MaxDelay {
+		return fmt.Errorf("delay plus jitter must be at most %s", MaxDelay)
+	}
+	return nil
+}
+
+// Delays holds the response delay for each probe endpoint, so probe
+// timeoutSeconds and kubelet behavior on slow probes can be tested. It is
+// safe for concurrent use.
+type Delays struct {
+	mu      sync.Mutex
+	byProbe map[string]Delay
+}
+
+// NewDelays creates a set of probe delays, all disabled.
+func NewDelays() *Delays {
+	return &Delays{byProbe: map[string]Delay{}}
+}
+
+// Set sets the delay for probe, or for every probe when probe is empty. A
+// zero delay disables it.
+func (d *Delays) Set(probe string, delay Delay) error {
+	if err := delay.Validate(); err != nil {
+		return err
+	}
+	probes := Probes
+	if probe != "" {
+		if !slices.Contains(Probes, probe) {
+			return fmt.Errorf("probe must be one of healthz, readyz, or startupz, got %q", probe)
+		}
+		probes = []string{probe}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, p := range probes {
+		if delay.IsZero() {
+			delete(d.byProbe, p)
+		} else {
+			d.byProbe[p] = delay
+		}
+	}
+	return nil
+}
+
+// Clear disables all delays.
+func (d *Delays) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	clear(d.byProbe)
+}
+
+// Get returns the delay for probe. It is safe to call on a nil receiver,
+// which has no delays.
+func (d *Delays) Get(probe string) Delay {
+	if d == nil {
+		return Delay{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.byProbe[probe]
+}
+
+// List returns the enabled delays in probe order.
+func (d *Delays) List() []api.HealthDelay {
+	delays := []api.HealthDelay{}
+	for _, p := range Probes {
+		delay := d.Get(p)
+		if delay.IsZero() {
+			continue
+		}
+		hd := api.HealthDelay{Probe: p, Delay: delay.Fixed.String()}
+		if delay.Jitter > 0 {
+			hd.Jitter = delay.Jitter.String()
+		}
+		delays = append(delays, hd)
+	}
+	return delays
+}
+
+// Wait blocks for the delay configured for probe, returning early if ctx is
+// done, as when the kubelet gives up on the probe.
+func (d *Delays) Wait(ctx context.Context, probe string) {
+	delay := d.Get(probe)
+	wait := delay.Fixed
+	if delay.Jitter > 0 {
+		wait += time.Duration(rand.Int64N(int64(delay.Jitter)))
+	}
+	if wait <= 0 {
+		return
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
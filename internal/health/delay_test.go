@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDelaysSet(t *testing.T) {
+	d := NewDelays()
+	if err := d.Set("", Delay{Fixed: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set(ProbeReadyz, Delay{Fixed: 2 * time.Second, Jitter: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set(ProbeStartupz, Delay{}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := d.List()
+	if len(got) != 2 || got[0].Probe != ProbeHealthz || got[0].Delay != "1s" || got[1].Jitter != "1s" {
+		t.Errorf("List() = %+v, want healthz 1s and readyz 2s with 1s jitter", got)
+	}
+
+	for _, tt := range []struct {
+		probe string
+		delay Delay
+	}{
+		{"livez", Delay{Fixed: time.Second}},
+		{"", Delay{Fixed: -time.Second}},
+		{"", Delay{Jitter: -time.Second}},
+		{"", Delay{Fixed: MaxDelay, Jitter: time.Second}},
+	} {
+		if err := d.Set(tt.probe, tt.delay); err == nil {
+			t.Errorf("Set(%q, %+v) error = nil, want error", tt.probe, tt.delay)
+		}
+	}
+
+	d.Clear()
+	if got := d.List(); len(got) != 0 {
+		t.Errorf("List() after Clear = %+v, want none", got)
+	}
+}
+
+func TestDelaysWait(t *testing.T) {
+	var nilDelays *Delays
+	nilDelays.Wait(context.Background(), ProbeHealthz)
+
+	d := NewDelays()
+	d.Set(ProbeHealthz, Delay{Fixed: 20 * time.Millisecond, Jitter: 10 * time.Millisecond})
+
+	start := time.Now()
+	d.Wait(context.Background(), ProbeHealthz)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Wait() took %s, want at least 20ms", elapsed)
+	}
+
+	d.Set(ProbeHealthz, Delay{Fixed: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	d.Wait(ctx, ProbeHealthz)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Wait() took %s after the context was done", elapsed)
+	}
+}
@@ -0,0 +1,120 @@
+// Package health tracks synthetic dependency checks that gate readiness.
+package health
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// MaxDependencies bounds the number of dependency checks.
+const MaxDependencies = 50
+
+var dependencyName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Dependencies is a set of named checks, each of which can be toggled to
+// fail independently. It is safe for concurrent use.
+type Dependencies struct {
+	mu     sync.Mutex
+	checks map[string]api.DependencyCheck
+}
+
+// NewDependencies creates an empty set of dependency checks.
+func NewDependencies() *Dependencies {
+	return &Dependencies{checks: map[string]api.DependencyCheck{}}
+}
+
+// ParseDependencyNames parses a comma-separated list of check names.
+func ParseDependencyNames(s string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func validateName(name string) error {
+	if !dependencyName.MatchString(name) {
+		return fmt.Errorf("dependency name must be 1-63 lowercase letters, digits, hyphens, or underscores, got %q", name)
+	}
+	return nil
+}
+
+// Set adds a check or replaces the one of the same name.
+func (d *Dependencies) Set(check api.DependencyCheck) error {
+	if err := validateName(check.Name); err != nil {
+		return err
+	}
+	if !check.Failing {
+		check.Message = ""
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.checks[check.Name]; !ok && len(d.checks) >= MaxDependencies {
+		return fmt.Errorf("at most %d dependencies may be defined", MaxDependencies)
+	}
+	d.checks[check.Name] = check
+	return nil
+}
+
+// Delete removes a check and reports whether it existed.
+func (d *Dependencies) Delete(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.checks[name]; !ok {
+		return false
+	}
+	delete(d.checks, name)
+	return true
+}
+
+// List returns the checks sorted by name. It is safe to call on a nil
+// receiver, which has no checks.
+func (d *Dependencies) List() []api.DependencyCheck {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	checks := make([]api.DependencyCheck, 0, len(d.checks))
+	for _, c := range d.checks {
+		checks = append(checks, c)
+	}
+	slices.SortFunc(checks, func(a, b api.DependencyCheck) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return checks
+}
+
+// Err returns an error naming the failing checks, or nil if all pass.
+func (d *Dependencies) Err() error {
+	var failing []string
+	for _, c := range d.List() {
+		if !c.Failing {
+			continue
+		}
+		if c.Message != "" {
+			failing = append(failing, c.Name+": "+c.Message)
+		} else {
+			failing = append(failing, c.Name)
+		}
+	}
+	if len(failing) == 0 {
+		return nil
+	}
+	return errors.New("dependency check failing: " + strings.Join(failing, ", "))
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestParseDependencyNames(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"db", []string{"db"}, false},
+		{" db , cache,,", []string{"db", "cache"}, false},
+		{"db,Cache", nil, true},
+		{"db,-cache", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDependencyNames(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDependencyNames(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ParseDependencyNames(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	d := NewDependencies()
+	if err := d.Err(); err != nil {
+		t.Errorf("Err() with no checks = %v, want nil", err)
+	}
+
+	for _, c := range []api.DependencyCheck{
+		{Name: "db", Failing: true, Message: "connection refused"},
+		{Name: "cache", Message: "ignored while passing"},
+		{Name: "queue", Failing: true},
+	} {
+		if err := d.Set(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Set(api.DependencyCheck{Name: "Bad Name"}); err == nil {
+		t.Error("Set(invalid name) error = nil, want error")
+	}
+
+	checks := d.List()
+	if len(checks) != 3 || checks[0].Name != "cache" || checks[0].Message != "" {
+		t.Errorf("List() = %+v, want 3 checks sorted by name, passing ones without a message", checks)
+	}
+
+	want := "dependency check failing: db: connection refused, queue"
+	if err := d.Err(); err == nil || err.Error() != want {
+		t.Errorf("Err() = %v, want %q", err, want)
+	}
+
+	d.Set(api.DependencyCheck{Name: "db"})
+	if !d.Delete("queue") || d.Delete("queue") {
+		t.Error("Delete(queue) should succeed exactly once")
+	}
+	if err := d.Err(); err != nil {
+		t.Errorf("Err() after recovery = %v, want nil", err)
+	}
+}
+
+func TestDependenciesLimit(t *testing.T) {
+	d := NewDependencies()
+	for i := range MaxDependencies {
+		if err := d.Set(api.DependencyCheck{Name: fmt.Sprintf("dep%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Set(api.DependencyCheck{Name: "extra"}); err == nil {
+		t.Error("Set() beyond the limit error = nil, want error")
+	}
+	if err := d.Set(api.DependencyCheck{Name: "dep0", Failing: true}); err != nil {
+		t.Errorf("Set() replacing at the limit error = %v", err)
+	}
+}
+
+func TestNilDependencies(t *testing.T) {
+	var d *Dependencies
+	if d.List() != nil || d.Err() != nil {
+		t.Error("nil Dependencies should have no checks")
+	}
+}
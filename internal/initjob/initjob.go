@@ -0,0 +1,136 @@
+// Package initjob performs a bounded amount of work and returns, so hotpod can
+// stand in for an init container when testing init duration and failure
+// handling.
+package initjob
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ioBlockSize is the size of each write and read.
+const ioBlockSize = 1 << 20
+
+// Config describes the work to perform. Steps run in order: CPU, I/O, then
+// sleep; zero values skip a step.
+type Config struct {
+	// CPU is how long to burn CPU on each of Cores cores
+	CPU   time.Duration
+	Cores int
+	// IOSize is the number of bytes written, synced, and read back
+	IOSize int64
+	// IODir is where the scratch file is written
+	IODir string
+	// Sleep is how long to wait after the work is done
+	Sleep time.Duration
+}
+
+// Run performs the configured work. It returns an error if a step fails or
+// ctx is cancelled first.
+func Run(ctx context.Context, cfg Config) error {
+	start := time.Now()
+	slog.Info("init job started", "cpu", cfg.CPU, "cores", cfg.Cores, "io_size", cfg.IOSize, "sleep", cfg.Sleep)
+
+	if cfg.CPU > 0 {
+		stepStart := time.Now()
+		if err := burn(ctx, cfg.CPU, max(cfg.Cores, 1)); err != nil {
+			return fmt.Errorf("cpu step: %w", err)
+		}
+		slog.Info("init cpu step complete", "elapsed", time.Since(stepStart))
+	}
+
+	if cfg.IOSize > 0 {
+		stepStart := time.Now()
+		if err := performIO(ctx, cfg.IODir, cfg.IOSize); err != nil {
+			return fmt.Errorf("io step: %w", err)
+		}
+		slog.Info("init io step complete", "bytes", cfg.IOSize, "elapsed", time.Since(stepStart))
+	}
+
+	if cfg.Sleep > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("sleep step: %w", ctx.Err())
+		case <-time.After(cfg.Sleep):
+		}
+	}
+
+	slog.Info("init job complete", "elapsed", time.Since(start))
+	return nil
+}
+
+// burn consumes d of CPU on each of cores goroutines.
+func burn(ctx context.Context, d time.Duration, cores int) error {
+	burnCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for range cores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			x := 1.0
+			for burnCtx.Err() == nil {
+				for range 1000 {
+					x = math.Sin(x) + math.Cos(x)
+					x = math.Sqrt(math.Abs(x) + 1)
+				}
+			}
+			runtime.KeepAlive(x)
+		}()
+	}
+	wg.Wait()
+
+	// The timeout is the normal way out; only a cancelled parent is an error.
+	return ctx.Err()
+}
+
+// performIO writes size bytes to a scratch file in dir, syncs it, reads it
+// back, and removes it.
+func performIO(ctx context.Context, dir string, size int64) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "init-*.dat")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	block := make([]byte, ioBlockSize)
+	if _, err := rand.Read(block); err != nil {
+		return err
+	}
+	for remaining := size; remaining > 0; remaining -= ioBlockSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := f.Write(block[:min(remaining, ioBlockSize)]); err != nil {
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	n, err := io.CopyBuffer(io.Discard, f, block)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("read back %d bytes, wrote %d", n, size)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package initjob
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+
+	err := Run(context.Background(), Config{
+		CPU:    50 * time.Millisecond,
+		Cores:  2,
+		IOSize: 3<<20 + 17,
+		IODir:  dir,
+		Sleep:  50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Run() took %s, want at least 100ms", elapsed)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("scratch files left behind: %v", entries)
+	}
+}
+
+func TestRunNothing(t *testing.T) {
+	if err := Run(context.Background(), Config{}); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	for _, cfg := range []Config{
+		{CPU: time.Minute, Cores: 1},
+		{Sleep: time.Minute},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err := Run(ctx, cfg)
+		cancel()
+		if err == nil {
+			t.Errorf("Run(%+v) with cancelled context succeeded, want error", cfg)
+		}
+	}
+}
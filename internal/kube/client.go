@@ -0,0 +1,199 @@
+// Package kube provides a minimal in-cluster Kubernetes API client. It only
+// covers the handful of REST calls hotpod needs, avoiding a dependency on
+// client-go.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default in-cluster service account paths.
+const (
+	ServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenFile         = ServiceAccountDir + "/token"
+	caFile            = ServiceAccountDir + "/ca.crt"
+	namespaceFile     = ServiceAccountDir + "/namespace"
+)
+
+// ErrNotInCluster is returned when the process is not running in a pod with
+// the Kubernetes service environment variables set.
+var ErrNotInCluster = errors.New("not running in a Kubernetes cluster")
+
+// APIError is returned for non-2xx responses from the API server.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("kubernetes API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether err is an APIError with status 409.
+func IsConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// Client issues authenticated requests to the Kubernetes API server.
+type Client struct {
+	// baseURL is the API server URL, e.g. https://10.0.0.1:443
+	baseURL string
+	// tokenPath is re-read on every request so projected token rotation works
+	tokenPath string
+	// namespace is the pod's namespace
+	namespace string
+	http      *http.Client
+}
+
+// NewInCluster creates a client from the in-cluster service account.
+func NewInCluster() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no certificates found in service account CA")
+	}
+
+	ns, err := os.ReadFile(namespaceFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading service account namespace: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	return &Client{
+		baseURL:   "https://" + net.JoinHostPort(host, port),
+		tokenPath: tokenFile,
+		namespace: strings.TrimSpace(string(ns)),
+		http:      &http.Client{Transport: transport, Timeout: 10 * time.Second},
+	}, nil
+}
+
+// NewForTesting creates a client against an arbitrary base URL without
+// authentication. It is intended for tests using httptest servers.
+func NewForTesting(baseURL, namespace string) *Client {
+	return &Client{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		namespace: namespace,
+		http:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Namespace returns the namespace the pod is running in.
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
+// Do sends a request to the API server. If body is non-nil it is encoded as
+// JSON; if out is non-nil the response body is decoded into it.
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) error {
+	return c.DoWithContentType(ctx, method, path, "application/json", body, out)
+}
+
+// DoWithContentType is like Do but allows a custom request content type, as
+// needed for merge and strategic-merge patches.
+func (c *Client) DoWithContentType(ctx context.Context, method, path, contentType string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request body: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.tokenPath != "" {
+		token, err := os.ReadFile(c.tokenPath)
+		if err != nil {
+			return fmt.Errorf("reading service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var status struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(msg, &status) == nil && status.Message != "" {
+			return &APIError{StatusCode: resp.StatusCode, Message: status.Message}
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+	return nil
+}
+
+// PodName returns the pod name from the Downward API (POD_NAME), falling back
+// to the hostname, which Kubernetes sets to the pod name by default.
+func PodName() string {
+	if v := os.Getenv("POD_NAME"); v != "" {
+		return v
+	}
+	h, _ := os.Hostname()
+	return h
+}
+
+// PodNamespace returns the pod namespace from the Downward API
+// (POD_NAMESPACE), falling back to the service account namespace file.
+func PodNamespace() string {
+	if v := os.Getenv("POD_NAMESPACE"); v != "" {
+		return v
+	}
+	if b, err := os.ReadFile(namespaceFile); err == nil {
+		return strings.TrimSpace(string(b))
+	}
+	return ""
+}
+
+// NodeName returns the name of the node the pod is scheduled on, from the
+// Downward API (NODE_NAME). Unlike the pod name, it has no fallback.
+func NodeName() string {
+	return os.Getenv("NODE_NAME")
+}
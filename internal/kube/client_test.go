@@ -0,0 +1,71 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/namespaces/ns/things" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var in map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]string{"echo": in["name"]}); err != nil {
+			t.Errorf("encode: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewForTesting(srv.URL, "ns")
+	var out map[string]string
+	if err := c.Do(context.Background(), http.MethodPost, "/api/v1/namespaces/ns/things", map[string]string{"name": "x"}, &out); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if out["echo"] != "x" {
+		t.Errorf("echo = %q, want x", out["echo"])
+	}
+}
+
+func TestClientDoAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"kind":"Status","message":"pods \"x\" not found"}`))
+	}))
+	defer srv.Close()
+
+	c := NewForTesting(srv.URL, "ns")
+	err := c.Do(context.Background(), http.MethodGet, "/api/v1/namespaces/ns/pods/x", nil, nil)
+	if !IsNotFound(err) {
+		t.Fatalf("IsNotFound(%v) = false", err)
+	}
+	if IsConflict(err) {
+		t.Error("IsConflict should be false for 404")
+	}
+}
+
+func TestNewInClusterOutsideCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	if _, err := NewInCluster(); err != ErrNotInCluster {
+		t.Errorf("NewInCluster() error = %v, want ErrNotInCluster", err)
+	}
+}
+
+func TestPodName(t *testing.T) {
+	t.Setenv("POD_NAME", "hotpod-abc")
+	if got := PodName(); got != "hotpod-abc" {
+		t.Errorf("PodName() = %q, want hotpod-abc", got)
+	}
+}
@@ -0,0 +1,354 @@
+// Package leader implements Kubernetes Lease-based leader election, with
+// hooks to drop or stall leadership so operator failover can be exercised.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Status describes the elector's view of the lease.
+type Status = api.LeaderStatus
+
+// microTimeFormat is the RFC 3339 layout used by Lease MicroTime fields.
+const microTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// Config holds lease timing parameters, with the same meaning as in
+// client-go's leader election.
+type Config struct {
+	// LeaseName is the Lease object name
+	LeaseName string
+	// Identity is this candidate's holder identity, typically the pod name
+	Identity string
+	// LeaseDuration is how long non-leaders wait before taking over an unrenewed lease
+	LeaseDuration time.Duration
+	// RenewDeadline is how long the leader keeps trying to renew before stepping down
+	RenewDeadline time.Duration
+	// RetryPeriod is the interval between acquire or renew attempts
+	RetryPeriod time.Duration
+}
+
+// lease is the subset of a coordination.k8s.io/v1 Lease the elector uses.
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       *string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int32  `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *string `json:"acquireTime,omitempty"`
+	RenewTime            *string `json:"renewTime,omitempty"`
+	LeaseTransitions     *int32  `json:"leaseTransitions,omitempty"`
+}
+
+// Elector campaigns for a Lease.
+type Elector struct {
+	client    *kube.Client
+	namespace string
+	cfg       Config
+	now       func() time.Time
+
+	mu            sync.Mutex
+	leader        bool
+	holder        string
+	transitions   int32
+	acquireTime   time.Time
+	renewTime     time.Time
+	lastRenewOK   time.Time
+	lastErr       string
+	heldOffUntil  time.Time
+	stalledUntil  time.Time
+	dropRequested bool
+}
+
+// New creates an elector for a Lease in namespace.
+func New(client *kube.Client, namespace string, cfg Config) *Elector {
+	return &Elector{client: client, namespace: namespace, cfg: cfg, now: time.Now}
+}
+
+func (e *Elector) leasePath() string {
+	return fmt.Sprintf("/apis/coordination.k8s.io/v1/namespaces/%s/leases/%s", e.namespace, e.cfg.LeaseName)
+}
+
+// Run campaigns for leadership until ctx is cancelled, releasing the lease on
+// the way out if held.
+func (e *Elector) Run(ctx context.Context) {
+	slog.Info("leader election started", "lease", e.cfg.LeaseName, "identity", e.cfg.Identity)
+
+	ticker := time.NewTicker(e.cfg.RetryPeriod)
+	defer ticker.Stop()
+
+	for {
+		e.step(ctx)
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.Background(), e.cfg.RetryPeriod)
+			e.release(releaseCtx)
+			cancel()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// step performs one acquire-or-renew round.
+func (e *Elector) step(ctx context.Context) {
+	now := e.now()
+
+	e.mu.Lock()
+	drop := e.dropRequested
+	e.dropRequested = false
+	stalled := now.Before(e.stalledUntil)
+	heldOff := now.Before(e.heldOffUntil)
+	e.mu.Unlock()
+
+	if drop {
+		e.release(ctx)
+		return
+	}
+	if stalled {
+		// Deliberately neither renew nor step down, to simulate a leader that
+		// is wedged but still believes it holds the lease.
+		return
+	}
+	if heldOff {
+		return
+	}
+
+	err := e.tryAcquireOrRenew(ctx, now)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		e.lastErr = err.Error()
+		if e.leader {
+			metrics.LeaderRenewFailuresTotal.Inc()
+			if now.Sub(e.lastRenewOK) > e.cfg.RenewDeadline {
+				e.setLeaderLocked(false, "renew deadline exceeded")
+			}
+		}
+		return
+	}
+	e.lastErr = ""
+}
+
+func (e *Elector) tryAcquireOrRenew(ctx context.Context, now time.Time) error {
+	var l lease
+	err := e.client.Do(ctx, http.MethodGet, e.leasePath(), nil, &l)
+	if kube.IsNotFound(err) {
+		l = e.newLease(now, 0)
+		if err := e.client.Do(ctx, http.MethodPost, fmt.Sprintf("/apis/coordination.k8s.io/v1/namespaces/%s/leases", e.namespace), l, nil); err != nil {
+			return fmt.Errorf("creating lease: %w", err)
+		}
+		e.observe(l, now, true)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting lease: %w", err)
+	}
+
+	holder := deref(l.Spec.HolderIdentity)
+	renew := parseMicroTime(l.Spec.RenewTime)
+	duration := time.Duration(derefInt(l.Spec.LeaseDurationSeconds)) * time.Second
+	expired := holder == "" || renew.IsZero() || now.After(renew.Add(duration))
+
+	if holder != e.cfg.Identity && !expired {
+		e.observe(l, now, false)
+		return nil
+	}
+
+	transitions := derefInt(l.Spec.LeaseTransitions)
+	acquire := deref(l.Spec.AcquireTime)
+	if holder != e.cfg.Identity {
+		transitions++
+		acquire = now.UTC().Format(microTimeFormat)
+	}
+
+	updated := e.newLease(now, transitions)
+	updated.Metadata.ResourceVersion = l.Metadata.ResourceVersion
+	updated.Spec.AcquireTime = &acquire
+	if err := e.client.Do(ctx, http.MethodPut, e.leasePath(), updated, nil); err != nil {
+		if kube.IsConflict(err) {
+			return fmt.Errorf("lease was updated concurrently: %w", err)
+		}
+		return fmt.Errorf("updating lease: %w", err)
+	}
+	e.observe(updated, now, true)
+	return nil
+}
+
+func (e *Elector) newLease(now time.Time, transitions int32) lease {
+	identity := e.cfg.Identity
+	seconds := int32(e.cfg.LeaseDuration / time.Second)
+	ts := now.UTC().Format(microTimeFormat)
+	return lease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Metadata:   leaseMetadata{Name: e.cfg.LeaseName, Namespace: e.namespace},
+		Spec: leaseSpec{
+			HolderIdentity:       &identity,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &ts,
+			RenewTime:            &ts,
+			LeaseTransitions:     &transitions,
+		},
+	}
+}
+
+// observe records the lease state after a successful API round.
+func (e *Elector) observe(l lease, now time.Time, held bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.holder = deref(l.Spec.HolderIdentity)
+	e.transitions = derefInt(l.Spec.LeaseTransitions)
+	e.acquireTime = parseMicroTime(l.Spec.AcquireTime)
+	e.renewTime = parseMicroTime(l.Spec.RenewTime)
+	if held {
+		e.lastRenewOK = now
+	}
+	if held != e.leader {
+		reason := "lease acquired"
+		if !held {
+			reason = "lease held by " + e.holder
+		}
+		e.setLeaderLocked(held, reason)
+	}
+}
+
+func (e *Elector) setLeaderLocked(leader bool, reason string) {
+	e.leader = leader
+	if leader {
+		metrics.LeaderIsLeader.Set(1)
+		metrics.LeaderTransitionsTotal.WithLabelValues("acquired").Inc()
+		slog.Info("became leader", "lease", e.cfg.LeaseName, "reason", reason)
+		events.Record(slog.LevelInfo, events.TypeLifecycle, "became leader", map[string]any{"lease": e.cfg.LeaseName})
+		return
+	}
+	metrics.LeaderIsLeader.Set(0)
+	metrics.LeaderTransitionsTotal.WithLabelValues("lost").Inc()
+	slog.Warn("lost leadership", "lease", e.cfg.LeaseName, "reason", reason)
+	events.Record(slog.LevelWarn, events.TypeLifecycle, "lost leadership", map[string]any{"lease": e.cfg.LeaseName, "reason": reason})
+}
+
+// release gives up the lease if held by clearing the holder identity, so
+// another candidate can acquire it without waiting for expiry.
+func (e *Elector) release(ctx context.Context) {
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.mu.Unlock()
+	if !wasLeader {
+		return
+	}
+
+	var l lease
+	if err := e.client.Do(ctx, http.MethodGet, e.leasePath(), nil, &l); err == nil && deref(l.Spec.HolderIdentity) == e.cfg.Identity {
+		empty := ""
+		one := int32(1)
+		l.Spec.HolderIdentity = &empty
+		l.Spec.LeaseDurationSeconds = &one
+		if err := e.client.Do(ctx, http.MethodPut, e.leasePath(), l, nil); err != nil {
+			slog.Warn("failed to release lease", "error", err)
+		}
+	}
+
+	e.mu.Lock()
+	e.holder = ""
+	e.setLeaderLocked(false, "released")
+	e.mu.Unlock()
+}
+
+// Drop releases leadership at the next retry and stays out of the election
+// for holdOff, giving other candidates a chance to take over.
+func (e *Elector) Drop(holdOff time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dropRequested = true
+	e.heldOffUntil = e.now().Add(holdOff)
+}
+
+// Stall stops renewing the lease for d without stepping down, simulating a
+// leader that is alive but wedged. Other candidates take over once the lease
+// expires, while this instance still reports itself as leader.
+func (e *Elector) Stall(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stalledUntil = e.now().Add(d)
+}
+
+// Status returns the elector's current view.
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := Status{
+		Enabled:     true,
+		Identity:    e.cfg.Identity,
+		Lease:       e.cfg.LeaseName,
+		Leader:      e.leader,
+		Holder:      e.holder,
+		Transitions: e.transitions,
+		LastError:   e.lastErr,
+	}
+	now := e.now()
+	if !e.acquireTime.IsZero() {
+		t := e.acquireTime
+		s.AcquireTime = &t
+	}
+	if !e.renewTime.IsZero() {
+		t := e.renewTime
+		s.RenewTime = &t
+	}
+	if e.heldOffUntil.After(now) {
+		t := e.heldOffUntil
+		s.HeldOffUntil = &t
+	}
+	if e.stalledUntil.After(now) {
+		t := e.stalledUntil
+		s.StalledUntil = &t
+	}
+	return s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func parseMicroTime(s *string) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, *s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
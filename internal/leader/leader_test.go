@@ -0,0 +1,157 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/kube"
+)
+
+// fakeLeases stores a single Lease and enforces optimistic concurrency on
+// updates, like the API server.
+type fakeLeases struct {
+	mu      sync.Mutex
+	lease   *lease
+	version int
+}
+
+func (f *fakeLeases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		if f.lease == nil {
+			http.Error(w, `{"reason":"NotFound"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(f.lease)
+	case http.MethodPost, http.MethodPut:
+		var l lease
+		if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Method == http.MethodPost && f.lease != nil {
+			http.Error(w, `{"reason":"AlreadyExists"}`, http.StatusConflict)
+			return
+		}
+		if r.Method == http.MethodPut && (f.lease == nil || l.Metadata.ResourceVersion != f.lease.Metadata.ResourceVersion) {
+			http.Error(w, `{"reason":"Conflict"}`, http.StatusConflict)
+			return
+		}
+		f.version++
+		l.Metadata.ResourceVersion = strconv.Itoa(f.version)
+		f.lease = &l
+		json.NewEncoder(w).Encode(l)
+	}
+}
+
+func (f *fakeLeases) holder() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lease == nil {
+		return ""
+	}
+	return deref(f.lease.Spec.HolderIdentity)
+}
+
+func newTestElector(url, identity string, now *time.Time) *Elector {
+	e := New(kube.NewForTesting(url, "default"), "default", Config{
+		LeaseName:     "hotpod",
+		Identity:      identity,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	})
+	e.now = func() time.Time { return *now }
+	return e
+}
+
+func TestElectorAcquireAndDrop(t *testing.T) {
+	fake := &fakeLeases{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestElector(srv.URL, "a", &now)
+	b := newTestElector(srv.URL, "b", &now)
+	ctx := context.Background()
+
+	a.step(ctx)
+	b.step(ctx)
+	if !a.Status().Leader || b.Status().Leader {
+		t.Fatalf("a leader = %v, b leader = %v; want a only", a.Status().Leader, b.Status().Leader)
+	}
+	if got := b.Status().Holder; got != "a" {
+		t.Errorf("b sees holder %q, want a", got)
+	}
+
+	a.Drop(time.Minute)
+	a.step(ctx)
+	if a.Status().Leader {
+		t.Error("a still leader after drop")
+	}
+	if got := fake.holder(); got != "" {
+		t.Errorf("holder after drop = %q, want empty", got)
+	}
+
+	now = now.Add(2 * time.Second)
+	b.step(ctx)
+	a.step(ctx)
+	if !b.Status().Leader || a.Status().Leader {
+		t.Fatalf("a leader = %v, b leader = %v; want b only", a.Status().Leader, b.Status().Leader)
+	}
+	if got := b.Status().Transitions; got != 1 {
+		t.Errorf("transitions = %d, want 1", got)
+	}
+	if a.Status().HeldOffUntil == nil {
+		t.Error("a should report a hold-off time")
+	}
+}
+
+func TestElectorStallAllowsTakeover(t *testing.T) {
+	fake := &fakeLeases{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestElector(srv.URL, "a", &now)
+	b := newTestElector(srv.URL, "b", &now)
+	ctx := context.Background()
+
+	a.step(ctx)
+	a.Stall(time.Minute)
+
+	now = now.Add(10 * time.Second)
+	b.step(ctx)
+	if b.Status().Leader {
+		t.Fatal("b acquired an unexpired lease")
+	}
+
+	now = now.Add(10 * time.Second)
+	a.step(ctx)
+	b.step(ctx)
+	if !b.Status().Leader {
+		t.Fatal("b did not take over an expired lease")
+	}
+	if !a.Status().Leader {
+		t.Error("stalled leader should still believe it is leader")
+	}
+	if a.Status().StalledUntil == nil {
+		t.Error("a should report a stall time")
+	}
+
+	now = now.Add(time.Minute)
+	b.step(ctx)
+	a.step(ctx)
+	if a.Status().Leader {
+		t.Error("a still leader after stall ended and b holds the lease")
+	}
+}
@@ -1,8 +1,12 @@
 package load
 
 import (
+	"context"
 	"fmt"
 	"sync/atomic"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
 )
 
 // OpType represents the type of load operation.
@@ -14,28 +18,54 @@ const (
 	OpTypeIO      OpType = "io"
 	OpTypeLatency OpType = "latency"
 	OpTypeWork    OpType = "work"
+	OpTypeDNS     OpType = "dns"
 )
 
 // Tracker tracks concurrent operations and enforces limits.
 type Tracker struct {
 	// maxOps is the maximum concurrent operations per type (<=0 means unlimited)
 	maxOps int
+	// queueTimeout is how long AcquireContext waits for a slot (0 rejects immediately)
+	queueTimeout time.Duration
 	// counts tracks current operation counts per type
 	counts map[OpType]*atomic.Int64
+	// waiting tracks callers queued for a slot per type
+	waiting map[OpType]*atomic.Int64
+	// slots holds one token per running operation when maxOps is positive.
+	// Blocked channel senders are served in FIFO order, and Acquire turns
+	// new callers away while any are blocked, which gives queued callers
+	// admission-queue semantics.
+	slots map[OpType]chan struct{}
 }
 
 // NewTracker creates a new operation tracker.
 func NewTracker(maxOps int) *Tracker {
-	return &Tracker{
-		maxOps: maxOps,
-		counts: map[OpType]*atomic.Int64{
-			OpTypeCPU:     {},
-			OpTypeMemory:  {},
-			OpTypeIO:      {},
-			OpTypeLatency: {},
-			OpTypeWork:    {},
-		},
+	ops := []OpType{OpTypeCPU, OpTypeMemory, OpTypeIO, OpTypeLatency, OpTypeWork, OpTypeDNS}
+	t := &Tracker{
+		maxOps:  maxOps,
+		counts:  make(map[OpType]*atomic.Int64, len(ops)),
+		waiting: make(map[OpType]*atomic.Int64, len(ops)),
+		slots:   make(map[OpType]chan struct{}, len(ops)),
+	}
+	for _, op := range ops {
+		t.counts[op] = &atomic.Int64{}
+		t.waiting[op] = &atomic.Int64{}
+		if maxOps > 0 {
+			t.slots[op] = make(chan struct{}, maxOps)
+		}
 	}
+	return t
+}
+
+// SetQueueTimeout sets how long AcquireContext waits for a free slot before
+// giving up. Zero restores the default of rejecting immediately.
+func (t *Tracker) SetQueueTimeout(d time.Duration) {
+	t.queueTimeout = d
+}
+
+// QueueTimeout returns the configured admission queue timeout.
+func (t *Tracker) QueueTimeout() time.Duration {
+	return t.queueTimeout
 }
 
 // ErrTooManyOps is returned when the concurrent operation limit is exceeded.
@@ -43,17 +73,67 @@ var ErrTooManyOps = fmt.Errorf("too many concurrent operations")
 
 // Acquire attempts to start an operation of the given type.
 // Returns a release function on success, or ErrTooManyOps if limit exceeded.
+// While callers are queued in AcquireContext, a freed slot is theirs, so
+// Acquire fails rather than jump ahead of them.
 func (t *Tracker) Acquire(op OpType) (release func(), err error) {
-	counter := t.counts[op]
+	slots := t.slots[op]
+	if slots == nil {
+		return t.start(op, nil), nil
+	}
+	if t.waiting[op].Load() > 0 {
+		return nil, ErrTooManyOps
+	}
 
-	for {
-		current := counter.Load()
-		if t.maxOps > 0 && current >= int64(t.maxOps) {
-			return nil, ErrTooManyOps
-		}
+	select {
+	case slots <- struct{}{}:
+		return t.start(op, slots), nil
+	default:
+		return nil, ErrTooManyOps
+	}
+}
 
-		if counter.CompareAndSwap(current, current+1) {
-			return func() { counter.Add(-1) }, nil
+// AcquireContext is like Acquire, but when a queue timeout is configured and
+// the limit is reached or others are already queued, it joins the queue and
+// waits up to that long for a slot to free up. It
+// returns ErrTooManyOps if the wait times out, or the context's error if the
+// caller goes away first.
+func (t *Tracker) AcquireContext(ctx context.Context, op OpType) (release func(), err error) {
+	release, err = t.Acquire(op)
+	if err == nil || t.queueTimeout <= 0 {
+		return release, err
+	}
+
+	waiting := t.waiting[op]
+	metrics.AdmissionQueueLength.WithLabelValues(string(op)).Set(float64(waiting.Add(1)))
+	defer func() {
+		metrics.AdmissionQueueLength.WithLabelValues(string(op)).Set(float64(waiting.Add(-1)))
+	}()
+
+	start := time.Now()
+	timer := time.NewTimer(t.queueTimeout)
+	defer timer.Stop()
+
+	slots := t.slots[op]
+	select {
+	case slots <- struct{}{}:
+		metrics.AdmissionQueueWaitSeconds.WithLabelValues(string(op), "admitted").Observe(time.Since(start).Seconds())
+		return t.start(op, slots), nil
+	case <-timer.C:
+		metrics.AdmissionQueueWaitSeconds.WithLabelValues(string(op), "timeout").Observe(time.Since(start).Seconds())
+		return nil, ErrTooManyOps
+	case <-ctx.Done():
+		metrics.AdmissionQueueWaitSeconds.WithLabelValues(string(op), "cancelled").Observe(time.Since(start).Seconds())
+		return nil, ctx.Err()
+	}
+}
+
+func (t *Tracker) start(op OpType, slots chan struct{}) func() {
+	counter := t.counts[op]
+	counter.Add(1)
+	return func() {
+		counter.Add(-1)
+		if slots != nil {
+			<-slots
 		}
 	}
 }
@@ -74,3 +154,11 @@ func (t *Tracker) Counts() map[OpType]int64 {
 	}
 	return result
 }
+
+// Waiting returns the number of callers queued for a slot of the given type.
+func (t *Tracker) Waiting(op OpType) int64 {
+	if w := t.waiting[op]; w != nil {
+		return w.Load()
+	}
+	return 0
+}
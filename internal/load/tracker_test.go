@@ -1,6 +1,7 @@
 package load
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -190,3 +191,98 @@ func TestTrackerLimitUnderConcurrency(t *testing.T) {
 		t.Errorf("leaked operations: count = %d", tracker.Count(OpTypeLatency))
 	}
 }
+
+func TestTrackerAcquireContextRejectsWithoutQueue(t *testing.T) {
+	tracker := NewTracker(1)
+
+	release, err := tracker.AcquireContext(context.Background(), OpTypeCPU)
+	if err != nil {
+		t.Fatalf("Acquire error = %v", err)
+	}
+	defer release()
+
+	if _, err := tracker.AcquireContext(context.Background(), OpTypeCPU); err != ErrTooManyOps {
+		t.Errorf("second Acquire error = %v, want ErrTooManyOps", err)
+	}
+}
+
+func TestTrackerAcquireContextWaitsForSlot(t *testing.T) {
+	tracker := NewTracker(1)
+	tracker.SetQueueTimeout(time.Second)
+
+	release, err := tracker.Acquire(OpTypeCPU)
+	if err != nil {
+		t.Fatalf("Acquire error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		r, err := tracker.AcquireContext(context.Background(), OpTypeCPU)
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for tracker.Waiting(OpTypeCPU) != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := tracker.Waiting(OpTypeCPU); got != 1 {
+		t.Fatalf("Waiting() = %d, want 1", got)
+	}
+
+	release()
+	if err := <-done; err != nil {
+		t.Errorf("queued Acquire error = %v, want nil", err)
+	}
+	if got := tracker.Waiting(OpTypeCPU); got != 0 {
+		t.Errorf("Waiting() after admission = %d, want 0", got)
+	}
+}
+
+func TestTrackerAcquireDefersToWaiters(t *testing.T) {
+	tracker := NewTracker(2)
+	tracker.SetQueueTimeout(20 * time.Millisecond)
+
+	// A caller counted as queued owns the next free slot, even one that is
+	// free right now.
+	tracker.waiting[OpTypeCPU].Add(1)
+	if _, err := tracker.Acquire(OpTypeCPU); err != ErrTooManyOps {
+		t.Errorf("Acquire with a queued caller error = %v, want ErrTooManyOps", err)
+	}
+	release, err := tracker.AcquireContext(context.Background(), OpTypeCPU)
+	if err != nil {
+		t.Fatalf("AcquireContext with a free slot error = %v", err)
+	}
+	release()
+
+	tracker.waiting[OpTypeCPU].Add(-1)
+	release, err = tracker.Acquire(OpTypeCPU)
+	if err != nil {
+		t.Fatalf("Acquire with no queued callers error = %v", err)
+	}
+	release()
+}
+
+func TestTrackerAcquireContextTimeout(t *testing.T) {
+	tracker := NewTracker(1)
+	tracker.SetQueueTimeout(20 * time.Millisecond)
+
+	release, _ := tracker.Acquire(OpTypeIO)
+	defer release()
+
+	start := time.Now()
+	if _, err := tracker.AcquireContext(context.Background(), OpTypeIO); err != ErrTooManyOps {
+		t.Errorf("error = %v, want ErrTooManyOps", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("returned after %s, want at least the queue timeout", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tracker.AcquireContext(ctx, OpTypeIO); err != context.Canceled {
+		t.Errorf("cancelled error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewHandlerFormats(t *testing.T) {
+	level.Set(slog.LevelInfo)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatJSON, `"msg":"hello","key":"a b"`},
+		{FormatLogfmt, `msg=hello key="a b"`},
+		{FormatText, `INFO  hello grp.key="a b"`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		h, err := NewHandler(&buf, tt.format)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		logger := slog.New(h)
+		if tt.format == FormatText {
+			logger = logger.WithGroup("grp")
+		}
+		logger.Info("hello", "key", "a b")
+		logger.Debug("hidden")
+
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("%s: output %q does not contain %q", tt.format, buf.String(), tt.want)
+		}
+		if strings.Contains(buf.String(), "hidden") {
+			t.Errorf("%s: debug message logged at info level", tt.format)
+		}
+	}
+
+	if _, err := NewHandler(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	defer level.Set(slog.LevelInfo)
+
+	var buf bytes.Buffer
+	h, _ := NewHandler(&buf, FormatJSON)
+	logger := slog.New(h)
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("visible")
+	if Level() != "debug" || !strings.Contains(buf.String(), "visible") {
+		t.Errorf("level = %q, output = %q", Level(), buf.String())
+	}
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("expected error for invalid level")
+	}
+}
+
+func TestRotatingWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hotpod.log")
+	w, err := NewRotatingWriter(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for p, content := range want {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), b, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("expected at most 2 rotated files")
+	}
+}
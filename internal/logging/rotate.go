@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingWriter appends to a file and rotates it once it exceeds a size
+// limit, keeping a fixed number of older files named path.1, path.2, and so
+// on, with path.1 the most recent.
+type RotatingWriter struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewRotatingWriter opens path for appending. A maxSize of 0 disables
+// rotation.
+func NewRotatingWriter(path string, maxSize int64, maxFiles int) (*RotatingWriter, error) {
+	w := &RotatingWriter{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	w.f = f
+	w.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if p would push the file past the limit.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotatingWriter) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+
+	if w.maxFiles <= 0 {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing log file: %w", err)
+		}
+		return w.open()
+	}
+
+	os.Remove(w.backup(w.maxFiles))
+	for i := w.maxFiles - 1; i >= 1; i-- {
+		os.Rename(w.backup(i), w.backup(i+1))
+	}
+	if err := os.Rename(w.path, w.backup(1)); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	return w.open()
+}
+
+func (w *RotatingWriter) backup(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+// Close closes the current file.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Close()
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// textHandler writes human-oriented lines: a short timestamp, padded level,
+// the message, then key=value attributes.
+type textHandler struct {
+	opts   *slog.HandlerOptions
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	attrs  []byte
+}
+
+func newTextHandler(w io.Writer, opts *slog.HandlerOptions) *textHandler {
+	return &textHandler{opts: opts, mu: &sync.Mutex{}, w: w}
+}
+
+func (h *textHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.opts.Level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	if !r.Time.IsZero() {
+		buf.WriteString(r.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+		buf.WriteByte(' ')
+	}
+	fmt.Fprintf(&buf, "%-5s %s", r.Level.String(), r.Message)
+	buf.Write(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&buf, h.prefix, a)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var buf bytes.Buffer
+	buf.Write(h.attrs)
+	for _, a := range attrs {
+		appendAttr(&buf, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs = buf.Bytes()
+	return &h2
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+func appendAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		p := prefix
+		if a.Key != "" {
+			p += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(buf, p, ga)
+		}
+		return
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(prefix)
+	buf.WriteString(a.Key)
+	buf.WriteByte('=')
+
+	var s string
+	switch a.Value.Kind() {
+	case slog.KindTime:
+		s = a.Value.Time().Format(time.RFC3339Nano)
+	default:
+		s = a.Value.String()
+	}
+	if needsQuoting(s) {
+		s = strconv.Quote(s)
+	}
+	buf.WriteString(s)
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ripta/hotpod/internal/cgroup"
+)
+
+var (
+	memoryEventsDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "memory", "events_total"),
+		"Container cgroup memory events by type, from memory.events.", []string{"event"}, nil)
+	oomScoreAdjDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "oom_score_adj"),
+		"OOM killer score adjustment of the process.", nil, nil)
+)
+
+// cgroupCollector exports the container's memory events and OOM score
+// adjustment. Values that cannot be read are omitted rather than reported as
+// zero.
+type cgroupCollector struct {
+	root        string
+	oomScoreAdj string
+}
+
+func init() {
+	prometheus.MustRegister(&cgroupCollector{root: cgroup.DefaultRoot, oomScoreAdj: cgroup.DefaultOOMScoreAdjPath})
+}
+
+// Describe implements prometheus.Collector.
+func (c *cgroupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- memoryEventsDesc
+	ch <- oomScoreAdjDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *cgroupCollector) Collect(ch chan<- prometheus.Metric) {
+	if events, err := cgroup.ReadMemoryEvents(c.root); err == nil {
+		for event, n := range map[string]uint64{
+			"low":      events.Low,
+			"high":     events.High,
+			"max":      events.Max,
+			"oom":      events.OOM,
+			"oom_kill": events.OOMKill,
+		} {
+			ch <- prometheus.MustNewConstMetric(memoryEventsDesc, prometheus.CounterValue, float64(n), event)
+		}
+	}
+	if adj, err := cgroup.ReadOOMScoreAdj(c.oomScoreAdj); err == nil {
+		ch <- prometheus.MustNewConstMetric(oomScoreAdjDesc, prometheus.GaugeValue, float64(adj))
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCgroupCollector(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"memory.events": "low 0\nhigh 12\nmax 1\noom 0\noom_kill 0\n",
+		"oom_score_adj": "1000\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&cgroupCollector{root: dir, oomScoreAdj: filepath.Join(dir, "oom_score_adj")})
+
+	want := `
+# HELP hotpod_memory_events_total Container cgroup memory events by type, from memory.events.
+# TYPE hotpod_memory_events_total counter
+hotpod_memory_events_total{event="high"} 12
+hotpod_memory_events_total{event="low"} 0
+hotpod_memory_events_total{event="max"} 1
+hotpod_memory_events_total{event="oom"} 0
+hotpod_memory_events_total{event="oom_kill"} 0
+# HELP hotpod_oom_score_adj OOM killer score adjustment of the process.
+# TYPE hotpod_oom_score_adj gauge
+hotpod_oom_score_adj 1000
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	// Without cgroup files, nothing is reported.
+	empty := prometheus.NewRegistry()
+	empty.MustRegister(&cgroupCollector{root: t.TempDir(), oomScoreAdj: filepath.Join(t.TempDir(), "missing")})
+	if n, err := testutil.GatherAndCount(empty); err != nil || n != 0 {
+		t.Errorf("GatherAndCount() = %d, %v, want 0", n, err)
+	}
+}
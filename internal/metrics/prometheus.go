@@ -3,6 +3,10 @@ package metrics
 import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ripta/hotpod/internal/cgroup"
+	"github.com/ripta/hotpod/internal/rusage"
+	"github.com/ripta/hotpod/internal/wallclock"
 )
 
 // Namespace is the Prometheus metrics namespace for all hotpod metrics.
@@ -39,6 +43,37 @@ var (
 			Help:      "Number of HTTP requests currently being processed.",
 		},
 	)
+
+	// EndpointInFlight tracks currently processing requests by endpoint.
+	EndpointInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "endpoint_in_flight",
+			Help:      "Number of HTTP requests currently being processed by endpoint.",
+		},
+		[]string{"endpoint"},
+	)
+
+	// EndpointSaturation is in-flight requests by endpoint divided by
+	// MaxConcurrentOps.
+	EndpointSaturation = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "endpoint_saturation_ratio",
+			Help:      "In-flight requests by endpoint as a fraction of the max concurrent operations limit.",
+		},
+		[]string{"endpoint"},
+	)
+
+	// RequestsShedTotal counts requests rejected by priority-aware load shedding.
+	RequestsShedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "requests_shed_total",
+			Help:      "Total number of requests shed under load by priority and reason.",
+		},
+		[]string{"priority", "reason"},
+	)
 )
 
 // Resource consumption metrics track load generation operations.
@@ -100,6 +135,219 @@ var (
 			Help:      "Number of concurrent I/O operations.",
 		},
 	)
+
+	// AdmissionQueueLength tracks callers waiting for a concurrency slot.
+	AdmissionQueueLength = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "admission_queue_length",
+			Help:      "Number of operations waiting for a concurrency slot by operation type.",
+		},
+		[]string{"op"},
+	)
+
+	// AdmissionQueueWaitSeconds tracks time spent waiting for a concurrency slot.
+	AdmissionQueueWaitSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "admission_queue_wait_seconds",
+			Help:      "Time spent waiting for a concurrency slot by operation type and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"op", "outcome"},
+	)
+)
+
+// DNS metrics track lookups performed by the /dns endpoint.
+var (
+	// DNSLookupDuration tracks DNS lookup latency by record type and outcome.
+	DNSLookupDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "dns_lookup_duration_seconds",
+			Help:      "DNS lookup duration in seconds by record type and outcome.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		},
+		[]string{"type", "outcome"},
+	)
+)
+
+// Schedule metrics track the background load scheduler.
+var (
+	// ScheduleTarget tracks the scheduled target level by resource: cores for
+	// cpu, bytes for memory, and items for queue.
+	ScheduleTarget = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "schedule_target",
+			Help:      "Target level of the background load scheduler by resource.",
+		},
+		[]string{"resource"},
+	)
+)
+
+// Controller metrics track the HotpodProfile controller.
+var (
+	// ControllerApplyTotal counts attempts to apply a profile to a pod by result.
+	ControllerApplyTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "controller_apply_total",
+			Help:      "Total number of HotpodProfile applications to pods by result.",
+		},
+		[]string{"result"},
+	)
+)
+
+// Leader election metrics track the Lease-based leader election demo.
+var (
+	// LeaderIsLeader indicates whether this instance currently holds the lease (0 or 1).
+	LeaderIsLeader = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "leader_is_leader",
+			Help:      "Whether this instance holds the leader election lease (1) or not (0).",
+		},
+	)
+
+	// LeaderTransitionsTotal counts leadership changes seen by this instance.
+	LeaderTransitionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "leader_transitions_total",
+			Help:      "Total number of leadership changes by this instance by direction.",
+		},
+		[]string{"direction"},
+	)
+
+	// LeaderRenewFailuresTotal counts failed lease renewals while leader.
+	LeaderRenewFailuresTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "leader_renew_failures_total",
+			Help:      "Total number of failed lease renewals while holding leadership.",
+		},
+	)
+)
+
+// Node pressure metrics track the cooperative back-off from node pressure.
+var (
+	// NodePressureCondition indicates whether the node reports each pressure condition (0 or 1).
+	NodePressureCondition = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "node_pressure_condition",
+			Help:      "Whether the node reports the pressure condition (1) or not (0).",
+		},
+		[]string{"condition"},
+	)
+
+	// NodePressureBackoff indicates whether load is backed off for node pressure (0 or 1).
+	NodePressureBackoff = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "node_pressure_backoff",
+			Help:      "Whether hotpod is backing off its load because of node pressure (1) or not (0).",
+		},
+	)
+)
+
+// Session metrics track whether session requests land on the issuing pod.
+var (
+	// SessionRequestsTotal counts /session requests by result: new, same_pod, or other_pod.
+	SessionRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "session_requests_total",
+			Help:      "Total number of session requests by whether they landed on the issuing pod.",
+		},
+		[]string{"result"},
+	)
+)
+
+// Variant metrics label traffic with the deployment variant, so a
+// progressive delivery split is the ratio of each variant's request rate to
+// the total.
+var (
+	// VariantInfo is 1, labeled with the configured variant.
+	VariantInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "variant_info",
+			Help:      "The deployment variant of this instance, such as stable or canary.",
+		},
+		[]string{"variant"},
+	)
+
+	// VariantRequestsTotal counts requests by variant and status code,
+	// excluding probes, metrics, and admin endpoints.
+	VariantRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "variant_requests_total",
+			Help:      "Total number of requests served by deployment variant and status code.",
+		},
+		[]string{"variant", "code"},
+	)
+)
+
+// Protected metrics count /protected requests by outcome, to confirm an auth
+// layer in front of hotpod stops requests before they arrive.
+var (
+	// ProtectedRequestsTotal counts /protected requests by result: allowed,
+	// unauthorized, or forbidden.
+	ProtectedRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "protected_requests_total",
+			Help:      "Total number of protected endpoint requests by whether they were allowed.",
+		},
+		[]string{"result"},
+	)
+)
+
+// Mirror metrics count requests to the mirror endpoints, so a proxy's
+// mirroring percentage is the ratio of mirrored sink requests to source
+// requests.
+var (
+	// MirrorRequestsTotal counts /mirror requests by endpoint (source or
+	// sink) and whether they were mirrored copies.
+	MirrorRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "mirror_requests_total",
+			Help:      "Total number of mirror endpoint requests by endpoint and whether they were mirrored copies.",
+		},
+		[]string{"endpoint", "mirrored"},
+	)
+)
+
+// Burst metrics track the spacing of requests to /burst.
+var (
+	// BurstInterArrivalSeconds observes the time between consecutive /burst
+	// requests.
+	BurstInterArrivalSeconds = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "burst_inter_arrival_seconds",
+			Help:      "Time between consecutive burst endpoint requests in seconds.",
+			Buckets:   []float64{.0001, .0005, .001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+	)
+)
+
+// Endpoint gate metrics track which endpoint groups are exposed.
+var (
+	// EndpointGroupEnabled indicates whether each endpoint group is enabled
+	// (0 or 1).
+	EndpointGroupEnabled = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "endpoint_group_enabled",
+			Help:      "Whether an endpoint group is enabled (0 or 1).",
+		},
+		[]string{"group"},
+	)
 )
 
 // Lifecycle metrics track server startup and shutdown state.
@@ -122,6 +370,82 @@ var (
 		},
 	)
 
+	// ProfilesUploadedTotal counts continuous profile uploads by type and outcome.
+	ProfilesUploadedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "profiles_uploaded_total",
+			Help:      "Total number of continuous profiling uploads by profile type and outcome.",
+		},
+		[]string{"type", "outcome"},
+	)
+
+	// TimeSeconds reports hotpod's wall clock, including any configured skew,
+	// so it can be compared against the scrape timestamp.
+	TimeSeconds = promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "time_seconds",
+			Help:      "Current wall clock time as a Unix timestamp, including any simulated skew.",
+		},
+		func() float64 { return float64(wallclock.Now().UnixNano()) / 1e9 },
+	)
+
+	// ClockSkewSeconds reports the simulated clock skew.
+	ClockSkewSeconds = promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "clock_skew_seconds",
+			Help:      "Simulated offset of the reported wall clock from the node clock.",
+		},
+		func() float64 { return wallclock.Skew().Seconds() },
+	)
+
+	// CPUThrottledSecondsTotal reports the time the container's cgroup has
+	// been throttled by its CPU limit, or zero without cgroup statistics.
+	CPUThrottledSecondsTotal = promauto.NewCounterFunc(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "cpu_throttled_seconds_total",
+			Help:      "Total time the container was throttled by its CFS CPU quota.",
+		},
+		func() float64 {
+			stat, err := cgroup.ReadCPUStat(cgroup.DefaultRoot)
+			if err != nil {
+				return 0
+			}
+			return stat.Throttled.Seconds()
+		},
+	)
+
+	// MemoryMinorFaultsTotal reports the page faults this process resolved
+	// without I/O.
+	MemoryMinorFaultsTotal = promauto.NewCounterFunc(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "memory_minor_faults_total",
+			Help:      "Total minor page faults taken by the process.",
+		},
+		func() float64 {
+			faults, _ := rusage.PageFaults()
+			return float64(faults.Minor)
+		},
+	)
+
+	// MemoryMajorFaultsTotal reports the page faults that required I/O, such
+	// as swap-ins.
+	MemoryMajorFaultsTotal = promauto.NewCounterFunc(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "memory_major_faults_total",
+			Help:      "Total major page faults taken by the process.",
+		},
+		func() float64 {
+			faults, _ := rusage.PageFaults()
+			return float64(faults.Major)
+		},
+	)
+
 	// ShutdownInProgress indicates whether shutdown is in progress (0 or 1).
 	ShutdownInProgress = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -139,6 +463,66 @@ var (
 			Help:      "Unix timestamp when shutdown started (0 if not shutting down).",
 		},
 	)
+
+	// ShutdownPhase indicates the current shutdown phase (1 for the active phase).
+	ShutdownPhase = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "shutdown_phase",
+			Help:      "Current shutdown phase (1 for the active phase, 0 otherwise).",
+		},
+		[]string{"phase"},
+	)
+
+	// ShutdownPhaseDurationSeconds records how long each shutdown phase lasted.
+	ShutdownPhaseDurationSeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "shutdown_phase_duration_seconds",
+			Help:      "Time spent in each shutdown phase in seconds.",
+		},
+		[]string{"phase"},
+	)
+
+	// ShutdownInFlightRequests records in-flight requests when each shutdown phase began.
+	ShutdownInFlightRequests = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "shutdown_in_flight_requests",
+			Help:      "In-flight requests when each shutdown phase began.",
+		},
+		[]string{"phase"},
+	)
+
+	// ShutdownOpenConnections records open connections when each shutdown phase began.
+	ShutdownOpenConnections = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "shutdown_open_connections",
+			Help:      "Open client connections when each shutdown phase began.",
+		},
+		[]string{"phase"},
+	)
+
+	// ShutdownRequestsCompletedTotal counts requests that finished during each shutdown phase.
+	ShutdownRequestsCompletedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "shutdown_requests_completed_total",
+			Help:      "Total number of requests that completed during each shutdown phase.",
+		},
+		[]string{"phase"},
+	)
+
+	// OpenConnections tracks client connections by HTTP connection state.
+	OpenConnections = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "open_connections",
+			Help:      "Current client connections by state (active or idle).",
+		},
+		[]string{"state"},
+	)
 )
 
 // Fault injection metrics track chaos engineering operations.
@@ -162,6 +546,132 @@ var (
 		},
 		[]string{"endpoint"},
 	)
+
+	// FaultTLSHandshakeFaultsTotal counts TLS handshakes delayed, failed, or
+	// given a bad certificate by fault injection.
+	FaultTLSHandshakeFaultsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fault_tls_handshake_faults_total",
+			Help:      "Total number of TLS handshakes with injected faults.",
+		},
+		[]string{"fault"},
+	)
+
+	// FaultChaosTriggeredTotal counts faults triggered by the chaos schedule.
+	FaultChaosTriggeredTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fault_chaos_triggered_total",
+			Help:      "Total number of faults triggered by the chaos schedule.",
+		},
+		[]string{"kind"},
+	)
+
+	// FaultChaosActive is 1 while a chaos schedule fault of a kind is active.
+	FaultChaosActive = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "fault_chaos_active",
+			Help:      "Whether a chaos schedule fault is active, by kind.",
+		},
+		[]string{"kind"},
+	)
+
+	// FaultBrownoutLevel tracks how far a brownout has ramped, from 0 to 1.
+	FaultBrownoutLevel = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "fault_brownout_level",
+			Help:      "Fraction of peak latency and error rate a brownout applies.",
+		},
+	)
+
+	// FaultChaosRejectedTotal counts chaos actions rejected by guardrails.
+	FaultChaosRejectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fault_chaos_rejected_total",
+			Help:      "Total number of chaos actions rejected by blast radius guardrails.",
+		},
+		[]string{"action", "reason"},
+	)
+
+	// FaultHeaderFaultsInjectedTotal counts responses whose headers were
+	// modified by fault injection.
+	FaultHeaderFaultsInjectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fault_header_faults_injected_total",
+			Help:      "Total number of responses with injected header faults.",
+		},
+		[]string{"endpoint"},
+	)
+
+	// FaultBombsTotal counts response bombs sent by encoding.
+	FaultBombsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fault_bombs_total",
+			Help:      "Total number of oversized or compression bomb responses sent by encoding.",
+		},
+		[]string{"encoding"},
+	)
+
+	// FaultZombies tracks defunct child processes deliberately left unreaped.
+	FaultZombies = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "fault_zombies",
+			Help:      "Number of zombie child processes left unreaped by fault injection.",
+		},
+	)
+
+	// FaultThreadsHeld tracks OS threads pinned by the thread explosion fault.
+	FaultThreadsHeld = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "fault_threads_held",
+			Help:      "Number of OS threads pinned by thread explosion fault injection.",
+		},
+	)
+
+	// FaultPortConnectsTotal counts outbound connection attempts by the port exhaustion fault.
+	FaultPortConnectsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fault_port_connects_total",
+			Help:      "Total outbound connection attempts by the port exhaustion fault by outcome.",
+		},
+		[]string{"outcome"},
+	)
+
+	// FaultDeadlockedGoroutines tracks goroutines deadlocked by fault injection.
+	FaultDeadlockedGoroutines = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "fault_deadlocked_goroutines",
+			Help:      "Number of goroutines deliberately deadlocked by fault injection.",
+		},
+	)
+
+	// FaultContentionAcquisitionsTotal counts lock acquisitions by the contention fault.
+	FaultContentionAcquisitionsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fault_contention_acquisitions_total",
+			Help:      "Total number of mutex acquisitions by the contention fault.",
+		},
+	)
+
+	// FaultContentionWaitSecondsTotal accumulates time spent waiting for the contended mutex.
+	FaultContentionWaitSecondsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "fault_contention_wait_seconds_total",
+			Help:      "Total time spent waiting to acquire the contended mutex in seconds.",
+		},
+	)
 )
 
 // Sidecar metrics track resource consumption in sidecar mode.
@@ -184,12 +694,23 @@ var (
 		},
 	)
 
-	// SidecarMode indicates whether sidecar mode is active (0 or 1).
+	// SidecarProxyRequestsTotal counts requests forwarded by the sidecar proxy.
+	SidecarProxyRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "sidecar_proxy_requests_total",
+			Help:      "Total requests forwarded by the sidecar proxy.",
+		},
+		[]string{"code"},
+	)
+
+	// SidecarMode indicates whether the sidecar runner is active (0 or 1), in
+	// sidecar or combined mode.
 	SidecarMode = promauto.NewGauge(
 		prometheus.GaugeOpts{
 			Namespace: Namespace,
 			Name:      "sidecar_mode",
-			Help:      "Whether the server is running in sidecar mode (0 or 1).",
+			Help:      "Whether the server is running in sidecar or combined mode (0 or 1).",
 		},
 	)
 )
@@ -242,6 +763,37 @@ var (
 		},
 	)
 
+	// QueueItemsProcessedByPriorityTotal counts items successfully processed
+	// by priority.
+	QueueItemsProcessedByPriorityTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "queue_items_processed_by_priority_total",
+			Help:      "Total number of items processed successfully by priority.",
+		},
+		[]string{"priority"},
+	)
+
+	// QueueItemsFailedByPriorityTotal counts items that failed processing by
+	// priority.
+	QueueItemsFailedByPriorityTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "queue_items_failed_by_priority_total",
+			Help:      "Total number of items that failed processing by priority.",
+		},
+		[]string{"priority"},
+	)
+
+	// QueueWorkers tracks the number of running workers, busy or idle.
+	QueueWorkers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "queue_workers",
+			Help:      "Number of running queue workers, busy or idle.",
+		},
+	)
+
 	// QueueActiveWorkers tracks the number of workers currently processing items.
 	QueueActiveWorkers = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -261,6 +813,64 @@ var (
 		},
 	)
 
+	// QueueProcessingByPrioritySeconds tracks item processing duration by
+	// priority.
+	QueueProcessingByPrioritySeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "queue_processing_by_priority_seconds",
+			Help:      "Time spent processing queue items by priority.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"priority"},
+	)
+
+	// QueueWorkerFaultsTotal counts faults injected into queue workers
+	// through /admin/queue/fault, by fault kind. Duplicates count items.
+	QueueWorkerFaultsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "queue_worker_faults_total",
+			Help:      "Total number of faults injected into queue workers by fault.",
+		},
+		[]string{"fault"},
+	)
+
+	// WorkDownstreamSeconds tracks the outbound call phase of /work profiles.
+	WorkDownstreamSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "work_downstream_seconds",
+			Help:      "Duration of /work downstream calls by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"outcome"},
+	)
+
+	// QueueBatchSize tracks how many items workers dequeue at once.
+	QueueBatchSize = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "queue_batch_size",
+			Help:      "Number of items processed together in each worker batch.",
+			Buckets:   []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+		},
+	)
+
+	// QueueWaitSeconds tracks how long items wait to be dequeued, from when
+	// they were enqueued or, for delayed items, became due. Buckets reach ten
+	// minutes since a starved priority can wait far longer than processing
+	// takes.
+	QueueWaitSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "queue_wait_seconds",
+			Help:      "Time items wait in the queue before processing by priority.",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+		},
+		[]string{"priority"},
+	)
+
 	// QueueOldestItemAgeSeconds tracks the age of the oldest item in the queue.
 	QueueOldestItemAgeSeconds = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -270,3 +880,106 @@ var (
 		},
 	)
 )
+
+// Custom metrics API metrics track values served to the HPA.
+var (
+	// SyntheticUtilization is the value reported through the custom metrics
+	// API, set through POST /admin/custom-metrics.
+	SyntheticUtilization = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "synthetic_utilization",
+			Help:      "Synthetic utilization reported through the custom metrics API.",
+		},
+	)
+)
+
+// SLO metrics track hotpod's own traffic against its configured objectives.
+var (
+	// SLOObjective is the configured objective by SLI.
+	SLOObjective = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "slo_objective",
+			Help:      "Configured SLO objective by SLI.",
+		},
+		[]string{"sli"},
+	)
+
+	// SLOEventsTotal counts requests by SLI and whether they met it.
+	SLOEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "slo_events_total",
+			Help:      "Total number of requests counted toward an SLI by result.",
+		},
+		[]string{"sli", "result"},
+	)
+
+	// SLOErrorBudgetRemaining is the fraction of the error budget left over
+	// the SLO period; negative once the objective is missed.
+	SLOErrorBudgetRemaining = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "slo_error_budget_remaining",
+			Help:      "Fraction of the error budget remaining over the SLO period by SLI.",
+		},
+		[]string{"sli"},
+	)
+
+	// SLOBurnRate is how fast the error budget is spent over a window,
+	// relative to spending it exactly over the SLO period.
+	SLOBurnRate = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "slo_burn_rate",
+			Help:      "Error budget burn rate by SLI and window.",
+		},
+		[]string{"sli", "window"},
+	)
+)
+
+// Probe metrics track the synthetic prober, in the style of the blackbox
+// exporter.
+var (
+	// ProbeSuccess is 1 if the latest probe of a target succeeded.
+	ProbeSuccess = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "probe_success",
+			Help:      "Whether the latest probe of a target succeeded.",
+		},
+		[]string{"target"},
+	)
+
+	// ProbeDurationSeconds is how long the latest probe of a target took.
+	ProbeDurationSeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "probe_duration_seconds",
+			Help:      "Duration of the latest probe of a target in seconds.",
+		},
+		[]string{"target"},
+	)
+
+	// ProbeHTTPStatusCode is the status of the latest probe of a target, or
+	// 0 if no response was received.
+	ProbeHTTPStatusCode = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "probe_http_status_code",
+			Help:      "HTTP status code of the latest probe of a target.",
+		},
+		[]string{"target"},
+	)
+
+	// ProbesTotal counts probes by target and result.
+	ProbesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "probes_total",
+			Help:      "Total number of probes by target and result.",
+		},
+		[]string{"target", "result"},
+	)
+)
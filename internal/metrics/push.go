@@ -0,0 +1,257 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/ripta/hotpod/internal/wallclock"
+)
+
+// Push modes supported by Pusher.
+const (
+	PushModePushgateway = "pushgateway"
+	PushModeRemoteWrite = "remote_write"
+)
+
+// Pusher periodically pushes gathered metrics to a Pushgateway or a
+// Prometheus remote_write endpoint, for clusters without scrape
+// infrastructure.
+type Pusher struct {
+	mode     string
+	url      string
+	job      string
+	instance string
+	interval time.Duration
+	gatherer prometheus.Gatherer
+	client   *http.Client
+}
+
+// NewPusher creates a Pusher that gathers from the default registry.
+func NewPusher(mode, url, job string, interval time.Duration) *Pusher {
+	instance, err := os.Hostname()
+	if err != nil || instance == "" {
+		instance = "unknown"
+	}
+
+	return &Pusher{
+		mode:     mode,
+		url:      url,
+		job:      job,
+		instance: instance,
+		interval: interval,
+		gatherer: prometheus.DefaultGatherer,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Start pushes metrics every interval until the context is cancelled. A final
+// push is attempted on cancellation so short-lived runs still report.
+func (p *Pusher) Start(ctx context.Context) {
+	slog.Info("metrics pusher started", "mode", p.mode, "url", p.url, "interval", p.interval)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			finalCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := p.Push(finalCtx); err != nil {
+				slog.Warn("final metrics push failed", "error", err)
+			}
+			cancel()
+			slog.Info("metrics pusher stopped")
+			return
+		case <-ticker.C:
+			if err := p.Push(ctx); err != nil {
+				slog.Warn("metrics push failed", "mode", p.mode, "error", err)
+			}
+		}
+	}
+}
+
+// Push performs a single push using the configured mode.
+func (p *Pusher) Push(ctx context.Context) error {
+	switch p.mode {
+	case PushModePushgateway:
+		return push.New(p.url, p.job).
+			Gatherer(p.gatherer).
+			Grouping("instance", p.instance).
+			Client(p.client).
+			PushContext(ctx)
+	case PushModeRemoteWrite:
+		return p.remoteWrite(ctx)
+	default:
+		return fmt.Errorf("unknown push mode %q", p.mode)
+	}
+}
+
+func (p *Pusher) remoteWrite(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics: %w", err)
+	}
+
+	body := snappyEncode(encodeWriteRequest(families, p.job, p.instance, wallclock.Now()))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote_write returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// sample is a single flattened remote_write series value.
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// flattenFamilies converts gathered metric families into remote_write series,
+// expanding histograms and summaries into their _bucket/_sum/_count parts.
+func flattenFamilies(families []*dto.MetricFamily, job, instance string) []sample {
+	var out []sample
+
+	for _, mf := range families {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			base := [][2]string{{"job", job}, {"instance", instance}}
+			for _, lp := range m.GetLabel() {
+				base = append(base, [2]string{lp.GetName(), lp.GetValue()})
+			}
+
+			add := func(metricName string, value float64, extra ...[2]string) {
+				labels := make([][2]string, 0, len(base)+len(extra)+1)
+				labels = append(labels, [2]string{"__name__", metricName})
+				labels = append(labels, base...)
+				labels = append(labels, extra...)
+				sort.Slice(labels, func(i, j int) bool { return labels[i][0] < labels[j][0] })
+				out = append(out, sample{labels: labels, value: value})
+			}
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					add(name+"_bucket", float64(b.GetCumulativeCount()), [2]string{"le", formatFloat(b.GetUpperBound())})
+				}
+				add(name+"_bucket", float64(h.GetSampleCount()), [2]string{"le", "+Inf"})
+				add(name+"_sum", h.GetSampleSum())
+				add(name+"_count", float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add(name, q.GetValue(), [2]string{"quantile", formatFloat(q.GetQuantile())})
+				}
+				add(name+"_sum", s.GetSampleSum())
+				add(name+"_count", float64(s.GetSampleCount()))
+			}
+		}
+	}
+
+	return out
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequest encodes a prometheus.WriteRequest protobuf message.
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label        { string name = 1; string value = 2; }
+//	message Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(families []*dto.MetricFamily, job, instance string, now time.Time) []byte {
+	ts := now.UnixMilli()
+
+	var out []byte
+	for _, s := range flattenFamilies(families, job, instance) {
+		var series []byte
+		for _, l := range s.labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, l[0])
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, l[1])
+
+			series = protowire.AppendTag(series, 1, protowire.BytesType)
+			series = protowire.AppendBytes(series, label)
+		}
+
+		var smp []byte
+		smp = protowire.AppendTag(smp, 1, protowire.Fixed64Type)
+		smp = protowire.AppendFixed64(smp, math.Float64bits(s.value))
+		smp = protowire.AppendTag(smp, 2, protowire.VarintType)
+		smp = protowire.AppendVarint(smp, uint64(ts))
+
+		series = protowire.AppendTag(series, 2, protowire.BytesType)
+		series = protowire.AppendBytes(series, smp)
+
+		out = protowire.AppendTag(out, 1, protowire.BytesType)
+		out = protowire.AppendBytes(out, series)
+	}
+
+	return out
+}
+
+// snappyEncode produces a valid snappy block-format stream made entirely of
+// literal elements. It does not compress, but every snappy decoder accepts it,
+// which avoids pulling in a compression dependency for remote_write.
+func snappyEncode(src []byte) []byte {
+	const maxLiteral = 1 << 16
+
+	dst := binary.AppendUvarint(nil, uint64(len(src)))
+	for len(src) > 0 {
+		n := min(len(src), maxLiteral)
+		switch {
+		case n <= 60:
+			dst = append(dst, byte(n-1)<<2)
+		case n <= 1<<8:
+			dst = append(dst, 60<<2, byte(n-1))
+		default:
+			dst = append(dst, 61<<2, byte(n-1), byte((n-1)>>8))
+		}
+		dst = append(dst, src[:n]...)
+		src = src[n:]
+	}
+	return dst
+}
@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// snappyDecodeLiterals decodes the literal-only streams produced by snappyEncode.
+func snappyDecodeLiterals(t *testing.T, src []byte) []byte {
+	t.Helper()
+
+	n, w := binary.Uvarint(src)
+	if w <= 0 {
+		t.Fatal("invalid snappy length prefix")
+	}
+	src = src[w:]
+
+	var out []byte
+	for len(src) > 0 {
+		tag := src[0]
+		if tag&3 != 0 {
+			t.Fatalf("unexpected non-literal tag %#x", tag)
+		}
+		var length int
+		switch tag >> 2 {
+		case 60:
+			length = int(src[1]) + 1
+			src = src[2:]
+		case 61:
+			length = int(src[1]) | int(src[2])<<8 + 1
+			src = src[3:]
+		default:
+			length = int(tag>>2) + 1
+			src = src[1:]
+		}
+		out = append(out, src[:length]...)
+		src = src[length:]
+	}
+
+	if uint64(len(out)) != n {
+		t.Fatalf("decoded %d bytes, header says %d", len(out), n)
+	}
+	return out
+}
+
+func TestSnappyEncodeRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 60, 61, 256, 257, 70000} {
+		src := bytes.Repeat([]byte("x"), size)
+		got := snappyDecodeLiterals(t, snappyEncode(src))
+		if !bytes.Equal(got, src) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func newTestRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "test"})
+	c.Add(3)
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Help: "test", Buckets: []float64{1}})
+	h.Observe(0.5)
+	reg.MustRegister(c, h)
+	return reg
+}
+
+// seriesNames extracts the __name__ label of every series in a WriteRequest.
+func seriesNames(t *testing.T, b []byte) []string {
+	t.Helper()
+
+	var names []string
+	for len(b) > 0 {
+		_, _, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		series, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			t.Fatal("invalid timeseries")
+		}
+		b = b[n:]
+
+		for len(series) > 0 {
+			num, _, n := protowire.ConsumeTag(series)
+			series = series[n:]
+			msg, n := protowire.ConsumeBytes(series)
+			series = series[n:]
+			if num != 1 {
+				continue
+			}
+			_, _, n = protowire.ConsumeTag(msg)
+			key, n2 := protowire.ConsumeString(msg[n:])
+			msg = msg[n+n2:]
+			_, _, n = protowire.ConsumeTag(msg)
+			val, _ := protowire.ConsumeString(msg[n:])
+			if key == "__name__" {
+				names = append(names, val)
+			}
+		}
+	}
+	return names
+}
+
+func TestPusherRemoteWrite(t *testing.T) {
+	var body []byte
+	var headers http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	p := NewPusher(PushModeRemoteWrite, srv.URL, "hotpod", time.Second)
+	p.gatherer = newTestRegistry()
+
+	if err := p.Push(context.Background()); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	if headers.Get("Content-Encoding") != "snappy" {
+		t.Errorf("Content-Encoding = %q, want snappy", headers.Get("Content-Encoding"))
+	}
+
+	names := strings.Join(seriesNames(t, snappyDecodeLiterals(t, body)), ",")
+	for _, want := range []string{"test_total", "test_seconds_bucket", "test_seconds_sum", "test_seconds_count"} {
+		if !strings.Contains(names, want) {
+			t.Errorf("series %q missing from %s", want, names)
+		}
+	}
+}
+
+func TestPusherRemoteWriteError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	p := NewPusher(PushModeRemoteWrite, srv.URL, "hotpod", time.Second)
+	p.gatherer = newTestRegistry()
+
+	if err := p.Push(context.Background()); err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
+
+func TestPusherPushgateway(t *testing.T) {
+	var method, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	p := NewPusher(PushModePushgateway, srv.URL, "hotpod", time.Second)
+	p.gatherer = newTestRegistry()
+	p.instance = "pod-0"
+
+	if err := p.Push(context.Background()); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", method)
+	}
+	if path != "/metrics/job/hotpod/instance/pod-0" {
+		t.Errorf("path = %s", path)
+	}
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"math"
+	rtmetrics "runtime/metrics"
+	"runtime/pprof"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// runtimeLatencyBuckets are the upper bounds, in seconds, that runtime
+// latency histograms are folded into. The runtime's own buckets are too fine
+// grained and version dependent to export directly.
+var runtimeLatencyBuckets = []float64{
+	1e-6, 5e-6, 10e-6, 25e-6, 50e-6, 100e-6, 250e-6, 500e-6,
+	1e-3, 2.5e-3, 5e-3, 10e-3, 25e-3, 50e-3, 100e-3, 250e-3, 500e-3, 1,
+}
+
+// runtimeMetric maps a runtime/metrics sample to a hotpod_go_* metric.
+type runtimeMetric struct {
+	// runtime is the runtime/metrics name
+	runtime string
+	desc    *prometheus.Desc
+	// kind is used for scalar samples; histograms are detected by value kind
+	kind prometheus.ValueType
+}
+
+func newRuntimeMetric(runtimeName, name, help string, kind prometheus.ValueType) runtimeMetric {
+	return runtimeMetric{
+		runtime: runtimeName,
+		desc:    prometheus.NewDesc(prometheus.BuildFQName(Namespace, "go", name), help, nil, nil),
+		kind:    kind,
+	}
+}
+
+// runtimeMetrics are exported with stable names regardless of Go version; any
+// the running version does not support are skipped.
+var runtimeMetrics = []runtimeMetric{
+	newRuntimeMetric("/sched/latencies:seconds", "sched_latency_seconds",
+		"Time goroutines spent runnable before running (approximate sum).", prometheus.UntypedValue),
+	newRuntimeMetric("/sched/pauses/total/gc:seconds", "gc_pause_seconds",
+		"Stop-the-world pause latencies caused by the garbage collector (approximate sum).", prometheus.UntypedValue),
+	newRuntimeMetric("/sched/goroutines:goroutines", "goroutines",
+		"Number of live goroutines.", prometheus.GaugeValue),
+	newRuntimeMetric("/sched/gomaxprocs:threads", "gomaxprocs",
+		"Current GOMAXPROCS setting.", prometheus.GaugeValue),
+	newRuntimeMetric("/gc/cycles/total:gc-cycles", "gc_cycles_total",
+		"Number of completed GC cycles.", prometheus.CounterValue),
+	newRuntimeMetric("/gc/heap/goal:bytes", "gc_heap_goal_bytes",
+		"Heap size target for the end of the current GC cycle.", prometheus.GaugeValue),
+	newRuntimeMetric("/gc/heap/objects:objects", "heap_objects",
+		"Number of objects, live or unswept, occupying heap memory.", prometheus.GaugeValue),
+	newRuntimeMetric("/gc/heap/allocs:bytes", "heap_allocs_bytes_total",
+		"Cumulative bytes allocated on the heap.", prometheus.CounterValue),
+	newRuntimeMetric("/gc/heap/allocs:objects", "heap_allocs_objects_total",
+		"Cumulative objects allocated on the heap.", prometheus.CounterValue),
+	newRuntimeMetric("/memory/classes/total:bytes", "memory_mapped_bytes",
+		"All memory mapped by the Go runtime.", prometheus.GaugeValue),
+	newRuntimeMetric("/sync/mutex/wait/total:seconds", "mutex_wait_seconds_total",
+		"Cumulative time goroutines spent blocked on sync.Mutex or sync.RWMutex.", prometheus.CounterValue),
+}
+
+// threadsDesc reports OS threads created, like the default go_threads.
+var threadsDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "go", "threads"),
+	"Number of OS threads created.", nil, nil)
+
+// runtimeCollector exports runtime/metrics values under hotpod_go_*.
+type runtimeCollector struct {
+	mu      sync.Mutex
+	metrics []runtimeMetric
+	samples []rtmetrics.Sample
+}
+
+func newRuntimeCollector() *runtimeCollector {
+	supported := make(map[string]bool)
+	for _, d := range rtmetrics.All() {
+		supported[d.Name] = true
+	}
+
+	c := &runtimeCollector{}
+	for _, m := range runtimeMetrics {
+		if supported[m.runtime] {
+			c.metrics = append(c.metrics, m)
+			c.samples = append(c.samples, rtmetrics.Sample{Name: m.runtime})
+		}
+	}
+	return c
+}
+
+func init() {
+	prometheus.MustRegister(newRuntimeCollector())
+}
+
+// Describe implements prometheus.Collector.
+func (c *runtimeCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics {
+		ch <- m.desc
+	}
+	ch <- threadsDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *runtimeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rtmetrics.Read(c.samples)
+	for i, s := range c.samples {
+		m := c.metrics[i]
+		switch s.Value.Kind() {
+		case rtmetrics.KindUint64:
+			ch <- prometheus.MustNewConstMetric(m.desc, m.kind, float64(s.Value.Uint64()))
+		case rtmetrics.KindFloat64:
+			ch <- prometheus.MustNewConstMetric(m.desc, m.kind, s.Value.Float64())
+		case rtmetrics.KindFloat64Histogram:
+			count, sum, buckets := foldHistogram(s.Value.Float64Histogram(), runtimeLatencyBuckets)
+			ch <- prometheus.MustNewConstHistogram(m.desc, count, sum, buckets)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(threadsDesc, prometheus.GaugeValue, float64(pprof.Lookup("threadcreate").Count()))
+}
+
+// foldHistogram folds a runtime histogram into cumulative counts for the given
+// upper bounds. A runtime bucket is counted against the first bound at or
+// above its upper edge, so latencies are never under-reported. The runtime
+// does not track sums, so the sum is approximated from bucket lower edges.
+func foldHistogram(h *rtmetrics.Float64Histogram, bounds []float64) (count uint64, sum float64, buckets map[float64]uint64) {
+	buckets = make(map[float64]uint64, len(bounds))
+	for i, n := range h.Counts {
+		if n == 0 {
+			continue
+		}
+		count += n
+
+		lower, upper := h.Buckets[i], h.Buckets[i+1]
+		if !math.IsInf(lower, -1) {
+			sum += float64(n) * lower
+		}
+		for _, b := range bounds {
+			if upper <= b {
+				buckets[b] += n
+			}
+		}
+	}
+	return count, sum, buckets
+}
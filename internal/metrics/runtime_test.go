@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"math"
+	rtmetrics "runtime/metrics"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestFoldHistogram(t *testing.T) {
+	h := &rtmetrics.Float64Histogram{
+		Counts:  []uint64{1, 2, 3, 4},
+		Buckets: []float64{math.Inf(-1), 1e-6, 2e-6, 1e-3, math.Inf(1)},
+	}
+
+	count, sum, buckets := foldHistogram(h, []float64{1e-6, 1e-3, 1})
+
+	if count != 10 {
+		t.Errorf("count = %d, want 10", count)
+	}
+	want := map[float64]uint64{1e-6: 1, 1e-3: 6, 1: 6}
+	for b, n := range want {
+		if buckets[b] != n {
+			t.Errorf("bucket %g = %d, want %d", b, buckets[b], n)
+		}
+	}
+	if wantSum := 2*1e-6 + 3*2e-6 + 4*1e-3; math.Abs(sum-wantSum) > 1e-12 {
+		t.Errorf("sum = %g, want %g", sum, wantSum)
+	}
+}
+
+func TestRuntimeCollector(t *testing.T) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]bool)
+	for _, mf := range families {
+		found[mf.GetName()] = true
+		if mf.GetName() == "hotpod_go_goroutines" && mf.GetMetric()[0].GetGauge().GetValue() < 1 {
+			t.Error("hotpod_go_goroutines should be at least 1")
+		}
+	}
+
+	for _, name := range []string{
+		"hotpod_go_sched_latency_seconds",
+		"hotpod_go_gc_pause_seconds",
+		"hotpod_go_goroutines",
+		"hotpod_go_threads",
+		"hotpod_go_heap_objects",
+	} {
+		if !found[name] {
+			t.Errorf("metric %s not exported", name)
+		}
+	}
+}
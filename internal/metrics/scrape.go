@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Scrape cost limits.
+const (
+	// MaxScrapeDelay bounds the /metrics delay, including jitter.
+	MaxScrapeDelay = 5 * time.Minute
+	// MaxScrapePadding bounds the bytes of padding series added to /metrics.
+	MaxScrapePadding = 64 << 20
+)
+
+// paddingSeriesBytes is the approximate text exposition size of one padding
+// series, so the payload grows by about the configured padding.
+const paddingSeriesBytes = 256
+
+var paddingDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(Namespace, "scrape", "padding"),
+	"Synthetic series inflating the /metrics payload.",
+	[]string{"series", "pad"}, nil,
+)
+
+// paddingLabel fills each padding series out to paddingSeriesBytes; the
+// series label is a fixed-width index.
+var paddingLabel = strings.Repeat("x",
+	paddingSeriesBytes-len(`hotpod_scrape_padding{pad="",series="000000"} 0`+"\n"))
+
+// ScrapeCost makes /metrics slow and large, to exercise Prometheus scrape
+// timeouts and body size limits. It is a collector whose padding series are
+// only registered with the /metrics handler, not pushed. It is safe for
+// concurrent use.
+type ScrapeCost struct {
+	mu      sync.Mutex
+	delay   time.Duration
+	jitter  time.Duration
+	padding int64
+}
+
+// NewScrapeCost creates a scrape cost with no delay and no padding.
+func NewScrapeCost() *ScrapeCost {
+	return &ScrapeCost{}
+}
+
+// Set replaces the delay, jitter, and padding bytes. All zero disables the
+// scrape cost.
+func (s *ScrapeCost) Set(delay, jitter time.Duration, padding int64) error {
+	if delay < 0 || jitter < 0 {
+		return errors.New("delay and jitter must be non-negative")
+	}
+	if delay+jitter > MaxScrapeDelay {
+		return fmt.Errorf("delay plus jitter must be at most %s", MaxScrapeDelay)
+	}
+	if padding < 0 || padding > MaxScrapePadding {
+		return fmt.Errorf("padding must be between 0 and %d bytes", MaxScrapePadding)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay, s.jitter, s.padding = delay, jitter, padding
+	return nil
+}
+
+// Get returns the delay, jitter, and padding bytes. It is safe to call on a
+// nil receiver, which has no scrape cost.
+func (s *ScrapeCost) Get() (delay, jitter time.Duration, padding int64) {
+	if s == nil {
+		return 0, 0, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.delay, s.jitter, s.padding
+}
+
+// Wait blocks for the configured delay, returning early if ctx is done, as
+// when Prometheus gives up on the scrape.
+func (s *ScrapeCost) Wait(ctx context.Context) {
+	delay, jitter, _ := s.Get()
+	if jitter > 0 {
+		delay += time.Duration(rand.Int64N(int64(jitter)))
+	}
+	if delay <= 0 {
+		return
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// Describe implements prometheus.Collector. Padding series vary in number,
+// so the collector is unchecked.
+func (s *ScrapeCost) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector, emitting enough padding series to
+// add about the configured padding bytes.
+func (s *ScrapeCost) Collect(ch chan<- prometheus.Metric) {
+	_, _, padding := s.Get()
+	n := (padding + paddingSeriesBytes - 1) / paddingSeriesBytes
+	for i := range n {
+		ch <- prometheus.MustNewConstMetric(paddingDesc, prometheus.GaugeValue, 0, fmt.Sprintf("%06d", i), paddingLabel)
+	}
+}
@@ -0,0 +1,182 @@
+// Package nodepressure watches the node hotpod runs on for resource pressure
+// conditions, so long-running soak tests can back off their own load
+// instead of contributing to kubelet evictions.
+package nodepressure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Conditions are the node conditions that trigger a back-off while True.
+var Conditions = []string{"MemoryPressure", "DiskPressure"}
+
+// node is the subset of a core/v1 Node the watcher reads.
+type node struct {
+	Status struct {
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// Watcher polls a node's conditions and reports whether hotpod should back
+// off. Backing off can be toggled at runtime without stopping the polling,
+// so the node's conditions stay visible either way.
+type Watcher struct {
+	client   *kube.Client
+	node     string
+	interval time.Duration
+
+	enabled atomic.Bool
+
+	mu        sync.Mutex
+	pressure  []string
+	lastCheck time.Time
+	lastErr   string
+}
+
+// New creates a watcher for the named node, polled every interval. Backing
+// off starts enabled.
+func New(client *kube.Client, nodeName string, interval time.Duration) *Watcher {
+	w := &Watcher{client: client, node: nodeName, interval: interval}
+	w.enabled.Store(true)
+	return w
+}
+
+// Interval returns how often the node is polled.
+func (w *Watcher) Interval() time.Duration {
+	return w.interval
+}
+
+// SetEnabled turns backing off on or off.
+func (w *Watcher) SetEnabled(enabled bool) {
+	w.enabled.Store(enabled)
+	w.updateMetrics()
+}
+
+// BackingOff reports whether backing off is enabled and the node reports
+// pressure. It is false for a nil watcher.
+func (w *Watcher) BackingOff() bool {
+	if w == nil || !w.enabled.Load() {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pressure) > 0
+}
+
+// Status returns the watcher's view of the node.
+func (w *Watcher) Status() api.NodePressureStatus {
+	backingOff := w.BackingOff()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := api.NodePressureStatus{
+		Node:       w.node,
+		Enabled:    w.enabled.Load(),
+		BackingOff: backingOff,
+		Conditions: slices.Clone(w.pressure),
+		LastError:  w.lastErr,
+	}
+	if !w.lastCheck.IsZero() {
+		t := w.lastCheck
+		status.LastCheck = &t
+	}
+	return status
+}
+
+// Run polls the node every interval until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	slog.Info("node pressure watcher started", "node", w.node, "interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		reqCtx, cancel := context.WithTimeout(ctx, w.interval)
+		if err := w.Check(reqCtx); err != nil {
+			slog.Warn("failed to check node pressure", "node", w.node, "error", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			slog.Info("node pressure watcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check fetches the node once and updates the pressure conditions. On
+// failure the last known conditions are kept.
+func (w *Watcher) Check(ctx context.Context) error {
+	var n node
+	err := w.client.Do(ctx, "GET", fmt.Sprintf("/api/v1/nodes/%s", w.node), nil, &n)
+
+	w.mu.Lock()
+	w.lastCheck = time.Now()
+	if err != nil {
+		w.lastErr = err.Error()
+		w.mu.Unlock()
+		return err
+	}
+	w.lastErr = ""
+
+	var pressure []string
+	for _, c := range n.Status.Conditions {
+		if c.Status == "True" && slices.Contains(Conditions, c.Type) {
+			pressure = append(pressure, c.Type)
+		}
+	}
+	slices.Sort(pressure)
+	prev := w.pressure
+	w.pressure = pressure
+	w.mu.Unlock()
+
+	w.updateMetrics()
+	if slices.Equal(prev, pressure) {
+		return nil
+	}
+	if len(pressure) > 0 {
+		slog.Warn("node reports pressure", "node", w.node, "conditions", pressure, "backing_off", w.enabled.Load())
+		events.Record(slog.LevelWarn, events.TypeLifecycle, "node reports pressure", map[string]any{
+			"node":        w.node,
+			"conditions":  pressure,
+			"backing_off": w.enabled.Load(),
+		})
+	} else {
+		slog.Info("node pressure cleared", "node", w.node)
+		events.Record(slog.LevelInfo, events.TypeLifecycle, "node pressure cleared", map[string]any{"node": w.node})
+	}
+	return nil
+}
+
+func (w *Watcher) updateMetrics() {
+	w.mu.Lock()
+	for _, c := range Conditions {
+		v := 0.0
+		if slices.Contains(w.pressure, c) {
+			v = 1
+		}
+		metrics.NodePressureCondition.WithLabelValues(c).Set(v)
+	}
+	w.mu.Unlock()
+
+	if w.BackingOff() {
+		metrics.NodePressureBackoff.Set(1)
+	} else {
+		metrics.NodePressureBackoff.Set(0)
+	}
+}
@@ -0,0 +1,83 @@
+package nodepressure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/kube"
+)
+
+// nodeServer serves node-1 with the conditions in body.
+func nodeServer(t *testing.T, body *atomic.Value) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/node-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body.Load().(string)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWatcherBacksOffUnderPressure(t *testing.T) {
+	var body atomic.Value
+	body.Store(`{"status":{"conditions":[{"type":"Ready","status":"True"},{"type":"DiskPressure","status":"True"},{"type":"MemoryPressure","status":"False"},{"type":"PIDPressure","status":"True"}]}}`)
+	w := New(kube.NewForTesting(nodeServer(t, &body).URL, "ns"), "node-1", time.Second)
+
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !w.BackingOff() {
+		t.Error("BackingOff() = false under disk pressure")
+	}
+	status := w.Status()
+	if !slices.Equal(status.Conditions, []string{"DiskPressure"}) || status.LastCheck == nil || status.Node != "node-1" {
+		t.Errorf("Status() = %+v, want node-1 under DiskPressure only", status)
+	}
+
+	w.SetEnabled(false)
+	if w.BackingOff() {
+		t.Error("BackingOff() = true while disabled")
+	}
+	w.SetEnabled(true)
+
+	body.Store(`{"status":{"conditions":[{"type":"DiskPressure","status":"False"}]}}`)
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if w.BackingOff() {
+		t.Error("BackingOff() = true after pressure cleared")
+	}
+}
+
+func TestWatcherKeepsStateOnError(t *testing.T) {
+	var body atomic.Value
+	body.Store(`{"status":{"conditions":[{"type":"MemoryPressure","status":"True"}]}}`)
+	srv := nodeServer(t, &body)
+	w := New(kube.NewForTesting(srv.URL, "ns"), "node-1", time.Second)
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	w.node = "node-2"
+	if err := w.Check(context.Background()); err == nil {
+		t.Fatal("Check() of a missing node should fail")
+	}
+	if !w.BackingOff() || w.Status().LastError == "" {
+		t.Errorf("Status() = %+v, want last known pressure and the error", w.Status())
+	}
+}
+
+func TestNilWatcher(t *testing.T) {
+	var w *Watcher
+	if w.BackingOff() {
+		t.Error("nil watcher should not back off")
+	}
+}
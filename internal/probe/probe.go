@@ -0,0 +1,182 @@
+// Package probe periodically calls local or remote endpoints and exports
+// blackbox-style success and latency metrics, so hotpod can double as a
+// lightweight in-cluster canary.
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Header marks requests sent by the prober so they can be told apart from
+// live traffic in logs.
+const Header = "X-Hotpod-Probe"
+
+// maxBody bounds how much of a response body is read before it is
+// discarded, so a large response does not inflate probe latency.
+const maxBody = 1 << 20
+
+// Target is an endpoint to probe.
+type Target struct {
+	// Name labels the target's metrics: the target as configured
+	Name string
+	URL  string
+	// Local is a path on the local server, which may present a self-signed
+	// certificate
+	Local bool
+}
+
+// ParseTargets parses a comma-separated list of targets. Each is an http or
+// https URL, or a path like /healthz on the local server at localURL.
+func ParseTargets(s, localURL string) ([]Target, error) {
+	var targets []Target
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		switch {
+		case v == "":
+			continue
+		case strings.HasPrefix(v, "/"):
+			targets = append(targets, Target{Name: v, URL: localURL + v, Local: true})
+		default:
+			u, err := url.Parse(v)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("probe targets must be paths or http(s) URLs, got %q", v)
+			}
+			targets = append(targets, Target{Name: v, URL: v})
+		}
+	}
+	return targets, nil
+}
+
+// Prober probes its targets every interval. Any 2xx response is a success.
+type Prober struct {
+	targets  []Target
+	interval time.Duration
+	local    *http.Client
+	remote   *http.Client
+
+	mu      sync.Mutex
+	results map[string]api.ProbeResult
+}
+
+// New creates a prober for targets, probed every interval, each probe
+// bounded by timeout.
+func New(targets []Target, interval, timeout time.Duration) *Prober {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return &Prober{
+		targets:  targets,
+		interval: interval,
+		local:    &http.Client{Timeout: timeout, Transport: transport},
+		remote:   &http.Client{Timeout: timeout},
+		results:  map[string]api.ProbeResult{},
+	}
+}
+
+// Interval returns how often the targets are probed.
+func (p *Prober) Interval() time.Duration {
+	return p.interval
+}
+
+// Results returns the latest result of each target, in configured order.
+// Targets not yet probed are omitted.
+func (p *Prober) Results() []api.ProbeResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	results := []api.ProbeResult{}
+	for _, t := range p.targets {
+		if r, ok := p.results[t.Name]; ok {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// Run probes every target every interval until ctx is cancelled.
+func (p *Prober) Run(ctx context.Context) {
+	slog.Info("prober started", "targets", len(p.targets), "interval", p.interval)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.ProbeAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("prober stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeAll probes every target concurrently and waits for them to finish.
+func (p *Prober) ProbeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range p.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.probe(ctx, t)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Prober) probe(ctx context.Context, t Target) {
+	client := p.remote
+	if t.Local {
+		client = p.local
+	}
+
+	start := time.Now()
+	result := api.ProbeResult{Target: t.Name, Time: start}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.URL, nil)
+	if err == nil {
+		req.Header.Set(Header, "true")
+		var resp *http.Response
+		if resp, err = client.Do(req); err == nil {
+			_, err = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBody))
+			resp.Body.Close()
+			result.StatusCode = resp.StatusCode
+		}
+	}
+	elapsed := time.Since(start)
+	result.Duration = elapsed.String()
+
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+	case result.StatusCode < 200 || result.StatusCode > 299:
+		result.Error = fmt.Sprintf("unexpected status %d", result.StatusCode)
+	default:
+		result.Success = true
+	}
+	if !result.Success {
+		slog.Debug("probe failed", "target", t.Name, "error", result.Error)
+	}
+
+	success, outcome := 0.0, "failure"
+	if result.Success {
+		success, outcome = 1, "success"
+	}
+	metrics.ProbeSuccess.WithLabelValues(t.Name).Set(success)
+	metrics.ProbeDurationSeconds.WithLabelValues(t.Name).Set(elapsed.Seconds())
+	metrics.ProbeHTTPStatusCode.WithLabelValues(t.Name).Set(float64(result.StatusCode))
+	metrics.ProbesTotal.WithLabelValues(t.Name, outcome).Inc()
+
+	p.mu.Lock()
+	p.results[t.Name] = result
+	p.mu.Unlock()
+}
@@ -0,0 +1,79 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTargets(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    []Target
+		wantErr bool
+	}{
+		{"", nil, false},
+		{
+			"/healthz, https://example.com/ping",
+			[]Target{
+				{Name: "/healthz", URL: "http://127.0.0.1:8080/healthz", Local: true},
+				{Name: "https://example.com/ping", URL: "https://example.com/ping"},
+			},
+			false,
+		},
+		{"healthz", nil, true},
+		{"ftp://example.com", nil, true},
+	} {
+		got, err := ParseTargets(tt.in, "http://127.0.0.1:8080")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTargets(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTargets(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestProber(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(Header) == "" {
+			t.Errorf("probe of %s is missing the %s header", r.URL.Path, Header)
+		}
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	targets, err := ParseTargets("/up,/down,http://127.0.0.1:1/closed", ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(targets, time.Minute, 5*time.Second)
+	if got := p.Results(); len(got) != 0 {
+		t.Errorf("Results() before probing = %+v, want none", got)
+	}
+	p.ProbeAll(context.Background())
+
+	results := p.Results()
+	if len(results) != 3 {
+		t.Fatalf("Results() = %+v, want 3", results)
+	}
+	for i, want := range []struct {
+		success bool
+		status  int
+	}{
+		{true, http.StatusOK},
+		{false, http.StatusServiceUnavailable},
+		{false, 0},
+	} {
+		r := results[i]
+		if r.Success != want.success || r.StatusCode != want.status || r.Success != (r.Error == "") {
+			t.Errorf("%s: result = %+v, want success %t with status %d", r.Target, r, want.success, want.status)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package profiling pushes continuous profiles to a Pyroscope server, so
+// profiling backends can be validated against hotpod's CPU and memory
+// behaviors.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/wallclock"
+)
+
+// Sampling rates enabled for the mutex and block profile types, which the
+// runtime leaves off by default. Both are chosen to keep overhead low.
+const (
+	mutexProfileFraction = 10
+	blockProfileRate     = 10000 // nanoseconds
+)
+
+// Config configures a Pyroscope agent.
+type Config struct {
+	// URL is the Pyroscope server base URL
+	URL string
+	// AppName is the application name profiles are stored under
+	AppName string
+	// Labels are attached to every profile
+	Labels map[string]string
+	// Interval is how long each profile covers
+	Interval time.Duration
+	// Types lists the profile types to push: cpu, heap, goroutine, mutex, block
+	Types []string
+}
+
+// Agent collects profiles every interval and pushes them to Pyroscope's
+// /ingest API in pprof format.
+type Agent struct {
+	cfg    Config
+	name   string
+	client *http.Client
+}
+
+// NewAgent creates an agent.
+func NewAgent(cfg Config) *Agent {
+	return &Agent{
+		cfg:    cfg,
+		name:   appName(cfg.AppName, cfg.Labels),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run collects and pushes profiles until ctx is cancelled.
+func (a *Agent) Run(ctx context.Context) {
+	slog.Info("continuous profiling started", "url", a.cfg.URL, "app", a.name, "interval", a.cfg.Interval, "types", a.cfg.Types)
+
+	for _, t := range a.cfg.Types {
+		switch t {
+		case "mutex":
+			prev := runtime.SetMutexProfileFraction(mutexProfileFraction)
+			defer runtime.SetMutexProfileFraction(prev)
+		case "block":
+			runtime.SetBlockProfileRate(blockProfileRate)
+			defer runtime.SetBlockProfileRate(0)
+		}
+	}
+
+	for ctx.Err() == nil {
+		from := wallclock.Now()
+		var cpu bytes.Buffer
+		cpuStarted := a.wants("cpu") && a.startCPU(&cpu)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(a.cfg.Interval):
+		}
+		if cpuStarted {
+			pprof.StopCPUProfile()
+		}
+		until := wallclock.Now()
+
+		// Uploads use their own timeout so the final window still reports
+		// during shutdown.
+		uploadCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if cpuStarted {
+			a.upload(uploadCtx, "cpu", cpu.Bytes(), from, until)
+		}
+		for _, t := range a.cfg.Types {
+			if t == "cpu" {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := pprof.Lookup(t).WriteTo(&buf, 0); err != nil {
+				slog.Warn("failed to collect profile", "type", t, "error", err)
+				continue
+			}
+			a.upload(uploadCtx, t, buf.Bytes(), from, until)
+		}
+		cancel()
+	}
+	slog.Info("continuous profiling stopped")
+}
+
+func (a *Agent) wants(typ string) bool {
+	for _, t := range a.cfg.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// startCPU starts the CPU profiler. It fails if another CPU profile, such as
+// one requested through /admin/profile, is already running; that window is
+// then skipped.
+func (a *Agent) startCPU(w io.Writer) bool {
+	if err := pprof.StartCPUProfile(w); err != nil {
+		slog.Debug("skipping continuous CPU profile window", "error", err)
+		metrics.ProfilesUploadedTotal.WithLabelValues("cpu", "skipped").Inc()
+		return false
+	}
+	return true
+}
+
+func (a *Agent) upload(ctx context.Context, typ string, profile []byte, from, until time.Time) {
+	err := a.push(ctx, typ, profile, from, until)
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		slog.Warn("profile upload failed", "type", typ, "error", err)
+	}
+	metrics.ProfilesUploadedTotal.WithLabelValues(typ, outcome).Inc()
+}
+
+func (a *Agent) push(ctx context.Context, typ string, profile []byte, from, until time.Time) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(profile); err != nil {
+		return err
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	q.Set("name", a.name)
+	q.Set("from", strconv.FormatInt(from.Unix(), 10))
+	q.Set("until", strconv.FormatInt(until.Unix(), 10))
+	q.Set("spyName", "gospy")
+	if typ == "cpu" {
+		q.Set("sampleRate", "100")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(a.cfg.URL, "/")+"/ingest?"+q.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pyroscope returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// appName renders the Pyroscope application name with labels, e.g.
+// hotpod{env=dev,pod=hotpod-0}. Labels are sorted for a stable name.
+func appName(app string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return app
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + labels[k]
+	}
+	return app + "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,67 @@
+package profiling
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAgentPushesProfiles(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string]int{}
+	var names []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ingest" {
+			t.Errorf("path = %q, want /ingest", r.URL.Path)
+		}
+		f, _, err := r.FormFile("profile")
+		if err != nil {
+			t.Errorf("missing profile form file: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.Close()
+
+		typ := "heap"
+		if r.URL.Query().Get("sampleRate") != "" {
+			typ = "cpu"
+		}
+		mu.Lock()
+		got[typ]++
+		names = append(names, r.URL.Query().Get("name"))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	agent := NewAgent(Config{
+		URL:      srv.URL + "/",
+		AppName:  "hotpod",
+		Labels:   map[string]string{"pod": "hotpod-0", "env": "test"},
+		Interval: 50 * time.Millisecond,
+		Types:    []string{"cpu", "heap"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	agent.Run(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got["cpu"] < 2 || got["heap"] < 2 {
+		t.Errorf("uploads = %v, want at least 2 of each type", got)
+	}
+	for _, name := range names {
+		if name != "hotpod{env=test,pod=hotpod-0}" {
+			t.Errorf("name = %q, want labels rendered in sorted order", name)
+		}
+	}
+}
+
+func TestAppName(t *testing.T) {
+	if got := appName("hotpod", nil); got != "hotpod" {
+		t.Errorf("appName without labels = %q, want hotpod", got)
+	}
+}
@@ -0,0 +1,299 @@
+// Package proxyproto accepts PROXY protocol v1 and v2 headers, as sent by
+// HAProxy and cloud network load balancers, so the original client address
+// replaces the load balancer's as a connection's remote address.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Modes for accepting PROXY protocol headers.
+const (
+	// ModeOptional uses a header when one is sent, so direct and proxied
+	// connections both work.
+	ModeOptional = "optional"
+	// ModeRequired closes connections that do not start with a header.
+	ModeRequired = "required"
+)
+
+// DefaultHeaderTimeout bounds how long a connection may take to send its
+// header. In optional mode, a connection that sends nothing in that time is
+// treated as having no header.
+const DefaultHeaderTimeout = 5 * time.Second
+
+// v2Signature starts every version 2 header.
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// maxV1Header is the longest version 1 header, including the CRLF.
+const maxV1Header = 107
+
+// ErrNoHeader is returned by a required-mode connection that did not start
+// with a PROXY protocol header.
+var ErrNoHeader = errors.New("proxyproto: connection did not send a PROXY protocol header")
+
+// Header is a parsed PROXY protocol header.
+type Header struct {
+	// Version is 1 or 2.
+	Version int
+	// Source is the original client address, nil for LOCAL or UNKNOWN
+	// headers, which carry no addresses.
+	Source net.Addr
+	// Destination is the address the client connected to.
+	Destination net.Addr
+}
+
+// Listener wraps a net.Listener, reading a PROXY protocol header from the
+// start of each accepted connection.
+type Listener struct {
+	net.Listener
+	// Required rejects connections without a header.
+	Required bool
+	// ExemptLoopback lets loopback connections omit the header even when
+	// Required is set, so the server's own clients can still reach it.
+	ExemptLoopback bool
+	// HeaderTimeout bounds how long reading the header may take.
+	HeaderTimeout time.Duration
+}
+
+// NewListener wraps ln, accepting headers according to mode.
+func NewListener(ln net.Listener, mode string) *Listener {
+	return &Listener{Listener: ln, Required: mode == ModeRequired, HeaderTimeout: DefaultHeaderTimeout}
+}
+
+// Accept returns the next connection. Its header is read on first use, in
+// the connection's own goroutine, so a slow client cannot stall Accept.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	required := l.Required && !(l.ExemptLoopback && isLoopback(c.RemoteAddr()))
+	return &Conn{Conn: c, r: bufio.NewReader(c), required: required, timeout: l.HeaderTimeout}, nil
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// Conn is a connection whose remote address comes from its PROXY protocol
+// header, if it sent one.
+type Conn struct {
+	net.Conn
+	r        *bufio.Reader
+	required bool
+	timeout  time.Duration
+
+	once   sync.Once
+	header *Header
+	err    error
+}
+
+func (c *Conn) init() {
+	c.once.Do(func() {
+		if c.timeout > 0 {
+			c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+			defer c.Conn.SetReadDeadline(time.Time{})
+		}
+		c.header, c.err = readHeader(c.r)
+		// An optional-mode client that stays silent past the timeout, such as
+		// a pre-opened load balancer or browser connection, sent no header.
+		var ne net.Error
+		if !c.required && c.r.Buffered() == 0 && errors.As(c.err, &ne) && ne.Timeout() {
+			c.err = nil
+		}
+		if c.err == nil && c.header == nil && c.required {
+			c.err = ErrNoHeader
+		}
+		if c.err != nil {
+			c.Conn.Close()
+		}
+	})
+}
+
+// Header returns the connection's PROXY protocol header, or nil if it sent
+// none.
+func (c *Conn) Header() (*Header, error) {
+	c.init()
+	return c.header, c.err
+}
+
+// Read reads from the connection after its header.
+func (c *Conn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// RemoteAddr returns the original client address from the header, or the
+// peer address without one.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.init()
+	if c.header != nil && c.header.Source != nil {
+		return c.header.Source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// ProxyAddr returns the address of the peer that sent the header, typically
+// the load balancer.
+func (c *Conn) ProxyAddr() net.Addr {
+	return c.Conn.RemoteAddr()
+}
+
+// readHeader reads a header from the start of r, returning nil if the
+// connection starts with anything else.
+func readHeader(r *bufio.Reader) (*Header, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	switch first[0] {
+	case 'P':
+		if b, err := r.Peek(6); err == nil && string(b) == "PROXY " {
+			return readV1(r)
+		}
+	case v2Signature[0]:
+		if b, err := r.Peek(len(v2Signature)); err == nil && bytes.Equal(b, v2Signature) {
+			return readV2(r)
+		}
+	}
+	return nil, nil
+}
+
+// readV1 parses a text header: "PROXY TCP4 src dst sport dport\r\n".
+func readV1(r *bufio.Reader) (*Header, error) {
+	var line []byte
+	for len(line) < maxV1Header {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("proxyproto: reading v1 header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	s, ok := strings.CutSuffix(string(line), "\r\n")
+	if !ok {
+		return nil, errors.New("proxyproto: v1 header is not terminated by CRLF")
+	}
+
+	fields := strings.Split(s, " ")
+	h := &Header{Version: 1}
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return h, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("proxyproto: malformed v1 header %q", s)
+	}
+	src, err := v1Addr(fields[2], fields[4], fields[1] == "TCP4")
+	if err != nil {
+		return nil, err
+	}
+	dst, err := v1Addr(fields[3], fields[5], fields[1] == "TCP4")
+	if err != nil {
+		return nil, err
+	}
+	h.Source, h.Destination = src, dst
+	return h, nil
+}
+
+func v1Addr(ip, port string, v4 bool) (*net.TCPAddr, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil || (addr.To4() != nil) != v4 {
+		return nil, fmt.Errorf("proxyproto: invalid v1 address %q", ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("proxyproto: invalid v1 port %q", port)
+	}
+	return &net.TCPAddr{IP: addr, Port: int(p)}, nil
+}
+
+// readV2 parses a binary header: the signature, version and command, address
+// family, length, then addresses and any TLVs, which are skipped.
+func readV2(r *bufio.Reader) (*Header, error) {
+	var fixed [16]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return nil, fmt.Errorf("proxyproto: reading v2 header: %w", err)
+	}
+	if fixed[12]>>4 != 2 {
+		return nil, fmt.Errorf("proxyproto: unsupported v2 version %d", fixed[12]>>4)
+	}
+	cmd := fixed[12] & 0x0f
+	if cmd > 1 {
+		return nil, fmt.Errorf("proxyproto: unsupported v2 command %d", cmd)
+	}
+	family := fixed[13]
+	body := make([]byte, binary.BigEndian.Uint16(fixed[14:16]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("proxyproto: reading v2 addresses: %w", err)
+	}
+
+	h := &Header{Version: 2}
+	// LOCAL connections, such as load balancer health checks, keep their
+	// own address.
+	if cmd == 0 {
+		return h, nil
+	}
+
+	var ipLen int
+	switch family >> 4 {
+	case 1:
+		ipLen = net.IPv4len
+	case 2:
+		ipLen = net.IPv6len
+	default:
+		// AF_UNSPEC and AF_UNIX carry no usable IP address.
+		return h, nil
+	}
+	if len(body) < 2*ipLen+4 {
+		return nil, fmt.Errorf("proxyproto: v2 address block of %d bytes is too short", len(body))
+	}
+	src := net.IP(bytes.Clone(body[:ipLen]))
+	dst := net.IP(bytes.Clone(body[ipLen : 2*ipLen]))
+	ports := body[2*ipLen:]
+	h.Source = &net.TCPAddr{IP: src, Port: int(binary.BigEndian.Uint16(ports[0:2]))}
+	h.Destination = &net.TCPAddr{IP: dst, Port: int(binary.BigEndian.Uint16(ports[2:4]))}
+	return h, nil
+}
+
+type contextKey struct{}
+
+// ConnContext is an http.Server ConnContext hook that makes a connection's
+// header available to handlers through FromContext, including over TLS
+// served on top of the PROXY protocol listener.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+	if pc, ok := c.(*Conn); ok {
+		return context.WithValue(ctx, contextKey{}, pc)
+	}
+	return ctx
+}
+
+// FromContext returns the PROXY protocol connection a request arrived on, or
+// nil if the listener does not accept PROXY protocol.
+func FromContext(ctx context.Context) *Conn {
+	c, _ := ctx.Value(contextKey{}).(*Conn)
+	return c
+}
@@ -0,0 +1,222 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func v2Header(cmd, family byte, addrs []byte) []byte {
+	b := append([]byte{}, v2Signature...)
+	b = append(b, 0x20|cmd, family)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(addrs)))
+	return append(b, addrs...)
+}
+
+func TestReadHeader(t *testing.T) {
+	v4 := []byte{10, 0, 0, 1, 10, 0, 0, 2, 0x30, 0x39, 0x1f, 0x90}
+	v6 := append(append(net.ParseIP("2001:db8::1").To16(), net.ParseIP("2001:db8::2").To16()...), 0x30, 0x39, 0x1f, 0x90)
+
+	tests := []struct {
+		name    string
+		input   []byte
+		wantVer int
+		wantSrc string
+		wantDst string
+		wantErr bool
+	}{
+		{"none", []byte("GET / HTTP/1.1\r\n"), 0, "", "", false},
+		{"empty", nil, 0, "", "", false},
+		{"v1 tcp4", []byte("PROXY TCP4 192.0.2.1 192.0.2.2 12345 8080\r\nGET /"), 1, "192.0.2.1:12345", "192.0.2.2:8080", false},
+		{"v1 tcp6", []byte("PROXY TCP6 2001:db8::1 2001:db8::2 12345 8080\r\n"), 1, "[2001:db8::1]:12345", "[2001:db8::2]:8080", false},
+		{"v1 unknown", []byte("PROXY UNKNOWN\r\n"), 1, "", "", false},
+		{"v1 mismatched family", []byte("PROXY TCP4 2001:db8::1 192.0.2.2 1 2\r\n"), 0, "", "", true},
+		{"v1 bad port", []byte("PROXY TCP4 192.0.2.1 192.0.2.2 99999 8080\r\n"), 0, "", "", true},
+		{"v1 no crlf", []byte("PROXY TCP4 192.0.2.1 192.0.2.2 1 2\n"), 0, "", "", true},
+		{"v1 too long", []byte("PROXY " + strings.Repeat("x", 200)), 0, "", "", true},
+		{"v2 tcp4", v2Header(1, 0x11, v4), 2, "10.0.0.1:12345", "10.0.0.2:8080", false},
+		{"v2 tcp6", v2Header(1, 0x21, v6), 2, "[2001:db8::1]:12345", "[2001:db8::2]:8080", false},
+		{"v2 tlvs", v2Header(1, 0x11, append(v4, 0x04, 0x00, 0x01, 0xff)), 2, "10.0.0.1:12345", "10.0.0.2:8080", false},
+		{"v2 local", v2Header(0, 0x00, nil), 2, "", "", false},
+		{"v2 unix", v2Header(1, 0x31, make([]byte, 216)), 2, "", "", false},
+		{"v2 short", v2Header(1, 0x11, v4[:6]), 0, "", "", true},
+		{"v2 truncated", v2Header(1, 0x11, v4)[:20], 0, "", "", true},
+		{"v2 bad command", v2Header(2, 0x11, v4), 0, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := readHeader(bufio.NewReader(bytes.NewReader(tt.input)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readHeader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantVer == 0 {
+				if h != nil {
+					t.Errorf("readHeader() = %+v, want nil", h)
+				}
+				return
+			}
+			if h == nil || h.Version != tt.wantVer {
+				t.Fatalf("readHeader() = %+v, want version %d", h, tt.wantVer)
+			}
+			var src, dst string
+			if h.Source != nil {
+				src, dst = h.Source.String(), h.Destination.String()
+			}
+			if src != tt.wantSrc || dst != tt.wantDst {
+				t.Errorf("addresses = %s -> %s, want %s -> %s", src, dst, tt.wantSrc, tt.wantDst)
+			}
+		})
+	}
+}
+
+func TestListener(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		send       string
+		wantRemote string
+		wantBody   string
+		wantErr    bool
+	}{
+		{"optional with header", ModeOptional, "PROXY TCP4 192.0.2.1 192.0.2.2 12345 8080\r\nhello", "192.0.2.1:12345", "hello", false},
+		{"optional without header", ModeOptional, "hello", "", "hello", false},
+		{"required with header", ModeRequired, "PROXY TCP4 192.0.2.1 192.0.2.2 12345 8080\r\nhello", "192.0.2.1:12345", "hello", false},
+		{"required without header", ModeRequired, "hello", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ln := NewListener(inner, tt.mode)
+			defer ln.Close()
+
+			client, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			client.Write([]byte(tt.send))
+			client.(*net.TCPConn).CloseWrite()
+
+			c, err := ln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			c.SetDeadline(time.Now().Add(time.Second))
+
+			body, err := io.ReadAll(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("read error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			wantRemote := tt.wantRemote
+			if wantRemote == "" {
+				wantRemote = client.LocalAddr().String()
+			}
+			if got := c.RemoteAddr().String(); !tt.wantErr && got != wantRemote {
+				t.Errorf("RemoteAddr() = %s, want %s", got, wantRemote)
+			}
+		})
+	}
+}
+
+func TestListenerRequiredExemptLoopback(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := NewListener(inner, ModeRequired)
+	ln.ExemptLoopback = true
+	defer ln.Close()
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, r.RemoteAddr)
+		}),
+		ConnContext: ConnContext,
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("loopback client without a header: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if host, _, _ := net.SplitHostPort(string(body)); host != "127.0.0.1" {
+		t.Errorf("RemoteAddr = %q, want the loopback client", body)
+	}
+}
+
+func TestListenerOptionalIdle(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := NewListener(inner, ModeOptional)
+	ln.HeaderTimeout = 20 * time.Millisecond
+	defer ln.Close()
+
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	c, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		client.Write([]byte("hello"))
+		client.(*net.TCPConn).CloseWrite()
+	}()
+	c.SetDeadline(time.Now().Add(time.Second))
+	body, err := io.ReadAll(c)
+	if err != nil || string(body) != "hello" {
+		t.Fatalf("read = %q, %v; want hello from a connection idle past the header timeout", body, err)
+	}
+	if got := c.RemoteAddr().String(); got != client.LocalAddr().String() {
+		t.Errorf("RemoteAddr() = %s, want %s", got, client.LocalAddr())
+	}
+}
+
+func TestConnContext(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	pc := &Conn{Conn: server, r: bufio.NewReader(server)}
+	defer pc.Close()
+
+	for name, c := range map[string]net.Conn{
+		"plain": pc,
+		"tls":   tls.Server(pc, &tls.Config{}),
+	} {
+		if got := FromContext(ConnContext(context.Background(), c)); got != pc {
+			t.Errorf("%s: FromContext() = %v, want the PROXY protocol connection", name, got)
+		}
+	}
+	if got := FromContext(ConnContext(context.Background(), client)); got != nil {
+		t.Errorf("FromContext() = %v for a plain net.Conn, want nil", got)
+	}
+}
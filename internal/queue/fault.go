@@ -0,0 +1,67 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// MaxWorkerStall bounds how long an injected stall holds a worker.
+const MaxWorkerStall = 10 * time.Minute
+
+// Worker fault kinds, used as metric labels.
+const (
+	WorkerFaultPanic     = "panic"
+	WorkerFaultStall     = "stall"
+	WorkerFaultDuplicate = "duplicate"
+)
+
+// WorkerFaults are failures injected into queue workers so consumer-side
+// failure modes can be exercised. The zero value injects nothing.
+type WorkerFaults struct {
+	// PanicRate is the probability a batch panics before it is processed.
+	// The panic is recovered and the batch's items are marked failed.
+	PanicRate float64
+	// StallRate is the probability a worker stalls for Stall before
+	// processing a batch
+	StallRate float64
+	Stall     time.Duration
+	// DuplicateRate is the probability each processed item is processed a
+	// second time, as after a lost acknowledgement
+	DuplicateRate float64
+}
+
+// Enabled reports whether any fault is configured.
+func (f WorkerFaults) Enabled() bool {
+	return f.PanicRate > 0 || f.StallRate > 0 || f.DuplicateRate > 0
+}
+
+// Validate checks that rates are probabilities and that a stall rate comes
+// with a stall duration.
+func (f WorkerFaults) Validate() error {
+	for _, r := range []struct {
+		name string
+		rate float64
+	}{
+		{"panic_rate", f.PanicRate},
+		{"stall_rate", f.StallRate},
+		{"duplicate_rate", f.DuplicateRate},
+	} {
+		if r.rate < 0 || r.rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1, got %v", r.name, r.rate)
+		}
+	}
+	if f.Stall < 0 || f.Stall > MaxWorkerStall {
+		return fmt.Errorf("stall must be between 0 and %s, got %s", MaxWorkerStall, f.Stall)
+	}
+	if f.StallRate > 0 && f.Stall == 0 {
+		return errors.New("stall is required when stall_rate is set")
+	}
+	return nil
+}
+
+// roll reports whether an event with probability rate happens.
+func roll(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
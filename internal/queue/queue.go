@@ -2,6 +2,7 @@ package queue
 
 import (
 	"errors"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,6 +20,10 @@ const (
 // ErrQueueFull is returned when the queue has reached its maximum depth.
 var ErrQueueFull = errors.New("queue is full")
 
+// ErrPayloadLimit is returned when an item's payload would take the queued
+// payload total past the limit set by SetMaxPayload.
+var ErrPayloadLimit = errors.New("queued payload limit reached")
+
 // Item represents a work item in the queue.
 type Item struct {
 	// ID is a unique identifier for the item
@@ -29,12 +34,36 @@ type Item struct {
 	ProcessingTime time.Duration
 	// EnqueuedAt is when the item was added to the queue
 	EnqueuedAt time.Time
+	// PayloadSize is the number of bytes a worker holds while processing the
+	// item, in addition to the pool's per-item memory
+	PayloadSize int64
+	// NotBefore delays delivery: the item is not dequeued before this time,
+	// though it counts toward the queue depth while it waits
+	NotBefore time.Time
+}
+
+// wait returns how long the item has waited at now since it was enqueued or,
+// if delayed, became due.
+func (i *Item) wait(now time.Time) time.Duration {
+	if i.NotBefore.After(i.EnqueuedAt) {
+		return now.Sub(i.NotBefore)
+	}
+	return now.Sub(i.EnqueuedAt)
+}
+
+// ready reports whether the item may be dequeued at now.
+func (i *Item) ready(now time.Time) bool {
+	return !now.Before(i.NotBefore)
 }
 
 // Queue is a thread-safe priority queue.
 type Queue struct {
 	mu       sync.Mutex
 	maxDepth int
+	// maxPayload bounds the summed PayloadSize of queued items (0 = no limit)
+	maxPayload int64
+	// payload is the summed PayloadSize of queued items
+	payload int64
 
 	// Separate queues for each priority level
 	high   []*Item
@@ -68,6 +97,10 @@ func (q *Queue) Enqueue(item *Item) error {
 	if q.depth() >= q.maxDepth {
 		return ErrQueueFull
 	}
+	if q.maxPayload > 0 && q.payload+item.PayloadSize > q.maxPayload {
+		return ErrPayloadLimit
+	}
+	q.payload += item.PayloadSize
 
 	switch item.Priority {
 	case PriorityHigh:
@@ -85,9 +118,21 @@ func (q *Queue) Enqueue(item *Item) error {
 	return nil
 }
 
-// Dequeue removes and returns the highest priority item.
-// Returns nil if the queue is empty or paused.
+// Dequeue removes and returns the highest priority item whose NotBefore time
+// has passed, skipping over delayed items. Returns nil if the queue is paused
+// or holds no ready items.
 func (q *Queue) Dequeue() *Item {
+	items := q.DequeueBatch(1)
+	if len(items) == 0 {
+		return nil
+	}
+	return items[0]
+}
+
+// DequeueBatch removes and returns up to n ready items in the order Dequeue
+// would return them. It does not wait for a full batch, and returns nil if
+// the queue is paused or holds no ready items.
+func (q *Queue) DequeueBatch(n int) []*Item {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -95,36 +140,59 @@ func (q *Queue) Dequeue() *Item {
 		return nil
 	}
 
-	var item *Item
-
-	if len(q.high) > 0 {
-		item = q.high[0]
-		q.high = q.high[1:]
-	} else if len(q.normal) > 0 {
-		item = q.normal[0]
-		q.normal = q.normal[1:]
-	} else if len(q.low) > 0 {
-		item = q.low[0]
-		q.low = q.low[1:]
+	now := time.Now()
+	var items []*Item
+	for _, level := range []*[]*Item{&q.high, &q.normal, &q.low} {
+		for len(items) < n {
+			item := dequeueReady(level, now)
+			if item == nil {
+				break
+			}
+			items = append(items, item)
+			q.payload -= item.PayloadSize
+		}
 	}
 
-	if item != nil {
+	if len(items) > 0 {
+		for _, item := range items {
+			metrics.QueueWaitSeconds.WithLabelValues(item.Priority).Observe(item.wait(now).Seconds())
+		}
 		q.updateMetrics()
 	}
 
-	return item
+	return items
 }
 
-// MarkProcessed increments the processed counter.
-func (q *Queue) MarkProcessed() {
+// dequeueReady removes and returns the first ready item in level, or nil.
+func dequeueReady(level *[]*Item, now time.Time) *Item {
+	for i, item := range *level {
+		if !item.ready(now) {
+			continue
+		}
+		if i == 0 {
+			*level = (*level)[1:]
+		} else {
+			*level = slices.Delete(*level, i, i+1)
+		}
+		return item
+	}
+	return nil
+}
+
+// MarkProcessed increments the processed counters for an item of the given
+// priority.
+func (q *Queue) MarkProcessed(priority string) {
 	q.processedTotal.Add(1)
 	metrics.QueueItemsProcessedTotal.Inc()
+	metrics.QueueItemsProcessedByPriorityTotal.WithLabelValues(priority).Inc()
 }
 
-// MarkFailed increments the failed counter.
-func (q *Queue) MarkFailed() {
+// MarkFailed increments the failed counters for an item of the given
+// priority.
+func (q *Queue) MarkFailed(priority string) {
 	q.failedTotal.Add(1)
 	metrics.QueueItemsFailedTotal.Inc()
+	metrics.QueueItemsFailedByPriorityTotal.WithLabelValues(priority).Inc()
 }
 
 // Depth returns the current queue depth.
@@ -146,6 +214,57 @@ func (q *Queue) DepthByPriority() (high, normal, low int) {
 	return len(q.high), len(q.normal), len(q.low)
 }
 
+// Items returns copies of the queued items in the order they would be
+// dequeued, limited to priority if it is not empty. Items of one priority are
+// dequeued consecutively, so start is the dequeue position of the first
+// returned item, counting from zero.
+func (q *Queue) Items(priority string) (items []Item, start int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items = make([]Item, 0, q.depth())
+	for i, level := range q.levels() {
+		if priority == "" || priority == levelPriorities[i] {
+			for _, item := range level {
+				items = append(items, *item)
+			}
+			if priority != "" {
+				break
+			}
+		} else {
+			start += len(level)
+		}
+	}
+	return items, start
+}
+
+// Get returns a copy of the item with the given ID and its position in
+// dequeue order, counting from zero. It returns false if the item is no longer
+// queued.
+func (q *Queue) Get(id string) (Item, int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	position := 0
+	for _, level := range q.levels() {
+		for _, item := range level {
+			if item.ID == id {
+				return *item, position, true
+			}
+			position++
+		}
+	}
+	return Item{}, 0, false
+}
+
+// levelPriorities names the priority of each of levels.
+var levelPriorities = [3]string{PriorityHigh, PriorityNormal, PriorityLow}
+
+// levels returns the priority queues in dequeue order (must hold lock).
+func (q *Queue) levels() [3][]*Item {
+	return [3][]*Item{q.high, q.normal, q.low}
+}
+
 // Stats returns queue statistics.
 type Stats struct {
 	Depth          int
@@ -157,6 +276,8 @@ type Stats struct {
 	FailedTotal    int64
 	Paused         bool
 	OldestItemAge  time.Duration
+	// DelayedDepth is the number of items waiting for their NotBefore time
+	DelayedDepth int
 }
 
 // Stats returns current queue statistics.
@@ -175,6 +296,15 @@ func (q *Queue) Stats() Stats {
 		Paused:         q.paused.Load(),
 	}
 
+	now := time.Now()
+	for _, level := range q.levels() {
+		for _, item := range level {
+			if !item.ready(now) {
+				stats.DelayedDepth++
+			}
+		}
+	}
+
 	// Find oldest item
 	var oldest time.Time
 	if len(q.high) > 0 && (oldest.IsZero() || q.high[0].EnqueuedAt.Before(oldest)) {
@@ -203,11 +333,20 @@ func (q *Queue) Clear() int {
 	q.high = make([]*Item, 0)
 	q.normal = make([]*Item, 0)
 	q.low = make([]*Item, 0)
+	q.payload = 0
 
 	q.updateMetrics()
 	return count
 }
 
+// SetMaxPayload bounds the summed payload of queued items, and so of any
+// batch a worker dequeues, to n bytes. Zero removes the limit.
+func (q *Queue) SetMaxPayload(n int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxPayload = n
+}
+
 // Pause stops dequeue operations.
 func (q *Queue) Pause() {
 	q.paused.Store(true)
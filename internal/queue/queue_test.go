@@ -1,8 +1,13 @@
 package queue
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ripta/hotpod/internal/metrics"
 )
 
 func TestEnqueueDequeue(t *testing.T) {
@@ -82,6 +87,30 @@ func TestMaxDepth(t *testing.T) {
 	}
 }
 
+func TestMaxPayload(t *testing.T) {
+	q := New(10)
+	q.SetMaxPayload(100)
+
+	for _, size := range []int64{60, 40} {
+		if err := q.Enqueue(&Item{Priority: PriorityNormal, EnqueuedAt: time.Now(), PayloadSize: size}); err != nil {
+			t.Fatalf("enqueue %d bytes failed: %v", size, err)
+		}
+	}
+	if err := q.Enqueue(&Item{Priority: PriorityNormal, EnqueuedAt: time.Now(), PayloadSize: 1}); err != ErrPayloadLimit {
+		t.Errorf("expected ErrPayloadLimit, got %v", err)
+	}
+
+	// Dequeued payloads no longer count toward the limit.
+	q.Dequeue()
+	if err := q.Enqueue(&Item{Priority: PriorityNormal, EnqueuedAt: time.Now(), PayloadSize: 60}); err != nil {
+		t.Errorf("enqueue after dequeue failed: %v", err)
+	}
+	q.Clear()
+	if err := q.Enqueue(&Item{Priority: PriorityNormal, EnqueuedAt: time.Now(), PayloadSize: 100}); err != nil {
+		t.Errorf("enqueue after clear failed: %v", err)
+	}
+}
+
 func TestPauseResume(t *testing.T) {
 	q := New(100)
 
@@ -213,3 +242,152 @@ func TestDepthByPriority(t *testing.T) {
 		t.Errorf("low = %d, want 3", low)
 	}
 }
+
+func TestItemsAndGet(t *testing.T) {
+	q := New(100)
+	for _, item := range []*Item{
+		{ID: "n1", Priority: PriorityNormal},
+		{ID: "l1", Priority: PriorityLow},
+		{ID: "h1", Priority: PriorityHigh},
+		{ID: "n2", Priority: PriorityNormal},
+	} {
+		if err := q.Enqueue(item); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		priority  string
+		wantIDs   []string
+		wantStart int
+	}{
+		{"", []string{"h1", "n1", "n2", "l1"}, 0},
+		{PriorityHigh, []string{"h1"}, 0},
+		{PriorityNormal, []string{"n1", "n2"}, 1},
+		{PriorityLow, []string{"l1"}, 3},
+	}
+	for _, tt := range tests {
+		items, start := q.Items(tt.priority)
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") || start != tt.wantStart {
+			t.Errorf("Items(%q) = %v starting at %d, want %v starting at %d", tt.priority, ids, start, tt.wantIDs, tt.wantStart)
+		}
+	}
+
+	if item, pos, ok := q.Get("n2"); !ok || item.ID != "n2" || pos != 2 {
+		t.Errorf("Get(n2) = %+v, %d, %v, want position 2", item, pos, ok)
+	}
+	q.Dequeue()
+	if _, pos, ok := q.Get("n2"); !ok || pos != 1 {
+		t.Errorf("Get(n2) after dequeue = %d, %v, want position 1", pos, ok)
+	}
+	if _, _, ok := q.Get("h1"); ok {
+		t.Error("Get(h1) found a dequeued item")
+	}
+}
+
+func TestDequeueSkipsDelayed(t *testing.T) {
+	q := New(100)
+	now := time.Now()
+	for _, item := range []*Item{
+		{ID: "high-later", Priority: PriorityHigh, NotBefore: now.Add(time.Hour)},
+		{ID: "normal-later", Priority: PriorityNormal, NotBefore: now.Add(time.Hour)},
+		{ID: "normal-due", Priority: PriorityNormal, NotBefore: now.Add(-time.Second)},
+		{ID: "low", Priority: PriorityLow},
+	} {
+		if err := q.Enqueue(item); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+	}
+
+	if stats := q.Stats(); stats.Depth != 4 || stats.DelayedDepth != 2 {
+		t.Errorf("stats = depth %d, delayed %d, want 4 and 2", stats.Depth, stats.DelayedDepth)
+	}
+
+	for _, want := range []string{"normal-due", "low"} {
+		got := q.Dequeue()
+		if got == nil || got.ID != want {
+			t.Fatalf("dequeue = %v, want %q", got, want)
+		}
+	}
+	if got := q.Dequeue(); got != nil {
+		t.Errorf("dequeue = %q, want nil while remaining items are delayed", got.ID)
+	}
+
+	items, _ := q.Items("")
+	if len(items) != 2 || items[0].ID != "high-later" || items[1].ID != "normal-later" {
+		t.Errorf("remaining items = %+v", items)
+	}
+}
+
+func TestItemWait(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		item Item
+		want time.Duration
+	}{
+		{"enqueued", Item{EnqueuedAt: now.Add(-time.Minute)}, time.Minute},
+		{"delayed", Item{EnqueuedAt: now.Add(-time.Hour), NotBefore: now.Add(-time.Second)}, time.Second},
+		{"due before enqueue", Item{EnqueuedAt: now.Add(-time.Minute), NotBefore: now.Add(-time.Hour)}, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := tt.item.wait(now); got != tt.want {
+			t.Errorf("%s: wait = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPriorityMetrics(t *testing.T) {
+	q := New(100)
+	processed := testutil.ToFloat64(metrics.QueueItemsProcessedByPriorityTotal.WithLabelValues(PriorityLow))
+	failed := testutil.ToFloat64(metrics.QueueItemsFailedByPriorityTotal.WithLabelValues(PriorityLow))
+
+	if err := q.Enqueue(&Item{ID: "low", Priority: PriorityLow, EnqueuedAt: time.Now()}); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	item := q.Dequeue()
+	q.MarkProcessed(item.Priority)
+	q.MarkFailed(item.Priority)
+
+	if got := testutil.ToFloat64(metrics.QueueItemsProcessedByPriorityTotal.WithLabelValues(PriorityLow)); got != processed+1 {
+		t.Errorf("processed low = %v, want %v", got, processed+1)
+	}
+	if got := testutil.ToFloat64(metrics.QueueItemsFailedByPriorityTotal.WithLabelValues(PriorityLow)); got != failed+1 {
+		t.Errorf("failed low = %v, want %v", got, failed+1)
+	}
+	if got := testutil.CollectAndCount(metrics.QueueWaitSeconds); got == 0 {
+		t.Error("wait histogram has no series after a dequeue")
+	}
+}
+
+func TestDequeueBatch(t *testing.T) {
+	q := New(100)
+	for _, item := range []*Item{
+		{ID: "n1", Priority: PriorityNormal},
+		{ID: "l1", Priority: PriorityLow},
+		{ID: "h1", Priority: PriorityHigh},
+		{ID: "later", Priority: PriorityHigh, NotBefore: time.Now().Add(time.Hour)},
+		{ID: "n2", Priority: PriorityNormal},
+	} {
+		if err := q.Enqueue(item); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+	}
+
+	for _, want := range []string{"h1,n1,n2", "l1", ""} {
+		var ids []string
+		for _, item := range q.DequeueBatch(3) {
+			ids = append(ids, item.ID)
+		}
+		if got := strings.Join(ids, ","); got != want {
+			t.Errorf("DequeueBatch(3) = %q, want %q", got, want)
+		}
+	}
+	if q.Depth() != 1 {
+		t.Errorf("depth = %d, want the delayed item left", q.Depth())
+	}
+}
@@ -2,7 +2,10 @@ package queue
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -10,18 +13,30 @@ import (
 	"github.com/ripta/hotpod/internal/metrics"
 )
 
+// ErrPoolStopped is returned when scaling a worker pool that is not running.
+var ErrPoolStopped = errors.New("worker pool is not running")
+
 // WorkerPool manages background workers that process queue items.
 type WorkerPool struct {
 	queue *Queue
 
 	mu            sync.Mutex
 	activeWorkers atomic.Int32
+	ctx           context.Context
 	cancel        context.CancelFunc
 	wg            sync.WaitGroup
+	// workers holds a stop channel per running worker, closed to retire the
+	// worker once its current item is done
+	workers []chan struct{}
+	nextID  int
 
 	// Per-item resource consumption (immutable after Start, no lock needed for reads)
 	cpuPerItem    atomic.Int64
 	memoryPerItem atomic.Int64
+	batchSize     atomic.Int64
+
+	// faults are injected worker failures, swapped whole by SetFaults
+	faults atomic.Pointer[WorkerFaults]
 }
 
 // NewWorkerPool creates a new worker pool for the given queue.
@@ -34,7 +49,13 @@ func NewWorkerPool(q *Queue) *WorkerPool {
 // Start launches workers to process queue items.
 // If workers are already running, this stops them first.
 // The provided context controls worker lifetime - workers stop when it's cancelled.
-func (wp *WorkerPool) Start(ctx context.Context, workerCount int, cpuPerItem time.Duration, memoryPerItem int64) {
+//
+// With a batchSize above 1, each worker dequeues up to that many items at once
+// and processes them together: cpuPerItem and memoryPerItem are spent once per
+// batch rather than per item, item payloads are held for the whole batch, and
+// the batch takes as long as its slowest item. This models batch consumers,
+// whose per-item cost falls as batches fill.
+func (wp *WorkerPool) Start(ctx context.Context, workerCount int, cpuPerItem time.Duration, memoryPerItem int64, batchSize int) {
 	// Stop existing workers first (outside the lock to avoid deadlock)
 	wp.Stop()
 
@@ -44,16 +65,51 @@ func (wp *WorkerPool) Start(ctx context.Context, workerCount int, cpuPerItem tim
 	// Store config atomically for safe concurrent reads by workers
 	wp.cpuPerItem.Store(int64(cpuPerItem))
 	wp.memoryPerItem.Store(memoryPerItem)
+	wp.batchSize.Store(int64(max(batchSize, 1)))
 
-	workerCtx, cancel := context.WithCancel(ctx)
-	wp.cancel = cancel
+	wp.ctx, wp.cancel = context.WithCancel(ctx)
+	wp.addWorkers(workerCount)
 
-	for i := range workerCount {
-		wp.wg.Add(1)
-		go wp.worker(workerCtx, i)
+	slog.Info("worker pool started", "workers", workerCount, "cpu_per_item", cpuPerItem, "memory_per_item", memoryPerItem, "batch_size", batchSize)
+}
+
+// Scale adjusts the number of running workers to workerCount without
+// disturbing in-progress items: new workers start immediately, and retired
+// workers exit once their current item is processed. It returns the previous
+// worker count, or ErrPoolStopped if the pool has not been started.
+func (wp *WorkerPool) Scale(workerCount int) (int, error) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.cancel == nil {
+		return 0, ErrPoolStopped
+	}
+
+	previous := len(wp.workers)
+	if workerCount > previous {
+		wp.addWorkers(workerCount - previous)
+	} else {
+		for _, stop := range wp.workers[workerCount:] {
+			close(stop)
+		}
+		wp.workers = wp.workers[:workerCount]
+		metrics.QueueWorkers.Set(float64(workerCount))
 	}
 
-	slog.Info("worker pool started", "workers", workerCount, "cpu_per_item", cpuPerItem, "memory_per_item", memoryPerItem)
+	slog.Info("worker pool scaled", "workers", workerCount, "previous", previous)
+	return previous, nil
+}
+
+// addWorkers launches n more workers (must hold lock).
+func (wp *WorkerPool) addWorkers(n int) {
+	for range n {
+		stop := make(chan struct{})
+		wp.workers = append(wp.workers, stop)
+		wp.wg.Add(1)
+		go wp.worker(wp.ctx, stop, wp.nextID)
+		wp.nextID++
+	}
+	metrics.QueueWorkers.Set(float64(len(wp.workers)))
 }
 
 // Stop gracefully stops all workers.
@@ -63,18 +119,41 @@ func (wp *WorkerPool) Stop() {
 		wp.cancel()
 		wp.cancel = nil
 	}
+	wp.workers = nil
+	metrics.QueueWorkers.Set(0)
 	wp.mu.Unlock()
 
 	wp.wg.Wait()
 	slog.Info("worker pool stopped")
 }
 
+// SetFaults replaces the faults injected into workers. Batches already being
+// processed are unaffected.
+func (wp *WorkerPool) SetFaults(f WorkerFaults) {
+	wp.faults.Store(&f)
+}
+
+// Faults returns the faults injected into workers.
+func (wp *WorkerPool) Faults() WorkerFaults {
+	if f := wp.faults.Load(); f != nil {
+		return *f
+	}
+	return WorkerFaults{}
+}
+
+// Workers returns the number of running workers, busy or idle.
+func (wp *WorkerPool) Workers() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return len(wp.workers)
+}
+
 // ActiveWorkers returns the number of currently active workers.
 func (wp *WorkerPool) ActiveWorkers() int {
 	return int(wp.activeWorkers.Load())
 }
 
-func (wp *WorkerPool) worker(ctx context.Context, id int) {
+func (wp *WorkerPool) worker(ctx context.Context, stop <-chan struct{}, id int) {
 	defer wp.wg.Done()
 
 	slog.Debug("worker started", "worker_id", id)
@@ -84,15 +163,20 @@ func (wp *WorkerPool) worker(ctx context.Context, id int) {
 		case <-ctx.Done():
 			slog.Debug("worker stopping", "worker_id", id)
 			return
+		case <-stop:
+			slog.Debug("worker retired", "worker_id", id)
+			return
 		default:
 		}
 
-		item := wp.queue.Dequeue()
-		if item == nil {
+		batch := wp.queue.DequeueBatch(int(wp.batchSize.Load()))
+		if len(batch) == 0 {
 			// Queue is empty or paused, wait a bit
 			select {
 			case <-ctx.Done():
 				return
+			case <-stop:
+				return
 			case <-time.After(100 * time.Millisecond):
 				continue
 			}
@@ -101,31 +185,89 @@ func (wp *WorkerPool) worker(ctx context.Context, id int) {
 		wp.activeWorkers.Add(1)
 		metrics.QueueActiveWorkers.Set(float64(wp.activeWorkers.Load()))
 
-		wp.processItem(ctx, item)
+		wp.runBatch(ctx, id, batch)
 
 		wp.activeWorkers.Add(-1)
 		metrics.QueueActiveWorkers.Set(float64(wp.activeWorkers.Load()))
 	}
 }
 
-func (wp *WorkerPool) processItem(ctx context.Context, item *Item) {
+// runBatch processes a batch, applying any injected worker faults. An
+// injected panic is recovered here, so the worker survives and its items are
+// marked failed.
+func (wp *WorkerPool) runBatch(ctx context.Context, id int, batch []*Item) {
+	f := wp.Faults()
+	if !f.Enabled() {
+		wp.processBatch(ctx, batch)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.QueueWorkerFaultsTotal.WithLabelValues(WorkerFaultPanic).Inc()
+			slog.Error("worker panicked", "worker_id", id, "batch_size", len(batch), "panic", r, "stack", string(debug.Stack()))
+			wp.markFailed(batch)
+		}
+	}()
+
+	if roll(f.StallRate) {
+		metrics.QueueWorkerFaultsTotal.WithLabelValues(WorkerFaultStall).Inc()
+		slog.Warn("worker stalled", "worker_id", id, "batch_size", len(batch), "stall", f.Stall)
+		select {
+		case <-ctx.Done():
+		case <-time.After(f.Stall):
+		}
+	}
+	if roll(f.PanicRate) {
+		panic(fmt.Sprintf("injected panic in queue worker %d", id))
+	}
+
+	wp.processBatch(ctx, batch)
+
+	var dups []*Item
+	for _, item := range batch {
+		if roll(f.DuplicateRate) {
+			dups = append(dups, item)
+		}
+	}
+	if len(dups) > 0 && ctx.Err() == nil {
+		metrics.QueueWorkerFaultsTotal.WithLabelValues(WorkerFaultDuplicate).Add(float64(len(dups)))
+		ids := make([]string, len(dups))
+		for i, item := range dups {
+			ids[i] = item.ID
+		}
+		slog.Warn("worker processing items again", "worker_id", id, "item_ids", ids)
+		wp.processBatch(ctx, dups)
+	}
+}
+
+// processBatch processes items together, spending the pool's per-item CPU and
+// memory once for the whole batch.
+func (wp *WorkerPool) processBatch(ctx context.Context, items []*Item) {
 	start := time.Now()
+	metrics.QueueBatchSize.Observe(float64(len(items)))
 
-	// Simulate processing time
-	processingTime := item.ProcessingTime
-	if processingTime <= 0 {
-		processingTime = 100 * time.Millisecond
+	// Simulate processing time: the batch takes as long as its slowest item
+	var processingTime time.Duration
+	var payloadSize int64
+	for _, item := range items {
+		d := item.ProcessingTime
+		if d <= 0 {
+			d = 100 * time.Millisecond
+		}
+		processingTime = max(processingTime, d)
+		payloadSize += item.PayloadSize
 	}
 
 	// Load config atomically (safe for concurrent reads)
 	memoryPerItem := wp.memoryPerItem.Load()
 	cpuPerItem := time.Duration(wp.cpuPerItem.Load())
 
-	// Allocate memory if configured
+	// Allocate memory if configured, plus the items' own payloads
 	var memSink []byte
-	if memoryPerItem > 0 {
-		memSink = make([]byte, memoryPerItem)
-		for i := range memoryPerItem {
+	if size := memoryPerItem + payloadSize; size > 0 {
+		memSink = make([]byte, size)
+		for i := range size {
 			memSink[i] = byte(i)
 		}
 	}
@@ -136,7 +278,7 @@ func (wp *WorkerPool) processItem(ctx context.Context, item *Item) {
 		for time.Now().Before(cpuEnd) {
 			select {
 			case <-ctx.Done():
-				wp.queue.MarkFailed()
+				wp.markFailed(items)
 				return
 			default:
 				// Busy loop for CPU consumption
@@ -153,7 +295,7 @@ func (wp *WorkerPool) processItem(ctx context.Context, item *Item) {
 	if remaining > 0 {
 		select {
 		case <-ctx.Done():
-			wp.queue.MarkFailed()
+			wp.markFailed(items)
 			return
 		case <-time.After(remaining):
 		}
@@ -162,13 +304,24 @@ func (wp *WorkerPool) processItem(ctx context.Context, item *Item) {
 	// Keep memory alive until processing is done
 	_ = memSink
 
-	wp.queue.MarkProcessed()
-	metrics.QueueProcessingSeconds.Observe(time.Since(start).Seconds())
+	duration := time.Since(start)
+	for _, item := range items {
+		wp.queue.MarkProcessed(item.Priority)
+		metrics.QueueProcessingSeconds.Observe(duration.Seconds())
+		metrics.QueueProcessingByPrioritySeconds.WithLabelValues(item.Priority).Observe(duration.Seconds())
+
+		slog.Debug("item processed",
+			"item_id", item.ID,
+			"priority", item.Priority,
+			"batch_size", len(items),
+			"duration", duration,
+			"wait_time", item.wait(start),
+		)
+	}
+}
 
-	slog.Debug("item processed",
-		"item_id", item.ID,
-		"priority", item.Priority,
-		"duration", time.Since(start),
-		"wait_time", start.Sub(item.EnqueuedAt),
-	)
+func (wp *WorkerPool) markFailed(items []*Item) {
+	for _, item := range items {
+		wp.queue.MarkFailed(item.Priority)
+	}
 }
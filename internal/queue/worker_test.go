@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolScale(t *testing.T) {
+	q := New(100)
+	wp := NewWorkerPool(q)
+	defer wp.Stop()
+
+	if _, err := wp.Scale(2); !errors.Is(err, ErrPoolStopped) {
+		t.Fatalf("Scale before Start = %v, want ErrPoolStopped", err)
+	}
+
+	wp.Start(context.Background(), 1, 0, 0, 1)
+	previous, err := wp.Scale(3)
+	if err != nil || previous != 1 || wp.Workers() != 3 {
+		t.Fatalf("Scale(3) = %d, %v with %d workers, want previous 1 and 3 workers", previous, err, wp.Workers())
+	}
+
+	for range 3 {
+		if err := q.Enqueue(&Item{ProcessingTime: 200 * time.Millisecond, EnqueuedAt: time.Now()}); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+	}
+	waitFor(t, func() bool { return wp.ActiveWorkers() == 3 })
+
+	// Retiring every worker mid-item must let the items finish.
+	if previous, err := wp.Scale(0); err != nil || previous != 3 {
+		t.Fatalf("Scale(0) = %d, %v, want previous 3", previous, err)
+	}
+	waitFor(t, func() bool { return wp.ActiveWorkers() == 0 })
+
+	stats := q.Stats()
+	if stats.ProcessedTotal != 3 || stats.FailedTotal != 0 {
+		t.Errorf("processed %d and failed %d, want 3 and 0", stats.ProcessedTotal, stats.FailedTotal)
+	}
+
+	// With no workers left, new items stay queued.
+	if err := q.Enqueue(&Item{ProcessingTime: time.Millisecond, EnqueuedAt: time.Now()}); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if q.Depth() != 1 {
+		t.Errorf("depth = %d with no workers, want 1", q.Depth())
+	}
+}
+
+// waitFor polls cond until it holds or a deadline passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWorkerPoolBatch(t *testing.T) {
+	q := New(100)
+	wp := NewWorkerPool(q)
+	defer wp.Stop()
+
+	for range 4 {
+		if err := q.Enqueue(&Item{ProcessingTime: 300 * time.Millisecond, EnqueuedAt: time.Now()}); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+	}
+
+	// One worker with a batch of four finishes in roughly one item's time.
+	start := time.Now()
+	wp.Start(context.Background(), 1, 0, 0, 4)
+	waitFor(t, func() bool { return q.Stats().ProcessedTotal == 4 })
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("batch took %s, want about 300ms", elapsed)
+	}
+}
+
+func TestWorkerPoolFaults(t *testing.T) {
+	tests := []struct {
+		name          string
+		faults        WorkerFaults
+		wantProcessed int64
+		wantFailed    int64
+	}{
+		{"panic", WorkerFaults{PanicRate: 1}, 0, 2},
+		{"duplicate", WorkerFaults{DuplicateRate: 1}, 4, 0},
+		{"stall", WorkerFaults{StallRate: 1, Stall: 50 * time.Millisecond}, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := New(100)
+			wp := NewWorkerPool(q)
+			defer wp.Stop()
+			wp.SetFaults(tt.faults)
+
+			for range 2 {
+				if err := q.Enqueue(&Item{ProcessingTime: time.Millisecond, EnqueuedAt: time.Now()}); err != nil {
+					t.Fatalf("enqueue failed: %v", err)
+				}
+			}
+			wp.Start(context.Background(), 1, 0, 0, 2)
+			waitFor(t, func() bool {
+				stats := q.Stats()
+				return stats.ProcessedTotal+stats.FailedTotal >= 2 && wp.ActiveWorkers() == 0
+			})
+
+			stats := q.Stats()
+			if stats.ProcessedTotal != tt.wantProcessed || stats.FailedTotal != tt.wantFailed {
+				t.Errorf("processed %d and failed %d, want %d and %d", stats.ProcessedTotal, stats.FailedTotal, tt.wantProcessed, tt.wantFailed)
+			}
+			// The worker survives injected panics and keeps consuming.
+			if wp.Workers() != 1 {
+				t.Errorf("workers = %d, want 1", wp.Workers())
+			}
+		})
+	}
+}
+
+func TestWorkerFaultsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		faults  WorkerFaults
+		wantErr bool
+	}{
+		{"zero", WorkerFaults{}, false},
+		{"valid", WorkerFaults{PanicRate: 0.1, StallRate: 0.2, Stall: time.Second, DuplicateRate: 1}, false},
+		{"negative rate", WorkerFaults{PanicRate: -0.1}, true},
+		{"rate over 1", WorkerFaults{DuplicateRate: 1.1}, true},
+		{"stall rate without stall", WorkerFaults{StallRate: 0.5}, true},
+		{"stall too long", WorkerFaults{StallRate: 0.5, Stall: time.Hour}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.faults.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
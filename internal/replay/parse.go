@@ -0,0 +1,236 @@
+// Package replay reproduces recorded traffic against the local server so that
+// production-shaped load can be replayed in test clusters.
+package replay
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a single request to replay.
+type Record struct {
+	// Offset is the time since the first record at which to send the request
+	Offset time.Duration
+	// Method is the HTTP method (default: GET)
+	Method string
+	// Path is the endpoint path, e.g. /cpu
+	Path string
+	// Query is the encoded query string
+	Query string
+}
+
+// Supported log formats.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Parse reads a request log in the given format and returns records sorted by
+// offset. CSV rows are timestamp,endpoint,params[,method] with an optional
+// header row. JSON input is either an array or one object per line, each with
+// timestamp, endpoint, params (object or query string), and optional method.
+//
+// Timestamps may be RFC 3339 or numeric seconds (absolute or relative); they
+// are converted to offsets from the earliest record.
+func Parse(r io.Reader, format string) ([]Record, error) {
+	var raw []rawRecord
+	var err error
+	switch format {
+	case FormatCSV:
+		raw, err = parseCSV(r)
+	case FormatJSON:
+		raw, err = parseJSON(r)
+	default:
+		return nil, fmt.Errorf("format must be %s or %s, got %q", FormatCSV, FormatJSON, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("request log is empty")
+	}
+
+	sort.SliceStable(raw, func(i, j int) bool { return raw[i].at < raw[j].at })
+	first := raw[0].at
+
+	records := make([]Record, len(raw))
+	for i, rr := range raw {
+		records[i] = Record{
+			Offset: time.Duration(rr.at - first),
+			Method: rr.method,
+			Path:   rr.path,
+			Query:  rr.query,
+		}
+	}
+	return records, nil
+}
+
+// rawRecord is a parsed log line with its timestamp in nanoseconds.
+type rawRecord struct {
+	at     int64
+	method string
+	path   string
+	query  string
+}
+
+func newRawRecord(ts, endpoint, query, method string) (rawRecord, error) {
+	at, err := parseTimestamp(ts)
+	if err != nil {
+		return rawRecord{}, err
+	}
+
+	u, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return rawRecord{}, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		return rawRecord{}, fmt.Errorf("endpoint %q must be an absolute path", endpoint)
+	}
+	if strings.HasPrefix(u.Path, "/admin/") {
+		return rawRecord{}, fmt.Errorf("endpoint %q: admin endpoints cannot be replayed", endpoint)
+	}
+
+	q := u.RawQuery
+	if query = strings.TrimPrefix(strings.TrimSpace(query), "?"); query != "" {
+		if _, err := url.ParseQuery(query); err != nil {
+			return rawRecord{}, fmt.Errorf("invalid params %q: %w", query, err)
+		}
+		if q != "" {
+			q += "&"
+		}
+		q += query
+	}
+
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	return rawRecord{at: at, method: method, path: u.Path, query: q}, nil
+}
+
+func parseTimestamp(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UnixNano(), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q: must be RFC 3339 or seconds", s)
+	}
+	return int64(f * float64(time.Second)), nil
+}
+
+func parseCSV(r io.Reader) ([]rawRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var records []rawRecord
+	for line := 1; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 2 {
+			return nil, fmt.Errorf("line %d: expected timestamp,endpoint[,params[,method]]", line)
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(row[0]), "timestamp") {
+			continue
+		}
+
+		var params, method string
+		if len(row) > 2 {
+			params = row[2]
+		}
+		if len(row) > 3 {
+			method = row[3]
+		}
+		rec, err := newRawRecord(row[0], row[1], params, method)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// jsonRecord is the JSON form of a log entry.
+type jsonRecord struct {
+	Timestamp json.RawMessage `json:"timestamp"`
+	Endpoint  string          `json:"endpoint"`
+	Params    json.RawMessage `json:"params"`
+	Method    string          `json:"method"`
+}
+
+func (j jsonRecord) toRaw() (rawRecord, error) {
+	var ts string
+	if err := json.Unmarshal(j.Timestamp, &ts); err != nil {
+		ts = string(j.Timestamp)
+	}
+
+	var query string
+	if len(j.Params) > 0 && string(j.Params) != "null" {
+		if err := json.Unmarshal(j.Params, &query); err != nil {
+			var m map[string]any
+			if err := json.Unmarshal(j.Params, &m); err != nil {
+				return rawRecord{}, errors.New("params must be a query string or object")
+			}
+			v := url.Values{}
+			for k, val := range m {
+				v.Set(k, fmt.Sprint(val))
+			}
+			query = v.Encode()
+		}
+	}
+
+	return newRawRecord(ts, j.Endpoint, query, j.Method)
+}
+
+func parseJSON(r io.Reader) ([]rawRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []jsonRecord
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("decoding JSON array: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var e jsonRecord
+			if err := dec.Decode(&e); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("decoding JSON record %d: %w", len(entries)+1, err)
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	records := make([]rawRecord, 0, len(entries))
+	for i, e := range entries {
+		rec, err := e.toRaw()
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
@@ -0,0 +1,73 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSV(t *testing.T) {
+	input := `timestamp,endpoint,params,method
+2024-01-01T00:00:01Z,/cpu,duration=1s
+2024-01-01T00:00:00Z,/latency?duration=10ms,jitter=5ms
+2024-01-01T00:00:02.5Z,/queue/enqueue,count=3,post
+`
+	records, err := Parse(strings.NewReader(input), FormatCSV)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	want := []Record{
+		{0, "GET", "/latency", "duration=10ms&jitter=5ms"},
+		{time.Second, "GET", "/cpu", "duration=1s"},
+		{2500 * time.Millisecond, "POST", "/queue/enqueue", "count=3"},
+	}
+	for i, w := range want {
+		if records[i] != w {
+			t.Errorf("record %d = %+v, want %+v", i, records[i], w)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	lines := `{"timestamp": 100.0, "endpoint": "/cpu", "params": {"duration": "1s"}}
+{"timestamp": 100.25, "endpoint": "/memory", "params": "size=10Mi"}`
+	array := `[{"timestamp": "2024-01-01T00:00:00Z", "endpoint": "/cpu"}, {"timestamp": "2024-01-01T00:00:00.25Z", "endpoint": "/memory", "params": "size=10Mi"}]`
+
+	for name, input := range map[string]string{"lines": lines, "array": array} {
+		records, err := Parse(strings.NewReader(input), FormatJSON)
+		if err != nil {
+			t.Fatalf("%s: Parse() error = %v", name, err)
+		}
+		if len(records) != 2 {
+			t.Fatalf("%s: got %d records, want 2", name, len(records))
+		}
+		if records[1].Offset != 250*time.Millisecond || records[1].Query != "size=10Mi" {
+			t.Errorf("%s: record = %+v", name, records[1])
+		}
+	}
+}
+
+var invalidLogs = []struct {
+	name   string
+	format string
+	input  string
+}{
+	{"empty", FormatJSON, ""},
+	{"bad format", "xml", "<x/>"},
+	{"bad timestamp", FormatCSV, "yesterday,/cpu"},
+	{"relative path", FormatCSV, "0,cpu"},
+	{"admin endpoint", FormatCSV, "0,/admin/reset"},
+	{"bad params", FormatJSON, `{"timestamp": 0, "endpoint": "/cpu", "params": [1]}`},
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, tt := range invalidLogs {
+		if _, err := Parse(strings.NewReader(tt.input), tt.format); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
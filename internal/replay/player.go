@@ -0,0 +1,221 @@
+package replay
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Status describes the current or most recent replay.
+type Status = api.ReplayStatus
+
+// Header marks requests sent by the replayer so they can be told apart from
+// live traffic in logs.
+const Header = "X-Hotpod-Replay"
+
+// maxInFlight bounds concurrent replayed requests. Records that would exceed
+// it are counted as skipped rather than delaying the schedule.
+const maxInFlight = 1000
+
+// ErrRunning is returned by Start when a replay is already in progress.
+var ErrRunning = errors.New("a replay is already running")
+
+// Player replays records against a base URL.
+type Player struct {
+	baseURL string
+	client  *http.Client
+
+	mu     sync.Mutex
+	status Status
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	sent, skipped, errs atomic.Int64
+}
+
+// NewPlayer creates a player that sends requests to baseURL, typically the
+// local server's loopback address. An https base URL is not verified, since
+// the local server's certificate is usually self-signed.
+func NewPlayer(baseURL string) *Player {
+	client := &http.Client{Timeout: 5 * time.Minute}
+	if strings.HasPrefix(baseURL, "https://") {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	return &Player{baseURL: baseURL, client: client}
+}
+
+// Start begins replaying records in the background. speed scales the
+// original timing: 2 replays twice as fast, 0.5 at half speed. When loop is
+// true the log is replayed repeatedly until Stop is called.
+func (p *Player) Start(records []Record, speed float64, loop bool) error {
+	if speed <= 0 {
+		return errors.New("speed must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.status.Running {
+		return ErrRunning
+	}
+
+	now := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	p.sent.Store(0)
+	p.skipped.Store(0)
+	p.errs.Store(0)
+	p.status = Status{
+		Running:   true,
+		Total:     len(records),
+		Statuses:  map[int]int64{},
+		Speed:     speed,
+		Loop:      loop,
+		StartedAt: &now,
+	}
+
+	go p.run(ctx, records, speed, loop)
+	return nil
+}
+
+// Stop cancels a running replay and waits for it to finish dispatching.
+func (p *Player) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Status returns a snapshot of the replay state.
+func (p *Player) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.status
+	s.Sent = p.sent.Load()
+	s.Skipped = p.skipped.Load()
+	s.Errors = p.errs.Load()
+	s.Statuses = make(map[int]int64, len(p.status.Statuses))
+	for k, v := range p.status.Statuses {
+		s.Statuses[k] = v
+	}
+	if s.StartedAt != nil {
+		end := time.Now()
+		if s.FinishedAt != nil {
+			end = *s.FinishedAt
+		}
+		s.Duration = end.Sub(*s.StartedAt).Round(time.Millisecond).String()
+	}
+	return s
+}
+
+func (p *Player) run(ctx context.Context, records []Record, speed float64, loop bool) {
+	defer close(p.done)
+
+	slog.Info("replay started", "records", len(records), "speed", speed, "loop", loop)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxInFlight)
+
+	for iteration := 1; ; iteration++ {
+		p.mu.Lock()
+		p.status.Iteration = iteration
+		p.mu.Unlock()
+
+		if !p.playOnce(ctx, records, speed, sem, &wg) || !loop {
+			break
+		}
+	}
+
+	wg.Wait()
+
+	now := time.Now()
+	p.mu.Lock()
+	p.status.Running = false
+	p.status.FinishedAt = &now
+	p.cancel = nil
+	p.mu.Unlock()
+
+	slog.Info("replay finished", "sent", p.sent.Load(), "skipped", p.skipped.Load(), "errors", p.errs.Load())
+}
+
+// playOnce dispatches every record on schedule. It returns false if the
+// replay was cancelled.
+func (p *Player) playOnce(ctx context.Context, records []Record, speed float64, sem chan struct{}, wg *sync.WaitGroup) bool {
+	start := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for _, rec := range records {
+		due := start.Add(time.Duration(float64(rec.Offset) / speed))
+		if wait := time.Until(due); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return false
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return false
+		}
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			p.skipped.Add(1)
+			continue
+		}
+
+		wg.Add(1)
+		go func(rec Record) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			p.send(ctx, rec)
+		}(rec)
+	}
+	return true
+}
+
+func (p *Player) send(ctx context.Context, rec Record) {
+	target := p.baseURL + rec.Path
+	if rec.Query != "" {
+		target += "?" + rec.Query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, rec.Method, target, nil)
+	if err != nil {
+		p.errs.Add(1)
+		return
+	}
+	req.Header.Set(Header, "true")
+
+	p.sent.Add(1)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.errs.Add(1)
+			slog.Debug("replay request failed", "path", rec.Path, "error", err)
+		}
+		return
+	}
+	resp.Body.Close()
+
+	p.mu.Lock()
+	p.status.Statuses[resp.StatusCode]++
+	p.mu.Unlock()
+}
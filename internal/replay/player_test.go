@@ -0,0 +1,83 @@
+package replay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPlayerReplaysAtScaledSpeed(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(Header) != "true" {
+			t.Errorf("missing %s header", Header)
+		}
+		mu.Lock()
+		paths = append(paths, r.URL.RequestURI())
+		mu.Unlock()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	records := []Record{
+		{0, "GET", "/cpu", "duration=1s"},
+		{200 * time.Millisecond, "GET", "/fail", ""},
+	}
+
+	p := NewPlayer(srv.URL)
+	start := time.Now()
+	if err := p.Start(records, 4, false); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := p.Start(records, 1, false); err != ErrRunning {
+		t.Errorf("second Start() error = %v, want ErrRunning", err)
+	}
+
+	waitFor(t, func() bool { return !p.Status().Running })
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("replay took %s, want at least 50ms at 4x speed", elapsed)
+	}
+
+	s := p.Status()
+	if s.Sent != 2 || s.Statuses[200] != 1 || s.Statuses[500] != 1 {
+		t.Errorf("status = %+v", s)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) != 2 || paths[0] != "/cpu?duration=1s" {
+		t.Errorf("paths = %v", paths)
+	}
+}
+
+func TestPlayerStopLoop(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	p := NewPlayer(srv.URL)
+	if err := p.Start([]Record{{0, "GET", "/", ""}, {10 * time.Millisecond, "GET", "/", ""}}, 1, true); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return p.Status().Iteration > 2 })
+	p.Stop()
+
+	if s := p.Status(); s.Running || s.FinishedAt == nil {
+		t.Errorf("status after Stop = %+v", s)
+	}
+}
@@ -0,0 +1,62 @@
+// Package requestid carries a per-request correlation ID through contexts
+// and across hops, so chains of hotpod instances can be correlated end to
+// end.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// Header is the HTTP header carrying the request ID.
+const Header = "X-Request-ID"
+
+// maxLength bounds accepted request IDs so callers cannot bloat logs.
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a random 128-bit request ID.
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether id is acceptable as an incoming request ID: non-empty,
+// at most 128 bytes, and printable ASCII without spaces.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns a copy of ctx carrying id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Inject sets the request ID header on an outbound request from its context,
+// unless the header is already set.
+func Inject(req *http.Request) {
+	if req.Header.Get(Header) != "" {
+		return
+	}
+	if id := FromContext(req.Context()); id != "" {
+		req.Header.Set(Header, id)
+	}
+}
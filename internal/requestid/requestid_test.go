@@ -0,0 +1,64 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	a, b := New(), New()
+	if len(a) != 32 || !Valid(a) {
+		t.Errorf("New() = %q, want 32 hex characters", a)
+	}
+	if a == b {
+		t.Errorf("New() returned %q twice", a)
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"abc-123", true},
+		{"0f8fad5b-d9cb-469f-a165-70867728950e", true},
+		{"", false},
+		{"has space", false},
+		{"tab\there", false},
+		{"newline\n", false},
+		{"café", false},
+		{strings.Repeat("a", maxLength), true},
+		{strings.Repeat("a", maxLength+1), false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestInject(t *testing.T) {
+	ctx := WithID(context.Background(), "abc")
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.invalid/", nil)
+	Inject(req)
+	if got := req.Header.Get(Header); got != "abc" {
+		t.Errorf("Inject set %q, want %q", got, "abc")
+	}
+
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "http://example.invalid/", nil)
+	req.Header.Set(Header, "explicit")
+	Inject(req)
+	if got := req.Header.Get(Header); got != "explicit" {
+		t.Errorf("Inject overwrote header with %q", got)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "http://example.invalid/", nil)
+	Inject(req)
+	if got := req.Header.Get(Header); got != "" {
+		t.Errorf("Inject without ID set %q", got)
+	}
+}
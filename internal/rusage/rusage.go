@@ -0,0 +1,22 @@
+// Package rusage reports this process's resource usage counters: page
+// faults and CPU time.
+package rusage
+
+import "errors"
+
+// ErrUnsupported is returned on platforms without getrusage.
+var ErrUnsupported = errors.New("resource usage is not available on this platform")
+
+// Faults counts page faults taken by this process.
+type Faults struct {
+	// Minor faults were resolved without I/O, such as first touches of
+	// newly allocated memory
+	Minor uint64
+	// Major faults required I/O, such as reading a page back from swap
+	Major uint64
+}
+
+// Sub returns the faults taken since prev.
+func (f Faults) Sub(prev Faults) Faults {
+	return Faults{Minor: f.Minor - prev.Minor, Major: f.Major - prev.Major}
+}
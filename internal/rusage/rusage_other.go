@@ -0,0 +1,15 @@
+//go:build !unix
+
+package rusage
+
+import "time"
+
+// PageFaults is not implemented on this platform.
+func PageFaults() (Faults, error) {
+	return Faults{}, ErrUnsupported
+}
+
+// CPUTime is not implemented on this platform.
+func CPUTime() (time.Duration, error) {
+	return 0, ErrUnsupported
+}
@@ -0,0 +1,53 @@
+package rusage
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPageFaults(t *testing.T) {
+	before, err := PageFaults()
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("PageFaults() error = %v", err)
+	}
+
+	// Touching fresh pages takes minor faults.
+	data := make([]byte, 16<<20)
+	for i := 0; i < len(data); i += os.Getpagesize() {
+		data[i] = 1
+	}
+
+	after, err := PageFaults()
+	if err != nil {
+		t.Fatalf("PageFaults() error = %v", err)
+	}
+	if delta := after.Sub(before); delta.Minor == 0 {
+		t.Errorf("minor faults = 0 after touching %d bytes, want > 0", len(data))
+	}
+}
+
+func TestCPUTime(t *testing.T) {
+	before, err := CPUTime()
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("CPUTime() error = %v", err)
+	}
+
+	for start := time.Now(); time.Since(start) < 20*time.Millisecond; {
+	}
+
+	after, err := CPUTime()
+	if err != nil {
+		t.Fatalf("CPUTime() error = %v", err)
+	}
+	if after <= before {
+		t.Errorf("CPUTime() = %v after spinning, want more than %v", after, before)
+	}
+}
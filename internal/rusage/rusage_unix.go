@@ -0,0 +1,26 @@
+//go:build unix
+
+package rusage
+
+import (
+	"syscall"
+	"time"
+)
+
+// PageFaults returns the page faults taken by this process so far.
+func PageFaults() (Faults, error) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return Faults{}, err
+	}
+	return Faults{Minor: uint64(ru.Minflt), Major: uint64(ru.Majflt)}, nil
+}
+
+// CPUTime returns the user plus system CPU time this process has consumed.
+func CPUTime() (time.Duration, error) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0, err
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano()), nil
+}
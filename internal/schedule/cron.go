@@ -0,0 +1,192 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cronLookback bounds how far back Cron searches for the most recent match
+// when determining the current level.
+const cronLookback = 7 * 24 * time.Hour
+
+// Cron sets levels at times matching cron expressions.
+type Cron struct {
+	Entries []CronEntry
+
+	mu        sync.Mutex
+	lastMin   time.Time
+	lastLevel float64
+}
+
+// CronEntry is a cron expression and the level it sets.
+type CronEntry struct {
+	Expr  *CronExpr
+	Level float64
+}
+
+// Level implements Pattern. The level is that of the most recently matched
+// entry, or zero if none matched within the last week.
+func (c *Cron) Level(_ time.Duration, now time.Time) float64 {
+	minute := now.Truncate(time.Minute)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if minute.Equal(c.lastMin) {
+		return c.lastLevel
+	}
+
+	level := 0.0
+	for t := minute; now.Sub(t) <= cronLookback; t = t.Add(-time.Minute) {
+		if e, ok := c.match(t); ok {
+			level = e.Level
+			break
+		}
+	}
+
+	c.lastMin, c.lastLevel = minute, level
+	return level
+}
+
+// match returns the last entry matching t, so later entries win ties.
+func (c *Cron) match(t time.Time) (CronEntry, bool) {
+	for i := len(c.Entries) - 1; i >= 0; i-- {
+		if c.Entries[i].Expr.Matches(t) {
+			return c.Entries[i], true
+		}
+	}
+	return CronEntry{}, false
+}
+
+func parseCronPattern(args string, parseLevel LevelParser) (Pattern, error) {
+	c := &Cron{}
+	for _, part := range strings.Split(args, ";") {
+		part = strings.TrimSpace(part)
+		i := strings.LastIndex(part, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid cron entry %q: expected expression=level", part)
+		}
+		expr, err := ParseCronExpr(part[:i])
+		if err != nil {
+			return nil, err
+		}
+		level, err := parseLevel(part[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", part[:i], err)
+		}
+		c.Entries = append(c.Entries, CronEntry{Expr: expr, Level: level})
+	}
+	return c, nil
+}
+
+// CronExpr is a standard five-field cron expression: minute, hour, day of
+// month, month, and day of week. Fields support *, lists, ranges, and steps.
+type CronExpr struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var cronFields = []cronField{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// ParseCronExpr parses a five-field cron expression.
+func ParseCronExpr(s string) (*CronExpr, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields", s)
+	}
+
+	var bits [5]uint64
+	for i, f := range fields {
+		b, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", s, err)
+		}
+		bits[i] = b
+	}
+
+	return &CronExpr{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domStar: fields[2] == "*", dowStar: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(f string, r cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(f, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		lo, hi := r.min, r.max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", a)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value %q", b)
+				}
+			} else if hasStep {
+				hi = r.max
+			}
+		}
+
+		// Allow 7 as an alias for Sunday in the day-of-week field.
+		if r.max == 6 && hi == 7 {
+			bits |= 1
+			if lo == 7 {
+				continue
+			}
+			hi = 6
+		}
+
+		if lo < r.min || hi > r.max || lo > hi {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, r.min, r.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	if bits == 0 {
+		return 0, errors.New("empty field")
+	}
+	return bits, nil
+}
+
+// Matches reports whether t falls in a minute matched by the expression.
+// As in standard cron, when both day fields are restricted a day matches if
+// either does.
+func (c *CronExpr) Matches(t time.Time) bool {
+	if c.minute&(1<<uint(t.Minute())) == 0 || c.hour&(1<<uint(t.Hour())) == 0 || c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dowMatch
+	case c.dowStar:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+type cronMatchTest struct {
+	expr string
+	time string
+	want bool
+}
+
+var cronMatchTests = []cronMatchTest{
+	{"* * * * *", "2024-03-05T10:17:00Z", true},
+	{"0 9 * * 1-5", "2024-03-05T09:00:00Z", true},  // Tuesday
+	{"0 9 * * 1-5", "2024-03-09T09:00:00Z", false}, // Saturday
+	{"*/15 * * * *", "2024-03-05T10:45:00Z", true},
+	{"*/15 * * * *", "2024-03-05T10:46:00Z", false},
+	{"0 0 1,15 * *", "2024-03-15T00:00:00Z", true},
+	{"0 0 * * 7", "2024-03-10T00:00:00Z", true}, // Sunday
+	{"0 0 * * 7", "2024-03-11T00:00:00Z", false},
+	{"0 0 13 * 5", "2024-03-08T00:00:00Z", true}, // Friday, not the 13th
+	{"30 12 * 6 *", "2024-03-05T12:30:00Z", false},
+}
+
+func TestCronMatches(t *testing.T) {
+	for _, tt := range cronMatchTests {
+		e, err := ParseCronExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCronExpr(%q) error = %v", tt.expr, err)
+		}
+		ts, _ := time.Parse(time.RFC3339, tt.time)
+		if got := e.Matches(ts); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.expr, tt.time, got, tt.want)
+		}
+	}
+}
+
+func TestCronLevelHoldsLastMatch(t *testing.T) {
+	p, err := ParsePattern("cron:0 9 * * *=10;0 18 * * *=2", parseFloat)
+	if err != nil {
+		t.Fatalf("ParsePattern() error = %v", err)
+	}
+
+	tests := []struct {
+		time string
+		want float64
+	}{
+		{"2024-03-05T08:59:00Z", 2},
+		{"2024-03-05T09:00:00Z", 10},
+		{"2024-03-05T17:30:00Z", 10},
+		{"2024-03-05T23:00:00Z", 2},
+	}
+	for _, tt := range tests {
+		ts, _ := time.Parse(time.RFC3339, tt.time)
+		if got := p.Level(0, ts); got != tt.want {
+			t.Errorf("Level(%s) = %v, want %v", tt.time, got, tt.want)
+		}
+	}
+}
+
+func TestCronLevelNoMatch(t *testing.T) {
+	p, err := ParsePattern("cron:0 0 29 2 *=5", parseFloat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, _ := time.Parse(time.RFC3339, "2023-06-01T00:00:00Z")
+	if got := p.Level(0, ts); got != 0 {
+		t.Errorf("Level() = %v, want 0 with no recent match", got)
+	}
+}
@@ -0,0 +1,173 @@
+// Package schedule drives background CPU, memory, and queue load along
+// time-based patterns so long-running autoscaling soak tests need no external
+// load generator.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pattern yields the target level for a resource. elapsed is the time since
+// the scheduler started; now is the wall clock time.
+type Pattern interface {
+	Level(elapsed time.Duration, now time.Time) float64
+}
+
+// LevelParser converts a level string (e.g. "500m" or "256Mi") into the
+// resource's native unit.
+type LevelParser func(string) (float64, error)
+
+// ParsePattern parses a pattern spec of the form kind:args. Supported kinds:
+//
+//	sine:period=24h,min=100m,max=1500m[,phase=6h]
+//	step:0s=100m,1h=1500m,90m=100m[,period=2h]
+//	cron:0 9 * * 1-5=1500m;0 18 * * *=100m
+//
+// Sine oscillates between min and max. Step holds each level from its offset
+// until the next, repeating every period if given. Cron sets a level whenever
+// its five-field expression matches, holding it until another entry matches.
+func ParsePattern(spec string, parseLevel LevelParser) (Pattern, error) {
+	kind, args, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid pattern %q: expected kind:args", spec)
+	}
+
+	switch kind {
+	case "sine":
+		return parseSine(args, parseLevel)
+	case "step":
+		return parseStep(args, parseLevel)
+	case "cron":
+		return parseCronPattern(args, parseLevel)
+	default:
+		return nil, fmt.Errorf("invalid pattern kind %q: must be sine, step, or cron", kind)
+	}
+}
+
+// Sine oscillates smoothly between Min and Max once per Period. It starts at
+// the midpoint, rising, unless shifted by Phase.
+type Sine struct {
+	Period time.Duration
+	Phase  time.Duration
+	Min    float64
+	Max    float64
+}
+
+// Level implements Pattern.
+func (s *Sine) Level(elapsed time.Duration, _ time.Time) float64 {
+	x := 2 * math.Pi * float64(elapsed+s.Phase) / float64(s.Period)
+	return s.Min + (s.Max-s.Min)*(1+math.Sin(x))/2
+}
+
+func parseSine(args string, parseLevel LevelParser) (Pattern, error) {
+	kv, err := parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sine{}
+	for k, v := range kv {
+		switch k {
+		case "period":
+			s.Period, err = time.ParseDuration(v)
+		case "phase":
+			s.Phase, err = time.ParseDuration(v)
+		case "min":
+			s.Min, err = parseLevel(v)
+		case "max":
+			s.Max, err = parseLevel(v)
+		default:
+			err = fmt.Errorf("unknown sine argument %q", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sine %s: %w", k, err)
+		}
+	}
+
+	if s.Period <= 0 {
+		return nil, errors.New("sine period must be positive")
+	}
+	if s.Max < s.Min {
+		return nil, errors.New("sine max must be at least min")
+	}
+	return s, nil
+}
+
+// Step holds a sequence of levels, each starting at its offset.
+type Step struct {
+	Steps []StepLevel
+	// Period, if positive, repeats the sequence.
+	Period time.Duration
+}
+
+// StepLevel is a level that takes effect at an offset.
+type StepLevel struct {
+	At    time.Duration
+	Level float64
+}
+
+// Level implements Pattern.
+func (s *Step) Level(elapsed time.Duration, _ time.Time) float64 {
+	if s.Period > 0 {
+		elapsed %= s.Period
+	}
+	level := 0.0
+	for _, st := range s.Steps {
+		if st.At > elapsed {
+			break
+		}
+		level = st.Level
+	}
+	return level
+}
+
+func parseStep(args string, parseLevel LevelParser) (Pattern, error) {
+	s := &Step{}
+	for _, part := range strings.Split(args, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid step %q: expected offset=level", part)
+		}
+		if k == "period" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid step period %q", v)
+			}
+			s.Period = d
+			continue
+		}
+
+		at, err := time.ParseDuration(k)
+		if err != nil || at < 0 {
+			return nil, fmt.Errorf("invalid step offset %q", k)
+		}
+		level, err := parseLevel(v)
+		if err != nil {
+			return nil, fmt.Errorf("step %s: %w", k, err)
+		}
+		s.Steps = append(s.Steps, StepLevel{At: at, Level: level})
+	}
+
+	if len(s.Steps) == 0 {
+		return nil, errors.New("step pattern needs at least one offset=level")
+	}
+	sort.Slice(s.Steps, func(i, j int) bool { return s.Steps[i].At < s.Steps[j].At })
+	return s, nil
+}
+
+func parseArgs(args string) (map[string]string, error) {
+	kv := map[string]string{}
+	for _, part := range strings.Split(args, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid argument %q: expected key=value", part)
+		}
+		kv[k] = v
+	}
+	return kv, nil
+}
@@ -0,0 +1,81 @@
+package schedule
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func TestSine(t *testing.T) {
+	p, err := ParsePattern("sine:period=4h,min=1,max=3", parseFloat)
+	if err != nil {
+		t.Fatalf("ParsePattern() error = %v", err)
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 2},
+		{time.Hour, 3},
+		{2 * time.Hour, 2},
+		{3 * time.Hour, 1},
+		{4 * time.Hour, 2},
+	}
+	for _, tt := range tests {
+		if got := p.Level(tt.elapsed, time.Time{}); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Level(%s) = %v, want %v", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestStep(t *testing.T) {
+	p, err := ParsePattern("step:1h=5,0s=1,90m=2,period=2h", parseFloat)
+	if err != nil {
+		t.Fatalf("ParsePattern() error = %v", err)
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 1},
+		{59 * time.Minute, 1},
+		{time.Hour, 5},
+		{100 * time.Minute, 2},
+		{2*time.Hour + time.Minute, 1},
+	}
+	for _, tt := range tests {
+		if got := p.Level(tt.elapsed, time.Time{}); got != tt.want {
+			t.Errorf("Level(%s) = %v, want %v", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+var invalidPatterns = []string{
+	"",
+	"square:period=1h",
+	"sine:min=1,max=2",
+	"sine:period=1h,min=3,max=2",
+	"sine:period=1h,amplitude=2",
+	"step:",
+	"step:1h",
+	"step:soon=1",
+	"step:0s=lots",
+	"cron:0 9 * *=1",
+	"cron:0 9 * * *",
+	"cron:61 * * * *=1",
+}
+
+func TestParsePatternInvalid(t *testing.T) {
+	for _, spec := range invalidPatterns {
+		if _, err := ParsePattern(spec, parseFloat); err == nil {
+			t.Errorf("ParsePattern(%q) should error", spec)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/queue"
+)
+
+// tick is how often targets are re-evaluated and CPU is burned.
+const tick = 100 * time.Millisecond
+
+// memoryChunk is the granularity at which scheduled memory is held, so small
+// changes in the target do not reallocate everything.
+const memoryChunk = 1 << 20
+
+// queueItemProcessingTime is the processing time of items enqueued to reach
+// the scheduled queue depth.
+const queueItemProcessingTime = 100 * time.Millisecond
+
+// Scheduler drives background load to follow configured patterns. Any
+// pattern may be nil to leave that resource alone.
+type Scheduler struct {
+	cpu    Pattern
+	memory Pattern
+	depth  Pattern
+	queue  *queue.Queue
+	// paused, when set and true, suspends the patterns and releases held
+	// memory until it turns false
+	paused func() bool
+
+	mu     sync.Mutex
+	chunks [][]byte
+	seq    int64
+}
+
+// New creates a scheduler. cpu is in cores, memory in bytes, and depth in
+// queue items; q may be nil if the queue is unavailable.
+func New(cpu, memory, depth Pattern, q *queue.Queue) *Scheduler {
+	return &Scheduler{cpu: cpu, memory: memory, depth: depth, queue: q}
+}
+
+// SetPause makes the scheduler hold no load while paused returns true, as
+// when backing off from node pressure. It must be called before Run.
+func (s *Scheduler) SetPause(paused func() bool) {
+	s.paused = paused
+}
+
+// Enabled reports whether any pattern is configured.
+func (s *Scheduler) Enabled() bool {
+	return s.cpu != nil || s.memory != nil || (s.depth != nil && s.queue != nil)
+}
+
+// Run drives load until ctx is cancelled, then releases held memory.
+func (s *Scheduler) Run(ctx context.Context) {
+	start := time.Now()
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	defer s.setMemory(0)
+
+	slog.Info("load scheduler started",
+		"cpu", s.cpu != nil,
+		"memory", s.memory != nil,
+		"queue", s.depth != nil && s.queue != nil,
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("load scheduler stopped")
+			return
+		case now := <-ticker.C:
+			if s.paused != nil && s.paused() {
+				s.setMemory(0)
+				continue
+			}
+			elapsed := now.Sub(start)
+			if s.cpu != nil {
+				cores := math.Max(0, s.cpu.Level(elapsed, now))
+				metrics.ScheduleTarget.WithLabelValues("cpu").Set(cores)
+				go burn(cores, tick)
+			}
+			if s.memory != nil {
+				bytes := int64(math.Max(0, s.memory.Level(elapsed, now)))
+				metrics.ScheduleTarget.WithLabelValues("memory").Set(float64(bytes))
+				s.setMemory(bytes)
+			}
+			if s.depth != nil && s.queue != nil {
+				depth := int(math.Max(0, s.depth.Level(elapsed, now)))
+				metrics.ScheduleTarget.WithLabelValues("queue").Set(float64(depth))
+				s.fillQueue(depth, now)
+			}
+		}
+	}
+}
+
+// burn consumes cores worth of CPU over the window, spread across goroutines.
+func burn(cores float64, window time.Duration) {
+	if cores <= 0 {
+		return
+	}
+	workers := int(math.Ceil(cores))
+	share := time.Duration(float64(window) * cores / float64(workers))
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deadline := time.Now().Add(share)
+			x := 1.0
+			for time.Now().Before(deadline) {
+				for range 1000 {
+					x = math.Sin(x) + math.Cos(x)
+					x = math.Sqrt(math.Abs(x) + 1)
+				}
+			}
+			runtime.KeepAlive(x)
+		}()
+	}
+	wg.Wait()
+}
+
+// setMemory grows or shrinks held memory to the target, in whole chunks.
+func (s *Scheduler) setMemory(target int64) {
+	want := int((target + memoryChunk - 1) / memoryChunk)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.chunks) < want {
+		chunk := make([]byte, memoryChunk)
+		// Touch every page so the memory counts against the container.
+		for i := 0; i < len(chunk); i += 4096 {
+			chunk[i] = 1
+		}
+		s.chunks = append(s.chunks, chunk)
+	}
+	if len(s.chunks) > want {
+		clear(s.chunks[want:])
+		s.chunks = s.chunks[:want]
+	}
+}
+
+// HeldMemory returns the bytes currently held for the memory pattern.
+func (s *Scheduler) HeldMemory() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.chunks)) * memoryChunk
+}
+
+// fillQueue enqueues items until the queue reaches the target depth. Workers
+// drain the queue independently, so depth only approximates the target.
+func (s *Scheduler) fillQueue(target int, now time.Time) {
+	for depth := s.queue.Depth(); depth < target; depth++ {
+		s.seq++
+		item := &queue.Item{
+			ID:             fmt.Sprintf("sched-%d", s.seq),
+			Priority:       queue.PriorityNormal,
+			ProcessingTime: queueItemProcessingTime,
+			EnqueuedAt:     now,
+		}
+		if err := s.queue.Enqueue(item); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package schedule
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/queue"
+)
+
+func TestSchedulerDrivesMemoryAndQueue(t *testing.T) {
+	memory := &Step{Steps: []StepLevel{{0, 3 * memoryChunk}}}
+	depth := &Step{Steps: []StepLevel{{0, 5}}}
+	q := queue.New(100)
+
+	s := New(nil, memory, depth, q)
+	if !s.Enabled() {
+		t.Fatal("Enabled() should be true")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for (s.HeldMemory() != 3*memoryChunk || q.Depth() != 5) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := s.HeldMemory(); got != 3*memoryChunk {
+		t.Errorf("HeldMemory() = %d, want %d", got, 3*memoryChunk)
+	}
+	if got := q.Depth(); got != 5 {
+		t.Errorf("queue depth = %d, want 5", got)
+	}
+
+	cancel()
+	<-done
+	if got := s.HeldMemory(); got != 0 {
+		t.Errorf("HeldMemory() after stop = %d, want 0", got)
+	}
+}
+
+func TestSchedulerPause(t *testing.T) {
+	memory := &Step{Steps: []StepLevel{{0, 2 * memoryChunk}}}
+	var paused atomic.Bool
+
+	s := New(nil, memory, nil, nil)
+	s.SetPause(paused.Load)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	waitFor := func(want int64) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for s.HeldMemory() != want && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := s.HeldMemory(); got != want {
+			t.Fatalf("HeldMemory() = %d, want %d", got, want)
+		}
+	}
+
+	waitFor(2 * memoryChunk)
+	paused.Store(true)
+	waitFor(0)
+	paused.Store(false)
+	waitFor(2 * memoryChunk)
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// ShutdownPhase names a stage of graceful shutdown.
+type ShutdownPhase = api.ShutdownPhase
+
+// PhaseStats describes one shutdown phase.
+type PhaseStats = api.ShutdownPhaseStats
+
+// ConnectionStats counts client connections by state.
+type ConnectionStats = api.ConnectionStats
+
+// DrainStatus is a snapshot of connection draining progress.
+type DrainStatus = api.DrainStatus
+
+const (
+	// PhaseNone means shutdown has not started.
+	PhaseNone ShutdownPhase = ""
+	// PhasePreStop is the configured shutdown delay, while endpoints are
+	// being removed from load balancers.
+	PhasePreStop ShutdownPhase = "pre_stop"
+	// PhaseDraining waits for in-flight requests to complete.
+	PhaseDraining ShutdownPhase = "draining"
+	// PhaseForced begins once the shutdown timeout is exceeded with requests
+	// still in flight; they are abandoned when the process exits.
+	PhaseForced ShutdownPhase = "forced"
+	// PhaseComplete means shutdown has finished.
+	PhaseComplete ShutdownPhase = "complete"
+)
+
+var shutdownPhases = []ShutdownPhase{PhasePreStop, PhaseDraining, PhaseForced}
+
+// drainTracker records connection states and per-phase shutdown statistics.
+type drainTracker struct {
+	mu     sync.Mutex
+	conns  map[net.Conn]http.ConnState
+	active int64
+	idle   int64
+	phase  ShutdownPhase
+	phases []*PhaseStats
+}
+
+// ConnState is an http.Server ConnState hook that tracks open, active, and
+// idle client connections.
+func (lc *Lifecycle) ConnState(c net.Conn, state http.ConnState) {
+	d := &lc.drain
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conns == nil {
+		d.conns = make(map[net.Conn]http.ConnState)
+	}
+
+	prev, known := d.conns[c]
+	if known {
+		d.adjust(prev, -1)
+	}
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(d.conns, c)
+	default:
+		d.conns[c] = state
+		d.adjust(state, 1)
+	}
+
+	metrics.OpenConnections.WithLabelValues("active").Set(float64(d.active))
+	metrics.OpenConnections.WithLabelValues("idle").Set(float64(d.idle))
+}
+
+func (d *drainTracker) adjust(state http.ConnState, delta int64) {
+	switch state {
+	case http.StateNew, http.StateActive:
+		d.active += delta
+	case http.StateIdle:
+		d.idle += delta
+	}
+}
+
+// enterPhase ends the current phase, if any, and starts the next one.
+func (lc *Lifecycle) enterPhase(next ShutdownPhase) {
+	d := &lc.drain
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The HTTP server may finish before a slow pre-stop delay elapses; later
+	// phases are not reopened once shutdown is complete.
+	if d.phase == PhaseComplete {
+		return
+	}
+
+	now := lc.clock.Now()
+	inFlight := lc.inFlight.Load()
+	conns := int64(len(d.conns))
+	lc.endPhaseLocked(now, inFlight, conns)
+
+	d.phase = next
+	if next == PhaseComplete {
+		return
+	}
+
+	d.phases = append(d.phases, &PhaseStats{
+		Phase:              next,
+		StartedAt:          now,
+		InFlightAtStart:    inFlight,
+		ConnectionsAtStart: conns,
+	})
+	for _, p := range shutdownPhases {
+		v := 0.0
+		if p == next {
+			v = 1
+		}
+		metrics.ShutdownPhase.WithLabelValues(string(p)).Set(v)
+	}
+	metrics.ShutdownInFlightRequests.WithLabelValues(string(next)).Set(float64(inFlight))
+	metrics.ShutdownOpenConnections.WithLabelValues(string(next)).Set(float64(conns))
+
+	slog.Info("shutdown phase", "phase", next, "in_flight", inFlight, "connections", conns)
+}
+
+func (lc *Lifecycle) endPhaseLocked(now time.Time, inFlight, conns int64) {
+	d := &lc.drain
+	if len(d.phases) == 0 || d.phase == PhaseComplete {
+		return
+	}
+	cur := d.phases[len(d.phases)-1]
+	if cur.EndedAt != nil {
+		return
+	}
+
+	duration := now.Sub(cur.StartedAt)
+	cur.EndedAt = &now
+	cur.Duration = duration.String()
+	cur.InFlightAtEnd = &inFlight
+	cur.ConnectionsAtEnd = &conns
+	metrics.ShutdownPhase.WithLabelValues(string(cur.Phase)).Set(0)
+	metrics.ShutdownPhaseDurationSeconds.WithLabelValues(string(cur.Phase)).Set(duration.Seconds())
+}
+
+// requestDone attributes a completed request to the current shutdown phase.
+func (lc *Lifecycle) requestDone() {
+	if !lc.IsShuttingDown() {
+		return
+	}
+
+	d := &lc.drain
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.phases) == 0 || d.phase == PhaseComplete {
+		return
+	}
+	cur := d.phases[len(d.phases)-1]
+	cur.RequestsCompleted++
+	metrics.ShutdownRequestsCompletedTotal.WithLabelValues(string(cur.Phase)).Inc()
+}
+
+// FinishShutdown closes the last shutdown phase and logs a draining summary.
+// It is called once the HTTP server has stopped.
+func (lc *Lifecycle) FinishShutdown() {
+	lc.enterPhase(PhaseComplete)
+
+	status := lc.DrainStatus()
+	for _, p := range status.Phases {
+		slog.Info("shutdown phase summary",
+			"phase", p.Phase,
+			"duration", p.Duration,
+			"in_flight_at_start", p.InFlightAtStart,
+			"connections_at_start", p.ConnectionsAtStart,
+			"requests_completed", p.RequestsCompleted,
+		)
+	}
+	events.Record(slog.LevelInfo, events.TypeLifecycle, "connection draining finished", map[string]any{
+		"in_flight":   status.InFlight,
+		"connections": status.Connections.Open,
+	})
+}
+
+// CurrentPhase returns the active shutdown phase.
+func (lc *Lifecycle) CurrentPhase() ShutdownPhase {
+	lc.drain.mu.Lock()
+	defer lc.drain.mu.Unlock()
+	return lc.drain.phase
+}
+
+// DrainStatus returns a snapshot of connections, in-flight requests, and
+// per-phase shutdown statistics.
+func (lc *Lifecycle) DrainStatus() DrainStatus {
+	d := &lc.drain
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := lc.clock.Now()
+	status := DrainStatus{
+		State:    lc.State().String(),
+		Phase:    d.phase,
+		InFlight: lc.inFlight.Load(),
+		Connections: ConnectionStats{
+			Open:   int64(len(d.conns)),
+			Active: d.active,
+			Idle:   d.idle,
+		},
+		Phases: make([]PhaseStats, 0, len(d.phases)),
+	}
+
+	for i, p := range d.phases {
+		snap := *p
+		if i == 0 {
+			started := p.StartedAt
+			status.ShutdownStarted = &started
+		}
+		if p.EndedAt == nil {
+			snap.Duration = now.Sub(p.StartedAt).String()
+		}
+		status.Phases = append(status.Phases, snap)
+	}
+	return status
+}
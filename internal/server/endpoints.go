@@ -0,0 +1,121 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// EndpointGates enables and disables groups of endpoints, so restricted
+// clusters can expose only a safe subset. It is safe for concurrent use.
+type EndpointGates struct {
+	groups []string
+
+	mu       sync.RWMutex
+	disabled map[string]bool
+}
+
+// NewEndpointGates creates gates for groups, with the groups in disabled
+// turned off.
+func NewEndpointGates(groups, disabled []string) *EndpointGates {
+	g := &EndpointGates{groups: groups, disabled: map[string]bool{}}
+	for _, group := range groups {
+		g.disabled[group] = slices.Contains(disabled, group)
+		metrics.EndpointGroupEnabled.WithLabelValues(group).Set(enabledValue(!g.disabled[group]))
+	}
+	return g
+}
+
+// Set enables or disables each group in changes, a map of group to whether
+// it is enabled. Nothing is changed if any group is unknown.
+func (g *EndpointGates) Set(changes map[string]bool) error {
+	for group := range changes {
+		if !slices.Contains(g.groups, group) {
+			return fmt.Errorf("endpoint groups must be among %s, got %q", strings.Join(g.groups, ", "), group)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for group, enabled := range changes {
+		if g.disabled[group] != enabled {
+			continue
+		}
+		g.disabled[group] = !enabled
+		metrics.EndpointGroupEnabled.WithLabelValues(group).Set(enabledValue(enabled))
+
+		slog.Info("endpoint group toggled", "group", group, "enabled", enabled)
+		events.Record(slog.LevelInfo, events.TypeAdmin, "endpoint group toggled", map[string]any{
+			"group":   group,
+			"enabled": enabled,
+		})
+	}
+	return nil
+}
+
+// Enabled reports whether group is enabled. Groups that cannot be gated
+// are always enabled.
+func (g *EndpointGates) Enabled(group string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return !g.disabled[group]
+}
+
+// Allows reports whether requests to path are let through by its group's
+// gate. A nil EndpointGates allows everything.
+func (g *EndpointGates) Allows(path string) bool {
+	return g == nil || g.Enabled(gatedGroup(path))
+}
+
+// Status returns whether each group is enabled, in configured order.
+func (g *EndpointGates) Status() []api.EndpointGroupStatus {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	status := make([]api.EndpointGroupStatus, len(g.groups))
+	for i, group := range g.groups {
+		status[i] = api.EndpointGroupStatus{Name: group, Enabled: !g.disabled[group]}
+	}
+	return status
+}
+
+func enabledValue(enabled bool) float64 {
+	if enabled {
+		return 1
+	}
+	return 0
+}
+
+// gatedGroup returns the endpoint group path is gated by: its first
+// segment, except for endpoints that belong to another group's family.
+func gatedGroup(path string) string {
+	switch path {
+	case "/upload":
+		return "io"
+	case "/stream-latency":
+		return "latency"
+	}
+	return endpointGroup(path)
+}
+
+// EndpointGate returns middleware that rejects requests to disabled
+// endpoint groups with 403.
+func EndpointGate(g *EndpointGates) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			group := gatedGroup(r.URL.Path)
+			if g.Enabled(group) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			writeError(w, http.StatusForbidden, errcode.EndpointDisabled, group+" endpoints are disabled")
+		})
+	}
+}
@@ -9,7 +9,9 @@ import (
 
 	"github.com/jonboulle/clockwork"
 
+	"github.com/ripta/hotpod/internal/events"
 	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/wallclock"
 )
 
 // State represents the server lifecycle state.
@@ -42,6 +44,10 @@ type Lifecycle struct {
 	state atomic.Int32
 	// readyOverride overrides the readiness check (0=no override, 1=force not-ready, 2=force ready)
 	readyOverride atomic.Int32
+	// preStop is set once a preStop hook has been received, marking the server not-ready
+	preStop atomic.Bool
+	// exitCode is the status to exit with after graceful shutdown (-1 = unset)
+	exitCode atomic.Int32
 	// inFlight tracks the number of requests currently being processed
 	inFlight atomic.Int64
 	// startTime is when the lifecycle was created
@@ -57,6 +63,9 @@ type Lifecycle struct {
 	shutdownDelay time.Duration
 	// shutdownTimeout is the max time to wait for in-flight requests to complete
 	shutdownTimeout time.Duration
+
+	// drain tracks client connections and per-phase shutdown statistics
+	drain drainTracker
 }
 
 // NewLifecycle creates a new lifecycle manager.
@@ -80,6 +89,7 @@ func NewLifecycleWithClock(clock clockwork.Clock, startupDelay, startupJitter, s
 		shutdownTimeout:  shutdownTimeout,
 	}
 	lc.state.Store(int32(StateStarting))
+	lc.exitCode.Store(-1)
 
 	if actualDelay > 0 {
 		slog.Info("startup delay configured", "delay", actualDelay)
@@ -104,6 +114,9 @@ func (lc *Lifecycle) becomeReady() {
 	metrics.StartupDurationSeconds.Set(lc.readyTime.Sub(lc.startTime).Seconds())
 
 	slog.Info("server is ready")
+	events.Record(slog.LevelInfo, events.TypeLifecycle, "server is ready", map[string]any{
+		"startup_duration": lc.readyTime.Sub(lc.startTime).String(),
+	})
 }
 
 // State returns the current lifecycle state.
@@ -150,9 +163,46 @@ func (lc *Lifecycle) IsReady() bool {
 	if override := lc.readyOverride.Load(); override != readyOverrideNone {
 		return override == readyOverrideReady
 	}
+	if lc.preStop.Load() {
+		return false
+	}
 	return lc.State() == StateReady
 }
 
+// BeginPreStop marks the server not-ready ahead of termination, as a pod
+// preStop hook would. It returns false if a preStop was already in progress.
+func (lc *Lifecycle) BeginPreStop() bool {
+	if !lc.preStop.CompareAndSwap(false, true) {
+		return false
+	}
+	slog.Info("preStop hook received, marking not ready", "in_flight", lc.inFlight.Load())
+	events.Record(slog.LevelInfo, events.TypeLifecycle, "preStop hook received", map[string]any{
+		"in_flight": lc.inFlight.Load(),
+	})
+	return true
+}
+
+// SetExitCode sets the status the process exits with after the next graceful
+// shutdown. A negative code clears it.
+func (lc *Lifecycle) SetExitCode(code int) {
+	if code < 0 {
+		code = -1
+	}
+	lc.exitCode.Store(int32(code))
+}
+
+// ExitCode returns the status to exit with after graceful shutdown, and
+// whether one was set.
+func (lc *Lifecycle) ExitCode() (int, bool) {
+	code := int(lc.exitCode.Load())
+	return code, code >= 0
+}
+
+// InPreStop reports whether a preStop hook has been received.
+func (lc *Lifecycle) InPreStop() bool {
+	return lc.preStop.Load()
+}
+
 // IsShuttingDown returns true if the server is shutting down.
 func (lc *Lifecycle) IsShuttingDown() bool {
 	return lc.State() == StateShuttingDown
@@ -181,6 +231,7 @@ func (lc *Lifecycle) TrackRequest() func() {
 	lc.inFlight.Add(1)
 	return func() {
 		lc.inFlight.Add(-1)
+		lc.requestDone()
 	}
 }
 
@@ -209,11 +260,15 @@ func (lc *Lifecycle) Shutdown(ctx context.Context) error {
 	lc.state.Store(int32(StateShuttingDown))
 
 	metrics.ShutdownInProgress.Set(1)
-	metrics.ShutdownStartedTimestamp.Set(float64(lc.clock.Now().Unix()))
+	metrics.ShutdownStartedTimestamp.Set(float64(lc.clock.Now().Add(wallclock.Skew()).Unix()))
 
 	slog.Info("shutdown initiated")
+	events.Record(slog.LevelInfo, events.TypeLifecycle, "shutdown initiated", map[string]any{
+		"in_flight": lc.inFlight.Load(),
+	})
 
 	if lc.shutdownDelay > 0 {
+		lc.enterPhase(PhasePreStop)
 		slog.Info("pre-stop delay", "delay", lc.shutdownDelay)
 		select {
 		case <-lc.clock.After(lc.shutdownDelay):
@@ -222,10 +277,15 @@ func (lc *Lifecycle) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	lc.enterPhase(PhaseDraining)
 	deadline := lc.clock.Now().Add(lc.shutdownTimeout)
 	for lc.inFlight.Load() > 0 {
 		if lc.clock.Now().After(deadline) {
+			lc.enterPhase(PhaseForced)
 			slog.Warn("shutdown timeout exceeded", "in_flight", lc.inFlight.Load())
+			events.Record(slog.LevelWarn, events.TypeLifecycle, "shutdown timeout exceeded", map[string]any{
+				"in_flight": lc.inFlight.Load(),
+			})
 			break
 		}
 		select {
@@ -236,5 +296,8 @@ func (lc *Lifecycle) Shutdown(ctx context.Context) error {
 	}
 
 	slog.Info("shutdown complete", "in_flight", lc.inFlight.Load())
+	events.Record(slog.LevelInfo, events.TypeLifecycle, "shutdown complete", map[string]any{
+		"in_flight": lc.inFlight.Load(),
+	})
 	return nil
 }
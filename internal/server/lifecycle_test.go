@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"net"
+	"net/http"
 	"testing"
 	"time"
 
@@ -266,3 +268,97 @@ func TestStateString(t *testing.T) {
 		}
 	}
 }
+
+func TestLifecycleDrainPhases(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	lc := NewLifecycleWithClock(clock, 0, 0, time.Second, 5*time.Second, false)
+
+	done := lc.TrackRequest()
+	errCh := make(chan error, 1)
+	go func() { errCh <- lc.Shutdown(context.Background()) }()
+
+	if err := clock.BlockUntilContext(context.Background(), 1); err != nil {
+		t.Fatalf("BlockUntilContext: %v", err)
+	}
+	if got := lc.CurrentPhase(); got != PhasePreStop {
+		t.Fatalf("phase = %q, want pre_stop", got)
+	}
+	clock.Advance(time.Second)
+
+	if err := clock.BlockUntilContext(context.Background(), 1); err != nil {
+		t.Fatalf("BlockUntilContext: %v", err)
+	}
+	if got := lc.CurrentPhase(); got != PhaseDraining {
+		t.Fatalf("phase = %q, want draining", got)
+	}
+	done()
+	clock.Advance(100 * time.Millisecond)
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	lc.FinishShutdown()
+
+	status := lc.DrainStatus()
+	if status.Phase != PhaseComplete {
+		t.Errorf("final phase = %q, want complete", status.Phase)
+	}
+	if len(status.Phases) != 2 {
+		t.Fatalf("phases = %+v, want pre_stop and draining", status.Phases)
+	}
+	pre, drain := status.Phases[0], status.Phases[1]
+	if pre.Duration != "1s" || pre.InFlightAtStart != 1 {
+		t.Errorf("pre_stop = %+v", pre)
+	}
+	if drain.RequestsCompleted != 1 || drain.InFlightAtEnd == nil || *drain.InFlightAtEnd != 0 {
+		t.Errorf("draining = %+v", drain)
+	}
+}
+
+func TestLifecycleDrainForced(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	lc := NewLifecycleWithClock(clock, 0, 0, 0, time.Second, false)
+
+	lc.TrackRequest()
+	errCh := make(chan error, 1)
+	go func() { errCh <- lc.Shutdown(context.Background()) }()
+
+	for i := 0; i < 11; i++ {
+		if err := clock.BlockUntilContext(context.Background(), 1); err != nil {
+			t.Fatalf("BlockUntilContext: %v", err)
+		}
+		clock.Advance(100 * time.Millisecond)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if got := lc.CurrentPhase(); got != PhaseForced {
+		t.Errorf("phase = %q, want forced", got)
+	}
+}
+
+func TestLifecycleConnState(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	lc := NewLifecycleWithClock(clock, 0, 0, 0, time.Second, false)
+
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	lc.ConnState(a, http.StateNew)
+	lc.ConnState(b, http.StateNew)
+	lc.ConnState(a, http.StateActive)
+	lc.ConnState(a, http.StateIdle)
+
+	got := lc.DrainStatus().Connections
+	if got != (ConnectionStats{Open: 2, Active: 1, Idle: 1}) {
+		t.Errorf("connections = %+v", got)
+	}
+
+	lc.ConnState(a, http.StateClosed)
+	lc.ConnState(b, http.StateHijacked)
+	if got := lc.DrainStatus().Connections; got != (ConnectionStats{}) {
+		t.Errorf("connections after close = %+v", got)
+	}
+}
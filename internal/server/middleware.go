@@ -1,21 +1,37 @@
 package server
 
 import (
-	"fmt"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
+	"maps"
+	"math"
 	"net/http"
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ripta/hotpod/internal/audit"
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
 	"github.com/ripta/hotpod/internal/fault"
 	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/nodepressure"
+	"github.com/ripta/hotpod/internal/proxyproto"
+	"github.com/ripta/hotpod/internal/requestid"
+	"github.com/ripta/hotpod/internal/shed"
+	"github.com/ripta/hotpod/internal/slo"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
 )
 
 // responseWriter wraps http.ResponseWriter to capture status code.
-//
-// TODO(ripta): No support for http.Hijacker, http.Flusher, or http.CloseNotifier
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode  int
@@ -31,6 +47,28 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap allows http.ResponseController to reach the underlying writer, so
+// handlers can flush streamed responses.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// RequestID returns middleware that reads the X-Request-ID header, generating
+// an ID if it is missing or invalid. The ID is echoed in the response and
+// carried in the request context for logs, events, and outbound calls.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+			r.Header.Set(requestid.Header, id)
+		}
+		w.Header().Set(requestid.Header, id)
+
+		next.ServeHTTP(w, r.WithContext(requestid.WithID(r.Context(), id)))
+	})
+}
+
 // Logging returns middleware that logs requests.
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -39,13 +77,22 @@ func Logging(next http.Handler) http.Handler {
 
 		next.ServeHTTP(rw, r)
 
-		slog.Info("request",
+		attrs := []any{
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", rw.statusCode,
 			"duration", time.Since(start),
 			"remote", r.RemoteAddr,
-		)
+			"request_id", requestid.FromContext(r.Context()),
+		}
+		// With PROXY protocol, remote is the original client; also log the
+		// load balancer it came through.
+		if pc := proxyproto.FromContext(r.Context()); pc != nil {
+			if h, _ := pc.Header(); h != nil {
+				attrs = append(attrs, "proxy", pc.ProxyAddr().String())
+			}
+		}
+		slog.Info("request", attrs...)
 	})
 }
 
@@ -57,9 +104,10 @@ func Recovery(next http.Handler) http.Handler {
 				slog.Error("panic recovered",
 					"error", err,
 					"path", r.URL.Path,
+					"request_id", requestid.FromContext(r.Context()),
 					"stack", string(debug.Stack()),
 				)
-				http.Error(w, `{"error":"internal server error","code":"INTERNAL_ERROR"}`, http.StatusInternalServerError)
+				writeError(w, http.StatusInternalServerError, errcode.InternalError, "internal server error")
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -77,16 +125,30 @@ func RequestTracking(lc *Lifecycle) func(http.Handler) http.Handler {
 	}
 }
 
+// writeError writes a JSON error response in the same shape as hotpod's
+// handlers.
+func writeError(w http.ResponseWriter, status int, code errcode.Code, message string) {
+	resp := errcode.Response(code, message, w.Header().Get(requestid.Header))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Warn("failed to encode error response", "error", err)
+	}
+}
+
+// shedResponse is the body of a request rejected by load shedding.
+type shedResponse struct {
+	api.ErrorResponse
+	Reason   string `json:"reason"`
+	Priority string `json:"priority"`
+}
+
 // DrainCheck returns middleware that rejects requests when draining.
 func DrainCheck(lc *Lifecycle) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if lc.ShouldRejectRequest() {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusServiceUnavailable)
-				if _, err := w.Write([]byte(`{"error":"server is shutting down","code":"OPERATION_TIMEOUT"}`)); err != nil {
-					slog.Warn("failed to write drain response", "error", err)
-				}
+				writeError(w, http.StatusServiceUnavailable, errcode.OperationTimeout, "server is shutting down")
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -114,6 +176,198 @@ func Metrics(next http.Handler) http.Handler {
 	})
 }
 
+// VariantHeader carries the deployment variant on every response.
+const VariantHeader = "X-Hotpod-Variant"
+
+// Variant returns middleware that stamps each response with variant, and
+// counts responses by variant and status. Probes, metrics, and admin
+// endpoints hit every instance alike, so they are not counted. An empty
+// variant returns next unchanged.
+func Variant(variant string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if variant == "" {
+			return next
+		}
+		metrics.VariantInfo.WithLabelValues(variant).Set(1)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(VariantHeader, variant)
+			if isControlPlane(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rw, r)
+			metrics.VariantRequestsTotal.WithLabelValues(variant, strconv.Itoa(rw.statusCode)).Inc()
+		})
+	}
+}
+
+// SLO returns middleware that counts each response's status and duration
+// toward t's SLIs. Probes, metrics, and admin endpoints are not counted.
+func SLO(t *slo.Tracker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if t == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isControlPlane(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rw, r)
+			now := time.Now()
+			t.Record(now, rw.statusCode, now.Sub(start))
+		})
+	}
+}
+
+// EndpointInFlight returns middleware that tracks in-flight requests per
+// normalized endpoint. When limit is positive, each endpoint's saturation
+// (in-flight / limit) is also published, so autoscalers can target
+// per-endpoint concurrency.
+func EndpointInFlight(limit int) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	inFlight := make(map[string]int)
+
+	update := func(endpoint string, delta int) {
+		mu.Lock()
+		defer mu.Unlock()
+		inFlight[endpoint] += delta
+		n := inFlight[endpoint]
+		metrics.EndpointInFlight.WithLabelValues(endpoint).Set(float64(n))
+		if limit > 0 {
+			metrics.EndpointSaturation.WithLabelValues(endpoint).Set(float64(n) / float64(limit))
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			endpoint := normalizeEndpoint(r.URL.Path)
+			update(endpoint, 1)
+			defer update(endpoint, -1)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// timeoutBody is the response body when a request exceeds its timeout.
+// http.TimeoutHandler writes it verbatim, so it cannot carry a request ID.
+var timeoutBody = func() string {
+	b, _ := json.Marshal(errcode.Response(errcode.OperationTimeout, "request timeout exceeded", ""))
+	return string(b)
+}()
+
+// TimeoutHeader lets a client shorten its own request's timeout, as a
+// duration like 2.5s or a number of seconds.
+const TimeoutHeader = "X-Hotpod-Timeout"
+
+// RequestTimeout returns middleware that bounds each request by the timeout
+// of its endpoint group in overrides, falling back to def. A zero timeout
+// leaves requests unbounded. A TimeoutHeader on the request can shorten its
+// timeout but never extend it past the configured one.
+//
+// Timed-out requests get a 503, except on streaming endpoints, which would
+// otherwise be buffered whole and could not hijack the connection: those
+// only see their context cancelled.
+func RequestTimeout(def time.Duration, overrides map[string]time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout, ok := overrides[endpointGroup(r.URL.Path)]
+			if !ok {
+				timeout = def
+			}
+
+			if v := r.Header.Get(TimeoutHeader); v != "" {
+				requested, err := parseTimeoutHeader(v)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, errcode.InvalidParameter, TimeoutHeader+" "+err.Error())
+					return
+				}
+				if timeout <= 0 || requested < timeout {
+					timeout = requested
+				}
+			}
+
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if isStreaming(r.URL.Path) {
+				ctx, cancel := context.WithTimeout(r.Context(), timeout)
+				defer cancel()
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			http.TimeoutHandler(next, timeout, timeoutBody).ServeHTTP(w, r)
+		})
+	}
+}
+
+var errInvalidTimeoutHeader = errors.New("must be a positive duration like 2.5s or a number of seconds")
+
+// parseTimeoutHeader parses a TimeoutHeader value as a Go duration or, if it
+// has no unit, as seconds.
+func parseTimeoutHeader(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		// The range check also rejects NaN and values that overflow.
+		secs, err := strconv.ParseFloat(v, 64)
+		if err != nil || !(secs > 0 && secs < 1e9) {
+			return 0, errInvalidTimeoutHeader
+		}
+		d = time.Duration(secs * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, errInvalidTimeoutHeader
+	}
+	return d, nil
+}
+
+// isStreaming reports whether path streams its response, which must reach
+// the client as it is written rather than be held in memory, or hijacks the
+// connection to write it.
+func isStreaming(path string) bool {
+	switch path {
+	case "/stream-latency", "/fault/bomb", "/framing":
+		return true
+	}
+	return false
+}
+
+// isManagementPath reports whether path is a health probe, metrics, or admin
+// route, served on the management port when one is configured. Peers fetch
+// /custom-metrics on the fleet port, which defaults to the management port.
+func isManagementPath(path string) bool {
+	switch path {
+	case "/healthz", "/readyz", "/startupz", "/metrics", "/custom-metrics":
+		return true
+	}
+	return endpointGroup(path) == "admin"
+}
+
+// SplitManagement returns a handler serving only management routes when mgmt
+// is true, or only the other routes when false. Requests for routes served
+// on the other listener get a 404.
+func SplitManagement(next http.Handler, mgmt bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isManagementPath(r.URL.Path) != mgmt {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// endpointGroup returns the first segment of path, e.g. "queue" for
+// /queue/enqueue.
+func endpointGroup(path string) string {
+	group, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return group
+}
+
 // normalizeEndpoint maps request paths to known routes to prevent unbounded
 // cardinality in Prometheus metrics. Unknown paths are grouped as "unknown".
 func normalizeEndpoint(path string) string {
@@ -124,20 +378,56 @@ func normalizeEndpoint(path string) string {
 		return "/readyz"
 	case path == "/startupz":
 		return "/startupz"
+	case path == "/prestop":
+		return "/prestop"
 	case path == "/metrics":
 		return "/metrics"
 	case path == "/info":
 		return "/info"
+	case path == "/echo":
+		return "/echo"
+	case path == "/session":
+		return "/session"
+	case path == "/protected":
+		return "/protected"
+	case path == "/cacheable":
+		return "/cacheable"
+	case path == "/framing":
+		return "/framing"
+	case path == "/burst":
+		return "/burst"
+	case path == "/burst/stats":
+		return "/burst/stats"
+	case path == "/mirror/source":
+		return "/mirror/source"
+	case path == "/mirror/sink":
+		return "/mirror/sink"
+	case path == "/events":
+		return "/events"
+	case path == "/leader":
+		return "/leader"
+	case path == "/custom-metrics":
+		return "/custom-metrics"
+	case path == "/ui" || path == "/ui/":
+		return "/ui"
 	case path == "/cpu":
 		return "/cpu"
 	case path == "/memory":
 		return "/memory"
+	case path == "/memory/pressure":
+		return "/memory/pressure"
 	case path == "/io":
 		return "/io"
+	case path == "/upload":
+		return "/upload"
 	case path == "/work":
 		return "/work"
 	case path == "/latency":
 		return "/latency"
+	case path == "/stream-latency":
+		return "/stream-latency"
+	case path == "/dns":
+		return "/dns"
 	case path == "/queue/enqueue":
 		return "/queue/enqueue"
 	case path == "/queue/process":
@@ -146,6 +436,12 @@ func normalizeEndpoint(path string) string {
 		return "/queue/status"
 	case path == "/queue/clear":
 		return "/queue/clear"
+	case path == "/queue/workers":
+		return "/queue/workers"
+	case path == "/queue/items":
+		return "/queue/items"
+	case strings.HasPrefix(path, "/queue/items/"):
+		return "/queue/items/{id}"
 	case strings.HasPrefix(path, "/fault/"):
 		return "/fault/*"
 	case strings.HasPrefix(path, "/admin/"):
@@ -155,24 +451,53 @@ func normalizeEndpoint(path string) string {
 	}
 }
 
-// ErrorInjection returns middleware that injects errors based on fault configuration.
+// ErrorInjection returns middleware that injects errors based on fault
+// configuration. Admin endpoints are never faulted, so a global rate of 1
+// cannot lock operators out of turning it off again; probes and metrics only
+// fail when a rule names them explicitly.
 func ErrorInjection(injector *fault.Injector) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if injector == nil {
+			if injector == nil || strings.HasPrefix(r.URL.Path, "/admin/") {
 				next.ServeHTTP(w, r)
 				return
 			}
 
 			endpoint := normalizeEndpoint(r.URL.Path)
-			statusCode := injector.ShouldInjectError(endpoint)
-			if statusCode != 0 {
+			var cfg *fault.ErrorConfig
+			if isControlPlane(r.URL.Path) {
+				cfg = injector.GetEndpointConfig(endpoint)
+			} else {
+				cfg = injector.GetConfig(endpoint)
+			}
+			if cfg != nil && cfg.ShouldInject() {
+				statusCode := cfg.SelectCode()
+				if delay := cfg.SampleDelay(); delay > 0 {
+					t := time.NewTimer(delay)
+					select {
+					case <-t.C:
+					case <-r.Context().Done():
+						t.Stop()
+						return
+					}
+				}
 				metrics.FaultErrorsInjectedTotal.WithLabelValues(endpoint, strconv.Itoa(statusCode)).Inc()
+				events.Record(slog.LevelDebug, events.TypeFault, "error injected", map[string]any{
+					"endpoint":   endpoint,
+					"path":       r.URL.Path,
+					"status":     statusCode,
+					"request_id": requestid.FromContext(r.Context()),
+				})
 
-				w.Header().Set("Content-Type", "application/json")
+				contentType, body, declared := cfg.Body.Render(statusCode, r.URL.Path)
+				if contentType != "" {
+					w.Header().Set("Content-Type", contentType)
+				}
+				if declared != len(body) {
+					w.Header().Set("Content-Length", strconv.Itoa(declared))
+				}
 				w.WriteHeader(statusCode)
-				body := fmt.Sprintf(`{"error":"injected fault","code":"FAULT_INJECTED","status":%d}`, statusCode)
-				if _, err := w.Write([]byte(body)); err != nil {
+				if _, err := w.Write(body); err != nil {
 					slog.Warn("failed to write fault injection response", "error", err)
 				}
 				return
@@ -183,6 +508,385 @@ func ErrorInjection(injector *fault.Injector) func(http.Handler) http.Handler {
 	}
 }
 
+// HeaderInjection returns middleware that adds, removes, or corrupts response
+// headers based on fault configuration. Like ErrorInjection, admin endpoints
+// are exempt and probes and metrics are only affected when named explicitly.
+func HeaderInjection(injector *fault.Injector) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if injector == nil || strings.HasPrefix(r.URL.Path, "/admin/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			endpoint := normalizeEndpoint(r.URL.Path)
+			var cfg *fault.HeaderConfig
+			if isControlPlane(r.URL.Path) {
+				cfg = injector.GetEndpointHeaderConfig(endpoint)
+			} else {
+				cfg = injector.GetHeaderConfig(endpoint)
+			}
+			if cfg == nil || !cfg.ShouldInject() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			metrics.FaultHeaderFaultsInjectedTotal.WithLabelValues(endpoint).Inc()
+			if !cfg.NeedsBodyLength() {
+				next.ServeHTTP(&headerFaultWriter{ResponseWriter: w, cfg: cfg}, r)
+				return
+			}
+
+			// Content-Length corruptions need the final body length, so the
+			// response is buffered and written in one go.
+			rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			maps.Copy(w.Header(), rec.header)
+			cfg.Apply(w.Header(), rec.body.Len())
+			body := rec.body.Bytes()
+			// net/http refuses a write that overruns Content-Length outright,
+			// so send exactly the declared prefix of a short body.
+			if n, err := strconv.Atoi(w.Header().Get("Content-Length")); err == nil && n < len(body) {
+				body = body[:n]
+			}
+			w.WriteHeader(rec.status)
+			if _, err := w.Write(body); err != nil {
+				slog.Debug("header fault response write ended early", "error", err)
+			}
+		})
+	}
+}
+
+// headerFaultWriter applies header faults just before the header is written.
+type headerFaultWriter struct {
+	http.ResponseWriter
+	cfg         *fault.HeaderConfig
+	wroteHeader bool
+}
+
+func (hw *headerFaultWriter) WriteHeader(code int) {
+	if !hw.wroteHeader {
+		hw.wroteHeader = true
+		hw.cfg.Apply(hw.ResponseWriter.Header(), -1)
+	}
+	hw.ResponseWriter.WriteHeader(code)
+}
+
+func (hw *headerFaultWriter) Write(b []byte) (int, error) {
+	if !hw.wroteHeader {
+		hw.WriteHeader(http.StatusOK)
+	}
+	return hw.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer.
+func (hw *headerFaultWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
+
+// bufferedResponse captures a complete response.
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (br *bufferedResponse) Header() http.Header {
+	return br.header
+}
+
+func (br *bufferedResponse) WriteHeader(code int) {
+	if br.wroteHeader {
+		return
+	}
+	br.wroteHeader = true
+	br.status = code
+}
+
+func (br *bufferedResponse) Write(b []byte) (int, error) {
+	br.wroteHeader = true
+	return br.body.Write(b)
+}
+
+// SkewedDate returns middleware that stamps the Date response header with the
+// skewed wall clock, so HTTP-level clock-skew checks see the simulated time.
+func SkewedDate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wallclock.Skew() != 0 {
+			w.Header().Set("Date", wallclock.Now().UTC().Format(http.TimeFormat))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LoadShedding returns middleware that rejects requests with 503 when the
+// shedder decides their priority cannot be served at the current load.
+// Probes, metrics, and admin endpoints are always treated as critical.
+func LoadShedding(s *shed.Shedder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			priority := shed.ParsePriority(r.Header.Get(shed.Header))
+			if isControlPlane(r.URL.Path) {
+				priority = shed.PriorityCritical
+			}
+
+			if reason, ok := s.Decide(priority); ok {
+				metrics.RequestsShedTotal.WithLabelValues(priority.String(), reason).Inc()
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", "1")
+				w.Header().Set("X-Hotpod-Shed-Reason", reason)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				body := shedResponse{
+					ErrorResponse: errcode.Response(errcode.LoadShed, "request shed under load", w.Header().Get(requestid.Header)),
+					Reason:        reason,
+					Priority:      priority.String(),
+				}
+				if err := json.NewEncoder(w).Encode(body); err != nil {
+					slog.Warn("failed to write load shedding response", "error", err)
+				}
+				return
+			}
+
+			done := s.Begin()
+			defer done()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ChaosSchedule returns middleware that applies the fault the chaos schedule
+// has active: hanging requests until it ends, delaying them, or failing them.
+// Like ErrorInjection, probes, metrics, and admin endpoints are exempt.
+func ChaosSchedule(s *fault.ChaosSchedule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f, until, ok := s.Active(time.Now())
+			if !ok || isControlPlane(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			switch f.Kind {
+			case fault.ChaosHang:
+				if !sleepContext(r.Context(), time.Until(until)) {
+					return
+				}
+			case fault.ChaosLatencySpike:
+				if !sleepContext(r.Context(), f.Delay) {
+					return
+				}
+			case fault.ChaosErrorBurst:
+				if f.ShouldFail() {
+					status := f.SelectCode()
+					metrics.FaultErrorsInjectedTotal.WithLabelValues(normalizeEndpoint(r.URL.Path), strconv.Itoa(status)).Inc()
+					writeError(w, status, errcode.FaultInjected, "error injected by chaos schedule")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Storm returns middleware that fails requests with 503 and a Retry-After
+// header while s has a storm in effect. Like ErrorInjection, probes,
+// metrics, and admin endpoints are exempt, as are /fault/ endpoints so the
+// storm can still be stopped.
+func Storm(s *fault.Storm) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isControlPlane(r.URL.Path) || strings.HasPrefix(r.URL.Path, "/fault/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			retryAfter, reject := s.Reject(time.Now())
+			if !reject {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			metrics.FaultErrorsInjectedTotal.WithLabelValues(normalizeEndpoint(r.URL.Path), strconv.Itoa(http.StatusServiceUnavailable)).Inc()
+			writeError(w, http.StatusServiceUnavailable, errcode.FaultInjected, "error injected by 503 storm")
+		})
+	}
+}
+
+// Brownout returns middleware that delays and fails requests by however
+// far b's brownout has ramped. Like ErrorInjection, probes, metrics, and
+// admin endpoints are exempt.
+func Brownout(b *fault.Brownout) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isControlPlane(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			delay, status := b.Degrade(time.Now())
+			if delay > 0 && !sleepContext(r.Context(), delay) {
+				return
+			}
+			if status != 0 {
+				metrics.FaultErrorsInjectedTotal.WithLabelValues(normalizeEndpoint(r.URL.Path), strconv.Itoa(status)).Inc()
+				writeError(w, status, errcode.FaultInjected, "error injected by brownout")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NodePressure returns middleware that rejects resource-consuming load
+// requests while np reports node pressure, so hotpod stops adding load to a
+// node the kubelet may start evicting pods from.
+func NodePressure(np *nodepressure.Watcher) func(http.Handler) http.Handler {
+	retryAfter := strconv.Itoa(int(math.Ceil(np.Interval().Seconds())))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isLoad(r.URL.Path) || !np.BackingOff() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Retry-After", retryAfter)
+			writeError(w, http.StatusServiceUnavailable, errcode.NodePressure, "backing off under node pressure")
+		})
+	}
+}
+
+// ReadOnly returns middleware that rejects fault endpoints and requests that
+// change server state with 403, leaving health, info, metrics, load, and
+// admin reads available.
+func ReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutating(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		writeError(w, http.StatusForbidden, errcode.ReadOnly, "server is read-only")
+	})
+}
+
+// isMutating reports whether r injects a fault or changes server state.
+// Load endpoints that accept any method, like /echo and /upload, change
+// nothing that outlives the request.
+func isMutating(r *http.Request) bool {
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+	switch endpointGroup(r.URL.Path) {
+	case "fault":
+		return true
+	case "prestop":
+		// The preStop hook marks the server not ready, whatever its method.
+		return true
+	case "admin", "queue", "events":
+		return !read
+	}
+	return r.URL.Path == "/burst/stats" && !read
+}
+
+// isLoad reports whether path is an endpoint that consumes CPU, memory, or
+// I/O on the node.
+func isLoad(path string) bool {
+	switch path {
+	case "/cpu", "/memory", "/io", "/work":
+		return true
+	}
+	return strings.HasPrefix(path, "/memory/")
+}
+
+// sleepContext waits for d, returning false if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isControlPlane reports whether path is a health probe, lifecycle hook, metrics, or admin
+// endpoint that should not be subject to data-plane protections.
+func isControlPlane(path string) bool {
+	switch path {
+	case "/healthz", "/readyz", "/startupz", "/prestop", "/metrics":
+		return true
+	}
+	return strings.HasPrefix(path, "/admin/")
+}
+
+// maxAuditBody is the maximum number of request body bytes kept per audit entry.
+const maxAuditBody = 4 << 10
+
+// AdminAudit returns middleware that records every mutating /admin/* request
+// in the audit log and emits it via slog.
+func AdminAudit(log *audit.Log, authn *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, "/admin/") || r.Method == http.MethodGet || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var body []byte
+			if r.Body != nil {
+				body, _ = io.ReadAll(io.LimitReader(r.Body, maxAuditBody+1))
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+			}
+			if len(body) > maxAuditBody {
+				body = append(body[:maxAuditBody], "...(truncated)"...)
+			}
+
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rw, r)
+
+			var params map[string]string
+			if q := r.URL.Query(); len(q) > 0 {
+				params = make(map[string]string, len(q))
+				for k, v := range q {
+					params[k] = strings.Join(v, ",")
+				}
+			}
+
+			principal := ""
+			if p, err := authn.Authenticate(r); err == nil {
+				principal = p.Name
+			}
+
+			entry := log.Record(audit.Entry{
+				Time:             start,
+				Method:           r.Method,
+				Path:             r.URL.Path,
+				Params:           params,
+				Body:             string(body),
+				Remote:           r.RemoteAddr,
+				Principal:        principal,
+				TokenFingerprint: audit.Fingerprint(auth.Credential(r)),
+				Status:           rw.statusCode,
+				Duration:         time.Since(start).String(),
+				RequestID:        requestid.FromContext(r.Context()),
+			})
+
+			slog.Info("admin audit",
+				"audit_id", entry.ID,
+				"method", entry.Method,
+				"path", entry.Path,
+				"params", entry.Params,
+				"remote", entry.Remote,
+				"principal", entry.Principal,
+				"token_fingerprint", entry.TokenFingerprint,
+				"status", entry.Status,
+				"request_id", entry.RequestID,
+			)
+		})
+	}
+}
+
 // Chain applies middlewares in order (first middleware wraps outermost).
 func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	for i := len(middlewares) - 1; i >= 0; i-- {
@@ -190,3 +894,25 @@ func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.
 	}
 	return h
 }
+
+// PersistState returns middleware that calls save after each successful
+// mutating /admin/* or /fault/* request, so runtime settings survive a
+// restart.
+func PersistState(save func()) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rw, r)
+
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				return
+			}
+			if rw.statusCode < 200 || rw.statusCode > 299 {
+				return
+			}
+			if strings.HasPrefix(r.URL.Path, "/admin/") || strings.HasPrefix(r.URL.Path, "/fault/") {
+				save()
+			}
+		})
+	}
+}
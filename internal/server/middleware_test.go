@@ -0,0 +1,910 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ripta/hotpod/internal/audit"
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/nodepressure"
+	"github.com/ripta/hotpod/internal/requestid"
+	"github.com/ripta/hotpod/internal/shed"
+	"github.com/ripta/hotpod/internal/slo"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestAdminAuditRecordsMutations(t *testing.T) {
+	log := audit.New(10)
+	var gotBody string
+	h := AdminAudit(log, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	req := httptest.NewRequest("POST", "/admin/error-rate?rate=0.5&endpoint=/cpu", strings.NewReader(`{"x":1}`))
+	req.Header.Set("X-Admin-Token", "secret")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotBody != `{"x":1}` {
+		t.Errorf("handler body = %q, want body preserved", gotBody)
+	}
+
+	entries := log.List(0, 0)
+	if len(entries) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Path != "/admin/error-rate" || e.Status != http.StatusAccepted {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Params["rate"] != "0.5" || e.Params["endpoint"] != "/cpu" {
+		t.Errorf("params = %v", e.Params)
+	}
+	if e.TokenFingerprint != audit.Fingerprint("secret") {
+		t.Errorf("fingerprint = %q", e.TokenFingerprint)
+	}
+	if e.Body != `{"x":1}` {
+		t.Errorf("body = %q", e.Body)
+	}
+}
+
+type auditSkipTest struct {
+	method string
+	path   string
+}
+
+var auditSkipTests = []auditSkipTest{
+	{"GET", "/admin/config"},
+	{"POST", "/cpu"},
+	{"HEAD", "/admin/audit"},
+}
+
+func TestAdminAuditRecordsReadOnlyRejections(t *testing.T) {
+	log := audit.New(10)
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), AdminAudit(log, nil), ReadOnly)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/admin/error-rate?rate=1", nil))
+	entries := log.List(0, 0)
+	if len(entries) != 1 || entries[0].Status != http.StatusForbidden {
+		t.Errorf("entries = %+v, want the rejected request recorded with 403", entries)
+	}
+}
+
+func TestAdminAuditSkipsReadsAndNonAdmin(t *testing.T) {
+	log := audit.New(10)
+	h := AdminAudit(log, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, tt := range auditSkipTests {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+	}
+
+	if got := log.List(0, 0); len(got) != 0 {
+		t.Errorf("recorded %d entries, want 0", len(got))
+	}
+}
+
+func TestLoadShedding(t *testing.T) {
+	s := shed.New(1, 0)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	h := LoadShedding(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latency" {
+			close(started)
+			<-block
+		}
+	}))
+
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/latency", nil))
+	<-started
+	defer close(block)
+
+	tests := []struct {
+		path     string
+		priority string
+		want     int
+	}{
+		{"/cpu", "", http.StatusServiceUnavailable},
+		{"/cpu", "low", http.StatusServiceUnavailable},
+		{"/cpu", "critical", http.StatusOK},
+		{"/readyz", "low", http.StatusOK},
+		{"/admin/config", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.priority != "" {
+			req.Header.Set(shed.Header, tt.priority)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s priority=%q: status = %d, want %d", tt.path, tt.priority, rec.Code, tt.want)
+		}
+		if rec.Code == http.StatusServiceUnavailable && rec.Header().Get("X-Hotpod-Shed-Reason") != shed.ReasonInFlight {
+			t.Errorf("%s: shed reason = %q", tt.path, rec.Header().Get("X-Hotpod-Shed-Reason"))
+		}
+	}
+}
+
+func TestNodePressure(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":{"conditions":[{"type":"MemoryPressure","status":"True"}]}}`))
+	}))
+	defer apiServer.Close()
+
+	np := nodepressure.New(kube.NewForTesting(apiServer.URL, "ns"), "node-1", 5*time.Second)
+	if err := np.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	h := NodePressure(np)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/cpu", http.StatusServiceUnavailable},
+		{"/memory/pressure", http.StatusServiceUnavailable},
+		{"/latency", http.StatusOK},
+		{"/readyz", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, tt.want)
+		}
+		if rec.Code == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") != "5" {
+			t.Errorf("%s: Retry-After = %q, want 5", tt.path, rec.Header().Get("Retry-After"))
+		}
+	}
+
+	np.SetEnabled(false)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/cpu", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/cpu with back-off disabled: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestPersistState(t *testing.T) {
+	saves := 0
+	h := PersistState(func() { saves++ })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("bad") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{"POST", "/admin/error-rate"},
+		{"DELETE", "/fault/zombie"},
+		{"POST", "/admin/error-rate?bad"},
+		{"GET", "/admin/config"},
+		{"POST", "/cpu"},
+	}
+	for _, tt := range tests {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+	}
+
+	if saves != 2 {
+		t.Errorf("saves = %d, want 2", saves)
+	}
+}
+
+func TestErrorInjection(t *testing.T) {
+	injector := fault.NewInjector()
+	injector.SetGlobalConfig(&fault.ErrorConfig{Rate: 1, Codes: []int{503}})
+	injector.SetEndpointConfig("/readyz", &fault.ErrorConfig{Rate: 1, Codes: []int{500}})
+	injector.SetEndpointConfig("/admin/*", &fault.ErrorConfig{Rate: 1, Codes: []int{500}})
+	injector.SetEndpointConfig("/io", &fault.ErrorConfig{Rate: 1, Codes: []int{429}, Delay: 20 * time.Millisecond})
+
+	h := ErrorInjection(injector)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		path     string
+		wantCode int
+	}{
+		{"/cpu", http.StatusServiceUnavailable},
+		{"/work", http.StatusServiceUnavailable},
+		{"/io", http.StatusTooManyRequests},
+		{"/healthz", http.StatusOK},
+		{"/readyz", http.StatusInternalServerError},
+		{"/admin/error-rate", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			start := time.Now()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.path == "/io" && time.Since(start) < 20*time.Millisecond {
+				t.Error("expected injected error to be delayed")
+			}
+		})
+	}
+
+	if got := testutil.ToFloat64(metrics.FaultErrorsInjectedTotal.WithLabelValues("/cpu", "503")); got < 1 {
+		t.Errorf("injected errors for /cpu = %v, want at least 1", got)
+	}
+}
+
+// activeChaosSchedule runs a chaos schedule that always triggers f, and
+// waits for it to.
+func activeChaosSchedule(t *testing.T, f fault.ChaosFault) *fault.ChaosSchedule {
+	t.Helper()
+	s := fault.NewChaosSchedule()
+	if err := s.Set(fault.ChaosScheduleConfig{Interval: time.Second, Faults: []fault.ChaosFault{f}}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go s.Run(ctx)
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, _, ok := s.Active(time.Now()); ok {
+			return s
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("chaos schedule did not trigger a fault")
+		}
+	}
+}
+
+func TestChaosSchedule(t *testing.T) {
+	s := activeChaosSchedule(t, fault.ChaosFault{Kind: fault.ChaosErrorBurst, Probability: 1, Duration: time.Minute, Codes: []int{502}})
+	h := ChaosSchedule(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range []struct {
+		path     string
+		wantCode int
+	}{
+		{"/work", http.StatusBadGateway},
+		{"/healthz", http.StatusOK},
+		{"/admin/chaos/schedule", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, tt.wantCode)
+		}
+	}
+}
+
+func TestChaosScheduleHang(t *testing.T) {
+	s := activeChaosSchedule(t, fault.ChaosFault{Kind: fault.ChaosHang, Probability: 1, Duration: time.Minute})
+	called := false
+	h := ChaosSchedule(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/work", nil).WithContext(ctx))
+	if called {
+		t.Error("hung request reached the handler")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 10*time.Second {
+		t.Errorf("hung request returned after %s, want when its context ended", elapsed)
+	}
+}
+
+func TestStorm(t *testing.T) {
+	s := fault.NewStorm()
+	if err := s.Start(fault.StormConfig{RetryAfter: []time.Duration{3 * time.Second}, Duration: time.Minute}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	h := Storm(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range []struct {
+		path           string
+		wantCode       int
+		wantRetryAfter string
+	}{
+		{"/work", http.StatusServiceUnavailable, "3"},
+		{"/healthz", http.StatusOK, ""},
+		{"/admin/state", http.StatusOK, ""},
+		{"/fault/storm", http.StatusOK, ""},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+		if rec.Code != tt.wantCode || rec.Header().Get("Retry-After") != tt.wantRetryAfter {
+			t.Errorf("%s: status = %d, Retry-After = %q, want %d, %q", tt.path, rec.Code, rec.Header().Get("Retry-After"), tt.wantCode, tt.wantRetryAfter)
+		}
+	}
+}
+
+func TestBrownout(t *testing.T) {
+	b := fault.NewBrownout()
+	// Started a ramp ago, the brownout is at its peak.
+	if err := b.Start(fault.BrownoutConfig{MaxErrorRate: 1, Codes: []int{503}, Ramp: time.Millisecond}, time.Now().Add(-time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	h := Brownout(b)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range []struct {
+		path     string
+		wantCode int
+	}{
+		{"/work", http.StatusServiceUnavailable},
+		{"/readyz", http.StatusOK},
+		{"/admin/brownout", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, tt.wantCode)
+		}
+	}
+}
+
+func TestSLO(t *testing.T) {
+	tracker := slo.New(slo.Config{Availability: 0.99, Period: time.Hour})
+	h := SLO(tracker)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/work" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	for _, path := range []string{"/work", "/echo", "/healthz", "/admin/slo"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	s := tracker.Status(time.Now()).SLIs[0]
+	if s.Total != 2 || s.Bad != 1 {
+		t.Errorf("SLI = %+v, want 1 bad of 2 data-plane requests", s)
+	}
+}
+
+func TestVariant(t *testing.T) {
+	counter := func(code string) float64 {
+		return testutil.ToFloat64(metrics.VariantRequestsTotal.WithLabelValues("canary", code))
+	}
+	ok, unavailable := counter("200"), counter("503")
+
+	h := Variant("canary")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/work" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	for _, path := range []string{"/work", "/echo", "/healthz", "/admin/slo"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if got := rec.Header().Get(VariantHeader); got != "canary" {
+			t.Errorf("%s: %s = %q, want canary", path, VariantHeader, got)
+		}
+	}
+
+	if got := counter("200") - ok; got != 1 {
+		t.Errorf("200 responses counted = %v, want 1", got)
+	}
+	if got := counter("503") - unavailable; got != 1 {
+		t.Errorf("503 responses counted = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.VariantInfo.WithLabelValues("canary")); got != 1 {
+		t.Errorf("variant info = %v, want 1", got)
+	}
+
+	rec := httptest.NewRecorder()
+	Variant("")(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest("GET", "/work", nil))
+	if got := rec.Header().Get(VariantHeader); got != "" {
+		t.Errorf("%s = %q without a variant, want none", VariantHeader, got)
+	}
+}
+
+func TestErrorInjectionTruncatedBody(t *testing.T) {
+	body, err := fault.NewErrorBody("truncated", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	injector := fault.NewInjector()
+	injector.SetEndpointConfig("/cpu", &fault.ErrorConfig{Rate: 1, Codes: []int{502}, Body: body})
+
+	ts := httptest.NewServer(ErrorInjection(injector)(http.NotFoundHandler()))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadGateway)
+	}
+	if _, err := io.ReadAll(resp.Body); err != io.ErrUnexpectedEOF {
+		t.Errorf("reading body error = %v, want unexpected EOF", err)
+	}
+}
+
+func TestHeaderInjection(t *testing.T) {
+	const body = `{"status":"ok","padding":"0123456789"}`
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		io.WriteString(w, body)
+	})
+
+	tests := []struct {
+		name  string
+		path  string
+		cfg   *fault.HeaderConfig
+		check func(t *testing.T, resp *http.Response, got []byte, readErr error)
+	}{
+		{
+			name: "set and remove",
+			path: "/cpu",
+			cfg:  &fault.HeaderConfig{Rate: 1, Set: map[string]string{"X-Chaos": "yes"}, Remove: []string{"Access-Control-Allow-Origin"}},
+			check: func(t *testing.T, resp *http.Response, got []byte, readErr error) {
+				if resp.Header.Get("X-Chaos") != "yes" || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+					t.Errorf("headers = %v", resp.Header)
+				}
+			},
+		},
+		{
+			name: "content-length-long",
+			path: "/cpu",
+			cfg:  &fault.HeaderConfig{Rate: 1, Corrupt: []fault.HeaderCorruption{fault.CorruptContentLengthLong}},
+			check: func(t *testing.T, resp *http.Response, got []byte, readErr error) {
+				if readErr != io.ErrUnexpectedEOF {
+					t.Errorf("read error = %v, want unexpected EOF", readErr)
+				}
+			},
+		},
+		{
+			name: "content-length-short",
+			path: "/cpu",
+			cfg:  &fault.HeaderConfig{Rate: 1, Corrupt: []fault.HeaderCorruption{fault.CorruptContentLengthShort}},
+			check: func(t *testing.T, resp *http.Response, got []byte, readErr error) {
+				if readErr != nil || len(got) != len(body)/2 {
+					t.Errorf("read %d bytes (err %v), want %d", len(got), readErr, len(body)/2)
+				}
+			},
+		},
+		{
+			name: "huge cookie",
+			path: "/cpu",
+			cfg:  &fault.HeaderConfig{Rate: 1, Corrupt: []fault.HeaderCorruption{fault.CorruptHugeCookie, fault.CorruptContentType}},
+			check: func(t *testing.T, resp *http.Response, got []byte, readErr error) {
+				if len(resp.Header.Get("Set-Cookie")) < 16<<10 {
+					t.Errorf("Set-Cookie length = %d, want at least 16KiB", len(resp.Header.Get("Set-Cookie")))
+				}
+				if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+					t.Errorf("Content-Type = %q, want text/html", resp.Header.Get("Content-Type"))
+				}
+			},
+		},
+		{
+			name: "connection close",
+			path: "/cpu",
+			cfg:  &fault.HeaderConfig{Rate: 1, Corrupt: []fault.HeaderCorruption{fault.CorruptConnectionClose}},
+			check: func(t *testing.T, resp *http.Response, got []byte, readErr error) {
+				if !resp.Close {
+					t.Errorf("response Close = false, want the server to close the connection")
+				}
+				if readErr != nil || string(got) != body {
+					t.Errorf("body = %q (err %v), want it intact", got, readErr)
+				}
+			},
+		},
+		{
+			name: "admin exempt",
+			path: "/admin/config",
+			cfg:  &fault.HeaderConfig{Rate: 1, Remove: []string{"Content-Type"}},
+			check: func(t *testing.T, resp *http.Response, got []byte, readErr error) {
+				if resp.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("Content-Type = %q, want admin response untouched", resp.Header.Get("Content-Type"))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := fault.NewInjector()
+			injector.SetHeaderConfig("", tt.cfg)
+
+			ts := httptest.NewServer(HeaderInjection(injector)(inner))
+			defer ts.Close()
+
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			got, readErr := io.ReadAll(resp.Body)
+			tt.check(t, resp, got, readErr)
+		})
+	}
+}
+
+func TestSkewedDate(t *testing.T) {
+	defer wallclock.SetSkew(0)
+	h := SkewedDate(http.NotFoundHandler())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/info", nil))
+	if rec.Header().Get("Date") != "" {
+		t.Errorf("Date = %q, want net/http default without skew", rec.Header().Get("Date"))
+	}
+
+	wallclock.SetSkew(-time.Hour)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/info", nil))
+	date, err := http.ParseTime(rec.Header().Get("Date"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if behind := time.Since(date); behind < 59*time.Minute || behind > 61*time.Minute {
+		t.Errorf("Date is %v behind, want about 1h", behind)
+	}
+}
+
+func TestEndpointInFlight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+	h := EndpointInFlight(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/latency", nil))
+		}()
+	}
+	for range 3 {
+		<-started
+	}
+
+	if got := testutil.ToFloat64(metrics.EndpointInFlight.WithLabelValues("/latency")); got != 3 {
+		t.Errorf("in flight = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(metrics.EndpointSaturation.WithLabelValues("/latency")); got != 0.75 {
+		t.Errorf("saturation = %v, want 0.75", got)
+	}
+
+	close(release)
+	wg.Wait()
+	if got := testutil.ToFloat64(metrics.EndpointInFlight.WithLabelValues("/latency")); got != 0 {
+		t.Errorf("in flight after completion = %v, want 0", got)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "propagated", incoming: "abc-123", wantSame: true},
+		{name: "generated when missing"},
+		{name: "replaced when invalid", incoming: "has space"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromCtx string
+			h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromCtx = requestid.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/cpu", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestid.Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(requestid.Header)
+			if !requestid.Valid(got) {
+				t.Fatalf("response request ID = %q, want a valid ID", got)
+			}
+			if fromCtx != got {
+				t.Errorf("context request ID = %q, want %q", fromCtx, got)
+			}
+			if same := got == tt.incoming; same != tt.wantSame {
+				t.Errorf("response request ID = %q, incoming %q", got, tt.incoming)
+			}
+		})
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	// The handler runs until the request times out, or for 200ms.
+	h := RequestTimeout(20*time.Millisecond, map[string]time.Duration{"io": 0, "queue": 50 * time.Millisecond})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/cpu", http.StatusServiceUnavailable},
+		{"/queue/enqueue", http.StatusServiceUnavailable},
+		{"/io", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusServiceUnavailable && !strings.Contains(rec.Body.String(), "OPERATION_TIMEOUT") {
+			t.Errorf("%s: body = %q, want a timeout error", tt.path, rec.Body)
+		}
+	}
+}
+
+func TestRequestTimeoutHeader(t *testing.T) {
+	h := RequestTimeout(50*time.Millisecond, map[string]time.Duration{"io": 0})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(100 * time.Millisecond):
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+
+	tests := []struct {
+		path   string
+		header string
+		want   int
+	}{
+		// Shortens the configured timeout.
+		{"/cpu", "10ms", http.StatusServiceUnavailable},
+		{"/cpu", "0.01", http.StatusServiceUnavailable},
+		// Cannot extend it.
+		{"/cpu", "1m", http.StatusServiceUnavailable},
+		// Bounds a group with no timeout.
+		{"/io", "10ms", http.StatusServiceUnavailable},
+		{"/io", "1s", http.StatusOK},
+		{"/io", "soon", http.StatusBadRequest},
+		{"/io", "-1s", http.StatusBadRequest},
+		{"/io", "0", http.StatusBadRequest},
+		{"/io", "NaN", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set(TimeoutHeader, tt.header)
+		start := time.Now()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s with %s=%s: status = %d, want %d", tt.path, TimeoutHeader, tt.header, rec.Code, tt.want)
+		}
+		if tt.header == "10ms" && time.Since(start) >= 50*time.Millisecond {
+			t.Errorf("%s with %s=%s: took %s, want the shorter timeout", tt.path, TimeoutHeader, tt.header, time.Since(start))
+		}
+	}
+}
+
+func TestSplitManagement(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	traffic := SplitManagement(ok, false)
+	mgmt := SplitManagement(ok, true)
+
+	tests := []struct {
+		path     string
+		wantMgmt bool
+	}{
+		{"/healthz", true},
+		{"/readyz", true},
+		{"/startupz", true},
+		{"/metrics", true},
+		{"/custom-metrics", true},
+		{"/admin/config", true},
+		{"/admin/faults/", true},
+		{"/cpu", false},
+		{"/info", false},
+		{"/administrator", false},
+		{"/metrics/extra", false},
+	}
+	for _, tt := range tests {
+		for _, h := range []struct {
+			handler http.Handler
+			serves  bool
+		}{{traffic, !tt.wantMgmt}, {mgmt, tt.wantMgmt}} {
+			rec := httptest.NewRecorder()
+			h.handler.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			want := http.StatusNotFound
+			if h.serves {
+				want = http.StatusOK
+			}
+			if rec.Code != want {
+				t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, want)
+			}
+		}
+	}
+}
+
+func TestRequestTimeoutStreaming(t *testing.T) {
+	var flushErr, ctxErr error
+	h := Logging(RequestTimeout(20*time.Millisecond, nil)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			flushErr = http.NewResponseController(w).Flush()
+			<-r.Context().Done()
+			ctxErr = r.Context().Err()
+		})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/stream-latency", nil))
+
+	if flushErr != nil || !rec.Flushed {
+		t.Errorf("flush error = %v, flushed = %v; want the response flushed through the middleware", flushErr, rec.Flushed)
+	}
+	if ctxErr != context.DeadlineExceeded {
+		t.Errorf("context error = %v, want %v", ctxErr, context.DeadlineExceeded)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestEndpointGate(t *testing.T) {
+	gates := NewEndpointGates([]string{"io", "latency", "fault"}, []string{"io"})
+	h := EndpointGate(gates)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	check := func(path string, want int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+	check("/io", http.StatusForbidden)
+	check("/upload", http.StatusForbidden)
+	check("/latency", http.StatusNoContent)
+	check("/cpu", http.StatusNoContent)
+	check("/admin/endpoints", http.StatusNoContent)
+
+	if err := gates.Set(map[string]bool{"io": true, "disk": false}); err == nil {
+		t.Error("Set with an unknown group succeeded")
+	}
+	check("/io", http.StatusForbidden)
+
+	if err := gates.Set(map[string]bool{"io": true, "latency": false}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	check("/io", http.StatusNoContent)
+	check("/stream-latency", http.StatusForbidden)
+	if got := testutil.ToFloat64(metrics.EndpointGroupEnabled.WithLabelValues("latency")); got != 0 {
+		t.Errorf("latency enabled gauge = %v, want 0", got)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	h := ReadOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, tt := range []struct {
+		method, path string
+		want         int
+	}{
+		{"GET", "/healthz", http.StatusNoContent},
+		{"GET", "/info", http.StatusNoContent},
+		{"GET", "/metrics", http.StatusNoContent},
+		{"GET", "/cpu", http.StatusNoContent},
+		{"POST", "/upload", http.StatusNoContent},
+		{"POST", "/echo", http.StatusNoContent},
+		{"GET", "/admin/config", http.StatusNoContent},
+		{"GET", "/queue/status", http.StatusNoContent},
+		{"GET", "/burst/stats", http.StatusNoContent},
+		{"GET", "/fault/error", http.StatusForbidden},
+		{"POST", "/fault/crash", http.StatusForbidden},
+		{"POST", "/admin/faults", http.StatusForbidden},
+		{"DELETE", "/admin/faults", http.StatusForbidden},
+		{"POST", "/queue/enqueue", http.StatusForbidden},
+		{"POST", "/events", http.StatusForbidden},
+		{"DELETE", "/burst/stats", http.StatusForbidden},
+		{"GET", "/prestop", http.StatusForbidden},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusForbidden && !strings.Contains(rec.Body.String(), "READ_ONLY") {
+			t.Errorf("%s %s: body = %s, want READ_ONLY", tt.method, tt.path, rec.Body)
+		}
+	}
+}
+
+func TestVersioned(t *testing.T) {
+	var gotPath string
+	h := Versioned(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+
+	for _, tt := range []struct {
+		path, header string
+		wantStatus   int
+		wantPath     string
+	}{
+		{"/info", "", http.StatusOK, "/info"},
+		{"/v1/info", "", http.StatusOK, "/info"},
+		{"/v1/queue/items/42", "", http.StatusOK, "/queue/items/42"},
+		{"/v1", "", http.StatusOK, "/"},
+		{"/info", "v1", http.StatusOK, "/info"},
+		{"/v1/info", "1", http.StatusOK, "/info"},
+		{"/variant", "", http.StatusOK, "/variant"},
+		{"/v2/info", "", http.StatusBadRequest, ""},
+		{"/info", "v2", http.StatusBadRequest, ""},
+		{"/v1/info", "v2", http.StatusBadRequest, ""},
+	} {
+		gotPath = ""
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set(api.VersionHeader, tt.header)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus || gotPath != tt.wantPath {
+			t.Errorf("%s (%q): status = %d, path = %q, want %d, %q", tt.path, tt.header, rec.Code, gotPath, tt.wantStatus, tt.wantPath)
+			continue
+		}
+		if tt.wantStatus == http.StatusOK && rec.Header().Get(api.VersionHeader) != api.Version {
+			t.Errorf("%s: %s = %q, want %q", tt.path, api.VersionHeader, rec.Header().Get(api.VersionHeader), api.Version)
+		}
+		if tt.wantStatus != http.StatusOK && !strings.Contains(rec.Body.String(), "UNSUPPORTED_VERSION") {
+			t.Errorf("%s (%q): body = %s, want UNSUPPORTED_VERSION", tt.path, tt.header, rec.Body)
+		}
+	}
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	for path, want := range map[string]string{
+		"/healthz":           "/healthz",
+		"/session":           "/session",
+		"/custom-metrics":    "/custom-metrics",
+		"/queue/workers":     "/queue/workers",
+		"/queue/items":       "/queue/items",
+		"/queue/items/q-123": "/queue/items/{id}",
+		"/queue/items/q-456": "/queue/items/{id}",
+		"/fault/error":       "/fault/*",
+		"/nope":              "unknown",
+	} {
+		if got := normalizeEndpoint(path); got != want {
+			t.Errorf("normalizeEndpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
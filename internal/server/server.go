@@ -2,15 +2,21 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/ripta/hotpod/internal/config"
+	"github.com/ripta/hotpod/internal/events"
 	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/proxyproto"
+	"github.com/ripta/hotpod/internal/slo"
 )
 
 // Server is the main HTTP server for hotpod.
@@ -19,7 +25,17 @@ type Server struct {
 	lifecycle  *Lifecycle
 	injector   *fault.Injector
 	httpServer *http.Server
+	// mgmtServer serves management routes when a management port is set
+	mgmtServer *http.Server
 	mux        *http.ServeMux
+	// tlsConfig serves the main listener over HTTPS when set
+	tlsConfig *tls.Config
+	// slo counts responses toward SLIs when set
+	slo *slo.Tracker
+	// extra holds additional middleware applied innermost, around the mux
+	extra []func(http.Handler) http.Handler
+	// exit terminates the process; replaced in tests
+	exit func(code int)
 }
 
 // New creates a new Server with the given configuration.
@@ -39,6 +55,7 @@ func New(cfg *config.Config, injector *fault.Injector) *Server {
 		lifecycle: lc,
 		injector:  injector,
 		mux:       mux,
+		exit:      os.Exit,
 	}
 
 	return s
@@ -59,45 +76,115 @@ func (s *Server) Mux() *http.ServeMux {
 	return s.mux
 }
 
+// SetTLSConfig serves the main listener over HTTPS with cfg. The management
+// listener stays plain HTTP.
+func (s *Server) SetTLSConfig(cfg *tls.Config) {
+	s.tlsConfig = cfg
+}
+
+// SetSLO counts every data-plane response toward t's SLIs, including those
+// failed by the built-in middleware.
+func (s *Server) SetSLO(t *slo.Tracker) {
+	s.slo = t
+}
+
+// Use appends middleware that wraps the mux inside the built-in middleware
+// chain. Middleware is applied in the order given (first wraps outermost).
+func (s *Server) Use(middlewares ...func(http.Handler) http.Handler) {
+	s.extra = append(s.extra, middlewares...)
+}
+
 // Run starts the server and blocks until shutdown signal is received.
 func (s *Server) Run(ctx context.Context) error {
 	var handler http.Handler = s.mux
+	handler = Chain(handler, s.extra...)
 	handler = Chain(handler,
+		RequestID,
+		Variant(s.cfg.Variant),
+		SLO(s.slo),
 		DrainCheck(s.lifecycle),
 		ErrorInjection(s.injector),
+		HeaderInjection(s.injector),
 		RequestTracking(s.lifecycle),
 		Metrics,
+		EndpointInFlight(s.cfg.MaxConcurrentOps),
 		Recovery,
 		Logging,
+		SkewedDate,
 	)
 
-	if s.cfg.RequestTimeout > 0 {
-		handler = http.TimeoutHandler(handler, s.cfg.RequestTimeout, `{"error":"request timeout exceeded","code":"OPERATION_TIMEOUT"}`)
-	}
+	timeouts, _ := s.cfg.RequestTimeoutOverrides()
+	handler = RequestTimeout(s.cfg.RequestTimeout, timeouts)(handler)
 
 	s.httpServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.cfg.Port),
-		Handler: handler,
+		Addr:      fmt.Sprintf(":%d", s.cfg.Port),
+		Handler:   Versioned(handler),
+		ConnState: s.lifecycle.ConnState,
+	}
+	if s.cfg.MgmtPort > 0 {
+		s.httpServer.Handler = Versioned(SplitManagement(handler, false))
+		s.mgmtServer = &http.Server{
+			Addr:    fmt.Sprintf(":%d", s.cfg.MgmtPort),
+			Handler: Versioned(SplitManagement(handler, true)),
+		}
 	}
 
-	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
 
-	errCh := make(chan error, 1)
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+	if s.cfg.ProxyProtocol != "" {
+		// Replay, the prober, and work profile self-calls connect over
+		// loopback without a header.
+		pln := proxyproto.NewListener(ln, s.cfg.ProxyProtocol)
+		pln.ExemptLoopback = true
+		ln = pln
+		s.httpServer.ConnContext = proxyproto.ConnContext
+	}
+
+	s.httpServer.TLSConfig = s.tlsConfig
+
+	errCh := make(chan error, 2)
 	go func() {
-		slog.Info("server starting", "port", s.cfg.Port)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		slog.Info("server starting", "port", s.cfg.Port, "proxy_protocol", s.cfg.ProxyProtocol, "tls", s.tlsConfig != nil)
+		var err error
+		if s.tlsConfig != nil {
+			err = s.httpServer.ServeTLS(ln, "", "")
+		} else {
+			err = s.httpServer.Serve(ln)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errCh <- err
 		}
-		close(errCh)
 	}()
+	if s.mgmtServer != nil {
+		go func() {
+			slog.Info("management server starting", "port", s.cfg.MgmtPort)
+			if err := s.mgmtServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				errCh <- fmt.Errorf("management server: %w", err)
+			}
+		}()
+	}
 
-	select {
-	case err := <-errCh:
-		return fmt.Errorf("server error: %w", err)
-	case <-ctx.Done():
-		slog.Info("shutdown signal received")
+wait:
+	for {
+		select {
+		case err := <-errCh:
+			return fmt.Errorf("server error: %w", err)
+		case <-ctx.Done():
+			break wait
+		case sig := <-sigCh:
+			if sig == syscall.SIGTERM && !s.handleSIGTERM() {
+				continue
+			}
+			break wait
+		}
 	}
+	slog.Info("shutdown signal received")
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout+s.cfg.ShutdownDelay+5*time.Second)
 	defer cancel()
@@ -108,9 +195,66 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}()
 
-	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+	err = s.httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		// Connections still open past the deadline are closed forcibly.
+		s.httpServer.Close()
+	}
+	s.lifecycle.FinishShutdown()
+
+	// Probes and metrics stay reachable until traffic has drained.
+	if s.mgmtServer != nil {
+		if err := s.mgmtServer.Shutdown(shutdownCtx); err != nil {
+			s.mgmtServer.Close()
+		}
+	}
+	if err != nil {
 		return fmt.Errorf("shutdown error: %w", err)
 	}
 
 	return nil
 }
+
+// SIGTERM behaviors selectable with HOTPOD_SIGTERM_BEHAVIOR.
+const (
+	SigtermGraceful        = "graceful"
+	SigtermIgnore          = "ignore"
+	SigtermExitImmediately = "exit-immediately"
+	SigtermCrashAfter      = "crash-after"
+)
+
+// sigtermExitCode is the status a process killed by SIGTERM reports, used
+// when exiting without graceful shutdown.
+const sigtermExitCode = 128 + int(syscall.SIGTERM)
+
+// handleSIGTERM applies the configured SIGTERM behavior and reports whether
+// graceful shutdown should begin. Misbehaving modes let us observe how
+// Kubernetes handles workloads that ignore or mishandle termination.
+func (s *Server) handleSIGTERM() bool {
+	behavior := s.cfg.SigtermBehavior
+	if behavior == "" {
+		behavior = SigtermGraceful
+	}
+	events.Record(slog.LevelWarn, events.TypeLifecycle, "SIGTERM received", map[string]any{
+		"behavior": behavior,
+	})
+
+	switch behavior {
+	case SigtermIgnore:
+		slog.Warn("ignoring SIGTERM", "behavior", behavior)
+		return false
+	case SigtermExitImmediately:
+		slog.Warn("exiting immediately on SIGTERM", "exit_code", sigtermExitCode, "in_flight", s.lifecycle.InFlightRequests())
+		s.exit(sigtermExitCode)
+		return false
+	case SigtermCrashAfter:
+		slog.Warn("crash scheduled after SIGTERM", "delay", s.cfg.SigtermDelay)
+		time.AfterFunc(s.cfg.SigtermDelay, func() {
+			slog.Error("crashing after SIGTERM", "exit_code", 1, "in_flight", s.lifecycle.InFlightRequests())
+			s.exit(1)
+		})
+		return false
+	default:
+		return true
+	}
+}
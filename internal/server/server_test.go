@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/config"
+)
+
+func TestHandleSIGTERM(t *testing.T) {
+	tests := []struct {
+		behavior     string
+		wantShutdown bool
+		wantExit     int
+	}{
+		{"", true, -1},
+		{SigtermGraceful, true, -1},
+		{SigtermIgnore, false, -1},
+		{SigtermExitImmediately, false, sigtermExitCode},
+		{SigtermCrashAfter, false, 1},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.Config{SigtermBehavior: tt.behavior, SigtermDelay: time.Millisecond, ShutdownTimeout: time.Second}
+		s := New(cfg, nil)
+		exited := make(chan int, 1)
+		s.exit = func(code int) { exited <- code }
+
+		if got := s.handleSIGTERM(); got != tt.wantShutdown {
+			t.Errorf("%q: handleSIGTERM() = %v, want %v", tt.behavior, got, tt.wantShutdown)
+		}
+
+		if tt.wantExit < 0 {
+			select {
+			case code := <-exited:
+				t.Errorf("%q: unexpected exit(%d)", tt.behavior, code)
+			case <-time.After(20 * time.Millisecond):
+			}
+			continue
+		}
+		select {
+		case code := <-exited:
+			if code != tt.wantExit {
+				t.Errorf("%q: exit code = %d, want %d", tt.behavior, code, tt.wantExit)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%q: process did not exit", tt.behavior)
+		}
+	}
+}
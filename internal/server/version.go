@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ripta/hotpod/pkg/api"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// supportedVersions are the API versions this build serves.
+var supportedVersions = []string{api.Version}
+
+// Versioned returns middleware that routes /v1/* to the unversioned paths,
+// which remain aliases of the current version. The version may instead be
+// requested by the X-Hotpod-API-Version header; either way, the version
+// served is reported in that header of the response.
+func Versioned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix, rest := api.SplitVersion(r.URL.Path)
+		version := api.Version
+		if prefix != "" {
+			version = prefix
+		}
+		if v := r.Header.Get(api.VersionHeader); v != "" {
+			if !strings.HasPrefix(v, "v") {
+				v = "v" + v
+			}
+			if prefix != "" && v != prefix {
+				writeError(w, http.StatusBadRequest, errcode.UnsupportedVersion, "API version header "+v+" conflicts with path version "+prefix)
+				return
+			}
+			version = v
+		}
+		w.Header().Add("Vary", api.VersionHeader)
+		if !slices.Contains(supportedVersions, version) {
+			writeError(w, http.StatusBadRequest, errcode.UnsupportedVersion, "API version "+version+" is not supported, must be one of: "+strings.Join(supportedVersions, ", "))
+			return
+		}
+		w.Header().Set(api.VersionHeader, version)
+
+		if prefix != "" {
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = rest
+			if r.URL.RawPath != "" {
+				r2.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, "/"+prefix)
+			}
+			r = r2
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,163 @@
+// Package shed decides whether to reject requests based on their priority and
+// the current server load, to prototype load-shedding patterns.
+package shed
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ripta/hotpod/internal/rusage"
+)
+
+// Header is the request header carrying the caller-declared priority.
+const Header = "X-Hotpod-Priority"
+
+// Priority is a request's importance. Lower priorities are shed first.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+)
+
+// String returns the header value for p.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return "normal"
+	}
+}
+
+// ParsePriority parses a header value. Unknown or empty values are normal.
+func ParsePriority(s string) Priority {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return PriorityLow
+	case "high":
+		return PriorityHigh
+	case "critical":
+		return PriorityCritical
+	default:
+		return PriorityNormal
+	}
+}
+
+// shedFactor is the fraction of a threshold at which each priority starts to
+// be shed. Low priority goes first, before the threshold is reached; high
+// priority tolerates some overload; critical requests are never shed.
+var shedFactor = map[Priority]float64{
+	PriorityLow:    0.8,
+	PriorityNormal: 1.0,
+	PriorityHigh:   1.2,
+}
+
+// Shed reasons reported to clients and in metrics.
+const (
+	ReasonInFlight = "in_flight"
+	ReasonCPU      = "cpu"
+)
+
+// Shedder tracks in-flight requests and process CPU usage and decides which
+// requests to shed.
+type Shedder struct {
+	maxInFlight int
+	maxCPU      float64
+
+	inFlight atomic.Int64
+	// cpuBits holds the most recent CPU sample, in cores, as float64 bits
+	cpuBits atomic.Uint64
+}
+
+// New creates a shedder. maxInFlight is the in-flight request threshold and
+// maxCPU the process CPU threshold in cores; zero disables either check.
+func New(maxInFlight int, maxCPU float64) *Shedder {
+	return &Shedder{maxInFlight: maxInFlight, maxCPU: maxCPU}
+}
+
+// Enabled reports whether any threshold is configured.
+func (s *Shedder) Enabled() bool {
+	return s != nil && (s.maxInFlight > 0 || s.maxCPU > 0)
+}
+
+// Begin records a request entering the server and returns a function to call
+// when it completes.
+func (s *Shedder) Begin() func() {
+	s.inFlight.Add(1)
+	return func() { s.inFlight.Add(-1) }
+}
+
+// InFlight returns the number of admitted requests in progress.
+func (s *Shedder) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// CPU returns the most recent process CPU usage sample in cores.
+func (s *Shedder) CPU() float64 {
+	return math.Float64frombits(s.cpuBits.Load())
+}
+
+func (s *Shedder) setCPU(cores float64) {
+	s.cpuBits.Store(math.Float64bits(cores))
+}
+
+// Decide reports whether a request of the given priority should be shed, and
+// if so, which threshold triggered it.
+func (s *Shedder) Decide(p Priority) (reason string, shed bool) {
+	factor, ok := shedFactor[p]
+	if !ok || !s.Enabled() {
+		return "", false
+	}
+
+	if s.maxInFlight > 0 && float64(s.inFlight.Load()) >= float64(s.maxInFlight)*factor {
+		return ReasonInFlight, true
+	}
+	if s.maxCPU > 0 && s.CPU() >= s.maxCPU*factor {
+		return ReasonCPU, true
+	}
+	return "", false
+}
+
+// Run samples process CPU usage every interval until ctx is cancelled. It is a
+// no-op when no CPU threshold is configured.
+func (s *Shedder) Run(ctx context.Context, interval time.Duration) {
+	if s.maxCPU <= 0 {
+		return
+	}
+
+	lastCPU, err := rusage.CPUTime()
+	if err != nil {
+		slog.Warn("CPU-based load shedding is unavailable", "error", err)
+		return
+	}
+	lastWall := time.Now()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			cpu, err := rusage.CPUTime()
+			if err != nil {
+				continue
+			}
+			if wall := now.Sub(lastWall); wall > 0 {
+				s.setCPU(float64(cpu-lastCPU) / float64(wall))
+			}
+			lastCPU, lastWall = cpu, now
+		}
+	}
+}
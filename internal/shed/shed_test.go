@@ -0,0 +1,55 @@
+package shed
+
+import "testing"
+
+type decideTest struct {
+	name       string
+	inFlight   int64
+	cpu        float64
+	priority   Priority
+	wantReason string
+}
+
+var decideTests = []decideTest{
+	{"idle", 0, 0, PriorityLow, ""},
+	{"low shed early", 8, 0, PriorityLow, ReasonInFlight},
+	{"normal below threshold", 9, 0, PriorityNormal, ""},
+	{"normal at threshold", 10, 0, PriorityNormal, ReasonInFlight},
+	{"high tolerates overload", 11, 0, PriorityHigh, ""},
+	{"high shed", 12, 0, PriorityHigh, ReasonInFlight},
+	{"critical never shed", 100, 10, PriorityCritical, ""},
+	{"cpu", 0, 2.0, PriorityNormal, ReasonCPU},
+	{"cpu low", 0, 1.6, PriorityLow, ReasonCPU},
+}
+
+func TestDecide(t *testing.T) {
+	for _, tt := range decideTests {
+		s := New(10, 2.0)
+		s.inFlight.Store(tt.inFlight)
+		s.setCPU(tt.cpu)
+
+		reason, shed := s.Decide(tt.priority)
+		if reason != tt.wantReason || shed != (tt.wantReason != "") {
+			t.Errorf("%s: Decide() = %q, %v; want %q", tt.name, reason, shed, tt.wantReason)
+		}
+	}
+}
+
+func TestDecideDisabled(t *testing.T) {
+	s := New(0, 0)
+	s.inFlight.Store(1000)
+	if _, shed := s.Decide(PriorityLow); shed {
+		t.Error("disabled shedder should not shed")
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, s := range []string{"low", "normal", "high", "critical"} {
+		if got := ParsePriority(s).String(); got != s {
+			t.Errorf("ParsePriority(%q) = %s", s, got)
+		}
+	}
+	if got := ParsePriority("urgent"); got != PriorityNormal {
+		t.Errorf("unknown priority = %s, want normal", got)
+	}
+}
@@ -0,0 +1,117 @@
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/requestid"
+	"github.com/ripta/hotpod/pkg/errcode"
+)
+
+// proxyShutdownTimeout bounds how long in-flight proxied requests may take to
+// finish once the proxy is stopped.
+const proxyShutdownTimeout = 10 * time.Second
+
+// Proxy forwards requests to the application container, burning a fixed amount
+// of CPU per request to emulate the data path of a mesh proxy such as Envoy.
+type Proxy struct {
+	overhead time.Duration
+	proxy    *httputil.ReverseProxy
+}
+
+// NewProxy creates a proxy forwarding to target and burning overhead of CPU per
+// request.
+func NewProxy(target *url.URL, overhead time.Duration) *Proxy {
+	p := &Proxy{overhead: overhead}
+	p.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+			r.Out.Host = r.In.Host
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.Warn("sidecar proxy upstream error", "path", r.URL.Path, "error", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			resp := errcode.Response(errcode.UpstreamUnavailable, "upstream unavailable", r.Header.Get(requestid.Header))
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				slog.Debug("failed to write proxy error", "error", err)
+			}
+		},
+	}
+	return p
+}
+
+// ServeHTTP burns the per-request overhead and forwards the request.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.overhead > 0 {
+		cpuBurn(p.overhead)
+		metrics.SidecarCPUBurnSecondsTotal.Add(p.overhead.Seconds())
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	p.proxy.ServeHTTP(rec, r)
+	metrics.SidecarProxyRequestsTotal.WithLabelValues(strconv.Itoa(rec.status)).Inc()
+}
+
+// Run serves the proxy on addr until ctx is cancelled, then waits for
+// in-flight requests to finish.
+func (p *Proxy) Run(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return p.Serve(ctx, ln)
+}
+
+// Serve serves the proxy on ln until ctx is cancelled.
+func (p *Proxy) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: p, ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), proxyShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// statusRecorder captures the response status code.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer, which
+// the reverse proxy uses to flush streamed responses.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
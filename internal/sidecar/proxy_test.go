@@ -0,0 +1,113 @@
+package sidecar
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+)
+
+func TestProxyForwards(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream-Path", r.URL.Path+"?"+r.URL.RawQuery)
+		w.Header().Set("X-Upstream-Forwarded-For", r.Header.Get("X-Forwarded-For"))
+		w.WriteHeader(http.StatusTeapot)
+		io.WriteString(w, "hello from "+r.Host)
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL)
+	p := NewProxy(target, 5*time.Millisecond)
+
+	burnBefore := counterValue(metrics.SidecarCPUBurnSecondsTotal)
+	reqsBefore := counterValue(metrics.SidecarProxyRequestsTotal.WithLabelValues("418"))
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.example/cpu?duration=1s", nil)
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+	if got := rec.Header().Get("X-Upstream-Path"); got != "/cpu?duration=1s" {
+		t.Errorf("upstream path = %q, want /cpu?duration=1s", got)
+	}
+	if got := rec.Header().Get("X-Upstream-Forwarded-For"); got == "" {
+		t.Error("upstream did not receive X-Forwarded-For")
+	}
+	if got := rec.Body.String(); got != "hello from app.example" {
+		t.Errorf("body = %q, want original Host preserved", got)
+	}
+
+	if after := counterValue(metrics.SidecarCPUBurnSecondsTotal); after-burnBefore < 0.005 {
+		t.Errorf("SidecarCPUBurnSecondsTotal increased by %v, want at least 0.005", after-burnBefore)
+	}
+	if after := counterValue(metrics.SidecarProxyRequestsTotal.WithLabelValues("418")); after != reqsBefore+1 {
+		t.Errorf("SidecarProxyRequestsTotal{code=418} = %v, want %v", after, reqsBefore+1)
+	}
+}
+
+func TestProxyUpstreamUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	p := NewProxy(&url.URL{Scheme: "http", Host: addr}, 0)
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+}
+
+func TestProxyServeStopsOnCancel(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewProxy(target, 0).Serve(ctx, ln)
+	}()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("GET through proxy: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("body = %q, want ok", body)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after cancellation")
+	}
+}
@@ -12,56 +12,106 @@ import (
 	"github.com/ripta/hotpod/internal/metrics"
 )
 
+// memoryChunk is the granularity at which memory is held, so jitter and ramp
+// steps do not reallocate everything.
+const memoryChunk = 1 << 20
+
+// memoryTick is how often the memory target is re-evaluated.
+const memoryTick = time.Second
+
+// Config describes the resources a Runner consumes.
+type Config struct {
+	// CPUBaseline is the CPU burned per 1s cycle
+	CPUBaseline time.Duration
+	// CPUJitter is the maximum random variance added to each cycle's burn
+	CPUJitter time.Duration
+	// MemoryBaseline is the memory held, in bytes
+	MemoryBaseline int64
+	// MemoryJitter is the maximum random variance, in bytes, applied each
+	// memoryTick
+	MemoryJitter int64
+	// MemoryPeak, if non-zero, is the level memory ramps to from the baseline
+	// and back, repeatedly
+	MemoryPeak int64
+	// MemoryRamp is how long each ramp between baseline and peak takes
+	MemoryRamp time.Duration
+}
+
 // Runner maintains steady CPU and memory consumption to simulate a sidecar
 // container (e.g., service mesh proxy) for ContainerResource HPA testing.
 type Runner struct {
-	cpuBaseline    time.Duration
-	cpuJitter      time.Duration
-	memoryBaseline int64
+	mu  sync.Mutex
+	cfg Config
+	// rampStart is when the current memory ramp began
+	rampStart time.Time
+	chunks    [][]byte
 
-	mu       sync.Mutex
-	memory   []byte
 	cancel   context.CancelFunc
 	done     chan struct{}
 	stopOnce sync.Once
 }
 
-// New creates a Runner with the given resource baselines.
-func New(cpuBaseline, cpuJitter time.Duration, memoryBaseline int64) *Runner {
-	return &Runner{
-		cpuBaseline:    cpuBaseline,
-		cpuJitter:      cpuJitter,
-		memoryBaseline: memoryBaseline,
-	}
+// New creates a Runner with the given resource configuration.
+func New(cfg Config) *Runner {
+	return &Runner{cfg: cfg}
+}
+
+// Config returns the current resource configuration.
+func (r *Runner) Config() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cfg
 }
 
-// Start allocates baseline memory and begins the CPU burn loop. It blocks
-// until the provided context is cancelled.
+// SetConfig replaces the resource configuration. CPU changes take effect on
+// the next cycle and memory changes within a memoryTick; any memory ramp
+// restarts from the new baseline.
+func (r *Runner) SetConfig(cfg Config) {
+	r.mu.Lock()
+	r.cfg = cfg
+	r.rampStart = time.Now()
+	r.mu.Unlock()
+
+	slog.Info("sidecar runner reconfigured", cfg.logAttrs()...)
+}
+
+// Start allocates baseline memory and begins the CPU burn loop, varying held
+// memory if jitter or a peak is configured. It blocks until the provided
+// context is cancelled.
 func (r *Runner) Start(ctx context.Context) {
 	ctx, r.cancel = context.WithCancel(ctx)
 	r.done = make(chan struct{})
 
 	r.mu.Lock()
-	if r.memoryBaseline > 0 {
-		r.memory = make([]byte, r.memoryBaseline)
-		// Touch every page to ensure the memory is actually allocated by the OS.
-		for i := range r.memory {
-			r.memory[i] = 1
-		}
-		metrics.SidecarMemoryHeldBytes.Set(float64(r.memoryBaseline))
-	}
+	r.rampStart = time.Now()
 	r.mu.Unlock()
+	r.setMemory(r.memoryTarget(time.Now()))
 
-	slog.Info("sidecar runner started",
-		"cpu_baseline", r.cpuBaseline,
-		"cpu_jitter", r.cpuJitter,
-		"memory_baseline", r.memoryBaseline,
-	)
+	slog.Info("sidecar runner started", r.Config().logAttrs()...)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.memoryLoop(ctx)
+	}()
 
 	r.cpuLoop(ctx)
+	wg.Wait()
 	close(r.done)
 }
 
+func (c Config) logAttrs() []any {
+	return []any{
+		"cpu_baseline", c.CPUBaseline,
+		"cpu_jitter", c.CPUJitter,
+		"memory_baseline", c.MemoryBaseline,
+		"memory_jitter", c.MemoryJitter,
+		"memory_peak", c.MemoryPeak,
+		"memory_ramp", c.MemoryRamp,
+	}
+}
+
 // Stop releases held memory and signals the CPU loop to exit. It is safe to
 // call multiple times.
 func (r *Runner) Stop() {
@@ -73,15 +123,113 @@ func (r *Runner) Stop() {
 			<-r.done
 		}
 
-		r.mu.Lock()
-		r.memory = nil
-		r.mu.Unlock()
-
-		metrics.SidecarMemoryHeldBytes.Set(0)
+		r.setMemory(0)
 		slog.Info("sidecar runner stopped")
 	})
 }
 
+// HeldMemory returns the bytes currently held.
+func (r *Runner) HeldMemory() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.heldLocked()
+}
+
+func (r *Runner) heldLocked() int64 {
+	var n int64
+	for _, c := range r.chunks {
+		n += int64(len(c))
+	}
+	return n
+}
+
+func (r *Runner) memoryLoop(ctx context.Context) {
+	ticker := time.NewTicker(memoryTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.setMemory(r.memoryTarget(now))
+		}
+	}
+}
+
+// memoryTarget returns the memory to hold at now.
+func (r *Runner) memoryTarget(now time.Time) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cfg.memoryTarget(now.Sub(r.rampStart))
+}
+
+// memoryTarget returns the memory to hold elapsed into a ramp. With a peak,
+// memory follows a triangle wave: a linear ramp from baseline to peak, then
+// back, each over MemoryRamp. Jitter is applied on top and the result is never
+// negative.
+func (c Config) memoryTarget(elapsed time.Duration) int64 {
+	target := float64(c.MemoryBaseline)
+	if c.MemoryPeak > 0 && c.MemoryRamp > 0 {
+		pos := elapsed % (2 * c.MemoryRamp)
+		frac := float64(pos) / float64(c.MemoryRamp)
+		if frac > 1 {
+			frac = 2 - frac
+		}
+		target += frac * float64(c.MemoryPeak-c.MemoryBaseline)
+	}
+	if c.MemoryJitter > 0 {
+		target += float64(rand.Int64N(c.MemoryJitter*2+1) - c.MemoryJitter)
+	}
+	return int64(math.Max(0, target))
+}
+
+// setMemory grows or shrinks held memory to exactly target bytes. Memory is
+// held in whole chunks plus one partial chunk, so only the chunks that change
+// are allocated or released.
+func (r *Runner) setMemory(target int64) {
+	full := int(target / memoryChunk)
+	rem := int(target % memoryChunk)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.heldLocked() == target {
+		return
+	}
+
+	// Drop any trailing partial chunk; it is reallocated below if needed.
+	if n := len(r.chunks); n > 0 && len(r.chunks[n-1]) < memoryChunk {
+		r.chunks[n-1] = nil
+		r.chunks = r.chunks[:n-1]
+	}
+	if len(r.chunks) > full {
+		clear(r.chunks[full:])
+		r.chunks = r.chunks[:full]
+	}
+	for len(r.chunks) < full {
+		r.chunks = append(r.chunks, touched(memoryChunk))
+	}
+	if rem > 0 {
+		r.chunks = append(r.chunks, touched(rem))
+	}
+	if target == 0 {
+		r.chunks = nil
+	}
+
+	metrics.SidecarMemoryHeldBytes.Set(float64(r.heldLocked()))
+}
+
+// touched allocates n bytes and touches every page so the memory is actually
+// allocated by the OS.
+func touched(n int) []byte {
+	b := make([]byte, n)
+	for i := 0; i < len(b); i += 4096 {
+		b[i] = 1
+	}
+	return b
+}
+
 func (r *Runner) cpuLoop(ctx context.Context) {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
@@ -91,9 +239,10 @@ func (r *Runner) cpuLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			burnDuration := r.cpuBaseline
-			if r.cpuJitter > 0 {
-				jitter := time.Duration(rand.Int64N(int64(r.cpuJitter)*2+1)) - r.cpuJitter
+			cfg := r.Config()
+			burnDuration := cfg.CPUBaseline
+			if cfg.CPUJitter > 0 {
+				jitter := time.Duration(rand.Int64N(int64(cfg.CPUJitter)*2+1)) - cfg.CPUJitter
 				burnDuration += jitter
 				if burnDuration < 0 {
 					burnDuration = 0
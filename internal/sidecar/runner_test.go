@@ -24,7 +24,7 @@ func counterValue(c prometheus.Counter) float64 {
 }
 
 func TestStartAllocatesMemory(t *testing.T) {
-	r := New(0, 0, 1024)
+	r := New(Config{MemoryBaseline: 1024})
 	ctx, cancel := context.WithCancel(context.Background())
 
 	go r.Start(ctx)
@@ -36,11 +36,8 @@ func TestStartAllocatesMemory(t *testing.T) {
 		t.Errorf("SidecarMemoryHeldBytes = %v, want 1024", held)
 	}
 
-	r.mu.Lock()
-	memLen := len(r.memory)
-	r.mu.Unlock()
-	if memLen != 1024 {
-		t.Errorf("memory len = %d, want 1024", memLen)
+	if held := r.HeldMemory(); held != 1024 {
+		t.Errorf("HeldMemory() = %d, want 1024", held)
 	}
 
 	cancel()
@@ -48,7 +45,7 @@ func TestStartAllocatesMemory(t *testing.T) {
 }
 
 func TestStopReleasesMemory(t *testing.T) {
-	r := New(0, 0, 2048)
+	r := New(Config{MemoryBaseline: 2048})
 	ctx, cancel := context.WithCancel(context.Background())
 
 	go r.Start(ctx)
@@ -63,7 +60,7 @@ func TestStopReleasesMemory(t *testing.T) {
 	}
 
 	r.mu.Lock()
-	memNil := r.memory == nil
+	memNil := r.chunks == nil
 	r.mu.Unlock()
 	if !memNil {
 		t.Error("memory should be nil after Stop")
@@ -74,7 +71,7 @@ func TestCPULoopIncrementsBurnCounter(t *testing.T) {
 	before := counterValue(metrics.SidecarCPUBurnSecondsTotal)
 
 	// Use a small baseline so the test completes quickly.
-	r := New(10*time.Millisecond, 0, 0)
+	r := New(Config{CPUBaseline: 10 * time.Millisecond})
 	ctx, cancel := context.WithCancel(context.Background())
 
 	go r.Start(ctx)
@@ -91,7 +88,7 @@ func TestCPULoopIncrementsBurnCounter(t *testing.T) {
 }
 
 func TestContextCancellationStopsRunner(t *testing.T) {
-	r := New(10*time.Millisecond, 0, 512)
+	r := New(Config{CPUBaseline: 10 * time.Millisecond, MemoryBaseline: 512})
 	ctx, cancel := context.WithCancel(context.Background())
 
 	done := make(chan struct{})
@@ -112,14 +109,14 @@ func TestContextCancellationStopsRunner(t *testing.T) {
 }
 
 func TestZeroMemoryBaseline(t *testing.T) {
-	r := New(0, 0, 0)
+	r := New(Config{})
 	ctx, cancel := context.WithCancel(context.Background())
 
 	go r.Start(ctx)
 	time.Sleep(100 * time.Millisecond)
 
 	r.mu.Lock()
-	memNil := r.memory == nil
+	memNil := r.chunks == nil
 	r.mu.Unlock()
 	if !memNil {
 		t.Error("memory should be nil with zero baseline")
@@ -128,3 +125,99 @@ func TestZeroMemoryBaseline(t *testing.T) {
 	cancel()
 	r.Stop()
 }
+
+func TestMemoryTargetRamp(t *testing.T) {
+	cfg := Config{MemoryBaseline: 50 << 20, MemoryPeak: 150 << 20, MemoryRamp: 10 * time.Minute}
+
+	tests := []struct {
+		elapsed time.Duration
+		want    int64
+	}{
+		{0, 50 << 20},
+		{5 * time.Minute, 100 << 20},
+		{10 * time.Minute, 150 << 20},
+		{15 * time.Minute, 100 << 20},
+		{20 * time.Minute, 50 << 20},
+		{25 * time.Minute, 100 << 20},
+	}
+	for _, tt := range tests {
+		if got := cfg.memoryTarget(tt.elapsed); got != tt.want {
+			t.Errorf("memoryTarget(%s) = %d, want %d", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryTargetRampDown(t *testing.T) {
+	cfg := Config{MemoryBaseline: 100, MemoryPeak: 20, MemoryRamp: time.Minute}
+
+	if got := cfg.memoryTarget(30 * time.Second); got != 60 {
+		t.Errorf("memoryTarget(30s) = %d, want 60", got)
+	}
+	if got := cfg.memoryTarget(time.Minute); got != 20 {
+		t.Errorf("memoryTarget(1m) = %d, want 20", got)
+	}
+}
+
+func TestMemoryTargetJitter(t *testing.T) {
+	cfg := Config{MemoryBaseline: 1000, MemoryJitter: 100}
+
+	varied := false
+	for range 100 {
+		got := cfg.memoryTarget(0)
+		if got < 900 || got > 1100 {
+			t.Fatalf("memoryTarget(0) = %d, want within [900, 1100]", got)
+		}
+		if got != 1000 {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("memoryTarget(0) never varied with jitter")
+	}
+
+	cfg = Config{MemoryBaseline: 10, MemoryJitter: 100}
+	for range 100 {
+		if got := cfg.memoryTarget(0); got < 0 {
+			t.Fatalf("memoryTarget(0) = %d, want non-negative", got)
+		}
+	}
+}
+
+func TestSetMemoryResizes(t *testing.T) {
+	r := New(Config{})
+	defer r.setMemory(0)
+
+	for _, target := range []int64{memoryChunk + 100, 3*memoryChunk + 7, memoryChunk, 42, 0} {
+		r.setMemory(target)
+		if got := r.HeldMemory(); got != target {
+			t.Errorf("HeldMemory() after setMemory(%d) = %d", target, got)
+		}
+		if held := gaugeValue(metrics.SidecarMemoryHeldBytes); held != float64(target) {
+			t.Errorf("SidecarMemoryHeldBytes after setMemory(%d) = %v", target, held)
+		}
+	}
+}
+
+func TestSetConfigResizesMemory(t *testing.T) {
+	r := New(Config{MemoryBaseline: 1024})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go r.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	r.SetConfig(Config{MemoryBaseline: 4096})
+	if got := r.Config().MemoryBaseline; got != 4096 {
+		t.Errorf("Config().MemoryBaseline = %d, want 4096", got)
+	}
+
+	deadline := time.Now().Add(3 * memoryTick)
+	for r.HeldMemory() != 4096 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if held := r.HeldMemory(); held != 4096 {
+		t.Errorf("HeldMemory() after SetConfig = %d, want 4096", held)
+	}
+
+	cancel()
+	r.Stop()
+}
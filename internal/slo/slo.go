@@ -0,0 +1,262 @@
+// Package slo computes availability and latency SLIs over hotpod's own
+// traffic against configured objectives, and exports error budget and
+// burn-rate metrics, so SLO alert rules can be validated end-to-end against
+// known fault injections.
+package slo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Resolution is the width of the buckets requests are counted in, and so
+// the granularity of every window.
+const Resolution = 10 * time.Second
+
+// MaxPeriod bounds the error budget period, and with it the memory held for
+// buckets.
+const MaxPeriod = 7 * 24 * time.Hour
+
+// SLI names.
+const (
+	Availability = "availability"
+	Latency      = "latency"
+)
+
+// Config holds the objectives. An objective of zero disables its SLI.
+type Config struct {
+	// Availability is the target fraction of responses that are not 5xx
+	Availability float64
+	// Latency is the target fraction of responses faster than LatencyThreshold
+	Latency          float64
+	LatencyThreshold time.Duration
+	// Period is the error budget period
+	Period time.Duration
+	// Windows are the burn-rate windows, each at most Period
+	Windows []time.Duration
+}
+
+// Enabled reports whether any objective is set.
+func (c Config) Enabled() bool {
+	return c.Availability > 0 || c.Latency > 0
+}
+
+// ParseWindows parses a comma-separated list of burn-rate windows like
+// "5m,1h,6h".
+func ParseWindows(s string) ([]time.Duration, error) {
+	var windows []time.Duration
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d < Resolution {
+			return nil, fmt.Errorf("SLO windows must be comma-separated durations of at least %s, got %q", Resolution, v)
+		}
+		windows = append(windows, d)
+	}
+	return windows, nil
+}
+
+// Validate checks that the objectives, period, and windows are valid.
+func (c Config) Validate() error {
+	if c.Availability < 0 || c.Availability >= 1 {
+		return errors.New("availability objective must be at least 0 and less than 1")
+	}
+	if c.Latency < 0 || c.Latency >= 1 {
+		return errors.New("latency objective must be at least 0 and less than 1")
+	}
+	if c.Latency > 0 && c.LatencyThreshold <= 0 {
+		return errors.New("latency threshold must be positive")
+	}
+	if c.Period < Resolution || c.Period > MaxPeriod {
+		return fmt.Errorf("SLO period must be between %s and %s", Resolution, MaxPeriod)
+	}
+	for _, w := range c.Windows {
+		if w > c.Period {
+			return fmt.Errorf("SLO window %s must not exceed the period %s", w, c.Period)
+		}
+	}
+	return nil
+}
+
+// bucket counts the requests in one Resolution-wide slot.
+type bucket struct {
+	// slot is the bucket's index since the Unix epoch, to detect stale
+	// buckets left from a previous lap of the ring
+	slot  int64
+	total int64
+	// errors are 5xx responses; slow are responses over the threshold
+	errors int64
+	slow   int64
+}
+
+// Tracker counts requests in a ring of buckets covering the error budget
+// period. It is safe for concurrent use, and a nil Tracker records nothing.
+type Tracker struct {
+	cfg Config
+
+	mu      sync.Mutex
+	buckets []bucket
+}
+
+// New creates a tracker for cfg, which must be valid.
+func New(cfg Config) *Tracker {
+	n := int(cfg.Period/Resolution) + 1
+	t := &Tracker{cfg: cfg, buckets: make([]bucket, n)}
+	for _, s := range t.slis() {
+		metrics.SLOObjective.WithLabelValues(s.name).Set(s.objective)
+	}
+	return t
+}
+
+type sli struct {
+	name      string
+	objective float64
+}
+
+func (t *Tracker) slis() []sli {
+	var slis []sli
+	if t.cfg.Availability > 0 {
+		slis = append(slis, sli{Availability, t.cfg.Availability})
+	}
+	if t.cfg.Latency > 0 {
+		slis = append(slis, sli{Latency, t.cfg.Latency})
+	}
+	return slis
+}
+
+// Record counts a response with status that took d, finishing at now.
+func (t *Tracker) Record(now time.Time, status int, d time.Duration) {
+	if t == nil {
+		return
+	}
+	failed := status >= 500
+	slow := t.cfg.Latency > 0 && d > t.cfg.LatencyThreshold
+	if t.cfg.Availability > 0 {
+		metrics.SLOEventsTotal.WithLabelValues(Availability, result(failed)).Inc()
+	}
+	if t.cfg.Latency > 0 {
+		metrics.SLOEventsTotal.WithLabelValues(Latency, result(slow)).Inc()
+	}
+
+	slot := now.UnixNano() / int64(Resolution)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := &t.buckets[slot%int64(len(t.buckets))]
+	if b.slot != slot {
+		*b = bucket{slot: slot}
+	}
+	b.total++
+	if failed {
+		b.errors++
+	}
+	if slow {
+		b.slow++
+	}
+}
+
+func result(bad bool) string {
+	if bad {
+		return "bad"
+	}
+	return "good"
+}
+
+// sum totals the buckets within window of now.
+func (t *Tracker) sum(now time.Time, window time.Duration) bucket {
+	last := now.UnixNano() / int64(Resolution)
+	first := last - int64(window/Resolution) + 1
+	var sum bucket
+	for _, b := range t.buckets {
+		if b.slot >= first && b.slot <= last {
+			sum.total += b.total
+			sum.errors += b.errors
+			sum.slow += b.slow
+		}
+	}
+	return sum
+}
+
+// Status computes each SLI over the period and its burn rate over each
+// window at now. A burn rate of 1 spends the error budget exactly over the
+// period; an error budget remaining below 0 means the objective is missed.
+func (t *Tracker) Status(now time.Time) api.SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := api.SLOStatus{Period: t.cfg.Period.String(), SLIs: []api.SLIStatus{}}
+	period := t.sum(now, t.cfg.Period)
+	windows := make([]bucket, len(t.cfg.Windows))
+	for i, w := range t.cfg.Windows {
+		windows[i] = t.sum(now, w)
+	}
+
+	for _, s := range t.slis() {
+		bad := func(b bucket) int64 {
+			if s.name == Latency {
+				return b.slow
+			}
+			return b.errors
+		}
+		budget := 1 - s.objective
+		st := api.SLIStatus{
+			Name:                 s.name,
+			Objective:            s.objective,
+			Total:                period.total,
+			Bad:                  bad(period),
+			ErrorBudgetRemaining: 1,
+			BurnRates:            map[string]float64{},
+		}
+		if s.name == Latency {
+			st.Threshold = t.cfg.LatencyThreshold.String()
+		}
+		if period.total > 0 {
+			st.ErrorBudgetRemaining = 1 - float64(st.Bad)/float64(period.total)/budget
+		}
+		for i, w := range t.cfg.Windows {
+			rate := 0.0
+			if windows[i].total > 0 {
+				rate = float64(bad(windows[i])) / float64(windows[i].total) / budget
+			}
+			st.BurnRates[w.String()] = rate
+		}
+		status.SLIs = append(status.SLIs, st)
+	}
+	return status
+}
+
+// Update publishes the error budget and burn-rate gauges at now.
+func (t *Tracker) Update(now time.Time) {
+	for _, s := range t.Status(now).SLIs {
+		metrics.SLOErrorBudgetRemaining.WithLabelValues(s.Name).Set(s.ErrorBudgetRemaining)
+		for w, rate := range s.BurnRates {
+			metrics.SLOBurnRate.WithLabelValues(s.Name, w).Set(rate)
+		}
+	}
+}
+
+// Run updates the gauges every Resolution until ctx is cancelled.
+func (t *Tracker) Run(ctx context.Context) {
+	slog.Info("SLO tracker started", "availability", t.cfg.Availability, "latency", t.cfg.Latency, "latency_threshold", t.cfg.LatencyThreshold, "period", t.cfg.Period, "windows", t.cfg.Windows)
+
+	ticker := time.NewTicker(Resolution)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.Update(now)
+		}
+	}
+}
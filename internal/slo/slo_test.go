@@ -0,0 +1,116 @@
+package slo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWindows(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    []time.Duration
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"5m, 1h", []time.Duration{5 * time.Minute, time.Hour}, false},
+		{"1s", nil, true},
+		{"soon", nil, true},
+	} {
+		got, err := ParseWindows(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseWindows(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseWindows(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"availability", Config{Availability: 0.999, Period: time.Hour, Windows: []time.Duration{5 * time.Minute}}, false},
+		{"latency", Config{Latency: 0.99, LatencyThreshold: time.Second, Period: time.Hour}, false},
+		{"availability of one", Config{Availability: 1, Period: time.Hour}, true},
+		{"latency without threshold", Config{Latency: 0.99, Period: time.Hour}, true},
+		{"long period", Config{Availability: 0.99, Period: 2 * MaxPeriod}, true},
+		{"window beyond period", Config{Availability: 0.99, Period: time.Hour, Windows: []time.Duration{2 * time.Hour}}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tr := New(Config{
+		Availability:     0.9,
+		Latency:          0.5,
+		LatencyThreshold: 100 * time.Millisecond,
+		Period:           time.Hour,
+		Windows:          []time.Duration{time.Minute},
+	})
+	start := time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	// An hour ago-ish: 10 good, fast requests, outside the 1m window.
+	for range 10 {
+		tr.Record(start, 200, time.Millisecond)
+	}
+	// Now: 10 requests, 1 failed and 5 slow.
+	now := start.Add(30 * time.Minute)
+	for i := range 10 {
+		status, d := 200, time.Millisecond
+		if i == 0 {
+			status = 503
+		}
+		if i < 5 {
+			d = time.Second
+		}
+		tr.Record(now, status, d)
+	}
+
+	st := tr.Status(now)
+	if len(st.SLIs) != 2 {
+		t.Fatalf("SLIs = %+v, want availability and latency", st.SLIs)
+	}
+	avail, lat := st.SLIs[0], st.SLIs[1]
+	if avail.Name != Availability || avail.Total != 20 || avail.Bad != 1 {
+		t.Errorf("availability = %+v, want 1 bad of 20", avail)
+	}
+	// 1/20 bad against a 10% budget spends half of it.
+	if got := avail.ErrorBudgetRemaining; !approx(got, 0.5) {
+		t.Errorf("availability budget remaining = %g, want 0.5", got)
+	}
+	// 1/10 bad in the last minute burns the 10% budget at 1x.
+	if got := avail.BurnRates["1m0s"]; !approx(got, 1) {
+		t.Errorf("availability burn rate = %g, want 1", got)
+	}
+	if lat.Name != Latency || lat.Bad != 5 || lat.Threshold != "100ms" {
+		t.Errorf("latency = %+v, want 5 bad over 100ms", lat)
+	}
+	if got := lat.BurnRates["1m0s"]; !approx(got, 1) {
+		t.Errorf("latency burn rate = %g, want 1", got)
+	}
+
+	// Past the period, every bucket has aged out.
+	st = tr.Status(start.Add(3 * time.Hour))
+	if st.SLIs[0].Total != 0 || st.SLIs[0].ErrorBudgetRemaining != 1 {
+		t.Errorf("aged out availability = %+v, want no requests and a full budget", st.SLIs[0])
+	}
+}
+
+func TestTrackerNil(t *testing.T) {
+	var tr *Tracker
+	tr.Record(time.Now(), 500, time.Second)
+}
+
+func approx(got, want float64) bool {
+	return got > want-1e-9 && got < want+1e-9
+}
@@ -0,0 +1,340 @@
+// Package state persists runtime chaos settings to a file so they survive
+// process restarts, including the crashes those settings cause.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/health"
+	"github.com/ripta/hotpod/internal/logging"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/queue"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Fault is a persisted error injection rule.
+type Fault struct {
+	Rate      float64    `json:"rate"`
+	Codes     []int      `json:"codes,omitempty"`
+	Delay     string     `json:"delay,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// DelayDistribution shapes Delay, if set
+	DelayDistribution *api.LatencyDistribution `json:"delay_distribution,omitempty"`
+
+	BodyFormat  string `json:"body_format,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+// HeaderFault is a persisted response header fault rule.
+type HeaderFault struct {
+	Rate      float64           `json:"rate"`
+	Set       map[string]string `json:"set,omitempty"`
+	Remove    []string          `json:"remove,omitempty"`
+	Corrupt   []string          `json:"corrupt,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+}
+
+// Snapshot is the persisted runtime state.
+type Snapshot struct {
+	SavedAt        time.Time              `json:"saved_at"`
+	GlobalFault    *Fault                 `json:"global_fault,omitempty"`
+	EndpointFaults map[string]Fault       `json:"endpoint_faults,omitempty"`
+	HeaderFaults   map[string]HeaderFault `json:"header_faults,omitempty"`
+	ReadyOverride  *bool                  `json:"ready_override,omitempty"`
+	QueuePaused    bool                   `json:"queue_paused,omitempty"`
+	ExitCode       *int                   `json:"exit_code,omitempty"`
+	LogLevel       string                 `json:"log_level,omitempty"`
+	ClockSkew      string                 `json:"clock_skew,omitempty"`
+	Dependencies   []api.DependencyCheck  `json:"dependencies,omitempty"`
+	HealthDelays   []api.HealthDelay      `json:"health_delays,omitempty"`
+	ScrapeCost     *ScrapeCost            `json:"scrape_cost,omitempty"`
+}
+
+// ScrapeCost is the persisted /metrics delay and padding.
+type ScrapeCost struct {
+	Delay   string `json:"delay,omitempty"`
+	Jitter  string `json:"jitter,omitempty"`
+	Padding int64  `json:"padding,omitempty"`
+}
+
+// Sources are the components whose state is captured and restored. Queue may
+// be nil in sidecar mode, and Dependencies, HealthDelays, and ScrapeCost may
+// be nil when not tracked.
+type Sources struct {
+	Injector     *fault.Injector
+	Lifecycle    *server.Lifecycle
+	Queue        *queue.Queue
+	Dependencies *health.Dependencies
+	HealthDelays *health.Delays
+	ScrapeCost   *metrics.ScrapeCost
+}
+
+// Store reads and writes snapshots at a file path.
+type Store struct {
+	path string
+	src  Sources
+	mu   sync.Mutex
+}
+
+// NewStore creates a store persisting src to path.
+func NewStore(path string, src Sources) *Store {
+	return &Store{path: path, src: src}
+}
+
+// Restore loads the snapshot, if one exists, and applies it. Expired fault
+// rules are dropped.
+func (s *Store) Restore() error {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading state file: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return fmt.Errorf("parsing state file: %w", err)
+	}
+	s.apply(&snap)
+	slog.Info("restored runtime state", "path", s.path, "saved_at", snap.SavedAt, "endpoint_faults", len(snap.EndpointFaults))
+	return nil
+}
+
+func (s *Store) apply(snap *Snapshot) {
+	if snap.GlobalFault != nil {
+		if cfg := snap.GlobalFault.errorConfig(); !cfg.IsExpired() {
+			s.src.Injector.SetGlobalConfig(cfg)
+		}
+	}
+	for endpoint, f := range snap.EndpointFaults {
+		if cfg := f.errorConfig(); !cfg.IsExpired() {
+			s.src.Injector.SetEndpointConfig(endpoint, cfg)
+		}
+	}
+
+	for endpoint, f := range snap.HeaderFaults {
+		if cfg := f.headerConfig(); !cfg.IsExpired() {
+			s.src.Injector.SetHeaderConfig(endpoint, cfg)
+		}
+	}
+
+	s.src.Lifecycle.SetReadyOverride(snap.ReadyOverride)
+	if snap.ExitCode != nil {
+		s.src.Lifecycle.SetExitCode(*snap.ExitCode)
+	}
+	if s.src.Queue != nil && snap.QueuePaused {
+		s.src.Queue.Pause()
+	}
+	if skew, err := time.ParseDuration(snap.ClockSkew); err == nil {
+		wallclock.SetSkew(skew)
+	}
+	if s.src.Dependencies != nil {
+		for _, check := range snap.Dependencies {
+			if err := s.src.Dependencies.Set(check); err != nil {
+				slog.Warn("ignoring persisted dependency check", "error", err)
+			}
+		}
+	}
+	if s.src.HealthDelays != nil {
+		for _, hd := range snap.HealthDelays {
+			if err := s.src.HealthDelays.Set(hd.Probe, healthDelay(hd)); err != nil {
+				slog.Warn("ignoring persisted health delay", "error", err)
+			}
+		}
+	}
+	if s.src.ScrapeCost != nil && snap.ScrapeCost != nil {
+		if err := snap.ScrapeCost.apply(s.src.ScrapeCost); err != nil {
+			slog.Warn("ignoring persisted scrape cost", "error", err)
+		}
+	}
+	if snap.LogLevel != "" {
+		if err := logging.SetLevel(snap.LogLevel); err != nil {
+			slog.Warn("ignoring persisted log level", "error", err)
+		}
+	}
+}
+
+// Capture returns the current state.
+func (s *Store) Capture() *Snapshot {
+	snap := &Snapshot{
+		SavedAt:       time.Now().UTC(),
+		ReadyOverride: s.src.Lifecycle.ReadyOverride(),
+		LogLevel:      logging.Level(),
+	}
+	if g := s.src.Injector.GetGlobalConfig(); g != nil {
+		f := newFault(g)
+		snap.GlobalFault = &f
+	}
+	if eps := s.src.Injector.GetEndpointConfigs(); len(eps) > 0 {
+		snap.EndpointFaults = make(map[string]Fault, len(eps))
+		for endpoint, cfg := range eps {
+			snap.EndpointFaults[endpoint] = newFault(cfg)
+		}
+	}
+	if hfs := s.src.Injector.GetHeaderConfigs(); len(hfs) > 0 {
+		snap.HeaderFaults = make(map[string]HeaderFault, len(hfs))
+		for endpoint, cfg := range hfs {
+			snap.HeaderFaults[endpoint] = newHeaderFault(cfg)
+		}
+	}
+	if skew := wallclock.Skew(); skew != 0 {
+		snap.ClockSkew = skew.String()
+	}
+	if code, ok := s.src.Lifecycle.ExitCode(); ok {
+		snap.ExitCode = &code
+	}
+	if s.src.Queue != nil {
+		snap.QueuePaused = s.src.Queue.IsPaused()
+	}
+	snap.Dependencies = s.src.Dependencies.List()
+	snap.HealthDelays = s.src.HealthDelays.List()
+	if delay, jitter, padding := s.src.ScrapeCost.Get(); delay > 0 || jitter > 0 || padding > 0 {
+		snap.ScrapeCost = &ScrapeCost{Delay: delay.String(), Jitter: jitter.String(), Padding: padding}
+	}
+	return snap
+}
+
+// Save captures the current state and writes it atomically, so a crash
+// mid-write never leaves a truncated file.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.MarshalIndent(s.Capture(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".hotpod-state-*")
+	if err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	return nil
+}
+
+// SaveOrLog saves the state, logging rather than returning any error.
+func (s *Store) SaveOrLog() {
+	if err := s.Save(); err != nil {
+		slog.Warn("failed to persist runtime state", "path", s.path, "error", err)
+	}
+}
+
+func newFault(cfg *fault.ErrorConfig) Fault {
+	f := Fault{Rate: cfg.Rate, Codes: cfg.Codes}
+	if cfg.Delay > 0 {
+		f.Delay = cfg.Delay.String()
+	}
+	f.DelayDistribution = cfg.DelayDist.API()
+	if cfg.Body != nil {
+		f.BodyFormat = string(cfg.Body.Format)
+		f.ContentType = cfg.Body.ContentType
+		f.Body = cfg.Body.Template
+	}
+	if !cfg.ExpiresAt.IsZero() {
+		t := cfg.ExpiresAt.UTC()
+		f.ExpiresAt = &t
+	}
+	return f
+}
+
+func (f Fault) errorConfig() *fault.ErrorConfig {
+	cfg := &fault.ErrorConfig{Rate: f.Rate, Codes: f.Codes}
+	if d, err := time.ParseDuration(f.Delay); err == nil {
+		cfg.Delay = d
+	}
+	if dist, err := fault.DistributionFromAPI(f.DelayDistribution); err != nil {
+		slog.Warn("ignoring persisted delay distribution", "error", err)
+	} else {
+		cfg.DelayDist = dist
+	}
+	if f.BodyFormat != "" || f.ContentType != "" || f.Body != "" {
+		body, err := fault.NewErrorBody(f.BodyFormat, f.ContentType, f.Body)
+		if err != nil {
+			slog.Warn("ignoring persisted error body", "error", err)
+		} else {
+			cfg.Body = body
+		}
+	}
+	if f.ExpiresAt != nil {
+		cfg.ExpiresAt = *f.ExpiresAt
+	}
+	return cfg
+}
+
+func healthDelay(hd api.HealthDelay) health.Delay {
+	var d health.Delay
+	if fixed, err := time.ParseDuration(hd.Delay); err == nil {
+		d.Fixed = fixed
+	}
+	if jitter, err := time.ParseDuration(hd.Jitter); err == nil {
+		d.Jitter = jitter
+	}
+	return d
+}
+
+func (c ScrapeCost) apply(cost *metrics.ScrapeCost) error {
+	var delay, jitter time.Duration
+	if d, err := time.ParseDuration(c.Delay); err == nil {
+		delay = d
+	}
+	if j, err := time.ParseDuration(c.Jitter); err == nil {
+		jitter = j
+	}
+	return cost.Set(delay, jitter, c.Padding)
+}
+
+func newHeaderFault(cfg *fault.HeaderConfig) HeaderFault {
+	f := HeaderFault{Rate: cfg.Rate, Set: cfg.Set, Remove: cfg.Remove}
+	for _, c := range cfg.Corrupt {
+		f.Corrupt = append(f.Corrupt, string(c))
+	}
+	if !cfg.ExpiresAt.IsZero() {
+		t := cfg.ExpiresAt.UTC()
+		f.ExpiresAt = &t
+	}
+	return f
+}
+
+func (f HeaderFault) headerConfig() *fault.HeaderConfig {
+	cfg := &fault.HeaderConfig{Rate: f.Rate, Set: f.Set, Remove: f.Remove}
+	for _, name := range f.Corrupt {
+		c, err := fault.ParseHeaderCorruption(name)
+		if err != nil {
+			slog.Warn("ignoring persisted header corruption", "error", err)
+			continue
+		}
+		cfg.Corrupt = append(cfg.Corrupt, c)
+	}
+	if f.ExpiresAt != nil {
+		cfg.ExpiresAt = *f.ExpiresAt
+	}
+	return cfg
+}
@@ -0,0 +1,116 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/fault"
+	"github.com/ripta/hotpod/internal/health"
+	"github.com/ripta/hotpod/internal/logging"
+	"github.com/ripta/hotpod/internal/metrics"
+	"github.com/ripta/hotpod/internal/queue"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/internal/wallclock"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func newSources() Sources {
+	return Sources{
+		Injector:     fault.NewInjector(),
+		Lifecycle:    server.NewLifecycle(0, 0, 0, time.Second, false),
+		Queue:        queue.New(10),
+		Dependencies: health.NewDependencies(),
+		HealthDelays: health.NewDelays(),
+		ScrapeCost:   metrics.NewScrapeCost(),
+	}
+}
+
+func TestSaveAndRestore(t *testing.T) {
+	defer logging.SetLevel("info")
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	src := newSources()
+	src.Injector.SetGlobalConfig(&fault.ErrorConfig{Rate: 0.1, Codes: []int{503}})
+	src.Injector.SetEndpointConfig("/cpu", &fault.ErrorConfig{Rate: 0.5, Codes: []int{500, 502}, Delay: 250 * time.Millisecond})
+	src.Injector.SetEndpointConfig("/io", &fault.ErrorConfig{Rate: 1, ExpiresAt: time.Now().Add(50 * time.Millisecond)})
+	src.Injector.SetHeaderConfig("/work", &fault.HeaderConfig{Rate: 1, Remove: []string{"Content-Type"}, Corrupt: []fault.HeaderCorruption{fault.CorruptHugeCookie}})
+	notReady := false
+	src.Lifecycle.SetReadyOverride(&notReady)
+	src.Lifecycle.SetExitCode(7)
+	src.Queue.Pause()
+	src.Dependencies.Set(api.DependencyCheck{Name: "db", Failing: true, Message: "connection refused"})
+	src.HealthDelays.Set(health.ProbeReadyz, health.Delay{Fixed: 2 * time.Second, Jitter: time.Second})
+	src.ScrapeCost.Set(5*time.Second, 0, 1<<20)
+	logging.SetLevel("debug")
+	wallclock.SetSkew(3 * time.Minute)
+	defer wallclock.SetSkew(0)
+
+	if err := NewStore(path, src).Save(); err != nil {
+		t.Fatal(err)
+	}
+	logging.SetLevel("info")
+	wallclock.SetSkew(0)
+	time.Sleep(60 * time.Millisecond)
+
+	restored := newSources()
+	if err := NewStore(path, restored).Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	if g := restored.Injector.GetGlobalConfig(); g == nil || g.Rate != 0.1 {
+		t.Errorf("global fault = %+v", g)
+	}
+	eps := restored.Injector.GetEndpointConfigs()
+	if cpu, ok := eps["/cpu"]; !ok || cpu.Rate != 0.5 || len(cpu.Codes) != 2 || cpu.Delay != 250*time.Millisecond {
+		t.Errorf("/cpu fault = %+v", cpu)
+	}
+	if _, ok := eps["/io"]; ok {
+		t.Error("expired /io fault should not be restored")
+	}
+	if hf := restored.Injector.GetEndpointHeaderConfig("/work"); hf == nil || len(hf.Corrupt) != 1 || hf.Remove[0] != "Content-Type" {
+		t.Errorf("/work header fault = %+v", hf)
+	}
+	if o := restored.Lifecycle.ReadyOverride(); o == nil || *o {
+		t.Errorf("ready override = %v, want false", o)
+	}
+	if code, ok := restored.Lifecycle.ExitCode(); !ok || code != 7 {
+		t.Errorf("exit code = %d, %v", code, ok)
+	}
+	if !restored.Queue.IsPaused() {
+		t.Error("queue should be paused")
+	}
+	if deps := restored.Dependencies.List(); len(deps) != 1 || !deps[0].Failing || deps[0].Message != "connection refused" {
+		t.Errorf("dependencies = %+v, want failing db", deps)
+	}
+	if d := restored.HealthDelays.Get(health.ProbeReadyz); d.Fixed != 2*time.Second || d.Jitter != time.Second {
+		t.Errorf("readyz delay = %+v, want 2s with 1s jitter", d)
+	}
+	if delay, jitter, padding := restored.ScrapeCost.Get(); delay != 5*time.Second || jitter != 0 || padding != 1<<20 {
+		t.Errorf("scrape cost = %v, %v, %d, want 5s, 0s, 1MB", delay, jitter, padding)
+	}
+	if wallclock.Skew() != 3*time.Minute {
+		t.Errorf("clock skew = %v, want 3m", wallclock.Skew())
+	}
+	if logging.Level() != "debug" {
+		t.Errorf("log level = %q, want debug", logging.Level())
+	}
+}
+
+func TestRestoreMissingFile(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "missing.json"), newSources())
+	if err := store.Restore(); err != nil {
+		t.Errorf("Restore() with no file = %v, want nil", err)
+	}
+}
+
+func TestRestoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewStore(path, newSources()).Restore(); err == nil {
+		t.Error("expected error for corrupt state file")
+	}
+}
@@ -0,0 +1,68 @@
+// Package topology resolves where hotpod runs: its pod, StatefulSet
+// ordinal, node, zone, and region. Responses carry it so topology-aware
+// routing and zone spill can be verified from the client side.
+package topology
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/ripta/hotpod/internal/kube"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Well-known node labels read for the zone and region.
+const (
+	ZoneLabel   = "topology.kubernetes.io/zone"
+	RegionLabel = "topology.kubernetes.io/region"
+)
+
+// current is the topology set by Resolve.
+var current atomic.Pointer[api.Topology]
+
+// node is the subset of a core/v1 Node that Resolve reads.
+type node struct {
+	Metadata struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+}
+
+// Resolve determines the topology and makes it the one Current returns. The
+// pod name, ordinal, and node come from the Downward API (POD_NAME,
+// POD_INDEX, NODE_NAME). zone and region, when empty, are read from the
+// node's labels through client, which may be nil to skip the lookup. A
+// failed lookup still sets the rest of the topology, and is returned.
+func Resolve(ctx context.Context, client *kube.Client, zone, region string) (api.Topology, error) {
+	t := api.Topology{Pod: kube.PodName(), Node: kube.NodeName(), Zone: zone, Region: region}
+	if v := os.Getenv("POD_INDEX"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			t.Ordinal = &i
+		}
+	}
+
+	var err error
+	if client != nil && t.Node != "" && (t.Zone == "" || t.Region == "") {
+		var n node
+		if err = client.Do(ctx, "GET", fmt.Sprintf("/api/v1/nodes/%s", t.Node), nil, &n); err == nil {
+			if t.Zone == "" {
+				t.Zone = n.Metadata.Labels[ZoneLabel]
+			}
+			if t.Region == "" {
+				t.Region = n.Metadata.Labels[RegionLabel]
+			}
+		} else {
+			err = fmt.Errorf("looking up node %s: %w", t.Node, err)
+		}
+	}
+
+	current.Store(&t)
+	return t, err
+}
+
+// Current returns the topology set by Resolve, or nil if it has not run.
+func Current() *api.Topology {
+	return current.Load()
+}
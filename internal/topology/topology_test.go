@@ -0,0 +1,59 @@
+package topology
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ripta/hotpod/internal/kube"
+)
+
+func TestResolveFromNodeLabels(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/node-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"metadata":{"labels":{"topology.kubernetes.io/zone":"us-east-1a","topology.kubernetes.io/region":"us-east-1"}}}`))
+	}))
+	defer apiServer.Close()
+
+	t.Setenv("POD_NAME", "hotpod-2")
+	t.Setenv("POD_INDEX", "2")
+	t.Setenv("NODE_NAME", "node-1")
+
+	got, err := Resolve(context.Background(), kube.NewForTesting(apiServer.URL, "ns"), "", "")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got.Pod != "hotpod-2" || got.Ordinal == nil || *got.Ordinal != 2 || got.Node != "node-1" || got.Zone != "us-east-1a" || got.Region != "us-east-1" {
+		t.Errorf("Resolve() = %+v", got)
+	}
+	if Current() == nil || Current().Zone != "us-east-1a" {
+		t.Errorf("Current() = %+v, want the resolved topology", Current())
+	}
+}
+
+func TestResolveOverridesAndFailures(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"nodes is forbidden"}`, http.StatusForbidden)
+	}))
+	defer apiServer.Close()
+
+	t.Setenv("POD_NAME", "hotpod-abc")
+	t.Setenv("POD_INDEX", "")
+	t.Setenv("NODE_NAME", "node-1")
+	client := kube.NewForTesting(apiServer.URL, "ns")
+
+	// Configured values skip the lookup entirely.
+	got, err := Resolve(context.Background(), client, "zone-a", "region-a")
+	if err != nil || got.Zone != "zone-a" || got.Region != "region-a" || got.Ordinal != nil {
+		t.Errorf("Resolve() = %+v, %v, want the configured zone without an ordinal", got, err)
+	}
+
+	got, err = Resolve(context.Background(), client, "", "")
+	if err == nil || got.Pod != "hotpod-abc" || got.Zone != "" {
+		t.Errorf("Resolve() = %+v, %v, want the pod without a zone and an error", got, err)
+	}
+}
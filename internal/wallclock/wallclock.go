@@ -0,0 +1,31 @@
+// Package wallclock provides the wall clock hotpod reports to the outside
+// world. It can be skewed from the node clock so that clock-skew detection
+// can be exercised without touching the node itself.
+//
+// Externally visible timestamps use it, and JWT validation checks exp and nbf
+// against both it and the node clock so that a skew can reject tokens but
+// never accept an expired one. Durations, timeouts, and deadlines keep using
+// the monotonic node clock.
+package wallclock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var skew atomic.Int64
+
+// Now returns the node's current time shifted by the configured skew.
+func Now() time.Time {
+	return time.Now().Add(Skew())
+}
+
+// Skew returns the configured offset from the node clock.
+func Skew() time.Duration {
+	return time.Duration(skew.Load())
+}
+
+// SetSkew sets the offset from the node clock. Zero removes the skew.
+func SetSkew(d time.Duration) {
+	skew.Store(int64(d))
+}
@@ -0,0 +1,23 @@
+package wallclock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSkew(t *testing.T) {
+	defer SetSkew(0)
+
+	SetSkew(-5 * time.Minute)
+	if got := Skew(); got != -5*time.Minute {
+		t.Errorf("Skew() = %v, want -5m", got)
+	}
+	if diff := time.Since(Now()); diff < 5*time.Minute || diff > 5*time.Minute+time.Second {
+		t.Errorf("Now() is %v behind the node clock, want about 5m", diff)
+	}
+
+	SetSkew(0)
+	if diff := time.Since(Now()); diff < 0 || diff > time.Second {
+		t.Errorf("Now() is %v behind the node clock without skew", diff)
+	}
+}
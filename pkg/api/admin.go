@@ -0,0 +1,400 @@
+package api
+
+import "time"
+
+// AdminReadyResponse is the JSON response for POST /admin/ready.
+type AdminReadyResponse struct {
+	Ready    bool   `json:"ready"`
+	Override *bool  `json:"override"`
+	State    string `json:"state"`
+}
+
+// AdminGCMemStats holds memory stats for the GC response.
+type AdminGCMemStats struct {
+	Alloc uint64 `json:"alloc"`
+	Sys   uint64 `json:"sys"`
+	NumGC uint32 `json:"num_gc"`
+}
+
+// AdminGCResponse is the JSON response for POST /admin/gc.
+type AdminGCResponse struct {
+	Before AdminGCMemStats `json:"before"`
+	After  AdminGCMemStats `json:"after"`
+}
+
+// AdminConfigFaultEndpoint holds per-endpoint fault injection config.
+type AdminConfigFaultEndpoint struct {
+	Rate      float64 `json:"rate"`
+	Codes     []int   `json:"codes"`
+	Delay     string  `json:"delay,omitempty"`
+	ExpiresAt string  `json:"expires_at,omitempty"`
+	// DelayDistribution shapes Delay, if set
+	DelayDistribution *LatencyDistribution `json:"delay_distribution,omitempty"`
+
+	BodyFormat  string `json:"body_format,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+// AdminConfigFault holds fault injection state.
+type AdminConfigFault struct {
+	Global    *AdminConfigFaultEndpoint            `json:"global"`
+	Endpoints map[string]*AdminConfigFaultEndpoint `json:"endpoints,omitempty"`
+}
+
+// AdminConfigQueue holds queue state for the config response.
+type AdminConfigQueue struct {
+	Available bool `json:"available"`
+	Depth     int  `json:"depth,omitempty"`
+	Paused    bool `json:"paused,omitempty"`
+	Workers   int  `json:"workers,omitempty"`
+}
+
+// AdminConfigLimits holds configuration limits.
+type AdminConfigLimits struct {
+	MaxCPUDuration        string `json:"max_cpu_duration"`
+	MaxMemorySize         string `json:"max_memory_size"`
+	MaxIOSize             string `json:"max_io_size"`
+	MaxConcurrentOps      int    `json:"max_concurrent_ops"`
+	AdmissionQueueTimeout string `json:"admission_queue_timeout"`
+	RequestTimeout        string `json:"request_timeout"`
+	// RequestTimeouts are the per-endpoint-group overrides of RequestTimeout
+	RequestTimeouts map[string]string `json:"request_timeouts,omitempty"`
+}
+
+// AdminConfigSidecar holds sidecar configuration.
+type AdminConfigSidecar struct {
+	Active          bool   `json:"active"`
+	CPUBaseline     string `json:"cpu_baseline,omitempty"`
+	CPUJitter       string `json:"cpu_jitter,omitempty"`
+	MemoryBaseline  string `json:"memory_baseline,omitempty"`
+	MemoryJitter    string `json:"memory_jitter,omitempty"`
+	MemoryPeak      string `json:"memory_peak,omitempty"`
+	MemoryRamp      string `json:"memory_ramp,omitempty"`
+	RequestOverhead string `json:"request_overhead,omitempty"`
+	ProxyPort       int    `json:"proxy_port,omitempty"`
+	ProxyTarget     string `json:"proxy_target,omitempty"`
+}
+
+// AdminSidecarResponse is the JSON response for GET and POST /admin/sidecar.
+type AdminSidecarResponse struct {
+	CPUBaseline    string `json:"cpu_baseline"`
+	CPUJitter      string `json:"cpu_jitter"`
+	MemoryBaseline string `json:"memory_baseline"`
+	MemoryJitter   string `json:"memory_jitter,omitempty"`
+	MemoryPeak     string `json:"memory_peak,omitempty"`
+	MemoryRamp     string `json:"memory_ramp,omitempty"`
+	// MemoryHeld is the memory currently held, which trails changes by up to
+	// a second
+	MemoryHeld string `json:"memory_held"`
+}
+
+// CustomMetricsResponse is the JSON response for GET /custom-metrics and
+// POST /admin/custom-metrics.
+type CustomMetricsResponse struct {
+	// Pod is the name of the reporting pod
+	Pod string `json:"pod"`
+	// Metrics maps custom metric names to their current values
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// AdminConfigResponse is the JSON response for GET /admin/config.
+type AdminConfigResponse struct {
+	Mode string `json:"mode"`
+	// ReadOnly reports whether faults and state changes are rejected
+	ReadOnly bool               `json:"read_only,omitempty"`
+	Limits   AdminConfigLimits  `json:"limits"`
+	Fault    AdminConfigFault   `json:"fault"`
+	Queue    AdminConfigQueue   `json:"queue"`
+	Sidecar  AdminConfigSidecar `json:"sidecar"`
+}
+
+// AdminResetResponse is the JSON response for POST /admin/reset.
+type AdminResetResponse struct {
+	FaultReset           bool `json:"fault_reset"`
+	QueueCleared         int  `json:"queue_cleared"`
+	WorkersStopped       bool `json:"workers_stopped"`
+	ReadyOverrideCleared bool `json:"ready_override_cleared"`
+	ExitCodeCleared      bool `json:"exit_code_cleared"`
+	ClockSkewCleared     bool `json:"clock_skew_cleared"`
+}
+
+// AdminErrorRateResponse is the JSON response for POST /admin/error-rate.
+type AdminErrorRateResponse struct {
+	Endpoint string  `json:"endpoint"`
+	Rate     float64 `json:"rate"`
+	Codes    []int   `json:"codes"`
+	Delay    string  `json:"delay,omitempty"`
+	Duration string  `json:"duration,omitempty"`
+	// DelayDistribution shapes Delay, if set
+	DelayDistribution *LatencyDistribution `json:"delay_distribution,omitempty"`
+}
+
+// AdminQueuePauseResponse is the JSON response for POST /admin/queue/pause.
+type AdminQueuePauseResponse struct {
+	Paused bool `json:"paused"`
+}
+
+// AdminQueueResumeResponse is the JSON response for POST /admin/queue/resume.
+type AdminQueueResumeResponse struct {
+	Paused bool `json:"paused"`
+}
+
+// QueueFault configures faults injected into queue workers. Rates are
+// probabilities between 0 and 1: panic_rate and stall_rate apply per batch,
+// and duplicate_rate per item.
+type QueueFault struct {
+	PanicRate     float64 `json:"panic_rate"`
+	StallRate     float64 `json:"stall_rate"`
+	Stall         string  `json:"stall,omitempty"`
+	DuplicateRate float64 `json:"duplicate_rate"`
+}
+
+// AdminQueueFaultResponse is the JSON response for /admin/queue/fault.
+type AdminQueueFaultResponse struct {
+	Enabled bool       `json:"enabled"`
+	Fault   QueueFault `json:"fault"`
+}
+
+// Dump is a goroutine or heap dump captured by POST /admin/dump.
+type Dump struct {
+	// Kind is goroutine or heap
+	Kind string `json:"kind"`
+	// Path is where the dump was written, unless it was returned inline
+	Path string `json:"path,omitempty"`
+	Size int    `json:"size"`
+	// Truncated is set when the dump exceeded the server's size limit
+	Truncated bool `json:"truncated,omitempty"`
+	// Data is the dump itself when returned inline
+	Data []byte `json:"data,omitempty"`
+}
+
+// AdminDumpResponse is the JSON response for POST /admin/dump.
+type AdminDumpResponse struct {
+	Dumps []Dump `json:"dumps"`
+}
+
+// AdminAuditResponse is the JSON response for GET /admin/audit.
+type AdminAuditResponse struct {
+	Count   int          `json:"count"`
+	Entries []AuditEntry `json:"entries"`
+}
+
+// AdminLogLevelResponse is the JSON response for /admin/loglevel.
+type AdminLogLevelResponse struct {
+	Level    string `json:"level"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// AdminExitCodeResponse is the JSON response for /admin/exit-code.
+type AdminExitCodeResponse struct {
+	// ExitCode is the status used after graceful shutdown (omitted when unset)
+	ExitCode *int `json:"exit_code,omitempty"`
+}
+
+// AdminClockResponse is the JSON response for /admin/clock.
+type AdminClockResponse struct {
+	Skew        string  `json:"skew"`
+	SkewSeconds float64 `json:"skew_seconds"`
+	// Time is the skewed wall clock reported to clients
+	Time string `json:"time"`
+	// NodeTime is the node's real wall clock
+	NodeTime string `json:"node_time"`
+}
+
+// AdminScrapeCostResponse is the JSON response for /admin/metrics-scrape.
+type AdminScrapeCostResponse struct {
+	Delay  string `json:"delay"`
+	Jitter string `json:"jitter,omitempty"`
+	// Padding is the approximate size added to each /metrics response
+	Padding      string `json:"padding"`
+	PaddingBytes int64  `json:"padding_bytes"`
+}
+
+// AdminTLSFaultsResponse is the JSON response for /admin/tls-faults.
+type AdminTLSFaultsResponse struct {
+	Delay     string  `json:"delay,omitempty"`
+	DelayRate float64 `json:"delay_rate"`
+	FailRate  float64 `json:"fail_rate"`
+	// Cert is expired or wrong-san while a bad certificate is presented
+	Cert string `json:"cert,omitempty"`
+}
+
+// ChaosSchedule is the JSON body for POST /admin/chaos/schedule. Every
+// interval, within any of the windows, one fault is picked from the faults
+// table by probability, or none with the remaining probability.
+type ChaosSchedule struct {
+	Interval string `json:"interval"`
+	// Windows are daily UTC time ranges like 09:00-17:00 that restrict when
+	// faults trigger (empty = always)
+	Windows []string             `json:"windows,omitempty"`
+	Faults  []ChaosScheduleFault `json:"faults"`
+}
+
+// ChaosScheduleFault is one row of a chaos schedule's probability table.
+type ChaosScheduleFault struct {
+	// Kind is hang, error-burst, or latency-spike
+	Kind        string  `json:"kind"`
+	Probability float64 `json:"probability"`
+	Duration    string  `json:"duration"`
+	// Rate is the fraction of requests an error burst fails (0 means all)
+	Rate  float64 `json:"rate,omitempty"`
+	Codes []int   `json:"codes,omitempty"`
+	// Delay is the latency a latency spike adds to each request
+	Delay string `json:"delay,omitempty"`
+}
+
+// AdminChaosScheduleResponse is the JSON response for /admin/chaos/schedule.
+type AdminChaosScheduleResponse struct {
+	Enabled  bool          `json:"enabled"`
+	Schedule ChaosSchedule `json:"schedule"`
+	// Active is the fault in effect, if any
+	Active *ChaosScheduleActive `json:"active,omitempty"`
+}
+
+// ChaosScheduleActive is a fault the chaos schedule has triggered.
+type ChaosScheduleActive struct {
+	Kind  string    `json:"kind"`
+	Until time.Time `json:"until"`
+}
+
+// FaultRule is one error injection rule in a JSON request. An empty endpoint
+// targets all endpoints.
+type FaultRule struct {
+	Endpoint string  `json:"endpoint,omitempty"`
+	Rate     float64 `json:"rate"`
+	Codes    []int   `json:"codes,omitempty"`
+	Delay    string  `json:"delay,omitempty"`
+	// DelayDistribution shapes Delay, e.g. {"kind":"lognormal","sigma":1}
+	DelayDistribution *LatencyDistribution `json:"delay_distribution,omitempty"`
+	Duration          string               `json:"duration,omitempty"`
+	// BodyFormat is one of json, problem, html, text, invalid-json,
+	// truncated, or empty
+	BodyFormat  string `json:"body_format,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	// Body is a text/template for the response body, executed with
+	// .Status, .StatusText, and .Path
+	Body string `json:"body,omitempty"`
+}
+
+// FaultRulesRequest is the JSON body for POST /admin/faults and
+// POST /admin/error-rate.
+type FaultRulesRequest struct {
+	Rules []FaultRule `json:"rules"`
+	// Replace discards all existing rules instead of merging into them
+	Replace bool `json:"replace,omitempty"`
+}
+
+// AdminFaultRule is a configured error injection rule.
+type AdminFaultRule struct {
+	Endpoint string `json:"endpoint,omitempty"`
+	AdminConfigFaultEndpoint
+}
+
+// AdminFaultsResponse is the JSON response for /admin/faults.
+type AdminFaultsResponse struct {
+	Rules []AdminFaultRule `json:"rules"`
+}
+
+// HeaderFaultRule is a response header fault rule. An empty endpoint targets
+// all endpoints.
+type HeaderFaultRule struct {
+	Endpoint string            `json:"endpoint,omitempty"`
+	Rate     float64           `json:"rate"`
+	Set      map[string]string `json:"set,omitempty"`
+	Remove   []string          `json:"remove,omitempty"`
+	// Corrupt lists content-length-long, content-length-short,
+	// content-type, huge-cookie, or connection-close
+	Corrupt   []string `json:"corrupt,omitempty"`
+	Duration  string   `json:"duration,omitempty"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+// AdminHeaderFaultsResponse is the JSON response for /admin/header-faults.
+type AdminHeaderFaultsResponse struct {
+	Rules []HeaderFaultRule `json:"rules"`
+}
+
+// AuditEntry describes a single admin API mutation.
+type AuditEntry struct {
+	// ID is a monotonically increasing sequence number
+	ID uint64 `json:"id"`
+	// Time is when the request completed
+	Time time.Time `json:"time"`
+	// Method is the HTTP method
+	Method string `json:"method"`
+	// Path is the request path
+	Path string `json:"path"`
+	// Params holds the query parameters
+	Params map[string]string `json:"params,omitempty"`
+	// Body holds the (possibly truncated) request body
+	Body string `json:"body,omitempty"`
+	// Remote is the client address
+	Remote string `json:"remote"`
+	// Principal is the name of the authenticated caller, if any
+	Principal string `json:"principal,omitempty"`
+	// TokenFingerprint identifies the credential used without revealing it
+	TokenFingerprint string `json:"token_fingerprint,omitempty"`
+	// Status is the HTTP status code returned
+	Status int `json:"status"`
+	// Duration is how long the request took
+	Duration string `json:"duration"`
+	// RequestID correlates the entry with request logs and events
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// BrownoutStatus is the JSON response for /admin/brownout.
+type BrownoutStatus struct {
+	Active bool `json:"active"`
+	// Level is how far the brownout has ramped, from 0 to 1
+	Level float64 `json:"level"`
+	// Latency and ErrorRate are what the brownout applies at Level
+	Latency   string  `json:"latency,omitempty"`
+	ErrorRate float64 `json:"error_rate,omitempty"`
+	// MaxLatency and MaxErrorRate are reached at the end of the ramp
+	MaxLatency   string     `json:"max_latency,omitempty"`
+	MaxErrorRate float64    `json:"max_error_rate,omitempty"`
+	Codes        []int      `json:"codes,omitempty"`
+	Ramp         string     `json:"ramp,omitempty"`
+	Hold         string     `json:"hold,omitempty"`
+	Started      *time.Time `json:"started,omitempty"`
+	// Until is when the brownout ends, if it has a hold
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// AdminEndpointsResponse is the JSON response for /admin/endpoints.
+type AdminEndpointsResponse struct {
+	Groups []EndpointGroupStatus `json:"groups"`
+}
+
+// EndpointGroupStatus reports whether an endpoint group is enabled.
+type EndpointGroupStatus struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// AdminSelftestResponse is the JSON response for /admin/selftest.
+type AdminSelftestResponse struct {
+	// Passed reports whether every check passed
+	Passed   bool            `json:"passed"`
+	Duration string          `json:"duration"`
+	Checks   []SelftestCheck `json:"checks"`
+}
+
+// SelftestCheck is the result of one self-test request.
+type SelftestCheck struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	// Target is the request path and query
+	Target string `json:"target"`
+	// Status is the response status, or 0 if no request was made
+	Status   int    `json:"status"`
+	Duration string `json:"duration"`
+	Passed   bool   `json:"passed"`
+	// Error describes why the check failed: an unexpected status, or a body
+	// not matching the response schema
+	Error string `json:"error,omitempty"`
+	// Skipped reports that no request was made because the endpoint's group
+	// is disabled; a skipped check does not fail the self-test
+	Skipped bool `json:"skipped,omitempty"`
+}
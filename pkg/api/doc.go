@@ -0,0 +1,48 @@
+// Package api defines the JSON request and response bodies of hotpod's HTTP
+// API. The server encodes these types and pkg/client decodes them, so the two
+// cannot drift apart.
+//
+// The contract is versioned by Version. Within a version, fields are only ever
+// added: existing fields keep their names, JSON keys, and meaning, and clients
+// must ignore fields they do not recognize. Removing or renaming a field, or
+// changing its type, requires a new version.
+package api
+
+import "strings"
+
+// Version is the version of the JSON contract described by this package. It is
+// reported by GET /info.
+const Version = "v1"
+
+// VersionHeader requests a version of the contract, and reports the version
+// each response was served under. A version may instead be requested by
+// prefixing the path with it, as in /v1/info; unprefixed paths are aliases
+// for the current version.
+const VersionHeader = "X-Hotpod-API-Version"
+
+// SplitVersion splits a leading version segment like /v1 from path,
+// returning the version and the path without it, or "" and path if it has
+// none.
+func SplitVersion(path string) (string, string) {
+	seg, rest, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if len(seg) < 2 || seg[0] != 'v' || strings.Trim(seg[1:], "0123456789") != "" {
+		return "", path
+	}
+	return seg, "/" + rest
+}
+
+// ErrorResponse is the JSON body of every non-2xx response from hotpod's own
+// handlers.
+type ErrorResponse struct {
+	// Error is a human-readable message
+	Error string `json:"error"`
+	// Code is a machine-readable error code, e.g. INVALID_PARAMETER; see
+	// pkg/errcode for the full list
+	Code string `json:"code"`
+	// DocURL links to the documentation for Code
+	DocURL string `json:"doc_url,omitempty"`
+	// Retryable reports whether the same request may succeed later
+	Retryable bool `json:"retryable"`
+	// RequestID is the X-Request-ID of the failed request
+	RequestID string `json:"request_id,omitempty"`
+}
@@ -0,0 +1,32 @@
+package api
+
+import "time"
+
+// EventsResponse is the JSON response for GET /events.
+type EventsResponse struct {
+	Count  int     `json:"count"`
+	Events []Event `json:"events"`
+}
+
+// CreateEventRequest is the JSON body for POST /events.
+type CreateEventRequest struct {
+	Message string         `json:"message"`
+	Level   string         `json:"level,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// Event is a single recorded occurrence.
+type Event struct {
+	// ID is a monotonically increasing sequence number
+	ID uint64 `json:"id"`
+	// Time is when the event was recorded
+	Time time.Time `json:"time"`
+	// Level is the severity: debug, info, warn, error
+	Level string `json:"level"`
+	// Type is the event category (lifecycle, fault, scenario, admin)
+	Type string `json:"type"`
+	// Message is a short human-readable description
+	Message string `json:"message"`
+	// Attrs holds additional structured detail
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
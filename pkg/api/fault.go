@@ -0,0 +1,130 @@
+package api
+
+import "time"
+
+// CrashResponse is the JSON response for /fault/crash (sent before crashing).
+type CrashResponse struct {
+	Message   string `json:"message"`
+	Delay     string `json:"delay"`
+	ExitCode  int    `json:"exit_code"`
+	Scheduled bool   `json:"scheduled"`
+}
+
+// PanicResponse is the JSON response for /fault/panic with goroutine=true
+// (sent before panicking). A handler panic responds with a 500 instead.
+type PanicResponse struct {
+	Message   string `json:"message"`
+	Delay     string `json:"delay"`
+	Goroutine bool   `json:"goroutine"`
+}
+
+// HangResponse is the JSON response for /fault/hang.
+type HangResponse struct {
+	Message   string `json:"message"`
+	Duration  string `json:"duration"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+}
+
+// OOMResponse is the JSON response for /fault/oom (sent before OOM starts).
+type OOMResponse struct {
+	Message string `json:"message"`
+	Rate    string `json:"rate"`
+	Started bool   `json:"started"`
+	// Until is the stopping condition, if allocation is bounded
+	Until string `json:"until,omitempty"`
+	// Target is the allocation target in bytes, or the memory.high threshold
+	// when Until is high. Memory is held once the target is reached.
+	Target int64 `json:"target,omitempty"`
+}
+
+// OOM simulation states reported by OOMStatus.
+const (
+	OOMStateIdle       = "idle"
+	OOMStateAllocating = "allocating"
+	OOMStateHolding    = "holding"
+)
+
+// OOMStatus is the JSON response for GET and DELETE /fault/oom.
+type OOMStatus struct {
+	State string `json:"state"`
+	// Rate is the allocation rate in bytes per second
+	Rate      int64      `json:"rate,omitempty"`
+	Until     string     `json:"until,omitempty"`
+	Target    int64      `json:"target,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// Allocated is the number of bytes allocated by the current run
+	Allocated int64 `json:"allocated"`
+	// Released is the number of bytes freed by DELETE /fault/oom
+	Released int64 `json:"released,omitempty"`
+}
+
+// FaultErrorResponse is the JSON response for /fault/error.
+type FaultErrorResponse struct {
+	Injected bool   `json:"injected"`
+	Status   int    `json:"status,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ZombieResponse is the JSON response for /fault/zombie.
+type ZombieResponse struct {
+	Created int    `json:"created"`
+	Reaped  int    `json:"reaped,omitempty"`
+	Total   int    `json:"total"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ThreadsResponse is the JSON response for /fault/threads.
+type ThreadsResponse struct {
+	Started        int    `json:"started,omitempty"`
+	Released       int    `json:"released,omitempty"`
+	Duration       string `json:"duration,omitempty"`
+	Held           int    `json:"held"`
+	ThreadsCreated int    `json:"threads_created"`
+}
+
+// DeadlockResponse is the JSON response for /fault/deadlock.
+type DeadlockResponse struct {
+	Created    int `json:"created"`
+	TotalPairs int `json:"total_pairs"`
+}
+
+// ContentionResponse is the JSON response for /fault/contention.
+type ContentionResponse struct {
+	Workers      int    `json:"workers"`
+	Hold         string `json:"hold"`
+	Duration     string `json:"duration"`
+	Async        bool   `json:"async,omitempty"`
+	Acquisitions int64  `json:"acquisitions,omitempty"`
+	TotalWait    string `json:"total_wait,omitempty"`
+}
+
+// PortStressStatus reports progress of the current or last run.
+type PortStressStatus struct {
+	Running   bool             `json:"running"`
+	Target    string           `json:"target,omitempty"`
+	StartedAt *time.Time       `json:"started_at,omitempty"`
+	Attempts  int64            `json:"attempts"`
+	Connected int64            `json:"connected"`
+	Errors    map[string]int64 `json:"errors,omitempty"`
+}
+
+// EvictResponse is the JSON response for /fault/evict, sent once the API
+// server accepts the pod's deletion.
+type EvictResponse struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	// GracePeriodSeconds is the override sent with the deletion, if any
+	GracePeriodSeconds *int64 `json:"grace_period_seconds,omitempty"`
+}
+
+// StormStatus is the JSON response for /fault/storm.
+type StormStatus struct {
+	Active bool `json:"active"`
+	// Rate is the fraction of requests failed (0 means all)
+	Rate       float64    `json:"rate,omitempty"`
+	RetryAfter []string   `json:"retry_after,omitempty"`
+	HTTPDate   bool       `json:"http_date,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+	// Rejected is the number of requests failed by the latest storm
+	Rejected int64 `json:"rejected"`
+}
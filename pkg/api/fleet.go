@@ -0,0 +1,44 @@
+package api
+
+// FleetPeersResponse is the JSON response for GET /admin/peers.
+type FleetPeersResponse struct {
+	Count int         `json:"count"`
+	Peers []FleetPeer `json:"peers"`
+}
+
+// FleetCommand is the JSON request for POST /admin/broadcast: an HTTP request
+// to send to every peer.
+type FleetCommand struct {
+	// Method defaults to POST
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Params map[string]string `json:"params,omitempty"`
+	// Body is sent verbatim as the request body, if non-empty
+	Body string `json:"body,omitempty"`
+}
+
+// FleetBroadcastResponse is the JSON response for POST /admin/broadcast.
+type FleetBroadcastResponse struct {
+	Peers     int           `json:"peers"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []FleetResult `json:"results"`
+}
+
+// FleetPeer is a discovered hotpod instance.
+type FleetPeer struct {
+	// Name is the pod name, or the address for DNS discovery
+	Name string `json:"name"`
+	// Addr is the host:port of the peer's HTTP server
+	Addr string `json:"addr"`
+}
+
+// FleetResult is the outcome of sending a command to one peer.
+type FleetResult struct {
+	Peer   string `json:"peer"`
+	Addr   string `json:"addr"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Body holds the (possibly truncated) response body
+	Body string `json:"body,omitempty"`
+}
@@ -0,0 +1,25 @@
+package api
+
+import "time"
+
+// LeaderFaultResponse is the JSON response for leadership faults.
+type LeaderFaultResponse struct {
+	Fault     string `json:"fault"`
+	Duration  string `json:"duration"`
+	WasLeader bool   `json:"was_leader"`
+}
+
+// LeaderStatus describes the elector's view of the lease.
+type LeaderStatus struct {
+	Enabled      bool       `json:"enabled"`
+	Identity     string     `json:"identity"`
+	Lease        string     `json:"lease"`
+	Leader       bool       `json:"leader"`
+	Holder       string     `json:"holder,omitempty"`
+	Transitions  int32      `json:"transitions"`
+	AcquireTime  *time.Time `json:"acquire_time,omitempty"`
+	RenewTime    *time.Time `json:"renew_time,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	HeldOffUntil *time.Time `json:"held_off_until,omitempty"`
+	StalledUntil *time.Time `json:"stalled_until,omitempty"`
+}
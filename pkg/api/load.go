@@ -0,0 +1,304 @@
+package api
+
+// CPUResponse is the JSON response for /cpu.
+type CPUResponse struct {
+	// RequestedDuration is the duration parameter value
+	RequestedDuration string `json:"requested_duration"`
+	// ActualDuration is how long the operation actually took
+	ActualDuration string `json:"actual_duration"`
+	// Cores is the number of goroutines used for CPU work
+	Cores int `json:"cores"`
+	// Intensity is the intensity level used
+	Intensity string `json:"intensity"`
+	// Iterations is the total number of work iterations completed
+	Iterations int64 `json:"iterations"`
+	// Cancelled indicates if the operation was cancelled
+	Cancelled bool `json:"cancelled,omitempty"`
+	// LimitApplied indicates if the duration was capped by the safety limit
+	LimitApplied bool `json:"limit_applied,omitempty"`
+	// Pinned indicates the burn goroutines were locked to OS threads
+	Pinned bool `json:"pinned,omitempty"`
+	// CPUs lists the CPUs the burn threads were bound to, round-robin
+	CPUs []int `json:"cpus,omitempty"`
+	// Throttling is the container's CPU throttling during the request, if
+	// cgroup statistics are available
+	Throttling *CPUThrottling `json:"throttling,omitempty"`
+}
+
+// CPUThrottling reports the change in the container's cgroup CPU throttling
+// counters over a request. The counters cover the whole container, so
+// concurrent requests and background load contribute too.
+type CPUThrottling struct {
+	// ThrottledDuration is the time the container was throttled
+	ThrottledDuration string `json:"throttled_duration"`
+	// Periods is the number of CFS enforcement periods that elapsed
+	Periods uint64 `json:"periods"`
+	// ThrottledPeriods is the number of those periods that were throttled
+	ThrottledPeriods uint64 `json:"throttled_periods"`
+}
+
+// MemoryResponse is the JSON response for /memory.
+type MemoryResponse struct {
+	// RequestedSize is the size parameter value in bytes
+	RequestedSize int64 `json:"requested_size"`
+	// RequestedSizeHuman is the human-readable size
+	RequestedSizeHuman string `json:"requested_size_human"`
+	// Duration is how long the memory was held
+	Duration string `json:"duration"`
+	// Pattern is the fill pattern used
+	Pattern string `json:"pattern"`
+	// Cancelled indicates if the operation was cancelled
+	Cancelled bool `json:"cancelled,omitempty"`
+	// LimitApplied indicates if the size was capped by the safety limit
+	LimitApplied bool `json:"limit_applied,omitempty"`
+}
+
+// MemoryPressureResponse is the JSON response for /memory/pressure. Fault
+// counts cover the whole process during the request.
+type MemoryPressureResponse struct {
+	// RequestedSize is the working set size in bytes
+	RequestedSize int64 `json:"requested_size"`
+	// RequestedSizeHuman is the human-readable size
+	RequestedSizeHuman string `json:"requested_size_human"`
+	// Duration is how long the working set was touched
+	Duration string `json:"duration"`
+	// Access is the page visiting order: random or sequential
+	Access string `json:"access"`
+	// Passes is the number of complete walks over the working set
+	Passes int64 `json:"passes"`
+	// PagesTouched is the number of page writes
+	PagesTouched int64  `json:"pages_touched"`
+	MinorFaults  uint64 `json:"minor_faults"`
+	MajorFaults  uint64 `json:"major_faults"`
+	// MinorFaultRate and MajorFaultRate are faults per second
+	MinorFaultRate float64 `json:"minor_fault_rate"`
+	MajorFaultRate float64 `json:"major_fault_rate"`
+	// Cancelled indicates if the operation was cancelled
+	Cancelled bool `json:"cancelled,omitempty"`
+	// LimitApplied indicates if the size was capped by the safety limit
+	LimitApplied bool `json:"limit_applied,omitempty"`
+}
+
+// UploadResponse is the JSON response for /upload.
+type UploadResponse struct {
+	// BytesRead is the size of the request body read
+	BytesRead int64 `json:"bytes_read"`
+	// Rate is the rate parameter value, if the body was read slowly
+	Rate string `json:"rate,omitempty"`
+	// Delay is the delay parameter value
+	Delay string `json:"delay,omitempty"`
+	// ActualDuration is how long reading the body took
+	ActualDuration string `json:"actual_duration"`
+	// Cancelled indicates if the body was not read to the end
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// IOResponse is the JSON response for /io.
+type IOResponse struct {
+	// RequestedSize is the size parameter value in bytes
+	RequestedSize int64 `json:"requested_size"`
+	// RequestedSizeHuman is the human-readable size
+	RequestedSizeHuman string `json:"requested_size_human"`
+	// Operation is the I/O operation type
+	Operation string `json:"operation"`
+	// Sync indicates if fsync was used
+	Sync bool `json:"sync"`
+	// ActualDuration is how long the operation took
+	ActualDuration string `json:"actual_duration"`
+	// BytesWritten is the number of bytes written
+	BytesWritten int64 `json:"bytes_written,omitempty"`
+	// BytesRead is the number of bytes read
+	BytesRead int64 `json:"bytes_read,omitempty"`
+	// Cancelled indicates if the operation was cancelled
+	Cancelled bool `json:"cancelled,omitempty"`
+	// LimitApplied indicates if the size was capped by the safety limit
+	LimitApplied bool `json:"limit_applied,omitempty"`
+	// Duration is the requested duration for sustained I/O, which repeats
+	// the operation of RequestedSize bytes until it elapses
+	Duration string `json:"duration,omitempty"`
+	// Rate is the target throughput in bytes per second (0 = unlimited)
+	Rate int64 `json:"rate,omitempty"`
+	// ActualRate is the measured throughput in bytes per second
+	ActualRate int64 `json:"actual_rate,omitempty"`
+	// Passes is the number of completed operations during sustained I/O
+	Passes int `json:"passes,omitempty"`
+}
+
+// WorkResponse is the JSON response for /work.
+type WorkResponse struct {
+	// Profile is the workload profile used
+	Profile string `json:"profile"`
+	// Variance is the variance multiplier applied
+	Variance float64 `json:"variance"`
+	// ActualDuration is the total time for the composite workload
+	ActualDuration string `json:"actual_duration"`
+	// CPUDuration is how long CPU work ran
+	CPUDuration string `json:"cpu_duration"`
+	// CPUIterations is the number of CPU work iterations
+	CPUIterations int64 `json:"cpu_iterations"`
+	// MemorySize is the amount of memory allocated
+	MemorySize int64 `json:"memory_size"`
+	// MemorySizeHuman is the human-readable memory size
+	MemorySizeHuman string `json:"memory_size_human"`
+	// Latency is the simulated latency duration
+	Latency string `json:"latency"`
+	// IOBytes is the number of bytes written and read back
+	IOBytes int64 `json:"io_bytes,omitempty"`
+	// Downstream reports the outbound call, if the profile makes one
+	Downstream *WorkDownstream `json:"downstream,omitempty"`
+	// Cancelled indicates if the operation was cancelled
+	Cancelled bool `json:"cancelled,omitempty"`
+	// LimitsApplied indicates if any limits were applied
+	LimitsApplied bool `json:"limits_applied,omitempty"`
+	// Topology is where the responding pod runs
+	Topology *Topology `json:"topology,omitempty"`
+}
+
+// WorkDownstream reports the outbound call phase of a /work request.
+type WorkDownstream struct {
+	URL      string `json:"url"`
+	Status   int    `json:"status,omitempty"`
+	Duration string `json:"duration"`
+	// Error is set when no response was received
+	Error string `json:"error,omitempty"`
+}
+
+// WorkProfile describes a composite /work workload. Durations and sizes use
+// the same notation as query parameters; omitted components are skipped.
+type WorkProfile struct {
+	Name string `json:"name"`
+	// CPU is how long to burn CPU, e.g. 20ms
+	CPU string `json:"cpu,omitempty"`
+	// Cores is the number of cores to burn CPU on (default 1)
+	Cores int `json:"cores,omitempty"`
+	// Intensity is low, medium, high, cache, or vector (default medium)
+	Intensity string `json:"intensity,omitempty"`
+	// Memory is held while CPU burns, e.g. 5Mi
+	Memory string `json:"memory,omitempty"`
+	// Latency is a wait alongside the other components, e.g. 50ms
+	Latency string `json:"latency,omitempty"`
+	// IO is written to and read back from the I/O directory, e.g. 1Mi
+	IO string `json:"io,omitempty"`
+	// Downstream is an http or https URL to GET alongside the other
+	// components, or "self" to call this pod's own /latency endpoint
+	Downstream string `json:"downstream,omitempty"`
+	// DownstreamLatency is the duration a self-call asks /latency to wait
+	// (default 50ms)
+	DownstreamLatency string `json:"downstream_latency,omitempty"`
+	// Builtin is set in responses for hotpod's default profiles
+	Builtin bool `json:"builtin,omitempty"`
+}
+
+// WorkProfilesResponse is the JSON response for GET /admin/profiles.
+type WorkProfilesResponse struct {
+	Count    int           `json:"count"`
+	Profiles []WorkProfile `json:"profiles"`
+}
+
+// LatencyResponse is the JSON response for /latency.
+type LatencyResponse struct {
+	// RequestedDuration is the duration parameter value
+	RequestedDuration string `json:"requested_duration"`
+	// ActualDuration is how long the operation actually took
+	ActualDuration string `json:"actual_duration"`
+	// Jitter is the jitter parameter value
+	Jitter string `json:"jitter,omitempty"`
+	// Status is the HTTP status code returned
+	Status int `json:"status"`
+	// Cancelled indicates if the operation was cancelled
+	Cancelled bool `json:"cancelled,omitempty"`
+	// Curve is the adaptive latency curve, if mode=adaptive
+	Curve string `json:"curve,omitempty"`
+	// Concurrency is the number of concurrent latency requests, including this
+	// one, observed when mode=adaptive
+	Concurrency int64 `json:"concurrency,omitempty"`
+	// Multiplier is the factor applied to duration by the adaptive curve
+	Multiplier float64 `json:"multiplier,omitempty"`
+	// Distribution is the distribution duration was sampled from, if any
+	Distribution *LatencyDistribution `json:"distribution,omitempty"`
+}
+
+// StreamChunk is a line of the /stream-latency response body.
+type StreamChunk struct {
+	// Chunk is the chunk's 1-based index
+	Chunk int `json:"chunk"`
+	// Elapsed is the time since the request arrived
+	Elapsed string `json:"elapsed"`
+}
+
+// StreamLatencyResponse is the last line of the /stream-latency response
+// body.
+type StreamLatencyResponse struct {
+	// TTFB is the requested wait before the response header
+	TTFB string `json:"ttfb"`
+	// BodyDuration is the requested time to stream the body over
+	BodyDuration string `json:"body_duration"`
+	// Chunks is the number of chunks requested
+	Chunks int `json:"chunks"`
+	// Sent is the number of chunks sent
+	Sent int `json:"sent"`
+	// Status is the HTTP status code returned
+	Status int `json:"status"`
+	// ActualTTFB is how long the header actually took
+	ActualTTFB string `json:"actual_ttfb"`
+	// ActualBodyDuration is how long the body actually took
+	ActualBodyDuration string `json:"actual_body_duration"`
+	// ActualDuration is how long the whole response took
+	ActualDuration string `json:"actual_duration"`
+	// Cancelled indicates if the stream was cut short
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// LatencyDistribution shapes latency around a base duration.
+type LatencyDistribution struct {
+	// Kind is normal, lognormal, pareto, or bimodal
+	Kind string `json:"kind"`
+	// StdDev is the normal distribution's standard deviation
+	StdDev string `json:"stddev,omitempty"`
+	// Sigma is the lognormal distribution's shape
+	Sigma float64 `json:"sigma,omitempty"`
+	// Alpha is the pareto distribution's shape
+	Alpha float64 `json:"alpha,omitempty"`
+	// Slow and SlowFraction are the bimodal distribution's slow mode and
+	// the fraction of samples in it
+	Slow         string  `json:"slow,omitempty"`
+	SlowFraction float64 `json:"slow_fraction,omitempty"`
+}
+
+// DNSLatency summarizes lookup latencies.
+type DNSLatency struct {
+	Min  string `json:"min"`
+	Mean string `json:"mean"`
+	P50  string `json:"p50"`
+	P90  string `json:"p90"`
+	P99  string `json:"p99"`
+	Max  string `json:"max"`
+}
+
+// DNSResponse is the JSON response for /dns.
+type DNSResponse struct {
+	// Name is the name looked up, after any trailing dot was added
+	Name string `json:"name"`
+	// Type is the record type queried
+	Type string `json:"type"`
+	// Resolver is "system" or "go"
+	Resolver string `json:"resolver"`
+	// Server is the DNS server queried directly, if set
+	Server string `json:"server,omitempty"`
+	// Count is the number of lookups performed
+	Count int `json:"count"`
+	// Succeeded is the number of lookups that returned an answer
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of lookups that returned an error
+	Failed int `json:"failed"`
+	// Errors counts failures by class: not_found, timeout, temporary, error
+	Errors map[string]int `json:"errors,omitempty"`
+	// Latency summarizes lookup latencies across all attempts
+	Latency DNSLatency `json:"latency"`
+	// Answers is a sample of the answers from the last successful lookup
+	Answers []string `json:"answers,omitempty"`
+	// ActualDuration is how long all lookups took
+	ActualDuration string `json:"actual_duration"`
+	// Cancelled indicates if the request was cancelled before all lookups ran
+	Cancelled bool `json:"cancelled,omitempty"`
+}
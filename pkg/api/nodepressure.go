@@ -0,0 +1,16 @@
+package api
+
+import "time"
+
+// NodePressureStatus is the JSON response for /admin/node-pressure.
+type NodePressureStatus struct {
+	Node string `json:"node"`
+	// Enabled is whether hotpod backs off under node pressure
+	Enabled bool `json:"enabled"`
+	// BackingOff is whether load is currently backed off
+	BackingOff bool `json:"backing_off"`
+	// Conditions are the pressure conditions the node reports as True
+	Conditions []string   `json:"conditions,omitempty"`
+	LastCheck  *time.Time `json:"last_check,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+}
@@ -0,0 +1,20 @@
+package api
+
+import "time"
+
+// ProbesResponse is the JSON response for GET /admin/probes.
+type ProbesResponse struct {
+	Interval string        `json:"interval"`
+	Results  []ProbeResult `json:"results"`
+}
+
+// ProbeResult is the latest probe of one target.
+type ProbeResult struct {
+	Target  string    `json:"target"`
+	Time    time.Time `json:"time"`
+	Success bool      `json:"success"`
+	// StatusCode is 0 if no response was received
+	StatusCode int    `json:"status_code,omitempty"`
+	Duration   string `json:"duration"`
+	Error      string `json:"error,omitempty"`
+}
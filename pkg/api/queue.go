@@ -0,0 +1,101 @@
+package api
+
+import "time"
+
+// EnqueueRequest is the JSON body for POST /queue/enqueue, describing items
+// individually rather than through query parameters.
+type EnqueueRequest struct {
+	Items []EnqueueItem `json:"items"`
+}
+
+// EnqueueItem describes one or more identical items in an EnqueueRequest.
+type EnqueueItem struct {
+	// Count is the number of copies to enqueue (default 1)
+	Count int `json:"count,omitempty"`
+	// Priority is high, normal, or low (default normal)
+	Priority string `json:"priority,omitempty"`
+	// ProcessingTime is a duration, e.g. 250ms (default 100ms)
+	ProcessingTime string `json:"processing_time,omitempty"`
+	// PayloadSize is the memory a worker holds while processing the item, in
+	// size notation, e.g. 512Ki. Items are rejected once queued payloads
+	// would exceed HOTPOD_MAX_MEMORY_SIZE.
+	PayloadSize string `json:"payload_size,omitempty"`
+	// NotBefore delays delivery until the given time
+	NotBefore time.Time `json:"not_before,omitzero"`
+}
+
+// EnqueueResponse is the JSON response for /queue/enqueue.
+type EnqueueResponse struct {
+	Enqueued             int    `json:"enqueued"`
+	QueueDepth           int    `json:"queue_depth"`
+	EstimatedProcessTime string `json:"estimated_process_time"`
+	Rejected             int    `json:"rejected,omitempty"`
+	RejectionReason      string `json:"rejection_reason,omitempty"`
+}
+
+// ProcessResponse is the JSON response for /queue/process.
+type ProcessResponse struct {
+	Workers       int    `json:"workers"`
+	CPUPerItem    string `json:"cpu_per_item"`
+	MemoryPerItem string `json:"memory_per_item"`
+	BatchSize     int    `json:"batch_size"`
+	Started       bool   `json:"started"`
+}
+
+// QueueWorkersResponse is the JSON response for POST /queue/workers.
+type QueueWorkersResponse struct {
+	Workers  int `json:"workers"`
+	Previous int `json:"previous"`
+	// ActiveWorkers is the number of workers busy with an item, including
+	// retired workers finishing their last item
+	ActiveWorkers int `json:"active_workers"`
+}
+
+// QueueStatusResponse is the JSON response for /queue/status.
+type QueueStatusResponse struct {
+	QueueDepth          int    `json:"queue_depth"`
+	HighPriorityDepth   int    `json:"high_priority_depth"`
+	NormalPriorityDepth int    `json:"normal_priority_depth"`
+	LowPriorityDepth    int    `json:"low_priority_depth"`
+	ItemsEnqueuedTotal  int64  `json:"items_enqueued_total"`
+	ItemsProcessedTotal int64  `json:"items_processed_total"`
+	ItemsFailedTotal    int64  `json:"items_failed_total"`
+	Workers             int    `json:"workers"`
+	ActiveWorkers       int    `json:"active_workers"`
+	OldestItemAge       string `json:"oldest_item_age"`
+	Paused              bool   `json:"paused"`
+	// DelayedDepth is the number of queued items not yet due for delivery
+	DelayedDepth int `json:"delayed_depth"`
+}
+
+// QueueClearResponse is the JSON response for /queue/clear.
+type QueueClearResponse struct {
+	Cleared    int `json:"cleared"`
+	QueueDepth int `json:"queue_depth"`
+}
+
+// QueueItem is a queued work item, as returned by GET /queue/items and
+// GET /queue/items/{id}.
+type QueueItem struct {
+	ID       string `json:"id"`
+	Priority string `json:"priority"`
+	// Position is the item's place in dequeue order, counting from zero
+	Position       int       `json:"position"`
+	ProcessingTime string    `json:"processing_time"`
+	PayloadSize    int64     `json:"payload_size,omitempty"`
+	EnqueuedAt     time.Time `json:"enqueued_at"`
+	NotBefore      time.Time `json:"not_before,omitzero"`
+	// Wait is how long the item has been queued
+	Wait string `json:"wait"`
+}
+
+// QueueItemsResponse is the JSON response for GET /queue/items.
+type QueueItemsResponse struct {
+	// Total is the number of queued items matching the filter
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+	Items  []QueueItem `json:"items"`
+	// NextOffset is the offset of the next page, if there is one
+	NextOffset int `json:"next_offset,omitempty"`
+}
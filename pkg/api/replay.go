@@ -0,0 +1,19 @@
+package api
+
+import "time"
+
+// ReplayStatus describes the current or most recent replay.
+type ReplayStatus struct {
+	Running    bool          `json:"running"`
+	Total      int           `json:"total"`
+	Sent       int64         `json:"sent"`
+	Skipped    int64         `json:"skipped"`
+	Errors     int64         `json:"errors"`
+	Statuses   map[int]int64 `json:"statuses,omitempty"`
+	Speed      float64       `json:"speed"`
+	Loop       bool          `json:"loop,omitempty"`
+	Iteration  int           `json:"iteration,omitempty"`
+	StartedAt  *time.Time    `json:"started_at,omitempty"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	Duration   string        `json:"duration,omitempty"`
+}
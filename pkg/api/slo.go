@@ -0,0 +1,24 @@
+package api
+
+// SLOStatus is the JSON response for /admin/slo.
+type SLOStatus struct {
+	// Period is the error budget period
+	Period string      `json:"period"`
+	SLIs   []SLIStatus `json:"slis"`
+}
+
+// SLIStatus is one SLI measured over the SLO period.
+type SLIStatus struct {
+	// Name is availability or latency
+	Name      string  `json:"name"`
+	Objective float64 `json:"objective"`
+	// Threshold is the latency a response must beat to be good
+	Threshold string `json:"threshold,omitempty"`
+	Total     int64  `json:"total"`
+	Bad       int64  `json:"bad"`
+	// ErrorBudgetRemaining is negative once the objective is missed
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"`
+	// BurnRates maps each window to its burn rate, where 1 spends the
+	// error budget exactly over the period
+	BurnRates map[string]float64 `json:"burn_rates"`
+}
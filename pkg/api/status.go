@@ -0,0 +1,317 @@
+package api
+
+import "time"
+
+// HealthResponse is the JSON response for health endpoints.
+type HealthResponse struct {
+	// Status is "ok", "not_ready", or "starting"
+	Status string `json:"status"`
+	// Reason explains why the server is not ready (omitted when ok)
+	Reason string `json:"reason,omitempty"`
+	// Remaining is the time until startup completes (only for /startupz)
+	Remaining string `json:"remaining,omitempty"`
+	// Time is the server's wall clock, including any simulated skew
+	Time string `json:"time"`
+	// Checks are the synthetic dependency checks (only for /readyz)
+	Checks []DependencyCheck `json:"checks,omitempty"`
+}
+
+// DependencyCheck is a synthetic readiness dependency, such as a database or
+// cache connection, that /readyz reports on.
+type DependencyCheck struct {
+	Name string `json:"name"`
+	// Failing makes /readyz report not ready while set
+	Failing bool `json:"failing"`
+	// Message explains the failure, e.g. "connection refused"
+	Message string `json:"message,omitempty"`
+}
+
+// HealthDelay is the response delay injected into a health probe endpoint.
+type HealthDelay struct {
+	// Probe is healthz, readyz, or startupz
+	Probe string `json:"probe"`
+	Delay string `json:"delay"`
+	// Jitter adds a random delay of up to this much
+	Jitter string `json:"jitter,omitempty"`
+}
+
+// HealthDelaysResponse is the JSON response for /admin/health-delay.
+type HealthDelaysResponse struct {
+	Delays []HealthDelay `json:"delays"`
+}
+
+// DependenciesResponse is the JSON response for /admin/dependencies.
+type DependenciesResponse struct {
+	Count  int               `json:"count"`
+	Checks []DependencyCheck `json:"checks"`
+}
+
+// PreStopResponse is the JSON response for GET /prestop.
+type PreStopResponse struct {
+	Delay          string `json:"delay"`
+	Waited         string `json:"waited"`
+	Interrupted    bool   `json:"interrupted,omitempty"`
+	InFlightBefore int64  `json:"in_flight_before"`
+	InFlightAfter  int64  `json:"in_flight_after"`
+	Ready          bool   `json:"ready"`
+}
+
+// InfoResponse is the JSON response for /info.
+type InfoResponse struct {
+	Version string `json:"version"`
+	// APIVersion is the JSON contract version, see Version
+	APIVersion string `json:"api_version"`
+	// Variant is the deployment variant, e.g. stable or canary
+	Variant string `json:"variant,omitempty"`
+	Uptime  string `json:"uptime"`
+	// Time is the server's wall clock, including any simulated skew
+	Time      string        `json:"time"`
+	ClockSkew string        `json:"clock_skew,omitempty"`
+	Lifecycle InfoLifecycle `json:"lifecycle"`
+	Resources InfoResources `json:"resources"`
+	Config    InfoConfig    `json:"config"`
+	// Build identifies the binary, so results can be traced to the exact
+	// build that produced them
+	Build InfoBuild `json:"build"`
+	// Features are the optional features the configuration turns on, e.g.
+	// tls, chaos, or read-only
+	Features []string `json:"features"`
+}
+
+// InfoBuild describes how the running binary was built.
+type InfoBuild struct {
+	// Commit is the VCS revision the binary was built from
+	Commit string `json:"commit,omitempty"`
+	// CommitTime is when Commit was made, in RFC 3339
+	CommitTime string `json:"commit_time,omitempty"`
+	// Modified reports that the working tree had uncommitted changes
+	Modified bool `json:"modified,omitempty"`
+	// Date is when the binary was built, in RFC 3339, if stamped at build time
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+	// Module is the main module's path
+	Module string `json:"module,omitempty"`
+	// Dependencies are the modules linked into the binary
+	Dependencies []InfoDependency `json:"dependencies,omitempty"`
+}
+
+// InfoDependency is a module linked into the binary.
+type InfoDependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Replace is the module that replaced Path, as path@version, if any
+	Replace string `json:"replace,omitempty"`
+}
+
+// InfoLifecycle contains lifecycle state information.
+type InfoLifecycle struct {
+	State            string `json:"state"`
+	StartedAt        string `json:"started_at"`
+	ReadyAt          string `json:"ready_at,omitempty"`
+	StartupComplete  bool   `json:"startup_complete"`
+	ShuttingDown     bool   `json:"shutting_down"`
+	InFlightRequests int64  `json:"in_flight_requests"`
+}
+
+// InfoResources contains runtime resource information.
+type InfoResources struct {
+	CPUCores    int    `json:"cpu_cores"`
+	MemoryTotal uint64 `json:"memory_total"`
+	MemoryUsed  uint64 `json:"memory_used"`
+	Goroutines  int    `json:"goroutines"`
+	// OOMScoreAdj is the process's oom_score_adj, which the kubelet sets
+	// from the pod's QoS class
+	OOMScoreAdj *int `json:"oom_score_adj,omitempty"`
+	// MemoryHigh is the cgroup memory.high throttling threshold in bytes
+	MemoryHigh int64 `json:"memory_high,omitempty"`
+	// MemoryEvents are the cgroup v2 memory.events counters
+	MemoryEvents *MemoryEvents `json:"memory_events,omitempty"`
+}
+
+// MemoryEvents are the container's cgroup memory event counts.
+type MemoryEvents struct {
+	Low     uint64 `json:"low"`
+	High    uint64 `json:"high"`
+	Max     uint64 `json:"max"`
+	OOM     uint64 `json:"oom"`
+	OOMKill uint64 `json:"oom_kill"`
+}
+
+// InfoConfig contains configuration information.
+type InfoConfig struct {
+	Port             int    `json:"port"`
+	MgmtPort         int    `json:"mgmt_port,omitempty"`
+	LogLevel         string `json:"log_level"`
+	MaxCPUDuration   string `json:"max_cpu_duration"`
+	MaxMemorySize    string `json:"max_memory_size"`
+	MaxIOSize        string `json:"max_io_size"`
+	IOPath           string `json:"io_path"`
+	MaxConcurrentOps int    `json:"max_concurrent_ops"`
+	RequestTimeout   string `json:"request_timeout"`
+	// RequestTimeouts are the per-endpoint-group overrides of RequestTimeout
+	RequestTimeouts  map[string]string `json:"request_timeouts,omitempty"`
+	StartupDelay     string            `json:"startup_delay"`
+	StartupJitter    string            `json:"startup_jitter"`
+	ShutdownDelay    string            `json:"shutdown_delay"`
+	ShutdownTimeout  string            `json:"shutdown_timeout"`
+	DrainImmediately bool              `json:"drain_immediately"`
+	SigtermBehavior  string            `json:"sigterm_behavior"`
+}
+
+// ShutdownPhase names a stage of graceful shutdown.
+type ShutdownPhase string
+
+// ShutdownPhaseStats describes one shutdown phase.
+type ShutdownPhaseStats struct {
+	Phase              ShutdownPhase `json:"phase"`
+	StartedAt          time.Time     `json:"started_at"`
+	EndedAt            *time.Time    `json:"ended_at,omitempty"`
+	Duration           string        `json:"duration"`
+	InFlightAtStart    int64         `json:"in_flight_at_start"`
+	ConnectionsAtStart int64         `json:"connections_at_start"`
+	RequestsCompleted  int64         `json:"requests_completed"`
+	InFlightAtEnd      *int64        `json:"in_flight_at_end,omitempty"`
+	ConnectionsAtEnd   *int64        `json:"connections_at_end,omitempty"`
+}
+
+// ConnectionStats counts client connections by state.
+type ConnectionStats struct {
+	Open   int64 `json:"open"`
+	Active int64 `json:"active"`
+	Idle   int64 `json:"idle"`
+}
+
+// DrainStatus is a snapshot of connection draining progress.
+type DrainStatus struct {
+	State           string               `json:"state"`
+	Phase           ShutdownPhase        `json:"phase,omitempty"`
+	InFlight        int64                `json:"in_flight"`
+	Connections     ConnectionStats      `json:"connections"`
+	ShutdownStarted *time.Time           `json:"shutdown_started,omitempty"`
+	Phases          []ShutdownPhaseStats `json:"phases"`
+}
+
+// EchoResponse is the JSON response for /echo, describing the request as the
+// server received it.
+type EchoResponse struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Host   string `json:"host"`
+	Proto  string `json:"proto"`
+	// RemoteAddr is the client address; with PROXY protocol, the original
+	// client's rather than the load balancer's
+	RemoteAddr string              `json:"remote_addr"`
+	Headers    map[string][]string `json:"headers"`
+	// Proxy is set when the connection sent a PROXY protocol header
+	Proxy *EchoProxy `json:"proxy,omitempty"`
+	// Topology is where the responding pod runs
+	Topology *Topology `json:"topology,omitempty"`
+}
+
+// EchoProxy describes a connection's PROXY protocol header.
+type EchoProxy struct {
+	Version int `json:"version"`
+	// Addr is the peer that sent the header, typically the load balancer
+	Addr string `json:"addr"`
+	// Source and Destination are omitted for LOCAL and UNKNOWN headers
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+}
+
+// Topology describes where the responding pod runs, so clients can tally
+// response distributions by pod, node, and zone.
+type Topology struct {
+	Pod string `json:"pod"`
+	// Ordinal is the StatefulSet pod index, if known
+	Ordinal *int   `json:"ordinal,omitempty"`
+	Node    string `json:"node,omitempty"`
+	Zone    string `json:"zone,omitempty"`
+	Region  string `json:"region,omitempty"`
+}
+
+// SessionResponse is the JSON response for /session.
+type SessionResponse struct {
+	Session string `json:"session"`
+	// Pod is the pod that served this request
+	Pod string `json:"pod"`
+	// IssuedBy is the pod that issued the session
+	IssuedBy string `json:"issued_by"`
+	// New is set when this request was issued a new session
+	New bool `json:"new,omitempty"`
+	// SamePod reports whether the request landed on the issuing pod
+	SamePod bool `json:"same_pod"`
+	// Source is where the session token was read from: header or cookie
+	Source string `json:"source,omitempty"`
+}
+
+// MirrorResponse is the JSON response for /mirror/source and /mirror/sink.
+type MirrorResponse struct {
+	// Endpoint is source or sink
+	Endpoint string `json:"endpoint"`
+	// Mirrored reports whether this request was a mirrored copy
+	Mirrored bool `json:"mirrored"`
+	// Host is the request's host, which Envoy suffixes with -shadow on
+	// mirrored copies
+	Host string `json:"host"`
+	// Source and Sink are this instance's counts for each endpoint,
+	// including this request
+	Source MirrorCounts `json:"source"`
+	Sink   MirrorCounts `json:"sink"`
+}
+
+// MirrorCounts counts the requests to a mirror endpoint.
+type MirrorCounts struct {
+	Total int64 `json:"total"`
+	// Mirrored is the number of requests that were mirrored copies
+	Mirrored int64 `json:"mirrored"`
+}
+
+// ProtectedResponse is the JSON response for /protected.
+type ProtectedResponse struct {
+	Authorized bool `json:"authorized"`
+	// User is the basic auth user, if basic auth is required
+	User string `json:"user,omitempty"`
+	// Checks are the requirements met: basic and header
+	Checks []string `json:"checks"`
+}
+
+// BurstResponse is the JSON response for /burst.
+type BurstResponse struct {
+	// Seq is this request's position among the arrivals since the last reset
+	Seq int64 `json:"seq"`
+	// InterArrival is the time since the previous arrival, if any
+	InterArrival string `json:"inter_arrival,omitempty"`
+}
+
+// BurstStats is the JSON response for /burst/stats.
+type BurstStats struct {
+	// Window is how far back the stats reach
+	Window string `json:"window"`
+	// Count is the number of arrivals within the window
+	Count int `json:"count"`
+	// Total is the number of arrivals since the last reset
+	Total int64 `json:"total"`
+	// Rate is the arrivals per second between the first and last arrival
+	// within the window
+	Rate float64 `json:"rate"`
+	// PeakPerSecond is the most arrivals within any one second
+	PeakPerSecond int `json:"peak_per_second"`
+	// CV is the coefficient of variation of the inter-arrival times: about
+	// 0 for evenly paced arrivals, 1 for Poisson arrivals, and above 1 for
+	// bursty arrivals
+	CV float64 `json:"cv"`
+	// InterArrival summarizes the inter-arrival times, given two or more
+	// arrivals
+	InterArrival *BurstInterArrival `json:"inter_arrival,omitempty"`
+}
+
+// BurstInterArrival summarizes inter-arrival times.
+type BurstInterArrival struct {
+	Min  string `json:"min"`
+	Mean string `json:"mean"`
+	P50  string `json:"p50"`
+	P90  string `json:"p90"`
+	P99  string `json:"p99"`
+	Max  string `json:"max"`
+}
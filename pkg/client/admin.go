@@ -0,0 +1,474 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Admin endpoints require the read role for GET and the mutate role
+// otherwise when admin auth is configured.
+
+// SetReady calls POST /admin/ready to force readiness. A nil ready toggles
+// the override: it clears an existing override or forces not-ready.
+func (c *Client) SetReady(ctx context.Context, ready *bool) (*api.AdminReadyResponse, error) {
+	q := query{}
+	if ready != nil {
+		q.str("state", strconv.FormatBool(*ready))
+	}
+	return call[api.AdminReadyResponse](ctx, c, http.MethodPost, "/admin/ready", q)
+}
+
+// GC calls POST /admin/gc to force a garbage collection.
+func (c *Client) GC(ctx context.Context) (*api.AdminGCResponse, error) {
+	return call[api.AdminGCResponse](ctx, c, http.MethodPost, "/admin/gc", nil)
+}
+
+// Config calls GET /admin/config.
+func (c *Client) Config(ctx context.Context) (*api.AdminConfigResponse, error) {
+	return call[api.AdminConfigResponse](ctx, c, http.MethodGet, "/admin/config", nil)
+}
+
+// Reset calls POST /admin/reset, clearing injected faults and overrides.
+func (c *Client) Reset(ctx context.Context) (*api.AdminResetResponse, error) {
+	return call[api.AdminResetResponse](ctx, c, http.MethodPost, "/admin/reset", nil)
+}
+
+// ErrorRateOptions are the parameters for POST /admin/error-rate.
+type ErrorRateOptions struct {
+	// Endpoint limits the rule to one endpoint (empty is global)
+	Endpoint string
+	// Rate is the error probability from 0 to 1; it is always sent, and 0
+	// disables the rule
+	Rate float64
+	// Codes are the status codes to choose from (default 500)
+	Codes []int
+	// BodyFormat selects the error body, e.g. json, problem, or html
+	BodyFormat string
+	Delay      time.Duration
+	// DelayDistribution samples the delay around Delay
+	DelayDistribution *api.LatencyDistribution
+	// Duration is how long the rule lasts (zero is forever)
+	Duration time.Duration
+}
+
+// SetErrorRate calls POST /admin/error-rate.
+func (c *Client) SetErrorRate(ctx context.Context, opts ErrorRateOptions) (*api.AdminErrorRateResponse, error) {
+	q := query{}.str("endpoint", opts.Endpoint).str("body_format", opts.BodyFormat).
+		dur("delay", opts.Delay).dist(opts.DelayDistribution).dur("duration", opts.Duration)
+	q.str("rate", strconv.FormatFloat(opts.Rate, 'g', -1, 64))
+	if len(opts.Codes) > 0 {
+		codes := make([]string, len(opts.Codes))
+		for i, code := range opts.Codes {
+			codes[i] = strconv.Itoa(code)
+		}
+		q.str("codes", strings.Join(codes, ","))
+	}
+	return call[api.AdminErrorRateResponse](ctx, c, http.MethodPost, "/admin/error-rate", q)
+}
+
+// Faults calls GET /admin/faults.
+func (c *Client) Faults(ctx context.Context) (*api.AdminFaultsResponse, error) {
+	return call[api.AdminFaultsResponse](ctx, c, http.MethodGet, "/admin/faults", nil)
+}
+
+// SetFaults calls POST /admin/faults to apply several error rules at once.
+func (c *Client) SetFaults(ctx context.Context, req api.FaultRulesRequest) (*api.AdminFaultsResponse, error) {
+	return callJSON[api.AdminFaultsResponse](ctx, c, http.MethodPost, "/admin/faults", req)
+}
+
+// ClearFaults calls DELETE /admin/faults.
+func (c *Client) ClearFaults(ctx context.Context) (*api.AdminFaultsResponse, error) {
+	return call[api.AdminFaultsResponse](ctx, c, http.MethodDelete, "/admin/faults", nil)
+}
+
+// HeaderFaults calls GET /admin/header-faults.
+func (c *Client) HeaderFaults(ctx context.Context) (*api.AdminHeaderFaultsResponse, error) {
+	return call[api.AdminHeaderFaultsResponse](ctx, c, http.MethodGet, "/admin/header-faults", nil)
+}
+
+// SetHeaderFault calls POST /admin/header-faults.
+func (c *Client) SetHeaderFault(ctx context.Context, rule api.HeaderFaultRule) (*api.AdminHeaderFaultsResponse, error) {
+	return callJSON[api.AdminHeaderFaultsResponse](ctx, c, http.MethodPost, "/admin/header-faults", rule)
+}
+
+// ClearHeaderFaults calls DELETE /admin/header-faults, removing the rule for
+// endpoint, or every rule if endpoint is nil.
+func (c *Client) ClearHeaderFaults(ctx context.Context, endpoint *string) (*api.AdminHeaderFaultsResponse, error) {
+	q := query{}
+	if endpoint != nil {
+		q["endpoint"] = []string{*endpoint}
+	}
+	return call[api.AdminHeaderFaultsResponse](ctx, c, http.MethodDelete, "/admin/header-faults", q)
+}
+
+// PauseQueue calls POST /admin/queue/pause.
+func (c *Client) PauseQueue(ctx context.Context) (*api.AdminQueuePauseResponse, error) {
+	return call[api.AdminQueuePauseResponse](ctx, c, http.MethodPost, "/admin/queue/pause", nil)
+}
+
+// ResumeQueue calls POST /admin/queue/resume.
+func (c *Client) ResumeQueue(ctx context.Context) (*api.AdminQueueResumeResponse, error) {
+	return call[api.AdminQueueResumeResponse](ctx, c, http.MethodPost, "/admin/queue/resume", nil)
+}
+
+// QueueFault calls GET /admin/queue/fault.
+func (c *Client) QueueFault(ctx context.Context) (*api.AdminQueueFaultResponse, error) {
+	return call[api.AdminQueueFaultResponse](ctx, c, http.MethodGet, "/admin/queue/fault", nil)
+}
+
+// SetQueueFault calls POST /admin/queue/fault.
+func (c *Client) SetQueueFault(ctx context.Context, fault api.QueueFault) (*api.AdminQueueFaultResponse, error) {
+	return callJSON[api.AdminQueueFaultResponse](ctx, c, http.MethodPost, "/admin/queue/fault", fault)
+}
+
+// ClearQueueFault calls DELETE /admin/queue/fault.
+func (c *Client) ClearQueueFault(ctx context.Context) (*api.AdminQueueFaultResponse, error) {
+	return call[api.AdminQueueFaultResponse](ctx, c, http.MethodDelete, "/admin/queue/fault", nil)
+}
+
+// Audit calls GET /admin/audit, returning entries after afterID, capped to
+// the most recent limit when limit is positive.
+func (c *Client) Audit(ctx context.Context, afterID uint64, limit int) (*api.AdminAuditResponse, error) {
+	q := query{}.size("after_id", int64(afterID)).int("limit", limit)
+	return call[api.AdminAuditResponse](ctx, c, http.MethodGet, "/admin/audit", q)
+}
+
+// DrainStatus calls GET /admin/drain-status.
+func (c *Client) DrainStatus(ctx context.Context) (*api.DrainStatus, error) {
+	return call[api.DrainStatus](ctx, c, http.MethodGet, "/admin/drain-status", nil)
+}
+
+// LogLevel calls GET /admin/loglevel.
+func (c *Client) LogLevel(ctx context.Context) (*api.AdminLogLevelResponse, error) {
+	return call[api.AdminLogLevelResponse](ctx, c, http.MethodGet, "/admin/loglevel", nil)
+}
+
+// SetLogLevel calls POST /admin/loglevel.
+func (c *Client) SetLogLevel(ctx context.Context, level string) (*api.AdminLogLevelResponse, error) {
+	return call[api.AdminLogLevelResponse](ctx, c, http.MethodPost, "/admin/loglevel", query{}.str("level", level))
+}
+
+// ExitCode calls GET /admin/exit-code.
+func (c *Client) ExitCode(ctx context.Context) (*api.AdminExitCodeResponse, error) {
+	return call[api.AdminExitCodeResponse](ctx, c, http.MethodGet, "/admin/exit-code", nil)
+}
+
+// SetExitCode calls POST /admin/exit-code, setting the status used after
+// graceful shutdown.
+func (c *Client) SetExitCode(ctx context.Context, code int) (*api.AdminExitCodeResponse, error) {
+	q := query{}.str("code", strconv.Itoa(code))
+	return call[api.AdminExitCodeResponse](ctx, c, http.MethodPost, "/admin/exit-code", q)
+}
+
+// ClearExitCode calls DELETE /admin/exit-code.
+func (c *Client) ClearExitCode(ctx context.Context) (*api.AdminExitCodeResponse, error) {
+	return call[api.AdminExitCodeResponse](ctx, c, http.MethodDelete, "/admin/exit-code", nil)
+}
+
+// Clock calls GET /admin/clock.
+func (c *Client) Clock(ctx context.Context) (*api.AdminClockResponse, error) {
+	return call[api.AdminClockResponse](ctx, c, http.MethodGet, "/admin/clock", nil)
+}
+
+// SetClockSkew calls POST /admin/clock to skew the wall clock hotpod reports.
+func (c *Client) SetClockSkew(ctx context.Context, skew time.Duration) (*api.AdminClockResponse, error) {
+	return call[api.AdminClockResponse](ctx, c, http.MethodPost, "/admin/clock", query{}.str("skew", skew.String()))
+}
+
+// ClearClockSkew calls DELETE /admin/clock.
+func (c *Client) ClearClockSkew(ctx context.Context) (*api.AdminClockResponse, error) {
+	return call[api.AdminClockResponse](ctx, c, http.MethodDelete, "/admin/clock", nil)
+}
+
+// Sidecar calls GET /admin/sidecar.
+func (c *Client) Sidecar(ctx context.Context) (*api.AdminSidecarResponse, error) {
+	return call[api.AdminSidecarResponse](ctx, c, http.MethodGet, "/admin/sidecar", nil)
+}
+
+// SidecarOptions are settings for SetSidecar, in the server's notation. Empty
+// fields are left unchanged; use "0" to clear one.
+type SidecarOptions struct {
+	// CPUBaseline and CPUJitter use Kubernetes CPU notation, e.g. 200m
+	CPUBaseline string
+	CPUJitter   string
+	// MemoryBaseline, MemoryJitter, and MemoryPeak use size notation, e.g. 100Mi
+	MemoryBaseline string
+	MemoryJitter   string
+	MemoryPeak     string
+	// MemoryRamp is a duration, e.g. 10m
+	MemoryRamp string
+}
+
+// SetSidecar calls POST /admin/sidecar to reconfigure the sidecar runner.
+func (c *Client) SetSidecar(ctx context.Context, opts SidecarOptions) (*api.AdminSidecarResponse, error) {
+	q := query{}.str("cpu_baseline", opts.CPUBaseline).str("cpu_jitter", opts.CPUJitter).
+		str("memory_baseline", opts.MemoryBaseline).str("memory_jitter", opts.MemoryJitter).
+		str("memory_peak", opts.MemoryPeak).str("memory_ramp", opts.MemoryRamp)
+	return call[api.AdminSidecarResponse](ctx, c, http.MethodPost, "/admin/sidecar", q)
+}
+
+// CustomMetrics calls GET /custom-metrics.
+func (c *Client) CustomMetrics(ctx context.Context) (*api.CustomMetricsResponse, error) {
+	return call[api.CustomMetricsResponse](ctx, c, http.MethodGet, "/custom-metrics", nil)
+}
+
+// SetSyntheticUtilization calls POST /admin/custom-metrics to set the
+// synthetic utilization served through the custom metrics API.
+func (c *Client) SetSyntheticUtilization(ctx context.Context, v float64) (*api.CustomMetricsResponse, error) {
+	q := query{}.str("synthetic_utilization", strconv.FormatFloat(v, 'g', -1, 64))
+	return call[api.CustomMetricsResponse](ctx, c, http.MethodPost, "/admin/custom-metrics", q)
+}
+
+// WorkProfiles calls GET /admin/profiles.
+func (c *Client) WorkProfiles(ctx context.Context) (*api.WorkProfilesResponse, error) {
+	return call[api.WorkProfilesResponse](ctx, c, http.MethodGet, "/admin/profiles", nil)
+}
+
+// DefineWorkProfile calls POST /admin/profiles to add or replace a /work
+// profile.
+func (c *Client) DefineWorkProfile(ctx context.Context, profile api.WorkProfile) (*api.WorkProfilesResponse, error) {
+	return callJSON[api.WorkProfilesResponse](ctx, c, http.MethodPost, "/admin/profiles", profile)
+}
+
+// DeleteWorkProfile calls DELETE /admin/profiles/{name}.
+func (c *Client) DeleteWorkProfile(ctx context.Context, name string) (*api.WorkProfilesResponse, error) {
+	return call[api.WorkProfilesResponse](ctx, c, http.MethodDelete, "/admin/profiles/"+url.PathEscape(name), nil)
+}
+
+// Dependencies calls GET /admin/dependencies.
+func (c *Client) Dependencies(ctx context.Context) (*api.DependenciesResponse, error) {
+	return call[api.DependenciesResponse](ctx, c, http.MethodGet, "/admin/dependencies", nil)
+}
+
+// SetDependency calls POST /admin/dependencies to add a dependency check or
+// toggle its failure.
+func (c *Client) SetDependency(ctx context.Context, check api.DependencyCheck) (*api.DependenciesResponse, error) {
+	return callJSON[api.DependenciesResponse](ctx, c, http.MethodPost, "/admin/dependencies", check)
+}
+
+// DeleteDependency calls DELETE /admin/dependencies/{name}.
+func (c *Client) DeleteDependency(ctx context.Context, name string) (*api.DependenciesResponse, error) {
+	return call[api.DependenciesResponse](ctx, c, http.MethodDelete, "/admin/dependencies/"+url.PathEscape(name), nil)
+}
+
+// HealthDelays calls GET /admin/health-delay.
+func (c *Client) HealthDelays(ctx context.Context) (*api.HealthDelaysResponse, error) {
+	return call[api.HealthDelaysResponse](ctx, c, http.MethodGet, "/admin/health-delay", nil)
+}
+
+// SetHealthDelay calls POST /admin/health-delay. An empty probe applies the
+// delay to /healthz, /readyz, and /startupz.
+func (c *Client) SetHealthDelay(ctx context.Context, probe string, delay, jitter time.Duration) (*api.HealthDelaysResponse, error) {
+	q := query{}.str("probe", probe).dur("delay", delay).dur("jitter", jitter)
+	return call[api.HealthDelaysResponse](ctx, c, http.MethodPost, "/admin/health-delay", q)
+}
+
+// ClearHealthDelays calls DELETE /admin/health-delay.
+func (c *Client) ClearHealthDelays(ctx context.Context) (*api.HealthDelaysResponse, error) {
+	return call[api.HealthDelaysResponse](ctx, c, http.MethodDelete, "/admin/health-delay", nil)
+}
+
+// Endpoints calls GET /admin/endpoints.
+func (c *Client) Endpoints(ctx context.Context) (*api.AdminEndpointsResponse, error) {
+	return call[api.AdminEndpointsResponse](ctx, c, http.MethodGet, "/admin/endpoints", nil)
+}
+
+// SetEndpoints calls POST /admin/endpoints, enabling and disabling the named
+// endpoint groups and leaving the rest as they are.
+func (c *Client) SetEndpoints(ctx context.Context, enable, disable []string) (*api.AdminEndpointsResponse, error) {
+	q := query{}.str("enable", strings.Join(enable, ",")).str("disable", strings.Join(disable, ","))
+	return call[api.AdminEndpointsResponse](ctx, c, http.MethodPost, "/admin/endpoints", q)
+}
+
+// Selftest calls GET /admin/selftest. A failed check is reported in the
+// response, not as an error.
+func (c *Client) Selftest(ctx context.Context) (*api.AdminSelftestResponse, error) {
+	return call[api.AdminSelftestResponse](ctx, c, http.MethodGet, "/admin/selftest", nil)
+}
+
+// ScrapeCost calls GET /admin/metrics-scrape.
+func (c *Client) ScrapeCost(ctx context.Context) (*api.AdminScrapeCostResponse, error) {
+	return call[api.AdminScrapeCostResponse](ctx, c, http.MethodGet, "/admin/metrics-scrape", nil)
+}
+
+// SetScrapeCost calls POST /admin/metrics-scrape, delaying each /metrics
+// response and inflating it by about padding bytes.
+func (c *Client) SetScrapeCost(ctx context.Context, delay, jitter time.Duration, padding int64) (*api.AdminScrapeCostResponse, error) {
+	q := query{}.dur("delay", delay).dur("jitter", jitter).size("padding", padding)
+	return call[api.AdminScrapeCostResponse](ctx, c, http.MethodPost, "/admin/metrics-scrape", q)
+}
+
+// ClearScrapeCost calls DELETE /admin/metrics-scrape.
+func (c *Client) ClearScrapeCost(ctx context.Context) (*api.AdminScrapeCostResponse, error) {
+	return call[api.AdminScrapeCostResponse](ctx, c, http.MethodDelete, "/admin/metrics-scrape", nil)
+}
+
+// TLSFaults calls GET /admin/tls-faults.
+func (c *Client) TLSFaults(ctx context.Context) (*api.AdminTLSFaultsResponse, error) {
+	return call[api.AdminTLSFaultsResponse](ctx, c, http.MethodGet, "/admin/tls-faults", nil)
+}
+
+// TLSFaultOptions are the parameters for POST /admin/tls-faults.
+type TLSFaultOptions struct {
+	Delay     time.Duration
+	DelayRate float64
+	FailRate  float64
+	// Cert is "expired" or "wrong-san" to present a bad certificate
+	Cert string
+}
+
+// SetTLSFaults calls POST /admin/tls-faults, replacing the handshake faults.
+func (c *Client) SetTLSFaults(ctx context.Context, opts TLSFaultOptions) (*api.AdminTLSFaultsResponse, error) {
+	q := query{}.dur("delay", opts.Delay).float("delay_rate", opts.DelayRate).float("fail_rate", opts.FailRate).str("cert", opts.Cert)
+	return call[api.AdminTLSFaultsResponse](ctx, c, http.MethodPost, "/admin/tls-faults", q)
+}
+
+// ClearTLSFaults calls DELETE /admin/tls-faults.
+func (c *Client) ClearTLSFaults(ctx context.Context) (*api.AdminTLSFaultsResponse, error) {
+	return call[api.AdminTLSFaultsResponse](ctx, c, http.MethodDelete, "/admin/tls-faults", nil)
+}
+
+// ChaosSchedule calls GET /admin/chaos/schedule.
+func (c *Client) ChaosSchedule(ctx context.Context) (*api.AdminChaosScheduleResponse, error) {
+	return call[api.AdminChaosScheduleResponse](ctx, c, http.MethodGet, "/admin/chaos/schedule", nil)
+}
+
+// SetChaosSchedule calls POST /admin/chaos/schedule, replacing the schedule.
+func (c *Client) SetChaosSchedule(ctx context.Context, schedule api.ChaosSchedule) (*api.AdminChaosScheduleResponse, error) {
+	return callJSON[api.AdminChaosScheduleResponse](ctx, c, http.MethodPost, "/admin/chaos/schedule", schedule)
+}
+
+// ClearChaosSchedule calls DELETE /admin/chaos/schedule.
+func (c *Client) ClearChaosSchedule(ctx context.Context) (*api.AdminChaosScheduleResponse, error) {
+	return call[api.AdminChaosScheduleResponse](ctx, c, http.MethodDelete, "/admin/chaos/schedule", nil)
+}
+
+// BrownoutOptions are the parameters for POST /admin/brownout.
+type BrownoutOptions struct {
+	// MaxLatency and MaxErrorRate are reached at the end of the ramp
+	MaxLatency   time.Duration
+	MaxErrorRate float64
+	Codes        []int
+	Ramp         time.Duration
+	// Hold is how long the peak lasts (zero holds until stopped)
+	Hold time.Duration
+}
+
+// Brownout calls GET /admin/brownout.
+func (c *Client) Brownout(ctx context.Context) (*api.BrownoutStatus, error) {
+	return call[api.BrownoutStatus](ctx, c, http.MethodGet, "/admin/brownout", nil)
+}
+
+// StartBrownout calls POST /admin/brownout, ramping latency and error rate
+// up to their maximums.
+func (c *Client) StartBrownout(ctx context.Context, opts BrownoutOptions) (*api.BrownoutStatus, error) {
+	codes := make([]string, len(opts.Codes))
+	for i, code := range opts.Codes {
+		codes[i] = strconv.Itoa(code)
+	}
+	q := query{}.dur("max_latency", opts.MaxLatency).float("max_error_rate", opts.MaxErrorRate).
+		str("codes", strings.Join(codes, ",")).dur("ramp", opts.Ramp).dur("hold", opts.Hold)
+	return call[api.BrownoutStatus](ctx, c, http.MethodPost, "/admin/brownout", q)
+}
+
+// StopBrownout calls DELETE /admin/brownout.
+func (c *Client) StopBrownout(ctx context.Context) (*api.BrownoutStatus, error) {
+	return call[api.BrownoutStatus](ctx, c, http.MethodDelete, "/admin/brownout", nil)
+}
+
+// SLO calls GET /admin/slo.
+func (c *Client) SLO(ctx context.Context) (*api.SLOStatus, error) {
+	return call[api.SLOStatus](ctx, c, http.MethodGet, "/admin/slo", nil)
+}
+
+// Probes calls GET /admin/probes.
+func (c *Client) Probes(ctx context.Context) (*api.ProbesResponse, error) {
+	return call[api.ProbesResponse](ctx, c, http.MethodGet, "/admin/probes", nil)
+}
+
+// Peers calls GET /admin/peers.
+func (c *Client) Peers(ctx context.Context) (*api.FleetPeersResponse, error) {
+	return call[api.FleetPeersResponse](ctx, c, http.MethodGet, "/admin/peers", nil)
+}
+
+// Broadcast calls POST /admin/broadcast to send cmd to every peer.
+func (c *Client) Broadcast(ctx context.Context, cmd api.FleetCommand) (*api.FleetBroadcastResponse, error) {
+	return callJSON[api.FleetBroadcastResponse](ctx, c, http.MethodPost, "/admin/broadcast", cmd)
+}
+
+// ReplayOptions are the parameters for POST /admin/replay.
+type ReplayOptions struct {
+	// Format is json or csv (default json)
+	Format string
+	// Speed multiplies the recorded request rate (default 1)
+	Speed float64
+	// Loop restarts the log when it ends
+	Loop bool
+}
+
+// StartReplay calls POST /admin/replay with the request log read from log.
+func (c *Client) StartReplay(ctx context.Context, log io.Reader, opts ReplayOptions) (*api.ReplayStatus, error) {
+	q := query{}.str("format", opts.Format).float("speed", opts.Speed).bool("loop", opts.Loop)
+	return callBody[api.ReplayStatus](ctx, c, http.MethodPost, "/admin/replay", q, log, "")
+}
+
+// Replay calls GET /admin/replay.
+func (c *Client) Replay(ctx context.Context) (*api.ReplayStatus, error) {
+	return call[api.ReplayStatus](ctx, c, http.MethodGet, "/admin/replay", nil)
+}
+
+// StopReplay calls DELETE /admin/replay.
+func (c *Client) StopReplay(ctx context.Context) (*api.ReplayStatus, error) {
+	return call[api.ReplayStatus](ctx, c, http.MethodDelete, "/admin/replay", nil)
+}
+
+// DumpOptions are the parameters for POST /admin/dump.
+type DumpOptions struct {
+	// Kinds is goroutine, heap, or both (empty captures both)
+	Kinds []string
+	// Inline returns the dumps in the response instead of writing them to
+	// the server's dump directory
+	Inline bool
+}
+
+// Dump calls POST /admin/dump.
+func (c *Client) Dump(ctx context.Context, opts DumpOptions) (*api.AdminDumpResponse, error) {
+	q := query{}.str("kinds", strings.Join(opts.Kinds, ",")).bool("inline", opts.Inline)
+	return call[api.AdminDumpResponse](ctx, c, http.MethodPost, "/admin/dump", q)
+}
+
+// ProfileOptions are the parameters for POST /admin/profile.
+type ProfileOptions struct {
+	// Type is cpu, trace, heap, allocs, goroutine, threadcreate, block, or
+	// mutex (default cpu)
+	Type string
+	// Duration is rounded down to whole seconds
+	Duration time.Duration
+	// Debug selects a text rendering (1 or 2) for snapshot profiles
+	Debug int
+}
+
+// Profile calls POST /admin/profile and returns the captured profile.
+func (c *Client) Profile(ctx context.Context, opts ProfileOptions) ([]byte, error) {
+	q := query{}.str("type", opts.Type).int("seconds", int(opts.Duration/time.Second)).int("debug", opts.Debug)
+	return c.Do(ctx, http.MethodPost, "/admin/profile", url.Values(q), nil, "")
+}
+
+// NodePressure calls GET /admin/node-pressure.
+func (c *Client) NodePressure(ctx context.Context) (*api.NodePressureStatus, error) {
+	return call[api.NodePressureStatus](ctx, c, http.MethodGet, "/admin/node-pressure", nil)
+}
+
+// SetNodePressureBackoff calls POST /admin/node-pressure to turn backing off
+// under node pressure on or off.
+func (c *Client) SetNodePressureBackoff(ctx context.Context, enabled bool) (*api.NodePressureStatus, error) {
+	q := query{}.str("enabled", strconv.FormatBool(enabled))
+	return call[api.NodePressureStatus](ctx, c, http.MethodPost, "/admin/node-pressure", q)
+}
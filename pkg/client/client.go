@@ -0,0 +1,232 @@
+// Package client is a Go client for the hotpod HTTP API, for test suites and
+// tools that drive hotpod programmatically.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Config configures a Client.
+type Config struct {
+	// BaseURL is the hotpod address, e.g. http://hotpod.default.svc:8080
+	BaseURL string
+	// Token is sent as a bearer token for admin and chaos endpoints
+	Token string
+	// HTTPClient is used for requests (default: a client with a 30s timeout)
+	HTTPClient *http.Client
+}
+
+// Client calls a single hotpod instance. It is safe for concurrent use.
+type Client struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+// New creates a client for cfg.BaseURL.
+func New(cfg Config) *Client {
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{
+		base:  strings.TrimSuffix(cfg.BaseURL, "/"),
+		token: cfg.Token,
+		http:  hc,
+	}
+}
+
+// Error is a non-2xx response from hotpod.
+type Error struct {
+	// StatusCode is the HTTP status code
+	StatusCode int
+	// Code is hotpod's machine-readable error code, e.g. INVALID_PARAMETER,
+	// if the response carried one
+	Code string
+	// Message is the error message, or the raw response body
+	Message string
+	// Retryable reports whether hotpod considers the request worth retrying
+	// unchanged
+	Retryable bool
+	// RequestID is the X-Request-ID of the failed request, if reported
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("hotpod returned %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("hotpod returned %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// StatusCode returns the HTTP status code of err if it is an *Error, or 0.
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// timeoutKey is the context key for WithServerTimeout.
+type timeoutKey struct{}
+
+// WithServerTimeout returns a context whose requests ask hotpod to give up
+// after d, via the X-Hotpod-Timeout header. hotpod caps d at its configured
+// request timeout; a request that exceeds it fails with a 503 *Error.
+func WithServerTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// sessionKey is the context key for WithSession.
+type sessionKey struct{}
+
+// WithSession returns a context whose requests carry session token in the
+// X-Hotpod-Session header, as returned by Session, so load balancers that
+// hash on the header route them consistently.
+func WithSession(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, token)
+}
+
+// Do sends a request and returns the raw response body. Non-2xx responses
+// are returned as *Error. It is exported for endpoints without a typed
+// method.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) ([]byte, error) {
+	u := c.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	// Pin the contract version the api types were built for, so a server
+	// that moves its unprefixed paths to a newer version fails loudly.
+	req.Header.Set(api.VersionHeader, api.Version)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok && d > 0 {
+		req.Header.Set("X-Hotpod-Timeout", d.String())
+	}
+	if token, ok := ctx.Value(sessionKey{}).(string); ok && token != "" {
+		req.Header.Set("X-Hotpod-Session", token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body api.ErrorResponse
+		if json.Unmarshal(b, &body) != nil || body.Error == "" {
+			body = api.ErrorResponse{Error: strings.TrimSpace(string(b))}
+		}
+		return nil, &Error{StatusCode: resp.StatusCode, Code: body.Code, Message: body.Error, Retryable: body.Retryable, RequestID: body.RequestID}
+	}
+	return b, nil
+}
+
+// call sends a request and decodes the JSON response into a new T.
+func call[T any](ctx context.Context, c *Client, method, path string, q query) (*T, error) {
+	return callBody[T](ctx, c, method, path, q, nil, "")
+}
+
+// callJSON sends in as a JSON body and decodes the JSON response into a new T.
+func callJSON[T any](ctx context.Context, c *Client, method, path string, in any) (*T, error) {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	return callBody[T](ctx, c, method, path, nil, bytes.NewReader(b), "application/json")
+}
+
+func callBody[T any](ctx context.Context, c *Client, method, path string, q query, body io.Reader, contentType string) (*T, error) {
+	b, err := c.Do(ctx, method, path, url.Values(q), body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	out := new(T)
+	if err := json.Unmarshal(b, out); err != nil {
+		return nil, fmt.Errorf("decoding %s %s response: %w", method, path, err)
+	}
+	return out, nil
+}
+
+// query builds request parameters, omitting zero values so the server
+// applies its defaults.
+type query url.Values
+
+func (q query) str(key, v string) query {
+	if v != "" {
+		url.Values(q).Set(key, v)
+	}
+	return q
+}
+
+func (q query) int(key string, v int) query {
+	if v != 0 {
+		url.Values(q).Set(key, strconv.Itoa(v))
+	}
+	return q
+}
+
+func (q query) float(key string, v float64) query {
+	if v != 0 {
+		url.Values(q).Set(key, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return q
+}
+
+func (q query) dur(key string, v time.Duration) query {
+	if v != 0 {
+		url.Values(q).Set(key, v.String())
+	}
+	return q
+}
+
+func (q query) bool(key string, v bool) query {
+	if v {
+		url.Values(q).Set(key, "true")
+	}
+	return q
+}
+
+// dist sets the distribution query parameters shared by /latency and
+// /admin/error-rate.
+func (q query) dist(d *api.LatencyDistribution) query {
+	if d == nil {
+		return q
+	}
+	return q.str("distribution", d.Kind).str("stddev", d.StdDev).float("sigma", d.Sigma).
+		float("alpha", d.Alpha).str("slow", d.Slow).float("slow_fraction", d.SlowFraction)
+}
+
+func (q query) size(key string, v int64) query {
+	if v != 0 {
+		url.Values(q).Set(key, strconv.FormatInt(v, 10))
+	}
+	return q
+}
@@ -0,0 +1,378 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ripta/hotpod/internal/auth"
+	"github.com/ripta/hotpod/internal/events"
+	"github.com/ripta/hotpod/internal/handlers"
+	"github.com/ripta/hotpod/internal/server"
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+func TestClientRequests(t *testing.T) {
+	exitCode := 0
+	ready := false
+	endpoint := "/cpu"
+
+	tests := []struct {
+		name      string
+		call      func(ctx context.Context, c *Client) error
+		method    string
+		path      string
+		query     string
+		body      string
+		mediaType string
+	}{
+		{
+			name:   "cpu with defaults",
+			call:   func(ctx context.Context, c *Client) error { _, err := c.CPU(ctx, CPUOptions{}); return err },
+			method: "GET", path: "/cpu",
+		},
+		{
+			name: "cpu",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.CPU(ctx, CPUOptions{Duration: 1500 * time.Millisecond, Cores: 2, Intensity: "high"})
+				return err
+			},
+			method: "GET", path: "/cpu", query: "cores=2&duration=1.5s&intensity=high",
+		},
+		{
+			name: "memory",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Memory(ctx, MemoryOptions{Size: 1 << 20, Duration: time.Minute})
+				return err
+			},
+			method: "GET", path: "/memory", query: "duration=1m0s&size=1048576",
+		},
+		{
+			name:   "mirror sink",
+			call:   func(ctx context.Context, c *Client) error { _, err := c.MirrorSink(ctx); return err },
+			method: "GET", path: "/mirror/sink",
+		},
+		{
+			name:   "burst stats",
+			call:   func(ctx context.Context, c *Client) error { _, err := c.BurstStats(ctx, 30*time.Second); return err },
+			method: "GET", path: "/burst/stats", query: "window=30s",
+		},
+		{
+			name: "set endpoints",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SetEndpoints(ctx, []string{"io"}, []string{"fault", "queue"})
+				return err
+			},
+			method: "POST", path: "/admin/endpoints", query: "disable=fault%2Cqueue&enable=io",
+		},
+		{
+			name:   "selftest",
+			call:   func(ctx context.Context, c *Client) error { _, err := c.Selftest(ctx); return err },
+			method: "GET", path: "/admin/selftest",
+		},
+		{
+			name: "upload",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Upload(ctx, UploadOptions{Rate: 1024, Delay: time.Second}, strings.NewReader("payload"))
+				return err
+			},
+			method: "POST", path: "/upload", query: "delay=1s&rate=1024", mediaType: "application/octet-stream", body: "payload",
+		},
+		{
+			name: "stream latency",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.StreamLatency(ctx, StreamLatencyOptions{TTFB: 2 * time.Second, BodyDuration: 10 * time.Second, Chunks: 5})
+				return err
+			},
+			method: "GET", path: "/stream-latency", query: "body_duration=10s&chunks=5&ttfb=2s",
+		},
+		{
+			name: "enqueue",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Enqueue(ctx, EnqueueOptions{Count: 5, Priority: "high"})
+				return err
+			},
+			method: "POST", path: "/queue/enqueue", query: "count=5&priority=high",
+		},
+		{
+			name: "enqueue items",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.EnqueueItems(ctx, api.EnqueueRequest{Items: []api.EnqueueItem{{Count: 2, PayloadSize: "1Mi"}}})
+				return err
+			},
+			method: "POST", path: "/queue/enqueue", mediaType: "application/json",
+			body: `{"items":[{"count":2,"payload_size":"1Mi"}]}`,
+		},
+		{
+			name:   "scale workers to zero",
+			call:   func(ctx context.Context, c *Client) error { _, err := c.ScaleWorkers(ctx, 0); return err },
+			method: "POST", path: "/queue/workers", query: "count=0",
+		},
+		{
+			name: "queue items",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.QueueItems(ctx, QueueItemsOptions{Priority: "low", Offset: 100, Limit: 50})
+				return err
+			},
+			method: "GET", path: "/queue/items", query: "limit=50&offset=100&priority=low",
+		},
+		{
+			name:   "queue item",
+			call:   func(ctx context.Context, c *Client) error { _, err := c.QueueItem(ctx, "123-4"); return err },
+			method: "GET", path: "/queue/items/123-4",
+		},
+		{
+			name: "crash with exit code zero",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Crash(ctx, CrashOptions{ExitCode: &exitCode})
+				return err
+			},
+			method: "POST", path: "/fault/crash", query: "exit_code=0",
+		},
+		{
+			name: "threads until released",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Threads(ctx, ThreadsOptions{Count: 10, UntilReleased: true})
+				return err
+			},
+			method: "POST", path: "/fault/threads", query: "count=10&duration=0s",
+		},
+		{
+			name:   "ready toggle",
+			call:   func(ctx context.Context, c *Client) error { _, err := c.SetReady(ctx, nil); return err },
+			method: "POST", path: "/admin/ready",
+		},
+		{
+			name:   "ready false",
+			call:   func(ctx context.Context, c *Client) error { _, err := c.SetReady(ctx, &ready); return err },
+			method: "POST", path: "/admin/ready", query: "state=false",
+		},
+		{
+			name: "error rate zero is sent",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SetErrorRate(ctx, ErrorRateOptions{Endpoint: "/cpu", Codes: []int{502, 503}})
+				return err
+			},
+			method: "POST", path: "/admin/error-rate", query: "codes=502%2C503&endpoint=%2Fcpu&rate=0",
+		},
+		{
+			name: "set faults",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SetFaults(ctx, api.FaultRulesRequest{Rules: []api.FaultRule{{Endpoint: "/cpu", Rate: 0.5}}})
+				return err
+			},
+			method: "POST", path: "/admin/faults", mediaType: "application/json",
+			body: `{"rules":[{"endpoint":"/cpu","rate":0.5}]}`,
+		},
+		{
+			name: "clear one header fault",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ClearHeaderFaults(ctx, &endpoint)
+				return err
+			},
+			method: "DELETE", path: "/admin/header-faults", query: "endpoint=%2Fcpu",
+		},
+		{
+			name: "clock skew",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SetClockSkew(ctx, -90*time.Second)
+				return err
+			},
+			method: "POST", path: "/admin/clock", query: "skew=-1m30s",
+		},
+		{
+			name: "sidecar",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SetSidecar(ctx, SidecarOptions{CPUBaseline: "200m", MemoryJitter: "0"})
+				return err
+			},
+			method: "POST", path: "/admin/sidecar", query: "cpu_baseline=200m&memory_jitter=0",
+		},
+		{
+			name: "synthetic utilization zero is sent",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.SetSyntheticUtilization(ctx, 0)
+				return err
+			},
+			method: "POST", path: "/admin/custom-metrics", query: "synthetic_utilization=0",
+		},
+		{
+			name: "delete work profile",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.DeleteWorkProfile(ctx, "checkout")
+				return err
+			},
+			method: "DELETE", path: "/admin/profiles/checkout",
+		},
+		{
+			name: "events",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Events(ctx, EventsOptions{AfterID: 7, Type: "fault"})
+				return err
+			},
+			method: "GET", path: "/events", query: "after_id=7&type=fault",
+		},
+		{
+			name: "replay",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.StartReplay(ctx, strings.NewReader("0,GET,/cpu\n"), ReplayOptions{Format: "csv", Loop: true})
+				return err
+			},
+			method: "POST", path: "/admin/replay", query: "format=csv&loop=true", body: "0,GET,/cpu\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				got, body = r, string(b)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte("{}"))
+			}))
+			defer srv.Close()
+
+			c := New(Config{BaseURL: srv.URL + "/", Token: "secret"})
+			if err := tt.call(context.Background(), c); err != nil {
+				t.Fatalf("call: %v", err)
+			}
+
+			if got.Method != tt.method || got.URL.Path != tt.path || got.URL.RawQuery != tt.query {
+				t.Errorf("request = %s %s?%s, want %s %s?%s", got.Method, got.URL.Path, got.URL.RawQuery, tt.method, tt.path, tt.query)
+			}
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+			if ct := got.Header.Get("Content-Type"); ct != tt.mediaType {
+				t.Errorf("Content-Type = %q, want %q", ct, tt.mediaType)
+			}
+			if auth := got.Header.Get("Authorization"); auth != "Bearer secret" {
+				t.Errorf("Authorization = %q, want bearer token", auth)
+			}
+			if v := got.Header.Get(api.VersionHeader); v != api.Version {
+				t.Errorf("%s = %q, want %q", api.VersionHeader, v, api.Version)
+			}
+		})
+	}
+}
+
+func TestClientError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode string
+		wantMsg  string
+	}{
+		{"hotpod error", http.StatusBadRequest, `{"error":"rate must be between 0 and 1","code":"INVALID_PARAMETER"}`, "INVALID_PARAMETER", "rate must be between 0 and 1"},
+		{"retryable error", http.StatusTooManyRequests, `{"error":"concurrent operation limit exceeded","code":"TOO_MANY_REQUESTS","retryable":true,"request_id":"abc"}`, "TOO_MANY_REQUESTS", "concurrent operation limit exceeded"},
+		{"plain text", http.StatusBadGateway, "upstream connect error\n", "", "upstream connect error"},
+		{"readiness failure", http.StatusServiceUnavailable, `{"status":"not ready"}`, "", `{"status":"not ready"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := New(Config{BaseURL: srv.URL}).Info(context.Background())
+			if got := StatusCode(err); got != tt.status {
+				t.Fatalf("StatusCode(%v) = %d, want %d", err, got, tt.status)
+			}
+			apiErr := err.(*Error)
+			if apiErr.Code != tt.wantCode || apiErr.Message != tt.wantMsg {
+				t.Errorf("error = %+v, want code %q message %q", apiErr, tt.wantCode, tt.wantMsg)
+			}
+			if wantRetry := tt.status == http.StatusTooManyRequests; apiErr.Retryable != wantRetry || (wantRetry && apiErr.RequestID != "abc") {
+				t.Errorf("error = %+v, want retryable %v", apiErr, wantRetry)
+			}
+		})
+	}
+}
+
+func TestWithServerTimeout(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Hotpod-Timeout"))
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL})
+	if _, err := c.Info(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Info(WithServerTimeout(context.Background(), 2500*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "" || got[1] != "2.5s" {
+		t.Errorf("X-Hotpod-Timeout headers = %q, want none then 2.5s", got)
+	}
+}
+
+// TestClientAgainstHandlers checks that responses from the real handlers
+// decode into the client's types.
+func TestClientAgainstHandlers(t *testing.T) {
+	mux := http.NewServeMux()
+	handlers.NewHealthHandlers(server.NewLifecycle(0, 0, 0, 30*time.Second, false), nil, nil).Register(mux)
+	handlers.NewEventsHandlers(auth.New("", nil), events.New(10)).Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := New(Config{BaseURL: srv.URL})
+
+	health, err := c.Healthz(ctx)
+	if err != nil {
+		t.Fatalf("Healthz: %v", err)
+	}
+	if health.Status != "ok" {
+		t.Errorf("Healthz status = %q, want ok", health.Status)
+	}
+
+	ev, err := c.CreateEvent(ctx, api.CreateEventRequest{Message: "phase one", Attrs: map[string]any{"step": 1}})
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	list, err := c.Events(ctx, EventsOptions{Type: events.TypeScenario})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if list.Count != 1 || list.Events[0].ID != ev.ID || list.Events[0].Message != "phase one" {
+		t.Errorf("Events = %+v, want the created event %+v", list, ev)
+	}
+}
+
+func TestSession(t *testing.T) {
+	mux := http.NewServeMux()
+	handlers.NewSessionHandlers("pod-a").Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL})
+	first, err := c.Session(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Session: %v", err)
+	}
+	if !first.New || first.IssuedBy != "pod-a" {
+		t.Fatalf("first Session = %+v, want a new session from pod-a", first)
+	}
+
+	ctx := WithSession(context.Background(), first.Session+":"+first.IssuedBy)
+	again, err := c.Session(ctx, false)
+	if err != nil {
+		t.Fatalf("Session: %v", err)
+	}
+	if again.New || again.Session != first.Session || !again.SamePod || again.Source != "header" {
+		t.Errorf("replayed Session = %+v, want the same session on the same pod", again)
+	}
+}
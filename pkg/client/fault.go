@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Fault endpoints require chaos to be enabled on the server, and the mutate
+// role when admin auth is configured.
+
+// CrashOptions are the parameters for POST /fault/crash.
+type CrashOptions struct {
+	Delay time.Duration
+	// ExitCode is the process exit code (nil uses the server default of 1)
+	ExitCode *int
+}
+
+// Crash calls POST /fault/crash. The process exits after responding.
+func (c *Client) Crash(ctx context.Context, opts CrashOptions) (*api.CrashResponse, error) {
+	q := query{}.dur("delay", opts.Delay)
+	if opts.ExitCode != nil {
+		q.str("exit_code", strconv.Itoa(*opts.ExitCode))
+	}
+	return call[api.CrashResponse](ctx, c, http.MethodPost, "/fault/crash", q)
+}
+
+// PanicOptions are the parameters for POST /fault/panic.
+type PanicOptions struct {
+	// Goroutine panics in a background goroutine after responding, crashing
+	// the process, rather than in the handler
+	Goroutine bool
+	// Delay postpones a goroutine panic
+	Delay time.Duration
+	// Message is the panic value (empty uses the server default)
+	Message string
+}
+
+// Panic calls POST /fault/panic. A handler panic returns an *Error with
+// status 500, and a goroutine panic crashes the process after responding.
+func (c *Client) Panic(ctx context.Context, opts PanicOptions) (*api.PanicResponse, error) {
+	q := query{}.bool("goroutine", opts.Goroutine).dur("delay", opts.Delay).str("message", opts.Message)
+	return call[api.PanicResponse](ctx, c, http.MethodPost, "/fault/panic", q)
+}
+
+// HangOptions are the parameters for POST /fault/hang.
+type HangOptions struct {
+	// Duration bounds the hang (zero hangs until ctx is done)
+	Duration time.Duration
+	// Partial sends the response headers before hanging
+	Partial bool
+}
+
+// Hang calls POST /fault/hang, which blocks until the hang ends.
+func (c *Client) Hang(ctx context.Context, opts HangOptions) (*api.HangResponse, error) {
+	q := query{}.dur("duration", opts.Duration).bool("partial", opts.Partial)
+	return call[api.HangResponse](ctx, c, http.MethodPost, "/fault/hang", q)
+}
+
+// OOM calls POST /fault/oom, allocating rate bytes per second until the
+// process is killed (zero uses the server default of 100MB/s).
+func (c *Client) OOM(ctx context.Context, rate int64) (*api.OOMResponse, error) {
+	return call[api.OOMResponse](ctx, c, http.MethodPost, "/fault/oom", query{}.size("rate", rate))
+}
+
+// OOMUntil calls POST /fault/oom with a stopping condition: "high" stops
+// allocating once the container is under memory.high pressure.
+func (c *Client) OOMUntil(ctx context.Context, rate int64, until string) (*api.OOMResponse, error) {
+	q := query{}.size("rate", rate).str("until", until)
+	return call[api.OOMResponse](ctx, c, http.MethodPost, "/fault/oom", q)
+}
+
+// OOMHold calls POST /fault/oom with a target: allocation stops once target
+// bytes are allocated, and the memory is held until StopOOM is called.
+func (c *Client) OOMHold(ctx context.Context, rate, target int64) (*api.OOMResponse, error) {
+	q := query{}.size("rate", rate).size("target", target)
+	return call[api.OOMResponse](ctx, c, http.MethodPost, "/fault/oom", q)
+}
+
+// OOMStatus calls GET /fault/oom.
+func (c *Client) OOMStatus(ctx context.Context) (*api.OOMStatus, error) {
+	return call[api.OOMStatus](ctx, c, http.MethodGet, "/fault/oom", nil)
+}
+
+// StopOOM calls DELETE /fault/oom, cancelling the simulation and releasing
+// any memory it holds.
+func (c *Client) StopOOM(ctx context.Context) (*api.OOMStatus, error) {
+	return call[api.OOMStatus](ctx, c, http.MethodDelete, "/fault/oom", nil)
+}
+
+// FaultErrorOptions are the parameters for GET /fault/error.
+type FaultErrorOptions struct {
+	// Rate is the probability of an error (zero uses the server default of 0.5)
+	Rate float64
+	// Status is the error status code (zero uses the server default of 500)
+	Status int
+}
+
+// FaultError calls GET /fault/error. An injected error is returned as an
+// *Error carrying the chosen status.
+func (c *Client) FaultError(ctx context.Context, opts FaultErrorOptions) (*api.FaultErrorResponse, error) {
+	q := query{}.float("rate", opts.Rate).int("status", opts.Status)
+	return call[api.FaultErrorResponse](ctx, c, http.MethodGet, "/fault/error", q)
+}
+
+// Zombie calls POST /fault/zombie to create count zombie processes.
+func (c *Client) Zombie(ctx context.Context, count int) (*api.ZombieResponse, error) {
+	return call[api.ZombieResponse](ctx, c, http.MethodPost, "/fault/zombie", query{}.int("count", count))
+}
+
+// ReapZombies calls DELETE /fault/zombie.
+func (c *Client) ReapZombies(ctx context.Context) (*api.ZombieResponse, error) {
+	return call[api.ZombieResponse](ctx, c, http.MethodDelete, "/fault/zombie", nil)
+}
+
+// ThreadsOptions are the parameters for POST /fault/threads.
+type ThreadsOptions struct {
+	Count int
+	// Duration is how long the threads are held (zero uses the server
+	// default of 60s)
+	Duration time.Duration
+	// UntilReleased holds the threads until ReleaseThreads is called
+	UntilReleased bool
+}
+
+// Threads calls POST /fault/threads to pin OS threads.
+func (c *Client) Threads(ctx context.Context, opts ThreadsOptions) (*api.ThreadsResponse, error) {
+	q := query{}.int("count", opts.Count).dur("duration", opts.Duration)
+	if opts.UntilReleased {
+		q.str("duration", "0s")
+	}
+	return call[api.ThreadsResponse](ctx, c, http.MethodPost, "/fault/threads", q)
+}
+
+// ReleaseThreads calls DELETE /fault/threads.
+func (c *Client) ReleaseThreads(ctx context.Context) (*api.ThreadsResponse, error) {
+	return call[api.ThreadsResponse](ctx, c, http.MethodDelete, "/fault/threads", nil)
+}
+
+// Deadlock calls POST /fault/deadlock to deadlock count goroutine pairs.
+func (c *Client) Deadlock(ctx context.Context, count int) (*api.DeadlockResponse, error) {
+	return call[api.DeadlockResponse](ctx, c, http.MethodPost, "/fault/deadlock", query{}.int("count", count))
+}
+
+// ContentionOptions are the parameters for POST /fault/contention.
+type ContentionOptions struct {
+	Workers  int
+	Hold     time.Duration
+	Duration time.Duration
+	// Async returns immediately instead of waiting for the run to finish
+	Async bool
+}
+
+// Contention calls POST /fault/contention.
+func (c *Client) Contention(ctx context.Context, opts ContentionOptions) (*api.ContentionResponse, error) {
+	q := query{}.int("workers", opts.Workers).dur("hold", opts.Hold).dur("duration", opts.Duration).bool("async", opts.Async)
+	return call[api.ContentionResponse](ctx, c, http.MethodPost, "/fault/contention", q)
+}
+
+// PortsOptions are the parameters for POST /fault/ports.
+type PortsOptions struct {
+	// Target is the host:port to dial (required)
+	Target string
+	// Rate limits dials per second (zero is unlimited)
+	Rate        float64
+	Concurrency int
+	Hold        time.Duration
+	Duration    time.Duration
+}
+
+// StartPorts calls POST /fault/ports to start ephemeral port exhaustion.
+func (c *Client) StartPorts(ctx context.Context, opts PortsOptions) (*api.PortStressStatus, error) {
+	q := query{}.str("target", opts.Target).float("rate", opts.Rate).int("concurrency", opts.Concurrency).
+		dur("hold", opts.Hold).dur("duration", opts.Duration)
+	return call[api.PortStressStatus](ctx, c, http.MethodPost, "/fault/ports", q)
+}
+
+// Ports calls GET /fault/ports.
+func (c *Client) Ports(ctx context.Context) (*api.PortStressStatus, error) {
+	return call[api.PortStressStatus](ctx, c, http.MethodGet, "/fault/ports", nil)
+}
+
+// StopPorts calls DELETE /fault/ports.
+func (c *Client) StopPorts(ctx context.Context) (*api.PortStressStatus, error) {
+	return call[api.PortStressStatus](ctx, c, http.MethodDelete, "/fault/ports", nil)
+}
+
+// DropLeadership calls POST /fault/leader/drop, releasing the lease and
+// sitting out the election for hold.
+func (c *Client) DropLeadership(ctx context.Context, hold time.Duration) (*api.LeaderFaultResponse, error) {
+	return call[api.LeaderFaultResponse](ctx, c, http.MethodPost, "/fault/leader/drop", query{}.dur("hold", hold))
+}
+
+// StallLeadership calls POST /fault/leader/stall, suspending lease renewal
+// for duration.
+func (c *Client) StallLeadership(ctx context.Context, duration time.Duration) (*api.LeaderFaultResponse, error) {
+	return call[api.LeaderFaultResponse](ctx, c, http.MethodPost, "/fault/leader/stall", query{}.dur("duration", duration))
+}
+
+// EvictOptions are the parameters for POST /fault/evict.
+type EvictOptions struct {
+	// GracePeriodSeconds overrides the pod's termination grace period (nil
+	// keeps the pod's own)
+	GracePeriodSeconds *int64
+}
+
+// Evict calls POST /fault/evict, deleting the server's own pod through the
+// Kubernetes API.
+func (c *Client) Evict(ctx context.Context, opts EvictOptions) (*api.EvictResponse, error) {
+	q := query{}
+	if opts.GracePeriodSeconds != nil {
+		q.str("grace_period", strconv.FormatInt(*opts.GracePeriodSeconds, 10))
+	}
+	return call[api.EvictResponse](ctx, c, http.MethodPost, "/fault/evict", q)
+}
+
+// StormOptions are the parameters for POST /fault/storm.
+type StormOptions struct {
+	// Rate is the fraction of requests failed (zero fails all)
+	Rate float64
+	// RetryAfter are the Retry-After values each response picks from
+	RetryAfter []time.Duration
+	// HTTPDate sends Retry-After as an HTTP date instead of seconds
+	HTTPDate bool
+	Duration time.Duration
+}
+
+// StartStorm calls POST /fault/storm to fail requests with 503 and
+// Retry-After for a window.
+func (c *Client) StartStorm(ctx context.Context, opts StormOptions) (*api.StormStatus, error) {
+	retryAfter := make([]string, len(opts.RetryAfter))
+	for i, d := range opts.RetryAfter {
+		retryAfter[i] = d.String()
+	}
+	q := query{}.float("rate", opts.Rate).str("retry_after", strings.Join(retryAfter, ",")).
+		bool("http_date", opts.HTTPDate).dur("duration", opts.Duration)
+	return call[api.StormStatus](ctx, c, http.MethodPost, "/fault/storm", q)
+}
+
+// Storm calls GET /fault/storm.
+func (c *Client) Storm(ctx context.Context) (*api.StormStatus, error) {
+	return call[api.StormStatus](ctx, c, http.MethodGet, "/fault/storm", nil)
+}
+
+// StopStorm calls DELETE /fault/storm.
+func (c *Client) StopStorm(ctx context.Context) (*api.StormStatus, error) {
+	return call[api.StormStatus](ctx, c, http.MethodDelete, "/fault/storm", nil)
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Zero-valued option fields are omitted so the server applies its defaults.
+
+// CPUOptions are the parameters for GET /cpu.
+type CPUOptions struct {
+	Duration time.Duration
+	Cores    int
+	// Intensity is low, medium, high, cache, or vector
+	Intensity string
+	// Pin locks each burn goroutine to its own OS thread
+	Pin bool
+	// CPUs is a Linux CPU list, e.g. 2-3, to bind the burn threads to
+	CPUs string
+}
+
+// CPU calls GET /cpu.
+func (c *Client) CPU(ctx context.Context, opts CPUOptions) (*api.CPUResponse, error) {
+	q := query{}.dur("duration", opts.Duration).int("cores", opts.Cores).str("intensity", opts.Intensity).
+		bool("pin", opts.Pin).str("cpus", opts.CPUs)
+	return call[api.CPUResponse](ctx, c, http.MethodGet, "/cpu", q)
+}
+
+// MemoryOptions are the parameters for GET /memory.
+type MemoryOptions struct {
+	// Size is the allocation in bytes
+	Size     int64
+	Duration time.Duration
+	// Pattern is the fill pattern: random, zero, or sequential
+	Pattern string
+}
+
+// Memory calls GET /memory.
+func (c *Client) Memory(ctx context.Context, opts MemoryOptions) (*api.MemoryResponse, error) {
+	q := query{}.size("size", opts.Size).dur("duration", opts.Duration).str("pattern", opts.Pattern)
+	return call[api.MemoryResponse](ctx, c, http.MethodGet, "/memory", q)
+}
+
+// MemoryPressureOptions are the parameters for GET /memory/pressure.
+type MemoryPressureOptions struct {
+	// Size is the working set in bytes
+	Size     int64
+	Duration time.Duration
+	// Access is the page visiting order: random or sequential
+	Access string
+}
+
+// MemoryPressure calls GET /memory/pressure.
+func (c *Client) MemoryPressure(ctx context.Context, opts MemoryPressureOptions) (*api.MemoryPressureResponse, error) {
+	q := query{}.size("size", opts.Size).dur("duration", opts.Duration).str("access", opts.Access)
+	return call[api.MemoryPressureResponse](ctx, c, http.MethodGet, "/memory/pressure", q)
+}
+
+// IOOptions are the parameters for GET /io.
+type IOOptions struct {
+	// Size is the number of bytes to read or write
+	Size int64
+	// Operation is write, read, or mixed
+	Operation string
+	// Sync forces fsync after writes
+	Sync bool
+	// Duration repeats the operation until it elapses, for sustained I/O
+	Duration time.Duration
+	// Rate limits sustained I/O to this many bytes per second
+	Rate int64
+}
+
+// IO calls GET /io.
+func (c *Client) IO(ctx context.Context, opts IOOptions) (*api.IOResponse, error) {
+	q := query{}.size("size", opts.Size).str("operation", opts.Operation).bool("sync", opts.Sync).
+		dur("duration", opts.Duration).size("rate", opts.Rate)
+	return call[api.IOResponse](ctx, c, http.MethodGet, "/io", q)
+}
+
+// UploadOptions are the parameters for POST /upload.
+type UploadOptions struct {
+	// Rate reads the body at this many bytes per second
+	Rate int64
+	// Delay waits this long before reading the body
+	Delay time.Duration
+}
+
+// Upload calls POST /upload with body.
+func (c *Client) Upload(ctx context.Context, opts UploadOptions, body io.Reader) (*api.UploadResponse, error) {
+	q := query{}.size("rate", opts.Rate).dur("delay", opts.Delay)
+	return callBody[api.UploadResponse](ctx, c, http.MethodPost, "/upload", q, body, "application/octet-stream")
+}
+
+// WorkOptions are the parameters for GET /work.
+type WorkOptions struct {
+	// Profile is web, api, worker, or heavy
+	Profile string
+	// Variance randomizes the work by up to this fraction (0 to 1)
+	Variance float64
+}
+
+// Work calls GET /work.
+func (c *Client) Work(ctx context.Context, opts WorkOptions) (*api.WorkResponse, error) {
+	q := query{}.str("profile", opts.Profile).float("variance", opts.Variance)
+	return call[api.WorkResponse](ctx, c, http.MethodGet, "/work", q)
+}
+
+// LatencyOptions are the parameters for GET /latency.
+type LatencyOptions struct {
+	Duration time.Duration
+	Jitter   time.Duration
+	// Status is the response status code; non-2xx statuses are returned as
+	// an *Error
+	Status int
+	// Mode is fixed or adaptive
+	Mode string
+	// Curve, Capacity, and Max tune adaptive mode
+	Curve    string
+	Capacity int
+	Max      time.Duration
+	// Distribution samples the sleep around Duration, capped at Max
+	Distribution *api.LatencyDistribution
+}
+
+// Latency calls GET /latency.
+func (c *Client) Latency(ctx context.Context, opts LatencyOptions) (*api.LatencyResponse, error) {
+	q := query{}.dur("duration", opts.Duration).dur("jitter", opts.Jitter).int("status", opts.Status).
+		str("mode", opts.Mode).str("curve", opts.Curve).int("capacity", opts.Capacity).dur("max", opts.Max).
+		dist(opts.Distribution)
+	return call[api.LatencyResponse](ctx, c, http.MethodGet, "/latency", q)
+}
+
+// StreamLatencyOptions are the parameters for GET /stream-latency.
+type StreamLatencyOptions struct {
+	// TTFB is the wait before the response header
+	TTFB time.Duration
+	// BodyDuration is the time the body is streamed over
+	BodyDuration time.Duration
+	Chunks       int
+	// Status is the response status code; non-2xx statuses are returned as
+	// an *Error
+	Status int
+}
+
+// StreamLatency calls GET /stream-latency, reading the whole stream, and
+// returns its closing summary. Callers measuring time-to-first-byte
+// themselves should use Do or a plain HTTP client instead.
+func (c *Client) StreamLatency(ctx context.Context, opts StreamLatencyOptions) (*api.StreamLatencyResponse, error) {
+	q := query{}.dur("ttfb", opts.TTFB).dur("body_duration", opts.BodyDuration).int("chunks", opts.Chunks).int("status", opts.Status)
+	b, err := c.Do(ctx, http.MethodGet, "/stream-latency", url.Values(q), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	out := new(api.StreamLatencyResponse)
+	if err := json.Unmarshal(lines[len(lines)-1], out); err != nil {
+		return nil, fmt.Errorf("decoding GET /stream-latency response: %w", err)
+	}
+	return out, nil
+}
+
+// DNSOptions are the parameters for GET /dns.
+type DNSOptions struct {
+	// Name is the name to resolve (required)
+	Name string
+	// Type is the lookup type, e.g. host, srv, or txt
+	Type        string
+	Count       int
+	Rate        float64
+	Concurrency int
+	Timeout     time.Duration
+}
+
+// DNS calls GET /dns.
+func (c *Client) DNS(ctx context.Context, opts DNSOptions) (*api.DNSResponse, error) {
+	q := query{}.str("name", opts.Name).str("type", opts.Type).int("count", opts.Count).
+		float("rate", opts.Rate).int("concurrency", opts.Concurrency).dur("timeout", opts.Timeout)
+	return call[api.DNSResponse](ctx, c, http.MethodGet, "/dns", q)
+}
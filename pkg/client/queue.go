@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// EnqueueOptions are the parameters for POST /queue/enqueue.
+type EnqueueOptions struct {
+	// Count is the number of items to enqueue (default 1)
+	Count          int
+	ProcessingTime time.Duration
+	// Priority is high, normal, or low
+	Priority string
+}
+
+// Enqueue calls POST /queue/enqueue.
+func (c *Client) Enqueue(ctx context.Context, opts EnqueueOptions) (*api.EnqueueResponse, error) {
+	q := query{}.int("count", opts.Count).dur("processing_time", opts.ProcessingTime).str("priority", opts.Priority)
+	return call[api.EnqueueResponse](ctx, c, http.MethodPost, "/queue/enqueue", q)
+}
+
+// EnqueueItems calls POST /queue/enqueue with a JSON body, for items that
+// differ in priority, processing time, payload size, or delivery time.
+func (c *Client) EnqueueItems(ctx context.Context, req api.EnqueueRequest) (*api.EnqueueResponse, error) {
+	return callJSON[api.EnqueueResponse](ctx, c, http.MethodPost, "/queue/enqueue", req)
+}
+
+// ProcessOptions are the parameters for POST /queue/process.
+type ProcessOptions struct {
+	// Workers is the number of workers to run (default: the server's
+	// configured worker count)
+	Workers       int
+	CPUPerItem    time.Duration
+	MemoryPerItem int64
+	// BatchSize is the number of items each worker processes at once
+	// (default 1)
+	BatchSize int
+}
+
+// Process calls POST /queue/process to start or resize the worker pool.
+func (c *Client) Process(ctx context.Context, opts ProcessOptions) (*api.ProcessResponse, error) {
+	q := query{}.int("workers", opts.Workers).dur("cpu_per_item", opts.CPUPerItem).size("memory_per_item", opts.MemoryPerItem).
+		int("batch_size", opts.BatchSize)
+	return call[api.ProcessResponse](ctx, c, http.MethodPost, "/queue/process", q)
+}
+
+// ScaleWorkers calls POST /queue/workers to resize the running worker pool
+// without interrupting in-progress items.
+func (c *Client) ScaleWorkers(ctx context.Context, count int) (*api.QueueWorkersResponse, error) {
+	q := query{}.str("count", strconv.Itoa(count))
+	return call[api.QueueWorkersResponse](ctx, c, http.MethodPost, "/queue/workers", q)
+}
+
+// QueueStatus calls GET /queue/status.
+func (c *Client) QueueStatus(ctx context.Context) (*api.QueueStatusResponse, error) {
+	return call[api.QueueStatusResponse](ctx, c, http.MethodGet, "/queue/status", nil)
+}
+
+// ClearQueue calls POST /queue/clear.
+func (c *Client) ClearQueue(ctx context.Context) (*api.QueueClearResponse, error) {
+	return call[api.QueueClearResponse](ctx, c, http.MethodPost, "/queue/clear", nil)
+}
+
+// QueueItemsOptions are the parameters for GET /queue/items.
+type QueueItemsOptions struct {
+	// Priority limits the listing to high, normal, or low items
+	Priority string
+	Offset   int
+	// Limit is the page size (default 100)
+	Limit int
+}
+
+// QueueItems calls GET /queue/items to list queued items in dequeue order.
+func (c *Client) QueueItems(ctx context.Context, opts QueueItemsOptions) (*api.QueueItemsResponse, error) {
+	q := query{}.str("priority", opts.Priority).int("offset", opts.Offset).int("limit", opts.Limit)
+	return call[api.QueueItemsResponse](ctx, c, http.MethodGet, "/queue/items", q)
+}
+
+// QueueItem calls GET /queue/items/{id}.
+func (c *Client) QueueItem(ctx context.Context, id string) (*api.QueueItem, error) {
+	return call[api.QueueItem](ctx, c, http.MethodGet, "/queue/items/"+url.PathEscape(id), nil)
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Healthz calls GET /healthz.
+func (c *Client) Healthz(ctx context.Context) (*api.HealthResponse, error) {
+	return call[api.HealthResponse](ctx, c, http.MethodGet, "/healthz", nil)
+}
+
+// Readyz calls GET /readyz. A pod that is not ready returns an *Error with
+// status 503.
+func (c *Client) Readyz(ctx context.Context) (*api.HealthResponse, error) {
+	return call[api.HealthResponse](ctx, c, http.MethodGet, "/readyz", nil)
+}
+
+// Startupz calls GET /startupz. A pod still starting returns an *Error with
+// status 503.
+func (c *Client) Startupz(ctx context.Context) (*api.HealthResponse, error) {
+	return call[api.HealthResponse](ctx, c, http.MethodGet, "/startupz", nil)
+}
+
+// PreStop calls GET /prestop, which blocks for delay (zero uses the server's
+// configured delay).
+func (c *Client) PreStop(ctx context.Context, delay time.Duration) (*api.PreStopResponse, error) {
+	return call[api.PreStopResponse](ctx, c, http.MethodGet, "/prestop", query{}.dur("delay", delay))
+}
+
+// Info calls GET /info.
+func (c *Client) Info(ctx context.Context) (*api.InfoResponse, error) {
+	return call[api.InfoResponse](ctx, c, http.MethodGet, "/info", nil)
+}
+
+// Echo calls GET /echo.
+func (c *Client) Echo(ctx context.Context) (*api.EchoResponse, error) {
+	return call[api.EchoResponse](ctx, c, http.MethodGet, "/echo", nil)
+}
+
+// Session calls GET /session, carrying the token from WithSession if ctx has
+// one. reset issues a new session regardless. The token to pass to
+// WithSession for later requests is Session:IssuedBy of the response.
+func (c *Client) Session(ctx context.Context, reset bool) (*api.SessionResponse, error) {
+	return call[api.SessionResponse](ctx, c, http.MethodGet, "/session", query{}.bool("reset", reset))
+}
+
+// MirrorSource calls GET /mirror/source.
+func (c *Client) MirrorSource(ctx context.Context) (*api.MirrorResponse, error) {
+	return call[api.MirrorResponse](ctx, c, http.MethodGet, "/mirror/source", nil)
+}
+
+// MirrorSink calls GET /mirror/sink.
+func (c *Client) MirrorSink(ctx context.Context) (*api.MirrorResponse, error) {
+	return call[api.MirrorResponse](ctx, c, http.MethodGet, "/mirror/sink", nil)
+}
+
+// Burst calls GET /burst, recording an arrival.
+func (c *Client) Burst(ctx context.Context) (*api.BurstResponse, error) {
+	return call[api.BurstResponse](ctx, c, http.MethodGet, "/burst", nil)
+}
+
+// BurstStats calls GET /burst/stats. A zero window uses the server default.
+func (c *Client) BurstStats(ctx context.Context, window time.Duration) (*api.BurstStats, error) {
+	return call[api.BurstStats](ctx, c, http.MethodGet, "/burst/stats", query{}.dur("window", window))
+}
+
+// ResetBurst calls DELETE /burst/stats.
+func (c *Client) ResetBurst(ctx context.Context) (*api.BurstStats, error) {
+	return call[api.BurstStats](ctx, c, http.MethodDelete, "/burst/stats", nil)
+}
+
+// Leader calls GET /leader.
+func (c *Client) Leader(ctx context.Context) (*api.LeaderStatus, error) {
+	return call[api.LeaderStatus](ctx, c, http.MethodGet, "/leader", nil)
+}
+
+// Metrics returns the Prometheus text exposition from GET /metrics.
+func (c *Client) Metrics(ctx context.Context) ([]byte, error) {
+	return c.Do(ctx, http.MethodGet, "/metrics", nil, nil, "")
+}
+
+// EventsOptions filters GET /events.
+type EventsOptions struct {
+	// Since returns events recorded at or after this time
+	Since time.Time
+	// AfterID returns events with a greater ID, for incremental polling
+	AfterID uint64
+	// Level is the minimum level: debug, info, warn, or error
+	Level string
+	// Type is an event type: lifecycle, fault, scenario, or admin
+	Type string
+	// Limit caps the number of events returned
+	Limit int
+}
+
+// Events calls GET /events.
+func (c *Client) Events(ctx context.Context, opts EventsOptions) (*api.EventsResponse, error) {
+	q := query{}.str("level", opts.Level).str("type", opts.Type).int("limit", opts.Limit)
+	if !opts.Since.IsZero() {
+		q.str("since", opts.Since.Format(time.RFC3339))
+	}
+	if opts.AfterID != 0 {
+		q.size("after_id", int64(opts.AfterID))
+	}
+	return call[api.EventsResponse](ctx, c, http.MethodGet, "/events", q)
+}
+
+// CreateEvent calls POST /events to add a scenario marker to the timeline.
+func (c *Client) CreateEvent(ctx context.Context, req api.CreateEventRequest) (*api.Event, error) {
+	return callJSON[api.Event](ctx, c, http.MethodPost, "/events", req)
+}
@@ -0,0 +1,131 @@
+// Package errcode defines the machine-readable error codes carried in the
+// code field of hotpod's error responses, so automated clients can branch on
+// failures without matching messages.
+package errcode
+
+import (
+	"strings"
+
+	"github.com/ripta/hotpod/pkg/api"
+)
+
+// Code is a machine-readable error code.
+type Code string
+
+// Request errors.
+const (
+	// InvalidParameter is a malformed or out-of-range query parameter or body
+	InvalidParameter Code = "INVALID_PARAMETER"
+	// Unauthorized is a missing or invalid admin token, or missing
+	// /protected credentials
+	Unauthorized Code = "UNAUTHORIZED"
+	// Forbidden is an admin token without the required role, or wrong
+	// /protected credentials
+	Forbidden Code = "FORBIDDEN"
+	// UnsupportedVersion is an API version this server does not serve, by
+	// path prefix or X-Hotpod-API-Version header
+	UnsupportedVersion Code = "UNSUPPORTED_VERSION"
+)
+
+// Capacity and timeout errors, all worth retrying.
+const (
+	// TooManyRequests is the concurrent operation limit being reached
+	TooManyRequests Code = "TOO_MANY_REQUESTS"
+	// OperationTimeout is a request exceeding its timeout, or arriving while
+	// the server drains
+	OperationTimeout Code = "OPERATION_TIMEOUT"
+	// LoadShed is a request rejected by load shedding
+	LoadShed Code = "LOAD_SHED"
+	// UpstreamUnavailable is the sidecar proxy failing to reach the app
+	UpstreamUnavailable Code = "UPSTREAM_UNAVAILABLE"
+	// FaultInjected is an error injected by a fault rule
+	FaultInjected Code = "FAULT_INJECTED"
+	// ChaosCooldown is a chaos action repeated within its cooldown
+	ChaosCooldown Code = "CHAOS_COOLDOWN"
+	// NodePressure is load rejected while backing off from node pressure
+	NodePressure Code = "NODE_PRESSURE"
+)
+
+// Disabled feature errors.
+const (
+	ChaosDisabled          Code = "CHAOS_DISABLED"
+	QueueDisabled          Code = "QUEUE_DISABLED"
+	QueueNotAvailable      Code = "QUEUE_NOT_AVAILABLE"
+	SidecarDisabled        Code = "SIDECAR_DISABLED"
+	LeaderElectionDisabled Code = "LEADER_ELECTION_DISABLED"
+	FleetNotConfigured     Code = "FLEET_NOT_CONFIGURED"
+	TLSDisabled            Code = "TLS_DISABLED"
+	ChaosNotAllowed        Code = "CHAOS_NOT_ALLOWED"
+	KubeAPIUnavailable     Code = "KUBE_API_UNAVAILABLE"
+	NodePressureDisabled   Code = "NODE_PRESSURE_DISABLED"
+	SLODisabled            Code = "SLO_DISABLED"
+	ProberDisabled         Code = "PROBER_DISABLED"
+	ProtectedDisabled      Code = "PROTECTED_DISABLED"
+	EndpointDisabled       Code = "ENDPOINT_DISABLED"
+	ReadOnly               Code = "READ_ONLY"
+)
+
+// Conflicting state errors, retryable once the other operation finishes.
+const (
+	FaultRunning      Code = "FAULT_RUNNING"
+	ProfileInProgress Code = "PROFILE_IN_PROGRESS"
+	ReplayRunning     Code = "REPLAY_RUNNING"
+	PoolNotRunning    Code = "POOL_NOT_RUNNING"
+	ChaosLimitReached Code = "CHAOS_LIMIT_REACHED"
+)
+
+// Not found errors.
+const (
+	ItemNotFound       Code = "ITEM_NOT_FOUND"
+	ProfileNotFound    Code = "PROFILE_NOT_FOUND"
+	DependencyNotFound Code = "DEPENDENCY_NOT_FOUND"
+)
+
+// Server-side failures.
+const (
+	InternalError   Code = "INTERNAL_ERROR"
+	FaultFailed     Code = "FAULT_FAILED"
+	ProfileFailed   Code = "PROFILE_FAILED"
+	DiscoveryFailed Code = "DISCOVERY_FAILED"
+)
+
+// All lists every code.
+var All = []Code{
+	InvalidParameter, Unauthorized, Forbidden, UnsupportedVersion,
+	TooManyRequests, OperationTimeout, LoadShed, UpstreamUnavailable, FaultInjected, ChaosCooldown, NodePressure,
+	ChaosDisabled, QueueDisabled, QueueNotAvailable, SidecarDisabled, LeaderElectionDisabled, FleetNotConfigured, TLSDisabled, ChaosNotAllowed, KubeAPIUnavailable, NodePressureDisabled, SLODisabled, ProberDisabled, ProtectedDisabled, EndpointDisabled, ReadOnly,
+	FaultRunning, ProfileInProgress, ReplayRunning, PoolNotRunning, ChaosLimitReached,
+	ItemNotFound, ProfileNotFound, DependencyNotFound,
+	InternalError, FaultFailed, ProfileFailed, DiscoveryFailed,
+}
+
+// docBase is where each code is documented, under an anchor of its
+// lowercased name.
+const docBase = "https://github.com/ripta/hotpod/blob/main/docs/errors.md#"
+
+// Retryable reports whether the same request may succeed if sent again
+// later, without changes.
+func (c Code) Retryable() bool {
+	switch c {
+	case TooManyRequests, OperationTimeout, LoadShed, UpstreamUnavailable, FaultInjected, ChaosCooldown, NodePressure,
+		FaultRunning, ProfileInProgress, ReplayRunning, ChaosLimitReached, DiscoveryFailed:
+		return true
+	}
+	return false
+}
+
+// DocURL returns the documentation URL for the code.
+func (c Code) DocURL() string {
+	return docBase + strings.ToLower(string(c))
+}
+
+// Response builds the error response body for code. requestID may be empty.
+func Response(code Code, message, requestID string) api.ErrorResponse {
+	return api.ErrorResponse{
+		Error:     message,
+		Code:      string(code),
+		DocURL:    code.DocURL(),
+		Retryable: code.Retryable(),
+		RequestID: requestID,
+	}
+}
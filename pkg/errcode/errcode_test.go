@@ -0,0 +1,64 @@
+package errcode
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAllUnique(t *testing.T) {
+	seen := map[Code]bool{}
+	for _, c := range All {
+		if seen[c] {
+			t.Errorf("duplicate code %q", c)
+		}
+		seen[c] = true
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		code Code
+		want bool
+	}{
+		{InvalidParameter, false},
+		{Unauthorized, false},
+		{TooManyRequests, true},
+		{OperationTimeout, true},
+		{LoadShed, true},
+		{ChaosDisabled, false},
+		{ChaosNotAllowed, false},
+		{ChaosCooldown, true},
+		{FaultRunning, true},
+		{ItemNotFound, false},
+		{InternalError, false},
+		{DiscoveryFailed, true},
+	}
+	for _, tt := range tests {
+		if got := tt.code.Retryable(); got != tt.want {
+			t.Errorf("%s.Retryable() = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestResponse(t *testing.T) {
+	resp := Response(LoadShed, "shed", "abc")
+	if resp.Code != "LOAD_SHED" || resp.Error != "shed" || resp.RequestID != "abc" || !resp.Retryable {
+		t.Errorf("Response() = %+v", resp)
+	}
+	if want := "https://github.com/ripta/hotpod/blob/main/docs/errors.md#load_shed"; resp.DocURL != want {
+		t.Errorf("DocURL = %q, want %q", resp.DocURL, want)
+	}
+}
+
+func TestAllDocumented(t *testing.T) {
+	doc, err := os.ReadFile("../../docs/errors.md")
+	if err != nil {
+		t.Fatalf("failed to read docs: %v", err)
+	}
+	for _, c := range All {
+		if !strings.Contains(string(doc), "\n### "+string(c)+"\n") {
+			t.Errorf("code %q is not documented in docs/errors.md", c)
+		}
+	}
+}